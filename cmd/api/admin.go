@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/bootstrap"
+	"github.com/delordemm1/go-api-simple-starter/internal/modules/user"
+	"github.com/spf13/cobra"
+)
+
+// adminCommand groups operational subcommands that run against the configured database
+// without starting the server. app returns the application wired by the CLI callback.
+func adminCommand(app func() *bootstrap.App) *cobra.Command {
+	admin := &cobra.Command{
+		Use:   "admin",
+		Short: "Administrative tasks",
+	}
+	admin.AddCommand(&cobra.Command{
+		Use:   "promote <email>",
+		Short: "Grant the admin role to a user with a verified email (bootstraps the first admin)",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			a := app()
+			ctx := cmd.Context()
+			defer a.DB.Close()
+
+			// Security events are delivered by a background stream; run it for this command
+			// so the promotion reaches the SIEM like any other admin event.
+			if s, ok := a.SecurityEvents.(bootstrap.Starter); ok {
+				if err := s.Start(ctx); err != nil {
+					a.Logger.Warn("security event stream not started", "error", err)
+				}
+			}
+			if s, ok := a.SecurityEvents.(bootstrap.Stopper); ok {
+				defer s.Stop(ctx)
+			}
+
+			u, err := a.UserService.PromoteAdmin(ctx, args[0], user.AdminPromotionCLI)
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "promote %s: %v\n", args[0], err)
+				os.Exit(1)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s (%s) is an admin\n", u.Email, u.ID)
+		},
+	})
+	return admin
+}
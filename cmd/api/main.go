@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -9,14 +11,9 @@ import (
 	"time"
 
 	"github.com/danielgtaylor/huma/v2/humacli"
-	"github.com/delordemm1/go-api-simple-starter/internal/cache"
+	"github.com/delordemm1/go-api-simple-starter/internal/bootstrap"
+	"github.com/delordemm1/go-api-simple-starter/internal/buildinfo"
 	"github.com/delordemm1/go-api-simple-starter/internal/config"
-	"github.com/delordemm1/go-api-simple-starter/internal/database"
-	"github.com/delordemm1/go-api-simple-starter/internal/modules/user"
-	"github.com/delordemm1/go-api-simple-starter/internal/notification"
-	"github.com/delordemm1/go-api-simple-starter/internal/notification/templates"
-	"github.com/delordemm1/go-api-simple-starter/internal/server"
-	"github.com/delordemm1/go-api-simple-starter/internal/session"
 )
 
 // Options for the CLI.
@@ -25,63 +22,36 @@ type Options struct {
 }
 
 func main() {
+	// app is built by the callback below, which runs before any command (serve or admin).
+	var app *bootstrap.App
 	cli := humacli.New(func(hooks humacli.Hooks, options *Options) {
 		// Use a structured logger
-		logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+		logger := slog.New(slog.NewJSONHandler(os.Stdout, nil)).With(buildinfo.LogAttrs()...)
+		build := buildinfo.Get()
+		logger.Info("go-api-simple-starter starting", "buildTime", build.BuildTime, "goVersion", build.GoVersion)
 		cfg := config.Load()
 		if cfg == nil {
 			logger.Error("failed to load configuration")
 			os.Exit(1)
 		}
-		logger.Info("configuration loaded successfully", "env", cfg)
+		logger.Info("configuration loaded successfully", "env", cfg.Server.Env)
 
-		// --- Database & Cache ---
-		dbPool := database.NewPostgresPool(cfg.Database.URL)
-		if dbPool == nil {
-			logger.Error("failed to connect to postgres")
+		// --- Dependency wiring (see internal/bootstrap) ---
+		var err error
+		app, err = bootstrap.New(cfg, logger)
+		if err != nil {
+			logger.Error("failed to build application", "error", err)
 			os.Exit(1)
 		}
-		hooks.OnStop(dbPool.Close)
-		logger.Info("successfully connected to postgres database")
-		redisClient := cache.NewRedisClient(cfg.Redis.URL)
-		if redisClient == nil {
-			logger.Error("failed to connect to redis")
-			os.Exit(1)
-		}
-		hooks.OnStop(func() { redisClient.Close() })
-		logger.Info("successfully connected to redis")
-
-		// --- Module Initialization (Bottom-Up) ---
-
-		// Templates engine (embedded by default, disk override in dev)
-		tmplEngine := templates.NewEngine(templates.Config{
-			Dir:    cfg.Templates.Dir,
-			Reload: cfg.Templates.Reload,
-		}, logger)
-
-		emailSender := notification.NewSMTPEmailSender(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.From, logger)
-		smsSender := notification.NewDummySMSSender(logger)
-		// Create the main notification service
-		notificationService := notification.NewService(logger, emailSender, smsSender, tmplEngine)
-		// User Module
-		userRepo := user.NewRepository(dbPool)
-
-		// Session provider (Postgres-backed) with sliding & absolute TTLs
-		sessionsProvider := session.NewPostgresProvider(dbPool, session.Config{
-			SlidingTTL:  7 * 24 * time.Hour,
-			AbsoluteTTL: 30 * 24 * time.Hour,
-		})
 
-		userService := user.NewService(&user.Config{
-			Repo:         userRepo,
-			Logger:       logger,
-			Config:       cfg,
-			Sessions:     sessionsProvider,
-			Notification: notificationService,
-		})
-
-		router := server.New(cfg, logger, userService, sessionsProvider)
+		var srv *http.Server
 		hooks.OnStart(func() {
+			// Bring up components in dependency order (health-gated).
+			if err := app.Start(context.Background()); err != nil {
+				logger.Error("failed to start application", "error", err)
+				os.Exit(1)
+			}
+
 			// Determine port: CLI -p overrides, else cfg.Server.Port, else 8080
 			port := options.Port
 			if port <= 0 {
@@ -101,13 +71,35 @@ func main() {
 				logger.Info("using default port", "port", port)
 			}
 
+			srv = &http.Server{Addr: fmt.Sprintf(":%d", port), Handler: app.Router}
 			logger.Info(fmt.Sprintf("Starting server on port %d...", port))
-			if err := http.ListenAndServe(fmt.Sprintf(":%d", port), router); err != nil {
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 				slog.Error("Server failed to start", "error", err)
 				os.Exit(1)
 			}
 		})
+		hooks.OnStop(func() {
+			timeout := time.Duration(cfg.Shutdown.TimeoutSeconds) * time.Second
+			if timeout <= 0 {
+				timeout = 30 * time.Second
+			}
+			logger.Info("shutting down", "timeout", timeout.String())
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			// Stop accepting requests first, then tear down components in reverse order.
+			if srv != nil {
+				if err := srv.Shutdown(ctx); err != nil {
+					logger.Error("http server shutdown failed", "error", err)
+				}
+			}
+			if err := app.Stop(ctx); err != nil {
+				logger.Error("application shutdown failed", "error", err)
+				return
+			}
+			logger.Info("shutdown complete")
+		})
 	})
+	cli.Root().AddCommand(adminCommand(func() *bootstrap.App { return app }))
 	cli.Run()
 }
 
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/openapits"
+	"github.com/delordemm1/go-api-simple-starter/internal/server"
+)
+
+// Generates TypeScript definitions (openapi-typescript layout) from the Huma spec, so the
+// frontend (e.g., the SvelteKit proxy) shares the API's request/response types.
+//
+// Usage: go run ./cmd/openapi-ts [-o web/src/lib/api/schema.d.ts] [-check]
+
+func main() {
+	out := flag.String("o", "", "output file (default: stdout)")
+	check := flag.Bool("check", false, "fail if the output file is out of date instead of writing it")
+	flag.Parse()
+
+	// No dependencies are wired; route registration alone produces the spec.
+	spec := server.Spec(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	var buf bytes.Buffer
+	if err := openapits.Generate(&buf, spec); err != nil {
+		log.Fatalf("❌ Failed to generate TypeScript types: %v", err)
+	}
+
+	if *out == "" {
+		if _, err := os.Stdout.Write(buf.Bytes()); err != nil {
+			log.Fatalf("❌ Failed to write output: %v", err)
+		}
+		return
+	}
+
+	if *check {
+		current, err := os.ReadFile(*out)
+		if err != nil || !bytes.Equal(current, buf.Bytes()) {
+			log.Fatalf("❌ %s is out of date. Run: go run ./cmd/openapi-ts -o %s", *out, *out)
+		}
+		log.Printf("✅ %s is up to date", *out)
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(*out), 0o755); err != nil {
+		log.Fatalf("❌ Failed to create output directory: %v", err)
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0o644); err != nil {
+		log.Fatalf("❌ Failed to write %s: %v", *out, err)
+	}
+	log.Printf("✅ Wrote TypeScript types to %s", *out)
+}
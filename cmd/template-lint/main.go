@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"log/slog"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/notification/templates"
+)
+
+// Checks that every notification template only references variables its data type has,
+// the same check the API runs at startup, so CI catches a renamed field before a deploy.
+//
+// Usage: go run ./cmd/template-lint [-dir internal/notification/templates/files]
+
+func main() {
+	dir := flag.String("dir", "", "template directory to check (default: the embedded templates)")
+	flag.Parse()
+
+	engine := templates.NewEngine(templates.Config{Dir: *dir}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	handles := templates.Handles()
+	if err := engine.Check(handles...); err != nil {
+		log.Fatalf("❌ Template contract violations:\n%v", err)
+	}
+	log.Printf("✅ %d templates match their data types", len(handles))
+}
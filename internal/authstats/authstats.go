@@ -0,0 +1,273 @@
+// Package authstats tracks the success/failure ratio of authentication attempts (logins and
+// one-time code checks) over a rolling window, per instance and across all instances through
+// Redis. It raises an alert when the failure ratio spikes, an early sign of credential
+// stuffing against the API.
+package authstats
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/cache"
+	"github.com/delordemm1/go-api-simple-starter/internal/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+// Kind is the type of authentication attempt.
+type Kind string
+
+const (
+	KindLogin Kind = "login" // password, phone, and OAuth sign-ins
+	KindOTP   Kind = "otp"   // one-time code checks (verification, reset, restore, ...)
+)
+
+var kinds = []Kind{KindLogin, KindOTP}
+
+// Scopes of a ratio: this process, or all instances sharing the Redis.
+const (
+	ScopeInstance = "instance"
+	ScopeGlobal   = "global"
+)
+
+var (
+	// attemptsTotal counts attempts, labelled by kind and outcome (success / failure).
+	attemptsTotal = metrics.NewCounter("auth_attempts_total")
+	// failureRatio and windowAttempts describe the rolling window, labelled by kind and scope.
+	failureRatio   = metrics.NewGauge("auth_failure_ratio")
+	windowAttempts = metrics.NewGauge("auth_window_attempts")
+)
+
+// Alert describes a failure ratio at or above the configured threshold.
+type Alert struct {
+	Kind     Kind
+	Scope    string
+	Attempts int64
+	Failures int64
+	Ratio    float64
+	Window   time.Duration
+}
+
+// Config configures a Tracker.
+type Config struct {
+	// Window is the rolling window ratios are computed over (default 5m).
+	Window time.Duration
+	// Interval is how often Evaluate runs, and the resolution of the window (default 30s).
+	Interval time.Duration
+	// FailureRatio is the alert threshold in (0, 1]; 0 disables alerts.
+	FailureRatio float64
+	// MinAttempts keeps low-traffic windows from alerting (default 20).
+	MinAttempts int64
+	// Redis shares counts across instances under Namespace; nil tracks this instance only.
+	Redis     *redis.Client
+	Namespace cache.Namespace
+	// Notify is called once per spike and scope. For the global scope, only one instance
+	// is notified per window.
+	Notify func(Alert)
+}
+
+type counts struct {
+	attempts int64
+	failures int64
+}
+
+func (c *counts) add(o counts) {
+	c.attempts += o.attempts
+	c.failures += o.failures
+}
+
+// Tracker counts attempts and evaluates their failure ratios.
+type Tracker struct {
+	cfg   Config
+	log   *slog.Logger
+	slots int
+
+	mu      sync.Mutex
+	pending map[Kind]counts   // since the last Evaluate
+	ring    map[Kind][]counts // one entry per Evaluate in the window
+	next    int
+	firing  map[string]bool // kind/scope pairs already alerted for the current spike
+}
+
+// New returns a tracker. Register Evaluate to run every cfg.Interval.
+func New(cfg Config, log *slog.Logger) *Tracker {
+	if cfg.Window <= 0 {
+		cfg.Window = 5 * time.Minute
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 30 * time.Second
+	}
+	if cfg.MinAttempts <= 0 {
+		cfg.MinAttempts = 20
+	}
+	slots := max(int(cfg.Window/cfg.Interval), 1)
+	t := &Tracker{
+		cfg:     cfg,
+		log:     log,
+		slots:   slots,
+		pending: map[Kind]counts{},
+		ring:    map[Kind][]counts{},
+		firing:  map[string]bool{},
+	}
+	for _, k := range kinds {
+		t.ring[k] = make([]counts, slots)
+	}
+	return t
+}
+
+// Interval returns how often Evaluate should run.
+func (t *Tracker) Interval() time.Duration { return t.cfg.Interval }
+
+// Record counts one attempt. It only touches memory, so it is safe on the request path;
+// Evaluate publishes the counts.
+func (t *Tracker) Record(kind Kind, success bool) {
+	outcome := "success"
+	if !success {
+		outcome = "failure"
+	}
+	attemptsTotal.Inc(string(kind), outcome)
+
+	t.mu.Lock()
+	c := t.pending[kind]
+	c.attempts++
+	if !success {
+		c.failures++
+	}
+	t.pending[kind] = c
+	t.mu.Unlock()
+}
+
+// Evaluate closes the current interval: it rolls the instance window, adds this instance's
+// counts to the shared Redis window, updates the ratio gauges, and notifies on spikes.
+func (t *Tracker) Evaluate(ctx context.Context) error {
+	now := time.Now()
+
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = map[Kind]counts{}
+	local := map[Kind]counts{}
+	for _, k := range kinds {
+		t.ring[k][t.next] = pending[k]
+		var sum counts
+		for _, c := range t.ring[k] {
+			sum.add(c)
+		}
+		local[k] = sum
+	}
+	t.next = (t.next + 1) % t.slots
+	t.mu.Unlock()
+
+	for _, k := range kinds {
+		t.observe(ctx, k, ScopeInstance, local[k])
+	}
+	if t.cfg.Redis == nil {
+		return nil
+	}
+
+	global, err := t.syncGlobal(ctx, now, pending)
+	if err != nil {
+		return err
+	}
+	for _, k := range kinds {
+		t.observe(ctx, k, ScopeGlobal, global[k])
+	}
+	return nil
+}
+
+// syncGlobal adds pending to the Redis bucket of now and returns the sums of the buckets
+// in the window. Buckets expire on their own once they leave it.
+func (t *Tracker) syncGlobal(ctx context.Context, now time.Time, pending map[Kind]counts) (map[Kind]counts, error) {
+	bucket := now.Truncate(t.cfg.Interval)
+	pipe := t.cfg.Redis.Pipeline()
+	for k, c := range pending {
+		if c.attempts == 0 {
+			continue
+		}
+		key := t.bucketKey(k, bucket)
+		pipe.HIncrBy(ctx, key, "attempts", c.attempts)
+		pipe.HIncrBy(ctx, key, "failures", c.failures)
+		pipe.Expire(ctx, key, t.cfg.Window+t.cfg.Interval)
+	}
+	reads := map[Kind][]*redis.SliceCmd{}
+	for _, k := range kinds {
+		for i := 0; i < t.slots; i++ {
+			b := bucket.Add(-time.Duration(i) * t.cfg.Interval)
+			reads[k] = append(reads[k], pipe.HMGet(ctx, t.bucketKey(k, b), "attempts", "failures"))
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("authstats sync: %w", err)
+	}
+
+	global := map[Kind]counts{}
+	for k, cmds := range reads {
+		var sum counts
+		for _, cmd := range cmds {
+			vals := cmd.Val()
+			sum.add(counts{attempts: toInt(vals[0]), failures: toInt(vals[1])})
+		}
+		global[k] = sum
+	}
+	return global, nil
+}
+
+func (t *Tracker) bucketKey(k Kind, bucket time.Time) string {
+	return t.cfg.Namespace.Key("authstats", string(k), strconv.FormatInt(bucket.Unix(), 10))
+}
+
+func toInt(v any) int64 {
+	s, ok := v.(string)
+	if !ok {
+		return 0
+	}
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
+
+// observe publishes the window c of kind/scope and notifies when it crosses the threshold.
+// A spike alerts once; the alert re-arms when the ratio drops back below the threshold.
+func (t *Tracker) observe(ctx context.Context, k Kind, scope string, c counts) {
+	var ratio float64
+	if c.attempts > 0 {
+		ratio = float64(c.failures) / float64(c.attempts)
+	}
+	failureRatio.Set(ratio, string(k), scope)
+	windowAttempts.Set(float64(c.attempts), string(k), scope)
+
+	if t.cfg.FailureRatio <= 0 || t.cfg.Notify == nil {
+		return
+	}
+	id := string(k) + "/" + scope
+	over := c.attempts >= t.cfg.MinAttempts && ratio >= t.cfg.FailureRatio
+
+	t.mu.Lock()
+	fire := over && !t.firing[id]
+	t.firing[id] = over
+	t.mu.Unlock()
+
+	if !fire || (scope == ScopeGlobal && !t.claimGlobalAlert(ctx, k)) {
+		return
+	}
+	t.cfg.Notify(Alert{
+		Kind:     k,
+		Scope:    scope,
+		Attempts: c.attempts,
+		Failures: c.failures,
+		Ratio:    ratio,
+		Window:   t.cfg.Window,
+	})
+}
+
+// claimGlobalAlert elects one instance to report a global spike per window. When Redis is
+// unavailable every instance reports: a duplicate alert beats a lost one.
+func (t *Tracker) claimGlobalAlert(ctx context.Context, k Kind) bool {
+	ok, err := t.cfg.Redis.SetNX(ctx, t.cfg.Namespace.Key("authstats", "alert", string(k)), 1, t.cfg.Window).Result()
+	if err != nil {
+		t.log.Warn("authstats: claim global alert failed", "error", err, "kind", k)
+		return true
+	}
+	return ok
+}
@@ -0,0 +1,647 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/authstats"
+	"github.com/delordemm1/go-api-simple-starter/internal/cache"
+	"github.com/delordemm1/go-api-simple-starter/internal/captcha"
+	"github.com/delordemm1/go-api-simple-starter/internal/clock"
+	"github.com/delordemm1/go-api-simple-starter/internal/config"
+	"github.com/delordemm1/go-api-simple-starter/internal/database"
+	"github.com/delordemm1/go-api-simple-starter/internal/httpx"
+	"github.com/delordemm1/go-api-simple-starter/internal/idempotency"
+	"github.com/delordemm1/go-api-simple-starter/internal/ipreputation"
+	"github.com/delordemm1/go-api-simple-starter/internal/jobs"
+	"github.com/delordemm1/go-api-simple-starter/internal/jwtkeys"
+	"github.com/delordemm1/go-api-simple-starter/internal/modules/audit"
+	"github.com/delordemm1/go-api-simple-starter/internal/modules/user"
+	"github.com/delordemm1/go-api-simple-starter/internal/notification"
+	"github.com/delordemm1/go-api-simple-starter/internal/notification/templates"
+	"github.com/delordemm1/go-api-simple-starter/internal/passhash"
+	"github.com/delordemm1/go-api-simple-starter/internal/pwned"
+	"github.com/delordemm1/go-api-simple-starter/internal/quota"
+	"github.com/delordemm1/go-api-simple-starter/internal/ratelimit"
+	"github.com/delordemm1/go-api-simple-starter/internal/scheduler"
+	"github.com/delordemm1/go-api-simple-starter/internal/server"
+	"github.com/delordemm1/go-api-simple-starter/internal/session"
+	"github.com/delordemm1/go-api-simple-starter/internal/siem"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// App is the fully wired application: shared infrastructure, modules, and the HTTP router.
+// New subsystems (workers, schedulers, ...) get a provider below and register with Lifecycle.
+type App struct {
+	Config *config.Config
+	Logger *slog.Logger
+	// Clock is the time source shared by session TTLs and the user service's expiry checks.
+	Clock clock.Clock
+
+	DB    *pgxpool.Pool
+	Redis *redis.Client
+
+	Sessions session.Provider
+	// SessionInvalidation broadcasts session changes to the other instances' local caches; nil
+	// when SESSION_LOCAL_CACHE_SECONDS is 0. Other per-process caches may hook into OnInvalidate.
+	SessionInvalidation *session.Invalidator
+	// JWTKeys signs session tokens and backs /.well-known/jwks.json; nil unless
+	// SESSION_TOKEN_FORMAT is "jwt".
+	JWTKeys      *jwtkeys.Set
+	Notification notification.Service
+	// Templates renders notifications; admins upload template overrides through it.
+	Templates      *templates.Engine
+	SecurityEvents siem.Publisher
+	// Audit keeps the per-user security event log; it is fed through SecurityEvents.
+	Audit       audit.Service
+	UserService user.Service
+	Quota       *quota.Tracker // nil when quota tracking is disabled
+	// RateLimit enforces the auth endpoint limits; nil when RATE_LIMIT_ENABLED is false.
+	RateLimit *ratelimit.Limiter
+	// AuthStats tracks login and OTP failure ratios and alerts on spikes.
+	AuthStats *authstats.Tracker
+	// IPReputation screens registrations and logins by client IP; nil when no checker is configured.
+	IPReputation *ipreputation.Policy
+	// Captcha guards the public auth endpoints; nil when CAPTCHA_PROVIDER is "none".
+	Captcha *captcha.Policy
+	// Jobs runs background tasks; modules register their handlers in JobHandlers.
+	Jobs        jobs.Queue
+	JobHandlers *jobs.Registry
+
+	Router    chi.Router
+	Lifecycle *Container
+}
+
+// New constructs the application bottom-up. Components are registered with the lifecycle
+// container in dependency order, so Start brings them up in that order and Stop tears
+// them down in reverse.
+func New(cfg *config.Config, logger *slog.Logger) (*App, error) {
+	if err := enforceProductionGuardrails(cfg, logger); err != nil {
+		return nil, err
+	}
+
+	app := &App{
+		Config:    cfg,
+		Logger:    logger,
+		Clock:     clock.Real{},
+		Lifecycle: NewContainer(logger),
+	}
+	if t := cfg.Shutdown.ComponentTimeoutSeconds; t > 0 {
+		app.Lifecycle.StopTimeout = time.Duration(t) * time.Second
+	}
+
+	provideAlerts(app)
+	provideDatabase(app)
+	provideRedis(app)
+	if err := provideNotification(app); err != nil {
+		return nil, err
+	}
+	if err := provideTaskQueue(app); err != nil {
+		return nil, err
+	}
+	if err := provideSessions(app); err != nil {
+		return nil, err
+	}
+	if err := provideSecurityEvents(app); err != nil {
+		return nil, err
+	}
+	provideAudit(app)
+	provideAuthStats(app)
+	if err := provideIPReputation(app); err != nil {
+		return nil, err
+	}
+	if err := provideCaptcha(app); err != nil {
+		return nil, err
+	}
+	if err := provideUserModule(app); err != nil {
+		return nil, err
+	}
+	provideJobs(app)
+	provideQuota(app)
+	if err := provideRateLimit(app); err != nil {
+		return nil, err
+	}
+	if err := provideRouter(app); err != nil {
+		return nil, err
+	}
+
+	return app, nil
+}
+
+// Start brings up all registered components in order.
+func (a *App) Start(ctx context.Context) error {
+	return a.Lifecycle.Start(ctx)
+}
+
+// Stop shuts down all registered components in reverse order.
+func (a *App) Stop(ctx context.Context) error {
+	return a.Lifecycle.Stop(ctx)
+}
+
+// --- Providers ---
+
+func provideAlerts(app *App) {
+	cfg := app.Config.Alerts
+	if cfg.InternalErrorThreshold <= 0 {
+		return
+	}
+	httpx.RegisterAlertHook(httpx.NewSpikeAlerter("ErrInternal", cfg.InternalErrorThreshold,
+		time.Duration(cfg.WindowSeconds)*time.Second,
+		func(code string, count int, window time.Duration) {
+			app.Logger.Error("alert: problem spike detected", "code", code, "count", count, "window", window.String())
+		}))
+}
+
+func provideDatabase(app *App) {
+	app.DB = database.NewPostgresPool(app.Config.Database.URL)
+	app.Lifecycle.Register("postgres", Hooks{
+		OnHealth: app.DB.Ping,
+		OnStop: func(context.Context) error {
+			app.DB.Close()
+			return nil
+		},
+	})
+}
+
+func provideRedis(app *App) {
+	app.Redis = cache.NewRedisClient(app.Config.Redis.URL)
+	app.Lifecycle.Register("redis", Hooks{
+		OnHealth: func(ctx context.Context) error { return app.Redis.Ping(ctx).Err() },
+		OnStop:   func(context.Context) error { return app.Redis.Close() },
+	})
+}
+
+func provideNotification(app *App) error {
+	cfg := app.Config
+	// Templates engine (embedded by default, disk override in dev, S3 or database overrides
+	// uploaded by admins)
+	source, err := provideTemplateSource(app)
+	if err != nil {
+		return err
+	}
+	tmplEngine := templates.NewEngine(templates.Config{
+		Dir:             cfg.Templates.Dir,
+		Reload:          cfg.Templates.Reload,
+		Source:          source,
+		RefreshInterval: time.Duration(cfg.Templates.RefreshSeconds) * time.Second,
+	}, app.Logger)
+	app.Templates = tmplEngine
+	// Fail the boot on a template referencing a field its data type lacks, rather than the
+	// first send of that template. Remote sources are checked once postgres is up.
+	checkTemplates := func(context.Context) error {
+		if err := tmplEngine.Check(templates.Handles()...); err != nil {
+			return fmt.Errorf("notification templates: %w", err)
+		}
+		return nil
+	}
+	if source == nil {
+		if err := checkTemplates(context.Background()); err != nil {
+			return err
+		}
+	} else {
+		app.Lifecycle.Register("templates", Hooks{OnStart: checkTemplates})
+	}
+
+	encryption, err := notification.ParseSMTPEncryption(cfg.SMTP.Encryption)
+	if err != nil {
+		return err
+	}
+	auth, err := notification.ParseSMTPAuth(cfg.SMTP.Auth)
+	if err != nil {
+		return err
+	}
+	emailSender, err := notification.NewSMTPEmailSender(notification.SMTPConfig{
+		Host:       cfg.SMTP.Host,
+		Port:       cfg.SMTP.Port,
+		Username:   cfg.SMTP.Username,
+		Password:   cfg.SMTP.Password,
+		Encryption: encryption,
+		Auth:       auth,
+		HeloName:   cfg.SMTP.HeloName,
+		From:       notification.FormatAddress(cfg.SMTP.FromName, cfg.SMTP.From),
+		ReplyTo:    cfg.SMTP.ReplyTo,
+	}, app.Logger)
+	if err != nil {
+		return err
+	}
+	smsSender := notification.NewDummySMSSender(app.Logger)
+	app.Notification = notification.NewService(app.Logger, emailSender, smsSender, tmplEngine, notification.Config{
+		DryRun:       cfg.Notifications.DryRun,
+		Outbox:       notification.NewPostgresOutbox(app.DB, cfg.Server.Namespace()),
+		MaxAttempts:  cfg.Notifications.MaxAttempts,
+		RetryBackoff: time.Duration(cfg.Notifications.RetryBackoffSeconds) * time.Second,
+		DeadLetters:  notification.NewPostgresDeadLetters(app.DB, cfg.Server.Namespace()),
+	})
+	// Registered after postgres, so in-flight sends (and their outbox records) drain before the pool closes.
+	app.Lifecycle.Register("notifications", app.Notification,
+		WithStopTimeout(time.Duration(cfg.Shutdown.NotificationsTimeoutSeconds)*time.Second))
+	return nil
+}
+
+// provideTemplateSource returns the configured template source, or nil for the engine's default
+// (EMAIL_TEMPLATES_DIR when set, else the embedded templates).
+func provideTemplateSource(app *App) (templates.Source, error) {
+	cfg := app.Config.Templates
+	kind, err := templates.ParseSourceKind(cfg.Source)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case templates.SourceDisk:
+		if cfg.Dir == "" {
+			return nil, fmt.Errorf("TEMPLATES_SOURCE=disk requires EMAIL_TEMPLATES_DIR")
+		}
+		return templates.NewDirSource(cfg.Dir), nil
+	case templates.SourceS3:
+		store, err := templates.NewS3Store(templates.S3Config{
+			Bucket:          cfg.S3Bucket,
+			Prefix:          cfg.S3Prefix,
+			Region:          cfg.S3Region,
+			Endpoint:        cfg.S3Endpoint,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			SessionToken:    cfg.S3SessionToken,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return templates.WithFallback(store, templates.NewEmbeddedSource()), nil
+	case templates.SourceDatabase:
+		return templates.WithFallback(templates.NewPostgresStore(app.DB, app.Config.Server.Namespace()), templates.NewEmbeddedSource()), nil
+	default:
+		return nil, nil
+	}
+}
+
+func provideTaskQueue(app *App) error {
+	cfg := app.Config.Jobs
+	app.JobHandlers = jobs.NewRegistry()
+	qcfg := jobs.Config{
+		Concurrency:  cfg.Concurrency,
+		MaxAttempts:  cfg.MaxAttempts,
+		RetryBackoff: time.Duration(cfg.RetryBackoffSeconds) * time.Second,
+		ClaimIdle:    time.Duration(cfg.ClaimIdleSeconds) * time.Second,
+	}
+	switch cfg.Backend {
+	case "", jobs.BackendMemory:
+		app.Jobs = jobs.NewMemoryQueue(app.JobHandlers, qcfg, app.Logger)
+	case jobs.BackendRedis:
+		app.Jobs = jobs.NewRedisQueue(app.Redis, cache.Namespace(app.Config.Server.Namespace()), app.JobHandlers, qcfg, app.Logger)
+	default:
+		return fmt.Errorf("unknown JOBS_BACKEND %q (want %q or %q)", cfg.Backend, jobs.BackendMemory, jobs.BackendRedis)
+	}
+	// Registered after postgres, redis, and notifications, so running jobs finish before they stop.
+	app.Lifecycle.Register("jobs", app.Jobs)
+	return nil
+}
+
+func provideSessions(app *App) error {
+	binding, err := session.ParseBindingMode(app.Config.Sessions.Binding)
+	if err != nil {
+		return fmt.Errorf("SESSION_BINDING: %w", err)
+	}
+	// Session provider with sliding & absolute TTLs, timed per method
+	cfg := app.Config.Sessions
+	scfg := session.Config{
+		SlidingTTL:            time.Duration(cfg.SlidingTTLHours) * time.Hour,
+		AbsoluteTTL:           time.Duration(cfg.AbsoluteTTLHours) * time.Hour,
+		RememberMeSlidingTTL:  time.Duration(cfg.RememberMeSlidingTTLHours) * time.Hour,
+		RememberMeAbsoluteTTL: time.Duration(cfg.RememberMeAbsoluteTTLHours) * time.Hour,
+		ElevationTTL:          time.Duration(cfg.ElevationMinutes) * time.Minute,
+		Namespace:             cache.Namespace(app.Config.Server.Namespace()),
+		TokenBytes:            cfg.TokenBytes,
+		Clock:                 app.Clock,
+		Binding:               binding,
+	}
+	switch cfg.Backend {
+	case "", session.BackendPostgres:
+		app.Sessions = session.NewPostgresProvider(app.DB, scfg)
+	case session.BackendRedis:
+		app.Sessions = session.NewRedisProvider(app.Redis, app.DB, scfg)
+	case session.BackendMemory:
+		app.Logger.Warn("sessions are kept in memory: they are lost on restart and not shared between instances")
+		app.Sessions = session.NewMemoryProvider(scfg)
+	default:
+		return fmt.Errorf("unknown SESSION_BACKEND %q (want %q, %q or %q)", cfg.Backend, session.BackendPostgres, session.BackendRedis, session.BackendMemory)
+	}
+	app.Sessions = session.NewInstrumentedProvider(app.Sessions)
+	if cfg.LocalCacheSeconds > 0 {
+		// Registered after redis, so the subscription closes before the client does.
+		app.SessionInvalidation = session.NewInvalidator(app.Redis, cache.Namespace(app.Config.Server.Namespace()), app.Logger)
+		app.Lifecycle.Register("session-invalidation", app.SessionInvalidation)
+		app.Sessions = session.NewCachedProvider(app.Sessions, time.Duration(cfg.LocalCacheSeconds)*time.Second, scfg, app.SessionInvalidation)
+	}
+	if cfg.IssuancePerUserPerMinute > 0 || cfg.IssuancePerIPPerMinute > 0 || cfg.IssuanceGlobalAlertPerMinute > 0 {
+		app.Sessions = session.NewIssuanceGuard(app.Sessions, session.IssuanceConfig{
+			Redis:                app.Redis,
+			Namespace:            cache.Namespace(app.Config.Server.Namespace()),
+			PerUserPerMinute:     cfg.IssuancePerUserPerMinute,
+			PerIPPerMinute:       cfg.IssuancePerIPPerMinute,
+			BlockFor:             time.Duration(cfg.IssuanceBlockMinutes) * time.Minute,
+			GlobalAlertPerMinute: cfg.IssuanceGlobalAlertPerMinute,
+			Notify: func(perMinute int64) {
+				app.Logger.Error("alert: session issuance spike", "per_minute", perMinute)
+			},
+		}, app.Logger)
+	}
+	switch cfg.TokenFormat {
+	case "", session.TokenFormatOpaque:
+	case session.TokenFormatJWT:
+		keys, err := jwtkeys.New(app.Config.JWTSecret, app.Config.JWTPreviousSecrets...)
+		if err != nil {
+			return fmt.Errorf("SESSION_TOKEN_FORMAT=jwt requires JWT_SECRET: %w", err)
+		}
+		app.JWTKeys = keys
+		// Outermost, so the stores, the local cache and the issuance guard see opaque tokens.
+		app.Sessions = session.NewJWTProvider(app.Sessions, keys, strings.TrimSuffix(app.Config.Server.PublicURL, "/"))
+		app.Logger.Info("session tokens are issued as JWTs", "kid", keys.KeyID())
+	default:
+		return fmt.Errorf("unknown SESSION_TOKEN_FORMAT %q (want %q or %q)", cfg.TokenFormat, session.TokenFormatOpaque, session.TokenFormatJWT)
+	}
+	return nil
+}
+
+func provideSecurityEvents(app *App) error {
+	cfg := app.Config.SIEM
+	if cfg.Endpoint == "" {
+		app.SecurityEvents = siem.Nop{}
+		return nil
+	}
+	stream, err := siem.New(siem.Config{
+		Endpoint:      cfg.Endpoint,
+		Secret:        cfg.Secret,
+		BatchSize:     cfg.BatchSize,
+		FlushInterval: time.Duration(cfg.FlushIntervalSeconds) * time.Second,
+		QueueSize:     cfg.QueueSize,
+	}, app.Logger)
+	if err != nil {
+		return err
+	}
+	app.SecurityEvents = stream
+	app.Lifecycle.Register("siem", stream)
+	return nil
+}
+
+// provideAudit records security events in the per-user audit log alongside the SIEM stream.
+func provideAudit(app *App) {
+	app.Audit = audit.NewService(audit.NewRepository(app.DB), app.Logger)
+	app.SecurityEvents = siem.Multi{app.SecurityEvents, app.Audit}
+	app.Lifecycle.Register("audit", app.Audit)
+}
+
+func provideAuthStats(app *App) {
+	cfg := app.Config.Alerts
+	app.AuthStats = authstats.New(authstats.Config{
+		Window:       time.Duration(cfg.AuthWindowSeconds) * time.Second,
+		Interval:     time.Duration(cfg.AuthIntervalSeconds) * time.Second,
+		FailureRatio: cfg.AuthFailureRatio,
+		MinAttempts:  int64(cfg.AuthMinAttempts),
+		Redis:        app.Redis,
+		Namespace:    cache.Namespace(app.Config.Server.Namespace()),
+		Notify: func(a authstats.Alert) {
+			app.Logger.Error("alert: authentication failure ratio spike", "kind", a.Kind, "scope", a.Scope,
+				"ratio", a.Ratio, "failures", a.Failures, "attempts", a.Attempts, "window", a.Window.String())
+		},
+	}, app.Logger)
+	app.Lifecycle.Register("auth-stats", scheduler.Every("auth-stats", app.AuthStats.Interval(), app.AuthStats.Evaluate, app.Logger))
+}
+
+func provideIPReputation(app *App) error {
+	cfg := app.Config.IPReputation
+	register, err := ipreputation.ParseAction(cfg.RegisterAction)
+	if err != nil {
+		return fmt.Errorf("IP_REPUTATION_REGISTER_ACTION: %w", err)
+	}
+	if register == ipreputation.ActionStepUp {
+		return fmt.Errorf("IP_REPUTATION_REGISTER_ACTION: %q is only supported for login", ipreputation.ActionStepUp)
+	}
+	login, err := ipreputation.ParseAction(cfg.LoginAction)
+	if err != nil {
+		return fmt.Errorf("IP_REPUTATION_LOGIN_ACTION: %w", err)
+	}
+
+	var checkers ipreputation.Multi
+	if len(cfg.Denylist) > 0 {
+		denylist, err := ipreputation.NewDenylist(cfg.Denylist)
+		if err != nil {
+			return err
+		}
+		checkers = append(checkers, denylist)
+	}
+	switch cfg.Provider {
+	case "", "none":
+	case "abuseipdb":
+		if cfg.AbuseIPDBAPIKey == "" {
+			return fmt.Errorf("IP_REPUTATION_PROVIDER=abuseipdb requires IP_REPUTATION_ABUSEIPDB_API_KEY")
+		}
+		checkers = append(checkers, ipreputation.NewAbuseIPDB(ipreputation.AbuseIPDBConfig{
+			APIKey:   cfg.AbuseIPDBAPIKey,
+			Timeout:  time.Duration(cfg.TimeoutSeconds) * time.Second,
+			CacheTTL: time.Duration(cfg.CacheMinutes) * time.Minute,
+		}))
+	default:
+		return fmt.Errorf("unknown IP_REPUTATION_PROVIDER %q (want \"none\" or \"abuseipdb\")", cfg.Provider)
+	}
+	if len(checkers) == 0 {
+		return nil
+	}
+	app.IPReputation = &ipreputation.Policy{
+		Checker:  checkers,
+		MinScore: cfg.MinScore,
+		Actions: map[string]ipreputation.Action{
+			ipreputation.EndpointRegister: register,
+			ipreputation.EndpointLogin:    login,
+		},
+	}
+	return nil
+}
+
+func provideCaptcha(app *App) error {
+	cfg := app.Config.Captcha
+	provider, err := captcha.ParseProvider(cfg.Provider)
+	if err != nil {
+		return fmt.Errorf("CAPTCHA_PROVIDER: %w", err)
+	}
+	endpoints, err := captcha.ParseEndpoints(cfg.Endpoints)
+	if err != nil {
+		return fmt.Errorf("CAPTCHA_ENDPOINTS: %w", err)
+	}
+	if provider == captcha.ProviderNone || len(endpoints) == 0 {
+		return nil
+	}
+	if cfg.Secret == "" {
+		return fmt.Errorf("CAPTCHA_PROVIDER=%s requires CAPTCHA_SECRET", provider)
+	}
+	verifier, err := captcha.New(provider, captcha.Config{
+		Secret:   cfg.Secret,
+		Hostname: cfg.Hostname,
+		MinScore: cfg.MinScore,
+		Timeout:  time.Duration(cfg.TimeoutSeconds) * time.Second,
+	})
+	if err != nil {
+		return err
+	}
+	app.Captcha = &captcha.Policy{Verifier: verifier, Endpoints: endpoints}
+	return nil
+}
+
+func provideUserModule(app *App) error {
+	hashCfg := app.Config.PasswordHash
+	algorithm, err := passhash.ParseAlgorithm(hashCfg.Algorithm)
+	if err != nil {
+		return err
+	}
+	hasher, err := passhash.New(passhash.Config{
+		Algorithm:   algorithm,
+		BcryptCost:  hashCfg.BcryptCost,
+		Memory:      hashCfg.Argon2MemoryKiB,
+		Iterations:  hashCfg.Argon2Iterations,
+		Parallelism: hashCfg.Argon2Parallelism,
+	})
+	if err != nil {
+		return err
+	}
+	var breaches user.BreachChecker
+	if cfg := app.Config.PasswordBreach; cfg.Enabled {
+		breaches = pwned.New(pwned.Config{
+			Endpoint: cfg.Endpoint,
+			MinCount: cfg.MinCount,
+			Timeout:  time.Duration(cfg.TimeoutSeconds) * time.Second,
+		})
+	}
+	app.UserService = user.NewService(&user.Config{
+		Repo:              user.NewInstrumentedRepository(user.NewRepository(app.DB)),
+		Logger:            app.Logger,
+		Config:            app.Config,
+		Sessions:          app.Sessions,
+		Notification:      app.Notification,
+		SecurityEvents:    app.SecurityEvents,
+		BreachedPasswords: breaches,
+		PasswordHasher:    hasher,
+		Clock:             app.Clock,
+		Jobs:              app.Jobs,
+		JobHandlers:       app.JobHandlers,
+		AuthAttempts:      app.AuthStats,
+		IPReputation:      app.IPReputation,
+		Captcha:           app.Captcha,
+		Templates:         app.Templates,
+		Idempotency:       idempotency.NewRedisStore(app.Redis, cache.Namespace(app.Config.Server.Namespace())),
+		Runbook:           app.Lifecycle.Runbook,
+	})
+	if len(app.Config.Admin.Emails) > 0 {
+		// Started after postgres is healthy; users not yet signed up are promoted on a later start.
+		app.Lifecycle.Register("admin-bootstrap", Hooks{
+			OnStart: func(ctx context.Context) error {
+				app.UserService.PromoteConfiguredAdmins(ctx)
+				return nil
+			},
+		})
+	}
+	return nil
+}
+
+func provideJobs(app *App) {
+	if gc := app.Config.Sessions; gc.GCIntervalMinutes > 0 {
+		app.Lifecycle.Register("session-gc", scheduler.Every("session-gc",
+			time.Duration(gc.GCIntervalMinutes)*time.Minute,
+			func(ctx context.Context) error {
+				n, err := app.Sessions.PurgeExpired(ctx, gc.GCBatchSize)
+				if n > 0 {
+					app.Logger.Info("purged expired sessions", "count", n)
+				}
+				return err
+			}, app.Logger))
+	}
+
+	if r := app.Config.Retention; r.Enabled() {
+		app.Lifecycle.Register("data-retention", scheduler.Every("data-retention",
+			time.Duration(r.IntervalMinutes)*time.Minute,
+			func(ctx context.Context) error {
+				_, err := app.UserService.EnforceRetention(ctx)
+				return err
+			}, app.Logger))
+	}
+
+	if r := app.Config.Retention; r.ExpiredIntervalMinutes > 0 {
+		app.Lifecycle.Register("expired-gc", scheduler.Every("expired-gc",
+			time.Duration(r.ExpiredIntervalMinutes)*time.Minute,
+			func(ctx context.Context) error {
+				_, err := app.UserService.PurgeExpired(ctx)
+				return err
+			}, app.Logger))
+	}
+
+	cfg := app.Config.Accounts
+	if cfg.CleanupEnabled {
+		app.Lifecycle.Register("account-cleanup", scheduler.Every("account-cleanup",
+			time.Duration(cfg.CleanupIntervalMinutes)*time.Minute,
+			func(ctx context.Context) error {
+				_, err := app.UserService.CleanupAccounts(ctx)
+				return err
+			}, app.Logger))
+	}
+}
+
+func provideQuota(app *App) {
+	cfg := app.Config.Quota
+	if cfg.RequestsPerWindow <= 0 {
+		return
+	}
+	app.Quota = quota.NewTracker(app.Redis, cache.Namespace(app.Config.Server.Namespace()), cfg.RequestsPerWindow, time.Duration(cfg.WindowSeconds)*time.Second)
+}
+
+func provideRateLimit(app *App) error {
+	cfg := app.Config.RateLimit
+	if !cfg.Enabled {
+		return nil
+	}
+	rules := map[string]string{
+		"RATE_LIMIT_LOGIN_PER_IP":               cfg.LoginPerIP,
+		"RATE_LIMIT_LOGIN_PER_ACCOUNT":          cfg.LoginPerAccount,
+		"RATE_LIMIT_REGISTER_PER_IP":            cfg.RegisterPerIP,
+		"RATE_LIMIT_PASSWORD_RESET_PER_IP":      cfg.PasswordResetPerIP,
+		"RATE_LIMIT_PASSWORD_RESET_PER_ACCOUNT": cfg.PasswordResetPerAccount,
+		"RATE_LIMIT_PHONE_PER_IP":               cfg.PhonePerIP,
+		"RATE_LIMIT_PHONE_PER_ACCOUNT":          cfg.PhonePerAccount,
+	}
+	parsed := make(map[string]ratelimit.Rule, len(rules))
+	for env, spec := range rules {
+		rule, err := ratelimit.ParseRule(spec)
+		if err != nil {
+			return fmt.Errorf("%s: %w", env, err)
+		}
+		parsed[env] = rule
+	}
+	app.RateLimit = ratelimit.NewLimiter(app.Redis, cache.Namespace(app.Config.Server.Namespace()), map[string]ratelimit.Bucket{
+		ratelimit.BucketLogin: {
+			PerIP:      parsed["RATE_LIMIT_LOGIN_PER_IP"],
+			PerAccount: parsed["RATE_LIMIT_LOGIN_PER_ACCOUNT"],
+		},
+		ratelimit.BucketRegister: {
+			PerIP: parsed["RATE_LIMIT_REGISTER_PER_IP"],
+		},
+		ratelimit.BucketPasswordReset: {
+			PerIP:      parsed["RATE_LIMIT_PASSWORD_RESET_PER_IP"],
+			PerAccount: parsed["RATE_LIMIT_PASSWORD_RESET_PER_ACCOUNT"],
+		},
+		ratelimit.BucketPhone: {
+			PerIP:      parsed["RATE_LIMIT_PHONE_PER_IP"],
+			PerAccount: parsed["RATE_LIMIT_PHONE_PER_ACCOUNT"],
+		},
+	})
+	return nil
+}
+
+func provideRouter(app *App) error {
+	router, err := server.New(app.Config, app.Logger, app.UserService, app.Audit, app.Sessions, app.Quota, app.RateLimit, app.SecurityEvents, app.JWTKeys, app.Lifecycle.Health)
+	if err != nil {
+		return err
+	}
+	app.Router = router
+	return nil
+}
@@ -0,0 +1,106 @@
+package bootstrap
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/config"
+)
+
+// minSecretLength is the shortest JWT secret accepted in production.
+const minSecretLength = 32
+
+// weakSecrets are placeholder values that commonly leak from examples into deployments.
+var weakSecrets = map[string]bool{
+	"secret": true, "changeme": true, "change-me": true, "your-secret-key": true, "dev": true, "test": true,
+}
+
+// ProductionIssues returns the insecure settings found in cfg. It is only meaningful for
+// production deployments; development defaults intentionally trip most of these checks.
+func ProductionIssues(cfg *config.Config) []string {
+	var issues []string
+
+	secret := strings.TrimSpace(cfg.JWTSecret)
+	switch {
+	case secret == "":
+		issues = append(issues, "JWT_SECRET is not set")
+	case weakSecrets[strings.ToLower(secret)]:
+		issues = append(issues, "JWT_SECRET is a well-known placeholder value")
+	case len(secret) < minSecretLength:
+		issues = append(issues, fmt.Sprintf("JWT_SECRET is shorter than %d characters", minSecretLength))
+	}
+
+	if cfg.Templates.Reload {
+		issues = append(issues, "TEMPLATES_RELOAD=true re-reads templates from disk on every render")
+	}
+	if cfg.Notifications.DryRun {
+		issues = append(issues, "NOTIFICATIONS_DRY_RUN=true: no emails or SMS will be delivered")
+	}
+
+	if len(cfg.Server.CORSAllowedOrigins) == 0 {
+		issues = append(issues, "CORS_ALLOWED_ORIGINS is not set")
+	}
+	for _, o := range cfg.Server.CORSAllowedOrigins {
+		if strings.TrimSpace(o) == "*" {
+			issues = append(issues, "CORS_ALLOWED_ORIGINS allows any origin (*)")
+			break
+		}
+	}
+
+	if u, err := url.Parse(cfg.Server.PublicURL); cfg.Server.PublicURL == "" || err != nil || u.Scheme != "https" {
+		issues = append(issues, "PUBLIC_URL must be an https:// URL (TLS terminated in front of the API)")
+	}
+	if strings.Contains(cfg.Database.URL, "sslmode=disable") {
+		issues = append(issues, "DATABASE_URL disables TLS (sslmode=disable)")
+	}
+
+	if strings.EqualFold(strings.TrimSpace(cfg.SMTP.Encryption), "none") {
+		issues = append(issues, "SMTP_ENCRYPTION=none sends email credentials and content unencrypted")
+	}
+
+	if k := cfg.OAuthTokens.EncryptionKey; k != "" && len(k) < minSecretLength {
+		issues = append(issues, fmt.Sprintf("OAUTH_TOKEN_ENCRYPTION_KEY is shorter than %d characters", minSecretLength))
+	}
+
+	if cfg.Debug.PayloadCapture {
+		issues = append(issues, "DEBUG_PAYLOAD_CAPTURE=true keeps request and response bodies in memory and the logs")
+	}
+	// /debug/vars is only mounted with a metrics secret; a guessable one makes it public.
+	if k := strings.TrimSpace(cfg.Debug.MetricsSecret); k != "" && (weakSecrets[strings.ToLower(k)] || len(k) < minSecretLength) {
+		issues = append(issues, fmt.Sprintf("DEBUG_METRICS_SECRET is a placeholder or shorter than %d characters: /debug/vars (memstats, command line, counters) is guessable", minSecretLength))
+	}
+
+	if cfg.SIEM.Endpoint != "" && cfg.SIEM.Secret == "" {
+		issues = append(issues, "SIEM_ENDPOINT is set without SIEM_SECRET: security events are sent unsigned")
+	}
+
+	return issues
+}
+
+// enforceProductionGuardrails refuses to start a production deployment with insecure settings,
+// unless ALLOW_INSECURE_PRODUCTION=true, in which case every issue is logged as an error.
+func enforceProductionGuardrails(cfg *config.Config, log *slog.Logger) error {
+	if cfg.Server.Env != "production" {
+		return nil
+	}
+	issues := ProductionIssues(cfg)
+	if len(issues) == 0 {
+		return nil
+	}
+
+	if cfg.Server.AllowInsecureProduction {
+		for _, issue := range issues {
+			log.Error("INSECURE PRODUCTION CONFIGURATION", "issue", issue)
+		}
+		return nil
+	}
+
+	errs := make([]error, len(issues))
+	for i, issue := range issues {
+		errs[i] = errors.New(issue)
+	}
+	return fmt.Errorf("refusing to start with insecure production configuration (set ALLOW_INSECURE_PRODUCTION=true to override): %w", errors.Join(errs...))
+}
@@ -0,0 +1,243 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/runbook"
+)
+
+// Starter is implemented by components that need to run work after construction
+// (e.g., start a worker loop). Start must not block.
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// Stopper is implemented by components that hold resources to release on shutdown.
+type Stopper interface {
+	Stop(ctx context.Context) error
+}
+
+// HealthChecker is implemented by components that can report readiness (e.g., ping a database).
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// Hooks adapts plain functions to the lifecycle interfaces. Nil funcs are no-ops.
+type Hooks struct {
+	OnStart  func(ctx context.Context) error
+	OnStop   func(ctx context.Context) error
+	OnHealth func(ctx context.Context) error
+}
+
+func (h Hooks) Start(ctx context.Context) error {
+	if h.OnStart == nil {
+		return nil
+	}
+	return h.OnStart(ctx)
+}
+
+func (h Hooks) Stop(ctx context.Context) error {
+	if h.OnStop == nil {
+		return nil
+	}
+	return h.OnStop(ctx)
+}
+
+func (h Hooks) HealthCheck(ctx context.Context) error {
+	if h.OnHealth == nil {
+		return nil
+	}
+	return h.OnHealth(ctx)
+}
+
+type component struct {
+	name        string
+	value       any
+	stopTimeout time.Duration
+}
+
+// Option customizes how a registered component is managed.
+type Option func(*component)
+
+// WithStopTimeout bounds how long the component may take to stop, overriding
+// Container.StopTimeout (e.g., longer for workers draining in-flight jobs).
+func WithStopTimeout(d time.Duration) Option {
+	return func(c *component) { c.stopTimeout = d }
+}
+
+// Container starts registered components in registration order and stops them in reverse.
+// Before a component starts, it must report healthy (when it implements HealthChecker),
+// so dependents are never started on top of an unavailable dependency.
+type Container struct {
+	log *slog.Logger
+
+	// HealthAttempts and HealthDelay control how long Start waits for a component to become healthy.
+	HealthAttempts int
+	HealthDelay    time.Duration
+
+	// StopTimeout bounds each component's Stop unless overridden with WithStopTimeout.
+	// A component that overruns is abandoned (logged and reported) so the remaining
+	// components still get their turn; the overall Stop context still applies.
+	StopTimeout time.Duration
+
+	mu         sync.Mutex
+	components []component
+	started    int
+}
+
+// NewContainer creates an empty lifecycle container.
+func NewContainer(log *slog.Logger) *Container {
+	return &Container{
+		log:            log,
+		HealthAttempts: 5,
+		HealthDelay:    2 * time.Second,
+		StopTimeout:    10 * time.Second,
+	}
+}
+
+// Register adds a component. It may implement any of Starter, Stopper, HealthChecker and
+// runbook.Reporter; components implementing none are accepted (and ignored) to keep wiring
+// uniform.
+func (c *Container) Register(name string, value any, opts ...Option) {
+	comp := component{name: name, value: value}
+	for _, opt := range opts {
+		opt(&comp)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.components = append(c.components, comp)
+}
+
+// Start waits for each component to be healthy and starts it, in registration order.
+// On failure, the components already started are stopped in reverse order.
+func (c *Container) Start(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, comp := range c.components {
+		if hc, ok := comp.value.(HealthChecker); ok {
+			if err := c.waitHealthy(ctx, comp.name, hc); err != nil {
+				c.stopFrom(ctx, i-1)
+				return err
+			}
+		}
+		if s, ok := comp.value.(Starter); ok {
+			if err := s.Start(ctx); err != nil {
+				c.stopFrom(ctx, i-1)
+				return fmt.Errorf("start %s: %w", comp.name, err)
+			}
+		}
+		c.started = i + 1
+		c.log.Info("component ready", "component", comp.name)
+	}
+	return nil
+}
+
+// Stop stops all started components in reverse registration order and returns
+// the joined errors of any that failed.
+func (c *Container) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stopFrom(ctx, c.started-1)
+}
+
+// Health reports the health of every component implementing HealthChecker.
+// A nil value means healthy.
+func (c *Container) Health(ctx context.Context) map[string]error {
+	c.mu.Lock()
+	comps := append([]component(nil), c.components...)
+	c.mu.Unlock()
+
+	out := make(map[string]error)
+	for _, comp := range comps {
+		if hc, ok := comp.value.(HealthChecker); ok {
+			out[comp.name] = hc.HealthCheck(ctx)
+		}
+	}
+	return out
+}
+
+// Runbook reports the status of every component implementing runbook.Reporter.
+func (c *Container) Runbook(ctx context.Context) map[string]runbook.Status {
+	c.mu.Lock()
+	comps := append([]component(nil), c.components...)
+	c.mu.Unlock()
+
+	out := make(map[string]runbook.Status)
+	for _, comp := range comps {
+		if r, ok := comp.value.(runbook.Reporter); ok {
+			out[comp.name] = r.Status(ctx)
+		}
+	}
+	return out
+}
+
+func (c *Container) waitHealthy(ctx context.Context, name string, hc HealthChecker) error {
+	attempts := c.HealthAttempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = hc.HealthCheck(ctx); err == nil {
+			return nil
+		}
+		c.log.Warn("component not healthy yet", "component", name, "attempt", i+1, "error", err)
+		if i < attempts-1 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.HealthDelay):
+			}
+		}
+	}
+	return fmt.Errorf("component %s not healthy: %w", name, err)
+}
+
+func (c *Container) stopFrom(ctx context.Context, last int) error {
+	var errs []error
+	for i := last; i >= 0; i-- {
+		comp := c.components[i]
+		s, ok := comp.value.(Stopper)
+		if !ok {
+			continue
+		}
+		if err := c.stopOne(ctx, comp, s); err != nil {
+			errs = append(errs, fmt.Errorf("stop %s: %w", comp.name, err))
+		}
+	}
+	c.started = 0
+	return errors.Join(errs...)
+}
+
+// stopOne stops a single component within its stop timeout and logs the outcome.
+func (c *Container) stopOne(ctx context.Context, comp component, s Stopper) error {
+	timeout := comp.stopTimeout
+	if timeout <= 0 {
+		timeout = c.StopTimeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	c.log.Info("stopping component", "component", comp.name, "timeout", timeout.String())
+	start := time.Now()
+	err := s.Stop(ctx)
+	elapsed := time.Since(start).String()
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		c.log.Error("component stop timed out; in-flight work may be lost", "component", comp.name, "duration", elapsed)
+	case err != nil:
+		c.log.Error("component stop failed", "component", comp.name, "error", err, "duration", elapsed)
+	default:
+		c.log.Info("component stopped", "component", comp.name, "duration", elapsed)
+	}
+	return err
+}
@@ -0,0 +1,72 @@
+// Package buildinfo exposes the version, commit, and build time of the running binary.
+//
+// Values are injected at build time via ldflags, e.g.:
+//
+//	go build -ldflags "-X github.com/delordemm1/go-api-simple-starter/internal/buildinfo.Version=v1.4.0 \
+//	  -X github.com/delordemm1/go-api-simple-starter/internal/buildinfo.Commit=$(git rev-parse --short HEAD) \
+//	  -X github.com/delordemm1/go-api-simple-starter/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/api
+//
+// When they are not set, the VCS stamp recorded by the Go toolchain is used instead.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// Set via -ldflags "-X ...". Left empty, they fall back to the embedded VCS information.
+var (
+	Version   = ""
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info describes the running build.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"buildTime"`
+	GoVersion string `json:"goVersion"`
+}
+
+var (
+	once sync.Once
+	info Info
+)
+
+// Get returns the build information, resolving fallbacks once.
+func Get() Info {
+	once.Do(func() {
+		info = Info{Version: Version, Commit: Commit, BuildTime: BuildTime, GoVersion: runtime.Version()}
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			if info.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+				info.Version = bi.Main.Version
+			}
+			for _, s := range bi.Settings {
+				switch {
+				case s.Key == "vcs.revision" && info.Commit == "":
+					info.Commit = s.Value
+				case s.Key == "vcs.time" && info.BuildTime == "":
+					info.BuildTime = s.Value
+				}
+			}
+		}
+		if info.Version == "" {
+			info.Version = "dev"
+		}
+		if info.Commit == "" {
+			info.Commit = "unknown"
+		}
+		if len(info.Commit) > 12 {
+			info.Commit = info.Commit[:12]
+		}
+	})
+	return info
+}
+
+// LogAttrs returns the build fields as slog key/value pairs, for logger.With.
+func LogAttrs() []any {
+	i := Get()
+	return []any{"version", i.Version, "commit", i.Commit}
+}
@@ -0,0 +1,22 @@
+package cache
+
+import "strings"
+
+// Namespace prefixes shared-infrastructure keys (Redis keys, session tokens, notification
+// records) so several environments, regions, or blue/green deploys can share one Redis and
+// one database without colliding. The zero value adds no prefix.
+type Namespace string
+
+// Key joins parts with ":" and prefixes the namespace, e.g. "prod-eu:quota:42:1700000000".
+func (n Namespace) Key(parts ...string) string {
+	key := strings.Join(parts, ":")
+	if n == "" {
+		return key
+	}
+	return string(n) + ":" + key
+}
+
+// String returns the namespace itself.
+func (n Namespace) String() string {
+	return string(n)
+}
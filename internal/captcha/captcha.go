@@ -0,0 +1,211 @@
+// Package captcha verifies CAPTCHA tokens solved by clients, so public endpoints (registration,
+// login, password reset) can turn away scripted requests. Verifiers are pluggable: Cloudflare
+// Turnstile, hCaptcha and Google reCAPTCHA share the same siteverify protocol.
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/metrics"
+)
+
+// ErrRejected is returned when the provider reports the token as invalid, expired, reused or
+// (reCAPTCHA v3) scored below the minimum. Other errors mean the token could not be checked.
+var ErrRejected = errors.New("captcha: token rejected")
+
+// verifications counts siteverify calls, labelled by provider and outcome (passed / rejected / error).
+var verifications = metrics.NewCounter("captcha_verifications")
+
+// Verifier checks a client's CAPTCHA token. remoteIP is passed to the provider when known.
+// Implementations must be safe for concurrent use.
+type Verifier interface {
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// Provider names a siteverify service.
+type Provider string
+
+const (
+	ProviderNone      Provider = "none"
+	ProviderTurnstile Provider = "turnstile"
+	ProviderHCaptcha  Provider = "hcaptcha"
+	ProviderReCAPTCHA Provider = "recaptcha"
+)
+
+// ParseProvider validates a provider name; "" means ProviderNone.
+func ParseProvider(s string) (Provider, error) {
+	switch p := Provider(strings.ToLower(strings.TrimSpace(s))); p {
+	case "":
+		return ProviderNone, nil
+	case ProviderNone, ProviderTurnstile, ProviderHCaptcha, ProviderReCAPTCHA:
+		return p, nil
+	default:
+		return "", fmt.Errorf("captcha: unknown provider %q (want none, turnstile, hcaptcha, or recaptcha)", s)
+	}
+}
+
+// Endpoints that can require a CAPTCHA.
+const (
+	EndpointRegister       = "register"
+	EndpointLogin          = "login"
+	EndpointForgotPassword = "forgot_password"
+)
+
+// ParseEndpoints validates a list of endpoint names.
+func ParseEndpoints(names []string) (map[string]bool, error) {
+	out := make(map[string]bool, len(names))
+	for _, n := range names {
+		switch n = strings.ToLower(strings.TrimSpace(n)); n {
+		case "":
+		case EndpointRegister, EndpointLogin, EndpointForgotPassword:
+			out[n] = true
+		default:
+			return nil, fmt.Errorf("captcha: unknown endpoint %q (want register, login, or forgot_password)", n)
+		}
+	}
+	return out, nil
+}
+
+// Policy is the verifier and the endpoints that require it.
+type Policy struct {
+	Verifier  Verifier
+	Endpoints map[string]bool
+}
+
+// Required reports whether endpoint must present a CAPTCHA token. A nil Policy requires none.
+func (p *Policy) Required(endpoint string) bool {
+	return p != nil && p.Verifier != nil && p.Endpoints[endpoint]
+}
+
+// Default siteverify URLs.
+const (
+	DefaultTurnstileEndpoint = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+	DefaultHCaptchaEndpoint  = "https://api.hcaptcha.com/siteverify"
+	DefaultReCAPTCHAEndpoint = "https://www.google.com/recaptcha/api/siteverify"
+)
+
+// Config configures a siteverify verifier.
+type Config struct {
+	Secret string
+	// Endpoint is the siteverify URL. Default: the provider's public endpoint.
+	Endpoint string
+	// Hostname, when set, must match the hostname the provider says the token was solved on.
+	Hostname string
+	// MinScore rejects reCAPTCHA v3 tokens scoring below it (0-1). 0 disables the check;
+	// tokens without a score (v2, Turnstile) are unaffected.
+	MinScore float64
+	// Timeout bounds each verification. Default: 5s.
+	Timeout time.Duration
+}
+
+// New returns the verifier for provider, or nil for ProviderNone.
+func New(provider Provider, cfg Config) (Verifier, error) {
+	var endpoint string
+	switch provider {
+	case ProviderNone, "":
+		return nil, nil
+	case ProviderTurnstile:
+		endpoint = DefaultTurnstileEndpoint
+	case ProviderHCaptcha:
+		endpoint = DefaultHCaptchaEndpoint
+	case ProviderReCAPTCHA:
+		endpoint = DefaultReCAPTCHAEndpoint
+	default:
+		return nil, fmt.Errorf("captcha: unknown provider %q", provider)
+	}
+	if cfg.Secret == "" {
+		return nil, fmt.Errorf("captcha: %s requires a secret", provider)
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = endpoint
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	return &siteVerify{name: string(provider), cfg: cfg, http: &http.Client{Timeout: cfg.Timeout}}, nil
+}
+
+// NewTurnstile returns a Cloudflare Turnstile verifier.
+func NewTurnstile(cfg Config) (Verifier, error) { return New(ProviderTurnstile, cfg) }
+
+// NewHCaptcha returns an hCaptcha verifier.
+func NewHCaptcha(cfg Config) (Verifier, error) { return New(ProviderHCaptcha, cfg) }
+
+// NewReCAPTCHA returns a Google reCAPTCHA (v2 or v3) verifier.
+func NewReCAPTCHA(cfg Config) (Verifier, error) { return New(ProviderReCAPTCHA, cfg) }
+
+// siteVerify implements the form-encoded siteverify protocol all three providers share.
+type siteVerify struct {
+	name string
+	cfg  Config
+	http *http.Client
+}
+
+type siteVerifyResponse struct {
+	Success    bool     `json:"success"`
+	Hostname   string   `json:"hostname"`
+	Score      *float64 `json:"score"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+func (v *siteVerify) Verify(ctx context.Context, token, remoteIP string) error {
+	if strings.TrimSpace(token) == "" {
+		verifications.Inc(v.name, "rejected")
+		return fmt.Errorf("%w: missing token", ErrRejected)
+	}
+
+	res, err := v.call(ctx, token, remoteIP)
+	if err != nil {
+		verifications.Inc(v.name, "error")
+		return err
+	}
+
+	switch {
+	case !res.Success:
+		err = fmt.Errorf("%w: %s", ErrRejected, strings.Join(res.ErrorCodes, ","))
+	case v.cfg.Hostname != "" && !strings.EqualFold(res.Hostname, v.cfg.Hostname):
+		err = fmt.Errorf("%w: solved on %q", ErrRejected, res.Hostname)
+	case v.cfg.MinScore > 0 && res.Score != nil && *res.Score < v.cfg.MinScore:
+		err = fmt.Errorf("%w: score %.2f", ErrRejected, *res.Score)
+	}
+	if err != nil {
+		verifications.Inc(v.name, "rejected")
+		return err
+	}
+	verifications.Inc(v.name, "passed")
+	return nil
+}
+
+func (v *siteVerify) call(ctx context.Context, token, remoteIP string) (*siteVerifyResponse, error) {
+	form := url.Values{"secret": {v.cfg.Secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.cfg.Endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("captcha: %s request: %w", v.name, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("captcha: %s siteverify: %w", v.name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("captcha: %s siteverify: unexpected status %d", v.name, resp.StatusCode)
+	}
+
+	var out siteVerifyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("captcha: %s siteverify: decode: %w", v.name, err)
+	}
+	return &out, nil
+}
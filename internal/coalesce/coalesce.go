@@ -0,0 +1,66 @@
+// Package coalesce collapses concurrent identical reads into a single backend call
+// (singleflight), so a burst of requests for the same key after a cache miss or expiry
+// hits the database or upstream once.
+package coalesce
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/metrics"
+	"golang.org/x/sync/singleflight"
+)
+
+// calls counts coalesced reads, labelled by group name and role (leader, shared).
+var calls = metrics.NewCounter("coalesce_calls")
+
+// Group coalesces calls by key. The zero value is not usable; create groups with NewGroup,
+// typically as package-level or service-level fields.
+type Group[V any] struct {
+	name string
+	g    singleflight.Group
+}
+
+// NewGroup creates a group; name labels its metrics (e.g., "user_profile").
+func NewGroup[V any](name string) *Group[V] {
+	return &Group[V]{name: name}
+}
+
+// Do returns the result of fn for key, sharing one in-flight call among concurrent callers.
+// fn runs with a context detached from any single caller's cancellation, so one client
+// disconnecting does not fail the others; each caller still stops waiting when its own
+// ctx is done. Callers share the returned value and must treat it as read-only.
+func (g *Group[V]) Do(ctx context.Context, key string, fn func(ctx context.Context) (V, error)) (V, error) {
+	ch := g.g.DoChan(key, func() (v any, err error) {
+		defer func() {
+			// Surface panics as errors instead of crashing every waiter's request.
+			if r := recover(); r != nil {
+				err = fmt.Errorf("coalesce %s: panic: %v", g.name, r)
+			}
+		}()
+		return fn(contextx.Detach(ctx))
+	})
+
+	var zero V
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case res := <-ch:
+		role := "leader"
+		if res.Shared {
+			role = "shared"
+		}
+		calls.Inc(g.name, role)
+		if res.Err != nil {
+			return zero, res.Err
+		}
+		return res.Val.(V), nil
+	}
+}
+
+// Forget drops the in-flight call for key, so the next Do starts a fresh one
+// (e.g., after a write that makes the pending result stale).
+func (g *Group[V]) Forget(key string) {
+	g.g.Forget(key)
+}
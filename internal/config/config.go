@@ -12,22 +12,88 @@ import (
 
 // Config holds all the configuration for the application.
 type Config struct {
-	Server       ServerConfig       `mapstructure:"server"`
-	Database     DatabaseConfig     `mapstructure:"database"`
-	Redis        RedisConfig        `mapstructure:"redis"`
-	Google       GoogleConfig       `mapstructure:"google"`
-	Apple        AppleConfig        `mapstructure:"apple"`
-	SMTP         SMTPConfig         `mapstructure:"smtp"`
-	Templates    TemplatesConfig    `mapstructure:"templates"`
-	Verification VerificationConfig `mapstructure:"verification"`
-	ResetToken   ResetTokenConfig   `mapstructure:"reset_token"`
-	JWTSecret    string             `mapstructure:"jwt_secret" env:"JWT_SECRET"`
+	Server          ServerConfig          `mapstructure:"server"`
+	Database        DatabaseConfig        `mapstructure:"database"`
+	Redis           RedisConfig           `mapstructure:"redis"`
+	Google          GoogleConfig          `mapstructure:"google"`
+	Apple           AppleConfig           `mapstructure:"apple"`
+	Microsoft       MicrosoftConfig       `mapstructure:"microsoft"`
+	OIDC            OIDCConfig            `mapstructure:"oidc"`
+	OAuthTokens     OAuthTokensConfig     `mapstructure:"oauth_tokens"`
+	SMTP            SMTPConfig            `mapstructure:"smtp"`
+	Templates       TemplatesConfig       `mapstructure:"templates"`
+	Verification    VerificationConfig    `mapstructure:"verification"`
+	ResetToken      ResetTokenConfig      `mapstructure:"reset_token"`
+	VerifyLink      VerifyLinkConfig      `mapstructure:"verify_link"`
+	SignInAlert     SignInAlertConfig     `mapstructure:"signin_alert"`
+	Accounts        AccountsConfig        `mapstructure:"accounts"`
+	Sessions        SessionsConfig        `mapstructure:"sessions"`
+	Retention       RetentionConfig       `mapstructure:"retention"`
+	BotDetection    BotDetectionConfig    `mapstructure:"bot_detection"`
+	IPReputation    IPReputationConfig    `mapstructure:"ip_reputation"`
+	Captcha         CaptchaConfig         `mapstructure:"captcha"`
+	Alerts          AlertsConfig          `mapstructure:"alerts"`
+	Notifications   NotificationsConfig   `mapstructure:"notifications"`
+	Debug           DebugConfig           `mapstructure:"debug"`
+	SIEM            SIEMConfig            `mapstructure:"siem"`
+	Quota           QuotaConfig           `mapstructure:"quota"`
+	RateLimit       RateLimitConfig       `mapstructure:"rate_limit"`
+	Jobs            JobsConfig            `mapstructure:"jobs"`
+	Onboarding      OnboardingConfig      `mapstructure:"onboarding"`
+	Shutdown        ShutdownConfig        `mapstructure:"shutdown"`
+	Modules         ModulesConfig         `mapstructure:"modules"`
+	Admin           AdminConfig           `mapstructure:"admin"`
+	Terms           TermsConfig           `mapstructure:"terms"`
+	PasswordBreach  PasswordBreachConfig  `mapstructure:"password_breach"`
+	PasswordHistory PasswordHistoryConfig `mapstructure:"password_history"`
+	PasswordHash    PasswordHashConfig    `mapstructure:"password_hash"`
+	JWTSecret       string                `mapstructure:"jwt_secret" env:"JWT_SECRET"`
+	// JWTPreviousSecrets (comma-separated) still verify JWT access tokens during a JWT_SECRET rotation.
+	JWTPreviousSecrets []string `mapstructure:"jwt_previous_secrets" env:"JWT_PREVIOUS_SECRETS"`
 }
 
 type GoogleConfig struct {
 	ClientID     string `mapstructure:"client_id" env:"GOOGLE_CLIENT_ID"`
 	ClientSecret string `mapstructure:"client_secret" env:"GOOGLE_CLIENT_SECRET"`
 	RedirectURL  string `mapstructure:"redirect_url" env:"GOOGLE_REDIRECT_URL"`
+	// ExtraScopes (space-separated) are requested on top of email and profile, for apps that
+	// call Google APIs with the stored tokens (see OAuthTokensConfig).
+	ExtraScopes string `mapstructure:"extra_scopes" env:"GOOGLE_EXTRA_SCOPES"`
+}
+
+// OAuthTokensConfig controls storage of provider access/refresh tokens on linked accounts.
+// Tokens are kept only when EncryptionKey is set; they are sealed with AES-256-GCM under it.
+// PreviousEncryptionKeys (comma-separated) still decrypt tokens during a key rotation.
+type OAuthTokensConfig struct {
+	EncryptionKey          string   `mapstructure:"encryption_key" env:"OAUTH_TOKEN_ENCRYPTION_KEY"`
+	PreviousEncryptionKeys []string `mapstructure:"previous_encryption_keys" env:"OAUTH_TOKEN_PREVIOUS_ENCRYPTION_KEYS"`
+}
+
+// MicrosoftConfig configures sign-in with Microsoft (Azure AD / Entra ID). TenantID is a
+// directory ID or domain for single-tenant apps, or "common", "organizations", or "consumers".
+type MicrosoftConfig struct {
+	ClientID     string `mapstructure:"client_id" env:"MICROSOFT_CLIENT_ID"`
+	ClientSecret string `mapstructure:"client_secret" env:"MICROSOFT_CLIENT_SECRET"`
+	TenantID     string `mapstructure:"tenant_id" env:"MICROSOFT_TENANT_ID"`
+	RedirectURL  string `mapstructure:"redirect_url" env:"MICROSOFT_REDIRECT_URL"`
+}
+
+// OIDCConfig configures the generic OpenID Connect provider ("oidc"), e.g. Keycloak, Auth0, or
+// Okta. It is enabled when IssuerURL is set; endpoints and keys come from the issuer's
+// discovery document. The *Claim fields name the id_token (or userinfo) claims to read and
+// may be dotted paths into nested claims.
+type OIDCConfig struct {
+	IssuerURL    string `mapstructure:"issuer_url" env:"OIDC_ISSUER_URL"`
+	ClientID     string `mapstructure:"client_id" env:"OIDC_CLIENT_ID"`
+	ClientSecret string `mapstructure:"client_secret" env:"OIDC_CLIENT_SECRET"`
+	RedirectURL  string `mapstructure:"redirect_url" env:"OIDC_REDIRECT_URL"`
+	Scopes       string `mapstructure:"scopes" env:"OIDC_SCOPES"` // space-separated
+	SubjectClaim string `mapstructure:"subject_claim" env:"OIDC_SUBJECT_CLAIM"`
+	EmailClaim   string `mapstructure:"email_claim" env:"OIDC_EMAIL_CLAIM"`
+	NameClaim    string `mapstructure:"name_claim" env:"OIDC_NAME_CLAIM"`
+	// EmailVerifiedClaim asserts the email is verified (true or "true"); when it is absent the
+	// email is treated as unverified and confirmed with a code.
+	EmailVerifiedClaim string `mapstructure:"email_verified_claim" env:"OIDC_EMAIL_VERIFIED_CLAIM"`
 }
 
 type AppleConfig struct {
@@ -39,9 +105,37 @@ type AppleConfig struct {
 }
 
 // ServerConfig holds the server configuration.
+// PublicURL is the externally visible base URL (https in production).
+// CORSAllowedOrigins is a comma-separated list of browser origins allowed to call the API.
+// AllowInsecureProduction downgrades the production guardrails from a startup failure to error logs.
+// TrustedProxies lists the CIDR ranges (or addresses) of reverse proxies whose X-Forwarded-For and
+// X-Real-IP headers are believed; empty means the connecting address is always the client.
 type ServerConfig struct {
-	Port string `mapstructure:"port"`
-	Env  string `mapstructure:"env"`
+	Port                    string   `mapstructure:"port"`
+	Env                     string   `mapstructure:"env"`
+	PublicURL               string   `mapstructure:"public_url" env:"PUBLIC_URL"`
+	CORSAllowedOrigins      []string `mapstructure:"cors_allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+	AllowInsecureProduction bool     `mapstructure:"allow_insecure_production" env:"ALLOW_INSECURE_PRODUCTION"`
+	TrustedProxies          []string `mapstructure:"trusted_proxies" env:"TRUSTED_PROXIES"`
+	// Region identifies the deployment region (e.g. "eu-west-1"). Combined with Env it forms
+	// the default key namespace.
+	Region string `mapstructure:"region" env:"REGION"`
+	// KeyNamespace overrides the namespace applied to Redis keys, session tokens, and
+	// notification records, e.g. "prod-eu-blue" for one side of a blue/green deploy.
+	KeyNamespace string `mapstructure:"key_namespace" env:"KEY_NAMESPACE"`
+}
+
+// Namespace returns the key namespace: KeyNamespace when set, otherwise "<env>-<region>"
+// when a region is configured. It is empty by default so existing keys and session
+// tokens remain valid for single-environment deployments.
+func (c ServerConfig) Namespace() string {
+	if c.KeyNamespace != "" {
+		return c.KeyNamespace
+	}
+	if c.Region != "" {
+		return c.Env + "-" + c.Region
+	}
+	return ""
 }
 
 // DatabaseConfig holds the database configuration.
@@ -56,25 +150,392 @@ type RedisConfig struct {
 
 type SMTPConfig struct {
 	From     string `mapstructure:"from" env:"SMTP_FROM"`
+	FromName string `mapstructure:"from_name" env:"SMTP_FROM_NAME"`
+	ReplyTo  string `mapstructure:"reply_to" env:"SMTP_REPLY_TO"`
 	Password string `mapstructure:"password" env:"SMTP_PASSWORD"`
 	Username string `mapstructure:"username" env:"SMTP_USERNAME"`
 	Port     int    `mapstructure:"port" env:"SMTP_PORT"`
 	Host     string `mapstructure:"host" env:"SMTP_HOST"`
+	// Encryption is starttls (default), tls (implicit TLS, usually port 465; "ssl" is an
+	// alias), or none for local development servers.
+	Encryption string `mapstructure:"encryption" env:"SMTP_ENCRYPTION"`
+	// Auth is the authentication mechanism: auto (default), plain, login, cram-md5, or none.
+	Auth string `mapstructure:"auth" env:"SMTP_AUTH"`
+	// HeloName is the hostname sent in EHLO/HELO. Default: localhost.
+	HeloName string `mapstructure:"helo_name" env:"SMTP_HELO_NAME"`
 }
 
+// TemplatesConfig selects where notification templates are loaded from. Source is "embedded"
+// (default), "disk" (Dir), "s3" (the S3 fields) or "database" (the notification_templates table);
+// S3 and database hold only the templates uploaded by admins and fall back to the embedded ones.
+// Cached templates are revalidated with the source every RefreshSeconds (0: never).
 type TemplatesConfig struct {
-	Dir    string `mapstructure:"dir" env:"EMAIL_TEMPLATES_DIR"`
-	Reload bool   `mapstructure:"reload" env:"TEMPLATES_RELOAD"`
+	Source            string `mapstructure:"source" env:"TEMPLATES_SOURCE"`
+	Dir               string `mapstructure:"dir" env:"EMAIL_TEMPLATES_DIR"`
+	Reload            bool   `mapstructure:"reload" env:"TEMPLATES_RELOAD"`
+	RefreshSeconds    int    `mapstructure:"refresh_seconds" env:"TEMPLATES_REFRESH_SECONDS"`
+	S3Bucket          string `mapstructure:"s3_bucket" env:"TEMPLATES_S3_BUCKET"`
+	S3Prefix          string `mapstructure:"s3_prefix" env:"TEMPLATES_S3_PREFIX"`
+	S3Region          string `mapstructure:"s3_region" env:"TEMPLATES_S3_REGION"`
+	S3Endpoint        string `mapstructure:"s3_endpoint" env:"TEMPLATES_S3_ENDPOINT"`
+	S3AccessKeyID     string `mapstructure:"s3_access_key_id" env:"TEMPLATES_S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey string `mapstructure:"s3_secret_access_key" env:"TEMPLATES_S3_SECRET_ACCESS_KEY"`
+	S3SessionToken    string `mapstructure:"s3_session_token" env:"TEMPLATES_S3_SESSION_TOKEN"`
 }
 
+// VerificationConfig holds the global OTP policy. Purposes overrides it per verification
+// purpose (e.g., "password_reset"); zero fields in an override fall back to the global values.
+// Overrides are read from VERIFICATION_<PURPOSE>_<FIELD> (e.g., VERIFICATION_PASSWORD_RESET_TTL_MINUTES)
+// for the purposes listed in verificationPurposes.
+// CodeAlphabet is "numeric" (digits) or "base32" (Crockford base32, case-insensitive).
 type VerificationConfig struct {
-	TTLMinutes            int `mapstructure:"ttl_minutes" env:"VERIFICATION_TTL_MINUTES"`
-	ResendCooldownSeconds int `mapstructure:"resend_cooldown_seconds" env:"VERIFICATION_RESEND_COOLDOWN_SECONDS"`
-	MaxAttempts           int `mapstructure:"max_attempts" env:"VERIFICATION_MAX_ATTEMPTS"`
+	TTLMinutes            int                           `mapstructure:"ttl_minutes" env:"VERIFICATION_TTL_MINUTES"`
+	ResendCooldownSeconds int                           `mapstructure:"resend_cooldown_seconds" env:"VERIFICATION_RESEND_COOLDOWN_SECONDS"`
+	MaxAttempts           int                           `mapstructure:"max_attempts" env:"VERIFICATION_MAX_ATTEMPTS"`
+	CodeLength            int                           `mapstructure:"code_length" env:"VERIFICATION_CODE_LENGTH"`
+	CodeAlphabet          string                        `mapstructure:"code_alphabet" env:"VERIFICATION_CODE_ALPHABET"`
+	Purposes              map[string]VerificationPolicy `mapstructure:"purposes"`
+}
+
+// VerificationPolicy is the OTP policy for a single purpose.
+type VerificationPolicy struct {
+	TTLMinutes            int    `mapstructure:"ttl_minutes"`
+	ResendCooldownSeconds int    `mapstructure:"resend_cooldown_seconds"`
+	MaxAttempts           int    `mapstructure:"max_attempts"`
+	CodeLength            int    `mapstructure:"code_length"`
+	CodeAlphabet          string `mapstructure:"code_alphabet"`
 }
 
+// For returns the effective policy for purpose, falling back to the global values.
+func (c VerificationConfig) For(purpose string) VerificationPolicy {
+	p := c.Purposes[purpose]
+	if p.TTLMinutes <= 0 {
+		p.TTLMinutes = c.TTLMinutes
+	}
+	if p.ResendCooldownSeconds <= 0 {
+		p.ResendCooldownSeconds = c.ResendCooldownSeconds
+	}
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = c.MaxAttempts
+	}
+	if p.CodeLength <= 0 {
+		p.CodeLength = c.CodeLength
+	}
+	if p.CodeAlphabet == "" {
+		p.CodeAlphabet = c.CodeAlphabet
+	}
+	return p
+}
+
+// verificationPurposes lists the purposes whose per-purpose env overrides are bound.
+var verificationPurposes = []string{"email_verify", "password_reset", "account_restore", "phone_login", "email_change", "login_step_up"}
+
+// ResetTokenConfig controls the action token that authorizes FinalizePasswordReset.
+// When LinkTemplate is set (e.g., "https://app.example.com/reset-password?token={token}"
+// or "myapp://reset?token={token}"), the reset email also carries a deep link with a
+// token, so clients can finalize the reset without verifying the code first.
+// Placeholders: {token} (required) and {email}, both URL-escaped.
 type ResetTokenConfig struct {
-	TTLMinutes int `mapstructure:"ttl_minutes" env:"RESET_TOKEN_TTL_MINUTES"`
+	TTLMinutes   int    `mapstructure:"ttl_minutes" env:"RESET_TOKEN_TTL_MINUTES"`
+	LinkTemplate string `mapstructure:"link_template" env:"PASSWORD_RESET_LINK_TEMPLATE"`
+}
+
+// VerifyLinkConfig controls the verification link sent alongside the email verification code.
+// When RedirectURL is set (e.g., "https://app.example.com/email-verified"), the email also links
+// to GET <PUBLIC_URL>/users/verify-email?token=..., which verifies the address with a token valid for
+// TTLMinutes (until a newer one is sent) and redirects the browser to RedirectURL with a status query
+// parameter: "verified", "invalid" (unknown, replaced or expired token) or "error".
+type VerifyLinkConfig struct {
+	RedirectURL string `mapstructure:"redirect_url" env:"VERIFY_EMAIL_REDIRECT_URL"`
+	TTLMinutes  int    `mapstructure:"ttl_minutes" env:"VERIFY_EMAIL_LINK_TTL_MINUTES"`
+}
+
+// SignInAlertConfig controls the "new sign-in detected" email sent when a login comes from a
+// user agent and IP address pair the user has not signed in from before (the first login of
+// an account is not reported). When RevokeLinkTemplate is set (e.g.,
+// "https://app.example.com/sessions/revoke?token={token}"), the email links to it with a
+// single-use token for POST /users/sessions/revoke, which signs out every session without
+// signing in. Placeholder: {token} (required), URL-escaped.
+type SignInAlertConfig struct {
+	Enabled             bool   `mapstructure:"enabled" env:"SIGNIN_ALERT_ENABLED"`
+	RevokeLinkTemplate  string `mapstructure:"revoke_link_template" env:"SIGNIN_ALERT_REVOKE_LINK_TEMPLATE"`
+	RevokeTokenTTLHours int    `mapstructure:"revoke_token_ttl_hours" env:"SIGNIN_ALERT_REVOKE_TOKEN_TTL_HOURS"`
+}
+
+// AccountsConfig controls account lifecycle policies.
+// DeletionGraceDays is how long a soft-deleted account can still be restored.
+// The cleanup job (opt-in via CleanupEnabled) deletes accounts left unverified for
+// UnverifiedDeleteDays, emails users inactive for InactiveMonths, and anonymizes them
+// AnonymizeAfterDays later if they do not come back. A zero threshold disables that step.
+// UnverifiedLoginGraceDays lets users with an unverified email sign in to a restricted session
+// for that many days after signup; 0 blocks their login with ErrEmailNotVerified.
+// RegistrationIdempotencyMinutes is how long a registration retried with the same email and
+// password gets the same pending user back without another verification email; 0 disables it.
+type AccountsConfig struct {
+	DeletionGraceDays        int  `mapstructure:"deletion_grace_days" env:"ACCOUNT_DELETION_GRACE_DAYS"`
+	CleanupEnabled           bool `mapstructure:"cleanup_enabled" env:"ACCOUNT_CLEANUP_ENABLED"`
+	CleanupIntervalMinutes   int  `mapstructure:"cleanup_interval_minutes" env:"ACCOUNT_CLEANUP_INTERVAL_MINUTES"`
+	UnverifiedDeleteDays     int  `mapstructure:"unverified_delete_days" env:"ACCOUNT_UNVERIFIED_DELETE_DAYS"`
+	InactiveMonths           int  `mapstructure:"inactive_months" env:"ACCOUNT_INACTIVE_MONTHS"`
+	AnonymizeAfterDays       int  `mapstructure:"anonymize_after_days" env:"ACCOUNT_ANONYMIZE_AFTER_DAYS"`
+	UnverifiedLoginGraceDays int  `mapstructure:"unverified_login_grace_days" env:"ACCOUNT_UNVERIFIED_LOGIN_GRACE_DAYS"`
+	// LoginFailureMinMilliseconds pads failed password logins to at least this duration, so an
+	// unknown email cannot be told from a wrong password by timing. 0 disables padding.
+	LoginFailureMinMilliseconds int `mapstructure:"login_failure_min_milliseconds" env:"ACCOUNT_LOGIN_FAILURE_MIN_MILLISECONDS"`
+
+	RegistrationIdempotencyMinutes int `mapstructure:"registration_idempotency_minutes" env:"ACCOUNT_REGISTRATION_IDEMPOTENCY_MINUTES"`
+}
+
+// RetentionConfig sets how many days records are kept before the retention job deletes them
+// (0 keeps them forever): AuditDays for the account lifecycle audit trail, ActivityDays for the
+// activity timeline (login history; each user's latest login is always kept), DeliveryDays for
+// the notification delivery log, and VerificationCodeDays for expired one-time codes (a
+// backstop for when ExpiredIntervalMinutes is 0). The job runs every IntervalMinutes and
+// deletes at most BatchSize rows per statement. Expired action tokens, OAuth states and
+// one-time codes are deleted every ExpiredIntervalMinutes (0 disables).
+type RetentionConfig struct {
+	AuditDays              int `mapstructure:"audit_days" env:"RETENTION_AUDIT_DAYS"`
+	ActivityDays           int `mapstructure:"activity_days" env:"RETENTION_ACTIVITY_DAYS"`
+	DeliveryDays           int `mapstructure:"delivery_days" env:"RETENTION_DELIVERY_DAYS"`
+	VerificationCodeDays   int `mapstructure:"verification_code_days" env:"RETENTION_VERIFICATION_CODE_DAYS"`
+	IntervalMinutes        int `mapstructure:"interval_minutes" env:"RETENTION_INTERVAL_MINUTES"`
+	BatchSize              int `mapstructure:"batch_size" env:"RETENTION_BATCH_SIZE"`
+	ExpiredIntervalMinutes int `mapstructure:"expired_interval_minutes" env:"RETENTION_EXPIRED_INTERVAL_MINUTES"`
+}
+
+// Enabled reports whether any retention policy is set.
+func (c RetentionConfig) Enabled() bool {
+	return c.AuditDays > 0 || c.ActivityDays > 0 || c.DeliveryDays > 0 || c.VerificationCodeDays > 0
+}
+
+// SessionsConfig controls session storage, tokens and background session maintenance.
+// Backend is where sessions are stored: "postgres" (user_active_sessions), "redis", or "memory"
+// (in-process, for tests and local demos).
+// GCIntervalMinutes is how often expired sessions are purged (0 disables); GCBatchSize bounds each delete.
+// TokenBytes is the entropy of new session tokens (minimum 16); changing it signs everyone out.
+// Binding is what happens when a session is used from a client other than the one it was created
+// on (client family and IP network): "off", "log", "step_up" (password re-entry), or "reject".
+// SlidingTTLHours and AbsoluteTTLHours are the idle timeout and maximum lifetime of sessions;
+// logins with rememberMe get the RememberMe* lifetimes instead. Each session keeps the lifetimes
+// it was created with. ElevationMinutes is how long a session may call sensitive operations
+// (email change, account deletion) after login or re-entering the password.
+// LocalCacheSeconds keeps validated sessions in each instance's memory for that long (0 disables);
+// revocations are broadcast to the other instances over Redis pub/sub.
+// TokenFormat is what clients receive: "opaque" tokens, or "jwt" access tokens signed with keys
+// derived from JWT_SECRET and published at /.well-known/jwks.json.
+type SessionsConfig struct {
+	Backend                    string `mapstructure:"backend" env:"SESSION_BACKEND"`
+	GCIntervalMinutes          int    `mapstructure:"gc_interval_minutes" env:"SESSION_GC_INTERVAL_MINUTES"`
+	GCBatchSize                int    `mapstructure:"gc_batch_size" env:"SESSION_GC_BATCH_SIZE"`
+	TokenBytes                 int    `mapstructure:"token_bytes" env:"SESSION_TOKEN_BYTES"`
+	Binding                    string `mapstructure:"binding" env:"SESSION_BINDING"`
+	SlidingTTLHours            int    `mapstructure:"sliding_ttl_hours" env:"SESSION_SLIDING_TTL_HOURS"`
+	AbsoluteTTLHours           int    `mapstructure:"absolute_ttl_hours" env:"SESSION_ABSOLUTE_TTL_HOURS"`
+	RememberMeSlidingTTLHours  int    `mapstructure:"remember_me_sliding_ttl_hours" env:"SESSION_REMEMBER_ME_SLIDING_TTL_HOURS"`
+	RememberMeAbsoluteTTLHours int    `mapstructure:"remember_me_absolute_ttl_hours" env:"SESSION_REMEMBER_ME_ABSOLUTE_TTL_HOURS"`
+	ElevationMinutes           int    `mapstructure:"elevation_minutes" env:"SESSION_ELEVATION_MINUTES"`
+	LocalCacheSeconds          int    `mapstructure:"local_cache_seconds" env:"SESSION_LOCAL_CACHE_SECONDS"`
+	TokenFormat                string `mapstructure:"token_format" env:"SESSION_TOKEN_FORMAT"`
+	// Issuance guardrail: a user or client IP creating more sessions per minute than allowed is
+	// refused new sessions for IssuanceBlockMinutes. 0 disables a limit. IssuanceGlobalAlertPerMinute
+	// raises an alert when that many sessions are created in one minute across all instances.
+	IssuancePerUserPerMinute     int64 `mapstructure:"issuance_per_user_per_minute" env:"SESSION_ISSUANCE_PER_USER_PER_MINUTE"`
+	IssuancePerIPPerMinute       int64 `mapstructure:"issuance_per_ip_per_minute" env:"SESSION_ISSUANCE_PER_IP_PER_MINUTE"`
+	IssuanceBlockMinutes         int   `mapstructure:"issuance_block_minutes" env:"SESSION_ISSUANCE_BLOCK_MINUTES"`
+	IssuanceGlobalAlertPerMinute int64 `mapstructure:"issuance_global_alert_per_minute" env:"SESSION_ISSUANCE_GLOBAL_ALERT_PER_MINUTE"`
+}
+
+// QuotaConfig controls the soft per-user request quota reported in RateLimit-* headers
+// and GET /users/me/usage. RequestsPerWindow <= 0 disables tracking.
+type QuotaConfig struct {
+	RequestsPerWindow int64 `mapstructure:"requests_per_window" env:"QUOTA_REQUESTS_PER_WINDOW"`
+	WindowSeconds     int   `mapstructure:"window_seconds" env:"QUOTA_WINDOW_SECONDS"`
+}
+
+// RateLimitConfig controls the hard limits on login, registration and password-reset routes.
+// Each rule is "<limit>/<window>" (e.g. "10/1m"); an empty rule is not enforced. Per-IP rules
+// count by client IP, per-account rules by the submitted email.
+type RateLimitConfig struct {
+	Enabled                 bool   `mapstructure:"enabled" env:"RATE_LIMIT_ENABLED"`
+	LoginPerIP              string `mapstructure:"login_per_ip" env:"RATE_LIMIT_LOGIN_PER_IP"`
+	LoginPerAccount         string `mapstructure:"login_per_account" env:"RATE_LIMIT_LOGIN_PER_ACCOUNT"`
+	RegisterPerIP           string `mapstructure:"register_per_ip" env:"RATE_LIMIT_REGISTER_PER_IP"`
+	PasswordResetPerIP      string `mapstructure:"password_reset_per_ip" env:"RATE_LIMIT_PASSWORD_RESET_PER_IP"`
+	PasswordResetPerAccount string `mapstructure:"password_reset_per_account" env:"RATE_LIMIT_PASSWORD_RESET_PER_ACCOUNT"`
+	PhonePerIP              string `mapstructure:"phone_per_ip" env:"RATE_LIMIT_PHONE_PER_IP"`
+	PhonePerAccount         string `mapstructure:"phone_per_account" env:"RATE_LIMIT_PHONE_PER_ACCOUNT"`
+}
+
+// JobsConfig selects and tunes the background jobs queue. Backend is "memory" (in-process,
+// lost on restart) or "redis" (Redis Streams, shared by all instances).
+type JobsConfig struct {
+	Backend             string `mapstructure:"backend" env:"JOBS_BACKEND"`
+	Concurrency         int    `mapstructure:"concurrency" env:"JOBS_CONCURRENCY"`
+	MaxAttempts         int    `mapstructure:"max_attempts" env:"JOBS_MAX_ATTEMPTS"`
+	RetryBackoffSeconds int    `mapstructure:"retry_backoff_seconds" env:"JOBS_RETRY_BACKOFF_SECONDS"`
+	ClaimIdleSeconds    int    `mapstructure:"claim_idle_seconds" env:"JOBS_CLAIM_IDLE_SECONDS"`
+}
+
+// OnboardingConfig defines the email sequence sent after signup as a comma-separated list of
+// <template id>@<delay>, e.g. "user.welcome@0h,user.onboarding_tips@24h,user.onboarding_reengage@7d".
+// Delays are Go durations counted from signup, plus a "d" (days) suffix. Empty disables it.
+type OnboardingConfig struct {
+	Sequence string `mapstructure:"sequence" env:"ONBOARDING_SEQUENCE"`
+}
+
+// PasswordBreachConfig controls the HaveIBeenPwned range check applied to new passwords
+// (registration and password reset). Passwords seen in at least MinCount breaches are rejected.
+type PasswordBreachConfig struct {
+	Enabled        bool   `mapstructure:"enabled" env:"PASSWORD_BREACH_CHECK_ENABLED"`
+	Endpoint       string `mapstructure:"endpoint" env:"PASSWORD_BREACH_ENDPOINT"`
+	MinCount       int    `mapstructure:"min_count" env:"PASSWORD_BREACH_MIN_COUNT"`
+	TimeoutSeconds int    `mapstructure:"timeout_seconds" env:"PASSWORD_BREACH_TIMEOUT_SECONDS"`
+}
+
+// PasswordHistoryConfig controls password reuse prevention: a new password may not match any
+// of the user's last Size passwords (including the current one). Size <= 0 disables the check.
+type PasswordHistoryConfig struct {
+	Size int `mapstructure:"size" env:"PASSWORD_HISTORY_SIZE"`
+}
+
+// PasswordHashConfig selects how new passwords are hashed: Algorithm is "argon2id" (default) or
+// "bcrypt". Stored hashes of either algorithm keep working, and hashes not made with the current
+// algorithm and parameters are replaced on the user's next successful login.
+type PasswordHashConfig struct {
+	Algorithm         string `mapstructure:"algorithm" env:"PASSWORD_HASH_ALGORITHM"`
+	BcryptCost        int    `mapstructure:"bcrypt_cost" env:"PASSWORD_HASH_BCRYPT_COST"`
+	Argon2MemoryKiB   uint32 `mapstructure:"argon2_memory_kib" env:"PASSWORD_HASH_ARGON2_MEMORY_KIB"`
+	Argon2Iterations  uint32 `mapstructure:"argon2_iterations" env:"PASSWORD_HASH_ARGON2_ITERATIONS"`
+	Argon2Parallelism uint8  `mapstructure:"argon2_parallelism" env:"PASSWORD_HASH_ARGON2_PARALLELISM"`
+}
+
+// AdminConfig bootstraps administrators. Every user in Emails (comma-separated) is promoted
+// to the admin role at startup once their email is verified; later starts are no-ops for
+// users who already are admins. Removing an email does not demote the user.
+// ImpersonationMinutes is the lifetime of admin impersonation sessions; 0 disables impersonation.
+type AdminConfig struct {
+	Emails               []string `mapstructure:"emails" env:"ADMIN_EMAILS"`
+	ImpersonationMinutes int      `mapstructure:"impersonation_minutes" env:"ADMIN_IMPERSONATION_MINUTES"`
+}
+
+// TermsConfig names the terms-of-service version users must have accepted. Registration
+// records it as accepted; once it changes, users who accepted an older version (or none) get
+// ErrTermsAcceptanceRequired until they accept the new one. Empty disables the check.
+type TermsConfig struct {
+	CurrentVersion string `mapstructure:"current_version" env:"TERMS_CURRENT_VERSION"`
+}
+
+// ModulesConfig switches whole route modules off, e.g. MODULES_DISABLED=admin for a
+// public-only deployment. Disabled modules register neither routes nor OpenAPI entries.
+type ModulesConfig struct {
+	Disabled []string `mapstructure:"disabled" env:"MODULES_DISABLED"`
+}
+
+// Enabled reports whether the named module should be mounted.
+func (c ModulesConfig) Enabled(name string) bool {
+	for _, d := range c.Disabled {
+		if strings.EqualFold(strings.TrimSpace(d), name) {
+			return false
+		}
+	}
+	return true
+}
+
+// ShutdownConfig bounds graceful shutdown. TimeoutSeconds covers the whole shutdown (HTTP
+// drain plus every component); ComponentTimeoutSeconds bounds each component's Stop, and
+// NotificationsTimeoutSeconds overrides it for in-flight notification deliveries (retries included).
+type ShutdownConfig struct {
+	TimeoutSeconds              int `mapstructure:"timeout_seconds" env:"SHUTDOWN_TIMEOUT_SECONDS"`
+	ComponentTimeoutSeconds     int `mapstructure:"component_timeout_seconds" env:"SHUTDOWN_COMPONENT_TIMEOUT_SECONDS"`
+	NotificationsTimeoutSeconds int `mapstructure:"notifications_timeout_seconds" env:"SHUTDOWN_NOTIFICATIONS_TIMEOUT_SECONDS"`
+}
+
+// SIEMConfig controls streaming of security events (logins, failed logins, session
+// revocations, admin actions) to an external SIEM. An empty Endpoint disables streaming.
+// Endpoint is an https:// URL or a syslog+tcp://, syslog+tls:// or syslog+udp:// address;
+// Secret signs deliveries with HMAC-SHA256.
+type SIEMConfig struct {
+	Endpoint             string `mapstructure:"endpoint" env:"SIEM_ENDPOINT"`
+	Secret               string `mapstructure:"secret" env:"SIEM_SECRET"`
+	BatchSize            int    `mapstructure:"batch_size" env:"SIEM_BATCH_SIZE"`
+	FlushIntervalSeconds int    `mapstructure:"flush_interval_seconds" env:"SIEM_FLUSH_INTERVAL_SECONDS"`
+	QueueSize            int    `mapstructure:"queue_size" env:"SIEM_QUEUE_SIZE"`
+}
+
+// DebugConfig controls opt-in debugging aids. Payload capture records sanitized request and
+// response bodies for a sample of requests (PayloadSampleRate, 0..1) or for requests carrying
+// the X-Debug-Capture header set to PayloadSecret; captures are logged at debug level and kept
+// in a ring buffer served at /debug/payloads (same header required).
+type DebugConfig struct {
+	PayloadCapture      bool    `mapstructure:"payload_capture" env:"DEBUG_PAYLOAD_CAPTURE"`
+	PayloadSampleRate   float64 `mapstructure:"payload_sample_rate" env:"DEBUG_PAYLOAD_SAMPLE_RATE"`
+	PayloadSecret       string  `mapstructure:"payload_secret" env:"DEBUG_PAYLOAD_SECRET"`
+	PayloadMaxBodyBytes int     `mapstructure:"payload_max_body_bytes" env:"DEBUG_PAYLOAD_MAX_BODY_BYTES"`
+	PayloadBufferSize   int     `mapstructure:"payload_buffer_size" env:"DEBUG_PAYLOAD_BUFFER_SIZE"`
+	// MetricsSecret guards GET /debug/vars (Authorization: Bearer <secret>); empty leaves it unmounted.
+	MetricsSecret string `mapstructure:"metrics_secret" env:"DEBUG_METRICS_SECRET"`
+}
+
+// IPReputationConfig controls the IP reputation check on registration and login. Provider
+// selects the threat feed ("none" or "abuseipdb"); Denylist entries (IPs or CIDRs) are always
+// consulted. An IP scoring at least MinScore (0-100) triggers the endpoint's action: "off",
+// "monitor" (log only), "step_up", or "block". Lookups that fail let the request through.
+type IPReputationConfig struct {
+	Provider        string   `mapstructure:"provider" env:"IP_REPUTATION_PROVIDER"`
+	Denylist        []string `mapstructure:"denylist" env:"IP_REPUTATION_DENYLIST"`
+	AbuseIPDBAPIKey string   `mapstructure:"abuseipdb_api_key" env:"IP_REPUTATION_ABUSEIPDB_API_KEY"`
+	MinScore        int      `mapstructure:"min_score" env:"IP_REPUTATION_MIN_SCORE"`
+	TimeoutSeconds  int      `mapstructure:"timeout_seconds" env:"IP_REPUTATION_TIMEOUT_SECONDS"`
+	CacheMinutes    int      `mapstructure:"cache_minutes" env:"IP_REPUTATION_CACHE_MINUTES"`
+	RegisterAction  string   `mapstructure:"register_action" env:"IP_REPUTATION_REGISTER_ACTION"`
+	LoginAction     string   `mapstructure:"login_action" env:"IP_REPUTATION_LOGIN_ACTION"`
+}
+
+// CaptchaConfig controls CAPTCHA verification on public auth endpoints. Provider selects the
+// siteverify service ("none", "turnstile", "hcaptcha" or "recaptcha"); Endpoints lists which of
+// "register", "login" and "forgot_password" require a solved token. MinScore (0-1) applies to
+// reCAPTCHA v3 only. Hostname, when set, must match the site the token was solved on.
+type CaptchaConfig struct {
+	Provider       string   `mapstructure:"provider" env:"CAPTCHA_PROVIDER"`
+	Secret         string   `mapstructure:"secret" env:"CAPTCHA_SECRET"`
+	Endpoints      []string `mapstructure:"endpoints" env:"CAPTCHA_ENDPOINTS"`
+	MinScore       float64  `mapstructure:"min_score" env:"CAPTCHA_MIN_SCORE"`
+	Hostname       string   `mapstructure:"hostname" env:"CAPTCHA_HOSTNAME"`
+	TimeoutSeconds int      `mapstructure:"timeout_seconds" env:"CAPTCHA_TIMEOUT_SECONDS"`
+}
+
+// BotDetectionConfig controls the lightweight registration bot deterrents.
+// HoneypotEnabled rejects registrations whose hidden honeypot field is filled in.
+// MinFormSeconds rejects registrations submitted faster than this after the form was rendered (0 disables).
+type BotDetectionConfig struct {
+	HoneypotEnabled bool `mapstructure:"honeypot_enabled" env:"BOT_HONEYPOT_ENABLED"`
+	MinFormSeconds  int  `mapstructure:"min_form_seconds" env:"BOT_MIN_FORM_SECONDS"`
+}
+
+// NotificationsConfig controls notification delivery.
+// DryRun renders and records notifications in the outbox but never calls providers.
+// Email and SMS dispatches are tried MaxAttempts times (backoff starting at
+// RetryBackoffSeconds, doubling) before they are moved to the dead-letter table.
+type NotificationsConfig struct {
+	DryRun              bool `mapstructure:"dry_run" env:"NOTIFICATIONS_DRY_RUN"`
+	MaxAttempts         int  `mapstructure:"max_attempts" env:"NOTIFICATIONS_MAX_ATTEMPTS"`
+	RetryBackoffSeconds int  `mapstructure:"retry_backoff_seconds" env:"NOTIFICATIONS_RETRY_BACKOFF_SECONDS"`
+}
+
+// AlertsConfig controls error-spike alerting on problem responses.
+// An alert fires when InternalErrorThreshold ErrInternal problems occur within WindowSeconds (0 disables).
+//
+// Login and OTP failure ratios are tracked over AuthWindowSeconds, per instance and globally.
+// An alert fires when the failure ratio reaches AuthFailureRatio (0 disables) in a window of
+// at least AuthMinAttempts attempts.
+type AlertsConfig struct {
+	InternalErrorThreshold int `mapstructure:"internal_error_threshold" env:"ALERT_INTERNAL_ERROR_THRESHOLD"`
+	WindowSeconds          int `mapstructure:"window_seconds" env:"ALERT_WINDOW_SECONDS"`
+
+	AuthFailureRatio    float64 `mapstructure:"auth_failure_ratio" env:"ALERT_AUTH_FAILURE_RATIO"`
+	AuthMinAttempts     int     `mapstructure:"auth_min_attempts" env:"ALERT_AUTH_MIN_ATTEMPTS"`
+	AuthWindowSeconds   int     `mapstructure:"auth_window_seconds" env:"ALERT_AUTH_WINDOW_SECONDS"`
+	AuthIntervalSeconds int     `mapstructure:"auth_interval_seconds" env:"ALERT_AUTH_INTERVAL_SECONDS"`
 }
 
 // --- Helpers for auto-binding env vars ---
@@ -121,6 +582,10 @@ func bindEnvsFromStruct(prefix string, t reflect.Type) {
 			bindEnvsFromStruct(key, ft)
 			continue
 		}
+		if ft.Kind() == reflect.Map {
+			// Map entries have dynamic keys; they are bound explicitly in Load.
+			continue
+		}
 
 		envName := f.Tag.Get("env")
 		if envName == "" {
@@ -148,15 +613,156 @@ func Load() *Config {
 	viper.SetDefault("server.env", "development")
 	viper.SetDefault("smtp.port", 587)
 	viper.SetDefault("templates.reload", false)
+	viper.SetDefault("templates.source", "embedded")
+	viper.SetDefault("templates.refresh_seconds", 60)
+	viper.SetDefault("templates.s3_prefix", "templates/")
+
+	// Microsoft sign-in accepts any account type unless narrowed to a tenant
+	viper.SetDefault("microsoft.tenant_id", "common")
+
+	// Generic OIDC provider claim mapping defaults (standard claims)
+	viper.SetDefault("oidc.scopes", "openid email profile")
+	viper.SetDefault("oidc.subject_claim", "sub")
+	viper.SetDefault("oidc.email_claim", "email")
+	viper.SetDefault("oidc.name_claim", "name")
+	viper.SetDefault("oidc.email_verified_claim", "email_verified")
 
 	// Verification & Reset token defaults
 	viper.SetDefault("verification.ttl_minutes", 10)
 	viper.SetDefault("verification.resend_cooldown_seconds", 60)
 	viper.SetDefault("verification.max_attempts", 5)
+	viper.SetDefault("verification.code_length", 6)
+	viper.SetDefault("verification.code_alphabet", "numeric")
+	// SMS sign-in codes are always typed on a phone keypad: 6 digits unless overridden
+	viper.SetDefault("verification.purposes.phone_login.code_length", 6)
+	viper.SetDefault("verification.purposes.phone_login.code_alphabet", "numeric")
 	viper.SetDefault("reset_token.ttl_minutes", 15)
+	viper.SetDefault("verify_link.ttl_minutes", 1440)
+	viper.SetDefault("signin_alert.enabled", true)
+	viper.SetDefault("signin_alert.revoke_token_ttl_hours", 72)
+
+	// Account lifecycle defaults
+	viper.SetDefault("accounts.deletion_grace_days", 30)
+	viper.SetDefault("accounts.cleanup_enabled", false)
+	viper.SetDefault("accounts.cleanup_interval_minutes", 60)
+	viper.SetDefault("accounts.unverified_delete_days", 7)
+	viper.SetDefault("accounts.inactive_months", 12)
+	viper.SetDefault("accounts.anonymize_after_days", 30)
+	viper.SetDefault("accounts.unverified_login_grace_days", 0)
+	viper.SetDefault("accounts.login_failure_min_milliseconds", 300)
+	viper.SetDefault("accounts.registration_idempotency_minutes", 10)
+	viper.SetDefault("retention.audit_days", 0)
+	viper.SetDefault("retention.activity_days", 0)
+	viper.SetDefault("retention.delivery_days", 0)
+	viper.SetDefault("retention.verification_code_days", 30)
+	viper.SetDefault("retention.interval_minutes", 60)
+	viper.SetDefault("retention.batch_size", 1000)
+	viper.SetDefault("retention.expired_interval_minutes", 15)
+
+	// Session maintenance defaults
+	viper.SetDefault("sessions.backend", "postgres")
+	viper.SetDefault("sessions.gc_interval_minutes", 15)
+	viper.SetDefault("sessions.gc_batch_size", 1000)
+	viper.SetDefault("sessions.token_bytes", 32)
+	viper.SetDefault("sessions.binding", "off")
+	viper.SetDefault("sessions.sliding_ttl_hours", 7*24)
+	viper.SetDefault("sessions.absolute_ttl_hours", 30*24)
+	viper.SetDefault("sessions.remember_me_sliding_ttl_hours", 30*24)
+	viper.SetDefault("sessions.remember_me_absolute_ttl_hours", 90*24)
+	viper.SetDefault("sessions.elevation_minutes", 10)
+	viper.SetDefault("sessions.local_cache_seconds", 0)
+	viper.SetDefault("sessions.token_format", "opaque")
+	viper.SetDefault("sessions.issuance_per_user_per_minute", 20)
+	viper.SetDefault("sessions.issuance_per_ip_per_minute", 60)
+	viper.SetDefault("sessions.issuance_block_minutes", 15)
+	viper.SetDefault("sessions.issuance_global_alert_per_minute", 0)
+	viper.SetDefault("admin.impersonation_minutes", 30)
+
+	// Registration bot detection defaults (disabled)
+	viper.SetDefault("bot_detection.honeypot_enabled", false)
+	viper.SetDefault("bot_detection.min_form_seconds", 0)
+
+	// Notification defaults
+	viper.SetDefault("notifications.dry_run", false)
+	viper.SetDefault("notifications.max_attempts", 3)
+	viper.SetDefault("notifications.retry_backoff_seconds", 2)
+
+	// Soft quota defaults (1000 requests per hour)
+	viper.SetDefault("quota.requests_per_window", 1000)
+	viper.SetDefault("quota.window_seconds", 3600)
+
+	// Auth rate limits
+	viper.SetDefault("rate_limit.enabled", true)
+	viper.SetDefault("rate_limit.login_per_ip", "20/1m")
+	viper.SetDefault("rate_limit.login_per_account", "10/15m")
+	viper.SetDefault("rate_limit.register_per_ip", "10/1h")
+	viper.SetDefault("rate_limit.password_reset_per_ip", "20/1h")
+	viper.SetDefault("rate_limit.password_reset_per_account", "5/1h")
+	viper.SetDefault("rate_limit.phone_per_ip", "20/1h")
+	viper.SetDefault("rate_limit.phone_per_account", "5/15m")
+
+	// Background jobs: in-process by default
+	viper.SetDefault("jobs.backend", "memory")
+	viper.SetDefault("jobs.concurrency", 4)
+	viper.SetDefault("jobs.max_attempts", 5)
+	viper.SetDefault("jobs.retry_backoff_seconds", 5)
+	viper.SetDefault("jobs.claim_idle_seconds", 300)
+
+	// Breached-password check defaults (disabled)
+	viper.SetDefault("password_breach.enabled", false)
+	viper.SetDefault("password_breach.min_count", 1)
+	viper.SetDefault("password_breach.timeout_seconds", 3)
+	viper.SetDefault("password_history.size", 5)
+	viper.SetDefault("password_hash.algorithm", "argon2id")
+	viper.SetDefault("password_hash.bcrypt_cost", 10)
+	viper.SetDefault("password_hash.argon2_memory_kib", 19456)
+	viper.SetDefault("password_hash.argon2_iterations", 2)
+	viper.SetDefault("password_hash.argon2_parallelism", 1)
+
+	// Graceful shutdown defaults
+	viper.SetDefault("shutdown.timeout_seconds", 30)
+	viper.SetDefault("shutdown.component_timeout_seconds", 10)
+	viper.SetDefault("shutdown.notifications_timeout_seconds", 20)
+
+	// SIEM streaming defaults (disabled until SIEM_ENDPOINT is set)
+	viper.SetDefault("siem.batch_size", 100)
+	viper.SetDefault("siem.flush_interval_seconds", 5)
+	viper.SetDefault("siem.queue_size", 10000)
+
+	// Debug payload capture defaults (disabled)
+	viper.SetDefault("debug.payload_capture", false)
+	viper.SetDefault("debug.payload_sample_rate", 0.0)
+	viper.SetDefault("debug.payload_max_body_bytes", 4096)
+	viper.SetDefault("debug.payload_buffer_size", 100)
+
+	viper.SetDefault("ip_reputation.provider", "none")
+	viper.SetDefault("ip_reputation.min_score", 75)
+	viper.SetDefault("ip_reputation.timeout_seconds", 2)
+	viper.SetDefault("ip_reputation.cache_minutes", 60)
+	viper.SetDefault("ip_reputation.register_action", "block")
+	viper.SetDefault("ip_reputation.login_action", "step_up")
+
+	viper.SetDefault("captcha.provider", "none")
+	viper.SetDefault("captcha.endpoints", []string{"register", "login", "forgot_password"})
+	viper.SetDefault("captcha.min_score", 0.5)
+	viper.SetDefault("captcha.timeout_seconds", 5)
+
+	// Error alerting defaults
+	viper.SetDefault("alerts.internal_error_threshold", 20)
+	viper.SetDefault("alerts.window_seconds", 60)
+	viper.SetDefault("alerts.auth_failure_ratio", 0.5)
+	viper.SetDefault("alerts.auth_min_attempts", 50)
+	viper.SetDefault("alerts.auth_window_seconds", 300)
+	viper.SetDefault("alerts.auth_interval_seconds", 30)
 
 	// Auto-bind env vars for all config leaves
 	bindEnvsFromStruct("", reflect.TypeOf(Config{}))
+	for _, purpose := range verificationPurposes {
+		for _, field := range []string{"ttl_minutes", "resend_cooldown_seconds", "max_attempts", "code_length", "code_alphabet"} {
+			key := "verification.purposes." + purpose + "." + field
+			_ = viper.BindEnv(key, strings.ToUpper("verification_"+purpose+"_"+field))
+		}
+	}
 
 	// Unmarshal configuration into our struct
 	var cfg Config
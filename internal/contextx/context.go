@@ -1,5 +1,7 @@
 package contextx
 
+import "context"
+
 // Key is a private type to avoid collisions in request context keys.
 type Key string
 
@@ -7,4 +9,47 @@ type Key string
 const UserIDKey Key = "userID"
 
 // SessionIDKey is the context key used to store the current session ID (string).
-const SessionIDKey Key = "sessionID"
\ No newline at end of file
+const SessionIDKey Key = "sessionID"
+
+// SessionScopeKey is the context key used to store the current session's scope (string; "" = unrestricted).
+const SessionScopeKey Key = "sessionScope"
+
+// SessionElevatedKey is the context key used to store whether the current session recently
+// authenticated (bool), i.e. may call operations guarded by middleware.RequireRecentAuth.
+const SessionElevatedKey Key = "sessionElevated"
+
+// ImpersonatorIDKey is the context key used to store the ID of the admin impersonating the
+// authenticated user (string; "" outside impersonation sessions).
+const ImpersonatorIDKey Key = "impersonatorID"
+
+// ClientIPKey is the context key used to store the caller's IP address (string).
+const ClientIPKey Key = "clientIP"
+
+// UserAgentKey is the context key used to store the caller's User-Agent header (string).
+const UserAgentKey Key = "userAgent"
+
+// ClientIP returns the caller's IP address stored by the client info middleware, or "".
+func ClientIP(ctx context.Context) string {
+	v, _ := ctx.Value(ClientIPKey).(string)
+	return v
+}
+
+// UserAgent returns the caller's User-Agent stored by the client info middleware, or "".
+func UserAgent(ctx context.Context) string {
+	v, _ := ctx.Value(UserAgentKey).(string)
+	return v
+}
+
+// ImpersonatorID returns the admin impersonating the authenticated user, or "".
+func ImpersonatorID(ctx context.Context) string {
+	v, _ := ctx.Value(ImpersonatorIDKey).(string)
+	return v
+}
+
+// Detach returns a context carrying ctx's values (request ID, client info, session) that is
+// never cancelled and has no deadline. Use it for work that outlives the request: fire-and-forget
+// goroutines started by a handler would otherwise be aborted as soon as the response is
+// written. Work started this way must bound itself (a timeout, or the component's Stop).
+func Detach(ctx context.Context) context.Context {
+	return context.WithoutCancel(ctx)
+}
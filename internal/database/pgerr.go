@@ -0,0 +1,41 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SQLSTATE codes for integrity constraint violations.
+// See https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	NotNullViolation    = "23502"
+	ForeignKeyViolation = "23503"
+	UniqueViolation     = "23505"
+	CheckViolation      = "23514"
+)
+
+// AsPgError returns the *pgconn.PgError wrapped by err, if any.
+func AsPgError(err error) (*pgconn.PgError, bool) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr, true
+	}
+	return nil, false
+}
+
+// IsUniqueViolation reports whether err is a unique constraint violation.
+// When constraint is non-empty, the violated constraint name must also match.
+func IsUniqueViolation(err error, constraint string) bool {
+	pgErr, ok := AsPgError(err)
+	if !ok || pgErr.Code != UniqueViolation {
+		return false
+	}
+	return constraint == "" || pgErr.ConstraintName == constraint
+}
+
+// IsForeignKeyViolation reports whether err is a foreign key violation.
+func IsForeignKeyViolation(err error) bool {
+	pgErr, ok := AsPgError(err)
+	return ok && pgErr.Code == ForeignKeyViolation
+}
@@ -0,0 +1,96 @@
+package e2etest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+// Client is a small JSON client for the server under test. Failures to build or send a
+// request fail the test; status codes are left to the caller.
+type Client struct {
+	tb    testing.TB
+	base  string
+	http  *http.Client
+	token string
+}
+
+// Response is a finished request with its body already read.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Decode unmarshals the JSON body into out, failing the test on error.
+func (r *Response) Decode(tb testing.TB, out any) {
+	tb.Helper()
+	if err := json.Unmarshal(r.Body, out); err != nil {
+		tb.Fatalf("e2etest: decode %s: %v", r.Body, err)
+	}
+}
+
+// WithToken returns a copy of the client that sends token as its Bearer session.
+func (c *Client) WithToken(token string) *Client {
+	cp := *c
+	cp.token = token
+	return &cp
+}
+
+// Token returns the session token the client authenticates with, if any.
+func (c *Client) Token() string {
+	return c.token
+}
+
+// Do sends method path with body encoded as JSON (nil for none) and returns the response.
+func (c *Client) Do(method, path string, body any) *Response {
+	return c.DoContentType(method, path, "application/json", body)
+}
+
+// DoContentType is Do with an explicit request content type, e.g. application/merge-patch+json.
+func (c *Client) DoContentType(method, path, contentType string, body any) *Response {
+	c.tb.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			c.tb.Fatalf("e2etest: encode %s %s: %v", method, path, err)
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequest(method, c.base+path, reader)
+	if err != nil {
+		c.tb.Fatalf("e2etest: build %s %s: %v", method, path, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		c.tb.Fatalf("e2etest: %s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.tb.Fatalf("e2etest: read %s %s: %v", method, path, err)
+	}
+	return &Response{Status: resp.StatusCode, Header: resp.Header, Body: raw}
+}
+
+// Expect sends the request like Do and fails the test unless the response has status.
+func (c *Client) Expect(status int, method, path string, body any) *Response {
+	c.tb.Helper()
+	resp := c.Do(method, path, body)
+	if resp.Status != status {
+		c.tb.Fatalf("e2etest: %s %s: got %d, want %d: %s", method, path, resp.Status, status, resp.Body)
+	}
+	return resp
+}
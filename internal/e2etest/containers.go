@@ -0,0 +1,72 @@
+package e2etest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	tcpostgres "github.com/testcontainers/testcontainers-go/modules/postgres"
+	tcredis "github.com/testcontainers/testcontainers-go/modules/redis"
+)
+
+// Images of the disposable databases started when no URLs are given.
+const (
+	postgresImage = "postgres:15-alpine"
+	redisImage    = "redis:7-alpine"
+)
+
+// startContainers runs throwaway Postgres and Redis containers for tb and returns their URLs.
+// They are removed by tb.Cleanup. The test is skipped when no Docker daemon is reachable.
+func startContainers(tb testing.TB) (databaseURL, redisURL string) {
+	tb.Helper()
+	if !dockerAvailable() {
+		tb.Skip("e2etest: no Docker daemon for the database containers; set E2E_DATABASE_URL and E2E_REDIS_URL to use existing ones")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	pg, err := tcpostgres.Run(ctx, postgresImage,
+		tcpostgres.WithDatabase("app"),
+		tcpostgres.WithUsername("app"),
+		tcpostgres.WithPassword("app"),
+		tcpostgres.BasicWaitStrategies(),
+	)
+	testcontainers.CleanupContainer(tb, pg)
+	if err != nil {
+		tb.Fatalf("e2etest: start postgres: %v", err)
+	}
+	databaseURL, err = pg.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		tb.Fatalf("e2etest: postgres url: %v", err)
+	}
+
+	rd, err := tcredis.Run(ctx, redisImage)
+	testcontainers.CleanupContainer(tb, rd)
+	if err != nil {
+		tb.Fatalf("e2etest: start redis: %v", err)
+	}
+	redisURL, err = rd.ConnectionString(ctx)
+	if err != nil {
+		tb.Fatalf("e2etest: redis url: %v", err)
+	}
+	return databaseURL, redisURL
+}
+
+// dockerAvailable reports whether testcontainers can reach a Docker daemon.
+func dockerAvailable() (ok bool) {
+	defer func() {
+		// testcontainers' Docker host lookup panics on some paths instead of returning an error.
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	provider, err := testcontainers.NewDockerProvider()
+	if err != nil {
+		return false
+	}
+	defer provider.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return provider.Health(ctx) == nil
+}
@@ -0,0 +1,175 @@
+// Package e2etest boots the whole API (bootstrap.New, migrations, lifecycle, router) on an
+// httptest server and drives it over HTTP, so complete user journeys can be exercised the
+// way a client would. Projects built on the starter reuse the Harness, Client, and Mailbox
+// for their own modules and call the journeys in journeys.go from their test files.
+//
+// Start runs disposable Postgres and Redis containers with testcontainers, so the journeys run
+// under a plain go test wherever Docker is available. E2E_DATABASE_URL and E2E_REDIS_URL
+// override them with existing instances (docker compose, a CI service). With neither Docker
+// nor the URLs, the test is skipped.
+package e2etest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/bootstrap"
+	"github.com/delordemm1/go-api-simple-starter/internal/config"
+	_ "github.com/jackc/pgx/v5/stdlib" // PostgreSQL driver for goose
+	"github.com/pressly/goose/v3"
+)
+
+// Options customise a Harness. The zero value reads URLs from the environment and starts
+// containers when they are not set.
+type Options struct {
+	// DatabaseURL defaults to E2E_DATABASE_URL, then to a Postgres container. The database is
+	// migrated to the latest version.
+	DatabaseURL string
+	// RedisURL defaults to E2E_REDIS_URL, then to a Redis container.
+	RedisURL string
+	// MigrationsDir defaults to the "migrations" directory of the enclosing module.
+	MigrationsDir string
+	// Configure, when set, adjusts the loaded configuration before the app is built,
+	// e.g. to enable a feature flag for one suite.
+	Configure func(cfg *config.Config)
+	// LogOutput receives the app's logs (JSON). Default: discarded.
+	LogOutput io.Writer
+}
+
+// Harness is a running API under test.
+type Harness struct {
+	App     *bootstrap.App
+	Server  *httptest.Server
+	Mailbox *Mailbox
+	tb      testing.TB
+}
+
+// Start starts the databases when needed, migrates the database, builds and starts the app with notifications in dry-run mode,
+// and serves it on a local httptest server. Everything is torn down by tb.Cleanup.
+func Start(tb testing.TB, opts Options) *Harness {
+	tb.Helper()
+
+	if opts.DatabaseURL == "" {
+		opts.DatabaseURL = os.Getenv("E2E_DATABASE_URL")
+	}
+	if opts.RedisURL == "" {
+		opts.RedisURL = os.Getenv("E2E_REDIS_URL")
+	}
+	if opts.DatabaseURL == "" || opts.RedisURL == "" {
+		databaseURL, redisURL := startContainers(tb)
+		if opts.DatabaseURL == "" {
+			opts.DatabaseURL = databaseURL
+		}
+		if opts.RedisURL == "" {
+			opts.RedisURL = redisURL
+		}
+	}
+	if opts.MigrationsDir == "" {
+		dir, err := findMigrationsDir()
+		if err != nil {
+			tb.Fatalf("e2etest: %v", err)
+		}
+		opts.MigrationsDir = dir
+	}
+	if opts.LogOutput == nil {
+		opts.LogOutput = io.Discard
+	}
+
+	if err := migrate(opts.DatabaseURL, opts.MigrationsDir); err != nil {
+		tb.Fatalf("e2etest: migrate: %v", err)
+	}
+
+	cfg := config.Load()
+	if cfg == nil {
+		tb.Fatal("e2etest: failed to load configuration")
+	}
+	cfg.Server.Env = "test"
+	cfg.Database.URL = opts.DatabaseURL
+	cfg.Redis.URL = opts.RedisURL
+	cfg.Notifications.DryRun = true
+	if opts.Configure != nil {
+		opts.Configure(cfg)
+	}
+
+	box := newMailbox()
+	logger := slog.New(&captureHandler{next: slog.NewJSONHandler(opts.LogOutput, nil), box: box})
+
+	app, err := bootstrap.New(cfg, logger)
+	if err != nil {
+		tb.Fatalf("e2etest: build app: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := app.Start(ctx); err != nil {
+		tb.Fatalf("e2etest: start app: %v", err)
+	}
+
+	srv := httptest.NewServer(app.Router)
+	tb.Cleanup(func() {
+		srv.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := app.Stop(ctx); err != nil {
+			tb.Logf("e2etest: stop app: %v", err)
+		}
+	})
+
+	return &Harness{App: app, Server: srv, Mailbox: box, tb: tb}
+}
+
+// Client returns an unauthenticated client for the server under test.
+func (h *Harness) Client() *Client {
+	return &Client{tb: h.tb, base: h.Server.URL, http: h.Server.Client()}
+}
+
+// WaitForMessage returns the next message from templateID to recipient after the first skip
+// ones, failing the test if none arrives within 10 seconds.
+func (h *Harness) WaitForMessage(recipient, templateID string, skip int) Message {
+	h.tb.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	msg, ok := h.Mailbox.Next(ctx, recipient, templateID, skip)
+	if !ok {
+		h.tb.Fatalf("e2etest: no %q message for %s", templateID, recipient)
+	}
+	return msg
+}
+
+func migrate(databaseURL, dir string) error {
+	db, err := sql.Open("pgx", databaseURL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if err := goose.SetDialect("postgres"); err != nil {
+		return err
+	}
+	return goose.UpContext(context.Background(), db, dir)
+}
+
+// findMigrationsDir walks up from the working directory (the test's package directory) to
+// the module root and returns its migrations directory.
+func findMigrationsDir() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return filepath.Join(dir, "migrations"), nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("module root (go.mod) not found; set Options.MigrationsDir")
+		}
+		dir = parent
+	}
+}
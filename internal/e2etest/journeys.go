@@ -0,0 +1,179 @@
+package e2etest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/notification/templates"
+	"github.com/delordemm1/go-api-simple-starter/internal/securerand"
+)
+
+// Account is a user created by a journey, with the credentials needed to continue from it.
+type Account struct {
+	ID        string
+	Email     string
+	Password  string
+	FirstName string
+	LastName  string
+}
+
+// NewAccount returns fresh, unique credentials for a journey; nothing is created yet.
+func NewAccount(tb testing.TB) Account {
+	tb.Helper()
+	suffix, err := securerand.String("abcdefghijklmnopqrstuvwxyz0123456789", 12)
+	if err != nil {
+		tb.Fatalf("e2etest: %v", err)
+	}
+	return Account{
+		Email:     "e2e-" + suffix + "@example.test",
+		Password:  "Correct-Horse-" + suffix,
+		FirstName: "Ada",
+		LastName:  "Lovelace",
+	}
+}
+
+// Register creates acct and returns it with its ID set.
+func (h *Harness) Register(acct Account) Account {
+	h.tb.Helper()
+	resp := h.Client().Expect(http.StatusOK, http.MethodPost, "/users/register", map[string]any{
+		"firstName":       acct.FirstName,
+		"lastName":        acct.LastName,
+		"email":           acct.Email,
+		"password":        acct.Password,
+		"confirmPassword": acct.Password,
+		"acceptTerms":     true,
+	})
+	var body struct {
+		ID string `json:"id"`
+	}
+	resp.Decode(h.tb, &body)
+	acct.ID = body.ID
+	return acct
+}
+
+// VerifyEmail confirms acct's email with the code from its latest verification email.
+func (h *Harness) VerifyEmail(acct Account) {
+	h.tb.Helper()
+	sent := 0
+	for _, m := range h.Mailbox.Messages(acct.Email) {
+		if m.TemplateID == templates.VerifyEmail.ID() {
+			sent++
+		}
+	}
+	code := h.WaitForMessage(acct.Email, templates.VerifyEmail.ID(), max(sent-1, 0)).Code()
+	if code == "" {
+		h.tb.Fatalf("e2etest: no verification code sent to %s", acct.Email)
+	}
+	h.Client().Expect(http.StatusNoContent, http.MethodPost, "/users/verify/email/confirm", map[string]any{
+		"email": acct.Email,
+		"code":  code,
+	})
+}
+
+// Login signs acct in and returns a client authenticated with the new session.
+func (h *Harness) Login(acct Account) *Client {
+	h.tb.Helper()
+	resp := h.Client().Expect(http.StatusOK, http.MethodPost, "/users/login", map[string]any{
+		"email":    acct.Email,
+		"password": acct.Password,
+	})
+	var body struct {
+		SessionToken string `json:"sessionToken"`
+	}
+	resp.Decode(h.tb, &body)
+	return h.Client().WithToken(body.SessionToken)
+}
+
+// SignUp registers, verifies, and logs in a fresh account: the starting point of most journeys.
+func (h *Harness) SignUp() (Account, *Client) {
+	h.tb.Helper()
+	acct := h.Register(NewAccount(h.tb))
+	h.VerifyEmail(acct)
+	return acct, h.Login(acct)
+}
+
+// ResetPassword runs forgot → verify code → reset for acct and returns it with the new password.
+func (h *Harness) ResetPassword(acct Account, newPassword string) Account {
+	h.tb.Helper()
+	skip := len(h.Mailbox.Messages(acct.Email))
+	c := h.Client()
+	c.Expect(http.StatusNoContent, http.MethodPost, "/users/password/forgot", map[string]any{"email": acct.Email})
+
+	code := h.nextMessage(acct.Email, templates.PasswordResetCode.ID(), skip).Code()
+	if code == "" {
+		h.tb.Fatalf("e2etest: no reset code sent to %s", acct.Email)
+	}
+	resp := c.Expect(http.StatusOK, http.MethodPost, "/users/password/code/verify", map[string]any{
+		"email": acct.Email,
+		"code":  code,
+	})
+	var body struct {
+		ResetToken string `json:"resetToken"`
+	}
+	resp.Decode(h.tb, &body)
+
+	c.Expect(http.StatusNoContent, http.MethodPost, "/users/password/reset", map[string]any{
+		"resetToken":      body.ResetToken,
+		"password":        newPassword,
+		"confirmPassword": newPassword,
+	})
+	acct.Password = newPassword
+	return acct
+}
+
+// nextMessage waits for the first templateID message to recipient that arrived after the
+// first skip messages of any template.
+func (h *Harness) nextMessage(recipient, templateID string, skip int) Message {
+	h.tb.Helper()
+	prior := 0
+	for _, m := range h.Mailbox.Messages(recipient)[:skip] {
+		if m.TemplateID == templateID {
+			prior++
+		}
+	}
+	return h.WaitForMessage(recipient, templateID, prior)
+}
+
+// RunAccountJourney drives register → verify → login → profile update → logout and checks
+// that the session stops working after logout.
+func RunAccountJourney(t *testing.T, h *Harness) {
+	t.Helper()
+	acct, c := h.SignUp()
+
+	var profile struct {
+		ID        string `json:"id"`
+		Email     string `json:"email"`
+		FirstName string `json:"firstName"`
+	}
+	c.Expect(http.StatusOK, http.MethodGet, "/users/profile", nil).Decode(t, &profile)
+	if profile.ID != acct.ID || profile.Email != acct.Email {
+		t.Fatalf("profile = %+v, want id %s email %s", profile, acct.ID, acct.Email)
+	}
+
+	resp := c.DoContentType(http.MethodPatch, "/users/profile", "application/merge-patch+json", map[string]any{"firstName": "Grace"})
+	if resp.Status != http.StatusOK {
+		t.Fatalf("PATCH /users/profile: got %d: %s", resp.Status, resp.Body)
+	}
+	resp.Decode(t, &profile)
+	if profile.FirstName != "Grace" {
+		t.Fatalf("firstName = %q after update, want Grace", profile.FirstName)
+	}
+
+	c.Expect(http.StatusNoContent, http.MethodPost, "/users/logout", nil)
+	c.Expect(http.StatusUnauthorized, http.MethodGet, "/users/profile", nil)
+}
+
+// RunPasswordResetJourney drives forgot → verify code → reset and checks that only the new
+// password signs in afterwards.
+func RunPasswordResetJourney(t *testing.T, h *Harness) {
+	t.Helper()
+	acct, _ := h.SignUp()
+	old := acct.Password
+
+	acct = h.ResetPassword(acct, old+"-new")
+	h.Login(acct).Expect(http.StatusOK, http.MethodGet, "/users/profile", nil)
+	h.Client().Expect(http.StatusUnauthorized, http.MethodPost, "/users/login", map[string]any{
+		"email":    acct.Email,
+		"password": old,
+	})
+}
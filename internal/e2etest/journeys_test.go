@@ -0,0 +1,14 @@
+package e2etest
+
+import "testing"
+
+// The journeys run against containers started by Start, or the databases named by
+// E2E_DATABASE_URL and E2E_REDIS_URL (see make test-e2e).
+
+func TestAccountJourney(t *testing.T) {
+	RunAccountJourney(t, Start(t, Options{}))
+}
+
+func TestPasswordResetJourney(t *testing.T) {
+	RunPasswordResetJourney(t, Start(t, Options{}))
+}
@@ -0,0 +1,135 @@
+package e2etest
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// dryRunMessage is the log line the notification service writes for every dry-run dispatch.
+const dryRunMessage = "DRY RUN: notification not sent"
+
+// Message is a notification captured from the dry-run log.
+type Message struct {
+	Channel    string
+	Recipient  string
+	TemplateID string
+	Subject    string
+	Body       string
+}
+
+// codePattern matches the default verification code (VERIFICATION_CODE_LENGTH=6, numeric).
+var codePattern = regexp.MustCompile(`\b\d{6}\b`)
+
+// Code returns the first verification code in the message body, or "" when there is none.
+func (m Message) Code() string {
+	return codePattern.FindString(m.Body)
+}
+
+// Mailbox collects the notifications rendered by the app under test. The harness runs
+// notifications in dry-run mode, so nothing leaves the process and every rendered message
+// is readable here.
+type Mailbox struct {
+	mu       sync.Mutex
+	messages []Message
+	notify   chan struct{}
+}
+
+func newMailbox() *Mailbox {
+	return &Mailbox{notify: make(chan struct{})}
+}
+
+// Messages returns every message captured so far for recipient, oldest first.
+func (m *Mailbox) Messages(recipient string) []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []Message
+	for _, msg := range m.messages {
+		if msg.Recipient == recipient {
+			out = append(out, msg)
+		}
+	}
+	return out
+}
+
+// Next waits until recipient receives a message from templateID after the first skip such
+// messages, and returns it. Dispatch is asynchronous, hence the wait.
+func (m *Mailbox) Next(ctx context.Context, recipient, templateID string, skip int) (Message, bool) {
+	for {
+		m.mu.Lock()
+		seen := 0
+		for _, msg := range m.messages {
+			if msg.Recipient != recipient || msg.TemplateID != templateID {
+				continue
+			}
+			if seen == skip {
+				m.mu.Unlock()
+				return msg, true
+			}
+			seen++
+		}
+		notify := m.notify
+		m.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return Message{}, false
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+func (m *Mailbox) add(msg Message) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	close(m.notify)
+	m.notify = make(chan struct{})
+}
+
+// captureHandler forwards records to next and feeds dry-run notifications to the mailbox.
+type captureHandler struct {
+	next slog.Handler
+	box  *Mailbox
+}
+
+func (h *captureHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= slog.LevelInfo || h.next.Enabled(ctx, level)
+}
+
+func (h *captureHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Message == dryRunMessage {
+		var msg Message
+		r.Attrs(func(a slog.Attr) bool {
+			switch a.Key {
+			case "channel":
+				msg.Channel = a.Value.String()
+			case "recipient":
+				msg.Recipient = a.Value.String()
+			case "template":
+				msg.TemplateID = a.Value.String()
+			case "subject":
+				msg.Subject = a.Value.String()
+			case "body":
+				msg.Body = a.Value.String()
+			}
+			return true
+		})
+		h.box.add(msg)
+	}
+	if !h.next.Enabled(ctx, r.Level) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *captureHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &captureHandler{next: h.next.WithAttrs(attrs), box: h.box}
+}
+
+func (h *captureHandler) WithGroup(name string) slog.Handler {
+	return &captureHandler{next: h.next.WithGroup(name), box: h.box}
+}
@@ -0,0 +1,104 @@
+package httpx
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/metrics"
+)
+
+// problemsTotal counts every problem response, labelled by code and status.
+var problemsTotal = metrics.NewCounter("http_problems_total")
+
+// AlertHook is notified of every Problem produced by ToProblem (and RecordProblem).
+// Implementations must be fast and non-blocking; they run on the request path.
+type AlertHook interface {
+	ObserveProblem(ctx context.Context, p *Problem)
+}
+
+// AlertHookFunc adapts a function to the AlertHook interface.
+type AlertHookFunc func(ctx context.Context, p *Problem)
+
+func (f AlertHookFunc) ObserveProblem(ctx context.Context, p *Problem) { f(ctx, p) }
+
+var (
+	hooksMu sync.RWMutex
+	hooks   []AlertHook
+)
+
+// RegisterAlertHook adds a hook that observes every problem. Call during startup.
+func RegisterAlertHook(h AlertHook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	hooks = append(hooks, h)
+}
+
+// RecordProblem counts p and forwards it to the registered alert hooks.
+// ToProblem calls it automatically; code writing problems directly (e.g., middleware)
+// should call it so error-rate metrics stay complete.
+func RecordProblem(ctx context.Context, p *Problem) {
+	code := p.Code
+	if code == "" {
+		code = "unknown"
+	}
+	problemsTotal.Inc(code, strconv.Itoa(p.GetStatus()))
+
+	hooksMu.RLock()
+	defer hooksMu.RUnlock()
+	for _, h := range hooks {
+		h.ObserveProblem(ctx, p)
+	}
+}
+
+// spikeAlerter fires notify when a problem code occurs at least threshold times in a window.
+type spikeAlerter struct {
+	code      string
+	threshold int
+	window    time.Duration
+	notify    func(code string, count int, window time.Duration)
+
+	mu          sync.Mutex
+	windowStart time.Time
+	count       int
+	fired       bool
+}
+
+// NewSpikeAlerter returns an AlertHook that calls notify once per window when problems
+// with the given code reach threshold within that window (e.g., a burst of ErrInternal).
+// notify runs in its own goroutine.
+func NewSpikeAlerter(code string, threshold int, window time.Duration, notify func(code string, count int, window time.Duration)) AlertHook {
+	if threshold <= 0 {
+		threshold = 1
+	}
+	if window <= 0 {
+		window = time.Minute
+	}
+	return &spikeAlerter{code: code, threshold: threshold, window: window, notify: notify}
+}
+
+func (a *spikeAlerter) ObserveProblem(_ context.Context, p *Problem) {
+	if p.Code != a.code {
+		return
+	}
+
+	a.mu.Lock()
+	now := time.Now()
+	if now.Sub(a.windowStart) > a.window {
+		a.windowStart = now
+		a.count = 0
+		a.fired = false
+	}
+	a.count++
+	fire := !a.fired && a.count >= a.threshold
+	if fire {
+		a.fired = true
+	}
+	count := a.count
+	a.mu.Unlock()
+
+	if fire && a.notify != nil {
+		go a.notify(a.code, count, a.window)
+	}
+}
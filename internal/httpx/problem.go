@@ -2,12 +2,16 @@ package httpx
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"strings"
 	"unicode"
 
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/delordemm1/go-api-simple-starter/internal/buildinfo"
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/validation"
 	"github.com/go-chi/chi/v5/middleware"
 )
 
@@ -16,6 +20,10 @@ import (
 //   - code: stable business code (e.g., ErrInvalidResetToken)
 //   - context: extra error payload (e.g., validation fields map)
 //   - requestId: propagated from chi middleware.RequestID
+//   - impersonatedBy: the admin acting as the user, for impersonation sessions
+//
+// Instance identifies the occurrence and the build that produced it, e.g.
+// "urn:build:v1.4.0:3f2a9c1d0b7e:request:host/abc-000001" (see problemInstance).
 type Problem struct {
 	// RFC 9457 standard fields
 	Type     string `json:"type,omitempty"`
@@ -31,6 +39,8 @@ type Problem struct {
 	Code      string `json:"code,omitempty"`
 	Context   any    `json:"context,omitempty"`
 	RequestID string `json:"requestId,omitempty"`
+	// ImpersonatedBy is the admin user ID behind an impersonation session (see ToProblem).
+	ImpersonatedBy string `json:"impersonatedBy,omitempty"`
 
 	// Aliases for client contracts preferring {code,message,data}
 	Message string `json:"message,omitempty"`
@@ -80,52 +90,148 @@ type DomainProblem interface {
 	ProblemContext() any
 }
 
+// ProblemErrorLister is optionally implemented by domain errors that carry
+// per-item details (e.g., one entry per invalid field). The details are exposed
+// in the Problem's errors array alongside any context payload.
+type ProblemErrorLister interface {
+	ProblemErrors() []*huma.ErrorDetail
+}
+
 // ToProblem converts any error into an RFC 7807 Problem with extensions.
 //
 // Behavior:
 //   - If err already implements huma.StatusError (e.g., a Problem), it is returned as-is.
+//   - If err joins several errors (errors.Join) containing DomainProblems, they are aggregated
+//     into one Problem: the first sets type/status/code and every one is listed in errors.
 //   - If err implements DomainProblem, it is formatted into a Problem.
 //   - Otherwise, returns a generic internal Problem with code ErrInternal.
+//
+// Every result is recorded via RecordProblem (metrics + alert hooks).
 func ToProblem(ctx context.Context, err error) error {
 	if err == nil {
 		return nil
 	}
 
 	// If it's already a Huma status error (including our Problem), pass through.
-	if _, ok := err.(huma.StatusError); ok {
+	if se, ok := err.(huma.StatusError); ok {
+		if p, ok := se.(*Problem); ok {
+			tagImpersonation(ctx, p)
+			RecordProblem(ctx, p)
+		} else {
+			RecordProblem(ctx, &Problem{Status: se.GetStatus()})
+		}
 		return err
 	}
 
+	p := buildProblem(ctx, err)
+	tagImpersonation(ctx, p)
+	RecordProblem(ctx, p)
+	return p
+}
+
+// tagImpersonation names the impersonating admin on problems raised in an impersonation session.
+func tagImpersonation(ctx context.Context, p *Problem) {
+	if p.ImpersonatedBy == "" {
+		p.ImpersonatedBy = contextx.ImpersonatorID(ctx)
+	}
+}
+
+// buildProblem maps err to a Problem without recording it.
+func buildProblem(ctx context.Context, err error) *Problem {
+	// Multi-error aggregation.
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		if p := joinedProblem(ctx, joined.Unwrap()); p != nil {
+			return p
+		}
+	}
+
 	// Domain-driven mapping w/o enumerating types.
 	var dp DomainProblem
 	if errors.As(err, &dp) {
-		code := dp.ProblemCode()
-		status := dp.ProblemStatus()
-		title := dp.ProblemTitle()
-		detail := dp.ProblemDetail()
-		typeURI := dp.ProblemTypeURI()
-		if typeURI == "" {
-			typeURI = "urn:problem:" + toKebab(code)
+		return fromDomainProblem(ctx, dp)
+	}
+
+	// Fallback internal problem.
+	return InternalProblem(ctx, "")
+}
+
+// fromDomainProblem formats a single DomainProblem.
+func fromDomainProblem(ctx context.Context, dp DomainProblem) *Problem {
+	code := dp.ProblemCode()
+	status := dp.ProblemStatus()
+	title := dp.ProblemTitle()
+	detail := dp.ProblemDetail()
+	typeURI := dp.ProblemTypeURI()
+	if typeURI == "" {
+		typeURI = "urn:problem:" + toKebab(code)
+	}
+
+	reqID := middleware.GetReqID(ctx)
+	msg := defaultDetail(detail, status)
+	ctxData := dp.ProblemContext()
+	return &Problem{
+		Type:      typeURI,
+		Title:     defaultTitle(title, status),
+		Status:    status,
+		Detail:    msg,
+		Instance:  problemInstance(reqID),
+		Errors:    problemErrors(dp),
+		Code:      code,
+		Context:   ctxData,
+		RequestID: reqID,
+		Message:   msg,
+		Data:      ctxData,
+	}
+}
+
+// joinedProblem aggregates the DomainProblems found in errs. It returns nil when
+// none of the joined errors is a DomainProblem.
+func joinedProblem(ctx context.Context, errs []error) *Problem {
+	var (
+		base    *Problem
+		details []*huma.ErrorDetail
+	)
+	for _, e := range errs {
+		var dp DomainProblem
+		if !errors.As(e, &dp) {
+			continue
+		}
+		if base == nil {
+			base = fromDomainProblem(ctx, dp)
 		}
+		details = append(details, problemErrors(dp)...)
+	}
+	if base == nil {
+		return nil
+	}
+	base.Errors = details
+	return base
+}
 
-		reqID := middleware.GetReqID(ctx)
-		msg := defaultDetail(detail, status)
-		ctxData := dp.ProblemContext()
-		return &Problem{
-			Type:      typeURI,
-			Title:     defaultTitle(title, status),
-			Status:    status,
-			Detail:    msg,
-			Code:      code,
-			Context:   ctxData,
-			RequestID: reqID,
-			Message:   msg,
-			Data:      ctxData,
+// problemErrors returns the per-item details of dp, or a single entry describing dp itself.
+func problemErrors(dp DomainProblem) []*huma.ErrorDetail {
+	if lister, ok := dp.(ProblemErrorLister); ok {
+		if list := lister.ProblemErrors(); len(list) > 0 {
+			return list
 		}
 	}
+	return []*huma.ErrorDetail{{
+		Message: defaultDetail(dp.ProblemDetail(), dp.ProblemStatus()),
+		Value:   dp.ProblemCode(),
+	}}
+}
 
-	// Fallback internal problem.
-	return InternalProblem(ctx, "")
+// WriteProblem converts err with ToProblem and writes it as the response. It is meant for
+// Huma middlewares, which must respond directly instead of returning an error.
+func WriteProblem(hctx huma.Context, err error) {
+	perr := ToProblem(hctx.Context(), err)
+	status := http.StatusInternalServerError
+	if se, ok := perr.(huma.StatusError); ok {
+		status = se.GetStatus()
+	}
+	hctx.SetHeader("Content-Type", "application/problem+json")
+	hctx.SetStatus(status)
+	_ = json.NewEncoder(hctx.BodyWriter()).Encode(perr)
 }
 
 // ValidationProblem builds a 400 validation error with the required context fields map.
@@ -133,14 +239,17 @@ func ValidationProblem(ctx context.Context, summary string, fields map[string][]
 	if summary == "" {
 		summary = "Validation error"
 	}
+	reqID := middleware.GetReqID(ctx)
 	return &Problem{
 		Type:      "urn:problem:validation-error",
 		Title:     "Validation error",
 		Status:    http.StatusBadRequest,
 		Detail:    summary,
+		Instance:  problemInstance(reqID),
+		Errors:    validation.FieldErrors(fields).ErrorDetails(nil),
 		Code:      "ErrValidation",
 		Context:   map[string]any{"fields": fields},
-		RequestID: middleware.GetReqID(ctx),
+		RequestID: reqID,
 		Message:   summary,
 		Data:      map[string]any{"fields": fields},
 	}
@@ -152,17 +261,30 @@ func InternalProblem(ctx context.Context, detail string) *Problem {
 	if detail == "" {
 		detail = "Something went wrong. Please try again later."
 	}
+	reqID := middleware.GetReqID(ctx)
 	return &Problem{
 		Type:      "urn:problem:internal",
 		Title:     http.StatusText(http.StatusInternalServerError),
 		Status:    http.StatusInternalServerError,
 		Detail:    detail,
+		Instance:  problemInstance(reqID),
 		Code:      "ErrInternal",
-		RequestID: middleware.GetReqID(ctx),
+		RequestID: reqID,
 		Message:   detail,
 	}
 }
 
+// problemInstance builds the Problem instance URI from the running build and the request ID,
+// so an error report pinpoints both the occurrence and the exact build that produced it.
+func problemInstance(reqID string) string {
+	b := buildinfo.Get()
+	instance := "urn:build:" + b.Version + ":" + b.Commit
+	if reqID != "" {
+		instance += ":request:" + reqID
+	}
+	return instance
+}
+
 func defaultTitle(title string, status int) string {
 	if title != "" {
 		return title
@@ -0,0 +1,53 @@
+// Package idempotency remembers the outcome of a request under a key for a while, so a
+// client retry can be answered with the first request's result instead of running it again.
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// Store records request outcomes by key.
+type Store interface {
+	// Get returns the value recorded for key; ok is false when there is none or it expired.
+	Get(ctx context.Context, key string) (value string, ok bool, err error)
+	// Put records value for key for ttl, replacing any earlier value.
+	Put(ctx context.Context, key, value string, ttl time.Duration) error
+}
+
+// RedisStore is a Store backed by Redis keys with a TTL.
+type RedisStore struct {
+	rdb *redis.Client
+	ns  cache.Namespace
+}
+
+// NewRedisStore returns a Store in rdb. Keys are prefixed with ns so environments sharing
+// a Redis never replay each other's requests.
+func NewRedisStore(rdb *redis.Client, ns cache.Namespace) *RedisStore {
+	return &RedisStore{rdb: rdb, ns: ns}
+}
+
+// Get implements Store.
+func (s *RedisStore) Get(ctx context.Context, key string) (string, bool, error) {
+	value, err := s.rdb.Get(ctx, s.ns.Key("idempotency", key)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("idempotency get: %w", err)
+	}
+	return value, true, nil
+}
+
+// Put implements Store.
+func (s *RedisStore) Put(ctx context.Context, key, value string, ttl time.Duration) error {
+	if err := s.rdb.Set(ctx, s.ns.Key("idempotency", key), value, ttl).Err(); err != nil {
+		return fmt.Errorf("idempotency put: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package ipreputation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultAbuseIPDBEndpoint is AbuseIPDB's v2 check API.
+const DefaultAbuseIPDBEndpoint = "https://api.abuseipdb.com/api/v2/check"
+
+// maxCacheEntries bounds the AbuseIPDB result cache; it is reset when full.
+const maxCacheEntries = 10000
+
+// AbuseIPDBConfig configures the AbuseIPDB checker.
+type AbuseIPDBConfig struct {
+	APIKey string
+	// Endpoint is the check API URL. Default: DefaultAbuseIPDBEndpoint.
+	Endpoint string
+	// MaxAgeDays only counts reports this recent. Default: 90.
+	MaxAgeDays int
+	// Timeout bounds each lookup. Default: 2s.
+	Timeout time.Duration
+	// CacheTTL is how long a score is reused, saving API quota. Default: 1h.
+	CacheTTL time.Duration
+}
+
+type cachedScore struct {
+	score int
+	until time.Time
+}
+
+// AbuseIPDB scores IPs with AbuseIPDB's abuse confidence score (0-100).
+type AbuseIPDB struct {
+	cfg  AbuseIPDBConfig
+	http *http.Client
+
+	mu    sync.Mutex
+	cache map[string]cachedScore
+}
+
+// NewAbuseIPDB returns an AbuseIPDB checker.
+func NewAbuseIPDB(cfg AbuseIPDBConfig) *AbuseIPDB {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = DefaultAbuseIPDBEndpoint
+	}
+	if cfg.MaxAgeDays <= 0 {
+		cfg.MaxAgeDays = 90
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 2 * time.Second
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = time.Hour
+	}
+	return &AbuseIPDB{cfg: cfg, http: &http.Client{Timeout: cfg.Timeout}, cache: map[string]cachedScore{}}
+}
+
+func (a *AbuseIPDB) Score(ctx context.Context, ip string) (int, error) {
+	now := time.Now()
+	a.mu.Lock()
+	c, ok := a.cache[ip]
+	a.mu.Unlock()
+	if ok && now.Before(c.until) {
+		return c.score, nil
+	}
+
+	score, err := a.lookup(ctx, ip)
+	if err != nil {
+		checks.Inc("abuseipdb", "error")
+		return 0, err
+	}
+	outcome := "clean"
+	if score > 0 {
+		outcome = "flagged"
+	}
+	checks.Inc("abuseipdb", outcome)
+
+	a.mu.Lock()
+	if len(a.cache) >= maxCacheEntries {
+		a.cache = map[string]cachedScore{}
+	}
+	a.cache[ip] = cachedScore{score: score, until: now.Add(a.cfg.CacheTTL)}
+	a.mu.Unlock()
+	return score, nil
+}
+
+func (a *AbuseIPDB) lookup(ctx context.Context, ip string) (int, error) {
+	q := url.Values{"ipAddress": {ip}, "maxAgeInDays": {strconv.Itoa(a.cfg.MaxAgeDays)}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.cfg.Endpoint+"?"+q.Encode(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("abuseipdb: build request: %w", err)
+	}
+	req.Header.Set("Key", a.cfg.APIKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("abuseipdb: check: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("abuseipdb: check: unexpected status %d", resp.StatusCode)
+	}
+	var body struct {
+		Data struct {
+			AbuseConfidenceScore int `json:"abuseConfidenceScore"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("abuseipdb: decode response: %w", err)
+	}
+	return body.Data.AbuseConfidenceScore, nil
+}
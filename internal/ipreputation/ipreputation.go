@@ -0,0 +1,152 @@
+// Package ipreputation scores client IPs against threat intelligence, so sensitive endpoints
+// (registration, login) can block or step up requests from known-bad addresses. Checkers
+// are pluggable: AbuseIPDB, a static Denylist, Nop, or several combined with Multi.
+package ipreputation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/metrics"
+)
+
+// Action is what an endpoint does with a request from a flagged IP.
+type Action string
+
+const (
+	ActionOff     Action = "off"     // do not check
+	ActionMonitor Action = "monitor" // log and count only
+	ActionStepUp  Action = "step_up" // require an extra verification step
+	ActionBlock   Action = "block"   // reject the request
+)
+
+// ParseAction validates an action name; "" means ActionOff.
+func ParseAction(s string) (Action, error) {
+	switch a := Action(strings.ToLower(strings.TrimSpace(s))); a {
+	case "":
+		return ActionOff, nil
+	case ActionOff, ActionMonitor, ActionStepUp, ActionBlock:
+		return a, nil
+	default:
+		return "", fmt.Errorf("ipreputation: unknown action %q (want off, monitor, step_up, or block)", s)
+	}
+}
+
+// MaxScore is the score of an IP that is certainly bad (e.g., on the denylist).
+const MaxScore = 100
+
+// checks counts lookups, labelled by checker and outcome (clean / flagged / error).
+var checks = metrics.NewCounter("ip_reputation_checks")
+
+// Checker scores an IP from 0 (no known abuse) to MaxScore. Implementations must be safe for
+// concurrent use and should bound their own latency; callers treat errors as "unknown".
+type Checker interface {
+	Score(ctx context.Context, ip string) (int, error)
+}
+
+// Nop scores every IP 0.
+type Nop struct{}
+
+func (Nop) Score(context.Context, string) (int, error) { return 0, nil }
+
+// Denylist scores IPs inside any of its networks MaxScore.
+type Denylist struct {
+	prefixes []netip.Prefix
+}
+
+// NewDenylist parses entries as IP addresses or CIDR ranges.
+func NewDenylist(entries []string) (*Denylist, error) {
+	d := &Denylist{}
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		if strings.Contains(e, "/") {
+			p, err := netip.ParsePrefix(e)
+			if err != nil {
+				return nil, fmt.Errorf("ipreputation: denylist entry %q: %w", e, err)
+			}
+			d.prefixes = append(d.prefixes, p.Masked())
+			continue
+		}
+		a, err := netip.ParseAddr(e)
+		if err != nil {
+			return nil, fmt.Errorf("ipreputation: denylist entry %q: %w", e, err)
+		}
+		d.prefixes = append(d.prefixes, netip.PrefixFrom(a.Unmap(), a.Unmap().BitLen()))
+	}
+	return d, nil
+}
+
+func (d *Denylist) Score(_ context.Context, ip string) (int, error) {
+	a, err := netip.ParseAddr(ip)
+	if err != nil {
+		return 0, fmt.Errorf("ipreputation: invalid ip %q: %w", ip, err)
+	}
+	a = a.Unmap()
+	for _, p := range d.prefixes {
+		if p.Contains(a) {
+			checks.Inc("denylist", "flagged")
+			return MaxScore, nil
+		}
+	}
+	checks.Inc("denylist", "clean")
+	return 0, nil
+}
+
+// Multi combines checkers and returns the highest score. A failing checker does not hide the
+// others' verdicts: its error is returned alongside the highest score the rest reported.
+type Multi []Checker
+
+func (m Multi) Score(ctx context.Context, ip string) (int, error) {
+	best := 0
+	var errs []error
+	for _, c := range m {
+		score, err := c.Score(ctx, ip)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		best = max(best, score)
+	}
+	return best, errors.Join(errs...)
+}
+
+// Endpoints whose action a Policy configures.
+const (
+	EndpointRegister = "register"
+	EndpointLogin    = "login"
+)
+
+// Policy decides what an endpoint does with a request, given its client IP's score.
+type Policy struct {
+	Checker Checker
+	// MinScore is the score at which an IP counts as flagged; values below 1 are raised to 1.
+	MinScore int
+	// Actions maps endpoints (EndpointRegister, EndpointLogin) to their action; missing
+	// endpoints are not checked.
+	Actions map[string]Action
+}
+
+// Screen scores ip and returns the action endpoint must take: its configured action when the
+// score reaches MinScore, ActionOff otherwise. It fails open: a failed lookup returns ActionOff
+// with the error (for logging), unless another checker of a Multi already flagged the IP.
+// A nil Policy or an empty ip always returns ActionOff.
+func (p *Policy) Screen(ctx context.Context, endpoint, ip string) (Action, int, error) {
+	if p == nil || ip == "" {
+		return ActionOff, 0, nil
+	}
+	action := p.Actions[endpoint]
+	if action == "" || action == ActionOff {
+		return ActionOff, 0, nil
+	}
+	score, err := p.Checker.Score(ctx, ip)
+	if score < max(p.MinScore, 1) {
+		return ActionOff, score, err
+	}
+	return action, score, err
+}
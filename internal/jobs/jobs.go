@@ -0,0 +1,177 @@
+// Package jobs runs background tasks (emails on a delay, fan-outs, slow side effects) outside
+// the request path. Handlers are registered by job type in a typed Registry; the Queue backend
+// (in-process memory, or Redis Streams for multi-instance deployments) is picked by config and
+// shares that registry, so switching backends needs no handler changes.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/metrics"
+	"github.com/google/uuid"
+)
+
+// runsTotal counts job executions, labelled by job type and outcome (ok, retry, claimed, dead).
+var runsTotal = metrics.NewCounter("jobs_runs")
+
+// Backends selectable with JOBS_BACKEND.
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+// Job is a unit of background work. Payload is the JSON encoding of the handler's input.
+type Job struct {
+	ID       string          `json:"id"`
+	Type     string          `json:"type"`
+	Payload  json.RawMessage `json:"payload"`
+	Attempts int             `json:"attempts"` // failed attempts so far
+	RunAt    time.Time       `json:"runAt"`    // zero: as soon as possible
+	LastErr  string          `json:"lastError,omitempty"`
+}
+
+// Queue accepts jobs and runs them with the handlers of its Registry. Implementations also
+// satisfy the bootstrap lifecycle interfaces (Start/Stop): workers run between the two.
+type Queue interface {
+	// Enqueue schedules job. An empty ID is assigned; a zero RunAt runs it immediately.
+	Enqueue(ctx context.Context, job Job) error
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// ErrUnknownType is returned (and the job dead-lettered) when no handler is registered.
+var ErrUnknownType = errors.New("jobs: no handler registered for job type")
+
+// Config tunes a Queue. Zero values take the defaults noted on each field.
+type Config struct {
+	// Concurrency is the number of jobs run at once per instance. Default: 4.
+	Concurrency int
+	// MaxAttempts is how many times a job is tried before it is dead-lettered. Default: 5.
+	MaxAttempts int
+	// RetryBackoff is the delay before the first retry; it doubles per attempt. Default: 5s.
+	RetryBackoff time.Duration
+	// ClaimIdle is how long a Redis message may stay unacknowledged (a crashed worker) before
+	// another consumer claims it. Default: 5m.
+	ClaimIdle time.Duration
+	// PollInterval bounds how long workers block waiting for work and how often delayed jobs
+	// are promoted. Default: 1s.
+	PollInterval time.Duration
+}
+
+func (c Config) withDefaults() Config {
+	if c.Concurrency <= 0 {
+		c.Concurrency = 4
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 5
+	}
+	if c.RetryBackoff <= 0 {
+		c.RetryBackoff = 5 * time.Second
+	}
+	if c.ClaimIdle <= 0 {
+		c.ClaimIdle = 5 * time.Minute
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = time.Second
+	}
+	return c
+}
+
+// backoff returns the delay before retrying a job that has failed attempts times.
+func (c Config) backoff(attempts int) time.Duration {
+	d := c.RetryBackoff
+	for i := 1; i < attempts && d < time.Hour; i++ {
+		d *= 2
+	}
+	return d
+}
+
+// Registry maps job types to handlers. Register handlers at startup, before the queue starts.
+type Registry struct {
+	mu       sync.RWMutex
+	handlers map[string]func(ctx context.Context, payload json.RawMessage) error
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{handlers: make(map[string]func(context.Context, json.RawMessage) error)}
+}
+
+// Handle registers fn for jobType; payloads are decoded into T before fn is called.
+// Registering a type twice replaces the earlier handler.
+func Handle[T any](r *Registry, jobType string, fn func(ctx context.Context, payload T) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[jobType] = func(ctx context.Context, raw json.RawMessage) error {
+		var payload T
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return fmt.Errorf("jobs: decode %s payload: %w", jobType, err)
+		}
+		return fn(ctx, payload)
+	}
+}
+
+// Enqueue encodes payload and schedules a jobType job on q to run at runAt (zero: now).
+func Enqueue[T any](ctx context.Context, q Queue, jobType string, payload T, runAt time.Time) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("jobs: encode %s payload: %w", jobType, err)
+	}
+	return q.Enqueue(ctx, Job{Type: jobType, Payload: raw, RunAt: runAt})
+}
+
+// run executes job with its registered handler, converting panics into errors.
+func (r *Registry) run(ctx context.Context, job Job) (err error) {
+	r.mu.RLock()
+	fn, ok := r.handlers[job.Type]
+	r.mu.RUnlock()
+	if !ok {
+		return ErrUnknownType
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("jobs: handler panicked: %v", p)
+		}
+	}()
+	return fn(ctx, job.Payload)
+}
+
+// prepare assigns an ID to a new job.
+func prepare(job *Job) error {
+	if job.ID == "" {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		job.ID = id.String()
+	}
+	return nil
+}
+
+// outcome runs job and decides what happens next: nil when it succeeded, otherwise the job
+// to retry (retry=true) or dead-letter (retry=false) with Attempts and LastErr updated.
+func outcome(ctx context.Context, reg *Registry, cfg Config, log *slog.Logger, job Job) (next *Job, retry bool) {
+	err := reg.run(ctx, job)
+	if err == nil {
+		runsTotal.Inc(job.Type, "ok")
+		return nil, false
+	}
+
+	job.Attempts++
+	job.LastErr = err.Error()
+	if job.Attempts >= cfg.MaxAttempts || errors.Is(err, ErrUnknownType) {
+		runsTotal.Inc(job.Type, "dead")
+		log.Error("job dead-lettered", "job_id", job.ID, "type", job.Type, "attempts", job.Attempts, "error", err)
+		return &job, false
+	}
+	job.RunAt = time.Now().Add(cfg.backoff(job.Attempts))
+	runsTotal.Inc(job.Type, "retry")
+	log.Warn("job failed, will retry", "job_id", job.ID, "type", job.Type, "attempts", job.Attempts, "retry_at", job.RunAt, "error", err)
+	return &job, true
+}
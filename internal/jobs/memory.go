@@ -0,0 +1,110 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MemoryQueue runs jobs inside the current process. Pending jobs are lost on restart and are
+// not shared between instances; use the Redis backend when either matters. Dead-lettered jobs
+// are only logged.
+type MemoryQueue struct {
+	reg *Registry
+	cfg Config
+	log *slog.Logger
+
+	work   chan Job
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// Counters for the admin runbook.
+	delayed    atomic.Int64
+	running    atomic.Int64
+	lastPickup atomic.Int64 // unix ms
+}
+
+// NewMemoryQueue returns an in-process queue running reg's handlers.
+func NewMemoryQueue(reg *Registry, cfg Config, logger *slog.Logger) *MemoryQueue {
+	cfg = cfg.withDefaults()
+	return &MemoryQueue{
+		reg:  reg,
+		cfg:  cfg,
+		log:  logger.With("component", "jobs", "backend", BackendMemory),
+		work: make(chan Job, 1024),
+	}
+}
+
+// Enqueue implements Queue. Delayed jobs wait on a timer; the call blocks only when the
+// buffer of ready jobs is full.
+func (q *MemoryQueue) Enqueue(ctx context.Context, job Job) error {
+	if err := prepare(&job); err != nil {
+		return err
+	}
+	if d := time.Until(job.RunAt); d > 0 {
+		q.delayed.Add(1)
+		time.AfterFunc(d, func() {
+			q.delayed.Add(-1)
+			q.work <- job
+		})
+		return nil
+	}
+	select {
+	case q.work <- job:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Start launches Concurrency workers.
+func (q *MemoryQueue) Start(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	q.cancel = cancel
+	q.lastPickup.Store(time.Now().UnixMilli())
+	for range q.cfg.Concurrency {
+		q.wg.Add(1)
+		go q.worker(ctx)
+	}
+	return nil
+}
+
+// Stop lets running jobs finish (bounded by ctx); jobs not yet started are dropped.
+func (q *MemoryQueue) Stop(ctx context.Context) error {
+	if q.cancel == nil {
+		return nil
+	}
+	q.cancel()
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *MemoryQueue) worker(ctx context.Context) {
+	defer q.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-q.work:
+			q.running.Add(1)
+			q.lastPickup.Store(time.Now().UnixMilli())
+			// Running jobs are not interrupted by Stop; it waits for them instead.
+			next, retry := outcome(context.WithoutCancel(ctx), q.reg, q.cfg, q.log, job)
+			if next != nil && retry {
+				_ = q.Enqueue(ctx, *next)
+			}
+			q.running.Add(-1)
+		}
+	}
+}
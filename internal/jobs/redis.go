@@ -0,0 +1,318 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/cache"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisGroup is the consumer group shared by every instance reading the stream.
+const redisGroup = "workers"
+
+// deadLetterMaxLen caps the dead-letter stream (approximately, oldest entries trimmed first).
+const deadLetterMaxLen = 10000
+
+// RedisQueue is a Queue on Redis Streams, for deployments that run several instances and do
+// not want a database-polling queue. Ready jobs are stream entries read through a consumer
+// group, so each is delivered to one instance; delayed jobs and retries wait in a sorted set
+// until due. Entries left unacknowledged longer than ClaimIdle (a worker crashed mid-job) are
+// claimed by another consumer and retried. Jobs that exhaust MaxAttempts move to a
+// "<stream>:dead" stream for inspection.
+//
+// Keys: <ns>:jobs:stream, <ns>:jobs:delayed, <ns>:jobs:stream:dead, and a heartbeat per
+// consumer at <ns>:jobs:workers:<consumer> (expires when the instance stops).
+type RedisQueue struct {
+	rdb      *redis.Client
+	reg      *Registry
+	cfg      Config
+	log      *slog.Logger
+	stream   string
+	delayed  string
+	dead     string
+	consumer string
+	// heartbeats prefixes each consumer's heartbeat key; lastPoll (unix ms) is when one of
+	// this instance's workers last asked for work.
+	heartbeats string
+	lastPoll   atomic.Int64
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRedisQueue returns a Redis Streams queue running reg's handlers.
+func NewRedisQueue(rdb *redis.Client, ns cache.Namespace, reg *Registry, cfg Config, logger *slog.Logger) *RedisQueue {
+	hostname, _ := os.Hostname()
+	stream := ns.Key("jobs", "stream")
+	return &RedisQueue{
+		rdb:        rdb,
+		reg:        reg,
+		cfg:        cfg.withDefaults(),
+		log:        logger.With("component", "jobs", "backend", BackendRedis),
+		stream:     stream,
+		delayed:    ns.Key("jobs", "delayed"),
+		heartbeats: ns.Key("jobs", "workers"),
+		dead:       stream + ":dead",
+		consumer:   hostname + "-" + uuid.NewString()[:8],
+	}
+}
+
+// Enqueue implements Queue: due jobs are added to the stream, later ones to the delayed set.
+func (q *RedisQueue) Enqueue(ctx context.Context, job Job) error {
+	if err := prepare(&job); err != nil {
+		return err
+	}
+	raw, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("jobs: encode job: %w", err)
+	}
+	if job.RunAt.After(time.Now()) {
+		return q.rdb.ZAdd(ctx, q.delayed, redis.Z{Score: float64(job.RunAt.UnixMilli()), Member: raw}).Err()
+	}
+	return q.rdb.XAdd(ctx, &redis.XAddArgs{Stream: q.stream, Values: map[string]any{"job": raw}}).Err()
+}
+
+// Start creates the consumer group if needed and launches the workers, the delayed-job
+// promoter, and the stuck-message claimer.
+func (q *RedisQueue) Start(ctx context.Context) error {
+	err := q.rdb.XGroupCreateMkStream(ctx, q.stream, redisGroup, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("jobs: create consumer group: %w", err)
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	q.cancel = cancel
+	for range q.cfg.Concurrency {
+		q.spawn(func() { q.worker(runCtx) })
+	}
+	q.spawn(func() { q.every(runCtx, q.cfg.PollInterval, q.promote) })
+	q.spawn(func() { q.every(runCtx, max(q.cfg.ClaimIdle/2, q.cfg.PollInterval), q.claim) })
+	q.lastPoll.Store(time.Now().UnixMilli())
+	if err := q.heartbeat(ctx); err != nil {
+		q.log.Warn("jobs heartbeat failed", "error", err)
+	}
+	q.spawn(func() { q.every(runCtx, heartbeatInterval, q.heartbeat) })
+	return nil
+}
+
+// Stop stops reading new jobs and waits (bounded by ctx) for running ones. Jobs interrupted
+// by the deadline stay pending and are claimed by another consumer after ClaimIdle.
+func (q *RedisQueue) Stop(ctx context.Context) error {
+	if q.cancel == nil {
+		return nil
+	}
+	q.cancel()
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		// Drop the heartbeat so the runbook stops listing this instance right away.
+		return q.rdb.Del(ctx, q.heartbeats+":"+q.consumer).Err()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// heartbeatInterval is how often each instance refreshes its heartbeat; the key expires
+// after three missed refreshes.
+const heartbeatInterval = 10 * time.Second
+
+// heartbeat publishes when this instance's workers last polled the stream.
+func (q *RedisQueue) heartbeat(ctx context.Context) error {
+	return q.rdb.Set(ctx, q.heartbeats+":"+q.consumer, q.lastPoll.Load(), 3*heartbeatInterval).Err()
+}
+
+func (q *RedisQueue) spawn(fn func()) {
+	q.wg.Add(1)
+	go func() {
+		defer q.wg.Done()
+		fn()
+	}()
+}
+
+func (q *RedisQueue) every(ctx context.Context, interval time.Duration, fn func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := fn(ctx); err != nil && ctx.Err() == nil {
+				q.log.Error("jobs maintenance failed", "error", err)
+			}
+		}
+	}
+}
+
+func (q *RedisQueue) worker(ctx context.Context) {
+	for ctx.Err() == nil {
+		q.lastPoll.Store(time.Now().UnixMilli())
+		streams, err := q.rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    redisGroup,
+			Consumer: q.consumer,
+			Streams:  []string{q.stream, ">"},
+			Count:    1,
+			Block:    q.cfg.PollInterval,
+		}).Result()
+		if err != nil {
+			if !errors.Is(err, redis.Nil) && ctx.Err() == nil {
+				q.log.Error("jobs read failed", "error", err)
+				time.Sleep(q.cfg.PollInterval)
+			}
+			continue
+		}
+		for _, s := range streams {
+			for _, msg := range s.Messages {
+				// Running jobs are not interrupted by Stop; it waits for them instead.
+				q.process(context.WithoutCancel(ctx), msg)
+			}
+		}
+	}
+}
+
+// process runs one stream entry and acknowledges it once its outcome (done, retry scheduled,
+// or dead-lettered) has been recorded.
+func (q *RedisQueue) process(ctx context.Context, msg redis.XMessage) {
+	job, ok := q.decode(msg)
+	if !ok {
+		q.ack(ctx, msg.ID)
+		return
+	}
+	next, retry := outcome(ctx, q.reg, q.cfg, q.log, job)
+	q.settle(ctx, msg.ID, next, retry)
+}
+
+// settle records the outcome of the entry id and acknowledges it. If recording fails the
+// entry stays pending, so the claimer retries it later rather than losing it.
+func (q *RedisQueue) settle(ctx context.Context, id string, next *Job, retry bool) {
+	switch {
+	case next == nil:
+	case retry:
+		if err := q.Enqueue(ctx, *next); err != nil {
+			q.log.Error("jobs retry scheduling failed", "job_id", next.ID, "error", err)
+			return
+		}
+	default:
+		if err := q.deadLetter(ctx, *next); err != nil {
+			q.log.Error("jobs dead-lettering failed", "job_id", next.ID, "error", err)
+			return
+		}
+	}
+	q.ack(ctx, id)
+}
+
+func (q *RedisQueue) ack(ctx context.Context, id string) {
+	pipe := q.rdb.TxPipeline()
+	pipe.XAck(ctx, q.stream, redisGroup, id)
+	pipe.XDel(ctx, q.stream, id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		q.log.Error("jobs ack failed", "entry_id", id, "error", err)
+	}
+}
+
+func (q *RedisQueue) deadLetter(ctx context.Context, job Job) error {
+	raw, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return q.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: q.dead,
+		MaxLen: deadLetterMaxLen,
+		Approx: true,
+		Values: map[string]any{"job": raw, "type": job.Type, "error": job.LastErr, "attempts": strconv.Itoa(job.Attempts)},
+	}).Err()
+}
+
+func (q *RedisQueue) decode(msg redis.XMessage) (Job, bool) {
+	var job Job
+	raw, _ := msg.Values["job"].(string)
+	if err := json.Unmarshal([]byte(raw), &job); err != nil {
+		q.log.Error("jobs dropped undecodable entry", "entry_id", msg.ID, "error", err)
+		return Job{}, false
+	}
+	return job, true
+}
+
+// promote moves due jobs from the delayed set to the stream. ZREM decides which instance
+// promotes a job when several race for it.
+func (q *RedisQueue) promote(ctx context.Context) error {
+	due, err := q.rdb.ZRangeByScore(ctx, q.delayed, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(time.Now().UnixMilli(), 10),
+		Count: 100,
+	}).Result()
+	if err != nil {
+		return err
+	}
+	for _, member := range due {
+		removed, err := q.rdb.ZRem(ctx, q.delayed, member).Result()
+		if err != nil {
+			return err
+		}
+		if removed == 0 {
+			continue
+		}
+		if err := q.rdb.XAdd(ctx, &redis.XAddArgs{Stream: q.stream, Values: map[string]any{"job": member}}).Err(); err != nil {
+			// Put it back so it is not lost; it is promoted again on the next tick.
+			q.rdb.ZAdd(ctx, q.delayed, redis.Z{Score: 0, Member: member})
+			return err
+		}
+	}
+	return nil
+}
+
+// claim takes over entries idle longer than ClaimIdle (their worker died mid-job). Each
+// counts as a failed attempt, so a job that keeps crashing its worker is dead-lettered.
+func (q *RedisQueue) claim(ctx context.Context) error {
+	start := "0-0"
+	for {
+		msgs, next, err := q.rdb.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   q.stream,
+			Group:    redisGroup,
+			Consumer: q.consumer,
+			MinIdle:  q.cfg.ClaimIdle,
+			Start:    start,
+			Count:    100,
+		}).Result()
+		if err != nil {
+			return err
+		}
+		for _, msg := range msgs {
+			job, ok := q.decode(msg)
+			if !ok {
+				q.ack(ctx, msg.ID)
+				continue
+			}
+			job.Attempts++
+			job.LastErr = "claimed after worker stalled"
+			job.RunAt = time.Time{}
+			retry := job.Attempts < q.cfg.MaxAttempts
+			if retry {
+				runsTotal.Inc(job.Type, "claimed")
+			} else {
+				runsTotal.Inc(job.Type, "dead")
+			}
+			q.log.Warn("jobs claimed stalled entry", "job_id", job.ID, "type", job.Type, "attempts", job.Attempts, "dead_lettered", !retry)
+			q.settle(ctx, msg.ID, &job, retry)
+		}
+		if next == "0-0" || next == "" {
+			return nil
+		}
+		start = next
+	}
+}
@@ -0,0 +1,152 @@
+package jobs
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/runbook"
+	"github.com/redis/go-redis/v9"
+)
+
+// stallAfter is how long a due job may wait without being picked up before the queue is
+// reported stalled on the admin runbook.
+const stallAfter = time.Minute
+
+// Stats is a queue snapshot as shown on the admin runbook.
+type Stats struct {
+	Backend     string `json:"backend"`
+	Concurrency int    `json:"concurrency" doc:"Workers per instance"`
+	// Ready counts due jobs no worker has picked up yet; Running those being worked on.
+	Ready   int64 `json:"ready"`
+	Running int64 `json:"running"`
+	Delayed int64 `json:"delayed" doc:"Jobs waiting for their run time, including retries"`
+	Dead    int64 `json:"dead" doc:"Dead-lettered jobs kept for inspection (Redis only)"`
+	// OldestReadyAt is when the longest-waiting ready job was queued (Redis only).
+	OldestReadyAt *time.Time `json:"oldestReadyAt,omitempty"`
+	// LastPickupAt is when a worker last took a job (memory only).
+	LastPickupAt *time.Time `json:"lastPickupAt,omitempty"`
+	// Workers lists the instances consuming the queue, by their last heartbeat (Redis only).
+	Workers []Worker `json:"workers,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// Worker is one instance consuming a Redis queue.
+type Worker struct {
+	ID         string    `json:"id"`
+	LastPollAt time.Time `json:"lastPollAt" doc:"When one of the instance's workers last asked for work"`
+}
+
+// Status implements runbook.Reporter. The queue is stalled when ready jobs have not been
+// picked up for stallAfter.
+func (q *MemoryQueue) Status(context.Context) runbook.Status {
+	stats := Stats{
+		Backend:     BackendMemory,
+		Concurrency: q.cfg.Concurrency,
+		Ready:       int64(len(q.work)),
+		Running:     q.running.Load(),
+		Delayed:     q.delayed.Load(),
+	}
+	var stalled bool
+	if ms := q.lastPickup.Load(); ms > 0 {
+		at := time.UnixMilli(ms)
+		stats.LastPickupAt = &at
+		stalled = stats.Ready > 0 && time.Since(at) > stallAfter
+	}
+	return runbook.Status{Stalled: stalled, Details: stats}
+}
+
+// Status implements runbook.Reporter. The queue is stalled when ready jobs have waited
+// longer than stallAfter, when none of its workers is alive, or when Redis cannot be read.
+func (q *RedisQueue) Status(ctx context.Context) runbook.Status {
+	stats, err := q.stats(ctx)
+	if err != nil {
+		stats.Error = err.Error()
+		return runbook.Status{Stalled: true, Details: stats}
+	}
+	stalled := stats.Ready > 0 &&
+		(len(stats.Workers) == 0 || stats.OldestReadyAt != nil && time.Since(*stats.OldestReadyAt) > stallAfter)
+	return runbook.Status{Stalled: stalled, Details: stats}
+}
+
+func (q *RedisQueue) stats(ctx context.Context) (Stats, error) {
+	stats := Stats{Backend: BackendRedis, Concurrency: q.cfg.Concurrency}
+
+	pipe := q.rdb.Pipeline()
+	delayed := pipe.ZCard(ctx, q.delayed)
+	dead := pipe.XLen(ctx, q.dead)
+	groups := pipe.XInfoGroups(ctx, q.stream)
+	if _, err := pipe.Exec(ctx); err != nil && !isMissingStream(err) {
+		return stats, err
+	}
+	stats.Delayed, stats.Dead = delayed.Val(), dead.Val()
+
+	for _, g := range groups.Val() {
+		if g.Name != redisGroup {
+			continue
+		}
+		stats.Running, stats.Ready = g.Pending, max(g.Lag, 0)
+		// Entries past the group's last delivered ID are the ones waiting for a worker.
+		next, err := q.rdb.XRangeN(ctx, q.stream, "("+g.LastDeliveredID, "+", 1).Result()
+		if err != nil {
+			return stats, err
+		}
+		if len(next) > 0 {
+			stats.OldestReadyAt = entryTime(next[0].ID)
+		}
+	}
+
+	workers, err := q.workers(ctx)
+	if err != nil {
+		return stats, err
+	}
+	stats.Workers = workers
+	return stats, nil
+}
+
+// workers reads the heartbeats of live consumers; entries expire when an instance stops.
+func (q *RedisQueue) workers(ctx context.Context) ([]Worker, error) {
+	var keys []string
+	iter := q.rdb.Scan(ctx, 0, q.heartbeats+":*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil || len(keys) == 0 {
+		return nil, err
+	}
+	values, err := q.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Worker, 0, len(keys))
+	for i, v := range values {
+		raw, _ := v.(string)
+		ms, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			continue // expired between SCAN and MGET
+		}
+		out = append(out, Worker{
+			ID:         strings.TrimPrefix(keys[i], q.heartbeats+":"),
+			LastPollAt: time.UnixMilli(ms),
+		})
+	}
+	return out, nil
+}
+
+// entryTime returns the enqueue time encoded in a stream entry ID ("<ms>-<seq>").
+func entryTime(id string) *time.Time {
+	ms, _, _ := strings.Cut(id, "-")
+	n, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return nil
+	}
+	t := time.UnixMilli(n)
+	return &t
+}
+
+// isMissingStream reports whether err is Redis refusing XINFO on a stream (or group) that
+// does not exist yet, which for the runbook just means nothing has been queued.
+func isMissingStream(err error) bool {
+	return err == redis.Nil || strings.HasPrefix(err.Error(), "ERR no such key")
+}
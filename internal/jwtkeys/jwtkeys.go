@@ -0,0 +1,124 @@
+// Package jwtkeys signs and verifies JWTs with Ed25519 keys derived from configured secrets
+// (JWT_SECRET), and publishes their public halves as a JSON Web Key Set so other services can
+// verify tokens offline. A key's ID (the JWT "kid" header) is derived from its public key, so
+// rolling the secret changes the kid; tokens signed under previous secrets keep verifying, and
+// stay listed in the key set, while those secrets are configured.
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// hkdfInfo separates the signing key from any other key derived from the same secret.
+const hkdfInfo = "go-api-simple-starter jwt signing key"
+
+// keyIDSize is the length of the key ID before encoding: the first bytes of SHA-256(public key).
+const keyIDSize = 8
+
+type key struct {
+	id      string
+	private ed25519.PrivateKey
+}
+
+// Set signs with its primary key and verifies with the primary or any previous key.
+type Set struct {
+	keys []key // keys[0] is the primary
+}
+
+// JWK is the public half of a key in JSON Web Key form (RFC 8037, OKP / Ed25519).
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+}
+
+// JWKS is a JSON Web Key Set, as served at /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// New returns a Set signing with a key derived from secret. Tokens signed with keys derived
+// from any of previous still verify.
+func New(secret string, previous ...string) (*Set, error) {
+	if secret == "" {
+		return nil, errors.New("jwtkeys: secret is required")
+	}
+	s := &Set{}
+	for _, sec := range append([]string{secret}, previous...) {
+		if sec == "" {
+			continue
+		}
+		k, err := newKey(sec)
+		if err != nil {
+			return nil, err
+		}
+		s.keys = append(s.keys, k)
+	}
+	return s, nil
+}
+
+func newKey(secret string) (key, error) {
+	seed, err := hkdf.Key(sha256.New, []byte(secret), nil, hkdfInfo, ed25519.SeedSize)
+	if err != nil {
+		return key{}, fmt.Errorf("jwtkeys: derive key: %w", err)
+	}
+	private := ed25519.NewKeyFromSeed(seed)
+	sum := sha256.Sum256(private.Public().(ed25519.PublicKey))
+	return key{id: base64.RawURLEncoding.EncodeToString(sum[:keyIDSize]), private: private}, nil
+}
+
+// KeyID returns the ID of the primary key, set as "kid" on every token Sign makes.
+func (s *Set) KeyID() string {
+	return s.keys[0].id
+}
+
+// Sign returns claims as a compact JWT signed with the primary key (alg EdDSA).
+func (s *Set) Sign(claims jwt.Claims) (string, error) {
+	k := s.keys[0]
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = k.id
+	return token.SignedString(k.private)
+}
+
+// Parse verifies raw with the key its "kid" names and decodes it into claims. Only EdDSA is
+// accepted; opts add checks such as jwt.WithIssuer. Errors wrap the jwt package's, e.g.
+// jwt.ErrTokenExpired.
+func (s *Set) Parse(raw string, claims jwt.Claims, opts ...jwt.ParserOption) error {
+	opts = append(opts, jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}))
+	_, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		for _, k := range s.keys {
+			if k.id == kid {
+				return k.private.Public(), nil
+			}
+		}
+		return nil, fmt.Errorf("jwtkeys: unknown key id %q", kid)
+	}, opts...)
+	return err
+}
+
+// JWKS returns the public keys, primary first.
+func (s *Set) JWKS() JWKS {
+	set := JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	for _, k := range s.keys {
+		set.Keys = append(set.Keys, JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(k.private.Public().(ed25519.PublicKey)),
+			Kid: k.id,
+			Alg: jwt.SigningMethodEdDSA.Alg(),
+			Use: "sig",
+		})
+	}
+	return set
+}
@@ -0,0 +1,23 @@
+// Package mapping holds the small generic helpers handlers use to convert domain entities into
+// response DTOs. Modules keep one mapper per entity (e.g., toUserDTO) and build every response
+// embedding that DTO from it, so a field added to the entity is exposed consistently.
+package mapping
+
+// Slice maps every element of in with f. It never returns nil, so empty lists encode as [].
+func Slice[T, U any](in []T, f func(T) U) []U {
+	out := make([]U, 0, len(in))
+	for _, v := range in {
+		out = append(out, f(v))
+	}
+	return out
+}
+
+// Value dereferences p, returning the zero value for nil (e.g., optional columns that the
+// DTO renders as an omitted field).
+func Value[T any](p *T) T {
+	if p == nil {
+		var zero T
+		return zero
+	}
+	return *p
+}
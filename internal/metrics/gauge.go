@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"expvar"
+)
+
+// Gauge is a label-partitioned value that can go up and down, published via expvar.
+type Gauge struct {
+	m *expvar.Map
+}
+
+var gauges = map[string]*Gauge{}
+
+// NewGauge returns the gauge registered under name, creating it on first use.
+func NewGauge(name string) *Gauge {
+	mu.Lock()
+	defer mu.Unlock()
+	if g, ok := gauges[name]; ok {
+		return g
+	}
+	g := &Gauge{m: expvar.NewMap(name)}
+	gauges[name] = g
+	return g
+}
+
+// Set stores v for the given labels.
+func (g *Gauge) Set(v float64, labels ...string) {
+	k := key(labels)
+	f, ok := g.m.Get(k).(*expvar.Float)
+	if !ok {
+		g.m.AddFloat(k, 0)
+		f = g.m.Get(k).(*expvar.Float)
+	}
+	f.Set(v)
+}
+
+// Value returns the current value for the given labels.
+func (g *Gauge) Value(labels ...string) float64 {
+	f, ok := g.m.Get(key(labels)).(*expvar.Float)
+	if !ok {
+		return 0
+	}
+	return f.Value()
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"expvar"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Counter is a monotonically increasing, label-partitioned counter published via expvar.
+// Each distinct label combination is stored as its own key (labels joined by ",").
+type Counter struct {
+	m *expvar.Map
+}
+
+var (
+	mu       sync.Mutex
+	counters = map[string]*Counter{}
+)
+
+// NewCounter returns the counter registered under name, creating it on first use.
+// Calling NewCounter twice with the same name returns the same counter, so packages
+// can declare their counters as package-level vars without coordinating.
+func NewCounter(name string) *Counter {
+	mu.Lock()
+	defer mu.Unlock()
+	if c, ok := counters[name]; ok {
+		return c
+	}
+	c := &Counter{m: expvar.NewMap(name)}
+	counters[name] = c
+	return c
+}
+
+// Inc increments the counter for the given labels by one.
+func (c *Counter) Inc(labels ...string) {
+	c.Add(1, labels...)
+}
+
+// Add increments the counter for the given labels by delta.
+func (c *Counter) Add(delta int64, labels ...string) {
+	c.m.Add(key(labels), delta)
+}
+
+// Value returns the current value for the given labels.
+func (c *Counter) Value(labels ...string) int64 {
+	v, ok := c.m.Get(key(labels)).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+	return v.Value()
+}
+
+func key(labels []string) string {
+	if len(labels) == 0 {
+		return "total"
+	}
+	return strings.Join(labels, ",")
+}
+
+// Handler serves all published metrics as JSON (expvar format).
+func Handler() http.Handler {
+	return expvar.Handler()
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"time"
+)
+
+// latencyBuckets are the upper bounds of the latency histogram, in the style of Prometheus'
+// cumulative "le" buckets. Calls slower than the last bound only count towards "+Inf".
+var latencyBuckets = []struct {
+	le    time.Duration
+	label string
+}{
+	{time.Millisecond, "1ms"},
+	{5 * time.Millisecond, "5ms"},
+	{10 * time.Millisecond, "10ms"},
+	{25 * time.Millisecond, "25ms"},
+	{50 * time.Millisecond, "50ms"},
+	{100 * time.Millisecond, "100ms"},
+	{250 * time.Millisecond, "250ms"},
+	{500 * time.Millisecond, "500ms"},
+	{time.Second, "1s"},
+	{5 * time.Second, "5s"},
+}
+
+// Timer records call counts, error counts, total latency, and a cumulative latency histogram
+// for labelled operations. It publishes <name>_calls, <name>_errors, <name>_duration_us and
+// <name>_latency (keyed by the labels plus "le=<bound>").
+type Timer struct {
+	calls    *Counter
+	errors   *Counter
+	duration *Counter
+	latency  *Counter
+}
+
+// NewTimer returns the timer registered under name, creating it on first use.
+func NewTimer(name string) *Timer {
+	return &Timer{
+		calls:    NewCounter(name + "_calls"),
+		errors:   NewCounter(name + "_errors"),
+		duration: NewCounter(name + "_duration_us"),
+		latency:  NewCounter(name + "_latency"),
+	}
+}
+
+// Observe records one call for labels that started at start and returned err.
+func (t *Timer) Observe(start time.Time, err error, labels ...string) {
+	elapsed := time.Since(start)
+	t.calls.Inc(labels...)
+	if err != nil {
+		t.errors.Inc(labels...)
+	}
+	t.duration.Add(elapsed.Microseconds(), labels...)
+
+	bucket := make([]string, len(labels)+1)
+	copy(bucket, labels)
+	for _, b := range latencyBuckets {
+		if elapsed <= b.le {
+			bucket[len(labels)] = "le=" + b.label
+			t.latency.Inc(bucket...)
+		}
+	}
+	bucket[len(labels)] = "le=+Inf"
+	t.latency.Inc(bucket...)
+}
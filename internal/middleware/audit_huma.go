@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/delordemm1/go-api-simple-starter/internal/siem"
+)
+
+// AuditCategory classifies an operation for the audit trail.
+type AuditCategory string
+
+const (
+	AuditAuth    AuditCategory = "auth"
+	AuditProfile AuditCategory = "profile"
+	AuditAdmin   AuditCategory = "admin"
+	AuditBilling AuditCategory = "billing"
+)
+
+// auditCategoryKey is the huma.Operation Metadata key read by AuditHuma (see Audit).
+const auditCategoryKey = "auditCategory"
+
+// Audit returns operation Metadata that makes AuditHuma emit an "audit.<category>" event for
+// every call of the operation, e.g. Metadata: middleware.Audit(middleware.AuditProfile).
+func Audit(category AuditCategory) map[string]any {
+	return map[string]any{auditCategoryKey: category}
+}
+
+// MergeMetadata combines operation Metadata maps, e.g. AllowScopes and Audit on one route.
+func MergeMetadata(parts ...map[string]any) map[string]any {
+	merged := make(map[string]any)
+	for _, p := range parts {
+		for k, v := range p {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// auditRecordKey carries the *auditRecord of the current request down the middleware chain,
+// so the session middleware (which runs later) can name the authenticated user.
+type auditRecordKey struct{}
+
+type auditRecord struct {
+	userID string
+}
+
+// setAuditUser records the authenticated user of the current request, if it is audited.
+func setAuditUser(ctx context.Context, userID string) {
+	if rec, ok := ctx.Value(auditRecordKey{}).(*auditRecord); ok {
+		rec.userID = userID
+	}
+}
+
+// AuditHuma is an API-wide Huma middleware that publishes one standardized event per call of
+// an operation declaring an audit category (see Audit). Operations without one pass through
+// untouched. The event is "audit.<category>" with outcome success for statuses below 400,
+// the authenticated user (when the session middleware ran), and the operation, method, path,
+// status, and target {id} path parameter in its metadata. Register it before the modules.
+func AuditHuma(events siem.Publisher) func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		op := ctx.Operation()
+		var category AuditCategory
+		if op != nil {
+			category, _ = op.Metadata[auditCategoryKey].(AuditCategory)
+		}
+		if category == "" {
+			next(ctx)
+			return
+		}
+
+		rec := &auditRecord{}
+		next(huma.WithValue(ctx, auditRecordKey{}, rec))
+
+		status := ctx.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		outcome := siem.OutcomeSuccess
+		if status >= http.StatusBadRequest {
+			outcome = siem.OutcomeFailure
+		}
+		meta := map[string]any{
+			"category":  string(category),
+			"operation": op.OperationID,
+			"method":    op.Method,
+			"path":      op.Path,
+			"status":    status,
+		}
+		if id := ctx.Param("id"); id != "" {
+			meta["targetId"] = id
+		}
+
+		e := siem.Event{Type: "audit." + string(category), Outcome: outcome, UserID: rec.userID, Metadata: meta}
+		if category == AuditAdmin {
+			e.ActorID = rec.userID
+		}
+		events.Publish(ctx.Context(), e)
+	}
+}
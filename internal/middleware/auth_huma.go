@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strings"
@@ -14,27 +15,51 @@ import (
 	chimw "github.com/go-chi/chi/v5/middleware"
 )
 
+// ImpersonatedByHeader is set on every response to a request made with an admin impersonation
+// session, to the impersonating admin's user ID, so clients can show a banner.
+const ImpersonatedByHeader = "X-Impersonated-By"
+
+// allowedScopesKey is the huma.Operation Metadata key read by JWTAuthHuma (see AllowScopes).
+const allowedScopesKey = "allowedSessionScopes"
+
+// AllowScopes returns operation Metadata that lets restricted sessions with one of scopes call
+// the operation, e.g. Metadata: middleware.AllowScopes(session.ScopeEmailUnverified).
+// Unrestricted sessions may call every protected operation.
+func AllowScopes(scopes ...string) map[string]any {
+	return map[string]any{allowedScopesKey: scopes}
+}
+
+// scopeAllowed reports whether op accepts sessions with scope.
+func scopeAllowed(op *huma.Operation, scope string) bool {
+	if scope == "" {
+		return true
+	}
+	if op == nil {
+		return false
+	}
+	allowed, _ := op.Metadata[allowedScopesKey].([]string)
+	for _, s := range allowed {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
 // JWTAuthHuma (now session-based) is a router-agnostic Huma middleware that validates
 // an opaque Bearer session ID, injects the user ID and session ID into the context,
-// and extends the session TTL. On failure, it writes an RFC7807 problem+json response.
+// and extends the session TTL. Restricted sessions (see session.Metadata.Scope) are refused with
+// 403 unless the operation opts in via AllowScopes. On failure, it writes an RFC7807
+// problem+json response.
 func JWTAuthHuma(provider session.Provider, logger *slog.Logger) func(huma.Context, func(huma.Context)) {
 	return func(ctx huma.Context, next func(huma.Context)) {
 		r, w := humachi.Unwrap(ctx)
 
+		writeProblem := func(status int, code, typeURI, detail string) {
+			writeAuthProblem(r, w, status, code, typeURI, detail)
+		}
 		writeUnauthorized := func(detail string) {
-			reqID := chimw.GetReqID(r.Context())
-			p := &apphttpx.Problem{
-				Type:      "urn:problem:auth/err-unauthorized",
-				Title:     http.StatusText(http.StatusUnauthorized),
-				Status:    http.StatusUnauthorized,
-				Detail:    detail,
-				Code:      "ErrUnauthorized",
-				RequestID: reqID,
-				Message:   detail, // alias to support {code,message,data}
-			}
-			w.Header().Set("Content-Type", "application/problem+json")
-			w.WriteHeader(p.GetStatus())
-			_ = json.NewEncoder(w).Encode(p)
+			writeProblem(http.StatusUnauthorized, "ErrUnauthorized", "urn:problem:auth/err-unauthorized", detail)
 		}
 
 		// 1) Authorization header
@@ -52,18 +77,79 @@ func JWTAuthHuma(provider session.Provider, logger *slog.Logger) func(huma.Conte
 		}
 
 		// 3) Validate session & extend sliding TTL
-		userID, err := provider.GetAndExtend(r.Context(), sessionID)
+		info, err := provider.GetAndExtend(r.Context(), sessionID)
+		if errors.Is(err, session.ErrMalformedToken) {
+			logger.Debug("malformed session token")
+			writeUnauthorized("invalid or expired session")
+			return
+		}
+		if errors.Is(err, session.ErrBindingMismatch) {
+			logger.Warn("session rejected: used from a different client", "ip", contextx.ClientIP(r.Context()), "user_agent", r.UserAgent())
+			writeUnauthorized("invalid or expired session")
+			return
+		}
 		if err != nil {
 			logger.Warn("invalid session", "error", err)
 			writeUnauthorized("invalid or expired session")
 			return
 		}
+		if info.BindingMismatch {
+			logger.Warn("session used from a different client", "user_id", info.UserID, "ip", contextx.ClientIP(r.Context()), "user_agent", r.UserAgent())
+		}
+
+		// 4) Restricted sessions may only call operations that opt in
+		if !scopeAllowed(ctx.Operation(), info.Scope) {
+			writeProblem(http.StatusForbidden, "ErrInsufficientScope", "urn:problem:auth/err-insufficient-scope",
+				"this session is restricted ("+info.Scope+") and cannot call this operation")
+			return
+		}
 
-		// 4) Inject into context for downstream handlers
-		ctx = huma.WithValue(ctx, contextx.UserIDKey, userID)
+		// 5) Inject into context for downstream handlers (and the audit trail)
+		setAuditUser(ctx.Context(), info.UserID)
+		ctx = huma.WithValue(ctx, contextx.UserIDKey, info.UserID)
 		ctx = huma.WithValue(ctx, contextx.SessionIDKey, sessionID)
+		ctx = huma.WithValue(ctx, contextx.SessionScopeKey, info.Scope)
+		ctx = huma.WithValue(ctx, contextx.SessionElevatedKey, info.Elevated)
+		if info.ImpersonatorID != "" {
+			// Impersonation is never silent: every response carries the banner header.
+			ctx = huma.WithValue(ctx, contextx.ImpersonatorIDKey, info.ImpersonatorID)
+			ctx.SetHeader(ImpersonatedByHeader, info.ImpersonatorID)
+		}
 
-		// 5) Continue
+		// 6) Continue
 		next(ctx)
 	}
-}
\ No newline at end of file
+}
+
+// RequireRecentAuth is a per-operation Huma middleware for sensitive operations (sudo mode): it
+// lets the request through only if the session authenticated recently, i.e. was created or
+// re-authenticated within session.Config.ElevationTTL. Other requests get 403
+// ErrRecentAuthRequired, and the client should re-enter the password at
+// POST /users/me/reauthenticate and retry. It must run after JWTAuthHuma, e.g.
+// Middlewares: huma.Middlewares{middleware.RequireRecentAuth} on a protected route.
+func RequireRecentAuth(ctx huma.Context, next func(huma.Context)) {
+	if elevated, _ := ctx.Context().Value(contextx.SessionElevatedKey).(bool); !elevated {
+		r, w := humachi.Unwrap(ctx)
+		writeAuthProblem(r, w, http.StatusForbidden, "ErrRecentAuthRequired", "urn:problem:auth/err-recent-auth-required",
+			"this operation requires recent authentication; re-enter your password and retry")
+		return
+	}
+	next(ctx)
+}
+
+// writeAuthProblem writes an RFC7807 problem+json response from an auth middleware.
+func writeAuthProblem(r *http.Request, w http.ResponseWriter, status int, code, typeURI, detail string) {
+	p := &apphttpx.Problem{
+		Type:      typeURI,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Code:      code,
+		RequestID: chimw.GetReqID(r.Context()),
+		Message:   detail, // alias to support {code,message,data}
+	}
+	apphttpx.RecordProblem(r.Context(), p)
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.GetStatus())
+	_ = json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+)
+
+// ParseTrustedProxies parses TRUSTED_PROXIES entries: CIDR ranges ("10.0.0.0/8") or single
+// addresses ("203.0.113.7").
+func ParseTrustedProxies(specs []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		if p, err := netip.ParsePrefix(spec); err == nil {
+			prefixes = append(prefixes, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(spec)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q: want a CIDR range or an IP address", spec)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// ClientInfo stores the caller's IP address and User-Agent in the request context so
+// services can record them (e.g., on sessions) without depending on *http.Request.
+// X-Forwarded-For and X-Real-IP are honored only when the connecting peer is in trusted;
+// otherwise a client could pick its own address and slip past per-IP limits and screening.
+// The resolved address also replaces r.RemoteAddr for the request logger.
+func ClientInfo(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := clientIP(r, trusted)
+			r.RemoteAddr = ip
+			ctx := context.WithValue(r.Context(), contextx.ClientIPKey, ip)
+			ctx = context.WithValue(ctx, contextx.UserAgentKey, r.UserAgent())
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// clientIP returns the peer address, or, when the peer is a trusted proxy, the nearest
+// untrusted address in X-Forwarded-For (read right to left, as each proxy appends the address
+// it received from), falling back to X-Real-IP.
+func clientIP(r *http.Request, trusted []netip.Prefix) string {
+	peer := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(peer); err == nil {
+		peer = host
+	}
+	if !isTrusted(peer, trusted) {
+		return peer
+	}
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		client := ""
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if _, err := netip.ParseAddr(hop); err != nil {
+				break
+			}
+			client = hop
+			if !isTrusted(hop, trusted) {
+				break
+			}
+		}
+		if client != "" {
+			return client
+		}
+	}
+	if real := strings.TrimSpace(r.Header.Get("X-Real-IP")); real != "" {
+		if _, err := netip.ParseAddr(real); err == nil {
+			return real
+		}
+	}
+	return peer
+}
+
+func isTrusted(ip string, trusted []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/securerand"
+)
+
+// RequireSecret serves next only to requests sending "Authorization: Bearer <secret>", so
+// debug endpoints can sit on the public router. Anyone else gets 404, as if the route did not
+// exist; an empty secret refuses everyone.
+func RequireSecret(secret string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if secret == "" || !ok || !securerand.Equal(strings.TrimSpace(got), secret) {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		next.ServeHTTP(w, r)
+	})
+}
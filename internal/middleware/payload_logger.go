@@ -0,0 +1,248 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/securerand"
+	chimw "github.com/go-chi/chi/v5/middleware"
+)
+
+// DebugCaptureHeader forces capture of a single request when it carries the shared secret.
+// The same header authorizes reading the capture buffer.
+const DebugCaptureHeader = "X-Debug-Capture"
+
+// redactedKeys are JSON/form keys whose values are never captured (matched case-insensitively
+// as substrings, e.g. "newPassword", "sessionToken").
+var redactedKeys = []string{"password", "token", "secret", "code", "authorization", "cookie", "key", "otp"}
+
+// PayloadLoggerConfig configures request/response payload capture.
+type PayloadLoggerConfig struct {
+	// SampleRate is the fraction of requests captured (0 disables sampling; 1 captures all).
+	SampleRate float64
+	// Secret enables forced capture via DebugCaptureHeader and protects the buffer endpoint.
+	// Empty disables both.
+	Secret string
+	// MaxBodyBytes caps how much of each body is captured. Default: 4096.
+	MaxBodyBytes int
+	// BufferSize is how many captures the in-memory ring buffer keeps. Default: 100.
+	BufferSize int
+}
+
+// PayloadCapture is one sanitized request/response pair.
+type PayloadCapture struct {
+	RequestID      string            `json:"requestId"`
+	Method         string            `json:"method"`
+	Path           string            `json:"path"`
+	Status         int               `json:"status"`
+	DurationMs     int64             `json:"durationMs"`
+	RequestHeaders map[string]string `json:"requestHeaders"`
+	RequestBody    string            `json:"requestBody,omitempty"`
+	ResponseBody   string            `json:"responseBody,omitempty"`
+	Forced         bool              `json:"forced"`
+	CapturedAt     time.Time         `json:"capturedAt"`
+}
+
+// PayloadLogger captures sanitized payloads of sampled (or explicitly flagged) requests,
+// logging each capture at debug level and keeping the most recent ones in a ring buffer.
+type PayloadLogger struct {
+	cfg PayloadLoggerConfig
+	log *slog.Logger
+
+	mu   sync.Mutex
+	ring []PayloadCapture
+	next int
+	full bool
+}
+
+// NewPayloadLogger creates a payload logger; mount Middleware on the router and,
+// optionally, Handler on a debug route.
+func NewPayloadLogger(cfg PayloadLoggerConfig, log *slog.Logger) *PayloadLogger {
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = 4096
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 100
+	}
+	return &PayloadLogger{cfg: cfg, log: log, ring: make([]PayloadCapture, cfg.BufferSize)}
+}
+
+// Middleware captures payloads for sampled requests. It must run after chi's RequestID.
+func (p *PayloadLogger) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forced := p.authorized(r)
+		if !forced && (p.cfg.SampleRate <= 0 || rand.Float64() >= p.cfg.SampleRate) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		reqBody, _ := io.ReadAll(io.LimitReader(r.Body, int64(p.cfg.MaxBodyBytes)))
+		// Hand the handler the full body: the captured prefix followed by the unread rest.
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(reqBody), r.Body), r.Body}
+
+		respBody := &cappedBuffer{max: p.cfg.MaxBodyBytes}
+		ww := chimw.NewWrapResponseWriter(w, r.ProtoMajor)
+		ww.Tee(respBody)
+
+		next.ServeHTTP(ww, r)
+
+		c := PayloadCapture{
+			RequestID:      chimw.GetReqID(r.Context()),
+			Method:         r.Method,
+			Path:           r.URL.Path,
+			Status:         ww.Status(),
+			DurationMs:     time.Since(start).Milliseconds(),
+			RequestHeaders: sanitizeHeaders(r.Header),
+			RequestBody:    sanitizeBody(r.Header.Get("Content-Type"), reqBody),
+			ResponseBody:   sanitizeBody(ww.Header().Get("Content-Type"), respBody.Bytes()),
+			Forced:         forced,
+			CapturedAt:     start,
+		}
+		p.store(c)
+		p.log.Debug("payload capture", "capture", c)
+	})
+}
+
+// Handler serves the ring buffer (newest first) to callers presenting the shared secret.
+func (p *PayloadLogger) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !p.authorized(r) {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(w).Encode(p.snapshot())
+	})
+}
+
+func (p *PayloadLogger) authorized(r *http.Request) bool {
+	got := r.Header.Get(DebugCaptureHeader)
+	return p.cfg.Secret != "" && got != "" && securerand.Equal(got, p.cfg.Secret)
+}
+
+func (p *PayloadLogger) store(c PayloadCapture) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.ring[p.next] = c
+	p.next = (p.next + 1) % len(p.ring)
+	if p.next == 0 {
+		p.full = true
+	}
+}
+
+func (p *PayloadLogger) snapshot() []PayloadCapture {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := p.next
+	if p.full {
+		n = len(p.ring)
+	}
+	out := make([]PayloadCapture, 0, n)
+	for i := 1; i <= n; i++ {
+		out = append(out, p.ring[(p.next-i+len(p.ring))%len(p.ring)])
+	}
+	return out
+}
+
+// cappedBuffer keeps the first max bytes written and silently drops the rest.
+type cappedBuffer struct {
+	bytes.Buffer
+	max int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.Len(); room > 0 {
+		if len(p) > room {
+			b.Buffer.Write(p[:room])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func isRedactedKey(k string) bool {
+	k = strings.ToLower(k)
+	for _, s := range redactedKeys {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func sanitizeHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		if isRedactedKey(k) || strings.EqualFold(k, DebugCaptureHeader) {
+			out[k] = "[REDACTED]"
+			continue
+		}
+		out[k] = strings.Join(v, ", ")
+	}
+	return out
+}
+
+// sanitizeBody redacts sensitive fields from JSON and form bodies; other content types
+// are summarized by size only, since they cannot be redacted reliably.
+func sanitizeBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	switch {
+	case strings.Contains(contentType, "json"):
+		var v any
+		if err := json.Unmarshal(body, &v); err != nil {
+			return "[truncated or invalid JSON omitted]"
+		}
+		b, _ := json.Marshal(redactValue(v))
+		return string(b)
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		vals, err := url.ParseQuery(string(body))
+		if err != nil {
+			return "[invalid form omitted]"
+		}
+		for k := range vals {
+			if isRedactedKey(k) {
+				vals[k] = []string{"[REDACTED]"}
+			}
+		}
+		return vals.Encode()
+	default:
+		return "[" + http.DetectContentType(body) + " body omitted]"
+	}
+}
+
+func redactValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			if isRedactedKey(k) {
+				t[k] = "[REDACTED]"
+				continue
+			}
+			t[k] = redactValue(val)
+		}
+		return t
+	case []any:
+		for i := range t {
+			t[i] = redactValue(t[i])
+		}
+		return t
+	default:
+		return v
+	}
+}
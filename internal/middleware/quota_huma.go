@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/quota"
+)
+
+// QuotaHuma counts each authenticated request against the caller's quota and reports
+// consumption in the RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset (seconds) and
+// RateLimit-Policy headers. It must run after the session auth middleware. Requests are
+// never rejected; when Redis is unavailable the headers are omitted.
+func QuotaHuma(tracker *quota.Tracker, logger *slog.Logger) func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		userID, _ := ctx.Context().Value(contextx.UserIDKey).(string)
+		if tracker == nil || userID == "" {
+			next(ctx)
+			return
+		}
+
+		usage, err := tracker.Consume(ctx.Context(), userID)
+		if err != nil {
+			logger.Warn("quota tracking failed", "error", err)
+			next(ctx)
+			return
+		}
+		setQuotaHeaders(ctx, usage)
+		next(ctx)
+	}
+}
+
+// setQuotaHeaders writes the RateLimit-* headers for usage.
+func setQuotaHeaders(ctx huma.Context, usage quota.Usage) {
+	reset := int64(time.Until(usage.ResetAt).Round(time.Second).Seconds())
+	ctx.SetHeader("RateLimit-Limit", strconv.FormatInt(usage.Limit, 10))
+	ctx.SetHeader("RateLimit-Remaining", strconv.FormatInt(usage.Remaining, 10))
+	ctx.SetHeader("RateLimit-Reset", strconv.FormatInt(max(reset, 0), 10))
+	ctx.SetHeader("RateLimit-Policy", strconv.FormatInt(usage.Limit, 10)+";w="+strconv.FormatInt(int64(usage.Window.Seconds()), 10))
+}
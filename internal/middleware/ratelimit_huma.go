@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	apphttpx "github.com/delordemm1/go-api-simple-starter/internal/httpx"
+	"github.com/delordemm1/go-api-simple-starter/internal/ratelimit"
+	chimw "github.com/go-chi/chi/v5/middleware"
+)
+
+// rateLimitBucketKey is the huma.Operation Metadata key read by RateLimitHuma (see RateLimit).
+const rateLimitBucketKey = "rateLimitBucket"
+
+// RateLimit returns operation Metadata that makes RateLimitHuma count calls of the operation in
+// bucket, e.g. Metadata: middleware.MergeMetadata(middleware.Audit(...), middleware.RateLimit(ratelimit.BucketLogin)).
+func RateLimit(bucket string) map[string]any {
+	return map[string]any{rateLimitBucketKey: bucket}
+}
+
+// rateLimitKey carries the *rateLimitState of the current request, for RateLimitAccount.
+type rateLimitKey struct{}
+
+type rateLimitState struct {
+	limiter *ratelimit.Limiter
+	logger  *slog.Logger
+	bucket  string
+}
+
+// RateLimitHuma is an API-wide Huma middleware that enforces the per-IP rule of the bucket an
+// operation declares (see RateLimit); other operations pass through. A client over its limit gets
+// 429 ErrRateLimited with Retry-After. Handlers apply the per-account rule with RateLimitAccount
+// once they know the account. When Redis is unavailable requests are let through.
+func RateLimitHuma(limiter *ratelimit.Limiter, logger *slog.Logger) func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		var bucket string
+		if op := ctx.Operation(); op != nil {
+			bucket, _ = op.Metadata[rateLimitBucketKey].(string)
+		}
+		if limiter == nil || bucket == "" {
+			next(ctx)
+			return
+		}
+
+		res, err := limiter.Allow(ctx.Context(), bucket, ratelimit.ScopeIP, contextx.ClientIP(ctx.Context()))
+		if err != nil {
+			logger.Warn("rate limit check failed; allowing request", "error", err, "bucket", bucket)
+		} else if !res.Allowed {
+			logger.Warn("rate limited", "bucket", bucket, "scope", ratelimit.ScopeIP, "ip", contextx.ClientIP(ctx.Context()))
+			ctx.SetHeader("Retry-After", retryAfterSeconds(res.RetryAfter))
+			apphttpx.WriteProblem(ctx, rateLimitProblem(ctx.Context()))
+			return
+		}
+
+		next(huma.WithValue(ctx, rateLimitKey{}, &rateLimitState{limiter: limiter, logger: logger, bucket: bucket}))
+	}
+}
+
+// RateLimitAccount counts the request against the per-account rule of the operation's bucket,
+// keyed by account (e.g. the submitted email). It returns a 429 error carrying Retry-After, to be
+// returned from the handler as is, or nil when the request may proceed.
+func RateLimitAccount(ctx context.Context, account string) error {
+	st, ok := ctx.Value(rateLimitKey{}).(*rateLimitState)
+	if !ok || account == "" {
+		return nil
+	}
+	res, err := st.limiter.Allow(ctx, st.bucket, ratelimit.ScopeAccount, account)
+	if err != nil {
+		st.logger.Warn("rate limit check failed; allowing request", "error", err, "bucket", st.bucket)
+		return nil
+	}
+	if res.Allowed {
+		return nil
+	}
+	st.logger.Warn("rate limited", "bucket", st.bucket, "scope", ratelimit.ScopeAccount)
+	p := rateLimitProblem(ctx)
+	apphttpx.RecordProblem(ctx, p)
+	return huma.ErrorWithHeaders(p, http.Header{"Retry-After": {retryAfterSeconds(res.RetryAfter)}})
+}
+
+func rateLimitProblem(ctx context.Context) *apphttpx.Problem {
+	const detail = "too many requests; retry after the time in the Retry-After header"
+	return &apphttpx.Problem{
+		Type:      "urn:problem:rate-limited",
+		Title:     http.StatusText(http.StatusTooManyRequests),
+		Status:    http.StatusTooManyRequests,
+		Detail:    detail,
+		Code:      "ErrRateLimited",
+		RequestID: chimw.GetReqID(ctx),
+		Message:   detail,
+	}
+}
+
+// retryAfterSeconds formats d as whole seconds, rounded up so clients never retry early.
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.FormatInt(max(int64((d+time.Second-1)/time.Second), 1), 10)
+}
@@ -0,0 +1,108 @@
+// Package audit keeps a per-user log of security events (logins, logouts, password
+// changes, OAuth links, verifications) with the client's IP and user agent, and serves
+// it at GET /users/security-events.
+//
+// The log is fed from the security event stream: the module's service is a
+// siem.Publisher, so anything the user module reports to the SIEM is also recorded
+// here when its type is one the log keeps.
+package audit
+
+import (
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/siem"
+)
+
+// EventType identifies a recorded security event.
+type EventType string
+
+const (
+	EventLogin           EventType = "login"
+	EventLoginFailed     EventType = "login_failed" // metadata: "reason"
+	EventNewDevice       EventType = "new_device"
+	EventLogout          EventType = "logout" // metadata: "all" when every session was revoked
+	EventPasswordChanged EventType = "password_changed"
+	EventPasswordReset   EventType = "password_reset"
+	EventOAuthLinked     EventType = "oauth_linked"   // metadata: "provider"
+	EventOAuthUnlinked   EventType = "oauth_unlinked" // metadata: "provider"
+	EventEmailVerified   EventType = "email_verified"
+)
+
+// Event is a single entry in a user's security event log.
+type Event struct {
+	ID        string         `db:"id"` // UUIDv7
+	UserID    string         `db:"user_id"`
+	Type      EventType      `db:"event_type"`
+	Outcome   string         `db:"outcome"`
+	IPAddress string         `db:"ip_address"`
+	UserAgent string         `db:"user_agent"`
+	Metadata  map[string]any `db:"metadata"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+// fromSIEM maps a stream event to a log entry. It reports false for events the log
+// does not keep and for events not attributable to a user (e.g., failed logins for
+// unknown emails).
+func fromSIEM(e siem.Event) (*Event, bool) {
+	if e.UserID == "" {
+		return nil, false
+	}
+	reason, _ := e.Metadata["reason"].(string)
+
+	var typ EventType
+	metadata := map[string]any{}
+	switch e.Type {
+	case string(EventLogin), string(EventNewDevice):
+		typ = EventType(e.Type)
+		copyKeys(metadata, e.Metadata, "authMethod")
+	case string(EventLoginFailed):
+		// The attempted email/phone is left out; the event belongs to the account anyway.
+		typ = EventLoginFailed
+		copyKeys(metadata, e.Metadata, "reason")
+	case string(EventPasswordChanged), string(EventPasswordReset), string(EventEmailVerified):
+		typ = EventType(e.Type)
+	case string(EventOAuthLinked), string(EventOAuthUnlinked):
+		typ = EventType(e.Type)
+		copyKeys(metadata, e.Metadata, "provider")
+	case "session_revoked":
+		if reason != "logout" {
+			return nil, false
+		}
+		typ = EventLogout
+	case "sessions_revoked":
+		// logout-all, or the revoke link of a sign-in alert email.
+		if reason != "logout_all" && reason != "sign_in_alert" {
+			return nil, false
+		}
+		typ = EventLogout
+		metadata["all"] = true
+		if reason == "sign_in_alert" {
+			metadata["via"] = reason
+		}
+	default:
+		return nil, false
+	}
+
+	outcome := e.Outcome
+	if outcome == "" {
+		outcome = siem.OutcomeSuccess
+	}
+	return &Event{
+		UserID:    e.UserID,
+		Type:      typ,
+		Outcome:   outcome,
+		IPAddress: e.IPAddress,
+		UserAgent: e.UserAgent,
+		Metadata:  metadata,
+		CreatedAt: e.Time,
+	}, true
+}
+
+// copyKeys copies the named entries present in src into dst.
+func copyKeys(dst, src map[string]any, keys ...string) {
+	for _, k := range keys {
+		if v, ok := src[k]; ok {
+			dst[k] = v
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// DomainError is the audit module's structured error; it satisfies httpx.DomainProblem
+// so handlers can return it through httpx.ToProblem.
+type DomainError struct {
+	Code       string
+	HTTPStatus int
+	Title      string
+	Message    string
+	TypeURI    string
+
+	cause error
+}
+
+func (e *DomainError) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.cause)
+	}
+	return e.Message
+}
+
+func (e *DomainError) Unwrap() error { return e.cause }
+
+// Is matches on Code, so copies made by WithCause match their sentinel.
+func (e *DomainError) Is(target error) bool {
+	t, ok := target.(*DomainError)
+	return ok && e.Code == t.Code
+}
+
+// WithCause returns a copy of the error wrapping err.
+func (e *DomainError) WithCause(err error) *DomainError {
+	cp := *e
+	cp.cause = err
+	return &cp
+}
+
+func (e *DomainError) ProblemCode() string    { return e.Code }
+func (e *DomainError) ProblemStatus() int     { return e.HTTPStatus }
+func (e *DomainError) ProblemTitle() string   { return e.Title }
+func (e *DomainError) ProblemDetail() string  { return e.Message }
+func (e *DomainError) ProblemTypeURI() string { return e.TypeURI }
+func (e *DomainError) ProblemContext() any    { return nil }
+
+var (
+	ErrUnauthorized = &DomainError{
+		Code:       "ErrUnauthorized",
+		HTTPStatus: http.StatusUnauthorized,
+		Title:      "Unauthorized",
+		Message:    "invalid authentication context",
+		TypeURI:    "urn:problem:audit/err-unauthorized",
+	}
+
+	ErrInvalidCursor = &DomainError{
+		Code:       "ErrInvalidCursor",
+		HTTPStatus: http.StatusBadRequest,
+		Title:      "Bad Request",
+		Message:    "invalid pagination cursor",
+		TypeURI:    "urn:problem:audit/err-invalid-cursor",
+	}
+
+	ErrInternal = &DomainError{
+		Code:       "ErrInternal",
+		HTTPStatus: http.StatusInternalServerError,
+		Title:      "Internal Server Error",
+		Message:    "internal server error",
+		TypeURI:    "urn:problem:audit/err-internal",
+	}
+)
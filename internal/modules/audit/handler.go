@@ -0,0 +1,116 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/httpx"
+	"github.com/delordemm1/go-api-simple-starter/internal/mapping"
+	"github.com/delordemm1/go-api-simple-starter/internal/middleware"
+	"github.com/delordemm1/go-api-simple-starter/internal/quota"
+	"github.com/delordemm1/go-api-simple-starter/internal/session"
+	"github.com/delordemm1/go-api-simple-starter/internal/validation"
+)
+
+// Handler serves the audit module's HTTP routes.
+type Handler struct {
+	service  Service
+	logger   *slog.Logger
+	sessions session.Provider
+	quota    *quota.Tracker
+}
+
+// NewHandler creates a new audit handler.
+func NewHandler(service Service, logger *slog.Logger, sessions session.Provider, quota *quota.Tracker) *Handler {
+	return &Handler{
+		service:  service,
+		logger:   logger,
+		sessions: sessions,
+		quota:    quota,
+	}
+}
+
+// RegisterRoutes sets up the routing for the audit module.
+func (h *Handler) RegisterRoutes(api huma.API) {
+	grp := huma.NewGroup(api)
+	grp.UseMiddleware(middleware.JWTAuthHuma(h.sessions, h.logger))
+	grp.UseMiddleware(middleware.QuotaHuma(h.quota, h.logger))
+
+	huma.Register(grp, huma.Operation{
+		Method:  http.MethodGet,
+		Path:    "/users/security-events",
+		Summary: "List the current user's security events",
+		Description: "Logins (including failed attempts), logouts, password changes, OAuth links and " +
+			"email verifications, with the IP address and user agent they came from.",
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.ListHandler)
+}
+
+// --- DTOs ---
+
+// ListRequest carries cursor pagination parameters.
+type ListRequest struct {
+	Cursor string `query:"cursor" doc:"Opaque cursor from a previous page's nextCursor"`
+	Limit  int    `query:"limit" doc:"Page size (1-100, default 20)" validate:"omitempty,gte=1,lte=100"`
+}
+
+// EventItem is a single security event.
+type EventItem struct {
+	ID        string         `json:"id"`
+	Type      string         `json:"type"`
+	Outcome   string         `json:"outcome" enum:"success,failure"`
+	IPAddress string         `json:"ipAddress,omitempty"`
+	UserAgent string         `json:"userAgent,omitempty"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+// ListResponse is a page of security events, newest first.
+type ListResponse struct {
+	Body struct {
+		Items      []EventItem `json:"items"`
+		NextCursor string      `json:"nextCursor,omitempty"`
+	}
+}
+
+// toEventItem maps a domain event to its listing entry.
+func toEventItem(e *Event) EventItem {
+	return EventItem{
+		ID:        e.ID,
+		Type:      string(e.Type),
+		Outcome:   e.Outcome,
+		IPAddress: e.IPAddress,
+		UserAgent: e.UserAgent,
+		Metadata:  e.Metadata,
+		CreatedAt: e.CreatedAt,
+	}
+}
+
+// --- Handlers ---
+
+// ListHandler returns the authenticated user's security events.
+func (h *Handler) ListHandler(ctx context.Context, input *ListRequest) (*ListResponse, error) {
+	userID, ok := ctx.Value(contextx.UserIDKey).(string)
+	if !ok {
+		return nil, httpx.ToProblem(ctx, ErrUnauthorized)
+	}
+	if verr := validation.ValidateStruct(input); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	events, next, err := h.service.List(ctx, userID, input.Cursor, input.Limit)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+
+	var resp ListResponse
+	resp.Body.Items = mapping.Slice(events, toEventItem)
+	resp.Body.NextCursor = next
+	return &resp, nil
+}
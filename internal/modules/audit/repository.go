@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/delordemm1/go-api-simple-starter/internal/database"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
+)
+
+// Repository persists the security event log.
+type Repository interface {
+	Create(ctx context.Context, e *Event) error
+	// ListForUser returns up to limit events for the user, newest first. When beforeID is
+	// set, only events older than that event (by UUIDv7 order) are returned.
+	ListForUser(ctx context.Context, userID string, beforeID string, limit int) ([]*Event, error)
+}
+
+type repository struct {
+	db   database.DBTX
+	psql squirrel.StatementBuilderType
+}
+
+// NewRepository creates a Postgres-backed audit repository.
+func NewRepository(db database.DBTX) Repository {
+	return &repository{
+		db:   db,
+		psql: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func (r *repository) Create(ctx context.Context, e *Event) error {
+	if e.ID == "" {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		e.ID = id.String()
+	}
+
+	sql, args, err := r.psql.Insert("security_events").
+		Columns("id", "user_id", "event_type", "outcome", "ip_address", "user_agent", "metadata", "created_at").
+		Values(e.ID, e.UserID, string(e.Type), e.Outcome, nullableString(e.IPAddress), nullableString(e.UserAgent), e.Metadata, e.CreatedAt).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(ctx, sql, args...)
+	return err
+}
+
+func (r *repository) ListForUser(ctx context.Context, userID string, beforeID string, limit int) ([]*Event, error) {
+	q := r.psql.Select(
+		"id", "user_id", "event_type", "outcome", "COALESCE(ip_address, '') AS ip_address", "COALESCE(user_agent, '') AS user_agent", "metadata", "created_at",
+	).From("security_events").
+		Where(squirrel.Eq{"user_id": userID}).
+		OrderBy("id DESC").
+		Limit(uint64(limit))
+	if beforeID != "" {
+		q = q.Where(squirrel.Lt{"id": beforeID})
+	}
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	var events []*Event
+	if err := pgxscan.Select(ctx, r.db, &events, sql, args...); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// nullableString stores empty strings as NULL.
+func nullableString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
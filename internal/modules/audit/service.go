@@ -0,0 +1,134 @@
+package audit
+
+import (
+	"context"
+	"encoding/base64"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/siem"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	// writeTimeout bounds a single background insert.
+	writeTimeout = 5 * time.Second
+)
+
+// Service records security events and lists them per user. It is a siem.Publisher:
+// events are written in the background so publishing never blocks the request, and
+// Stop (bootstrap lifecycle) waits for pending writes.
+type Service interface {
+	siem.Publisher
+	// List returns a page of the user's security events, newest first, and an opaque
+	// cursor for the next page ("" when there are no more events).
+	List(ctx context.Context, userID string, cursor string, limit int) ([]*Event, string, error)
+	Stop(ctx context.Context) error
+}
+
+type service struct {
+	repo     Repository
+	logger   *slog.Logger
+	inflight sync.WaitGroup
+}
+
+// NewService creates the audit service.
+func NewService(repo Repository, logger *slog.Logger) Service {
+	return &service{repo: repo, logger: logger}
+}
+
+// Publish records e when it is a security event the log keeps; other events are ignored.
+func (s *service) Publish(ctx context.Context, e siem.Event) {
+	ev, ok := fromSIEM(e)
+	if !ok {
+		return
+	}
+	if ev.CreatedAt.IsZero() {
+		ev.CreatedAt = time.Now().UTC()
+	}
+	if ev.IPAddress == "" {
+		ev.IPAddress = contextx.ClientIP(ctx)
+	}
+	if ev.UserAgent == "" {
+		ev.UserAgent = contextx.UserAgent(ctx)
+	}
+
+	// The request context is detached: the write outlives the response.
+	ctx = contextx.Detach(ctx)
+	s.inflight.Add(1)
+	go func() {
+		defer s.inflight.Done()
+		ctx, cancel := context.WithTimeout(ctx, writeTimeout)
+		defer cancel()
+		if err := s.repo.Create(ctx, ev); err != nil {
+			s.logger.Warn("failed to record security event", "error", err, "user_id", ev.UserID, "type", ev.Type)
+		}
+	}()
+}
+
+// Stop waits for pending writes, or until ctx is done.
+func (s *service) Stop(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *service) List(ctx context.Context, userID string, cursor string, limit int) ([]*Event, string, error) {
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	beforeID := ""
+	if cursor != "" {
+		id, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, "", ErrInvalidCursor
+		}
+		beforeID = id
+	}
+
+	// Fetch one extra row to know whether another page exists.
+	events, err := s.repo.ListForUser(ctx, userID, beforeID, limit+1)
+	if err != nil {
+		s.logger.Error("failed to list security events", "error", err, "user_id", userID)
+		return nil, "", ErrInternal.WithCause(err)
+	}
+
+	next := ""
+	if len(events) > limit {
+		events = events[:limit]
+		next = encodeCursor(events[len(events)-1].ID)
+	}
+	return events, next, nil
+}
+
+func encodeCursor(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+func decodeCursor(cursor string) (string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", err
+	}
+	id, err := uuid.Parse(string(b))
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
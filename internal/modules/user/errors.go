@@ -117,6 +117,14 @@ var (
 		TypeURI:    "urn:problem:user/err-not-found",
 	}
 
+	ErrForbidden = &DomainError{
+		Code:       "ErrForbidden",
+		HTTPStatus: http.StatusForbidden,
+		Title:      "Forbidden",
+		Message:    "you do not have permission to perform this action",
+		TypeURI:    "urn:problem:user/err-forbidden",
+	}
+
 	ErrUnauthorized = &DomainError{
 		Code:       "ErrUnauthorized",
 		HTTPStatus: http.StatusUnauthorized,
@@ -176,6 +184,38 @@ var (
 		TypeURI:    "urn:problem:user/err-invalid-reset-token",
 	}
 
+	ErrInvalidRevokeToken = &DomainError{
+		Code:       "ErrInvalidRevokeToken",
+		HTTPStatus: http.StatusBadRequest,
+		Title:      "Bad Request",
+		Message:    "the sign-out link is invalid or has expired",
+		TypeURI:    "urn:problem:user/err-invalid-revoke-token",
+	}
+
+	ErrInvalidCursor = &DomainError{
+		Code:       "ErrInvalidCursor",
+		HTTPStatus: http.StatusBadRequest,
+		Title:      "Bad Request",
+		Message:    "invalid pagination cursor",
+		TypeURI:    "urn:problem:user/err-invalid-cursor",
+	}
+
+	ErrInvalidRecipient = &DomainError{
+		Code:       "ErrInvalidRecipient",
+		HTTPStatus: http.StatusBadRequest,
+		Title:      "Bad Request",
+		Message:    "the recipient is not valid for this channel",
+		TypeURI:    "urn:problem:user/err-invalid-recipient",
+	}
+
+	ErrInvalidLocale = &DomainError{
+		Code:       "ErrInvalidLocale",
+		HTTPStatus: http.StatusBadRequest,
+		Title:      "Bad Request",
+		Message:    "locale must be a BCP 47 language tag (e.g. en-GB) and timeZone an IANA time zone (e.g. Europe/Berlin)",
+		TypeURI:    "urn:problem:user/err-invalid-locale",
+	}
+
 	// Registration
 	ErrEmailExists = &DomainError{
 		Code:       "ErrEmailExists",
@@ -185,6 +225,64 @@ var (
 		TypeURI:    "urn:problem:user/err-email-exists",
 	}
 
+	ErrPhoneExists = &DomainError{
+		Code:       "ErrPhoneExists",
+		HTTPStatus: http.StatusConflict,
+		Title:      "Conflict",
+		Message:    "a user with this phone number already exists",
+		TypeURI:    "urn:problem:user/err-phone-exists",
+	}
+
+	ErrConflict = &DomainError{
+		Code:       "ErrConflict",
+		HTTPStatus: http.StatusConflict,
+		Title:      "Conflict",
+		Message:    "the resource conflicts with an existing one",
+		TypeURI:    "urn:problem:user/err-conflict",
+	}
+
+	// Terms of service
+	ErrTermsAcceptanceRequired = &DomainError{
+		Code:       "ErrTermsAcceptanceRequired",
+		HTTPStatus: http.StatusForbidden,
+		Title:      "Terms Acceptance Required",
+		Message:    "the current terms of service must be accepted before continuing",
+		TypeURI:    "urn:problem:user/err-terms-acceptance-required",
+	}
+
+	ErrTermsVersionMismatch = &DomainError{
+		Code:       "ErrTermsVersionMismatch",
+		HTTPStatus: http.StatusConflict,
+		Title:      "Conflict",
+		Message:    "the accepted version is not the current terms of service",
+		TypeURI:    "urn:problem:user/err-terms-version-mismatch",
+	}
+
+	ErrPasswordResetRequired = &DomainError{
+		Code:       "ErrPasswordResetRequired",
+		HTTPStatus: http.StatusForbidden,
+		Title:      "Password Reset Required",
+		Message:    "the password for this account must be reset before signing in",
+		TypeURI:    "urn:problem:user/err-password-reset-required",
+	}
+
+	// Account deletion
+	ErrAccountPendingDeletion = &DomainError{
+		Code:       "ErrAccountPendingDeletion",
+		HTTPStatus: http.StatusConflict,
+		Title:      "Account Pending Deletion",
+		Message:    "this account was deleted and can still be restored; request a restore code to reactivate it",
+		TypeURI:    "urn:problem:user/err-account-pending-deletion",
+	}
+
+	ErrAccountSuspended = &DomainError{
+		Code:       "ErrAccountSuspended",
+		HTTPStatus: http.StatusForbidden,
+		Title:      "Account Suspended",
+		Message:    "this account has been suspended; contact support",
+		TypeURI:    "urn:problem:user/err-account-suspended",
+	}
+
 	ErrTermsNotAccepted = &DomainError{
 		Code:       "ErrTermsNotAccepted",
 		HTTPStatus: http.StatusBadRequest,
@@ -193,6 +291,57 @@ var (
 		TypeURI:    "urn:problem:user/err-terms-not-accepted",
 	}
 
+	ErrRegistrationRejected = &DomainError{
+		Code:       "ErrRegistrationRejected",
+		HTTPStatus: http.StatusBadRequest,
+		Title:      "Bad Request",
+		Message:    "registration could not be completed",
+		TypeURI:    "urn:problem:user/err-registration-rejected",
+	}
+
+	// IP reputation
+	ErrRequestBlocked = &DomainError{
+		Code:       "ErrRequestBlocked",
+		HTTPStatus: http.StatusForbidden,
+		Title:      "Forbidden",
+		Message:    "requests from this network are not accepted; contact support",
+		TypeURI:    "urn:problem:user/err-request-blocked",
+	}
+
+	// Session issuance guardrail
+	ErrSessionIssuanceBlocked = &DomainError{
+		Code:       "ErrSessionIssuanceBlocked",
+		HTTPStatus: http.StatusTooManyRequests,
+		Title:      "Too Many Requests",
+		Message:    "too many sign-ins in a short time; try again later",
+		TypeURI:    "urn:problem:user/err-session-issuance-blocked",
+	}
+
+	// CAPTCHA
+	ErrCaptchaFailed = &DomainError{
+		Code:       "ErrCaptchaFailed",
+		HTTPStatus: http.StatusBadRequest,
+		Title:      "Bad Request",
+		Message:    "captcha verification failed; solve the challenge and try again",
+		TypeURI:    "urn:problem:user/err-captcha-failed",
+	}
+
+	ErrCaptchaUnavailable = &DomainError{
+		Code:       "ErrCaptchaUnavailable",
+		HTTPStatus: http.StatusServiceUnavailable,
+		Title:      "Service Unavailable",
+		Message:    "captcha verification is temporarily unavailable; try again shortly",
+		TypeURI:    "urn:problem:user/err-captcha-unavailable",
+	}
+
+	ErrLoginStepUpRequired = &DomainError{
+		Code:       "ErrLoginStepUpRequired",
+		HTTPStatus: http.StatusForbidden,
+		Title:      "Verification Required",
+		Message:    "a verification code was sent to your email; confirm it to finish signing in",
+		TypeURI:    "urn:problem:user/err-login-step-up-required",
+	}
+
 	// OAuth
 	ErrUnsupportedOAuthProvider = &DomainError{
 		Code:       "ErrUnsupportedOAuthProvider",
@@ -234,6 +383,39 @@ var (
 		TypeURI:    "urn:problem:user/err-oauth-email-missing",
 	}
 
+	ErrOAuthAccountConflict = &DomainError{
+		Code:       "ErrOAuthAccountConflict",
+		HTTPStatus: http.StatusConflict,
+		Title:      "Conflict",
+		Message:    "the oauth account cannot be linked to this user",
+		TypeURI:    "urn:problem:user/err-oauth-account-conflict",
+	}
+
+	ErrLastSignInMethod = &DomainError{
+		Code:       "ErrLastSignInMethod",
+		HTTPStatus: http.StatusConflict,
+		Title:      "Conflict",
+		Message:    "this is the account's only sign-in method; set a password or link another provider first",
+		TypeURI:    "urn:problem:user/err-last-sign-in-method",
+	}
+
+	ErrOAuthTokenUnavailable = &DomainError{
+		Code:       "ErrOAuthTokenUnavailable",
+		HTTPStatus: http.StatusConflict,
+		Title:      "Conflict",
+		Message:    "no valid provider token is available for this account",
+		TypeURI:    "urn:problem:user/err-oauth-token-unavailable",
+	}
+
+	// Notification templates
+	ErrTemplatesReadOnly = &DomainError{
+		Code:       "ErrTemplatesReadOnly",
+		HTTPStatus: http.StatusConflict,
+		Title:      "Conflict",
+		Message:    "notification templates are read-only; set TEMPLATES_SOURCE to s3 or database to upload them",
+		TypeURI:    "urn:problem:user/err-templates-read-only",
+	}
+
 	// Generic internal
 	ErrInternal = &DomainError{
 		Code:       "ErrInternal",
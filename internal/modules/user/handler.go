@@ -6,6 +6,8 @@ import (
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/delordemm1/go-api-simple-starter/internal/middleware"
+	"github.com/delordemm1/go-api-simple-starter/internal/quota"
+	"github.com/delordemm1/go-api-simple-starter/internal/ratelimit"
 	"github.com/delordemm1/go-api-simple-starter/internal/session"
 )
 
@@ -14,114 +16,623 @@ type Handler struct {
 	service  Service
 	logger   *slog.Logger
 	sessions session.Provider
+	quota    *quota.Tracker // optional; nil disables quota headers and usage reporting
 }
 
 // NewHandler creates a new handler for the user module.
-func NewHandler(service Service, logger *slog.Logger, sessions session.Provider) *Handler {
+func NewHandler(service Service, logger *slog.Logger, sessions session.Provider, quota *quota.Tracker) *Handler {
 	return &Handler{
 		service:  service,
 		logger:   logger,
 		sessions: sessions,
+		quota:    quota,
 	}
 }
 
+// protected returns a group of api requiring a valid session, with quota reporting.
+func (h *Handler) protected(api huma.API) *huma.Group {
+	grp := huma.NewGroup(api)
+	grp.UseMiddleware(middleware.JWTAuthHuma(h.sessions, h.logger))
+	grp.UseMiddleware(middleware.QuotaHuma(h.quota, h.logger))
+	grp.UseMiddleware(h.requireCurrentTerms)
+	return grp
+}
+
 // RegisterRoutes sets up the routing for the user module.
 // It defines all the API endpoints and connects them to their respective handler functions.
 func (h *Handler) RegisterRoutes(api huma.API) {
 	// --- Authentication Routes ---
 	huma.Register(api, huma.Operation{
-		Method:  http.MethodPost,
-		Path:    "/users/register",
-		Summary: "Register a new user",
+		Method:   http.MethodPost,
+		Path:     "/users/register",
+		Summary:  "Register a new user",
+		Metadata: middleware.MergeMetadata(middleware.Audit(middleware.AuditAuth), middleware.RateLimit(ratelimit.BucketRegister)),
 	}, h.RegisterHandler)
 
 	huma.Register(api, huma.Operation{
-		Method:  http.MethodPost,
-		Path:    "/users/login",
-		Summary: "Log in a user",
+		Method:   http.MethodPost,
+		Path:     "/users/login",
+		Summary:  "Log in a user",
+		Metadata: middleware.MergeMetadata(middleware.Audit(middleware.AuditAuth), middleware.RateLimit(ratelimit.BucketLogin)),
 	}, h.LoginHandler)
 
+	huma.Register(api, huma.Operation{
+		Method:   http.MethodPost,
+		Path:     "/users/login/step-up",
+		Summary:  "Finish a login that requires verification with the emailed code",
+		Metadata: middleware.MergeMetadata(middleware.Audit(middleware.AuditAuth), middleware.RateLimit(ratelimit.BucketLogin)),
+	}, h.LoginStepUpHandler)
+
+	// --- Phone Login Routes (SMS one-time code) ---
+	huma.Register(api, huma.Operation{
+		Method:   http.MethodPost,
+		Path:     "/users/phone/register",
+		Summary:  "Register a new user with a phone number",
+		Metadata: middleware.MergeMetadata(middleware.Audit(middleware.AuditAuth), middleware.RateLimit(ratelimit.BucketPhone)),
+	}, h.PhoneRegisterHandler)
+
+	huma.Register(api, huma.Operation{
+		Method:   http.MethodPost,
+		Path:     "/users/phone/code",
+		Summary:  "Request an SMS sign-in code",
+		Metadata: middleware.MergeMetadata(middleware.Audit(middleware.AuditAuth), middleware.RateLimit(ratelimit.BucketPhone)),
+	}, h.PhoneCodeHandler)
+
+	huma.Register(api, huma.Operation{
+		Method:   http.MethodPost,
+		Path:     "/users/phone/login",
+		Summary:  "Log in with an SMS sign-in code",
+		Metadata: middleware.MergeMetadata(middleware.Audit(middleware.AuditAuth), middleware.RateLimit(ratelimit.BucketPhone)),
+	}, h.PhoneLoginHandler)
+
 	// --- Email Verification Routes ---
 	huma.Register(api, huma.Operation{
-		Method:  http.MethodPost,
-		Path:    "/users/verify/email/request",
-		Summary: "Request a 6-digit email verification code",
+		Method:   http.MethodPost,
+		Path:     "/users/verify/email/request",
+		Summary:  "Request an email verification code",
+		Metadata: middleware.Audit(middleware.AuditAuth),
 	}, h.ResendEmailVerificationHandler)
 
 	huma.Register(api, huma.Operation{
-		Method:  http.MethodPost,
-		Path:    "/users/verify/email/confirm",
-		Summary: "Confirm email verification with a 6-digit code",
+		Method:   http.MethodPost,
+		Path:     "/users/verify/email/confirm",
+		Summary:  "Confirm email verification with a one-time code",
+		Metadata: middleware.Audit(middleware.AuditAuth),
 	}, h.ConfirmEmailVerificationHandler)
 
+	huma.Register(api, huma.Operation{
+		Method:        http.MethodGet,
+		Path:          "/users/verify-email",
+		Summary:       "Confirm email verification from the emailed link and redirect to the frontend",
+		DefaultStatus: http.StatusSeeOther,
+		Metadata:      middleware.Audit(middleware.AuditAuth),
+	}, h.VerifyEmailLinkHandler)
+
 	// --- Password Management Routes ---
 	huma.Register(api, huma.Operation{
-		Method:  http.MethodPost,
-		Path:    "/users/password/forgot",
-		Summary: "Initiate password reset",
+		Method:   http.MethodPost,
+		Path:     "/users/password/forgot",
+		Summary:  "Initiate password reset",
+		Metadata: middleware.MergeMetadata(middleware.Audit(middleware.AuditAuth), middleware.RateLimit(ratelimit.BucketPasswordReset)),
 	}, h.ForgotPasswordHandler)
 
 	huma.Register(api, huma.Operation{
-		Method:  http.MethodPost,
-		Path:    "/users/password/code/verify",
-		Summary: "Verify 6-digit code and get a reset token",
+		Method:   http.MethodPost,
+		Path:     "/users/password/code/verify",
+		Summary:  "Verify reset code and get a reset token",
+		Metadata: middleware.MergeMetadata(middleware.Audit(middleware.AuditAuth), middleware.RateLimit(ratelimit.BucketPasswordReset)),
 	}, h.PasswordCodeVerifyHandler)
 
 	huma.Register(api, huma.Operation{
-		Method:  http.MethodPost,
-		Path:    "/users/password/reset",
-		Summary: "Reset password with a token",
+		Method:   http.MethodPost,
+		Path:     "/users/password/reset",
+		Summary:  "Reset password with a token",
+		Metadata: middleware.MergeMetadata(middleware.Audit(middleware.AuditAuth), middleware.RateLimit(ratelimit.BucketPasswordReset)),
 	}, h.ResetPasswordHandler)
 
+	// --- Sign-in Alert Routes ---
+	huma.Register(api, huma.Operation{
+		Method:        http.MethodPost,
+		Path:          "/users/sessions/revoke",
+		Summary:       "Sign out every session with the token from a sign-in alert email",
+		Metadata:      middleware.Audit(middleware.AuditAuth),
+		DefaultStatus: http.StatusNoContent,
+	}, h.RevokeSessionsWithTokenHandler)
+
+	// --- Account Restore Routes ---
+	huma.Register(api, huma.Operation{
+		Method:   http.MethodPost,
+		Path:     "/users/restore/request",
+		Summary:  "Request a code to restore a deleted account",
+		Metadata: middleware.Audit(middleware.AuditAuth),
+	}, h.RequestAccountRestoreHandler)
+
+	huma.Register(api, huma.Operation{
+		Method:   http.MethodPost,
+		Path:     "/users/restore/confirm",
+		Summary:  "Restore a deleted account with a one-time code",
+		Metadata: middleware.Audit(middleware.AuditAuth),
+	}, h.ConfirmAccountRestoreHandler)
+
 	// --- OAuth Routes ---
 	huma.Register(api, huma.Operation{
-		Method:  http.MethodGet,
-		Path:    "/users/oauth/{provider}",
-		Summary: "Initiate OAuth login",
+		Method:      http.MethodGet,
+		Path:        "/users/oauth/{provider}",
+		Summary:     "Initiate OAuth login",
+		Metadata:    middleware.Audit(middleware.AuditAuth),
+		Middlewares: huma.Middlewares{requireSupportedOAuthProvider},
 	}, h.OAuthLoginHandler)
 
 	huma.Register(api, huma.Operation{
-		Method:  http.MethodGet,
-		Path:    "/users/oauth/{provider}/callback",
-		Summary: "Handle OAuth callback",
+		Method:      http.MethodGet,
+		Path:        "/users/oauth/{provider}/callback",
+		Summary:     "Handle OAuth callback",
+		Metadata:    middleware.Audit(middleware.AuditAuth),
+		Middlewares: huma.Middlewares{requireSupportedOAuthProvider},
 	}, h.OAuthCallbackHandler)
 
 	huma.Register(api, huma.Operation{
-		Method:  http.MethodPost,
-		Path:    "/users/oauth/{provider}/callback",
-		Summary: "Handle OAuth callback (form_post for Apple)",
+		Method:      http.MethodPost,
+		Path:        "/users/oauth/{provider}/callback",
+		Summary:     "Handle OAuth callback (form_post for Apple)",
+		Metadata:    middleware.Audit(middleware.AuditAuth),
+		Middlewares: huma.Middlewares{requireSupportedOAuthProvider},
 	}, h.OAuthCallbackPostHandler)
 
 	// --- Protected Group (Session-based auth via Huma middleware) ---
-	grp := huma.NewGroup(api)
-	grp.UseMiddleware(middleware.JWTAuthHuma(h.sessions, h.logger))
+	grp := h.protected(api)
 
 	// --- Profile Routes (requires authentication middleware) ---
 	huma.Register(grp, huma.Operation{
-		Method:  http.MethodGet,
-		Path:    "/users/profile",
-		Summary: "Get the current user's profile",
+		Method:   http.MethodGet,
+		Path:     "/users/profile",
+		Summary:  "Get the current user's profile",
+		Metadata: middleware.MergeMetadata(middleware.AllowScopes(session.ScopeEmailUnverified), allowStaleTerms()),
 		Security: []map[string][]string{
 			{"bearer": {}},
 		},
 	}, h.GetProfileHandler)
 
 	huma.Register(grp, huma.Operation{
-		Method:  http.MethodPatch,
-		Path:    "/users/profile",
-		Summary: "Update the current user's profile",
+		Method:   http.MethodPatch,
+		Path:     "/users/profile",
+		Summary:  "Update the current user's profile",
+		Metadata: middleware.Audit(middleware.AuditProfile),
 		Security: []map[string][]string{
 			{"bearer": {}},
 		},
 	}, h.UpdateProfileHandler)
 
+	// --- Terms of Service (protected) ---
+	huma.Register(grp, huma.Operation{
+		Method:      http.MethodPost,
+		Path:        "/users/me/terms",
+		Summary:     "Accept the current terms of service",
+		Description: "Clears ErrTermsAcceptanceRequired. The version must be the one in TERMS_CURRENT_VERSION.",
+		Metadata:    middleware.MergeMetadata(middleware.Audit(middleware.AuditProfile), allowStaleTerms()),
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.AcceptTermsHandler)
+
+	// --- Password Change (protected) ---
+	huma.Register(grp, huma.Operation{
+		Method:   http.MethodPost,
+		Path:     "/users/password/change",
+		Summary:  "Change the password and sign out other sessions",
+		Metadata: middleware.Audit(middleware.AuditAuth),
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.ChangePasswordHandler)
+
+	// --- Email Change (protected) ---
+	huma.Register(grp, huma.Operation{
+		Method:        http.MethodPost,
+		Path:          "/users/me/email",
+		Summary:       "Request an email change (code sent to the new address)",
+		Description:   "Requires recent authentication (see POST /users/me/reauthenticate).",
+		Metadata:      middleware.Audit(middleware.AuditProfile),
+		Middlewares:   huma.Middlewares{middleware.RequireRecentAuth},
+		DefaultStatus: http.StatusAccepted,
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.RequestEmailChangeHandler)
+
+	huma.Register(grp, huma.Operation{
+		Method:   http.MethodPost,
+		Path:     "/users/me/email/confirm",
+		Summary:  "Confirm the pending email change with the emailed code",
+		Metadata: middleware.Audit(middleware.AuditProfile),
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.ConfirmEmailChangeHandler)
+
+	// --- Current Session (protected) ---
+	huma.Register(grp, huma.Operation{
+		Method:   http.MethodGet,
+		Path:     "/users/me/session",
+		Summary:  "Get details of the current session",
+		Metadata: middleware.MergeMetadata(middleware.AllowScopes(session.ScopeEmailUnverified, session.ScopeRebindRequired), allowStaleTerms()),
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.GetCurrentSessionHandler)
+
+	huma.Register(grp, huma.Operation{
+		Method:   http.MethodPost,
+		Path:     "/users/me/session/rebind",
+		Summary:  "Re-enter the password to keep using the session from a new client",
+		Metadata: middleware.MergeMetadata(middleware.AllowScopes(session.ScopeEmailUnverified, session.ScopeRebindRequired), middleware.Audit(middleware.AuditAuth)),
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.RebindSessionHandler)
+
+	huma.Register(grp, huma.Operation{
+		Method:   http.MethodPost,
+		Path:     "/users/me/reauthenticate",
+		Summary:  "Re-enter the password to unlock sensitive operations for a few minutes (sudo mode)",
+		Metadata: middleware.MergeMetadata(middleware.Audit(middleware.AuditAuth), allowStaleTerms()),
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.ReauthenticateHandler)
+
+	// --- Session (Device) Management (protected) ---
+	huma.Register(grp, huma.Operation{
+		Method:  http.MethodGet,
+		Path:    "/users/sessions",
+		Summary: "List the current user's active sessions",
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.ListSessionsHandler)
+
+	huma.Register(grp, huma.Operation{
+		Method:        http.MethodDelete,
+		Path:          "/users/sessions/{id}",
+		Summary:       "Sign out one of the current user's sessions",
+		Metadata:      middleware.Audit(middleware.AuditAuth),
+		DefaultStatus: http.StatusNoContent,
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.RevokeSessionHandler)
+
+	// --- Account Activity (protected) ---
+	huma.Register(grp, huma.Operation{
+		Method:  http.MethodGet,
+		Path:    "/users/me/activity",
+		Summary: "List the current user's security activity",
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.ListActivityHandler)
+
+	// --- Security checkup (protected) ---
+	huma.Register(grp, huma.Operation{
+		Method:  http.MethodGet,
+		Path:    "/users/me/security",
+		Summary: "Get the current user's security checkup",
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.SecurityCheckupHandler)
+
+	// --- Quota usage (protected) ---
+	huma.Register(grp, huma.Operation{
+		Method:  http.MethodGet,
+		Path:    "/users/me/usage",
+		Summary: "Get the current user's quota consumption",
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.GetUsageHandler)
+
+	// --- Onboarding emails (protected) ---
+	huma.Register(grp, huma.Operation{
+		Method:        http.MethodPost,
+		Path:          "/users/me/onboarding/unsubscribe",
+		Summary:       "Stop the remaining onboarding emails",
+		Metadata:      middleware.Audit(middleware.AuditProfile),
+		DefaultStatus: http.StatusNoContent,
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.UnsubscribeOnboardingHandler)
+
+	// --- Linked OAuth accounts (protected) ---
+	huma.Register(grp, huma.Operation{
+		Method:  http.MethodGet,
+		Path:    "/users/me/oauth",
+		Summary: "List the OAuth accounts linked to the current user",
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.ListOAuthAccountsHandler)
+
+	huma.Register(grp, huma.Operation{
+		Method:      http.MethodPost,
+		Path:        "/users/me/oauth/{provider}/link",
+		Summary:     "Start linking an OAuth account to the current user",
+		Metadata:    middleware.Audit(middleware.AuditAuth),
+		Middlewares: huma.Middlewares{requireSupportedOAuthProvider},
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.LinkOAuthAccountHandler)
+
+	huma.Register(grp, huma.Operation{
+		Method:        http.MethodDelete,
+		Path:          "/users/oauth/{provider}",
+		Summary:       "Disconnect an OAuth provider from the current user",
+		Metadata:      middleware.Audit(middleware.AuditAuth),
+		Middlewares:   huma.Middlewares{requireSupportedOAuthProvider},
+		DefaultStatus: http.StatusNoContent,
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.UnlinkOAuthAccountHandler)
+
+	huma.Register(grp, huma.Operation{
+		Method:        http.MethodDelete,
+		Path:          "/users/me/oauth/{provider}",
+		Summary:       "Unlink an OAuth account from the current user",
+		Description:   "Alias of DELETE /users/oauth/{provider}.",
+		Metadata:      middleware.Audit(middleware.AuditAuth),
+		Middlewares:   huma.Middlewares{requireSupportedOAuthProvider},
+		DefaultStatus: http.StatusNoContent,
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.UnlinkOAuthAccountHandler)
+
+	// --- Account Deletion (protected) ---
+	huma.Register(grp, huma.Operation{
+		Method:        http.MethodDelete,
+		Path:          "/users/me",
+		Summary:       "Delete the current user's account (restorable during the grace period)",
+		Description:   "Requires recent authentication (see POST /users/me/reauthenticate).",
+		Metadata:      middleware.MergeMetadata(middleware.Audit(middleware.AuditProfile), allowStaleTerms()),
+		Middlewares:   huma.Middlewares{middleware.RequireRecentAuth},
+		DefaultStatus: http.StatusNoContent,
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.DeleteAccountHandler)
+
 	// --- Logout (protected) ---
 	huma.Register(grp, huma.Operation{
-		Method:  http.MethodPost,
-		Path:    "/users/logout",
-		Summary: "Logout and invalidate current session",
+		Method:   http.MethodPost,
+		Path:     "/users/logout",
+		Summary:  "Logout and invalidate current session",
+		Metadata: middleware.MergeMetadata(middleware.AllowScopes(session.ScopeEmailUnverified, session.ScopeRebindRequired), middleware.Audit(middleware.AuditAuth), allowStaleTerms()),
 		Security: []map[string][]string{
 			{"bearer": {}},
 		},
 	}, h.LogoutHandler)
+
+	huma.Register(grp, huma.Operation{
+		Method:   http.MethodPost,
+		Path:     "/users/logout-all",
+		Summary:  "Logout everywhere by invalidating every session of the current user",
+		Metadata: middleware.MergeMetadata(middleware.AllowScopes(session.ScopeEmailUnverified, session.ScopeRebindRequired), middleware.Audit(middleware.AuditAuth), allowStaleTerms()),
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.LogoutAllHandler)
+}
+
+// RegisterAdminRoutes sets up the /admin endpoints (admin role required). They are
+// registered separately so deployments can switch the admin module off.
+func (h *Handler) RegisterAdminRoutes(api huma.API) {
+	admin := huma.NewGroup(h.protected(api), "/admin")
+	admin.UseMiddleware(h.requireAdmin)
+	// Every admin operation is audited.
+	admin.UseSimpleModifier(func(op *huma.Operation) {
+		op.Metadata = middleware.MergeMetadata(op.Metadata, middleware.Audit(middleware.AuditAdmin))
+	})
+
+	huma.Register(admin, huma.Operation{
+		Method:  http.MethodGet,
+		Path:    "/users",
+		Summary: "List users with verification/suspension filters and email or name search",
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.ListUsersHandler)
+
+	huma.Register(admin, huma.Operation{
+		Method:        http.MethodPost,
+		Path:          "/users/{id}/suspend",
+		Summary:       "Suspend a user: block sign-in and revoke their sessions",
+		DefaultStatus: http.StatusNoContent,
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.SuspendUserHandler)
+
+	huma.Register(admin, huma.Operation{
+		Method:        http.MethodPost,
+		Path:          "/users/{id}/unsuspend",
+		Summary:       "Lift a user's suspension",
+		DefaultStatus: http.StatusNoContent,
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.UnsuspendUserHandler)
+
+	huma.Register(admin, huma.Operation{
+		Method:        http.MethodPost,
+		Path:          "/users/{id}/force-password-reset",
+		Summary:       "Invalidate a user's password and revoke their sessions",
+		DefaultStatus: http.StatusNoContent,
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.ForcePasswordResetHandler)
+
+	huma.Register(admin, huma.Operation{
+		Method:        http.MethodPost,
+		Path:          "/users/{id}/force-reverify",
+		Summary:       "Require a user to re-verify their email",
+		DefaultStatus: http.StatusNoContent,
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.ForceReverificationHandler)
+
+	huma.Register(admin, huma.Operation{
+		Method:        http.MethodPost,
+		Path:          "/users/{id}/anonymize",
+		Summary:       "Irreversibly anonymize a user's account",
+		DefaultStatus: http.StatusNoContent,
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.AnonymizeUserHandler)
+
+	huma.Register(admin, huma.Operation{
+		Method:      http.MethodPost,
+		Path:        "/users/{id}/impersonate",
+		Summary:     "Open a session as a user (tagged as impersonation; requires recent authentication)",
+		Middlewares: huma.Middlewares{middleware.RequireRecentAuth},
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.ImpersonateUserHandler)
+
+	huma.Register(admin, huma.Operation{
+		Method:        http.MethodPost,
+		Path:          "/sessions/revocations",
+		Summary:       "Revoke sessions in bulk by creation time, IP range or unverified users",
+		DefaultStatus: http.StatusAccepted,
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.StartSessionRevocationHandler)
+
+	huma.Register(admin, huma.Operation{
+		Method:  http.MethodGet,
+		Path:    "/sessions/revocations/{id}",
+		Summary: "Get the progress of a bulk session revocation",
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.GetSessionRevocationHandler)
+
+	huma.Register(admin, huma.Operation{
+		Method:        http.MethodPost,
+		Path:          "/broadcast",
+		Summary:       "Email an announcement to all verified users or to inactive ones",
+		DefaultStatus: http.StatusAccepted,
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.StartBroadcastHandler)
+
+	huma.Register(admin, huma.Operation{
+		Method:  http.MethodGet,
+		Path:    "/broadcast/{id}",
+		Summary: "Get the progress of a broadcast",
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.GetBroadcastHandler)
+
+	huma.Register(admin, huma.Operation{
+		Method:  http.MethodPost,
+		Path:    "/broadcast/{id}/cancel",
+		Summary: "Cancel a running broadcast before its next batch",
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.CancelBroadcastHandler)
+
+	huma.Register(admin, huma.Operation{
+		Method:  http.MethodGet,
+		Path:    "/notifications/dead-letters",
+		Summary: "List notifications whose delivery attempts were exhausted",
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.ListDeadLettersHandler)
+
+	huma.Register(admin, huma.Operation{
+		Method:  http.MethodGet,
+		Path:    "/notifications/dead-letters/{id}",
+		Summary: "Inspect a dead-lettered notification",
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.GetDeadLetterHandler)
+
+	huma.Register(admin, huma.Operation{
+		Method:  http.MethodPatch,
+		Path:    "/notifications/dead-letters/{id}",
+		Summary: "Correct the recipient of a pending dead letter",
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.UpdateDeadLetterHandler)
+
+	huma.Register(admin, huma.Operation{
+		Method:        http.MethodPost,
+		Path:          "/notifications/dead-letters/{id}/requeue",
+		Summary:       "Send a pending dead letter again",
+		DefaultStatus: http.StatusAccepted,
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.RequeueDeadLetterHandler)
+
+	huma.Register(admin, huma.Operation{
+		Method:        http.MethodPost,
+		Path:          "/templates/{id}/test-send",
+		Summary:       "Send a notification template to an address, flagged as a test",
+		DefaultStatus: http.StatusAccepted,
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.TemplateTestSendHandler)
+
+	huma.Register(admin, huma.Operation{
+		Method:  http.MethodGet,
+		Path:    "/templates/{id}",
+		Summary: "Download the source of a notification template",
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.GetTemplateHandler)
+
+	huma.Register(admin, huma.Operation{
+		Method:  http.MethodPut,
+		Path:    "/templates/{id}",
+		Summary: "Upload a new version of a notification template",
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.UploadTemplateHandler)
+
+	huma.Register(admin, huma.Operation{
+		Method:  http.MethodGet,
+		Path:    "/retention",
+		Summary: "Export the data retention policies in effect",
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.ListRetentionPoliciesHandler)
+
+	huma.Register(admin, huma.Operation{
+		Method:  http.MethodGet,
+		Path:    "/runbook",
+		Summary: "Show queue, notification and scheduled job status",
+		Security: []map[string][]string{
+			{"bearer": {}},
+		},
+	}, h.RunbookHandler)
+
 }
@@ -0,0 +1,82 @@
+package user
+
+import (
+	"context"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/httpx"
+	"github.com/delordemm1/go-api-simple-starter/internal/validation"
+)
+
+// --- DTOs ---
+
+// DeleteAccountResponse is an empty successful response.
+type DeleteAccountResponse struct{}
+
+// RequestAccountRestoreRequest asks for a restore code for a soft-deleted account.
+type RequestAccountRestoreRequest struct {
+	Body struct {
+		Email string `json:"email" validate:"required,email"`
+	}
+}
+
+type RequestAccountRestoreResponse struct{}
+
+// ConfirmAccountRestoreRequest restores a soft-deleted account with a one-time code.
+type ConfirmAccountRestoreRequest struct {
+	Body struct {
+		Email string `json:"email" validate:"required,email"`
+		Code  string `json:"code" validate:"required,max=32"`
+	}
+}
+
+// ConfirmAccountRestoreResponse returns a new session for the restored account.
+type ConfirmAccountRestoreResponse struct {
+	Body struct {
+		SessionToken string `json:"sessionToken"`
+	}
+}
+
+// --- Handlers ---
+
+// DeleteAccountHandler soft-deletes the authenticated user's account and signs out all sessions.
+func (h *Handler) DeleteAccountHandler(ctx context.Context, _ *struct{}) (*DeleteAccountResponse, error) {
+	userID, ok := ctx.Value(contextx.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, httpx.ToProblem(ctx, ErrUnauthorized.WithDetail("invalid authentication context"))
+	}
+
+	if err := h.service.DeleteAccount(ctx, userID); err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return &DeleteAccountResponse{}, nil
+}
+
+// RequestAccountRestoreHandler sends a restore code if the account is restorable.
+// It always succeeds for well-formed input to avoid user enumeration.
+func (h *Handler) RequestAccountRestoreHandler(ctx context.Context, input *RequestAccountRestoreRequest) (*RequestAccountRestoreResponse, error) {
+	if verr := validation.ValidateStruct(&input.Body); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	if err := h.service.RequestAccountRestore(ctx, input.Body.Email); err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return &RequestAccountRestoreResponse{}, nil
+}
+
+// ConfirmAccountRestoreHandler validates the restore code, reactivates the account and signs the user in.
+func (h *Handler) ConfirmAccountRestoreHandler(ctx context.Context, input *ConfirmAccountRestoreRequest) (*ConfirmAccountRestoreResponse, error) {
+	if verr := validation.ValidateStruct(&input.Body); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	sessionToken, err := h.service.ConfirmAccountRestore(ctx, input.Body.Email, input.Body.Code)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+
+	resp := &ConfirmAccountRestoreResponse{}
+	resp.Body.SessionToken = sessionToken
+	return resp, nil
+}
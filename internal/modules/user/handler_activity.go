@@ -0,0 +1,77 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/httpx"
+	"github.com/delordemm1/go-api-simple-starter/internal/mapping"
+	"github.com/delordemm1/go-api-simple-starter/internal/validation"
+)
+
+// --- DTOs ---
+
+// ListActivityRequest carries cursor pagination parameters.
+type ListActivityRequest struct {
+	Cursor string `query:"cursor" doc:"Opaque cursor from a previous page's nextCursor"`
+	Limit  int    `query:"limit" doc:"Page size (1-100, default 20)" validate:"omitempty,gte=1,lte=100"`
+}
+
+// ActivityItem is a single timeline entry.
+type ActivityItem struct {
+	ID        string         `json:"id"`
+	Type      string         `json:"type"`
+	IPAddress string         `json:"ipAddress,omitempty"`
+	UserAgent string         `json:"userAgent,omitempty"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+// ListActivityResponse is a page of activity, newest first.
+type ListActivityResponse struct {
+	Body struct {
+		Items      []ActivityItem `json:"items"`
+		NextCursor string         `json:"nextCursor,omitempty"`
+	}
+}
+
+// toActivityItem maps a domain event to its timeline entry.
+func toActivityItem(e *ActivityEvent) ActivityItem {
+	return ActivityItem{
+		ID:        e.ID,
+		Type:      string(e.Type),
+		IPAddress: e.IPAddress,
+		UserAgent: e.UserAgent,
+		Metadata:  e.Metadata,
+		CreatedAt: e.CreatedAt,
+	}
+}
+
+// toListActivityResponse maps domain events to the response DTO.
+func toListActivityResponse(events []*ActivityEvent, next string) *ListActivityResponse {
+	var resp ListActivityResponse
+	resp.Body.Items = mapping.Slice(events, toActivityItem)
+	resp.Body.NextCursor = next
+	return &resp
+}
+
+// --- Handlers ---
+
+// ListActivityHandler returns the authenticated user's security activity timeline.
+func (h *Handler) ListActivityHandler(ctx context.Context, input *ListActivityRequest) (*ListActivityResponse, error) {
+	userID, ok := ctx.Value(contextx.UserIDKey).(string)
+	if !ok {
+		return nil, httpx.ToProblem(ctx, ErrUnauthorized.WithDetail("invalid authentication context"))
+	}
+	if verr := validation.ValidateStruct(input); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	events, next, err := h.service.ListActivity(ctx, userID, input.Cursor, input.Limit)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+
+	return toListActivityResponse(events, next), nil
+}
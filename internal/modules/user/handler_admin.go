@@ -0,0 +1,86 @@
+package user
+
+import (
+	"context"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/httpx"
+	"github.com/delordemm1/go-api-simple-starter/internal/validation"
+)
+
+// --- DTOs ---
+
+// AdminUserActionRequest targets a user by ID with an optional reason recorded in the audit trail.
+type AdminUserActionRequest struct {
+	ID   string `path:"id" validate:"required,uuid"`
+	Body struct {
+		Reason string `json:"reason,omitempty" validate:"omitempty,max=500"`
+	}
+}
+
+type AdminUserActionResponse struct{}
+
+// --- Middleware ---
+
+// requireAdmin rejects authenticated callers without the admin role with ErrForbidden.
+// It must run after the session auth middleware.
+func (h *Handler) requireAdmin(ctx huma.Context, next func(huma.Context)) {
+	userID, _ := ctx.Context().Value(contextx.UserIDKey).(string)
+	if userID == "" {
+		httpx.WriteProblem(ctx, ErrUnauthorized.WithDetail("invalid authentication context"))
+		return
+	}
+
+	ok, err := h.service.IsAdmin(ctx.Context(), userID)
+	if err != nil {
+		httpx.WriteProblem(ctx, err)
+		return
+	}
+	if !ok {
+		httpx.WriteProblem(ctx, ErrForbidden)
+		return
+	}
+	next(ctx)
+}
+
+// --- Handlers ---
+
+// ForcePasswordResetHandler invalidates a user's password and revokes their sessions.
+func (h *Handler) ForcePasswordResetHandler(ctx context.Context, input *AdminUserActionRequest) (*AdminUserActionResponse, error) {
+	if verr := validation.ValidateStruct(input); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	actorID, _ := ctx.Value(contextx.UserIDKey).(string)
+	if err := h.service.ForcePasswordReset(ctx, actorID, input.ID, input.Body.Reason); err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return &AdminUserActionResponse{}, nil
+}
+
+// AnonymizeUserHandler irreversibly anonymizes a user's account.
+func (h *Handler) AnonymizeUserHandler(ctx context.Context, input *AdminUserActionRequest) (*AdminUserActionResponse, error) {
+	if verr := validation.ValidateStruct(input); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	actorID, _ := ctx.Value(contextx.UserIDKey).(string)
+	if err := h.service.AnonymizeUser(ctx, actorID, input.ID, input.Body.Reason); err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return &AdminUserActionResponse{}, nil
+}
+
+// ForceReverificationHandler marks a user's email unverified and sends a new verification code.
+func (h *Handler) ForceReverificationHandler(ctx context.Context, input *AdminUserActionRequest) (*AdminUserActionResponse, error) {
+	if verr := validation.ValidateStruct(input); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	actorID, _ := ctx.Value(contextx.UserIDKey).(string)
+	if err := h.service.ForceReverification(ctx, actorID, input.ID, input.Body.Reason); err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return &AdminUserActionResponse{}, nil
+}
@@ -0,0 +1,116 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/httpx"
+	"github.com/delordemm1/go-api-simple-starter/internal/validation"
+)
+
+// --- DTOs ---
+
+// StartBroadcastRequest describes an announcement and the users it is emailed to.
+type StartBroadcastRequest struct {
+	Body struct {
+		Audience     string `json:"audience" enum:"verified,inactive" validate:"required,oneof=verified inactive" doc:"verified: every live user with a verified email; inactive: those of them with no login for inactiveDays"`
+		InactiveDays *int   `json:"inactiveDays,omitempty" validate:"omitempty,gte=1,lte=3650" doc:"Inactivity threshold for the inactive audience (default 30)"`
+		Subject      string `json:"subject" validate:"required,max=200"`
+		Message      string `json:"message" validate:"required,max=10000" doc:"Plain text; line breaks are kept"`
+		Reason       string `json:"reason" validate:"required,max=500" doc:"Why the announcement is sent (recorded for audit)"`
+	}
+}
+
+// BroadcastRequest targets a broadcast by ID.
+type BroadcastRequest struct {
+	ID string `path:"id" validate:"required,uuid"`
+}
+
+// BroadcastResponse reports a broadcast and its progress.
+type BroadcastResponse struct {
+	Body struct {
+		ID           string     `json:"id"`
+		ActorID      string     `json:"actorId"`
+		Audience     string     `json:"audience" enum:"verified,inactive"`
+		InactiveDays *int       `json:"inactiveDays,omitempty"`
+		Subject      string     `json:"subject"`
+		Reason       string     `json:"reason"`
+		Status       string     `json:"status" enum:"running,completed,failed,cancelled"`
+		Sent         int64      `json:"sent" doc:"Emails handed to the notification service so far"`
+		Failed       int64      `json:"failed" doc:"Recipients whose email could not be queued"`
+		Batches      int        `json:"batches" doc:"Recipient batches processed so far"`
+		Error        *string    `json:"error,omitempty"`
+		CreatedAt    time.Time  `json:"createdAt"`
+		UpdatedAt    time.Time  `json:"updatedAt"`
+		FinishedAt   *time.Time `json:"finishedAt,omitempty"`
+	}
+}
+
+func toBroadcastResponse(b *Broadcast) *BroadcastResponse {
+	var resp BroadcastResponse
+	resp.Body.ID = b.ID
+	resp.Body.ActorID = b.ActorID
+	resp.Body.Audience = string(b.Audience)
+	resp.Body.InactiveDays = b.InactiveDays
+	resp.Body.Subject = b.Subject
+	resp.Body.Reason = b.Reason
+	resp.Body.Status = string(b.Status)
+	resp.Body.Sent = b.Sent
+	resp.Body.Failed = b.Failed
+	resp.Body.Batches = b.Batches
+	resp.Body.Error = b.Error
+	resp.Body.CreatedAt = b.CreatedAt
+	resp.Body.UpdatedAt = b.UpdatedAt
+	resp.Body.FinishedAt = b.FinishedAt
+	return &resp
+}
+
+// --- Handlers ---
+
+// StartBroadcastHandler starts emailing an announcement to the selected audience.
+func (h *Handler) StartBroadcastHandler(ctx context.Context, input *StartBroadcastRequest) (*BroadcastResponse, error) {
+	if verr := validation.ValidateStruct(input); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	actorID, _ := ctx.Value(contextx.UserIDKey).(string)
+	b, err := h.service.StartBroadcast(ctx, actorID, BroadcastInput{
+		Audience:     BroadcastAudience(input.Body.Audience),
+		InactiveDays: input.Body.InactiveDays,
+		Subject:      input.Body.Subject,
+		Message:      input.Body.Message,
+		Reason:       input.Body.Reason,
+	})
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return toBroadcastResponse(b), nil
+}
+
+// GetBroadcastHandler reports the progress of a broadcast.
+func (h *Handler) GetBroadcastHandler(ctx context.Context, input *BroadcastRequest) (*BroadcastResponse, error) {
+	if verr := validation.ValidateStruct(input); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	b, err := h.service.GetBroadcast(ctx, input.ID)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return toBroadcastResponse(b), nil
+}
+
+// CancelBroadcastHandler stops a running broadcast before its next batch.
+func (h *Handler) CancelBroadcastHandler(ctx context.Context, input *BroadcastRequest) (*BroadcastResponse, error) {
+	if verr := validation.ValidateStruct(input); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	actorID, _ := ctx.Value(contextx.UserIDKey).(string)
+	b, err := h.service.CancelBroadcast(ctx, actorID, input.ID)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return toBroadcastResponse(b), nil
+}
@@ -0,0 +1,47 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/httpx"
+	"github.com/delordemm1/go-api-simple-starter/internal/validation"
+)
+
+// --- DTOs ---
+
+// ImpersonateUserRequest targets the user to act as; the reason is required for the audit trail.
+type ImpersonateUserRequest struct {
+	ID   string `path:"id" validate:"required,uuid"`
+	Body struct {
+		Reason string `json:"reason" validate:"required,max=500"`
+	}
+}
+
+// ImpersonateUserResponse returns the impersonation session token.
+type ImpersonateUserResponse struct {
+	Body struct {
+		SessionToken string    `json:"sessionToken"`
+		ExpiresAt    time.Time `json:"expiresAt"`
+	}
+}
+
+// --- Handlers ---
+
+// ImpersonateUserHandler opens a session as the target user on behalf of the calling admin.
+func (h *Handler) ImpersonateUserHandler(ctx context.Context, input *ImpersonateUserRequest) (*ImpersonateUserResponse, error) {
+	if verr := validation.ValidateStruct(input); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	actorID, _ := ctx.Value(contextx.UserIDKey).(string)
+	sessionToken, expiresAt, err := h.service.Impersonate(ctx, actorID, input.ID, input.Body.Reason)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	resp := &ImpersonateUserResponse{}
+	resp.Body.SessionToken = sessionToken
+	resp.Body.ExpiresAt = expiresAt
+	return resp, nil
+}
@@ -0,0 +1,238 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/httpx"
+	"github.com/delordemm1/go-api-simple-starter/internal/mapping"
+	"github.com/delordemm1/go-api-simple-starter/internal/notification"
+	"github.com/delordemm1/go-api-simple-starter/internal/validation"
+)
+
+// --- DTOs ---
+
+// ListDeadLettersRequest filters and paginates dead letters.
+type ListDeadLettersRequest struct {
+	Status string `query:"status" enum:"pending,requeued" doc:"Filter by status (default: all)"`
+	Cursor string `query:"cursor" doc:"Opaque cursor from a previous page's nextCursor"`
+	Limit  int    `query:"limit" doc:"Page size (1-100, default 20)" validate:"omitempty,gte=1,lte=100"`
+}
+
+// DeadLetterRequest targets a dead letter by ID.
+type DeadLetterRequest struct {
+	ID string `path:"id" validate:"required,uuid"`
+}
+
+// UpdateDeadLetterRequest corrects the recipient of a pending dead letter.
+type UpdateDeadLetterRequest struct {
+	ID   string `path:"id" validate:"required,uuid"`
+	Body struct {
+		Recipient string `json:"recipient" validate:"required,max=320"`
+	}
+}
+
+// DeadLetterItem summarizes a dead letter.
+type DeadLetterItem struct {
+	ID         string     `json:"id"`
+	Channel    string     `json:"channel"`
+	Recipient  string     `json:"recipient"`
+	Priority   string     `json:"priority"`
+	TemplateID string     `json:"templateId,omitempty"`
+	Attempts   int        `json:"attempts"`
+	LastError  string     `json:"lastError"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	RequeuedAt *time.Time `json:"requeuedAt,omitempty"`
+}
+
+// DeadLetterDetail is a dead letter with the rendered content that will be resent.
+type DeadLetterDetail struct {
+	DeadLetterItem
+	EmailSubject  string `json:"emailSubject,omitempty"`
+	EmailTextBody string `json:"emailTextBody,omitempty"`
+	SMSText       string `json:"smsText,omitempty"`
+}
+
+// ListDeadLettersResponse is a page of dead letters, newest first.
+type ListDeadLettersResponse struct {
+	Body struct {
+		Items      []DeadLetterItem `json:"items"`
+		NextCursor string           `json:"nextCursor,omitempty"`
+	}
+}
+
+// DeadLetterResponse returns a single dead letter.
+type DeadLetterResponse struct {
+	Body DeadLetterDetail
+}
+
+type RequeueDeadLetterResponse struct{}
+
+// TemplateTestSendRequest sends a template to an operator-chosen address.
+type TemplateTestSendRequest struct {
+	ID   string `path:"id" validate:"required,max=200" doc:"Template ID, e.g. user.verify_email"`
+	Body struct {
+		Recipient string          `json:"recipient" validate:"required,max=320" doc:"Email address or E.164 phone number"`
+		Channel   string          `json:"channel" enum:"email,sms" default:"email" validate:"required,oneof=email sms"`
+		Data      json.RawMessage `json:"data,omitempty" doc:"Template data overriding the sample values field by field; keys are the data struct's field names, e.g. FirstName"`
+	}
+}
+
+type TemplateTestSendResponse struct{}
+
+// TemplateRequest targets a notification template by ID.
+type TemplateRequest struct {
+	ID string `path:"id" validate:"required,max=200" doc:"Template ID, e.g. user.verify_email"`
+}
+
+// UploadTemplateRequest replaces the source of a notification template.
+type UploadTemplateRequest struct {
+	ID   string `path:"id" validate:"required,max=200" doc:"Template ID, e.g. user.verify_email"`
+	Body struct {
+		Content string `json:"content" validate:"required,max=262144" doc:"Template source defining the subject, email_text, email_html, ... blocks"`
+	}
+}
+
+// TemplateResponse returns a template's source and the version it is stored at.
+type TemplateResponse struct {
+	Body struct {
+		ID      string `json:"id"`
+		Version string `json:"version"`
+		Content string `json:"content,omitempty"`
+	}
+}
+
+func toDeadLetterItem(d *notification.DeadLetter) DeadLetterItem {
+	return DeadLetterItem{
+		ID:         d.ID,
+		Channel:    string(d.Channel),
+		Recipient:  d.Recipient,
+		Priority:   string(d.Priority),
+		TemplateID: d.TemplateID,
+		Attempts:   d.Attempts,
+		LastError:  d.LastError,
+		Status:     string(d.Status),
+		CreatedAt:  d.CreatedAt,
+		UpdatedAt:  d.UpdatedAt,
+		RequeuedAt: d.RequeuedAt,
+	}
+}
+
+func toDeadLetterResponse(d *notification.DeadLetter) *DeadLetterResponse {
+	return &DeadLetterResponse{Body: DeadLetterDetail{
+		DeadLetterItem: toDeadLetterItem(d),
+		EmailSubject:   d.Content.EmailSubject,
+		EmailTextBody:  d.Content.EmailTextBody,
+		SMSText:        d.Content.SMSText,
+	}}
+}
+
+// --- Handlers ---
+
+// ListDeadLettersHandler lists notifications whose delivery attempts were exhausted.
+func (h *Handler) ListDeadLettersHandler(ctx context.Context, input *ListDeadLettersRequest) (*ListDeadLettersResponse, error) {
+	if verr := validation.ValidateStruct(input); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	items, next, err := h.service.ListDeadLetters(ctx, input.Status, input.Cursor, input.Limit)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+
+	var resp ListDeadLettersResponse
+	resp.Body.Items = mapping.Slice(items, toDeadLetterItem)
+	resp.Body.NextCursor = next
+	return &resp, nil
+}
+
+// GetDeadLetterHandler returns a dead letter with its content.
+func (h *Handler) GetDeadLetterHandler(ctx context.Context, input *DeadLetterRequest) (*DeadLetterResponse, error) {
+	if verr := validation.ValidateStruct(input); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	d, err := h.service.GetDeadLetter(ctx, input.ID)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return toDeadLetterResponse(d), nil
+}
+
+// UpdateDeadLetterHandler changes the recipient of a pending dead letter.
+func (h *Handler) UpdateDeadLetterHandler(ctx context.Context, input *UpdateDeadLetterRequest) (*DeadLetterResponse, error) {
+	if verr := validation.ValidateStruct(input); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	actorID, _ := ctx.Value(contextx.UserIDKey).(string)
+	d, err := h.service.UpdateDeadLetterRecipient(ctx, actorID, input.ID, input.Body.Recipient)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return toDeadLetterResponse(d), nil
+}
+
+// RequeueDeadLetterHandler sends a pending dead letter again.
+func (h *Handler) RequeueDeadLetterHandler(ctx context.Context, input *DeadLetterRequest) (*RequeueDeadLetterResponse, error) {
+	if verr := validation.ValidateStruct(input); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	actorID, _ := ctx.Value(contextx.UserIDKey).(string)
+	if err := h.service.RequeueDeadLetter(ctx, actorID, input.ID); err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return &RequeueDeadLetterResponse{}, nil
+}
+
+// TemplateTestSendHandler renders a template and sends it, flagged as a test, through the real pipeline.
+func (h *Handler) TemplateTestSendHandler(ctx context.Context, input *TemplateTestSendRequest) (*TemplateTestSendResponse, error) {
+	if verr := validation.ValidateStruct(input); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	actorID, _ := ctx.Value(contextx.UserIDKey).(string)
+	if err := h.service.SendTemplateTest(ctx, actorID, input.ID, input.Body.Recipient, notification.Channel(input.Body.Channel), input.Body.Data); err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return &TemplateTestSendResponse{}, nil
+}
+
+// GetTemplateHandler returns the source of a notification template as currently served.
+func (h *Handler) GetTemplateHandler(ctx context.Context, input *TemplateRequest) (*TemplateResponse, error) {
+	if verr := validation.ValidateStruct(input); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	content, version, err := h.service.GetTemplate(ctx, input.ID)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	var resp TemplateResponse
+	resp.Body.ID = input.ID
+	resp.Body.Version = version
+	resp.Body.Content = content
+	return &resp, nil
+}
+
+// UploadTemplateHandler validates and stores a new version of a notification template.
+func (h *Handler) UploadTemplateHandler(ctx context.Context, input *UploadTemplateRequest) (*TemplateResponse, error) {
+	if verr := validation.ValidateStruct(input); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	actorID, _ := ctx.Value(contextx.UserIDKey).(string)
+	version, err := h.service.UploadTemplate(ctx, actorID, input.ID, input.Body.Content)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	var resp TemplateResponse
+	resp.Body.ID = input.ID
+	resp.Body.Version = version
+	return &resp, nil
+}
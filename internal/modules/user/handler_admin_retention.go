@@ -0,0 +1,41 @@
+package user
+
+import (
+	"context"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/mapping"
+)
+
+// --- DTOs ---
+
+// RetentionPolicyItem is one retention policy.
+type RetentionPolicyItem struct {
+	Target string `json:"target" enum:"audit,activity,deliveries,verification_codes"`
+	Table  string `json:"table" doc:"Database table the policy applies to"`
+	Days   int    `json:"days" doc:"Records older than this many days are deleted; 0 keeps them forever"`
+}
+
+// ListRetentionPoliciesResponse exports the data retention policies in effect.
+type ListRetentionPoliciesResponse struct {
+	Body struct {
+		Items []RetentionPolicyItem `json:"items"`
+	}
+}
+
+// toRetentionPolicyItem maps a retention policy to its listing entry.
+func toRetentionPolicyItem(p RetentionPolicy) RetentionPolicyItem {
+	return RetentionPolicyItem{
+		Target: string(p.Target),
+		Table:  p.Table,
+		Days:   p.Days,
+	}
+}
+
+// --- Handlers ---
+
+// ListRetentionPoliciesHandler returns the configured retention policies.
+func (h *Handler) ListRetentionPoliciesHandler(ctx context.Context, _ *struct{}) (*ListRetentionPoliciesResponse, error) {
+	var resp ListRetentionPoliciesResponse
+	resp.Body.Items = mapping.Slice(h.service.RetentionPolicies(), toRetentionPolicyItem)
+	return &resp, nil
+}
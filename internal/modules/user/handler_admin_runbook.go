@@ -0,0 +1,39 @@
+package user
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/runbook"
+)
+
+// --- DTOs ---
+
+// RunbookResponse shows whether asynchronous processing is keeping up.
+type RunbookResponse struct {
+	Body struct {
+		GeneratedAt time.Time `json:"generatedAt"`
+		Stalled     []string  `json:"stalled" doc:"Components that look stalled; empty when everything is keeping up"`
+		// Components holds each subsystem's snapshot: notification dispatch backlog and dead
+		// letters, job queue depth and worker heartbeats, and scheduled job runs.
+		Components map[string]runbook.Status `json:"components"`
+	}
+}
+
+// --- Handlers ---
+
+// RunbookHandler returns the status of background subsystems.
+func (h *Handler) RunbookHandler(ctx context.Context, _ *struct{}) (*RunbookResponse, error) {
+	var resp RunbookResponse
+	resp.Body.GeneratedAt = time.Now().UTC()
+	resp.Body.Components = h.service.Runbook(ctx)
+	resp.Body.Stalled = []string{}
+	for name, status := range resp.Body.Components {
+		if status.Stalled {
+			resp.Body.Stalled = append(resp.Body.Stalled, name)
+		}
+	}
+	slices.Sort(resp.Body.Stalled)
+	return &resp, nil
+}
@@ -0,0 +1,111 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/httpx"
+	"github.com/delordemm1/go-api-simple-starter/internal/mapping"
+	"github.com/delordemm1/go-api-simple-starter/internal/validation"
+)
+
+// --- DTOs ---
+
+// ListUsersRequest filters, searches, and paginates the admin user list.
+type ListUsersRequest struct {
+	Verified string `query:"verified" enum:"true,false" doc:"Filter by email verification (default: all)"`
+	Status   string `query:"status" enum:"active,suspended" doc:"Filter by suspension (default: all)"`
+	Q        string `query:"q" doc:"Case-insensitive search in email, first name, and last name" validate:"omitempty,max=200"`
+	Cursor   string `query:"cursor" doc:"Opaque cursor from a previous page's nextCursor"`
+	Limit    int    `query:"limit" doc:"Page size (1-100, default 20)" validate:"omitempty,gte=1,lte=100"`
+}
+
+// AdminUserItem summarizes a user for admin tooling.
+type AdminUserItem struct {
+	UserDTO
+	Role                  string     `json:"role"`
+	PasswordResetRequired bool       `json:"passwordResetRequired"`
+	SuspendedAt           *time.Time `json:"suspendedAt,omitempty"`
+	SuspendedReason       string     `json:"suspendedReason,omitempty"`
+	DeletedAt             *time.Time `json:"deletedAt,omitempty"`
+}
+
+// ListUsersResponse is a page of users, newest first.
+type ListUsersResponse struct {
+	Body struct {
+		Items      []AdminUserItem `json:"items"`
+		NextCursor string          `json:"nextCursor,omitempty"`
+	}
+}
+
+// toAdminUserItem maps a domain user to its admin listing entry.
+func toAdminUserItem(u *User) AdminUserItem {
+	return AdminUserItem{
+		UserDTO:               toUserDTO(u),
+		Role:                  string(u.Role),
+		PasswordResetRequired: u.PasswordResetRequired,
+		SuspendedAt:           u.SuspendedAt,
+		SuspendedReason:       mapping.Value(u.SuspendedReason),
+		DeletedAt:             u.DeletedAt,
+	}
+}
+
+// toListUsersResponse maps domain users to the response DTO.
+func toListUsersResponse(users []*User, next string) *ListUsersResponse {
+	var resp ListUsersResponse
+	resp.Body.Items = mapping.Slice(users, toAdminUserItem)
+	resp.Body.NextCursor = next
+	return &resp
+}
+
+// --- Handlers ---
+
+// ListUsersHandler lists users for admin tooling.
+func (h *Handler) ListUsersHandler(ctx context.Context, input *ListUsersRequest) (*ListUsersResponse, error) {
+	if verr := validation.ValidateStruct(input); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	filter := UserFilter{Query: input.Q}
+	if input.Verified != "" {
+		verified := input.Verified == "true"
+		filter.EmailVerified = &verified
+	}
+	if input.Status != "" {
+		suspended := input.Status == "suspended"
+		filter.Suspended = &suspended
+	}
+
+	users, next, err := h.service.ListUsers(ctx, filter, input.Cursor, input.Limit)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return toListUsersResponse(users, next), nil
+}
+
+// SuspendUserHandler blocks a user from signing in and revokes their sessions.
+func (h *Handler) SuspendUserHandler(ctx context.Context, input *AdminUserActionRequest) (*AdminUserActionResponse, error) {
+	if verr := validation.ValidateStruct(input); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	actorID, _ := ctx.Value(contextx.UserIDKey).(string)
+	if err := h.service.SuspendUser(ctx, actorID, input.ID, input.Body.Reason); err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return &AdminUserActionResponse{}, nil
+}
+
+// UnsuspendUserHandler lifts a user's suspension.
+func (h *Handler) UnsuspendUserHandler(ctx context.Context, input *AdminUserActionRequest) (*AdminUserActionResponse, error) {
+	if verr := validation.ValidateStruct(input); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	actorID, _ := ctx.Value(contextx.UserIDKey).(string)
+	if err := h.service.UnsuspendUser(ctx, actorID, input.ID, input.Body.Reason); err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return &AdminUserActionResponse{}, nil
+}
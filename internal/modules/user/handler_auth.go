@@ -2,9 +2,12 @@ package user
 
 import (
 	"context"
+	"time"
 
+	"github.com/delordemm1/go-api-simple-starter/internal/captcha"
 	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
 	"github.com/delordemm1/go-api-simple-starter/internal/httpx"
+	"github.com/delordemm1/go-api-simple-starter/internal/middleware"
 	"github.com/delordemm1/go-api-simple-starter/internal/validation"
 )
 
@@ -23,15 +26,28 @@ func (h *Handler) LogoutHandler(ctx context.Context, _ *struct{}) (*LogoutRespon
 		return nil, httpx.ToProblem(ctx, ErrUnauthorized.WithDetail("invalid authentication context"))
 	}
 
-	if err := h.sessions.Delete(ctx, sessionID); err != nil {
+	userID, _ := ctx.Value(contextx.UserIDKey).(string)
+	if err := h.service.Logout(ctx, userID, sessionID); err != nil {
 		// Deletion should be idempotent; but if provider returns error, map to generic
-		h.logger.Warn("failed to delete session on logout", "error", err)
 		return nil, httpx.ToProblem(ctx, ErrInternal.WithDetail("logout failed"))
 	}
 
 	return &LogoutResponse{}, nil
 }
 
+// LogoutAllHandler deletes every session of the authenticated user, the current one included.
+func (h *Handler) LogoutAllHandler(ctx context.Context, _ *struct{}) (*LogoutResponse, error) {
+	userID, _ := ctx.Value(contextx.UserIDKey).(string)
+	if userID == "" {
+		return nil, httpx.ToProblem(ctx, ErrUnauthorized.WithDetail("invalid authentication context"))
+	}
+
+	if err := h.service.LogoutAll(ctx, userID); err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return &LogoutResponse{}, nil
+}
+
 // --- DTOs (Data Transfer Objects) ---
 
 // RegisterRequest defines the structure for the user registration request body.
@@ -43,24 +59,27 @@ type RegisterRequest struct {
 		Password        string `json:"password" validate:"required,min=8"`
 		ConfirmPassword string `json:"confirmPassword" validate:"required,eqfield=Password"`
 		AcceptTerms     bool   `json:"acceptTerms" validate:"required,eq=true"`
+
+		// Bot deterrents: Website is a hidden honeypot input that must stay empty;
+		// FormRenderedAt is the unix time (seconds) at which the form was rendered.
+		Website        string `json:"website,omitempty"`
+		FormRenderedAt int64  `json:"formRenderedAt,omitempty"`
+		CaptchaToken   string `json:"captchaToken,omitempty" doc:"Solved CAPTCHA token; required when CAPTCHA_ENDPOINTS includes register"`
 	}
 }
 
 // RegisterResponse defines the structure for a successful registration response.
 type RegisterResponse struct {
-	Body struct {
-		ID        string `json:"id"`
-		FirstName string `json:"firstName"`
-		LastName  string `json:"lastName"`
-		Email     string `json:"email"`
-	}
+	Body UserDTO
 }
 
 // LoginRequest defines the structure for the user login request body.
 type LoginRequest struct {
 	Body struct {
-		Email    string `json:"email" validate:"required,email"`
-		Password string `json:"password" validate:"required"`
+		Email        string `json:"email" validate:"required,email"`
+		Password     string `json:"password" validate:"required"`
+		RememberMe   bool   `json:"rememberMe,omitempty" doc:"Issue a long-lived session (SESSION_REMEMBER_ME_* lifetimes)"`
+		CaptchaToken string `json:"captchaToken,omitempty" doc:"Solved CAPTCHA token; required when CAPTCHA_ENDPOINTS includes login"`
 	}
 }
 
@@ -71,24 +90,20 @@ type LoginResponse struct {
 	}
 }
 
+// LoginStepUpRequest finishes a login that failed with ErrLoginStepUpRequired.
+type LoginStepUpRequest struct {
+	Body struct {
+		Email      string `json:"email" validate:"required,email"`
+		Code       string `json:"code" validate:"required,max=32"`
+		RememberMe bool   `json:"rememberMe,omitempty" doc:"Same as on the login that required the step-up"`
+	}
+}
+
 // --- Mapper ---
 
 // toRegisterResponse converts a domain User object to a RegisterResponse DTO.
 func toRegisterResponse(user *User) *RegisterResponse {
-
-	return &RegisterResponse{
-		Body: struct {
-			ID        string `json:"id"`
-			FirstName string `json:"firstName"`
-			LastName  string `json:"lastName"`
-			Email     string `json:"email"`
-		}{
-			ID:        user.ID,
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-			Email:     user.Email,
-		},
-	}
+	return &RegisterResponse{Body: toUserDTO(user)}
 }
 
 // --- Handlers ---
@@ -100,6 +115,17 @@ func (h *Handler) RegisterHandler(ctx context.Context, input *RegisterRequest) (
 		return nil, httpx.ToProblem(ctx, verr)
 	}
 
+	signals := RegistrationSignals{Honeypot: input.Body.Website}
+	if input.Body.FormRenderedAt > 0 {
+		signals.FormRenderedAt = time.Unix(input.Body.FormRenderedAt, 0)
+	}
+	if err := h.service.ScreenRegistration(ctx, signals); err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	if err := h.service.VerifyCaptcha(ctx, captcha.EndpointRegister, input.Body.CaptchaToken); err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+
 	user, err := h.service.Register(ctx, input.Body.FirstName, input.Body.LastName, input.Body.Email, input.Body.Password)
 	if err != nil {
 		h.logger.Error("registration failed", "error", err)
@@ -117,8 +143,15 @@ func (h *Handler) LoginHandler(ctx context.Context, input *LoginRequest) (*Login
 		return nil, httpx.ToProblem(ctx, verr)
 	}
 
+	if err := h.service.VerifyCaptcha(ctx, captcha.EndpointLogin, input.Body.CaptchaToken); err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	if err := middleware.RateLimitAccount(ctx, input.Body.Email); err != nil {
+		return nil, err
+	}
+
 	// Authenticate and issue a session ID
-	sessionToken, err := h.service.Login(ctx, input.Body.Email, input.Body.Password)
+	sessionToken, err := h.service.Login(ctx, input.Body.Email, input.Body.Password, input.Body.RememberMe)
 	if err != nil {
 		h.logger.Warn("login attempt failed", "email", input.Body.Email, "error", err)
 		return nil, httpx.ToProblem(ctx, err)
@@ -129,3 +162,23 @@ func (h *Handler) LoginHandler(ctx context.Context, input *LoginRequest) (*Login
 	resp.Body.SessionToken = sessionToken
 	return resp, nil
 }
+
+// LoginStepUpHandler validates the emailed step-up code and issues the login's session.
+func (h *Handler) LoginStepUpHandler(ctx context.Context, input *LoginStepUpRequest) (*LoginResponse, error) {
+	if verr := validation.ValidateStruct(&input.Body); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+	if err := middleware.RateLimitAccount(ctx, input.Body.Email); err != nil {
+		return nil, err
+	}
+
+	sessionToken, err := h.service.ConfirmLoginStepUp(ctx, input.Body.Email, input.Body.Code, input.Body.RememberMe)
+	if err != nil {
+		h.logger.Warn("login step-up failed", "email", input.Body.Email, "error", err)
+		return nil, httpx.ToProblem(ctx, err)
+	}
+
+	resp := &LoginResponse{}
+	resp.Body.SessionToken = sessionToken
+	return resp, nil
+}
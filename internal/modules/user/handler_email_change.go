@@ -0,0 +1,67 @@
+package user
+
+import (
+	"context"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/httpx"
+	"github.com/delordemm1/go-api-simple-starter/internal/validation"
+)
+
+// --- DTOs ---
+
+// RequestEmailChangeRequest starts an email change for the authenticated user.
+type RequestEmailChangeRequest struct {
+	Body struct {
+		Email string `json:"email" validate:"required,email" doc:"The new email address; a confirmation code is sent there"`
+	}
+}
+
+// RequestEmailChangeResponse is an empty successful response.
+type RequestEmailChangeResponse struct{}
+
+// ConfirmEmailChangeRequest confirms the pending email change with the code sent to the new address.
+type ConfirmEmailChangeRequest struct {
+	Body struct {
+		Code string `json:"code" validate:"required,max=32"`
+	}
+}
+
+// --- Handlers ---
+
+// RequestEmailChangeHandler sends a code to the new address and a notice to the current one.
+func (h *Handler) RequestEmailChangeHandler(ctx context.Context, input *RequestEmailChangeRequest) (*RequestEmailChangeResponse, error) {
+	userID, ok := ctx.Value(contextx.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, httpx.ToProblem(ctx, ErrUnauthorized.WithDetail("invalid authentication context"))
+	}
+	if verr := validation.ValidateStruct(&input.Body); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	if err := h.service.RequestEmailChange(ctx, userID, input.Body.Email); err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return &RequestEmailChangeResponse{}, nil
+}
+
+// ConfirmEmailChangeHandler swaps in the new email and returns the updated profile.
+func (h *Handler) ConfirmEmailChangeHandler(ctx context.Context, input *ConfirmEmailChangeRequest) (*ProfileResponse, error) {
+	userID, ok := ctx.Value(contextx.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, httpx.ToProblem(ctx, ErrUnauthorized.WithDetail("invalid authentication context"))
+	}
+	if verr := validation.ValidateStruct(&input.Body); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	user, err := h.service.ConfirmEmailChange(ctx, userID, input.Body.Code)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	resp, err := h.profileResponse(ctx, user)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return resp, nil
+}
@@ -2,8 +2,14 @@ package user
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
 	"github.com/delordemm1/go-api-simple-starter/internal/httpx"
+	"github.com/delordemm1/go-api-simple-starter/internal/mapping"
+	"github.com/delordemm1/go-api-simple-starter/internal/validation"
 )
 
 // This header key must match the one your SvelteKit proxy is looking for.
@@ -12,7 +18,7 @@ import (
 
 // OAuthLoginRequest defines the provider being requested from the URL path.
 type OAuthLoginRequest struct {
-	Provider string `path:"provider"`
+	Provider string `path:"provider" enum:"google,apple,microsoft,oidc"`
 }
 
 // OAuthLoginResponse is the JSON response sent to the proxy.
@@ -25,18 +31,45 @@ type OAuthLoginResponse struct {
 // OAuthCallbackRequest defines the query parameters sent by the OAuth provider,
 // which are forwarded by the proxy.
 type OAuthCallbackRequest struct {
-	Provider string `path:"provider"`
-	Code     string `query:"code"`
-	State    string `query:"state"`
+	Provider string `path:"provider" enum:"google,apple,microsoft,oidc"`
+	Code     string `query:"code" validate:"required"`
+	State    string `query:"state" validate:"required"`
 }
 
-// OAuthCallbackResponse is the JSON response for a successful callback.
+// OAuthCallbackResponse is the JSON response for a successful callback: a session token
+// for a login, or linked=true (and no token) for a flow started from the link endpoint.
+// emailVerificationRequired=true means the token is a pending session until the emailed
+// code is confirmed.
 type OAuthCallbackResponse struct {
 	Body struct {
-		SessionToken string `json:"sessionToken"`
+		SessionToken              string `json:"sessionToken,omitempty"`
+		Linked                    bool   `json:"linked,omitempty"`
+		EmailVerificationRequired bool   `json:"emailVerificationRequired,omitempty"`
 	}
 }
 
+// toOAuthCallbackResponse maps the service result to the response DTO.
+func toOAuthCallbackResponse(result *OAuthCallbackResult) *OAuthCallbackResponse {
+	resp := &OAuthCallbackResponse{}
+	resp.Body.SessionToken = result.SessionID
+	resp.Body.Linked = result.Linked
+	resp.Body.EmailVerificationRequired = result.EmailVerificationRequired
+	return resp
+}
+
+// --- Middleware ---
+
+// requireSupportedOAuthProvider rejects unknown {provider} values with ErrUnsupportedOAuthProvider
+// before Huma parses the input, so junk values never reach the service or the enum validator.
+func requireSupportedOAuthProvider(ctx huma.Context, next func(huma.Context)) {
+	provider := ctx.Param("provider")
+	if !OAuthProvider(provider).IsSupported() {
+		httpx.WriteProblem(ctx, ErrUnsupportedOAuthProvider.WithDetail(fmt.Sprintf("unsupported oauth provider: %s", provider)))
+		return
+	}
+	next(ctx)
+}
+
 // --- Handlers ---
 
 // OAuthLoginHandler initiates the OAuth flow by returning a redirect URL to the proxy.
@@ -59,27 +92,30 @@ func (h *Handler) OAuthLoginHandler(ctx context.Context, input *OAuthLoginReques
 // On success, it returns the session token in a custom header for the proxy to handle.
 func (h *Handler) OAuthCallbackHandler(ctx context.Context, input *OAuthCallbackRequest) (*OAuthCallbackResponse, error) {
 	h.logger.Info("handling oauth callback", "provider", input.Provider)
+	if verr := validation.ValidateStruct(input); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
 
-	sessionToken, err := h.service.HandleOAuthCallback(ctx, OAuthProvider(input.Provider), input.State, input.Code)
+	result, err := h.service.HandleOAuthCallback(ctx, OAuthProvider(input.Provider), input.State, input.Code, "")
 	if err != nil {
 		h.logger.Error("oauth callback processing failed", "error", err)
 		return nil, httpx.ToProblem(ctx, err)
 	}
 
-	h.logger.Info("oauth login successful, returning session token in header")
+	h.logger.Info("oauth callback successful", "linked", result.Linked)
 
-	resp := &OAuthCallbackResponse{}
-	resp.Body.SessionToken = sessionToken
-	return resp, nil
+	return toOAuthCallbackResponse(result), nil
 }
 
 
 // OAuthCallbackPostRequest supports both form_post (Apple) and optional JSON via proxy.
 type OAuthCallbackPostRequest struct {
-	Provider string `path:"provider"`
+	Provider string `path:"provider" enum:"google,apple,microsoft,oidc"`
 	// Form-encoded fields from Apple (response_mode=form_post)
 	Code  string `form:"code"`
 	State string `form:"state"`
+	// User is the JSON with the user's name that Apple posts on the first authorization only.
+	User string `form:"user"`
 	// Optional JSON body if a proxy forwards as JSON
 	Body struct {
 		Code  string `json:"code"`
@@ -100,14 +136,98 @@ func (h *Handler) OAuthCallbackPostHandler(ctx context.Context, input *OAuthCall
 	if state == "" {
 		state = input.Body.State
 	}
+	user := input.User
+	if user == "" {
+		user = input.Body.User
+	}
 
-	sessionToken, err := h.service.HandleOAuthCallback(ctx, OAuthProvider(input.Provider), state, code)
+	result, err := h.service.HandleOAuthCallback(ctx, OAuthProvider(input.Provider), state, code, user)
 	if err != nil {
 		h.logger.Error("oauth callback processing failed (POST)", "error", err)
 		return nil, httpx.ToProblem(ctx, err)
 	}
 
-	resp := &OAuthCallbackResponse{}
-	resp.Body.SessionToken = sessionToken
+	return toOAuthCallbackResponse(result), nil
+}
+
+// --- Linked accounts (protected) ---
+
+// OAuthAccountItem is a provider identity linked to the current user.
+type OAuthAccountItem struct {
+	Provider string    `json:"provider"`
+	Email    string    `json:"email,omitempty"`
+	LinkedAt time.Time `json:"linkedAt"`
+}
+
+// toOAuthAccountItem maps a linked identity to its listing entry.
+func toOAuthAccountItem(a *OAuthAccount) OAuthAccountItem {
+	return OAuthAccountItem{
+		Provider: string(a.Provider),
+		Email:    a.Email,
+		LinkedAt: a.CreatedAt,
+	}
+}
+
+// ListOAuthAccountsResponse lists the current user's linked identities.
+type ListOAuthAccountsResponse struct {
+	Body struct {
+		Items []OAuthAccountItem `json:"items"`
+	}
+}
+
+// OAuthProviderPathRequest names a provider in the URL path.
+type OAuthProviderPathRequest struct {
+	Provider string `path:"provider" enum:"google,apple,microsoft,oidc"`
+}
+
+// UnlinkOAuthAccountResponse is an empty successful response.
+type UnlinkOAuthAccountResponse struct{}
+
+// ListOAuthAccountsHandler returns the identities linked to the authenticated user.
+func (h *Handler) ListOAuthAccountsHandler(ctx context.Context, _ *struct{}) (*ListOAuthAccountsResponse, error) {
+	userID, ok := ctx.Value(contextx.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, httpx.ToProblem(ctx, ErrUnauthorized.WithDetail("invalid authentication context"))
+	}
+
+	accounts, err := h.service.ListOAuthAccounts(ctx, userID)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+
+	resp := &ListOAuthAccountsResponse{}
+	resp.Body.Items = mapping.Slice(accounts, toOAuthAccountItem)
 	return resp, nil
-}
\ No newline at end of file
+}
+
+// LinkOAuthAccountHandler starts a link flow and returns the provider redirect URL. The
+// provider's callback then answers with linked=true instead of a session token.
+func (h *Handler) LinkOAuthAccountHandler(ctx context.Context, input *OAuthProviderPathRequest) (*OAuthLoginResponse, error) {
+	userID, ok := ctx.Value(contextx.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, httpx.ToProblem(ctx, ErrUnauthorized.WithDetail("invalid authentication context"))
+	}
+
+	redirectURL, err := h.service.InitiateOAuthLink(ctx, userID, OAuthProvider(input.Provider))
+	if err != nil {
+		h.logger.Error("failed to initiate oauth link", "error", err)
+		return nil, httpx.ToProblem(ctx, err)
+	}
+
+	resp := &OAuthLoginResponse{}
+	resp.Body.RedirectURL = redirectURL
+	return resp, nil
+}
+
+// UnlinkOAuthAccountHandler removes the authenticated user's identity for a provider.
+func (h *Handler) UnlinkOAuthAccountHandler(ctx context.Context, input *OAuthProviderPathRequest) (*UnlinkOAuthAccountResponse, error) {
+	userID, ok := ctx.Value(contextx.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, httpx.ToProblem(ctx, ErrUnauthorized.WithDetail("invalid authentication context"))
+	}
+
+	if err := h.service.UnlinkOAuthAccount(ctx, userID, OAuthProvider(input.Provider)); err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return &UnlinkOAuthAccountResponse{}, nil
+}
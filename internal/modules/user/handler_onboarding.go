@@ -0,0 +1,24 @@
+package user
+
+import (
+	"context"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/httpx"
+)
+
+// UnsubscribeOnboardingResponse is an empty successful response.
+type UnsubscribeOnboardingResponse struct{}
+
+// UnsubscribeOnboardingHandler stops the remaining onboarding emails for the authenticated user.
+func (h *Handler) UnsubscribeOnboardingHandler(ctx context.Context, _ *struct{}) (*UnsubscribeOnboardingResponse, error) {
+	userID, ok := ctx.Value(contextx.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, httpx.ToProblem(ctx, ErrUnauthorized.WithDetail("invalid authentication context"))
+	}
+
+	if err := h.service.UnsubscribeOnboarding(ctx, userID); err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return &UnsubscribeOnboardingResponse{}, nil
+}
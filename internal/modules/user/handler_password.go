@@ -3,7 +3,10 @@ package user
 import (
 	"context"
 
+	"github.com/delordemm1/go-api-simple-starter/internal/captcha"
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
 	"github.com/delordemm1/go-api-simple-starter/internal/httpx"
+	"github.com/delordemm1/go-api-simple-starter/internal/middleware"
 	"github.com/delordemm1/go-api-simple-starter/internal/validation"
 )
 
@@ -12,7 +15,8 @@ import (
 // ForgotPasswordRequest defines the structure for initiating a password reset.
 type ForgotPasswordRequest struct {
 	Body struct {
-		Email string `json:"email" validate:"required,email"`
+		Email        string `json:"email" validate:"required,email"`
+		CaptchaToken string `json:"captchaToken,omitempty" doc:"Solved CAPTCHA token; required when CAPTCHA_ENDPOINTS includes forgot_password"`
 	}
 }
 
@@ -31,11 +35,11 @@ type ResetPasswordRequest struct {
 // ResetPasswordResponse is an empty successful response.
 type ResetPasswordResponse struct{}
 
-// VerifyPasswordCodeRequest is used to exchange a 6-digit code for a reset token.
+// VerifyPasswordCodeRequest is used to exchange a one-time code for a reset token.
 type VerifyPasswordCodeRequest struct {
 	Body struct {
 		Email string `json:"email" validate:"required,email"`
-		Code  string `json:"code" validate:"required,len=6"`
+		Code  string `json:"code" validate:"required,max=32"`
 	}
 }
 
@@ -46,6 +50,22 @@ type VerifyPasswordCodeResponse struct {
 	}
 }
 
+// ChangePasswordRequest changes the authenticated user's password.
+type ChangePasswordRequest struct {
+	Body struct {
+		CurrentPassword string `json:"currentPassword" validate:"required"`
+		Password        string `json:"password" validate:"required,min=8"`
+		ConfirmPassword string `json:"confirmPassword" validate:"required,eqfield=Password"`
+	}
+}
+
+// ChangePasswordResponse carries the caller's new session token; the old one no longer works.
+type ChangePasswordResponse struct {
+	Body struct {
+		SessionToken string `json:"sessionToken"`
+	}
+}
+
 // --- Handlers ---
 
 // ForgotPasswordHandler handles the request to initiate a password reset.
@@ -56,6 +76,13 @@ func (h *Handler) ForgotPasswordHandler(ctx context.Context, input *ForgotPasswo
 	if verr := validation.ValidateStruct(&input.Body); verr != nil {
 		return nil, httpx.ToProblem(ctx, verr)
 	}
+	// Checked before the lookup, so a failed CAPTCHA reveals nothing about the email.
+	if err := h.service.VerifyCaptcha(ctx, captcha.EndpointForgotPassword, input.Body.CaptchaToken); err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	if err := middleware.RateLimitAccount(ctx, input.Body.Email); err != nil {
+		return nil, err
+	}
 
 	err := h.service.InitiatePasswordReset(ctx, input.Body.Email)
 	if err != nil {
@@ -89,7 +116,7 @@ func (h *Handler) ResetPasswordHandler(ctx context.Context, input *ResetPassword
 	return &ResetPasswordResponse{}, nil
 }
 
-// PasswordCodeVerifyHandler verifies a 6-digit code and returns a short-lived reset token.
+// PasswordCodeVerifyHandler verifies a one-time code and returns a short-lived reset token.
 func (h *Handler) PasswordCodeVerifyHandler(ctx context.Context, input *VerifyPasswordCodeRequest) (*VerifyPasswordCodeResponse, error) {
 	h.logger.Info("handling password code verify request")
 
@@ -97,6 +124,9 @@ func (h *Handler) PasswordCodeVerifyHandler(ctx context.Context, input *VerifyPa
 	if verr := validation.ValidateStruct(&input.Body); verr != nil {
 		return nil, httpx.ToProblem(ctx, verr)
 	}
+	if err := middleware.RateLimitAccount(ctx, input.Body.Email); err != nil {
+		return nil, err
+	}
 
 	resetToken, err := h.service.VerifyPasswordResetCode(ctx, input.Body.Email, input.Body.Code)
 	if err != nil {
@@ -108,3 +138,23 @@ func (h *Handler) PasswordCodeVerifyHandler(ctx context.Context, input *VerifyPa
 	resp.Body.ResetToken = resetToken
 	return resp, nil
 }
+
+// ChangePasswordHandler changes the password and signs out every other session.
+func (h *Handler) ChangePasswordHandler(ctx context.Context, input *ChangePasswordRequest) (*ChangePasswordResponse, error) {
+	userID, _ := ctx.Value(contextx.UserIDKey).(string)
+	sessionID, _ := ctx.Value(contextx.SessionIDKey).(string)
+	if userID == "" || sessionID == "" {
+		return nil, httpx.ToProblem(ctx, ErrUnauthorized.WithDetail("invalid authentication context"))
+	}
+	if verr := validation.ValidateStruct(&input.Body); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+
+	sessionToken, err := h.service.ChangePassword(ctx, userID, sessionID, input.Body.CurrentPassword, input.Body.Password)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	resp := &ChangePasswordResponse{}
+	resp.Body.SessionToken = sessionToken
+	return resp, nil
+}
@@ -0,0 +1,119 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/httpx"
+	"github.com/delordemm1/go-api-simple-starter/internal/middleware"
+	"github.com/delordemm1/go-api-simple-starter/internal/validation"
+)
+
+// --- DTOs ---
+
+// PhoneRegisterRequest registers a phone-only account; the sign-in code is sent by SMS.
+type PhoneRegisterRequest struct {
+	Body struct {
+		FirstName   string `json:"firstName" validate:"required,min=2"`
+		LastName    string `json:"lastName" validate:"required,min=2"`
+		Phone       string `json:"phone" validate:"required,e164" doc:"Phone number in E.164 format, e.g. +15551234567"`
+		AcceptTerms bool   `json:"acceptTerms" validate:"required,eq=true"`
+
+		// Bot deterrents, as on RegisterRequest.
+		Website        string `json:"website,omitempty"`
+		FormRenderedAt int64  `json:"formRenderedAt,omitempty"`
+	}
+}
+
+// PhoneRegisterResponse describes the created (or re-registered) account.
+type PhoneRegisterResponse struct {
+	Body UserDTO
+}
+
+// PhoneCodeRequest asks for an SMS sign-in code.
+type PhoneCodeRequest struct {
+	Body struct {
+		Phone string `json:"phone" validate:"required,e164"`
+	}
+}
+
+type PhoneCodeResponse struct{}
+
+// PhoneLoginRequest signs in with the SMS code.
+type PhoneLoginRequest struct {
+	Body struct {
+		Phone string `json:"phone" validate:"required,e164"`
+		Code  string `json:"code" validate:"required,max=32"`
+	}
+}
+
+// PhoneLoginResponse returns the new session token.
+type PhoneLoginResponse struct {
+	Body struct {
+		SessionToken string `json:"sessionToken"`
+	}
+}
+
+// --- Handlers ---
+
+// PhoneRegisterHandler creates a phone-only account and texts it a sign-in code.
+func (h *Handler) PhoneRegisterHandler(ctx context.Context, input *PhoneRegisterRequest) (*PhoneRegisterResponse, error) {
+	if verr := validation.ValidateStruct(&input.Body); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+	if err := middleware.RateLimitAccount(ctx, input.Body.Phone); err != nil {
+		return nil, err
+	}
+
+	signals := RegistrationSignals{Honeypot: input.Body.Website}
+	if input.Body.FormRenderedAt > 0 {
+		signals.FormRenderedAt = time.Unix(input.Body.FormRenderedAt, 0)
+	}
+	if err := h.service.ScreenRegistration(ctx, signals); err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+
+	user, err := h.service.RegisterWithPhone(ctx, input.Body.FirstName, input.Body.LastName, input.Body.Phone)
+	if err != nil {
+		h.logger.Error("phone registration failed", "error", err)
+		return nil, httpx.ToProblem(ctx, err)
+	}
+
+	return &PhoneRegisterResponse{Body: toUserDTO(user)}, nil
+}
+
+// PhoneCodeHandler texts a sign-in code to a registered phone.
+// It always succeeds for well-formed input to avoid user enumeration.
+func (h *Handler) PhoneCodeHandler(ctx context.Context, input *PhoneCodeRequest) (*PhoneCodeResponse, error) {
+	if verr := validation.ValidateStruct(&input.Body); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+	if err := middleware.RateLimitAccount(ctx, input.Body.Phone); err != nil {
+		return nil, err
+	}
+
+	if err := h.service.RequestPhoneLoginCode(ctx, input.Body.Phone); err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return &PhoneCodeResponse{}, nil
+}
+
+// PhoneLoginHandler validates the SMS code and returns a session token.
+func (h *Handler) PhoneLoginHandler(ctx context.Context, input *PhoneLoginRequest) (*PhoneLoginResponse, error) {
+	if verr := validation.ValidateStruct(&input.Body); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+	if err := middleware.RateLimitAccount(ctx, input.Body.Phone); err != nil {
+		return nil, err
+	}
+
+	sessionToken, err := h.service.ConfirmPhoneLogin(ctx, input.Body.Phone, input.Body.Code)
+	if err != nil {
+		h.logger.Warn("phone login attempt failed", "error", err)
+		return nil, httpx.ToProblem(ctx, err)
+	}
+
+	resp := &PhoneLoginResponse{}
+	resp.Body.SessionToken = sessionToken
+	return resp, nil
+}
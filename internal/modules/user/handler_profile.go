@@ -2,8 +2,13 @@ package user
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/danielgtaylor/huma/v2/conditional"
 	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
 	"github.com/delordemm1/go-api-simple-starter/internal/httpx"
 	"github.com/delordemm1/go-api-simple-starter/internal/validation"
@@ -14,41 +19,87 @@ import (
 
 // --- DTOs & Mappers ---
 
+// profileCacheControl lets clients reuse the profile briefly and then revalidate it
+// cheaply with If-None-Match.
+const profileCacheControl = "private, max-age=60"
+
+// GetProfileRequest supports conditional requests. Only the ETag validators (If-None-Match,
+// If-Match) are evaluated; see GetProfileHandler.
+type GetProfileRequest struct {
+	conditional.Params
+}
+
 // ProfileResponse is the DTO for a user's public profile.
+// The ETag is derived from the persisted updated_at and the sign-in methods, so it only changes
+// when the profile does. There is no Last-Modified: linking or unlinking an OAuth account changes
+// the profile without touching updated_at, and an unlink leaves no row to date the change by.
 type ProfileResponse struct {
-	Body struct {
-		ID        string    `json:"id"`
-		FirstName string    `json:"firstName"`
-		LastName  string    `json:"lastName"`
-		Email     string    `json:"email"`
-		CreatedAt time.Time `json:"createdAt"`
+	CacheControl string `header:"Cache-Control"`
+	Vary         string `header:"Vary"`
+	ETag         string `header:"ETag"`
+	Body         struct {
+		UserDTO
+		Locale        string   `json:"locale" doc:"BCP 47 language tag used to format notifications; empty for the default"`
+		TimeZone      string   `json:"timeZone" doc:"IANA time zone used for times in notifications; empty for UTC"`
+		MFAEnabled    bool     `json:"mfaEnabled" doc:"Whether a second factor is enrolled"`
+		AuthProviders []string `json:"authProviders" doc:"Sign-in methods: password when one is set, then linked OAuth providers"`
+		// Terms of service last accepted; empty/omitted until the user accepts a version.
+		AcceptedTermsVersion string     `json:"acceptedTermsVersion,omitempty"`
+		TermsAcceptedAt      *time.Time `json:"termsAcceptedAt,omitempty"`
 	}
 }
 
-// toProfileResponse maps a domain User object to a ProfileResponse DTO.
-func toProfileResponse(user *User) *ProfileResponse {
+// toProfileResponse maps a domain User object and its security status to a ProfileResponse DTO.
+func toProfileResponse(user *User, status *SecurityStatus) *ProfileResponse {
 	var resp ProfileResponse
-	resp.Body.ID = user.ID
-	resp.Body.FirstName = user.FirstName
-	resp.Body.LastName = user.LastName
-	resp.Body.Email = user.Email
-	resp.Body.CreatedAt = user.CreatedAt
+	resp.Body.UserDTO = toUserDTO(user)
+	resp.Body.Locale = user.Locale
+	resp.Body.TimeZone = user.TimeZone
+	resp.Body.MFAEnabled = status.MFAEnabled
+	resp.Body.AuthProviders = status.AuthProviders
+	if user.TermsVersion != nil {
+		resp.Body.AcceptedTermsVersion = *user.TermsVersion
+	}
+	resp.Body.TermsAcceptedAt = user.TermsAcceptedAt
+	resp.ETag = `"` + profileETag(user, status) + `"`
 	return &resp
 }
 
-// UpdateProfileRequest defines the fields that can be updated on a user's profile.
+// profileResponse loads the security status for user and builds its profile response.
+func (h *Handler) profileResponse(ctx context.Context, user *User) (*ProfileResponse, error) {
+	status, err := h.service.SecurityStatus(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return toProfileResponse(user, status), nil
+}
+
+// profileETag is a strong validator for the profile representation.
+func profileETag(user *User, status *SecurityStatus) string {
+	sum := sha256.Sum256([]byte(user.ID + "|" + strconv.FormatInt(user.UpdatedAt.UnixNano(), 10) +
+		"|" + strings.Join(status.AuthProviders, ",") + "|" + strconv.FormatBool(status.MFAEnabled)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// UpdateProfileRequest is a JSON Merge Patch (RFC 7396) of the user's profile:
+// only the fields present in the body are changed. Names cannot be removed, so
+// null is treated the same as an omitted field. Plain application/json is accepted too.
+// An empty locale or timeZone resets it to the default.
 type UpdateProfileRequest struct {
 	Body struct {
-		FirstName string `json:"firstName" validate:"required,min=2"`
-		LastName  string `json:"lastName" validate:"required,min=2"`
-	}
+		FirstName *string `json:"firstName,omitempty" validate:"omitempty,min=2"`
+		LastName  *string `json:"lastName,omitempty" validate:"omitempty,min=2"`
+		Locale    *string `json:"locale,omitempty" validate:"omitempty,max=35" doc:"BCP 47 language tag, e.g. en-GB"`
+		TimeZone  *string `json:"timeZone,omitempty" validate:"omitempty,max=64" doc:"IANA time zone, e.g. Europe/Berlin"`
+	} `contentType:"application/merge-patch+json"`
 }
 
 // --- Handlers ---
 
 // GetProfileHandler retrieves the profile of the currently authenticated user.
 // It relies on an authentication middleware to have set the user's ID in the context.
-func (h *Handler) GetProfileHandler(ctx context.Context, input *struct{}) (*ProfileResponse, error) {
+// Conditional requests for an unchanged profile get 304 Not Modified without a body.
+func (h *Handler) GetProfileHandler(ctx context.Context, input *GetProfileRequest) (*ProfileResponse, error) {
 	// Extract user ID from the context, which is set by the auth middleware.
 	userIDVal := ctx.Value(contextx.UserIDKey)
 	h.logger.Info("userIDVal", "userIDVal", userIDVal)
@@ -67,7 +118,23 @@ func (h *Handler) GetProfileHandler(ctx context.Context, input *struct{}) (*Prof
 		return nil, httpx.ToProblem(ctx, err)
 	}
 
-	return toProfileResponse(user), nil
+	status, err := h.service.SecurityStatus(ctx, user)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+
+	// Without a Last-Modified, date conditions are ignored (RFC 9110 §13.1.3, §13.1.4).
+	input.IfModifiedSince, input.IfUnmodifiedSince = time.Time{}, time.Time{}
+	if input.HasConditionalParams() {
+		if err := input.PreconditionFailed(profileETag(user, status), time.Time{}); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := toProfileResponse(user, status)
+	resp.CacheControl = profileCacheControl
+	resp.Vary = "Authorization"
+	return resp, nil
 }
 
 // UpdateProfileHandler updates the profile of the currently authenticated user.
@@ -86,12 +153,21 @@ func (h *Handler) UpdateProfileHandler(ctx context.Context, input *UpdateProfile
 
 	h.logger.Info("handling update profile request", "user_id", userID)
 
-	updatedUser, err := h.service.UpdateProfile(ctx, userID, UpdateProfileInput{FirstName: &input.Body.FirstName, LastName: &input.Body.LastName})
+	updatedUser, err := h.service.UpdateProfile(ctx, userID, UpdateProfileInput{
+		FirstName: input.Body.FirstName,
+		LastName:  input.Body.LastName,
+		Locale:    input.Body.Locale,
+		TimeZone:  input.Body.TimeZone,
+	})
 	if err != nil {
 		h.logger.Error("failed to update user profile", "user_id", userID, "error", err)
 		return nil, httpx.ToProblem(ctx, err)
 	}
 
 	h.logger.Info("profile updated successfully", "user_id", userID)
-	return toProfileResponse(updatedUser), nil
+	resp, err := h.profileResponse(ctx, updatedUser)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return resp, nil
 }
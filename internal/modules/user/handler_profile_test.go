@@ -0,0 +1,97 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/delordemm1/go-api-simple-starter/internal/config"
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/session"
+)
+
+// profileRepo serves one user and their linked OAuth accounts.
+type profileRepo struct {
+	Repository
+	user     *User
+	accounts []*OAuthAccount
+}
+
+func (r *profileRepo) FindByID(ctx context.Context, id string) (*User, error) {
+	if id != r.user.ID {
+		return nil, ErrNotFound
+	}
+	c := *r.user
+	return &c, nil
+}
+
+func (r *profileRepo) ListOAuthAccounts(ctx context.Context, userID string) ([]*OAuthAccount, error) {
+	return r.accounts, nil
+}
+
+// TestProfileRevalidatesOnLinkedAccounts checks that linking an OAuth account, which does not
+// touch updated_at, is never answered with 304 Not Modified.
+func TestProfileRevalidatesOnLinkedAccounts(t *testing.T) {
+	updated := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := &profileRepo{user: &User{ID: "0199f0a0-0000-7000-8000-000000000001", Email: "alice@example.com", PasswordHash: "x", UpdatedAt: updated}}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	sessions := session.NewMemoryProvider(session.Config{})
+	svc := NewService(&Config{Repo: repo, Logger: logger, Config: &config.Config{}, Sessions: sessions})
+	h := NewHandler(svc, logger, sessions, nil)
+	ctx := context.WithValue(context.Background(), contextx.UserIDKey, repo.user.ID)
+
+	first, err := h.GetProfileHandler(ctx, &GetProfileRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.accounts = []*OAuthAccount{{UserID: repo.user.ID, Provider: OAuthProviderGOOGLE}}
+
+	tests := []struct {
+		name string
+		in   *GetProfileRequest
+	}{
+		{"If-None-Match", func() *GetProfileRequest {
+			in := &GetProfileRequest{}
+			in.IfNoneMatch = []string{first.ETag}
+			return in
+		}()},
+		{"If-Modified-Since", func() *GetProfileRequest {
+			in := &GetProfileRequest{}
+			in.IfModifiedSince = updated.Add(time.Hour)
+			return in
+		}()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := h.GetProfileHandler(ctx, tt.in)
+			var se huma.StatusError
+			if errors.As(err, &se) && se.GetStatus() == http.StatusNotModified {
+				t.Fatal("304 Not Modified after an account was linked")
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := resp.Body.AuthProviders; len(got) != 2 || got[1] != "google" {
+				t.Fatalf("authProviders = %v", got)
+			}
+		})
+	}
+
+	// The unchanged profile still revalidates with its ETag.
+	second, err := h.GetProfileHandler(ctx, &GetProfileRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := &GetProfileRequest{}
+	in.IfNoneMatch = []string{second.ETag}
+	_, err = h.GetProfileHandler(ctx, in)
+	var se huma.StatusError
+	if !errors.As(err, &se) || se.GetStatus() != http.StatusNotModified {
+		t.Fatalf("If-None-Match of the current ETag: %v, want 304", err)
+	}
+}
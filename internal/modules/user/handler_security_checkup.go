@@ -0,0 +1,63 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/httpx"
+	"github.com/delordemm1/go-api-simple-starter/internal/mapping"
+)
+
+// --- DTOs ---
+
+// SecurityCheckupResponse is the current user's security posture.
+type SecurityCheckupResponse struct {
+	Body struct {
+		HasPassword            bool               `json:"hasPassword"`
+		PasswordSetAt          *time.Time         `json:"passwordSetAt,omitempty"`
+		PasswordAgeDays        *int               `json:"passwordAgeDays,omitempty" doc:"Whole days since the password was last set; omitted without a password"`
+		EmailVerified          bool               `json:"emailVerified"`
+		MFAEnabled             bool               `json:"mfaEnabled" doc:"Whether a second factor is enrolled"`
+		RecoveryCodesRemaining int                `json:"recoveryCodesRemaining" doc:"Unused second-factor recovery codes"`
+		ActiveSessions         int                `json:"activeSessions"`
+		SuspiciousEvents       []ActivityItem     `json:"suspiciousEvents" doc:"New-device sign-ins and administrator actions of the last 30 days, newest first"`
+		OAuthAccounts          []OAuthAccountItem `json:"oauthAccounts"`
+		Recommendations        []string           `json:"recommendations" doc:"Suggested actions: verify_email, change_password, review_activity, review_sessions"`
+	}
+}
+
+// toSecurityCheckupResponse maps a checkup to the response DTO.
+func toSecurityCheckupResponse(c *SecurityCheckup) *SecurityCheckupResponse {
+	var resp SecurityCheckupResponse
+	resp.Body.HasPassword = c.HasPassword
+	resp.Body.PasswordSetAt = c.PasswordSetAt
+	if c.PasswordSetAt != nil {
+		days := int(c.CheckedAt.Sub(*c.PasswordSetAt).Hours() / 24)
+		resp.Body.PasswordAgeDays = &days
+	}
+	resp.Body.EmailVerified = c.EmailVerified
+	resp.Body.MFAEnabled = c.MFAEnabled
+	resp.Body.RecoveryCodesRemaining = c.RecoveryCodesRemaining
+	resp.Body.ActiveSessions = c.ActiveSessions
+	resp.Body.SuspiciousEvents = mapping.Slice(c.SuspiciousEvents, toActivityItem)
+	resp.Body.OAuthAccounts = mapping.Slice(c.OAuthAccounts, toOAuthAccountItem)
+	resp.Body.Recommendations = c.Recommendations
+	return &resp
+}
+
+// --- Handlers ---
+
+// SecurityCheckupHandler returns the authenticated user's security posture in one call.
+func (h *Handler) SecurityCheckupHandler(ctx context.Context, _ *struct{}) (*SecurityCheckupResponse, error) {
+	userID, ok := ctx.Value(contextx.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, httpx.ToProblem(ctx, ErrUnauthorized.WithDetail("invalid authentication context"))
+	}
+
+	checkup, err := h.service.SecurityCheckup(ctx, userID)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return toSecurityCheckupResponse(checkup), nil
+}
@@ -0,0 +1,243 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/httpx"
+	"github.com/delordemm1/go-api-simple-starter/internal/mapping"
+	"github.com/delordemm1/go-api-simple-starter/internal/session"
+	"github.com/delordemm1/go-api-simple-starter/internal/validation"
+)
+
+// --- DTOs ---
+
+// CurrentSessionResponse describes the session used to authenticate the request.
+type CurrentSessionResponse struct {
+	Body struct {
+		CreatedAt         time.Time `json:"createdAt"`
+		LastActiveAt      time.Time `json:"lastActiveAt"`
+		IPAddress         string    `json:"ipAddress,omitempty"`
+		UserAgent         string    `json:"userAgent,omitempty"`
+		AuthMethod        string    `json:"authMethod,omitempty"`
+		Scope             string    `json:"scope,omitempty" doc:"Set for restricted sessions, e.g. email_unverified"`
+		RememberMe        bool      `json:"rememberMe" doc:"True if the session has the long remember-me lifetimes"`
+		ElevatedUntil     time.Time `json:"elevatedUntil" doc:"Until when the session may call operations requiring recent authentication"`
+		ImpersonatedBy    string    `json:"impersonatedBy,omitempty" doc:"Admin user ID, for admin impersonation sessions"`
+		AbsoluteExpiresAt time.Time `json:"absoluteExpiresAt"`
+		IdleExpiresAt     time.Time `json:"idleExpiresAt"`
+		// RemainingSeconds is the time left before the absolute lifetime ends.
+		RemainingSeconds int64 `json:"remainingSeconds"`
+	}
+}
+
+// RebindSessionRequest re-enters the password to keep using a session from a new client.
+type RebindSessionRequest struct {
+	Body struct {
+		Password string `json:"password" validate:"required"`
+	}
+}
+
+// RebindSessionResponse carries the session's new token; the old one no longer works.
+type RebindSessionResponse struct {
+	Body struct {
+		SessionToken string `json:"sessionToken"`
+	}
+}
+
+// ReauthenticateRequest re-enters the password to unlock sensitive operations for a while.
+type ReauthenticateRequest struct {
+	Body struct {
+		Password string `json:"password" validate:"required"`
+	}
+}
+
+// ReauthenticateResponse reports until when the session may call sensitive operations, and the
+// session's new token; the old one no longer works.
+type ReauthenticateResponse struct {
+	Body struct {
+		SessionToken  string    `json:"sessionToken"`
+		ElevatedUntil time.Time `json:"elevatedUntil"`
+	}
+}
+
+// RevokeSessionsWithTokenRequest carries the token from a sign-in alert's revoke link.
+type RevokeSessionsWithTokenRequest struct {
+	Body struct {
+		Token string `json:"token" validate:"required,max=128"`
+	}
+}
+
+// RevokeSessionsWithTokenResponse is an empty successful response.
+type RevokeSessionsWithTokenResponse struct{}
+
+// SessionItem is one of the user's active sessions (a signed-in device).
+type SessionItem struct {
+	ID           string    `json:"id"`
+	IPAddress    string    `json:"ipAddress,omitempty"`
+	UserAgent    string    `json:"userAgent,omitempty"`
+	AuthMethod   string    `json:"authMethod,omitempty"`
+	RememberMe   bool      `json:"rememberMe"`
+	CreatedAt    time.Time `json:"createdAt"`
+	LastActiveAt time.Time `json:"lastActiveAt"`
+	Current      bool      `json:"current" doc:"True for the session making this request"`
+}
+
+// ListSessionsResponse lists the user's active sessions, most recently active first.
+type ListSessionsResponse struct {
+	Body struct {
+		Items []SessionItem `json:"items"`
+	}
+}
+
+// RevokeSessionRequest targets one of the user's sessions by ID.
+type RevokeSessionRequest struct {
+	ID string `path:"id" validate:"required,uuid"`
+}
+
+// RevokeSessionResponse is an empty successful response.
+type RevokeSessionResponse struct{}
+
+// toListSessionsResponse maps sessions to the response DTO; currentID marks the caller's.
+func toListSessionsResponse(sessions []*session.Info, currentID string) *ListSessionsResponse {
+	var resp ListSessionsResponse
+	resp.Body.Items = mapping.Slice(sessions, func(s *session.Info) SessionItem {
+		return SessionItem{
+			ID:           s.ID,
+			IPAddress:    s.IP,
+			UserAgent:    s.UserAgent,
+			AuthMethod:   s.AuthMethod,
+			RememberMe:   s.RememberMe,
+			CreatedAt:    s.CreatedAt,
+			LastActiveAt: s.LastActiveAt,
+			Current:      s.ID == currentID,
+		}
+	})
+	return &resp
+}
+
+// toCurrentSessionResponse maps session info to the response DTO.
+func toCurrentSessionResponse(info *session.Info) *CurrentSessionResponse {
+	var resp CurrentSessionResponse
+	resp.Body.CreatedAt = info.CreatedAt
+	resp.Body.LastActiveAt = info.LastActiveAt
+	resp.Body.IPAddress = info.IP
+	resp.Body.UserAgent = info.UserAgent
+	resp.Body.AuthMethod = info.AuthMethod
+	resp.Body.Scope = info.Scope
+	resp.Body.RememberMe = info.RememberMe
+	resp.Body.ElevatedUntil = info.ElevatedUntil
+	resp.Body.ImpersonatedBy = info.ImpersonatorID
+	resp.Body.AbsoluteExpiresAt = info.AbsoluteExpiresAt
+	resp.Body.IdleExpiresAt = info.IdleExpiresAt
+	if remaining := time.Until(info.AbsoluteExpiresAt); remaining > 0 {
+		resp.Body.RemainingSeconds = int64(remaining.Seconds())
+	}
+	return &resp
+}
+
+// --- Handlers ---
+
+// GetCurrentSessionHandler returns metadata for the session carried by the Bearer token.
+func (h *Handler) GetCurrentSessionHandler(ctx context.Context, _ *struct{}) (*CurrentSessionResponse, error) {
+	sessionID, _ := ctx.Value(contextx.SessionIDKey).(string)
+	if sessionID == "" {
+		return nil, httpx.ToProblem(ctx, ErrUnauthorized.WithDetail("invalid authentication context"))
+	}
+
+	info, err := h.sessions.Get(ctx, sessionID)
+	if err != nil {
+		h.logger.Warn("failed to load current session", "error", err)
+		return nil, httpx.ToProblem(ctx, ErrUnauthorized.WithDetail("invalid or expired session"))
+	}
+
+	return toCurrentSessionResponse(info), nil
+}
+
+// RebindSessionHandler binds the current session to the calling client after a password check.
+func (h *Handler) RebindSessionHandler(ctx context.Context, input *RebindSessionRequest) (*RebindSessionResponse, error) {
+	if verr := validation.ValidateStruct(&input.Body); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+	sessionID, _ := ctx.Value(contextx.SessionIDKey).(string)
+	userID, _ := ctx.Value(contextx.UserIDKey).(string)
+	if sessionID == "" || userID == "" {
+		return nil, httpx.ToProblem(ctx, ErrUnauthorized.WithDetail("invalid authentication context"))
+	}
+
+	sessionToken, err := h.service.RebindSession(ctx, userID, sessionID, input.Body.Password)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	resp := &RebindSessionResponse{}
+	resp.Body.SessionToken = sessionToken
+	return resp, nil
+}
+
+// ListSessionsHandler lists the authenticated user's active sessions.
+func (h *Handler) ListSessionsHandler(ctx context.Context, _ *struct{}) (*ListSessionsResponse, error) {
+	userID, _ := ctx.Value(contextx.UserIDKey).(string)
+	sessionID, _ := ctx.Value(contextx.SessionIDKey).(string)
+	if userID == "" || sessionID == "" {
+		return nil, httpx.ToProblem(ctx, ErrUnauthorized.WithDetail("invalid authentication context"))
+	}
+
+	sessions, err := h.service.ListSessions(ctx, userID)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	var currentID string
+	if info, err := h.sessions.Get(ctx, sessionID); err == nil {
+		currentID = info.ID
+	}
+	return toListSessionsResponse(sessions, currentID), nil
+}
+
+// RevokeSessionHandler signs out one of the authenticated user's sessions.
+func (h *Handler) RevokeSessionHandler(ctx context.Context, input *RevokeSessionRequest) (*RevokeSessionResponse, error) {
+	if verr := validation.ValidateStruct(input); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+	userID, _ := ctx.Value(contextx.UserIDKey).(string)
+	if userID == "" {
+		return nil, httpx.ToProblem(ctx, ErrUnauthorized.WithDetail("invalid authentication context"))
+	}
+
+	if err := h.service.RevokeSession(ctx, userID, input.ID); err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return &RevokeSessionResponse{}, nil
+}
+
+// RevokeSessionsWithTokenHandler signs out every session of the user a sign-in alert was sent to.
+func (h *Handler) RevokeSessionsWithTokenHandler(ctx context.Context, input *RevokeSessionsWithTokenRequest) (*RevokeSessionsWithTokenResponse, error) {
+	if verr := validation.ValidateStruct(&input.Body); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+	if err := h.service.RevokeSessionsWithToken(ctx, input.Body.Token); err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return &RevokeSessionsWithTokenResponse{}, nil
+}
+
+// ReauthenticateHandler checks the password and puts the current session in sudo mode.
+func (h *Handler) ReauthenticateHandler(ctx context.Context, input *ReauthenticateRequest) (*ReauthenticateResponse, error) {
+	if verr := validation.ValidateStruct(&input.Body); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+	sessionID, _ := ctx.Value(contextx.SessionIDKey).(string)
+	userID, _ := ctx.Value(contextx.UserIDKey).(string)
+	if sessionID == "" || userID == "" {
+		return nil, httpx.ToProblem(ctx, ErrUnauthorized.WithDetail("invalid authentication context"))
+	}
+
+	sessionToken, until, err := h.service.Reauthenticate(ctx, userID, sessionID, input.Body.Password)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	resp := &ReauthenticateResponse{}
+	resp.Body.SessionToken = sessionToken
+	resp.Body.ElevatedUntil = until
+	return resp, nil
+}
@@ -0,0 +1,76 @@
+package user
+
+import (
+	"context"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/httpx"
+	"github.com/delordemm1/go-api-simple-starter/internal/validation"
+)
+
+// --- DTOs ---
+
+// AcceptTermsRequest accepts a terms-of-service version; it must be the current one.
+type AcceptTermsRequest struct {
+	Body struct {
+		Version string `json:"version" validate:"required,max=64" doc:"Terms version shown to the user (currentVersion of ErrTermsAcceptanceRequired)"`
+	}
+}
+
+// --- Middleware ---
+
+// termsExemptKey marks operations callable before the current terms are accepted.
+const termsExemptKey = "termsExempt"
+
+// allowStaleTerms returns operation Metadata exempting the operation from requireCurrentTerms:
+// reading the profile and session, accepting the terms, and leaving (logout, and account
+// deletion with the re-authentication it requires).
+func allowStaleTerms() map[string]any {
+	return map[string]any{termsExemptKey: true}
+}
+
+// requireCurrentTerms rejects callers who have not accepted TERMS_CURRENT_VERSION with
+// ErrTermsAcceptanceRequired, unless the operation opts out with allowStaleTerms.
+// It must run after the session auth middleware.
+func (h *Handler) requireCurrentTerms(ctx huma.Context, next func(huma.Context)) {
+	if op := ctx.Operation(); op != nil {
+		if exempt, _ := op.Metadata[termsExemptKey].(bool); exempt {
+			next(ctx)
+			return
+		}
+	}
+	userID, _ := ctx.Context().Value(contextx.UserIDKey).(string)
+	if userID == "" {
+		httpx.WriteProblem(ctx, ErrUnauthorized.WithDetail("invalid authentication context"))
+		return
+	}
+	if err := h.service.CheckTerms(ctx.Context(), userID); err != nil {
+		httpx.WriteProblem(ctx, err)
+		return
+	}
+	next(ctx)
+}
+
+// --- Handlers ---
+
+// AcceptTermsHandler records the authenticated user's acceptance of the current terms.
+func (h *Handler) AcceptTermsHandler(ctx context.Context, input *AcceptTermsRequest) (*ProfileResponse, error) {
+	if verr := validation.ValidateStruct(input); verr != nil {
+		return nil, httpx.ToProblem(ctx, verr)
+	}
+	userID, ok := ctx.Value(contextx.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, httpx.ToProblem(ctx, ErrUnauthorized.WithDetail("invalid authentication context"))
+	}
+
+	user, err := h.service.AcceptTerms(ctx, userID, input.Body.Version)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	resp, err := h.profileResponse(ctx, user)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,49 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/httpx"
+)
+
+// --- DTOs ---
+
+// UsageResponse reports the caller's quota consumption in the current window.
+type UsageResponse struct {
+	Body struct {
+		Limit         int64     `json:"limit"`
+		Used          int64     `json:"used"`
+		Remaining     int64     `json:"remaining"`
+		WindowSeconds int64     `json:"windowSeconds"`
+		ResetAt       time.Time `json:"resetAt"`
+	}
+}
+
+// --- Handlers ---
+
+// GetUsageHandler returns the authenticated user's current quota consumption.
+func (h *Handler) GetUsageHandler(ctx context.Context, _ *struct{}) (*UsageResponse, error) {
+	userID, ok := ctx.Value(contextx.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, httpx.ToProblem(ctx, ErrUnauthorized.WithDetail("invalid authentication context"))
+	}
+	if h.quota == nil {
+		return nil, httpx.ToProblem(ctx, ErrNotFound.WithDetail("usage tracking is disabled"))
+	}
+
+	usage, err := h.quota.Peek(ctx, userID)
+	if err != nil {
+		h.logger.Error("failed to read usage", "error", err, "user_id", userID)
+		return nil, httpx.ToProblem(ctx, ErrInternal.WithCause(err))
+	}
+
+	resp := &UsageResponse{}
+	resp.Body.Limit = usage.Limit
+	resp.Body.Used = usage.Used
+	resp.Body.Remaining = usage.Remaining
+	resp.Body.WindowSeconds = int64(usage.Window.Seconds())
+	resp.Body.ResetAt = usage.ResetAt
+	return resp, nil
+}
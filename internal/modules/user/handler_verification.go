@@ -17,19 +17,29 @@ type ResendEmailVerificationRequest struct {
 
 type ResendEmailVerificationResponse struct{}
 
-// ConfirmEmailVerificationRequest defines the structure for confirming an email with a 6-digit code.
+// ConfirmEmailVerificationRequest defines the structure for confirming an email with a one-time code.
 type ConfirmEmailVerificationRequest struct {
 	Body struct {
 		Email string `json:"email" validate:"required,email"`
-		Code  string `json:"code" validate:"required,len=6"`
+		Code  string `json:"code" validate:"required,max=32"`
 	}
 }
 
 type ConfirmEmailVerificationResponse struct{}
 
+// VerifyEmailLinkRequest carries the token of an emailed verification link.
+type VerifyEmailLinkRequest struct {
+	Token string `query:"token" doc:"Token from the verification email"`
+}
+
+// VerifyEmailLinkResponse redirects the browser to the frontend with the outcome.
+type VerifyEmailLinkResponse struct {
+	Location string `header:"Location"`
+}
+
 // --- Handlers ---
 
-// ResendEmailVerificationHandler triggers sending a 6-digit code for email verification.
+// ResendEmailVerificationHandler triggers sending a one-time code for email verification.
 // It enforces cooldown in the service layer and does not leak user enumeration.
 func (h *Handler) ResendEmailVerificationHandler(ctx context.Context, input *ResendEmailVerificationRequest) (*ResendEmailVerificationResponse, error) {
 	if verr := validation.ValidateStruct(&input.Body); verr != nil {
@@ -43,7 +53,7 @@ func (h *Handler) ResendEmailVerificationHandler(ctx context.Context, input *Res
 	return &ResendEmailVerificationResponse{}, nil
 }
 
-// ConfirmEmailVerificationHandler validates the 6-digit code and marks the user's email as verified.
+// ConfirmEmailVerificationHandler validates the one-time code and marks the user's email as verified.
 func (h *Handler) ConfirmEmailVerificationHandler(ctx context.Context, input *ConfirmEmailVerificationRequest) (*ConfirmEmailVerificationResponse, error) {
 	if verr := validation.ValidateStruct(&input.Body); verr != nil {
 		return nil, httpx.ToProblem(ctx, verr)
@@ -54,4 +64,13 @@ func (h *Handler) ConfirmEmailVerificationHandler(ctx context.Context, input *Co
 	}
 
 	return &ConfirmEmailVerificationResponse{}, nil
-}
\ No newline at end of file
+}
+// VerifyEmailLinkHandler verifies an email address from the link in the verification email and
+// redirects to VERIFY_EMAIL_REDIRECT_URL with ?status=verified, invalid or error.
+func (h *Handler) VerifyEmailLinkHandler(ctx context.Context, input *VerifyEmailLinkRequest) (*VerifyEmailLinkResponse, error) {
+	location, err := h.service.ConfirmEmailVerificationLink(ctx, input.Token)
+	if err != nil {
+		return nil, httpx.ToProblem(ctx, err)
+	}
+	return &VerifyEmailLinkResponse{Location: location}, nil
+}
@@ -0,0 +1,34 @@
+package user
+
+import (
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/mapping"
+)
+
+// UserDTO is the representation of a user shared by every response that returns one
+// (registration, profile, admin listing). Responses embed it and add their own fields.
+type UserDTO struct {
+	ID            string    `json:"id"`
+	FirstName     string    `json:"firstName"`
+	LastName      string    `json:"lastName"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"emailVerified"`
+	Phone         string    `json:"phone,omitempty" doc:"E.164 phone number, if one is on file"`
+	PhoneVerified bool      `json:"phoneVerified"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// toUserDTO maps a domain User to its shared response representation.
+func toUserDTO(u *User) UserDTO {
+	return UserDTO{
+		ID:            u.ID,
+		FirstName:     u.FirstName,
+		LastName:      u.LastName,
+		Email:         u.Email,
+		EmailVerified: u.EmailVerified,
+		Phone:         mapping.Value(u.Phone),
+		PhoneVerified: u.PhoneVerified,
+		CreatedAt:     u.CreatedAt,
+	}
+}
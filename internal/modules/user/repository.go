@@ -14,15 +14,26 @@ type Repository interface {
 	// Users
 	Create(ctx context.Context, user *User) error
 	FindByEmail(ctx context.Context, email string) (*User, error)
+	FindByPhone(ctx context.Context, phone string) (*User, error)
 	FindByID(ctx context.Context, id string) (*User, error)
 	Update(ctx context.Context, user *User) error
+	// SetRole changes the user's role. Update never writes the role, so profile-style updates
+	// cannot escalate privileges.
+	SetRole(ctx context.Context, userID string, role Role) error
+	ListUsers(ctx context.Context, filter UserFilter, beforeID string, limit int) ([]*User, error)
 
 	// Password (legacy token fields retained but not used in new 6-digit flow)
 	UpdatePassword(ctx context.Context, userID string, newPasswordHash string) error
+	RehashPassword(ctx context.Context, userID string, oldPasswordHash, newPasswordHash string) error
 	FindByPasswordResetToken(ctx context.Context, tokenHash string) (*User, error)
 	UpdatePasswordResetInfo(ctx context.Context, userID string, tokenHash string, expiry time.Time) error
 
-	// Verification codes (6-digit OTP)
+	// Password history (reuse prevention)
+	AddPasswordHistory(ctx context.Context, userID string, passwordHash string, keep int) error
+	ListPasswordHistory(ctx context.Context, userID string, limit int) ([]string, error)
+	PasswordSetAt(ctx context.Context, userID string) (*time.Time, error)
+
+	// Verification codes (OTP)
 	CreateVerificationCode(ctx context.Context, vc *VerificationCode) error
 	GetActiveVerificationCodeByContact(ctx context.Context, contact string, purpose VerificationPurpose, channel VerificationChannel) (*VerificationCode, error)
 	GetActiveVerificationCodeByUser(ctx context.Context, userID string, purpose VerificationPurpose, channel VerificationChannel) (*VerificationCode, error)
@@ -41,12 +52,57 @@ type Repository interface {
 	UpdateUserActiveSessionTimestamp(ctx context.Context, sessionToken string) error
 	DeleteSessionByToken(ctx context.Context, sessionToken string) error
 
+	// Bulk session revocation jobs (admin incident response)
+	CreateSessionRevocation(ctx context.Context, job *SessionRevocation) error
+	UpdateSessionRevocationProgress(ctx context.Context, id string, revoked int64, batches int) error
+	FinishSessionRevocation(ctx context.Context, id string, status SessionRevocationStatus, revoked int64, errMsg *string) error
+	FindSessionRevocationByID(ctx context.Context, id string) (*SessionRevocation, error)
+
+	// Admin broadcasts
+	CreateBroadcast(ctx context.Context, b *Broadcast) error
+	FindBroadcastByID(ctx context.Context, id string) (*Broadcast, error)
+	RecordBroadcastBatch(ctx context.Context, id string, sent, failed int64, cursor string) error
+	FinishBroadcast(ctx context.Context, id string, status BroadcastStatus, errMsg *string) (bool, error)
+	ListBroadcastRecipients(ctx context.Context, inactiveSince *time.Time, afterID string, limit int) ([]*User, error)
+
+	// Account activity timeline
+	CreateActivityEvent(ctx context.Context, e *ActivityEvent) error
+	ListActivityEvents(ctx context.Context, userID string, beforeID string, limit int) ([]*ActivityEvent, error)
+	ListActivityEventsSince(ctx context.Context, userID string, types []ActivityType, since time.Time, limit int) ([]*ActivityEvent, error)
+	// KnownLoginDevice reports whether the user has logged in before from this user agent and IP
+	// address pair, and whether the user has logged in before at all.
+	KnownLoginDevice(ctx context.Context, userID, userAgent, ipAddress string) (known, anyLogin bool, err error)
+
+	// Account lifecycle cleanup
+	ListUnverifiedCreatedBefore(ctx context.Context, before time.Time, limit int) ([]*User, error)
+	ListInactiveSince(ctx context.Context, since time.Time, limit int) ([]*User, error)
+	ListReengagementExpired(ctx context.Context, sentBefore time.Time, limit int) ([]*User, error)
+	ListDeletedBefore(ctx context.Context, before time.Time, limit int) ([]*User, error)
+	ClearReengagementForReturningUsers(ctx context.Context) (int64, error)
+	MarkReengagementSent(ctx context.Context, userID string, at time.Time) error
+	HardDelete(ctx context.Context, userID string) error
+	Anonymize(ctx context.Context, userID string, at time.Time) error
+	CreateLifecycleAudit(ctx context.Context, userID string, action LifecycleAction, reason string) error
+
+	// Data retention
+	PurgeRetained(ctx context.Context, target RetentionTarget, before time.Time, namespace string, limit int) (int64, error)
+	PurgeExpired(ctx context.Context, table string, now time.Time, limit int) (int64, error)
+
 	// Oauth states (for social login)
 	InsertOAuthState(ctx context.Context, state *OAuthState) error
 	GetOAuthStateByState(ctx context.Context, state string) (*OAuthState, error)
 	UpdateOAuthStateUserID(ctx context.Context, state string, userID string) (*OAuthState, error)
 	DeleteOAuthState(ctx context.Context, state string) error
-	DeleteExpiredOAuthStates(ctx context.Context) error
+
+	// Linked OAuth identities
+	FindOAuthAccount(ctx context.Context, provider OAuthProvider, providerUserID string) (*OAuthAccount, error)
+	FindOAuthAccountByUser(ctx context.Context, userID string, provider OAuthProvider) (*OAuthAccount, error)
+	ListOAuthAccounts(ctx context.Context, userID string) ([]*OAuthAccount, error)
+	CreateOAuthAccount(ctx context.Context, account *OAuthAccount) error
+	DeleteOAuthAccount(ctx context.Context, userID string, provider OAuthProvider) error
+	// UpdateOAuthAccountTokens stores sealed provider tokens. A nil refreshToken keeps the
+	// stored one, since providers often return a refresh token only on first consent.
+	UpdateOAuthAccountTokens(ctx context.Context, provider OAuthProvider, providerUserID string, accessToken, refreshToken []byte, expiresAt *time.Time) error
 }
 
 // repository implements the Repository interface using pgx and squirrel.
@@ -62,3 +118,30 @@ func NewRepository(db database.DBTX) Repository {
 		psql: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
 	}
 }
+
+// usersEmailConstraint and usersPhoneConstraint are the unique indexes backing users.email
+// and users.phone.
+const (
+	usersEmailConstraint = "users_email_key"
+	usersPhoneConstraint = "users_phone_key"
+)
+
+// mapWriteError translates Postgres constraint violations into domain errors so services
+// can rely on the database (not racy find-then-insert checks) for uniqueness.
+// Errors that are not constraint violations are returned unchanged.
+func mapWriteError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch {
+	case database.IsUniqueViolation(err, usersEmailConstraint):
+		return ErrEmailExists.WithCause(err)
+	case database.IsUniqueViolation(err, usersPhoneConstraint):
+		return ErrPhoneExists.WithCause(err)
+	case database.IsUniqueViolation(err, ""):
+		return ErrConflict.WithCause(err)
+	case database.IsForeignKeyViolation(err):
+		return ErrNotFound.WithCause(err)
+	}
+	return err
+}
@@ -0,0 +1,118 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
+)
+
+// CreateActivityEvent appends an event to the user's activity timeline.
+func (r *repository) CreateActivityEvent(ctx context.Context, e *ActivityEvent) error {
+	if e.ID == "" {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		e.ID = id.String()
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	if e.Metadata == nil {
+		e.Metadata = map[string]any{}
+	}
+
+	sql, args, err := r.psql.Insert("user_activity_events").
+		Columns("id", "user_id", "event_type", "ip_address", "user_agent", "metadata", "created_at").
+		Values(e.ID, e.UserID, string(e.Type), nullableString(e.IPAddress), nullableString(e.UserAgent), e.Metadata, e.CreatedAt).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(ctx, sql, args...)
+	return mapWriteError(err)
+}
+
+// ListActivityEvents returns up to limit events for the user, newest first.
+// When beforeID is set, only events older than that event (by UUIDv7 order) are returned.
+func (r *repository) ListActivityEvents(ctx context.Context, userID string, beforeID string, limit int) ([]*ActivityEvent, error) {
+	q := r.psql.Select(
+		"id", "user_id", "event_type", "COALESCE(ip_address, '') AS ip_address", "COALESCE(user_agent, '') AS user_agent", "metadata", "created_at",
+	).From("user_activity_events").
+		Where(squirrel.Eq{"user_id": userID}).
+		OrderBy("id DESC").
+		Limit(uint64(limit))
+	if beforeID != "" {
+		q = q.Where(squirrel.Lt{"id": beforeID})
+	}
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	var events []*ActivityEvent
+	if err := pgxscan.Select(ctx, r.db, &events, sql, args...); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// ListActivityEventsSince returns up to limit events of the given types recorded at or after
+// since, newest first.
+func (r *repository) ListActivityEventsSince(ctx context.Context, userID string, types []ActivityType, since time.Time, limit int) ([]*ActivityEvent, error) {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = string(t)
+	}
+	sql, args, err := r.psql.Select(
+		"id", "user_id", "event_type", "COALESCE(ip_address, '') AS ip_address", "COALESCE(user_agent, '') AS user_agent", "metadata", "created_at",
+	).From("user_activity_events").
+		Where(squirrel.Eq{"user_id": userID, "event_type": names}).
+		Where(squirrel.GtOrEq{"created_at": since}).
+		OrderBy("id DESC").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	var events []*ActivityEvent
+	if err := pgxscan.Select(ctx, r.db, &events, sql, args...); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// KnownLoginDevice reports whether the user has previously logged in with the given user agent
+// from the given IP address, and whether the user has previously logged in at all. Empty values
+// match logins recorded without them.
+func (r *repository) KnownLoginDevice(ctx context.Context, userID, userAgent, ipAddress string) (bool, bool, error) {
+	sql := `
+		SELECT
+			EXISTS (
+				SELECT 1 FROM user_activity_events
+				WHERE user_id = $1 AND event_type = $2
+				  AND user_agent IS NOT DISTINCT FROM $3 AND ip_address IS NOT DISTINCT FROM $4
+			),
+			EXISTS (
+				SELECT 1 FROM user_activity_events
+				WHERE user_id = $1 AND event_type = $2
+			)
+	`
+	var known, anyLogin bool
+	err := r.db.QueryRow(ctx, sql, userID, string(ActivityLogin), nullableString(userAgent), nullableString(ipAddress)).Scan(&known, &anyLogin)
+	if err != nil {
+		return false, false, err
+	}
+	return known, anyLogin, nil
+}
+
+// nullableString stores empty strings as NULL.
+func nullableString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,83 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+)
+
+// CreateBroadcast inserts a running broadcast.
+func (r *repository) CreateBroadcast(ctx context.Context, b *Broadcast) error {
+	sql := `
+		INSERT INTO broadcasts (id, actor_id, audience, inactive_days, subject, message, reason, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at, updated_at
+	`
+	return r.db.QueryRow(ctx, sql,
+		b.ID, b.ActorID, b.Audience, b.InactiveDays, b.Subject, b.Message, b.Reason, b.Status,
+	).Scan(&b.CreatedAt, &b.UpdatedAt)
+}
+
+// FindBroadcastByID returns a broadcast.
+func (r *repository) FindBroadcastByID(ctx context.Context, id string) (*Broadcast, error) {
+	var b Broadcast
+	if err := pgxscan.Get(ctx, r.db, &b, `SELECT * FROM broadcasts WHERE id = $1`, id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound.WithCause(err)
+		}
+		return nil, err
+	}
+	return &b, nil
+}
+
+// RecordBroadcastBatch adds a batch's counts to a running broadcast and advances its cursor.
+func (r *repository) RecordBroadcastBatch(ctx context.Context, id string, sent, failed int64, cursor string) error {
+	sql := `
+		UPDATE broadcasts
+		SET sent = sent + $2, failed = failed + $3, batches = batches + 1, cursor = $4, updated_at = NOW()
+		WHERE id = $1 AND status = 'running'
+	`
+	_, err := r.db.Exec(ctx, sql, id, sent, failed, cursor)
+	return err
+}
+
+// FinishBroadcast moves a running broadcast to status (completed, failed or cancelled). errMsg
+// is nil unless it failed. It reports false when the broadcast was not running.
+func (r *repository) FinishBroadcast(ctx context.Context, id string, status BroadcastStatus, errMsg *string) (bool, error) {
+	sql := `
+		UPDATE broadcasts
+		SET status = $2, error = $3, updated_at = NOW(), finished_at = NOW()
+		WHERE id = $1 AND status = 'running'
+	`
+	tag, err := r.db.Exec(ctx, sql, id, status, errMsg)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListBroadcastRecipients returns up to limit live users with a verified email and an ID after
+// afterID (all users when empty), in ID order. A non-nil inactiveSince keeps only users with no
+// login since then.
+func (r *repository) ListBroadcastRecipients(ctx context.Context, inactiveSince *time.Time, afterID string, limit int) ([]*User, error) {
+	sql := `
+		SELECT u.* FROM users u
+		WHERE u.email_verified = TRUE AND u.email <> ''
+		  AND u.deleted_at IS NULL AND u.suspended_at IS NULL AND u.anonymized_at IS NULL
+		  AND ($1::uuid IS NULL OR u.id > $1::uuid)
+		  AND ($2::timestamptz IS NULL OR NOT EXISTS (
+			SELECT 1 FROM user_activity_events e
+			WHERE e.user_id = u.id AND e.event_type = 'login' AND e.created_at >= $2::timestamptz
+		  ))
+		ORDER BY u.id
+		LIMIT $3
+	`
+	var users []*User
+	if err := pgxscan.Select(ctx, r.db, &users, sql, nullableString(afterID), inactiveSince, limit); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
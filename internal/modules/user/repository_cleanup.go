@@ -0,0 +1,207 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
+)
+
+// ListUnverifiedCreatedBefore returns non-deleted users created before the cutoff that have
+// verified neither their email nor their phone.
+func (r *repository) ListUnverifiedCreatedBefore(ctx context.Context, before time.Time, limit int) ([]*User, error) {
+	sql := `
+		SELECT * FROM users
+		WHERE email_verified = FALSE AND phone_verified = FALSE AND deleted_at IS NULL AND created_at < $1
+		ORDER BY created_at
+		LIMIT $2
+	`
+	var users []*User
+	if err := pgxscan.Select(ctx, r.db, &users, sql, before, limit); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// ListInactiveSince returns verified, active users with no login and no session activity
+// since the cutoff who have not been sent a re-engagement email yet.
+func (r *repository) ListInactiveSince(ctx context.Context, since time.Time, limit int) ([]*User, error) {
+	sql := `
+		SELECT u.* FROM users u
+		WHERE u.email_verified = TRUE
+		  AND u.deleted_at IS NULL
+		  AND u.reengagement_sent_at IS NULL
+		  AND u.created_at < $1
+		  AND NOT EXISTS (
+			SELECT 1 FROM user_activity_events e
+			WHERE e.user_id = u.id AND e.event_type = 'login' AND e.created_at >= $1
+		  )
+		  AND NOT EXISTS (
+			SELECT 1 FROM user_active_sessions s
+			WHERE s.user_id = u.id AND s.last_active_at >= $1
+		  )
+		ORDER BY u.created_at
+		LIMIT $2
+	`
+	var users []*User
+	if err := pgxscan.Select(ctx, r.db, &users, sql, since, limit); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// ListReengagementExpired returns users who were sent a re-engagement email before the cutoff
+// and have not logged in since.
+func (r *repository) ListReengagementExpired(ctx context.Context, sentBefore time.Time, limit int) ([]*User, error) {
+	sql := `
+		SELECT u.* FROM users u
+		WHERE u.reengagement_sent_at < $1
+		  AND u.anonymized_at IS NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM user_activity_events e
+			WHERE e.user_id = u.id AND e.event_type = 'login' AND e.created_at >= u.reengagement_sent_at
+		  )
+		ORDER BY u.reengagement_sent_at
+		LIMIT $2
+	`
+	var users []*User
+	if err := pgxscan.Select(ctx, r.db, &users, sql, sentBefore, limit); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// ListDeletedBefore returns soft-deleted, not yet anonymized users deleted before the cutoff.
+func (r *repository) ListDeletedBefore(ctx context.Context, before time.Time, limit int) ([]*User, error) {
+	sql := `
+		SELECT * FROM users
+		WHERE deleted_at < $1 AND anonymized_at IS NULL
+		ORDER BY deleted_at
+		LIMIT $2
+	`
+	var users []*User
+	if err := pgxscan.Select(ctx, r.db, &users, sql, before, limit); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// ClearReengagementForReturningUsers resets the re-engagement marker of users who logged in
+// after it was sent, so they re-enter the normal inactivity cycle.
+func (r *repository) ClearReengagementForReturningUsers(ctx context.Context) (int64, error) {
+	sql := `
+		UPDATE users u SET reengagement_sent_at = NULL, updated_at = NOW()
+		WHERE u.reengagement_sent_at IS NOT NULL
+		  AND u.anonymized_at IS NULL
+		  AND EXISTS (
+			SELECT 1 FROM user_activity_events e
+			WHERE e.user_id = u.id AND e.event_type = 'login' AND e.created_at >= u.reengagement_sent_at
+		  )
+	`
+	ct, err := r.db.Exec(ctx, sql)
+	if err != nil {
+		return 0, err
+	}
+	return ct.RowsAffected(), nil
+}
+
+// MarkReengagementSent records when the re-engagement email was sent.
+func (r *repository) MarkReengagementSent(ctx context.Context, userID string, at time.Time) error {
+	ct, err := r.db.Exec(ctx, `UPDATE users SET reengagement_sent_at = $1, updated_at = $1 WHERE id = $2`, at, userID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// HardDelete permanently removes a user; dependent rows are removed by ON DELETE CASCADE.
+func (r *repository) HardDelete(ctx context.Context, userID string) error {
+	ct, err := r.db.Exec(ctx, `DELETE FROM users WHERE id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Anonymize irreversibly scrubs a user's personal data while keeping the row (and its ID)
+// for referential integrity. Sessions and pending codes/tokens are removed, activity
+// events lose their client details, and delivery records addressed to the old email or phone are
+// redacted (outbox) or deleted (dead letters). Everything runs in a single statement, so it is atomic.
+//
+// Tables holding personal data that are added later (OAuth links, device tokens, ...)
+// must be scrubbed here as well.
+func (r *repository) Anonymize(ctx context.Context, userID string, at time.Time) error {
+	sql := `
+		WITH prev AS (
+			SELECT id, email, phone FROM users WHERE id = $2 FOR UPDATE
+		),
+		contacts AS (
+			SELECT email AS contact FROM prev WHERE email <> ''
+			UNION ALL
+			SELECT phone FROM prev WHERE phone IS NOT NULL
+		),
+		u AS (
+			UPDATE users SET
+				first_name = 'Deleted',
+				last_name = 'User',
+				email = 'anonymized+' || id::text || '@invalid',
+				password_hash = '',
+				email_verified = FALSE,
+				phone = NULL,
+				phone_verified = FALSE,
+				password_reset_token = '',
+				password_reset_token_expiry = NULL,
+				deleted_at = COALESCE(deleted_at, $1),
+				anonymized_at = $1,
+				updated_at = $1
+			WHERE id = $2
+			RETURNING id
+		),
+		s AS (DELETE FROM user_active_sessions WHERE user_id IN (SELECT id FROM u)),
+		vc AS (
+			DELETE FROM verification_codes
+			WHERE user_id IN (SELECT id FROM u) OR contact IN (SELECT contact FROM contacts)
+		),
+		t AS (DELETE FROM action_tokens WHERE user_id IN (SELECT id FROM u)),
+		ph AS (DELETE FROM user_password_history WHERE user_id IN (SELECT id FROM u)),
+		oa AS (DELETE FROM oauth_accounts WHERE user_id IN (SELECT id FROM u)),
+		e AS (
+			UPDATE user_activity_events SET ip_address = NULL, user_agent = NULL
+			WHERE user_id IN (SELECT id FROM u)
+		),
+		o AS (
+			UPDATE notification_outbox SET recipient = '[anonymized]', subject = NULL, body = ''
+			WHERE recipient IN (SELECT contact FROM contacts)
+		),
+		dl AS (DELETE FROM notification_dead_letters WHERE recipient IN (SELECT contact FROM contacts))
+		SELECT COUNT(*) FROM u
+	`
+	var n int
+	if err := r.db.QueryRow(ctx, sql, at, userID).Scan(&n); err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CreateLifecycleAudit records an automated account lifecycle action.
+func (r *repository) CreateLifecycleAudit(ctx context.Context, userID string, action LifecycleAction, reason string) error {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(ctx, `
+		INSERT INTO account_lifecycle_audit (id, user_id, action, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, id.String(), userID, string(action), reason, time.Now())
+	return err
+}
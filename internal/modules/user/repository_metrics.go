@@ -0,0 +1,462 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/metrics"
+)
+
+// repoTimer publishes per-method call counts, errors, and latency of the user repository.
+var repoTimer = metrics.NewTimer("user_repository")
+
+// instrumentedRepository decorates a Repository with per-method metrics (see metrics.Timer),
+// so slow queries and hot paths show up without instrumenting the services.
+type instrumentedRepository struct {
+	next Repository
+}
+
+// NewInstrumentedRepository wraps next so every call is timed and counted under
+// user_repository_* with the method name as label.
+func NewInstrumentedRepository(next Repository) Repository {
+	return &instrumentedRepository{next: next}
+}
+
+// observe records a call. Domain errors (not found, conflicts) are expected outcomes rather
+// than repository failures, so only other errors count towards the error rate.
+func (r *instrumentedRepository) observe(start time.Time, err error, method string) {
+	var de *DomainError
+	if errors.As(err, &de) {
+		err = nil
+	}
+	repoTimer.Observe(start, err, method)
+}
+
+func (r *instrumentedRepository) Create(ctx context.Context, user *User) error {
+	start := time.Now()
+	err := r.next.Create(ctx, user)
+	r.observe(start, err, "Create")
+	return err
+}
+
+func (r *instrumentedRepository) FindByEmail(ctx context.Context, email string) (*User, error) {
+	start := time.Now()
+	u, err := r.next.FindByEmail(ctx, email)
+	r.observe(start, err, "FindByEmail")
+	return u, err
+}
+
+func (r *instrumentedRepository) FindByPhone(ctx context.Context, phone string) (*User, error) {
+	start := time.Now()
+	u, err := r.next.FindByPhone(ctx, phone)
+	r.observe(start, err, "FindByPhone")
+	return u, err
+}
+
+func (r *instrumentedRepository) FindByID(ctx context.Context, id string) (*User, error) {
+	start := time.Now()
+	u, err := r.next.FindByID(ctx, id)
+	r.observe(start, err, "FindByID")
+	return u, err
+}
+
+func (r *instrumentedRepository) Update(ctx context.Context, user *User) error {
+	start := time.Now()
+	err := r.next.Update(ctx, user)
+	r.observe(start, err, "Update")
+	return err
+}
+
+func (r *instrumentedRepository) SetRole(ctx context.Context, userID string, role Role) error {
+	start := time.Now()
+	err := r.next.SetRole(ctx, userID, role)
+	r.observe(start, err, "SetRole")
+	return err
+}
+
+func (r *instrumentedRepository) ListUsers(ctx context.Context, filter UserFilter, beforeID string, limit int) ([]*User, error) {
+	start := time.Now()
+	users, err := r.next.ListUsers(ctx, filter, beforeID, limit)
+	r.observe(start, err, "ListUsers")
+	return users, err
+}
+
+func (r *instrumentedRepository) UpdatePassword(ctx context.Context, userID string, newPasswordHash string) error {
+	start := time.Now()
+	err := r.next.UpdatePassword(ctx, userID, newPasswordHash)
+	r.observe(start, err, "UpdatePassword")
+	return err
+}
+
+func (r *instrumentedRepository) FindByPasswordResetToken(ctx context.Context, tokenHash string) (*User, error) {
+	start := time.Now()
+	u, err := r.next.FindByPasswordResetToken(ctx, tokenHash)
+	r.observe(start, err, "FindByPasswordResetToken")
+	return u, err
+}
+
+func (r *instrumentedRepository) RehashPassword(ctx context.Context, userID string, oldPasswordHash, newPasswordHash string) error {
+	start := time.Now()
+	err := r.next.RehashPassword(ctx, userID, oldPasswordHash, newPasswordHash)
+	r.observe(start, err, "RehashPassword")
+	return err
+}
+
+func (r *instrumentedRepository) UpdatePasswordResetInfo(ctx context.Context, userID string, tokenHash string, expiry time.Time) error {
+	start := time.Now()
+	err := r.next.UpdatePasswordResetInfo(ctx, userID, tokenHash, expiry)
+	r.observe(start, err, "UpdatePasswordResetInfo")
+	return err
+}
+
+func (r *instrumentedRepository) AddPasswordHistory(ctx context.Context, userID string, passwordHash string, keep int) error {
+	start := time.Now()
+	err := r.next.AddPasswordHistory(ctx, userID, passwordHash, keep)
+	r.observe(start, err, "AddPasswordHistory")
+	return err
+}
+
+func (r *instrumentedRepository) ListPasswordHistory(ctx context.Context, userID string, limit int) ([]string, error) {
+	start := time.Now()
+	hashes, err := r.next.ListPasswordHistory(ctx, userID, limit)
+	r.observe(start, err, "ListPasswordHistory")
+	return hashes, err
+}
+
+func (r *instrumentedRepository) PasswordSetAt(ctx context.Context, userID string) (*time.Time, error) {
+	start := time.Now()
+	at, err := r.next.PasswordSetAt(ctx, userID)
+	r.observe(start, err, "PasswordSetAt")
+	return at, err
+}
+
+func (r *instrumentedRepository) CreateVerificationCode(ctx context.Context, vc *VerificationCode) error {
+	start := time.Now()
+	err := r.next.CreateVerificationCode(ctx, vc)
+	r.observe(start, err, "CreateVerificationCode")
+	return err
+}
+
+func (r *instrumentedRepository) GetActiveVerificationCodeByContact(ctx context.Context, contact string, purpose VerificationPurpose, channel VerificationChannel) (*VerificationCode, error) {
+	start := time.Now()
+	vc, err := r.next.GetActiveVerificationCodeByContact(ctx, contact, purpose, channel)
+	r.observe(start, err, "GetActiveVerificationCodeByContact")
+	return vc, err
+}
+
+func (r *instrumentedRepository) GetActiveVerificationCodeByUser(ctx context.Context, userID string, purpose VerificationPurpose, channel VerificationChannel) (*VerificationCode, error) {
+	start := time.Now()
+	vc, err := r.next.GetActiveVerificationCodeByUser(ctx, userID, purpose, channel)
+	r.observe(start, err, "GetActiveVerificationCodeByUser")
+	return vc, err
+}
+
+func (r *instrumentedRepository) UpdateVerificationCodeForResend(ctx context.Context, id string, newCodeHash string, newExpiresAt time.Time, lastSentAt time.Time, maxAttempts int) error {
+	start := time.Now()
+	err := r.next.UpdateVerificationCodeForResend(ctx, id, newCodeHash, newExpiresAt, lastSentAt, maxAttempts)
+	r.observe(start, err, "UpdateVerificationCodeForResend")
+	return err
+}
+
+func (r *instrumentedRepository) IncrementVerificationAttempt(ctx context.Context, id string) (int, int, error) {
+	start := time.Now()
+	attempts, maxAttempts, err := r.next.IncrementVerificationAttempt(ctx, id)
+	r.observe(start, err, "IncrementVerificationAttempt")
+	return attempts, maxAttempts, err
+}
+
+func (r *instrumentedRepository) ConsumeVerificationCode(ctx context.Context, id string) error {
+	start := time.Now()
+	err := r.next.ConsumeVerificationCode(ctx, id)
+	r.observe(start, err, "ConsumeVerificationCode")
+	return err
+}
+
+func (r *instrumentedRepository) CreateActionToken(ctx context.Context, t *ActionToken) error {
+	start := time.Now()
+	err := r.next.CreateActionToken(ctx, t)
+	r.observe(start, err, "CreateActionToken")
+	return err
+}
+
+func (r *instrumentedRepository) FindActionTokenByHash(ctx context.Context, tokenHash string, purpose string) (*ActionToken, error) {
+	start := time.Now()
+	t, err := r.next.FindActionTokenByHash(ctx, tokenHash, purpose)
+	r.observe(start, err, "FindActionTokenByHash")
+	return t, err
+}
+
+func (r *instrumentedRepository) ConsumeActionToken(ctx context.Context, id string) error {
+	start := time.Now()
+	err := r.next.ConsumeActionToken(ctx, id)
+	r.observe(start, err, "ConsumeActionToken")
+	return err
+}
+
+func (r *instrumentedRepository) DeleteUserActionTokensByPurpose(ctx context.Context, userID string, purpose string) error {
+	start := time.Now()
+	err := r.next.DeleteUserActionTokensByPurpose(ctx, userID, purpose)
+	r.observe(start, err, "DeleteUserActionTokensByPurpose")
+	return err
+}
+
+func (r *instrumentedRepository) CreateUserActiveSession(ctx context.Context, sess *UserActiveSession) error {
+	start := time.Now()
+	err := r.next.CreateUserActiveSession(ctx, sess)
+	r.observe(start, err, "CreateUserActiveSession")
+	return err
+}
+
+func (r *instrumentedRepository) UpdateUserActiveSessionTimestamp(ctx context.Context, sessionToken string) error {
+	start := time.Now()
+	err := r.next.UpdateUserActiveSessionTimestamp(ctx, sessionToken)
+	r.observe(start, err, "UpdateUserActiveSessionTimestamp")
+	return err
+}
+
+func (r *instrumentedRepository) DeleteSessionByToken(ctx context.Context, sessionToken string) error {
+	start := time.Now()
+	err := r.next.DeleteSessionByToken(ctx, sessionToken)
+	r.observe(start, err, "DeleteSessionByToken")
+	return err
+}
+
+func (r *instrumentedRepository) CreateSessionRevocation(ctx context.Context, job *SessionRevocation) error {
+	start := time.Now()
+	err := r.next.CreateSessionRevocation(ctx, job)
+	r.observe(start, err, "CreateSessionRevocation")
+	return err
+}
+
+func (r *instrumentedRepository) UpdateSessionRevocationProgress(ctx context.Context, id string, revoked int64, batches int) error {
+	start := time.Now()
+	err := r.next.UpdateSessionRevocationProgress(ctx, id, revoked, batches)
+	r.observe(start, err, "UpdateSessionRevocationProgress")
+	return err
+}
+
+func (r *instrumentedRepository) FinishSessionRevocation(ctx context.Context, id string, status SessionRevocationStatus, revoked int64, errMsg *string) error {
+	start := time.Now()
+	err := r.next.FinishSessionRevocation(ctx, id, status, revoked, errMsg)
+	r.observe(start, err, "FinishSessionRevocation")
+	return err
+}
+
+func (r *instrumentedRepository) FindSessionRevocationByID(ctx context.Context, id string) (*SessionRevocation, error) {
+	start := time.Now()
+	job, err := r.next.FindSessionRevocationByID(ctx, id)
+	r.observe(start, err, "FindSessionRevocationByID")
+	return job, err
+}
+
+func (r *instrumentedRepository) CreateBroadcast(ctx context.Context, b *Broadcast) error {
+	start := time.Now()
+	err := r.next.CreateBroadcast(ctx, b)
+	r.observe(start, err, "CreateBroadcast")
+	return err
+}
+
+func (r *instrumentedRepository) FindBroadcastByID(ctx context.Context, id string) (*Broadcast, error) {
+	start := time.Now()
+	b, err := r.next.FindBroadcastByID(ctx, id)
+	r.observe(start, err, "FindBroadcastByID")
+	return b, err
+}
+
+func (r *instrumentedRepository) RecordBroadcastBatch(ctx context.Context, id string, sent, failed int64, cursor string) error {
+	start := time.Now()
+	err := r.next.RecordBroadcastBatch(ctx, id, sent, failed, cursor)
+	r.observe(start, err, "RecordBroadcastBatch")
+	return err
+}
+
+func (r *instrumentedRepository) FinishBroadcast(ctx context.Context, id string, status BroadcastStatus, errMsg *string) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.FinishBroadcast(ctx, id, status, errMsg)
+	r.observe(start, err, "FinishBroadcast")
+	return ok, err
+}
+
+func (r *instrumentedRepository) ListBroadcastRecipients(ctx context.Context, inactiveSince *time.Time, afterID string, limit int) ([]*User, error) {
+	start := time.Now()
+	users, err := r.next.ListBroadcastRecipients(ctx, inactiveSince, afterID, limit)
+	r.observe(start, err, "ListBroadcastRecipients")
+	return users, err
+}
+
+func (r *instrumentedRepository) CreateActivityEvent(ctx context.Context, e *ActivityEvent) error {
+	start := time.Now()
+	err := r.next.CreateActivityEvent(ctx, e)
+	r.observe(start, err, "CreateActivityEvent")
+	return err
+}
+
+func (r *instrumentedRepository) ListActivityEvents(ctx context.Context, userID string, beforeID string, limit int) ([]*ActivityEvent, error) {
+	start := time.Now()
+	events, err := r.next.ListActivityEvents(ctx, userID, beforeID, limit)
+	r.observe(start, err, "ListActivityEvents")
+	return events, err
+}
+
+func (r *instrumentedRepository) ListActivityEventsSince(ctx context.Context, userID string, types []ActivityType, since time.Time, limit int) ([]*ActivityEvent, error) {
+	start := time.Now()
+	events, err := r.next.ListActivityEventsSince(ctx, userID, types, since, limit)
+	r.observe(start, err, "ListActivityEventsSince")
+	return events, err
+}
+
+func (r *instrumentedRepository) KnownLoginDevice(ctx context.Context, userID, userAgent, ipAddress string) (bool, bool, error) {
+	start := time.Now()
+	known, anyLogin, err := r.next.KnownLoginDevice(ctx, userID, userAgent, ipAddress)
+	r.observe(start, err, "KnownLoginDevice")
+	return known, anyLogin, err
+}
+
+func (r *instrumentedRepository) ListUnverifiedCreatedBefore(ctx context.Context, before time.Time, limit int) ([]*User, error) {
+	start := time.Now()
+	users, err := r.next.ListUnverifiedCreatedBefore(ctx, before, limit)
+	r.observe(start, err, "ListUnverifiedCreatedBefore")
+	return users, err
+}
+
+func (r *instrumentedRepository) ListInactiveSince(ctx context.Context, since time.Time, limit int) ([]*User, error) {
+	start := time.Now()
+	users, err := r.next.ListInactiveSince(ctx, since, limit)
+	r.observe(start, err, "ListInactiveSince")
+	return users, err
+}
+
+func (r *instrumentedRepository) ListReengagementExpired(ctx context.Context, sentBefore time.Time, limit int) ([]*User, error) {
+	start := time.Now()
+	users, err := r.next.ListReengagementExpired(ctx, sentBefore, limit)
+	r.observe(start, err, "ListReengagementExpired")
+	return users, err
+}
+
+func (r *instrumentedRepository) ListDeletedBefore(ctx context.Context, before time.Time, limit int) ([]*User, error) {
+	start := time.Now()
+	users, err := r.next.ListDeletedBefore(ctx, before, limit)
+	r.observe(start, err, "ListDeletedBefore")
+	return users, err
+}
+
+func (r *instrumentedRepository) ClearReengagementForReturningUsers(ctx context.Context) (int64, error) {
+	start := time.Now()
+	n, err := r.next.ClearReengagementForReturningUsers(ctx)
+	r.observe(start, err, "ClearReengagementForReturningUsers")
+	return n, err
+}
+
+func (r *instrumentedRepository) MarkReengagementSent(ctx context.Context, userID string, at time.Time) error {
+	start := time.Now()
+	err := r.next.MarkReengagementSent(ctx, userID, at)
+	r.observe(start, err, "MarkReengagementSent")
+	return err
+}
+
+func (r *instrumentedRepository) HardDelete(ctx context.Context, userID string) error {
+	start := time.Now()
+	err := r.next.HardDelete(ctx, userID)
+	r.observe(start, err, "HardDelete")
+	return err
+}
+
+func (r *instrumentedRepository) Anonymize(ctx context.Context, userID string, at time.Time) error {
+	start := time.Now()
+	err := r.next.Anonymize(ctx, userID, at)
+	r.observe(start, err, "Anonymize")
+	return err
+}
+
+func (r *instrumentedRepository) CreateLifecycleAudit(ctx context.Context, userID string, action LifecycleAction, reason string) error {
+	start := time.Now()
+	err := r.next.CreateLifecycleAudit(ctx, userID, action, reason)
+	r.observe(start, err, "CreateLifecycleAudit")
+	return err
+}
+
+func (r *instrumentedRepository) PurgeRetained(ctx context.Context, target RetentionTarget, before time.Time, namespace string, limit int) (int64, error) {
+	start := time.Now()
+	n, err := r.next.PurgeRetained(ctx, target, before, namespace, limit)
+	r.observe(start, err, "PurgeRetained")
+	return n, err
+}
+
+func (r *instrumentedRepository) PurgeExpired(ctx context.Context, table string, now time.Time, limit int) (int64, error) {
+	start := time.Now()
+	n, err := r.next.PurgeExpired(ctx, table, now, limit)
+	r.observe(start, err, "PurgeExpired")
+	return n, err
+}
+
+func (r *instrumentedRepository) InsertOAuthState(ctx context.Context, state *OAuthState) error {
+	start := time.Now()
+	err := r.next.InsertOAuthState(ctx, state)
+	r.observe(start, err, "InsertOAuthState")
+	return err
+}
+
+func (r *instrumentedRepository) GetOAuthStateByState(ctx context.Context, state string) (*OAuthState, error) {
+	start := time.Now()
+	st, err := r.next.GetOAuthStateByState(ctx, state)
+	r.observe(start, err, "GetOAuthStateByState")
+	return st, err
+}
+
+func (r *instrumentedRepository) UpdateOAuthStateUserID(ctx context.Context, state string, userID string) (*OAuthState, error) {
+	start := time.Now()
+	st, err := r.next.UpdateOAuthStateUserID(ctx, state, userID)
+	r.observe(start, err, "UpdateOAuthStateUserID")
+	return st, err
+}
+
+func (r *instrumentedRepository) DeleteOAuthState(ctx context.Context, state string) error {
+	start := time.Now()
+	err := r.next.DeleteOAuthState(ctx, state)
+	r.observe(start, err, "DeleteOAuthState")
+	return err
+}
+
+
+func (r *instrumentedRepository) FindOAuthAccount(ctx context.Context, provider OAuthProvider, providerUserID string) (*OAuthAccount, error) {
+	start := time.Now()
+	a, err := r.next.FindOAuthAccount(ctx, provider, providerUserID)
+	r.observe(start, err, "FindOAuthAccount")
+	return a, err
+}
+
+func (r *instrumentedRepository) FindOAuthAccountByUser(ctx context.Context, userID string, provider OAuthProvider) (*OAuthAccount, error) {
+	start := time.Now()
+	a, err := r.next.FindOAuthAccountByUser(ctx, userID, provider)
+	r.observe(start, err, "FindOAuthAccountByUser")
+	return a, err
+}
+
+func (r *instrumentedRepository) ListOAuthAccounts(ctx context.Context, userID string) ([]*OAuthAccount, error) {
+	start := time.Now()
+	a, err := r.next.ListOAuthAccounts(ctx, userID)
+	r.observe(start, err, "ListOAuthAccounts")
+	return a, err
+}
+
+func (r *instrumentedRepository) CreateOAuthAccount(ctx context.Context, account *OAuthAccount) error {
+	start := time.Now()
+	err := r.next.CreateOAuthAccount(ctx, account)
+	r.observe(start, err, "CreateOAuthAccount")
+	return err
+}
+
+func (r *instrumentedRepository) DeleteOAuthAccount(ctx context.Context, userID string, provider OAuthProvider) error {
+	start := time.Now()
+	err := r.next.DeleteOAuthAccount(ctx, userID, provider)
+	r.observe(start, err, "DeleteOAuthAccount")
+	return err
+}
+
+func (r *instrumentedRepository) UpdateOAuthAccountTokens(ctx context.Context, provider OAuthProvider, providerUserID string, accessToken, refreshToken []byte, expiresAt *time.Time) error {
+	start := time.Now()
+	err := r.next.UpdateOAuthAccountTokens(ctx, provider, providerUserID, accessToken, refreshToken, expiresAt)
+	r.observe(start, err, "UpdateOAuthAccountTokens")
+	return err
+}
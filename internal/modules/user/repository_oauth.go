@@ -16,8 +16,8 @@ func (r *repository) InsertOAuthState(ctx context.Context, state *OAuthState) er
 	state.UpdatedAt = time.Now()
 
 	query, args, err := r.psql.Insert("oauth_states").
-		Columns("state", "provider", "user_id", "verifier", "expires_at", "created_at", "updated_at").
-		Values(state.State, state.Provider, state.UserID, state.Verifier, state.ExpiresAt, state.CreatedAt, state.UpdatedAt).
+		Columns("state", "provider", "user_id", "verifier", "nonce", "expires_at", "created_at", "updated_at").
+		Values(state.State, state.Provider, state.UserID, state.Verifier, state.Nonce, state.ExpiresAt, state.CreatedAt, state.UpdatedAt).
 		ToSql()
 	if err != nil {
 		return err
@@ -25,7 +25,7 @@ func (r *repository) InsertOAuthState(ctx context.Context, state *OAuthState) er
 
 	_, err = r.db.Exec(ctx, query, args...)
 	if err != nil {
-		return err
+		return mapWriteError(err)
 	}
 
 	return nil
@@ -100,22 +100,123 @@ func (r *repository) DeleteOAuthState(ctx context.Context, state string) error {
 	return nil
 }
 
-// DeleteExpiredOAuthStates removes all OAuth state records that have expired.
-// This should be called periodically as a cleanup operation.
-func (r *repository) DeleteExpiredOAuthStates(ctx context.Context) error {
-	query, args, err := r.psql.Delete("oauth_states").
-		Where(squirrel.Lt{"expires_at": time.Now()}).
+// FindOAuthAccount retrieves the identity linked for a provider's subject.
+func (r *repository) FindOAuthAccount(ctx context.Context, provider OAuthProvider, providerUserID string) (*OAuthAccount, error) {
+	query, args, err := r.psql.Select("*").
+		From("oauth_accounts").
+		Where(squirrel.Eq{"provider": provider, "provider_user_id": providerUserID}).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var account OAuthAccount
+	if err := pgxscan.Get(ctx, r.db, &account, query, args...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound.WithCause(err)
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// FindOAuthAccountByUser retrieves the user's identity for provider.
+func (r *repository) FindOAuthAccountByUser(ctx context.Context, userID string, provider OAuthProvider) (*OAuthAccount, error) {
+	query, args, err := r.psql.Select("*").
+		From("oauth_accounts").
+		Where(squirrel.Eq{"user_id": userID, "provider": provider}).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var account OAuthAccount
+	if err := pgxscan.Get(ctx, r.db, &account, query, args...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound.WithCause(err)
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// ListOAuthAccounts returns the identities linked to a user, oldest first.
+func (r *repository) ListOAuthAccounts(ctx context.Context, userID string) ([]*OAuthAccount, error) {
+	query, args, err := r.psql.Select("*").
+		From("oauth_accounts").
+		Where(squirrel.Eq{"user_id": userID}).
+		OrderBy("created_at").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var accounts []*OAuthAccount
+	if err := pgxscan.Select(ctx, r.db, &accounts, query, args...); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// CreateOAuthAccount links a provider identity to a user. The unique constraints make it
+// fail with ErrConflict when the identity is linked elsewhere or the user already has one
+// for this provider.
+func (r *repository) CreateOAuthAccount(ctx context.Context, account *OAuthAccount) error {
+	account.CreatedAt = time.Now()
+	account.UpdatedAt = account.CreatedAt
+
+	query, args, err := r.psql.Insert("oauth_accounts").
+		Columns("id", "user_id", "provider", "provider_user_id", "email", "created_at", "updated_at").
+		Values(account.ID, account.UserID, account.Provider, account.ProviderUserID, account.Email, account.CreatedAt, account.UpdatedAt).
 		ToSql()
 	if err != nil {
 		return err
 	}
 
 	_, err = r.db.Exec(ctx, query, args...)
+	return mapWriteError(err)
+}
+
+// DeleteOAuthAccount unlinks the user's identity for provider.
+func (r *repository) DeleteOAuthAccount(ctx context.Context, userID string, provider OAuthProvider) error {
+	query, args, err := r.psql.Delete("oauth_accounts").
+		Where(squirrel.Eq{"user_id": userID, "provider": provider}).
+		ToSql()
 	if err != nil {
 		return err
 	}
 
-	// Note: We don't return an error if no rows were deleted,
-	// as it's normal for there to be no expired states to clean up.
+	cmdTag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// UpdateOAuthAccountTokens stores the sealed tokens of the identity (provider, providerUserID).
+func (r *repository) UpdateOAuthAccountTokens(ctx context.Context, provider OAuthProvider, providerUserID string, accessToken, refreshToken []byte, expiresAt *time.Time) error {
+	query, args, err := r.psql.Update("oauth_accounts").
+		Set("access_token", accessToken).
+		Set("refresh_token", squirrel.Expr("COALESCE(?, refresh_token)", refreshToken)).
+		Set("token_expires_at", expiresAt).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"provider": provider, "provider_user_id": providerUserID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	cmdTag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
 	return nil
 }
@@ -0,0 +1,67 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
+)
+
+// AddPasswordHistory records a password hash for the user and prunes all but the newest keep
+// entries in the same statement.
+func (r *repository) AddPasswordHistory(ctx context.Context, userID string, passwordHash string, keep int) error {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return err
+	}
+	sql := `
+		WITH ins AS (
+			INSERT INTO user_password_history (id, user_id, password_hash)
+			VALUES ($1, $2, $3)
+		)
+		DELETE FROM user_password_history
+		WHERE user_id = $2
+		  AND id NOT IN (
+			SELECT id FROM user_password_history
+			WHERE user_id = $2
+			ORDER BY created_at DESC, id DESC
+			LIMIT $4
+		  )
+	`
+	// The DELETE cannot see the row inserted by the CTE, so it keeps the newest keep-1 older rows.
+	_, err = r.db.Exec(ctx, sql, id.String(), userID, passwordHash, keep-1)
+	return err
+}
+
+// ListPasswordHistory returns the user's most recent password hashes, newest first.
+func (r *repository) ListPasswordHistory(ctx context.Context, userID string, limit int) ([]string, error) {
+	sql := `
+		SELECT password_hash FROM user_password_history
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`
+	var hashes []string
+	if err := pgxscan.Select(ctx, r.db, &hashes, sql, userID, limit); err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
+
+// PasswordSetAt returns when the user's password was last set, from the password history and
+// the password change/reset activity, or nil when neither has a record (e.g. both were pruned).
+func (r *repository) PasswordSetAt(ctx context.Context, userID string) (*time.Time, error) {
+	sql := `
+		SELECT GREATEST(
+			(SELECT MAX(created_at) FROM user_password_history WHERE user_id = $1),
+			(SELECT MAX(created_at) FROM user_activity_events WHERE user_id = $1 AND event_type = ANY($2))
+		)
+	`
+	var at *time.Time
+	types := []string{string(ActivityPasswordChanged), string(ActivityPasswordReset)}
+	if err := r.db.QueryRow(ctx, sql, userID, types).Scan(&at); err != nil {
+		return nil, err
+	}
+	return at, nil
+}
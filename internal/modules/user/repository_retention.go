@@ -0,0 +1,85 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// retentionQueries delete up to $2 records of a target older than $1. Each deletes by primary
+// key from a LIMITed subquery, so a pass never holds long locks on a large table.
+var retentionQueries = map[RetentionTarget]string{
+	RetentionAudit: `
+		DELETE FROM account_lifecycle_audit WHERE id IN (
+			SELECT id FROM account_lifecycle_audit WHERE created_at < $1 LIMIT $2
+		)`,
+	// A user's latest login is kept whatever its age: inactive-account cleanup reads it.
+	RetentionActivity: `
+		DELETE FROM user_activity_events WHERE id IN (
+			SELECT e.id FROM user_activity_events e
+			WHERE e.created_at < $1
+			  AND (e.event_type <> 'login' OR EXISTS (
+				SELECT 1 FROM user_activity_events n
+				WHERE n.user_id = e.user_id AND n.event_type = 'login' AND n.id > e.id
+			  ))
+			LIMIT $2
+		)`,
+	// Only this deployment's deliveries; environments sharing the database set their own policy.
+	RetentionDeliveries: `
+		DELETE FROM notification_outbox WHERE id IN (
+			SELECT id FROM notification_outbox WHERE created_at < $1 AND namespace = $3 LIMIT $2
+		)`,
+	RetentionVerificationCodes: `
+		DELETE FROM verification_codes WHERE id IN (
+			SELECT id FROM verification_codes WHERE expires_at < $1 LIMIT $2
+		)`,
+}
+
+// PurgeRetained deletes up to limit records of target older than before and returns how many
+// were deleted. namespace scopes targets shared between deployments (deliveries).
+func (r *repository) PurgeRetained(ctx context.Context, target RetentionTarget, before time.Time, namespace string, limit int) (int64, error) {
+	sql, ok := retentionQueries[target]
+	if !ok {
+		return 0, fmt.Errorf("unknown retention target %q", target)
+	}
+	args := []any{before, limit}
+	if target == RetentionDeliveries {
+		args = append(args, namespace)
+	}
+	ct, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+	return ct.RowsAffected(), nil
+}
+
+// expiredQueries delete up to $2 rows of a table that expired before $1. Unlike retention
+// targets, these rows are useless once expired and are deleted right away.
+var expiredQueries = map[string]string{
+	ExpiredActionTokens: `
+		DELETE FROM action_tokens WHERE id IN (
+			SELECT id FROM action_tokens WHERE expires_at < $1 LIMIT $2
+		)`,
+	ExpiredOAuthStates: `
+		DELETE FROM oauth_states WHERE state IN (
+			SELECT state FROM oauth_states WHERE expires_at < $1 LIMIT $2
+		)`,
+	ExpiredVerificationCodes: `
+		DELETE FROM verification_codes WHERE id IN (
+			SELECT id FROM verification_codes WHERE expires_at < $1 LIMIT $2
+		)`,
+}
+
+// PurgeExpired deletes up to limit rows of table that expired before now and returns how many
+// were deleted.
+func (r *repository) PurgeExpired(ctx context.Context, table string, now time.Time, limit int) (int64, error) {
+	sql, ok := expiredQueries[table]
+	if !ok {
+		return 0, fmt.Errorf("unknown expiring table %q", table)
+	}
+	ct, err := r.db.Exec(ctx, sql, now, limit)
+	if err != nil {
+		return 0, err
+	}
+	return ct.RowsAffected(), nil
+}
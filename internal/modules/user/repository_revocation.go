@@ -0,0 +1,55 @@
+package user
+
+import (
+	"context"
+	"errors"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+)
+
+// CreateSessionRevocation inserts a running bulk revocation job.
+func (r *repository) CreateSessionRevocation(ctx context.Context, job *SessionRevocation) error {
+	sql := `
+		INSERT INTO session_revocation_jobs (id, actor_id, created_before, ip_range, unverified_users, reason, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at, updated_at
+	`
+	return r.db.QueryRow(ctx, sql,
+		job.ID, job.ActorID, job.CreatedBefore, job.IPRange, job.UnverifiedUsers, job.Reason, job.Status,
+	).Scan(&job.CreatedAt, &job.UpdatedAt)
+}
+
+// UpdateSessionRevocationProgress records the running total after a batch.
+func (r *repository) UpdateSessionRevocationProgress(ctx context.Context, id string, revoked int64, batches int) error {
+	sql := `
+		UPDATE session_revocation_jobs
+		SET revoked = $2, batches = $3, updated_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(ctx, sql, id, revoked, batches)
+	return err
+}
+
+// FinishSessionRevocation marks a job completed or failed. errMsg is nil on success.
+func (r *repository) FinishSessionRevocation(ctx context.Context, id string, status SessionRevocationStatus, revoked int64, errMsg *string) error {
+	sql := `
+		UPDATE session_revocation_jobs
+		SET status = $2, revoked = $3, error = $4, updated_at = NOW(), finished_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(ctx, sql, id, status, revoked, errMsg)
+	return err
+}
+
+// FindSessionRevocationByID returns a bulk revocation job.
+func (r *repository) FindSessionRevocationByID(ctx context.Context, id string) (*SessionRevocation, error) {
+	var job SessionRevocation
+	if err := pgxscan.Get(ctx, r.db, &job, `SELECT * FROM session_revocation_jobs WHERE id = $1`, id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound.WithCause(err)
+		}
+		return nil, err
+	}
+	return &job, nil
+}
@@ -5,16 +5,18 @@ import (
 	"time"
 
 	"github.com/Masterminds/squirrel"
+	"github.com/delordemm1/go-api-simple-starter/internal/session"
 )
 
-// CreateUserActiveSession inserts a new user session record into the database.
+// CreateUserActiveSession inserts a new user session record into the database. Only the hash of
+// the session token is stored (see session.HashToken).
 func (r *repository) CreateUserActiveSession(ctx context.Context, sess *UserActiveSession) error {
 	sess.CreatedAt = time.Now()
 	sess.LastActiveAt = time.Now()
 
 	query, args, err := r.psql.Insert("user_active_sessions").
 		Columns("id", "user_id", "session_token", "user_agent", "ip_address", "last_active_at", "created_at").
-		Values(sess.ID, sess.UserID, sess.SessionToken, sess.UserAgent, sess.IpAddress, sess.LastActiveAt, sess.CreatedAt).
+		Values(sess.ID, sess.UserID, session.HashToken(sess.SessionToken), sess.UserAgent, sess.IpAddress, sess.LastActiveAt, sess.CreatedAt).
 		ToSql()
 	if err != nil {
 		return err
@@ -22,7 +24,7 @@ func (r *repository) CreateUserActiveSession(ctx context.Context, sess *UserActi
 
 	_, err = r.db.Exec(ctx, query, args...)
 	if err != nil {
-		return err
+		return mapWriteError(err)
 	}
 
 	return nil
@@ -32,7 +34,7 @@ func (r *repository) CreateUserActiveSession(ctx context.Context, sess *UserActi
 func (r *repository) UpdateUserActiveSessionTimestamp(ctx context.Context, sessionToken string) error {
 	query, args, err := r.psql.Update("user_active_sessions").
 		Set("last_active_at", time.Now()).
-		Where(squirrel.Eq{"session_token": sessionToken}).
+		Where(squirrel.Eq{"session_token": session.HashToken(sessionToken)}).
 		ToSql()
 	if err != nil {
 		return err
@@ -53,7 +55,7 @@ func (r *repository) UpdateUserActiveSessionTimestamp(ctx context.Context, sessi
 // DeleteSessionByToken removes a session from the database by its token.
 func (r *repository) DeleteSessionByToken(ctx context.Context, sessionToken string) error {
 	query, args, err := r.psql.Delete("user_active_sessions").
-		Where(squirrel.Eq{"session_token": sessionToken}).
+		Where(squirrel.Eq{"session_token": session.HashToken(sessionToken)}).
 		ToSql()
 	if err != nil {
 		return err
@@ -3,12 +3,12 @@ package user
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgconn"
 )
 
 // Create inserts a new user record into the database.
@@ -17,8 +17,8 @@ func (r *repository) Create(ctx context.Context, user *User) error {
 	user.UpdatedAt = time.Now()
 
 	query, args, err := r.psql.Insert("users").
-		Columns("id", "first_name", "last_name", "email", "password_hash", "email_verified", "created_at", "updated_at").
-		Values(user.ID, user.FirstName, user.LastName, user.Email, user.PasswordHash, user.EmailVerified, user.CreatedAt, user.UpdatedAt).
+		Columns("id", "first_name", "last_name", "email", "password_hash", "email_verified", "phone", "phone_verified", "terms_version", "terms_accepted_at", "created_at", "updated_at").
+		Values(user.ID, user.FirstName, user.LastName, user.Email, user.PasswordHash, user.EmailVerified, user.Phone, user.PhoneVerified, user.TermsVersion, user.TermsAcceptedAt, user.CreatedAt, user.UpdatedAt).
 		ToSql()
 	if err != nil {
 		return err
@@ -27,11 +27,7 @@ func (r *repository) Create(ctx context.Context, user *User) error {
 	_, err = r.db.Exec(ctx, query, args...)
 	if err != nil {
 		// Map unique constraint violation on users.email to a domain error.
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-			return ErrEmailExists.WithCause(err)
-		}
-		return err
+		return mapWriteError(err)
 	}
 
 	return nil
@@ -61,6 +57,30 @@ func (r *repository) FindByEmail(ctx context.Context, email string) (*User, erro
 	return &user, nil
 }
 
+// FindByPhone retrieves a user by their E.164 phone number.
+// It returns ErrNotFound if no user is found.
+func (r *repository) FindByPhone(ctx context.Context, phone string) (*User, error) {
+	query, args, err := r.psql.Select("*").
+		From("users").
+		Where(squirrel.Eq{"phone": phone}).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var user User
+	err = pgxscan.Get(ctx, r.db, &user, query, args...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound.WithCause(err)
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
 // FindByID retrieves a user by their unique ID.
 // It returns ErrNotFound if no user is found.
 func (r *repository) FindByID(ctx context.Context, id string) (*User, error) {
@@ -95,6 +115,17 @@ func (r *repository) Update(ctx context.Context, user *User) error {
 		Set("email", user.Email).
 		Set("password_hash", user.PasswordHash).
 		Set("email_verified", user.EmailVerified).
+		Set("phone", user.Phone).
+		Set("phone_verified", user.PhoneVerified).
+		Set("deleted_at", user.DeletedAt).
+		Set("password_reset_required", user.PasswordResetRequired).
+		Set("suspended_at", user.SuspendedAt).
+		Set("suspended_reason", user.SuspendedReason).
+		Set("locale", user.Locale).
+		Set("timezone", user.TimeZone).
+		Set("onboarding_unsubscribed_at", user.OnboardingUnsubscribedAt).
+		Set("terms_version", user.TermsVersion).
+		Set("terms_accepted_at", user.TermsAcceptedAt).
 		Set("updated_at", user.UpdatedAt).
 		Where(squirrel.Eq{"id": user.ID}).
 		ToSql()
@@ -104,7 +135,7 @@ func (r *repository) Update(ctx context.Context, user *User) error {
 
 	ct, err := r.db.Exec(ctx, query, args...)
 	if err != nil {
-		return err
+		return mapWriteError(err)
 	}
 
 	if ct.RowsAffected() == 0 {
@@ -114,6 +145,72 @@ func (r *repository) Update(ctx context.Context, user *User) error {
 	return nil
 }
 
+// SetRole updates the user's role.
+func (r *repository) SetRole(ctx context.Context, userID string, role Role) error {
+	query, args, err := r.psql.Update("users").
+		Set("role", role).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"id": userID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	ct, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListUsers returns up to limit users matching filter, newest first. When beforeID is set,
+// only users created before that user (by UUIDv7 order) are returned. Anonymized accounts
+// are never listed.
+func (r *repository) ListUsers(ctx context.Context, filter UserFilter, beforeID string, limit int) ([]*User, error) {
+	q := r.psql.Select("*").
+		From("users").
+		Where(squirrel.Eq{"anonymized_at": nil}).
+		OrderBy("id DESC").
+		Limit(uint64(limit))
+	if beforeID != "" {
+		q = q.Where(squirrel.Lt{"id": beforeID})
+	}
+	if filter.EmailVerified != nil {
+		q = q.Where(squirrel.Eq{"email_verified": *filter.EmailVerified})
+	}
+	if filter.Suspended != nil {
+		if *filter.Suspended {
+			q = q.Where(squirrel.NotEq{"suspended_at": nil})
+		} else {
+			q = q.Where(squirrel.Eq{"suspended_at": nil})
+		}
+	}
+	if filter.Query != "" {
+		pattern := "%" + likeEscaper.Replace(filter.Query) + "%"
+		q = q.Where(squirrel.Or{
+			squirrel.ILike{"email": pattern},
+			squirrel.ILike{"first_name": pattern},
+			squirrel.ILike{"last_name": pattern},
+		})
+	}
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	var users []*User
+	if err := pgxscan.Select(ctx, r.db, &users, sql, args...); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// likeEscaper escapes LIKE wildcards so search terms match literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 // UpdatePasswordResetInfo stores the hashed reset token and its expiry for a given user.
 func (r *repository) UpdatePasswordResetInfo(ctx context.Context, userID string, tokenHash string, expiry time.Time) error {
 	sql, args, err := r.psql.Update("users").
@@ -140,6 +237,7 @@ func (r *repository) UpdatePasswordResetInfo(ctx context.Context, userID string,
 func (r *repository) UpdatePassword(ctx context.Context, userID string, newPasswordHash string) error {
 	sql, args, err := r.psql.Update("users").
 		Set("password_hash", newPasswordHash).
+		Set("password_reset_required", false).
 		Set("password_reset_token", nil).
 		Set("password_reset_token_expiry", nil).
 		Set("updated_at", time.Now()).
@@ -159,6 +257,22 @@ func (r *repository) UpdatePassword(ctx context.Context, userID string, newPassw
 	return nil
 }
 
+// RehashPassword replaces the stored hash of the user's unchanged password with one made with
+// the current algorithm. It is a no-op if the hash is no longer oldPasswordHash, so a password
+// changed concurrently is never overwritten.
+func (r *repository) RehashPassword(ctx context.Context, userID string, oldPasswordHash, newPasswordHash string) error {
+	sql, args, err := r.psql.Update("users").
+		Set("password_hash", newPasswordHash).
+		Where(squirrel.Eq{"id": userID, "password_hash": oldPasswordHash}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	return err
+}
+
 // FindByPasswordResetToken finds a user by their hashed password reset token.
 func (r *repository) FindByPasswordResetToken(ctx context.Context, tokenHash string) (*User, error) {
 	return r.findOne(ctx, squirrel.Eq{"password_reset_token": tokenHash})
@@ -11,7 +11,7 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
-// --- Verification Codes (6-digit OTP) ---
+// --- Verification Codes (OTP) ---
 
 func (r *repository) CreateVerificationCode(ctx context.Context, vc *VerificationCode) error {
 	if vc.ID == "" {
@@ -37,7 +37,7 @@ func (r *repository) CreateVerificationCode(ctx context.Context, vc *Verificatio
 		return err
 	}
 	_, err = r.db.Exec(ctx, sql, args...)
-	return err
+	return mapWriteError(err)
 }
 
 func (r *repository) GetActiveVerificationCodeByContact(ctx context.Context, contact string, purpose VerificationPurpose, channel VerificationChannel) (*VerificationCode, error) {
@@ -166,7 +166,7 @@ func (r *repository) CreateActionToken(ctx context.Context, t *ActionToken) erro
 		return err
 	}
 	_, err = r.db.Exec(ctx, sql, args...)
-	return err
+	return mapWriteError(err)
 }
 
 func (r *repository) FindActionTokenByHash(ctx context.Context, tokenHash string, purpose string) (*ActionToken, error) {
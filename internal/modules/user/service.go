@@ -2,11 +2,26 @@ package user
 
 import (
 	"context"
+	"encoding/json"
 	"log/slog"
+	"sync"
+	"time"
 
+	"github.com/delordemm1/go-api-simple-starter/internal/captcha"
+	"github.com/delordemm1/go-api-simple-starter/internal/clock"
+	"github.com/delordemm1/go-api-simple-starter/internal/coalesce"
 	"github.com/delordemm1/go-api-simple-starter/internal/config"
+	"github.com/delordemm1/go-api-simple-starter/internal/idempotency"
+	"github.com/delordemm1/go-api-simple-starter/internal/ipreputation"
+	"github.com/delordemm1/go-api-simple-starter/internal/jobs"
 	"github.com/delordemm1/go-api-simple-starter/internal/notification"
+	"github.com/delordemm1/go-api-simple-starter/internal/notification/templates"
+	"github.com/delordemm1/go-api-simple-starter/internal/passhash"
+	"github.com/delordemm1/go-api-simple-starter/internal/runbook"
+	"github.com/delordemm1/go-api-simple-starter/internal/secretbox"
 	"github.com/delordemm1/go-api-simple-starter/internal/session"
+	"github.com/delordemm1/go-api-simple-starter/internal/siem"
+	"golang.org/x/oauth2"
 )
 
 // Service defines the interface for the user module's business logic.
@@ -15,33 +30,159 @@ import (
 type Service interface {
 	// Auth-related methods
 	Register(ctx context.Context, firstName, lastName, email, password string) (*User, error)
-	Login(ctx context.Context, email, password string) (string, error) // Returns a session ID
+	ScreenRegistration(ctx context.Context, signals RegistrationSignals) error
+	// VerifyCaptcha checks token when the endpoint (a captcha.Endpoint* name) requires a CAPTCHA.
+	VerifyCaptcha(ctx context.Context, endpoint, token string) error
+	Login(ctx context.Context, email, password string, rememberMe bool) (string, error) // Returns a session ID
+	// ConfirmLoginStepUp completes a Login that returned ErrLoginStepUpRequired.
+	ConfirmLoginStepUp(ctx context.Context, email, code string, rememberMe bool) (sessionID string, err error)
+	Logout(ctx context.Context, userID, sessionID string) error
+	LogoutAll(ctx context.Context, userID string) error // Signs out every device
+	// RebindSession re-checks the password and binds the session to the current client. The
+	// session's token is rotated; the returned one replaces sessionID, also when err is set.
+	RebindSession(ctx context.Context, userID, sessionID, password string) (newSessionID string, err error)
+	// Reauthenticate re-checks the password and puts the session in sudo mode until the returned
+	// time. The session's token is rotated; the returned one replaces sessionID, also when err is
+	// set.
+	Reauthenticate(ctx context.Context, userID, sessionID, password string) (newSessionID string, until time.Time, err error)
+
+	// Session (device) management
+	ListSessions(ctx context.Context, userID string) ([]*session.Info, error)
+	RevokeSession(ctx context.Context, userID, id string) error
+	// RevokeSessionsWithToken signs out every session using the link of a sign-in alert email.
+	RevokeSessionsWithToken(ctx context.Context, token string) error
+
+	// Phone login (SMS one-time code)
+	RegisterWithPhone(ctx context.Context, firstName, lastName, phone string) (*User, error)
+	RequestPhoneLoginCode(ctx context.Context, phone string) error
+	ConfirmPhoneLogin(ctx context.Context, phone, code string) (sessionID string, err error)
 
 	// Profile-related methods
 	GetProfile(ctx context.Context, userID string) (*User, error)
+	SecurityStatus(ctx context.Context, user *User) (*SecurityStatus, error)
+	// SecurityCheckup aggregates the user's security posture for GET /users/me/security.
+	SecurityCheckup(ctx context.Context, userID string) (*SecurityCheckup, error)
 	UpdateProfile(ctx context.Context, userID string, input UpdateProfileInput) (*User, error)
 
-	// Email verification (6-digit code)
+	// Terms of service (TERMS_CURRENT_VERSION)
+	CheckTerms(ctx context.Context, userID string) error
+	AcceptTerms(ctx context.Context, userID, version string) (*User, error)
+
+	// Email change (code to the new address, notice to the old one)
+	RequestEmailChange(ctx context.Context, userID, newEmail string) error
+	ConfirmEmailChange(ctx context.Context, userID, code string) (*User, error)
+
+	// Onboarding email sequence
+	UnsubscribeOnboarding(ctx context.Context, userID string) error
+
+	// Account deletion and restore (soft delete with a grace period)
+	DeleteAccount(ctx context.Context, userID string) error
+	RequestAccountRestore(ctx context.Context, email string) error
+	ConfirmAccountRestore(ctx context.Context, email, code string) (sessionID string, err error)
+
+	// Admin actions (incident response)
+	IsAdmin(ctx context.Context, userID string) (bool, error)
+	PromoteAdmin(ctx context.Context, email, source string) (*User, error)
+	PromoteConfiguredAdmins(ctx context.Context)
+	ForcePasswordReset(ctx context.Context, actorID, userID, reason string) error
+	ForceReverification(ctx context.Context, actorID, userID, reason string) error
+	Impersonate(ctx context.Context, actorID, userID, reason string) (sessionID string, expiresAt time.Time, err error)
+	StartSessionRevocation(ctx context.Context, actorID string, criteria SessionRevocationCriteria, reason string) (*SessionRevocation, error)
+	GetSessionRevocation(ctx context.Context, id string) (*SessionRevocation, error)
+	StartBroadcast(ctx context.Context, actorID string, input BroadcastInput) (*Broadcast, error)
+	GetBroadcast(ctx context.Context, id string) (*Broadcast, error)
+	CancelBroadcast(ctx context.Context, actorID, id string) (*Broadcast, error)
+
+	// User management (admin tooling)
+	ListUsers(ctx context.Context, filter UserFilter, cursor string, limit int) (users []*User, nextCursor string, err error)
+	SuspendUser(ctx context.Context, actorID, userID, reason string) error
+	UnsuspendUser(ctx context.Context, actorID, userID, reason string) error
+
+	// Notification dead letters (admin tooling)
+	ListDeadLetters(ctx context.Context, status string, cursor string, limit int) (items []*notification.DeadLetter, nextCursor string, err error)
+	GetDeadLetter(ctx context.Context, id string) (*notification.DeadLetter, error)
+	UpdateDeadLetterRecipient(ctx context.Context, actorID, id, recipient string) (*notification.DeadLetter, error)
+	RequeueDeadLetter(ctx context.Context, actorID, id string) error
+
+	// Notification templates (admin tooling)
+	SendTemplateTest(ctx context.Context, actorID, templateID, recipient string, channel notification.Channel, data json.RawMessage) error
+	GetTemplate(ctx context.Context, templateID string) (content, version string, err error)
+	UploadTemplate(ctx context.Context, actorID, templateID, content string) (version string, err error)
+
+	// AnonymizeUser irreversibly scrubs a user's personal data (admin tooling and cleanup).
+	AnonymizeUser(ctx context.Context, actorID, userID, reason string) error
+
+	// CleanupAccounts runs one pass of the inactive account cleanup pipeline.
+	CleanupAccounts(ctx context.Context) (*CleanupReport, error)
+
+	// Data retention: policies are exported for compliance review and enforced by a scheduled job.
+	RetentionPolicies() []RetentionPolicy
+	EnforceRetention(ctx context.Context) (*RetentionReport, error)
+	// PurgeExpired deletes expired action tokens, OAuth states and one-time codes and returns the
+	// count per table.
+	PurgeExpired(ctx context.Context) (map[string]int64, error)
+
+	// Runbook reports the state of background subsystems (notifications, jobs, schedules).
+	Runbook(ctx context.Context) map[string]runbook.Status
+
+	// Account activity timeline
+	ListActivity(ctx context.Context, userID string, cursor string, limit int) (events []*ActivityEvent, nextCursor string, err error)
+
+	// Email verification (one-time code or link)
 	ResendEmailVerification(ctx context.Context, email string) error
 	ConfirmEmailVerification(ctx context.Context, email, code string) error
+	// ConfirmEmailVerificationLink redeems an emailed verification link and returns the frontend
+	// URL to redirect to.
+	ConfirmEmailVerificationLink(ctx context.Context, token string) (redirectURL string, err error)
 
-	// Password reset (6-digit code + internal reset token)
+	// Password reset (one-time code + internal reset token)
 	InitiatePasswordReset(ctx context.Context, email string) error
 	VerifyPasswordResetCode(ctx context.Context, email, code string) (resetToken string, err error)
 	FinalizePasswordReset(ctx context.Context, resetToken, newPassword string) error
 
+	// Password change (authenticated; other sessions are revoked, the caller's token is rotated)
+	ChangePassword(ctx context.Context, userID, sessionID, currentPassword, newPassword string) (newSessionID string, err error)
+
 	// OAuth-related methods
 	InitiateOAuthLogin(ctx context.Context, provider OAuthProvider) (redirectURL string, err error)
-	HandleOAuthCallback(ctx context.Context, provider OAuthProvider, state, code string) (sessionID string, err error)
+	InitiateOAuthLink(ctx context.Context, userID string, provider OAuthProvider) (redirectURL string, err error)
+	// HandleOAuthCallback completes a flow. appleUser is Apple's first-login `user` form field
+	// (empty otherwise); the name in it is saved, as Apple never sends it again.
+	HandleOAuthCallback(ctx context.Context, provider OAuthProvider, state, code, appleUser string) (*OAuthCallbackResult, error)
+	ListOAuthAccounts(ctx context.Context, userID string) ([]*OAuthAccount, error)
+	UnlinkOAuthAccount(ctx context.Context, userID string, provider OAuthProvider) error
+	// OAuthToken returns a valid provider token for calling the provider's APIs on the user's
+	// behalf, refreshing (and re-storing) it when expired. Requires OAUTH_TOKEN_ENCRYPTION_KEY.
+	OAuthToken(ctx context.Context, userID string, provider OAuthProvider) (*oauth2.Token, error)
 }
 
 // service implements the Service interface.
 type service struct {
-	repo         Repository
-	logger       *slog.Logger
-	config       *config.Config
-	sessions     session.Provider
-	notification notification.Service
+	repo          Repository
+	logger        *slog.Logger
+	config        *config.Config
+	sessions      session.Provider
+	notification  notification.Service
+	events        siem.Publisher
+	breaches      BreachChecker // nil disables breached-password checks
+	hasher        PasswordHasher
+	dummyHash     func() string // hash compared against when there is no stored one (see checkPasswordHash)
+	profileReads  *coalesce.Group[*User]
+	clock         clock.Clock
+	oidc          *oidcClient // nil unless OIDC_ISSUER_URL is set
+	appleKeys     *oidcClient // Apple's signing keys, for verifying its id_tokens
+	microsoftKeys *oidcClient // the Microsoft tenant's signing keys, for verifying its id_tokens
+	jobs          jobs.Queue  // nil disables background work such as onboarding emails
+	onboarding    []onboardingStep
+	tokenBox      *secretbox.Box       // nil disables storing OAuth provider tokens
+	attempts      AttemptRecorder      // nil disables login/OTP failure-ratio tracking
+	ipReputation  *ipreputation.Policy // nil disables IP reputation checks
+	captcha       *captcha.Policy      // nil disables CAPTCHA checks
+	templates     *templates.Engine    // nil disables template downloads and uploads
+	idempotency   idempotency.Store    // nil disables replaying registration retries
+	runbook       runbook.Func         // nil reports no components
+	// tokenRefreshes collapses concurrent refreshes of one account's provider token.
+	tokenRefreshes *coalesce.Group[*oauth2.Token]
 	// cache redis.Client // Example of adding a cache dependency
 }
 
@@ -52,15 +193,90 @@ type Config struct {
 	Config       *config.Config
 	Sessions     session.Provider
 	Notification notification.Service
+	// SecurityEvents receives auth/audit events for SIEM streaming (optional).
+	SecurityEvents siem.Publisher
+	// BreachedPasswords rejects passwords found in known breaches (optional).
+	BreachedPasswords BreachChecker
+	// PasswordHasher hashes and verifies passwords (default: bcrypt at the default cost).
+	PasswordHasher PasswordHasher
+	// Clock drives every expiry, cooldown, and grace-period check (default: wall clock).
+	Clock clock.Clock
+	// Jobs schedules background work (onboarding emails, broadcasts); its handlers are registered in
+	// JobHandlers. Both are optional.
+	Jobs        jobs.Queue
+	JobHandlers *jobs.Registry
+	// AuthAttempts observes login and OTP outcomes for anomaly alerting (optional).
+	AuthAttempts AttemptRecorder
+	// IPReputation blocks or steps up registrations and logins from flagged IPs (optional).
+	IPReputation *ipreputation.Policy
+	// Captcha requires a solved CAPTCHA on registration, login and password reset (optional).
+	Captcha *captcha.Policy
+	// Templates lets admins download and upload notification templates (optional).
+	Templates *templates.Engine
+	// Idempotency records registrations so client retries are replayed (optional).
+	Idempotency idempotency.Store
+	// Runbook collects the status of background subsystems for the admin runbook (optional).
+	Runbook runbook.Func
 }
 
 // NewService creates a new user service with the given dependencies.
 func NewService(cfg *Config) Service {
-	return &service{
-		repo:         cfg.Repo,
-		logger:       cfg.Logger,
-		config:       cfg.Config,
-		sessions:     cfg.Sessions,
-		notification: cfg.Notification,
+	events := cfg.SecurityEvents
+	if events == nil {
+		events = siem.Nop{}
+	}
+	clk := clock.OrReal(cfg.Clock)
+	hasher := cfg.PasswordHasher
+	if hasher == nil {
+		hasher, _ = passhash.New(passhash.Config{Algorithm: passhash.Bcrypt})
+	}
+	var oidc *oidcClient
+	if issuer := cfg.Config.OIDC.IssuerURL; issuer != "" {
+		oidc = newOIDCClient(issuer, clk)
+	}
+	onboarding, err := parseOnboardingSequence(cfg.Config.Onboarding.Sequence)
+	if err != nil {
+		cfg.Logger.Error("invalid ONBOARDING_SEQUENCE; onboarding emails disabled", "error", err)
+		onboarding = nil
+	}
+	var tokenBox *secretbox.Box
+	if key := cfg.Config.OAuthTokens.EncryptionKey; key != "" {
+		tokenBox, err = secretbox.New(key, cfg.Config.OAuthTokens.PreviousEncryptionKeys...)
+		if err != nil {
+			cfg.Logger.Error("invalid OAUTH_TOKEN_ENCRYPTION_KEY; provider tokens will not be stored", "error", err)
+			tokenBox = nil
+		}
+	}
+	s := &service{
+		repo:          cfg.Repo,
+		logger:        cfg.Logger,
+		config:        cfg.Config,
+		sessions:      cfg.Sessions,
+		notification:  cfg.Notification,
+		events:        events,
+		breaches:      cfg.BreachedPasswords,
+		hasher:        hasher,
+		dummyHash:     sync.OnceValue(newDummyHash(hasher)),
+		profileReads:  coalesce.NewGroup[*User]("user_profile"),
+		clock:         clk,
+		oidc:          oidc,
+		appleKeys:     newOIDCClient(appleIssuer, clk),
+		microsoftKeys: newMicrosoftKeys(microsoftTenant(cfg.Config), clk),
+		jobs:          cfg.Jobs,
+		onboarding:    onboarding,
+		tokenBox:      tokenBox,
+		attempts:      cfg.AuthAttempts,
+		ipReputation:  cfg.IPReputation,
+		captcha:       cfg.Captcha,
+		templates:     cfg.Templates,
+		idempotency:   cfg.Idempotency,
+		runbook:       cfg.Runbook,
+
+		tokenRefreshes: coalesce.NewGroup[*oauth2.Token]("oauth_token_refresh"),
+	}
+	if cfg.JobHandlers != nil {
+		jobs.Handle(cfg.JobHandlers, jobOnboardingEmail, s.runOnboardingStep)
+		jobs.Handle(cfg.JobHandlers, jobBroadcastBatch, s.runBroadcastBatch)
 	}
+	return s
 }
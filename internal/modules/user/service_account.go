@@ -0,0 +1,133 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/notification"
+	"github.com/delordemm1/go-api-simple-starter/internal/notification/templates"
+)
+
+// deletionGrace is how long a soft-deleted account remains restorable.
+func (s *service) deletionGrace() time.Duration {
+	days := s.config.Accounts.DeletionGraceDays
+	if days <= 0 {
+		days = 30
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// isRestorable reports whether u is soft-deleted and still within the deletion grace period.
+func (s *service) isRestorable(u *User) bool {
+	return u.DeletedAt != nil && s.clock.Now().Sub(*u.DeletedAt) < s.deletionGrace()
+}
+
+// DeleteAccount soft-deletes the user and revokes all of their sessions.
+// The account can be restored via RequestAccountRestore until the grace period elapses.
+func (s *service) DeleteAccount(ctx context.Context, userID string) error {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrNotFound.WithCause(err)
+		}
+		s.logger.Error("delete account: find user failed", "error", err)
+		return ErrInternal.WithCause(err)
+	}
+	if user.DeletedAt != nil {
+		return nil
+	}
+
+	now := s.clock.Now()
+	user.DeletedAt = &now
+	if err := s.repo.Update(ctx, user); err != nil {
+		s.logger.Error("delete account: update user failed", "error", err, "user_id", user.ID)
+		return ErrInternal.WithCause(err)
+	}
+
+	if err := s.revokeAllSessions(ctx, user.ID, "", "account_deleted"); err != nil {
+		s.logger.Error("delete account: revoke sessions failed", "error", err, "user_id", user.ID)
+		return ErrInternal.WithCause(err)
+	}
+
+	s.recordActivity(ctx, user.ID, ActivityAccountDeleted, nil)
+	s.logger.Info("account soft-deleted", "user_id", user.ID)
+	return nil
+}
+
+// RequestAccountRestore emails a restore code to a soft-deleted account that is still within
+// the grace period. Unknown, active, or expired accounts are silently ignored to avoid enumeration.
+func (s *service) RequestAccountRestore(ctx context.Context, email string) error {
+	user, err := s.repo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		s.logger.Error("request restore: find user failed", "error", err)
+		return ErrInternal.WithCause(err)
+	}
+	if !s.isRestorable(user) {
+		return nil
+	}
+
+	code, err := s.createOrRefreshVerificationCode(ctx, user, user.Email, VerificationPurposeAccountRestore, VerificationChannelEmail)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		ctx := contextx.Detach(ctx) // the request may finish before the send
+		data := templates.AccountRestoreCodeData{
+			FirstName:        user.FirstName,
+			Code:             code,
+			ExpiresInMinutes: s.otpPolicy(VerificationPurposeAccountRestore).TTLMinutes,
+			ExpiresAt:        s.otpExpiresAt(VerificationPurposeAccountRestore),
+			Locale:           recipientLocale(user),
+			SupportEmail:     s.config.SMTP.From,
+		}
+		if err := notification.SendTemplate(ctx, s.notification, templates.AccountRestoreCode, user.Email, []notification.Channel{notification.ChannelEmail}, notification.PriorityHigh, data); err != nil {
+			s.logger.Error("failed to send account restore code", "error", err, "user_id", user.ID)
+		}
+	}()
+	return nil
+}
+
+// ConfirmAccountRestore validates the restore code, clears deleted_at, and signs the user in.
+func (s *service) ConfirmAccountRestore(ctx context.Context, email, code string) (string, error) {
+	user, err := s.repo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return "", ErrInvalidOTP
+		}
+		s.logger.Error("confirm restore: find user failed", "error", err)
+		return "", ErrInternal.WithCause(err)
+	}
+	if !s.isRestorable(user) {
+		return "", ErrInvalidOTP
+	}
+
+	if err := s.checkVerificationCode(ctx, user.ID, VerificationPurposeAccountRestore, code); err != nil {
+		return "", err
+	}
+
+	user.DeletedAt = nil
+	if err := s.repo.Update(ctx, user); err != nil {
+		s.logger.Error("confirm restore: update user failed", "error", err, "user_id", user.ID)
+		return "", ErrInternal.WithCause(err)
+	}
+	s.recordActivity(ctx, user.ID, ActivityAccountRestored, nil)
+	if user.SuspendedAt != nil {
+		// Restored, but still suspended: no session until an admin lifts the suspension.
+		return "", ErrAccountSuspended
+	}
+
+	sessionID, err := s.sessions.CreateAuthSession(ctx, user.ID, sessionMetadata(ctx, "account_restore"))
+	if err != nil {
+		s.logger.Error("confirm restore: create session failed", "error", err, "user_id", user.ID)
+		return "", sessionIssueError(err)
+	}
+
+	s.logger.Info("account restored", "user_id", user.ID)
+	return sessionID, nil
+}
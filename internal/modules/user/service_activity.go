@@ -0,0 +1,105 @@
+package user
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/authstats"
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/siem"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultActivityPageSize = 20
+	maxActivityPageSize     = 100
+)
+
+// ListActivity returns a page of the user's security activity, newest first, and an opaque
+// cursor for the next page ("" when there are no more events).
+func (s *service) ListActivity(ctx context.Context, userID string, cursor string, limit int) ([]*ActivityEvent, string, error) {
+	if limit <= 0 {
+		limit = defaultActivityPageSize
+	}
+	if limit > maxActivityPageSize {
+		limit = maxActivityPageSize
+	}
+
+	beforeID := ""
+	if cursor != "" {
+		id, err := decodeActivityCursor(cursor)
+		if err != nil {
+			return nil, "", ErrInvalidCursor
+		}
+		beforeID = id
+	}
+
+	// Fetch one extra row to know whether another page exists.
+	events, err := s.repo.ListActivityEvents(ctx, userID, beforeID, limit+1)
+	if err != nil {
+		s.logger.Error("failed to list activity events", "error", err, "user_id", userID)
+		return nil, "", ErrInternal.WithCause(err)
+	}
+
+	next := ""
+	if len(events) > limit {
+		events = events[:limit]
+		next = encodeActivityCursor(events[len(events)-1].ID)
+	}
+	return events, next, nil
+}
+
+// recordActivity appends an event to the user's timeline using the client info in ctx.
+// Failures are logged and never fail the calling flow.
+func (s *service) recordActivity(ctx context.Context, userID string, typ ActivityType, metadata map[string]any) {
+	e := &ActivityEvent{
+		UserID:    userID,
+		Type:      typ,
+		IPAddress: contextx.ClientIP(ctx),
+		UserAgent: contextx.UserAgent(ctx),
+		Metadata:  metadata,
+		CreatedAt: s.clock.Now(),
+	}
+	if err := s.repo.CreateActivityEvent(ctx, e); err != nil {
+		s.logger.Warn("failed to record activity event", "error", err, "user_id", userID, "type", typ)
+	}
+
+	actorID, _ := metadata["actorId"].(string)
+	s.events.Publish(ctx, siem.Event{Type: string(typ), UserID: userID, ActorID: actorID, Metadata: metadata})
+}
+
+// recordLogin records a login, preceded by a new_device event (and a sign-in alert email,
+// except on the account's first login) when the user agent and IP address pair has not been
+// seen on a previous login for this user.
+func (s *service) recordLogin(ctx context.Context, userID string, authMethod string) {
+	s.recordAttempt(authstats.KindLogin, true)
+	ua, ip := contextx.UserAgent(ctx), contextx.ClientIP(ctx)
+	if ua != "" || ip != "" {
+		known, anyLogin, err := s.repo.KnownLoginDevice(ctx, userID, ua, ip)
+		if err != nil {
+			s.logger.Warn("failed to check known devices", "error", err, "user_id", userID)
+		} else if !known {
+			s.recordActivity(ctx, userID, ActivityNewDevice, map[string]any{"authMethod": authMethod})
+			if anyLogin {
+				s.notifyNewSignIn(ctx, userID, authMethod)
+			}
+		}
+	}
+	s.recordActivity(ctx, userID, ActivityLogin, map[string]any{"authMethod": authMethod})
+}
+
+func encodeActivityCursor(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+func decodeActivityCursor(cursor string) (string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", err
+	}
+	id, err := uuid.Parse(string(b))
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
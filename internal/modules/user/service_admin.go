@@ -0,0 +1,170 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/notification"
+	"github.com/delordemm1/go-api-simple-starter/internal/notification/templates"
+)
+
+// IsAdmin reports whether the user has the admin role.
+func (s *service) IsAdmin(ctx context.Context, userID string) (bool, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		s.logger.Error("is admin: find user failed", "error", err, "user_id", userID)
+		return false, ErrInternal.WithCause(err)
+	}
+	return user.Role == RoleAdmin && user.DeletedAt == nil, nil
+}
+
+// Sources of an admin promotion, recorded on the ActivityAdminPromoted event.
+const (
+	AdminPromotionConfig = "config"
+	AdminPromotionCLI    = "cli"
+)
+
+// PromoteAdmin grants the admin role to the user with email. It exists to bootstrap the first
+// administrators (ADMIN_EMAILS, `api admin promote`) and is not exposed over HTTP. Only live
+// users with a verified email are promoted, so nobody can pre-register a configured address
+// and inherit its privileges. Promoting an admin again is a no-op.
+func (s *service) PromoteAdmin(ctx context.Context, email, source string) (*User, error) {
+	user, err := s.repo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNotFound.WithCause(err)
+		}
+		s.logger.Error("promote admin: find user failed", "error", err)
+		return nil, ErrInternal.WithCause(err)
+	}
+	if user.DeletedAt != nil || user.AnonymizedAt != nil {
+		return nil, ErrNotFound
+	}
+	if !user.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+	if user.Role == RoleAdmin {
+		return user, nil
+	}
+
+	if err := s.repo.SetRole(ctx, user.ID, RoleAdmin); err != nil {
+		s.logger.Error("promote admin: set role failed", "error", err, "user_id", user.ID)
+		return nil, ErrInternal.WithCause(err)
+	}
+	user.Role = RoleAdmin
+	// Sessions opened before the promotion would otherwise carry admin rights under tokens issued
+	// to a regular user; the new admin signs in again.
+	if err := s.revokeAllSessions(ctx, user.ID, "", "role_changed"); err != nil {
+		s.logger.Error("promote admin: revoke sessions failed", "error", err, "user_id", user.ID)
+	}
+
+	s.recordActivity(ctx, user.ID, ActivityAdminPromoted, map[string]any{"source": source})
+	s.logger.Warn("user promoted to admin", "user_id", user.ID, "source", source)
+	return user, nil
+}
+
+// PromoteConfiguredAdmins promotes every ADMIN_EMAILS entry. Users that are missing or
+// unverified are logged and retried on the next start; they never block startup.
+func (s *service) PromoteConfiguredAdmins(ctx context.Context) {
+	for _, email := range s.config.Admin.Emails {
+		email = strings.TrimSpace(email)
+		if email == "" {
+			continue
+		}
+		if _, err := s.PromoteAdmin(ctx, email, AdminPromotionConfig); err != nil {
+			s.logger.Warn("configured admin not promoted", "email", email, "error", err)
+		}
+	}
+}
+
+// ForcePasswordReset invalidates the user's password: password login is refused with
+// ErrPasswordResetRequired until the password is reset, and all sessions are revoked.
+func (s *service) ForcePasswordReset(ctx context.Context, actorID, userID, reason string) error {
+	user, err := s.findForAdminAction(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	user.PasswordResetRequired = true
+	if err := s.repo.Update(ctx, user); err != nil {
+		s.logger.Error("force password reset: update user failed", "error", err, "user_id", userID)
+		return ErrInternal.WithCause(err)
+	}
+	if err := s.revokeAllSessions(ctx, user.ID, actorID, "admin_forced_password_reset"); err != nil {
+		s.logger.Error("force password reset: revoke sessions failed", "error", err, "user_id", userID)
+		return ErrInternal.WithCause(err)
+	}
+
+	s.recordActivity(ctx, user.ID, ActivityAdminForcedPasswordReset, map[string]any{"actorId": actorID, "reason": reason})
+	s.logger.Warn("admin forced password reset", "actor_id", actorID, "user_id", user.ID, "reason", reason)
+	return nil
+}
+
+// ForceReverification marks the user's email as unverified, revokes all sessions,
+// and sends a fresh verification code. Login is blocked until the email is re-verified.
+func (s *service) ForceReverification(ctx context.Context, actorID, userID, reason string) error {
+	user, err := s.findForAdminAction(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	user.EmailVerified = false
+	if err := s.repo.Update(ctx, user); err != nil {
+		s.logger.Error("force reverification: update user failed", "error", err, "user_id", userID)
+		return ErrInternal.WithCause(err)
+	}
+	if err := s.revokeAllSessions(ctx, user.ID, actorID, "admin_forced_reverification"); err != nil {
+		s.logger.Error("force reverification: revoke sessions failed", "error", err, "user_id", userID)
+		return ErrInternal.WithCause(err)
+	}
+
+	code, err := s.createOrRefreshVerificationCode(ctx, user, user.Email, VerificationPurposeEmailVerify, VerificationChannelEmail)
+	switch {
+	case errors.Is(err, ErrResendTooSoon):
+		// A code was just sent; the user can still use it.
+	case err != nil:
+		return err
+	default:
+		go func() {
+			ctx := contextx.Detach(ctx) // the request may finish before the send
+			data := templates.VerifyEmailData{
+				FirstName:                  user.FirstName,
+				Code:                       code,
+				ExpiresInMinutes:           s.otpPolicy(VerificationPurposeEmailVerify).TTLMinutes,
+				ExpiresAt:                  s.otpExpiresAt(VerificationPurposeEmailVerify),
+				Locale:                     recipientLocale(user),
+				VerifyLink:                 s.emailVerifyLink(ctx, user),
+				VerifyLinkExpiresInMinutes: s.verifyLinkTTLMinutes(),
+				SupportEmail:               s.config.SMTP.From,
+			}
+			if err := notification.SendTemplate(ctx, s.notification, templates.VerifyEmail, user.Email, []notification.Channel{notification.ChannelEmail}, notification.PriorityHigh, data); err != nil {
+				s.logger.Error("failed to send verify email", "error", err, "user_id", user.ID)
+			}
+		}()
+	}
+
+	s.recordActivity(ctx, user.ID, ActivityAdminForcedReverification, map[string]any{"actorId": actorID, "reason": reason})
+	s.logger.Warn("admin forced email re-verification", "actor_id", actorID, "user_id", user.ID, "reason", reason)
+	return nil
+}
+
+// findForAdminAction loads a live (not deleted) user targeted by an admin action.
+func (s *service) findForAdminAction(ctx context.Context, userID string) (*User, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNotFound.WithCause(err)
+		}
+		s.logger.Error("admin action: find user failed", "error", err, "user_id", userID)
+		return nil, ErrInternal.WithCause(err)
+	}
+	if user.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	return user, nil
+}
@@ -0,0 +1,194 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/jobs"
+	"github.com/delordemm1/go-api-simple-starter/internal/notification"
+	"github.com/delordemm1/go-api-simple-starter/internal/notification/templates"
+	"github.com/delordemm1/go-api-simple-starter/internal/validation"
+	"github.com/google/uuid"
+)
+
+const (
+	// jobBroadcastBatch emails one batch of a broadcast's audience and enqueues the next.
+	jobBroadcastBatch = "user.broadcast_batch"
+	// broadcastBatchSize is the number of recipients emailed per job.
+	broadcastBatchSize = 100
+	// defaultBroadcastInactiveDays is the inactivity threshold of BroadcastAudienceInactive.
+	defaultBroadcastInactiveDays = 30
+)
+
+// broadcastBatchJob is the payload of a jobBroadcastBatch job. Progress lives on the broadcast
+// row, so a retried job resumes after the last recorded batch.
+type broadcastBatchJob struct {
+	BroadcastID string `json:"broadcastId"`
+}
+
+// BroadcastInput describes an announcement and its audience.
+type BroadcastInput struct {
+	Audience     BroadcastAudience
+	InactiveDays *int // BroadcastAudienceInactive only; default 30
+	Subject      string
+	Message      string
+	Reason       string
+}
+
+// StartBroadcast records a broadcast and emails its audience in the background, one
+// broadcastBatchSize batch per job. Poll GetBroadcast for progress; CancelBroadcast stops it
+// before the next batch.
+func (s *service) StartBroadcast(ctx context.Context, actorID string, input BroadcastInput) (*Broadcast, error) {
+	switch input.Audience {
+	case BroadcastAudienceVerified:
+		input.InactiveDays = nil
+	case BroadcastAudienceInactive:
+		if input.InactiveDays == nil {
+			days := defaultBroadcastInactiveDays
+			input.InactiveDays = &days
+		}
+	default:
+		return nil, validation.NewFieldError(validation.FieldErrors{"audience": {"must be verified or inactive"}})
+	}
+	if s.jobs == nil {
+		return nil, ErrInternal.WithCause(errors.New("broadcast: no job queue configured"))
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		s.logger.Error("failed to generate broadcast ID", "error", err)
+		return nil, ErrInternal.WithCause(err)
+	}
+	b := &Broadcast{
+		ID:           id.String(),
+		ActorID:      actorID,
+		Audience:     input.Audience,
+		InactiveDays: input.InactiveDays,
+		Subject:      input.Subject,
+		Message:      input.Message,
+		Reason:       input.Reason,
+		Status:       BroadcastRunning,
+	}
+	if err := s.repo.CreateBroadcast(ctx, b); err != nil {
+		s.logger.Error("failed to create broadcast", "error", err)
+		return nil, ErrInternal.WithCause(err)
+	}
+	if err := jobs.Enqueue(ctx, s.jobs, jobBroadcastBatch, broadcastBatchJob{BroadcastID: b.ID}, time.Time{}); err != nil {
+		s.logger.Error("failed to enqueue broadcast", "error", err, "broadcast_id", b.ID)
+		s.finishBroadcast(ctx, b.ID, BroadcastFailed, err)
+		return nil, ErrInternal.WithCause(err)
+	}
+
+	s.logger.Warn("admin started broadcast", "actor_id", actorID, "broadcast_id", b.ID, "audience", b.Audience, "reason", input.Reason)
+	return b, nil
+}
+
+// GetBroadcast returns a broadcast and its progress.
+func (s *service) GetBroadcast(ctx context.Context, id string) (*Broadcast, error) {
+	b, err := s.repo.FindBroadcastByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNotFound.WithDetail("broadcast not found")
+		}
+		s.logger.Error("failed to get broadcast", "error", err, "id", id)
+		return nil, ErrInternal.WithCause(err)
+	}
+	return b, nil
+}
+
+// CancelBroadcast stops a running broadcast; the batch in progress, if any, still completes.
+func (s *service) CancelBroadcast(ctx context.Context, actorID, id string) (*Broadcast, error) {
+	ok, err := s.repo.FinishBroadcast(ctx, id, BroadcastCancelled, nil)
+	if err != nil {
+		s.logger.Error("failed to cancel broadcast", "error", err, "id", id)
+		return nil, ErrInternal.WithCause(err)
+	}
+	b, err := s.GetBroadcast(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrConflict.WithDetail("broadcast is not running").WithContext(map[string]any{"status": b.Status})
+	}
+	s.logger.Warn("admin cancelled broadcast", "actor_id", actorID, "broadcast_id", id, "sent", b.Sent)
+	return b, nil
+}
+
+// runBroadcastBatch is the jobBroadcastBatch handler: it emails the next batch of recipients,
+// records progress, and enqueues the following batch, or marks the broadcast completed.
+func (s *service) runBroadcastBatch(ctx context.Context, job broadcastBatchJob) error {
+	b, err := s.repo.FindBroadcastByID(ctx, job.BroadcastID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if b.Status != BroadcastRunning {
+		return nil // cancelled (or already finished by an earlier run)
+	}
+
+	// The audience is fixed when the broadcast starts, so later batches see the same threshold.
+	var inactiveSince *time.Time
+	if b.Audience == BroadcastAudienceInactive && b.InactiveDays != nil {
+		t := b.CreatedAt.AddDate(0, 0, -*b.InactiveDays)
+		inactiveSince = &t
+	}
+	after := ""
+	if b.Cursor != nil {
+		after = *b.Cursor
+	}
+	users, err := s.repo.ListBroadcastRecipients(ctx, inactiveSince, after, broadcastBatchSize)
+	if err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		s.finishBroadcast(ctx, b.ID, BroadcastCompleted, nil)
+		s.logger.Info("broadcast completed", "broadcast_id", b.ID, "sent", b.Sent, "failed", b.Failed, "batches", b.Batches)
+		return nil
+	}
+
+	var sent, failed int64
+	for _, u := range users {
+		data := templates.AnnouncementData{
+			FirstName:    u.FirstName,
+			Subject:      b.Subject,
+			Message:      b.Message,
+			Locale:       recipientLocale(u),
+			SupportEmail: s.config.SMTP.From,
+		}
+		if err := notification.SendTemplate(ctx, s.notification, templates.Announcement, u.Email, []notification.Channel{notification.ChannelEmail}, notification.PriorityLow, data); err != nil {
+			failed++
+			s.logger.Warn("broadcast: send failed", "error", err, "broadcast_id", b.ID, "user_id", u.ID)
+			continue
+		}
+		sent++
+	}
+	if err := s.repo.RecordBroadcastBatch(ctx, b.ID, sent, failed, users[len(users)-1].ID); err != nil {
+		// Retrying would email this batch again; fail the broadcast instead.
+		s.logger.Error("broadcast: record progress failed", "error", err, "broadcast_id", b.ID)
+		s.finishBroadcast(ctx, b.ID, BroadcastFailed, err)
+		return nil
+	}
+
+	if len(users) < broadcastBatchSize {
+		s.finishBroadcast(ctx, b.ID, BroadcastCompleted, nil)
+		s.logger.Info("broadcast completed", "broadcast_id", b.ID, "sent", b.Sent+sent, "failed", b.Failed+failed, "batches", b.Batches+1)
+		return nil
+	}
+	// A failed enqueue fails this job; its retry resumes after the batch just recorded.
+	return jobs.Enqueue(ctx, s.jobs, jobBroadcastBatch, job, time.Time{})
+}
+
+// finishBroadcast records a broadcast's final status, logging (not returning) failures.
+func (s *service) finishBroadcast(ctx context.Context, id string, status BroadcastStatus, cause error) {
+	var errMsg *string
+	if cause != nil {
+		msg := cause.Error()
+		errMsg = &msg
+	}
+	if _, err := s.repo.FinishBroadcast(ctx, id, status, errMsg); err != nil {
+		s.logger.Error("failed to record broadcast outcome", "error", err, "broadcast_id", id, "status", status)
+	}
+}
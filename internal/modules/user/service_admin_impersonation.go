@@ -0,0 +1,48 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/siem"
+)
+
+// securityEventImpersonationStarted is streamed when an admin opens an impersonation session.
+const securityEventImpersonationStarted = "impersonation_started"
+
+// Impersonate opens a session as userID on behalf of admin actorID, e.g. to reproduce what a
+// user sees. The session is tagged with the admin (responses carry the X-Impersonated-By banner
+// header and problems name them), never counts as recently authenticated, and ends after
+// ADMIN_IMPERSONATION_MINUTES. Admins cannot be impersonated, so it never escalates privileges.
+func (s *service) Impersonate(ctx context.Context, actorID, userID, reason string) (string, time.Time, error) {
+	minutes := s.config.Admin.ImpersonationMinutes
+	if minutes <= 0 {
+		return "", time.Time{}, ErrForbidden.WithDetail("impersonation is disabled")
+	}
+	user, err := s.findForAdminAction(ctx, userID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if user.ID == actorID || user.Role == RoleAdmin {
+		return "", time.Time{}, ErrForbidden.WithDetail("admins cannot be impersonated")
+	}
+
+	meta := sessionMetadata(ctx, "impersonation")
+	meta.ImpersonatorID = actorID
+	meta.ExpiresAt = s.clock.Now().Add(time.Duration(minutes) * time.Minute)
+	sessionID, err := s.sessions.CreateAuthSession(ctx, user.ID, meta)
+	if err != nil {
+		s.logger.Error("impersonate: create session failed", "error", err, "user_id", user.ID)
+		return "", time.Time{}, sessionIssueError(err)
+	}
+
+	s.recordActivity(ctx, user.ID, ActivityAdminImpersonated, map[string]any{"actorId": actorID, "reason": reason})
+	s.events.Publish(ctx, siem.Event{
+		Type:     securityEventImpersonationStarted,
+		UserID:   user.ID,
+		ActorID:  actorID,
+		Metadata: map[string]any{"reason": reason, "expiresAt": meta.ExpiresAt},
+	})
+	s.logger.Warn("admin started impersonation", "actor_id", actorID, "user_id", user.ID, "reason", reason)
+	return sessionID, meta.ExpiresAt, nil
+}
@@ -0,0 +1,170 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/mail"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/notification"
+	"github.com/delordemm1/go-api-simple-starter/internal/notification/templates"
+	"github.com/delordemm1/go-api-simple-starter/internal/validation"
+)
+
+const (
+	defaultDeadLetterPageSize = 20
+	maxDeadLetterPageSize     = 100
+)
+
+// ListDeadLetters returns a page of dead-lettered notifications, newest first, and an opaque
+// cursor for the next page. An empty status lists all.
+func (s *service) ListDeadLetters(ctx context.Context, status string, cursor string, limit int) ([]*notification.DeadLetter, string, error) {
+	if limit <= 0 {
+		limit = defaultDeadLetterPageSize
+	}
+	if limit > maxDeadLetterPageSize {
+		limit = maxDeadLetterPageSize
+	}
+
+	beforeID := ""
+	if cursor != "" {
+		id, err := decodeActivityCursor(cursor)
+		if err != nil {
+			return nil, "", ErrInvalidCursor
+		}
+		beforeID = id
+	}
+
+	items, err := s.notification.ListDeadLetters(ctx, notification.DeadLetterStatus(status), beforeID, limit+1)
+	if err != nil {
+		s.logger.Error("failed to list dead letters", "error", err)
+		return nil, "", ErrInternal.WithCause(err)
+	}
+
+	next := ""
+	if len(items) > limit {
+		items = items[:limit]
+		next = encodeActivityCursor(items[len(items)-1].ID)
+	}
+	return items, next, nil
+}
+
+// GetDeadLetter returns a single dead letter, including its rendered content.
+func (s *service) GetDeadLetter(ctx context.Context, id string) (*notification.DeadLetter, error) {
+	d, err := s.notification.GetDeadLetter(ctx, id)
+	if err != nil {
+		return nil, s.deadLetterError("get dead letter", id, err)
+	}
+	return d, nil
+}
+
+// UpdateDeadLetterRecipient corrects the recipient of a pending dead letter before requeueing.
+func (s *service) UpdateDeadLetterRecipient(ctx context.Context, actorID, id, recipient string) (*notification.DeadLetter, error) {
+	d, err := s.notification.GetDeadLetter(ctx, id)
+	if err != nil {
+		return nil, s.deadLetterError("update dead letter", id, err)
+	}
+	if d.Channel == notification.ChannelEmail {
+		if _, err := mail.ParseAddress(recipient); err != nil {
+			return nil, ErrInvalidRecipient.WithDetail("recipient must be a valid email address")
+		}
+	}
+
+	d, err = s.notification.UpdateDeadLetterRecipient(ctx, id, recipient)
+	if err != nil {
+		return nil, s.deadLetterError("update dead letter", id, err)
+	}
+	s.logger.Warn("admin changed dead letter recipient", "actor_id", actorID, "id", id)
+	return d, nil
+}
+
+// RequeueDeadLetter sends a pending dead letter again. Delivery is asynchronous.
+func (s *service) RequeueDeadLetter(ctx context.Context, actorID, id string) error {
+	if err := s.notification.RequeueDeadLetter(ctx, id); err != nil {
+		return s.deadLetterError("requeue dead letter", id, err)
+	}
+	s.logger.Info("admin requeued dead letter", "actor_id", actorID, "id", id)
+	return nil
+}
+
+// SendTemplateTest renders templateID with data (or its sample data when data is empty) and
+// sends it to recipient over channel through the real pipeline, flagged as a test, so operators
+// can check provider configuration per environment. Delivery is asynchronous.
+func (s *service) SendTemplateTest(ctx context.Context, actorID, templateID, recipient string, channel notification.Channel, data json.RawMessage) error {
+	if _, ok := templates.Sample(templateID); !ok {
+		return ErrNotFound.WithDetail("template not found")
+	}
+	if channel == notification.ChannelEmail {
+		if _, err := mail.ParseAddress(recipient); err != nil {
+			return ErrInvalidRecipient.WithDetail("recipient must be a valid email address")
+		}
+	}
+
+	payload, err := templates.DecodeData(templateID, data)
+	if err != nil {
+		return validation.NewFieldError(validation.FieldErrors{"data": {err.Error()}})
+	}
+	if err := s.notification.SendTemplateTest(ctx, recipient, []notification.Channel{channel}, templateID, payload); err != nil {
+		// Rendering is the only synchronous step; a failure means the data does not fit the template.
+		s.logger.Warn("template test send failed to render", "error", err, "template", templateID)
+		return validation.NewFieldError(validation.FieldErrors{"data": {err.Error()}})
+	}
+
+	s.logger.Info("admin sent template test", "actor_id", actorID, "template", templateID, "channel", channel)
+	return nil
+}
+
+// GetTemplate returns the source of templateID as currently served, and its version.
+func (s *service) GetTemplate(ctx context.Context, templateID string) (string, string, error) {
+	if s.templates == nil {
+		return "", "", ErrNotFound.WithDetail("template not found")
+	}
+	content, version, err := s.templates.Content(ctx, templateID)
+	if err != nil {
+		return "", "", s.templateError("get template", templateID, err)
+	}
+	return string(content), version, nil
+}
+
+// UploadTemplate replaces the source of templateID, so email copy can change without a deploy.
+// The content is validated against the template's data type first. Other instances pick the new
+// version up within TEMPLATES_REFRESH_SECONDS.
+func (s *service) UploadTemplate(ctx context.Context, actorID, templateID, content string) (string, error) {
+	if s.templates == nil {
+		return "", ErrTemplatesReadOnly
+	}
+	version, err := s.templates.Upload(ctx, templateID, []byte(content))
+	if err != nil {
+		return "", s.templateError("upload template", templateID, err)
+	}
+	s.logger.Warn("admin uploaded notification template", "actor_id", actorID, "template", templateID, "version", version)
+	return version, nil
+}
+
+// templateError maps template engine errors to domain errors.
+func (s *service) templateError(op, id string, err error) error {
+	switch {
+	case errors.Is(err, templates.ErrTemplateNotFound):
+		return ErrNotFound.WithDetail("template not found")
+	case errors.Is(err, templates.ErrReadOnly):
+		return ErrTemplatesReadOnly
+	case errors.Is(err, templates.ErrInvalidTemplate):
+		return validation.NewFieldError(validation.FieldErrors{"content": {err.Error()}})
+	default:
+		s.logger.Error(op+" failed", "error", err, "template", id)
+		return ErrInternal.WithCause(err)
+	}
+}
+
+// deadLetterError maps notification dead-letter errors to domain errors.
+func (s *service) deadLetterError(op, id string, err error) error {
+	switch {
+	case errors.Is(err, notification.ErrDeadLetterNotFound):
+		return ErrNotFound.WithDetail("dead letter not found")
+	case errors.Is(err, notification.ErrDeadLetterNotPending):
+		return ErrConflict.WithDetail("dead letter was already requeued")
+	default:
+		s.logger.Error(op+" failed", "error", err, "id", id)
+		return ErrInternal.WithCause(err)
+	}
+}
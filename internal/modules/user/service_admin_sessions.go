@@ -0,0 +1,111 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/session"
+	"github.com/delordemm1/go-api-simple-starter/internal/siem"
+	"github.com/delordemm1/go-api-simple-starter/internal/validation"
+	"github.com/google/uuid"
+)
+
+// StartSessionRevocation records a bulk revocation job and revokes the matching sessions in the
+// background, in batches, updating the job's progress after each batch. Poll GetSessionRevocation
+// for the outcome. At least one criterion is required so a typo cannot log out everyone.
+func (s *service) StartSessionRevocation(ctx context.Context, actorID string, criteria SessionRevocationCriteria, reason string) (*SessionRevocation, error) {
+	filter := session.Filter{UnverifiedUsers: criteria.UnverifiedUsers}
+	if criteria.CreatedBefore != nil {
+		filter.CreatedBefore = *criteria.CreatedBefore
+	}
+	if criteria.IPRange != nil {
+		prefix, err := netip.ParsePrefix(*criteria.IPRange)
+		if err != nil {
+			return nil, validation.NewFieldError(validation.FieldErrors{"ipRange": {"must be a CIDR range such as 203.0.113.0/24"}})
+		}
+		filter.IPRange = prefix.Masked()
+		masked := filter.IPRange.String()
+		criteria.IPRange = &masked
+	}
+	if filter.IsZero() {
+		return nil, validation.NewFieldError(validation.FieldErrors{"createdBefore": {"at least one of createdBefore, ipRange or unverifiedUsers is required"}})
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		s.logger.Error("failed to generate session revocation ID", "error", err)
+		return nil, ErrInternal.WithCause(err)
+	}
+	job := &SessionRevocation{
+		ID:                        id.String(),
+		ActorID:                   actorID,
+		SessionRevocationCriteria: criteria,
+		Reason:                    reason,
+		Status:                    SessionRevocationRunning,
+	}
+	if err := s.repo.CreateSessionRevocation(ctx, job); err != nil {
+		s.logger.Error("failed to create session revocation", "error", err)
+		return nil, ErrInternal.WithCause(err)
+	}
+
+	s.logger.Warn("admin started bulk session revocation", "actor_id", actorID, "job_id", job.ID, "reason", reason)
+	go s.runSessionRevocation(contextx.Detach(ctx), job, filter)
+	return job, nil
+}
+
+// GetSessionRevocation returns a bulk revocation job and its progress.
+func (s *service) GetSessionRevocation(ctx context.Context, id string) (*SessionRevocation, error) {
+	job, err := s.repo.FindSessionRevocationByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNotFound.WithDetail("session revocation not found")
+		}
+		s.logger.Error("failed to get session revocation", "error", err, "id", id)
+		return nil, ErrInternal.WithCause(err)
+	}
+	return job, nil
+}
+
+// runSessionRevocation performs the revocation for job and records its outcome.
+func (s *service) runSessionRevocation(ctx context.Context, job *SessionRevocation, filter session.Filter) {
+	start := time.Now()
+	batches := 0
+	progress := func(revoked int64) {
+		batches++
+		if err := s.repo.UpdateSessionRevocationProgress(ctx, job.ID, revoked, batches); err != nil {
+			s.logger.Warn("failed to record session revocation progress", "error", err, "job_id", job.ID)
+		}
+	}
+
+	revoked, err := s.sessions.DeleteMatching(ctx, filter, s.config.Sessions.GCBatchSize, progress)
+	status, outcome := SessionRevocationCompleted, siem.OutcomeSuccess
+	var errMsg *string
+	if err != nil {
+		status, outcome = SessionRevocationFailed, siem.OutcomeFailure
+		msg := err.Error()
+		errMsg = &msg
+		s.logger.Error("bulk session revocation failed", "error", err, "job_id", job.ID, "revoked", revoked)
+	} else {
+		s.logger.Warn("bulk session revocation completed", "job_id", job.ID, "revoked", revoked, "batches", batches, "duration", time.Since(start))
+	}
+	if ferr := s.repo.FinishSessionRevocation(ctx, job.ID, status, revoked, errMsg); ferr != nil {
+		s.logger.Error("failed to record session revocation outcome", "error", ferr, "job_id", job.ID)
+	}
+
+	s.events.Publish(ctx, siem.Event{
+		Type:    securityEventSessionsRevoked,
+		Outcome: outcome,
+		ActorID: job.ActorID,
+		Metadata: map[string]any{
+			"reason":          "admin_bulk_revocation",
+			"jobId":           job.ID,
+			"count":           revoked,
+			"createdBefore":   job.CreatedBefore,
+			"ipRange":         job.IPRange,
+			"unverifiedUsers": job.UnverifiedUsers,
+		},
+	})
+}
@@ -0,0 +1,97 @@
+package user
+
+import (
+	"context"
+)
+
+const (
+	defaultUserPageSize = 20
+	maxUserPageSize     = 100
+)
+
+// ListUsers returns a page of users matching filter, newest first, and an opaque cursor for
+// the next page ("" when there are no more users).
+func (s *service) ListUsers(ctx context.Context, filter UserFilter, cursor string, limit int) ([]*User, string, error) {
+	if limit <= 0 {
+		limit = defaultUserPageSize
+	}
+	if limit > maxUserPageSize {
+		limit = maxUserPageSize
+	}
+
+	beforeID := ""
+	if cursor != "" {
+		id, err := decodeActivityCursor(cursor)
+		if err != nil {
+			return nil, "", ErrInvalidCursor
+		}
+		beforeID = id
+	}
+
+	users, err := s.repo.ListUsers(ctx, filter, beforeID, limit+1)
+	if err != nil {
+		s.logger.Error("failed to list users", "error", err)
+		return nil, "", ErrInternal.WithCause(err)
+	}
+
+	next := ""
+	if len(users) > limit {
+		users = users[:limit]
+		next = encodeActivityCursor(users[len(users)-1].ID)
+	}
+	return users, next, nil
+}
+
+// SuspendUser blocks every sign-in method for the user and revokes all their sessions until
+// UnsuspendUser is called. Suspending an already suspended user only updates the reason.
+func (s *service) SuspendUser(ctx context.Context, actorID, userID, reason string) error {
+	if actorID == userID {
+		return ErrForbidden.WithDetail("admins cannot suspend their own account")
+	}
+	user, err := s.findForAdminAction(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.SuspendedAt == nil {
+		now := s.clock.Now()
+		user.SuspendedAt = &now
+	}
+	user.SuspendedReason = &reason
+	if err := s.repo.Update(ctx, user); err != nil {
+		s.logger.Error("suspend user: update user failed", "error", err, "user_id", userID)
+		return ErrInternal.WithCause(err)
+	}
+	if err := s.revokeAllSessions(ctx, user.ID, actorID, "admin_suspended"); err != nil {
+		s.logger.Error("suspend user: revoke sessions failed", "error", err, "user_id", userID)
+		return ErrInternal.WithCause(err)
+	}
+	s.profileReads.Forget(user.ID)
+
+	s.recordActivity(ctx, user.ID, ActivityAdminSuspended, map[string]any{"actorId": actorID, "reason": reason})
+	s.logger.Warn("admin suspended user", "actor_id", actorID, "user_id", user.ID, "reason", reason)
+	return nil
+}
+
+// UnsuspendUser lifts a suspension. It is a no-op for users that are not suspended.
+func (s *service) UnsuspendUser(ctx context.Context, actorID, userID, reason string) error {
+	user, err := s.findForAdminAction(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.SuspendedAt == nil {
+		return nil
+	}
+
+	user.SuspendedAt = nil
+	user.SuspendedReason = nil
+	if err := s.repo.Update(ctx, user); err != nil {
+		s.logger.Error("unsuspend user: update user failed", "error", err, "user_id", userID)
+		return ErrInternal.WithCause(err)
+	}
+	s.profileReads.Forget(user.ID)
+
+	s.recordActivity(ctx, user.ID, ActivityAdminUnsuspended, map[string]any{"actorId": actorID, "reason": reason})
+	s.logger.Warn("admin lifted user suspension", "actor_id", actorID, "user_id", user.ID, "reason", reason)
+	return nil
+}
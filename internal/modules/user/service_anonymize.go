@@ -0,0 +1,46 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// AnonymizeUser irreversibly scrubs a user's personal data (name, email, credentials, client
+// details) and removes their sessions and pending codes/tokens. The user row and its ID are
+// kept so activity events and lifecycle audit records stay attributable.
+//
+// actorID is the admin performing the action, or empty for automated cleanup. Anonymizing an
+// already anonymized user is a no-op.
+func (s *service) AnonymizeUser(ctx context.Context, actorID, userID, reason string) error {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrNotFound.WithCause(err)
+		}
+		s.logger.Error("anonymize: find user failed", "error", err, "user_id", userID)
+		return ErrInternal.WithCause(err)
+	}
+	if user.AnonymizedAt != nil {
+		return nil
+	}
+
+	if actorID != "" {
+		// Recorded first so the anonymization scrubs the event's client details with the rest.
+		s.recordActivity(ctx, user.ID, ActivityAdminAnonymized, map[string]any{"actorId": actorID, "reason": reason})
+		reason = fmt.Sprintf("admin %s: %s", actorID, reason)
+	}
+
+	err = s.audited(ctx, user.ID, LifecycleAnonymized, reason, func() error {
+		// Revoke through the provider too, in case sessions live outside Postgres.
+		if err := s.revokeAllSessions(ctx, user.ID, actorID, "anonymized"); err != nil {
+			return err
+		}
+		return s.repo.Anonymize(ctx, user.ID, s.clock.Now())
+	})
+	if err != nil {
+		s.logger.Error("anonymize user failed", "error", err, "user_id", user.ID)
+		return ErrInternal.WithCause(err)
+	}
+	return nil
+}
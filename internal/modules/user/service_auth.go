@@ -3,84 +3,47 @@ package user
 import (
 	"context"
 	"errors"
+	"time"
 
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/ipreputation"
 	"github.com/delordemm1/go-api-simple-starter/internal/notification"
 	"github.com/delordemm1/go-api-simple-starter/internal/notification/templates"
+	"github.com/delordemm1/go-api-simple-starter/internal/session"
 	"github.com/google/uuid"
 )
 
 // Register handles the business logic for creating a new user.
+// Retries are safe: a repeat of a recent registration with the same email and password gets
+// the same pending user back without another verification email, and a registration racing
+// a concurrent one for the same email is answered like a re-registration. ErrEmailExists is
+// only returned for verified (or deleted) accounts.
 func (s *service) Register(ctx context.Context, firstName, lastName, email, password string) (*User, error) {
-	// 1) Check if a user with the given email already exists.
-	existing, err := s.repo.FindByEmail(ctx, email)
-	if err == nil {
-		// User exists
-		if existing.EmailVerified {
-			return nil, ErrEmailExists
-		}
-		// Re-register allowed for unverified: update names only, keep password as-is
-		changed := false
-		if existing.FirstName != firstName {
-			existing.FirstName = firstName
-			changed = true
-		}
-		if existing.LastName != lastName {
-			existing.LastName = lastName
-			changed = true
-		}
-		if changed {
-			if uerr := s.repo.Update(ctx, existing); uerr != nil {
-				s.logger.Error("failed to update unverified user names", "error", uerr, "user_id", existing.ID)
-				return nil, ErrInternal.WithCause(uerr)
-			}
-		}
-
-		// Generate or refresh 6-digit verification code (respect cooldown)
-		code, cerr := s.createOrRefreshVerificationCode(ctx, existing, existing.Email, VerificationPurposeEmailVerify, VerificationChannelEmail)
-		if cerr != nil {
-			if errors.Is(cerr, ErrResendTooSoon) {
-				s.logger.Info("verification code resend cooldown active", "email", email)
-			} else {
-				s.logger.Error("failed to create/refresh verification code", "error", cerr, "user_id", existing.ID)
-			}
-		} else if code != "" {
-			// Fire-and-forget notification
-			go func(u *User, c string) {
-				data := templates.VerifyEmailData{
-					FirstName:    u.FirstName,
-					Code:         c,
-					SupportEmail: s.config.SMTP.From,
-				}
-				if err := notification.SendTemplate(ctx, s.notification, templates.VerifyEmail, u.Email, []notification.Channel{notification.ChannelEmail}, notification.PriorityHigh, data); err != nil {
-					s.logger.Error("failed to send verify email", "error", err, "user_id", u.ID)
-				}
-			}(existing, code)
-		}
-
-		s.logger.Info("user re-registered; awaiting email verification", "user_id", existing.ID)
-		return existing, nil
-	}
-	// We expect "not found"; if it's any other error, map to internal.
-	if !errors.Is(err, ErrNotFound) {
-		s.logger.Error("failed to check existing user by email", "error", err)
-		return nil, ErrInternal.WithCause(err)
+	// 0) A client retry of a registration that already succeeded is answered from the record.
+	if user := s.replayRegistration(ctx, email, password); user != nil {
+		return user, nil
 	}
 
-	// 2) Hash the password for security.
-	hashedPassword, err := hashPassword(password)
+	// 1) Reject passwords known from data breaches, then hash it for storage.
+	// There is no lookup of the email first: the insert below decides, so two concurrent
+	// registrations cannot both pass a find-then-create check.
+	if err := s.checkBreachedPassword(ctx, password); err != nil {
+		return nil, err
+	}
+	hashedPassword, err := s.hashPassword(password)
 	if err != nil {
 		s.logger.Error("failed to hash password", "error", err)
 		return nil, ErrInternal.WithCause(err)
 	}
 
-	// 3) Generate a new user ID.
+	// 2) Generate a new user ID.
 	newUserID, err := uuid.NewV7()
 	if err != nil {
 		s.logger.Error("failed to generate user ID", "error", err)
 		return nil, ErrInternal.WithCause(err)
 	}
 
-	// 4) Create the new user entity.
+	// 3) Create the new user entity.
 	newUser := &User{
 		ID:            newUserID.String(),
 		FirstName:     firstName,
@@ -89,14 +52,25 @@ func (s *service) Register(ctx context.Context, firstName, lastName, email, pass
 		PasswordHash:  hashedPassword,
 		EmailVerified: false, // Email is not verified upon registration
 	}
+	// The registration form requires accepting the terms, so the current version is recorded.
+	s.acceptCurrentTerms(newUser)
 
-	// 5) Persist the user to the database.
+	// 4) Persist the user to the database.
+	// The unique index on email is the source of truth; an email that already has an account
+	// (registered earlier or by a concurrent request) surfaces here as ErrEmailExists and is
+	// answered from its row.
 	if err := s.repo.Create(ctx, newUser); err != nil {
+		if errors.Is(err, ErrEmailExists) {
+			return s.registrationConflict(ctx, firstName, lastName, email, password)
+		}
 		s.logger.Error("failed to create user", "error", err)
 		return nil, ErrInternal.WithCause(err)
 	}
+	s.rememberRegistration(ctx, newUser)
+	s.rememberPassword(ctx, newUser.ID, hashedPassword)
+	s.scheduleOnboarding(ctx, newUser)
 
-	// 6) Issue a 6-digit verification code and send email
+	// 5) Issue a verification code and send email
 	code, cerr := s.createOrRefreshVerificationCode(ctx, newUser, newUser.Email, VerificationPurposeEmailVerify, VerificationChannelEmail)
 	if cerr != nil {
 		if errors.Is(cerr, ErrResendTooSoon) {
@@ -106,10 +80,16 @@ func (s *service) Register(ctx context.Context, firstName, lastName, email, pass
 		}
 	} else if code != "" {
 		go func(u *User, c string) {
+			ctx := contextx.Detach(ctx) // the request may finish before the send
 			data := templates.VerifyEmailData{
-				FirstName:    u.FirstName,
-				Code:         c,
-				SupportEmail: s.config.SMTP.From,
+				FirstName:                  u.FirstName,
+				Code:                       c,
+				ExpiresInMinutes:           s.otpPolicy(VerificationPurposeEmailVerify).TTLMinutes,
+				ExpiresAt:                  s.otpExpiresAt(VerificationPurposeEmailVerify),
+				Locale:                     recipientLocale(u),
+				VerifyLink:                 s.emailVerifyLink(ctx, u),
+				VerifyLinkExpiresInMinutes: s.verifyLinkTTLMinutes(),
+				SupportEmail:               s.config.SMTP.From,
 			}
 			if err := notification.SendTemplate(ctx, s.notification, templates.VerifyEmail, u.Email, []notification.Channel{notification.ChannelEmail}, notification.PriorityHigh, data); err != nil {
 				s.logger.Error("failed to send verify email", "error", err, "user_id", u.ID)
@@ -121,12 +101,107 @@ func (s *service) Register(ctx context.Context, firstName, lastName, email, pass
 	return newUser, nil
 }
 
-// Login handles the business logic for authenticating a user.
-func (s *service) Login(ctx context.Context, email, password string) (string, error) {
+// reRegister answers a registration for an email that already has an account. Unverified
+// accounts get their names updated (the password is kept as-is) and a fresh verification code,
+// subject to the resend cooldown.
+func (s *service) reRegister(ctx context.Context, existing *User, firstName, lastName string) (*User, error) {
+	if existing.DeletedAt != nil {
+		// Soft-deleted: offer the restore path while it is still available.
+		if s.isRestorable(existing) {
+			return nil, ErrAccountPendingDeletion
+		}
+		return nil, ErrEmailExists
+	}
+	if existing.EmailVerified {
+		return nil, ErrEmailExists
+	}
+	// Re-register allowed for unverified: update names only, keep password as-is
+	changed := false
+	if existing.FirstName != firstName {
+		existing.FirstName = firstName
+		changed = true
+	}
+	if existing.LastName != lastName {
+		existing.LastName = lastName
+		changed = true
+	}
+	if changed {
+		if uerr := s.repo.Update(ctx, existing); uerr != nil {
+			s.logger.Error("failed to update unverified user names", "error", uerr, "user_id", existing.ID)
+			return nil, ErrInternal.WithCause(uerr)
+		}
+	}
+
+	// Generate or refresh verification code (respect cooldown)
+	code, cerr := s.createOrRefreshVerificationCode(ctx, existing, existing.Email, VerificationPurposeEmailVerify, VerificationChannelEmail)
+	if cerr != nil {
+		if errors.Is(cerr, ErrResendTooSoon) {
+			s.logger.Info("verification code resend cooldown active", "email", existing.Email)
+		} else {
+			s.logger.Error("failed to create/refresh verification code", "error", cerr, "user_id", existing.ID)
+		}
+	} else if code != "" {
+		// Fire-and-forget notification
+		go func(u *User, c string) {
+			ctx := contextx.Detach(ctx) // the request may finish before the send
+			data := templates.VerifyEmailData{
+				FirstName:                  u.FirstName,
+				Code:                       c,
+				ExpiresInMinutes:           s.otpPolicy(VerificationPurposeEmailVerify).TTLMinutes,
+				ExpiresAt:                  s.otpExpiresAt(VerificationPurposeEmailVerify),
+				Locale:                     recipientLocale(u),
+				VerifyLink:                 s.emailVerifyLink(ctx, u),
+				VerifyLinkExpiresInMinutes: s.verifyLinkTTLMinutes(),
+				SupportEmail:               s.config.SMTP.From,
+			}
+			if err := notification.SendTemplate(ctx, s.notification, templates.VerifyEmail, u.Email, []notification.Channel{notification.ChannelEmail}, notification.PriorityHigh, data); err != nil {
+				s.logger.Error("failed to send verify email", "error", err, "user_id", u.ID)
+			}
+		}(existing, code)
+	}
+
+	s.logger.Info("user re-registered; awaiting email verification", "user_id", existing.ID)
+	return existing, nil
+}
+
+// padLoginFailure waits until ACCOUNT_LOGIN_FAILURE_MIN_MILLISECONDS have passed since start (or
+// ctx ends), so the remaining differences between failure paths (lookups, event recording) do
+// not show in the response time.
+func (s *service) padLoginFailure(ctx context.Context, start time.Time) {
+	floor := time.Duration(s.config.Accounts.LoginFailureMinMilliseconds) * time.Millisecond
+	wait := floor - time.Since(start)
+	if wait <= 0 {
+		return
+	}
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
+
+// Login handles the business logic for authenticating a user. With rememberMe the session
+// gets the long remember-me lifetimes.
+// Failed logins take as long for an unknown email as for a wrong password: the password is
+// always checked against a hash, and failures are padded to ACCOUNT_LOGIN_FAILURE_MIN_MILLISECONDS.
+func (s *service) Login(ctx context.Context, email, password string, rememberMe bool) (string, error) {
+	start := time.Now()
+
+	// 0) Requests from IPs with a bad reputation may be refused outright.
+	ipAction := s.screenIP(ctx, ipreputation.EndpointLogin)
+	if ipAction == ipreputation.ActionBlock {
+		s.recordFailedLogin(ctx, email, "", "ip_blocked")
+		return "", ErrRequestBlocked
+	}
+
 	// 1) Find the user by their email address.
 	user, err := s.repo.FindByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, ErrNotFound) {
+			s.checkPasswordHash(password, "") // as costly as checking a real password
+			s.recordFailedLogin(ctx, email, "", "unknown_email")
+			s.padLoginFailure(ctx, start)
 			// Use a generic error to avoid telling attackers that the email exists.
 			return "", ErrInvalidCredentials
 		}
@@ -135,22 +210,86 @@ func (s *service) Login(ctx context.Context, email, password string) (string, er
 	}
 
 	// 2) Check if the provided password matches the stored hash.
-	if !checkPasswordHash(password, user.PasswordHash) {
+	if !s.checkPasswordHash(password, user.PasswordHash) {
+		s.recordFailedLogin(ctx, email, user.ID, "invalid_password")
+		s.padLoginFailure(ctx, start)
 		return "", ErrInvalidCredentials
 	}
 
-	// 2b) Block login until email is verified
-	if !user.EmailVerified {
-		return "", ErrEmailNotVerified
+	// 2a) Check the account may sign in at all.
+	meta, err := s.loginSessionMetadata(ctx, user, "password")
+	if err != nil {
+		return "", err
+	}
+	meta.RememberMe = rememberMe
+	// Hashes made with an older algorithm or cost are replaced now that the password is known.
+	s.upgradePasswordHash(ctx, user, password)
+
+	// 2b) From a flagged IP, the session is only issued once an emailed code is confirmed
+	// (ConfirmLoginStepUp).
+	if ipAction == ipreputation.ActionStepUp {
+		return "", s.startLoginStepUp(ctx, user)
 	}
 
 	// 3) Create an auth session and return the session ID.
-	sessionID, err := s.sessions.CreateAuthSession(ctx, user.ID, "", "")
+	sessionID, err := s.sessions.CreateAuthSession(ctx, user.ID, meta)
 	if err != nil {
 		s.logger.Error("failed to create auth session", "error", err)
-		return "", ErrInternal.WithCause(err)
+		return "", sessionIssueError(err)
 	}
 
+	s.recordLogin(ctx, user.ID, "password")
+
 	s.logger.Info("user logged in successfully", "user_id", user.ID)
 	return sessionID, nil
 }
+
+// loginSessionMetadata checks that user, whose credentials were verified, may sign in and
+// returns the metadata of the session to create. Rejections are recorded as failed logins.
+func (s *service) loginSessionMetadata(ctx context.Context, user *User, authMethod string) (session.Metadata, error) {
+	// Soft-deleted accounts must be restored first; past the grace period they are gone.
+	if user.DeletedAt != nil {
+		s.recordFailedLogin(ctx, user.Email, user.ID, "account_deleted")
+		if s.isRestorable(user) {
+			return session.Metadata{}, ErrAccountPendingDeletion
+		}
+		return session.Metadata{}, ErrInvalidCredentials
+	}
+
+	// Suspended accounts cannot sign in until an admin lifts the suspension.
+	if user.SuspendedAt != nil {
+		s.recordFailedLogin(ctx, user.Email, user.ID, "account_suspended")
+		return session.Metadata{}, ErrAccountSuspended
+	}
+
+	// An admin may have invalidated the password after a suspected compromise.
+	if user.PasswordResetRequired {
+		s.recordFailedLogin(ctx, user.Email, user.ID, "password_reset_required")
+		return session.Metadata{}, ErrPasswordResetRequired
+	}
+
+	// Block login until email is verified, unless the signup grace period allows a
+	// restricted session that ends with the grace period.
+	meta := sessionMetadata(ctx, authMethod)
+	if !user.EmailVerified {
+		graceEnd, ok := s.unverifiedLoginGraceEnd(user)
+		if !ok {
+			s.recordFailedLogin(ctx, user.Email, user.ID, "email_not_verified")
+			return session.Metadata{}, ErrEmailNotVerified
+		}
+		meta.Scope = session.ScopeEmailUnverified
+		meta.ExpiresAt = graceEnd
+	}
+	return meta, nil
+}
+
+// unverifiedLoginGraceEnd returns when the unverified-email login grace period of user ends,
+// and whether it is still running. ACCOUNT_UNVERIFIED_LOGIN_GRACE_DAYS=0 disables it.
+func (s *service) unverifiedLoginGraceEnd(user *User) (time.Time, bool) {
+	days := s.config.Accounts.UnverifiedLoginGraceDays
+	if days <= 0 {
+		return time.Time{}, false
+	}
+	end := user.CreatedAt.AddDate(0, 0, days)
+	return end, s.clock.Now().Before(end)
+}
@@ -0,0 +1,183 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/delordemm1/go-api-simple-starter/internal/config"
+	"github.com/delordemm1/go-api-simple-starter/internal/passhash"
+	"github.com/delordemm1/go-api-simple-starter/internal/session"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// loginFloor is the ACCOUNT_LOGIN_FAILURE_MIN_MILLISECONDS of the tests.
+const loginFloor = 60 * time.Millisecond
+
+// authRepo serves the lookups of failed sign-ins from memory; any other repository call panics.
+type authRepo struct {
+	Repository
+	users []*User
+	codes map[string]*VerificationCode // by user ID
+}
+
+func (r *authRepo) FindByEmail(ctx context.Context, email string) (*User, error) {
+	for _, u := range r.users {
+		if u.Email == email {
+			c := *u
+			return &c, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *authRepo) FindByPhone(ctx context.Context, phone string) (*User, error) {
+	for _, u := range r.users {
+		if u.Phone != nil && *u.Phone == phone {
+			c := *u
+			return &c, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *authRepo) GetActiveVerificationCodeByUser(ctx context.Context, userID string, purpose VerificationPurpose, channel VerificationChannel) (*VerificationCode, error) {
+	vc, ok := r.codes[userID]
+	if !ok || vc.Purpose != purpose {
+		return nil, ErrNotFound
+	}
+	c := *vc
+	return &c, nil
+}
+
+func (r *authRepo) IncrementVerificationAttempt(ctx context.Context, id string) (int, int, error) {
+	for _, vc := range r.codes {
+		if vc.ID == id {
+			vc.Attempts++
+			return vc.Attempts, vc.MaxAttempts, nil
+		}
+	}
+	return 0, 0, ErrNotFound
+}
+
+// newLoginAPI serves the user routes over a service knowing alice@example.com (password
+// "correct horse"), bob@example.com (no password, e.g. signed up with OAuth) and +15550000003
+// (an SMS sign-in code "123456" pending).
+func newLoginAPI(t *testing.T) humatest.TestAPI {
+	t.Helper()
+	hasher, err := passhash.New(passhash.Config{Algorithm: passhash.Bcrypt, BcryptCost: bcrypt.MinCost})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := hasher.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	phone, phoneUserID := "+15550000003", "0199f0a0-0000-7000-8000-000000000003"
+	cfg := &config.Config{}
+	cfg.Accounts.LoginFailureMinMilliseconds = int(loginFloor / time.Millisecond)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	sessions := session.NewMemoryProvider(session.Config{})
+	svc := NewService(&Config{
+		Repo: &authRepo{
+			users: []*User{
+				{ID: "0199f0a0-0000-7000-8000-000000000001", Email: "alice@example.com", PasswordHash: hash},
+				{ID: "0199f0a0-0000-7000-8000-000000000002", Email: "bob@example.com"},
+				{ID: phoneUserID, Phone: &phone, PhoneVerified: true},
+			},
+			codes: map[string]*VerificationCode{
+				phoneUserID: {
+					ID:          "0199f0a0-0000-7000-8000-0000000000c3",
+					Purpose:     VerificationPurposePhoneLogin,
+					Channel:     VerificationChannelSMS,
+					CodeHash:    hashToken("123456"),
+					MaxAttempts: 5,
+					ExpiresAt:   time.Now().Add(time.Hour),
+				},
+			},
+		},
+		Logger:         logger,
+		Config:         cfg,
+		Sessions:       sessions,
+		PasswordHasher: hasher,
+	})
+	_, api := humatest.New(t)
+	NewHandler(svc, logger, sessions, nil).RegisterRoutes(api)
+	return api
+}
+
+// problemShape is a problem body without the fields that identify the occurrence.
+func problemShape(t *testing.T, body []byte) map[string]any {
+	t.Helper()
+	var p map[string]any
+	if err := json.Unmarshal(body, &p); err != nil {
+		t.Fatalf("decode problem %s: %v", body, err)
+	}
+	delete(p, "instance")
+	delete(p, "requestId")
+	return p
+}
+
+// TestFailedSignInsAreSymmetric checks that a sign-in for an unknown account cannot be told
+// apart from one that failed on an existing account: same status and same problem body, and,
+// for password logins, both held to the failure floor.
+func TestFailedSignInsAreSymmetric(t *testing.T) {
+	api := newLoginAPI(t)
+
+	tests := []struct {
+		name          string
+		path          string
+		status        int
+		floor         bool
+		unknown, real map[string]any
+	}{
+		{
+			name:    "login with wrong password",
+			path:    "/users/login",
+			status:  http.StatusUnauthorized,
+			floor:   true,
+			unknown: map[string]any{"email": "nobody@example.com", "password": "correct horse"},
+			real:    map[string]any{"email": "alice@example.com", "password": "wrong horse"},
+		},
+		{
+			name:    "login to account without password",
+			path:    "/users/login",
+			status:  http.StatusUnauthorized,
+			floor:   true,
+			unknown: map[string]any{"email": "nobody@example.com", "password": "correct horse"},
+			real:    map[string]any{"email": "bob@example.com", "password": "correct horse"},
+		},
+		{
+			name:    "phone login with wrong code",
+			path:    "/users/phone/login",
+			status:  http.StatusBadRequest,
+			unknown: map[string]any{"phone": "+15559999999", "code": "654321"},
+			real:    map[string]any{"phone": "+15550000003", "code": "654321"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var shapes []map[string]any
+			for _, body := range []map[string]any{tt.unknown, tt.real} {
+				start := time.Now()
+				resp := api.Post(tt.path, body)
+				if elapsed := time.Since(start); tt.floor && elapsed < loginFloor {
+					t.Errorf("%v failed after %v, before the %v floor", body, elapsed, loginFloor)
+				}
+				if resp.Code != tt.status {
+					t.Fatalf("%v: status %d, want %d: %s", body, resp.Code, tt.status, resp.Body)
+				}
+				shapes = append(shapes, problemShape(t, resp.Body.Bytes()))
+			}
+			if !reflect.DeepEqual(shapes[0], shapes[1]) {
+				t.Errorf("problem bodies differ:\nunknown account: %v\nexisting account: %v", shapes[0], shapes[1])
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package user
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/ipreputation"
+	"github.com/delordemm1/go-api-simple-starter/internal/metrics"
+)
+
+// botTrips counts registrations rejected by the bot deterrents, labelled by reason.
+var botTrips = metrics.NewCounter("user_registration_bot_trips")
+
+// RegistrationSignals carries client-side hints used to screen automated registrations.
+type RegistrationSignals struct {
+	// Honeypot is the value of a hidden form field that humans never fill in.
+	Honeypot string
+	// FormRenderedAt is when the client rendered the registration form (zero if unknown).
+	FormRenderedAt time.Time
+}
+
+// ScreenRegistration applies the configured honeypot and minimum-form-time checks.
+// It returns ErrRegistrationRejected without revealing which check tripped, or ErrRequestBlocked
+// when the client IP's reputation blocks registration.
+func (s *service) ScreenRegistration(ctx context.Context, signals RegistrationSignals) error {
+	cfg := s.config.BotDetection
+
+	if s.screenIP(ctx, ipreputation.EndpointRegister) == ipreputation.ActionBlock {
+		return ErrRequestBlocked
+	}
+
+	if cfg.HoneypotEnabled && strings.TrimSpace(signals.Honeypot) != "" {
+		botTrips.Inc("honeypot")
+		s.logger.Warn("registration rejected by honeypot")
+		return ErrRegistrationRejected
+	}
+
+	// Frontends that don't send a render timestamp are not penalized.
+	if cfg.MinFormSeconds > 0 && !signals.FormRenderedAt.IsZero() {
+		if s.clock.Now().Sub(signals.FormRenderedAt) < time.Duration(cfg.MinFormSeconds)*time.Second {
+			botTrips.Inc("too_fast")
+			s.logger.Warn("registration rejected: form submitted too quickly")
+			return ErrRegistrationRejected
+		}
+	}
+
+	return nil
+}
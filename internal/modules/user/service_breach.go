@@ -0,0 +1,33 @@
+package user
+
+import (
+	"context"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/validation"
+)
+
+// BreachChecker reports whether a password appears in known data breaches
+// (implemented by pwned.Client).
+type BreachChecker interface {
+	Breached(ctx context.Context, password string) (bool, error)
+}
+
+// checkBreachedPassword rejects a new password found in known breaches with a validation
+// problem on the "password" field. Lookup failures are logged and the password is accepted,
+// so an outage of the breach service never blocks registration or recovery.
+func (s *service) checkBreachedPassword(ctx context.Context, password string) error {
+	if s.breaches == nil {
+		return nil
+	}
+	breached, err := s.breaches.Breached(ctx, password)
+	if err != nil {
+		s.logger.Warn("breached password check failed; accepting password", "error", err)
+		return nil
+	}
+	if breached {
+		return validation.NewFieldError(validation.FieldErrors{
+			"password": {"has appeared in a data breach; choose a different password"},
+		})
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package user
+
+import (
+	"context"
+	"errors"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/captcha"
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+)
+
+// VerifyCaptcha returns ErrCaptchaFailed when endpoint requires a CAPTCHA and token is missing or
+// rejected by the provider. A provider that cannot be reached fails closed with ErrCaptchaUnavailable,
+// since letting requests through would switch the protection off exactly when it is attacked.
+func (s *service) VerifyCaptcha(ctx context.Context, endpoint, token string) error {
+	if !s.captcha.Required(endpoint) {
+		return nil
+	}
+	err := s.captcha.Verifier.Verify(ctx, token, contextx.ClientIP(ctx))
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, captcha.ErrRejected):
+		s.logger.Warn("captcha rejected", "endpoint", endpoint, "error", err)
+		return ErrCaptchaFailed
+	default:
+		s.logger.Error("captcha verification failed", "endpoint", endpoint, "error", err)
+		return ErrCaptchaUnavailable.WithCause(err)
+	}
+}
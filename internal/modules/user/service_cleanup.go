@@ -0,0 +1,140 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/metrics"
+	"github.com/delordemm1/go-api-simple-starter/internal/notification"
+	"github.com/delordemm1/go-api-simple-starter/internal/notification/templates"
+)
+
+// cleanupBatchSize bounds how many accounts each cleanup step processes per run.
+const cleanupBatchSize = 100
+
+// cleanupActions counts automated lifecycle actions, labelled by action.
+var cleanupActions = metrics.NewCounter("user_account_cleanup_actions")
+
+// CleanupReport summarizes one cleanup pass.
+type CleanupReport struct {
+	DeletedUnverified int
+	ReengagementSent  int
+	Anonymized        int
+}
+
+// CleanupAccounts runs one pass of the account cleanup pipeline:
+//  1. hard-delete accounts left unverified for UnverifiedDeleteDays;
+//  2. email users inactive for InactiveMonths that their account will be anonymized;
+//  3. anonymize users who did not return within AnonymizeAfterDays of that email;
+//  4. anonymize soft-deleted accounts whose restore grace period has elapsed.
+//
+// Every action is written to account_lifecycle_audit. Each step handles at most
+// cleanupBatchSize accounts; the scheduler picks up the rest on the next run.
+func (s *service) CleanupAccounts(ctx context.Context) (*CleanupReport, error) {
+	cfg := s.config.Accounts
+	now := s.clock.Now()
+	report := &CleanupReport{}
+	var errs []error
+
+	if cfg.UnverifiedDeleteDays > 0 {
+		users, err := s.repo.ListUnverifiedCreatedBefore(ctx, now.AddDate(0, 0, -cfg.UnverifiedDeleteDays), cleanupBatchSize)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("list unverified users: %w", err))
+		}
+		for _, u := range users {
+			reason := fmt.Sprintf("email unverified for %d days", cfg.UnverifiedDeleteDays)
+			if err := s.audited(ctx, u.ID, LifecycleDeletedUnverified, reason, func() error {
+				return s.repo.HardDelete(ctx, u.ID)
+			}); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			report.DeletedUnverified++
+		}
+	}
+
+	if cfg.InactiveMonths > 0 {
+		if _, err := s.repo.ClearReengagementForReturningUsers(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("clear re-engagement markers: %w", err))
+		}
+
+		users, err := s.repo.ListInactiveSince(ctx, now.AddDate(0, -cfg.InactiveMonths, 0), cleanupBatchSize)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("list inactive users: %w", err))
+		}
+		for _, u := range users {
+			reason := fmt.Sprintf("inactive for %d months", cfg.InactiveMonths)
+			if err := s.audited(ctx, u.ID, LifecycleReengagementSent, reason, func() error {
+				return s.sendReengagement(ctx, u, now)
+			}); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			report.ReengagementSent++
+		}
+
+		if cfg.AnonymizeAfterDays > 0 {
+			users, err := s.repo.ListReengagementExpired(ctx, now.AddDate(0, 0, -cfg.AnonymizeAfterDays), cleanupBatchSize)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("list re-engagement expired users: %w", err))
+			}
+			for _, u := range users {
+				reason := fmt.Sprintf("no sign-in within %d days of re-engagement email", cfg.AnonymizeAfterDays)
+				if err := s.AnonymizeUser(ctx, "", u.ID, reason); err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				report.Anonymized++
+			}
+		}
+	}
+
+	users, err := s.repo.ListDeletedBefore(ctx, now.Add(-s.deletionGrace()), cleanupBatchSize)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("list deleted users: %w", err))
+	}
+	for _, u := range users {
+		if err := s.AnonymizeUser(ctx, "", u.ID, "deletion grace period elapsed"); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		report.Anonymized++
+	}
+
+	s.logger.Info("account cleanup pass finished",
+		"deleted_unverified", report.DeletedUnverified,
+		"reengagement_sent", report.ReengagementSent,
+		"anonymized", report.Anonymized,
+		"errors", len(errs))
+	return report, errors.Join(errs...)
+}
+
+// audited performs a lifecycle action and records it in the audit trail.
+func (s *service) audited(ctx context.Context, userID string, action LifecycleAction, reason string, fn func() error) error {
+	if err := fn(); err != nil {
+		return fmt.Errorf("%s %s: %w", action, userID, err)
+	}
+	cleanupActions.Inc(string(action))
+	if err := s.repo.CreateLifecycleAudit(ctx, userID, action, reason); err != nil {
+		// The action already happened; surface the missing audit record loudly.
+		s.logger.Error("failed to write lifecycle audit record", "error", err, "user_id", userID, "action", action)
+		return fmt.Errorf("audit %s %s: %w", action, userID, err)
+	}
+	s.logger.Info("account lifecycle action", "user_id", userID, "action", action, "reason", reason)
+	return nil
+}
+
+func (s *service) sendReengagement(ctx context.Context, u *User, now time.Time) error {
+	data := templates.ReengagementData{
+		FirstName:          u.FirstName,
+		InactiveMonths:     s.config.Accounts.InactiveMonths,
+		AnonymizeAfterDays: s.config.Accounts.AnonymizeAfterDays,
+		SupportEmail:       s.config.SMTP.From,
+	}
+	if err := notification.SendTemplate(ctx, s.notification, templates.Reengagement, u.Email, []notification.Channel{notification.ChannelEmail}, notification.PriorityLow, data); err != nil {
+		return err
+	}
+	return s.repo.MarkReengagementSent(ctx, u.ID, now)
+}
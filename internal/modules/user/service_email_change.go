@@ -0,0 +1,134 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/notification"
+	"github.com/delordemm1/go-api-simple-starter/internal/notification/templates"
+	"github.com/delordemm1/go-api-simple-starter/internal/validation"
+)
+
+// RequestEmailChange sends a confirmation code to newEmail and a notice to the user's current
+// address. users.email is only changed by ConfirmEmailChange; until then the pending address
+// lives solely in the email_change verification code.
+func (s *service) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrNotFound.WithCause(err)
+		}
+		s.logger.Error("request email change: find user failed", "error", err, "user_id", userID)
+		return ErrInternal.WithCause(err)
+	}
+	if strings.EqualFold(user.Email, newEmail) {
+		return validation.NewFieldError(validation.FieldErrors{"email": {"is already your email address"}})
+	}
+	if _, err := s.repo.FindByEmail(ctx, newEmail); err == nil {
+		return ErrEmailExists
+	} else if !errors.Is(err, ErrNotFound) {
+		s.logger.Error("request email change: find by email failed", "error", err)
+		return ErrInternal.WithCause(err)
+	}
+
+	// A pending code for a different address is replaced (after its cooldown) rather than
+	// refreshed, because a refresh keeps the original contact.
+	pending, err := s.repo.GetActiveVerificationCodeByUser(ctx, user.ID, VerificationPurposeEmailChange, VerificationChannelEmail)
+	switch {
+	case err == nil && !strings.EqualFold(pending.Contact, newEmail):
+		cooldown := time.Duration(s.otpPolicy(VerificationPurposeEmailChange).ResendCooldownSeconds) * time.Second
+		if s.clock.Now().Sub(pending.LastSentAt) < cooldown {
+			return ErrResendTooSoon
+		}
+		if err := s.repo.ConsumeVerificationCode(ctx, pending.ID); err != nil && !errors.Is(err, ErrNotFound) {
+			s.logger.Error("request email change: discard pending code failed", "error", err, "user_id", user.ID)
+			return ErrInternal.WithCause(err)
+		}
+	case err != nil && !errors.Is(err, ErrNotFound):
+		s.logger.Error("request email change: get pending code failed", "error", err, "user_id", user.ID)
+		return ErrInternal.WithCause(err)
+	}
+
+	code, err := s.createOrRefreshVerificationCode(ctx, user, newEmail, VerificationPurposeEmailChange, VerificationChannelEmail)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		ctx := contextx.Detach(ctx) // the request may finish before the send
+		codeData := templates.EmailChangeCodeData{
+			FirstName:        user.FirstName,
+			Code:             code,
+			ExpiresInMinutes: s.otpPolicy(VerificationPurposeEmailChange).TTLMinutes,
+			ExpiresAt:        s.otpExpiresAt(VerificationPurposeEmailChange),
+			Locale:           recipientLocale(user),
+			SupportEmail:     s.config.SMTP.From,
+		}
+		if err := notification.SendTemplate(ctx, s.notification, templates.EmailChangeCode, newEmail, []notification.Channel{notification.ChannelEmail}, notification.PriorityHigh, codeData); err != nil {
+			s.logger.Error("failed to send email change code", "error", err, "user_id", user.ID)
+		}
+		if user.Email == "" {
+			return
+		}
+		noticeData := templates.EmailChangeNoticeData{
+			FirstName:    user.FirstName,
+			NewEmail:     newEmail,
+			SupportEmail: s.config.SMTP.From,
+		}
+		if err := notification.SendTemplate(ctx, s.notification, templates.EmailChangeNotice, user.Email, []notification.Channel{notification.ChannelEmail}, notification.PriorityHigh, noticeData); err != nil {
+			s.logger.Error("failed to send email change notice", "error", err, "user_id", user.ID)
+		}
+	}()
+
+	s.logger.Info("email change requested", "user_id", user.ID)
+	return nil
+}
+
+// ConfirmEmailChange validates the code sent to the pending address and makes it the user's
+// (verified) email. The unique index on email still guards against the address being taken
+// in the meantime.
+func (s *service) ConfirmEmailChange(ctx context.Context, userID, code string) (*User, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNotFound.WithCause(err)
+		}
+		s.logger.Error("confirm email change: find user failed", "error", err, "user_id", userID)
+		return nil, ErrInternal.WithCause(err)
+	}
+
+	pending, err := s.repo.GetActiveVerificationCodeByUser(ctx, user.ID, VerificationPurposeEmailChange, VerificationChannelEmail)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrInvalidOTP
+		}
+		s.logger.Error("confirm email change: get pending code failed", "error", err, "user_id", user.ID)
+		return nil, ErrInternal.WithCause(err)
+	}
+	if err := s.checkVerificationCode(ctx, user.ID, VerificationPurposeEmailChange, code); err != nil {
+		return nil, err
+	}
+
+	oldEmail := user.Email
+	user.Email = pending.Contact
+	user.EmailVerified = true
+	if err := s.repo.Update(ctx, user); err != nil {
+		if errors.Is(err, ErrEmailExists) {
+			return nil, ErrEmailExists
+		}
+		s.logger.Error("confirm email change: update user failed", "error", err, "user_id", user.ID)
+		return nil, ErrInternal.WithCause(err)
+	}
+	s.profileReads.Forget(user.ID)
+	// Verification links sent to the old address must not vouch for the new one.
+	if err := s.repo.DeleteUserActionTokensByPurpose(ctx, user.ID, actionTokenEmailVerify); err != nil {
+		s.logger.Warn("confirm email change: delete verification links failed", "error", err, "user_id", user.ID)
+	}
+
+	s.recordActivity(ctx, user.ID, ActivityEmailChanged, map[string]any{"oldEmail": oldEmail, "newEmail": user.Email})
+	s.logger.Info("email changed", "user_id", user.ID)
+	return user, nil
+}
@@ -0,0 +1,98 @@
+package user
+
+import (
+	"context"
+	"errors"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/ipreputation"
+	"github.com/delordemm1/go-api-simple-starter/internal/metrics"
+	"github.com/delordemm1/go-api-simple-starter/internal/notification"
+	"github.com/delordemm1/go-api-simple-starter/internal/notification/templates"
+)
+
+// ipReputationVerdicts counts requests from flagged IPs, labelled by endpoint and action taken.
+var ipReputationVerdicts = metrics.NewCounter("user_ip_reputation_verdicts")
+
+// screenIP returns the action endpoint takes for the client IP in ctx. Monitored IPs are only
+// logged (ActionOff is returned); lookup failures let the request through.
+func (s *service) screenIP(ctx context.Context, endpoint string) ipreputation.Action {
+	ip := contextx.ClientIP(ctx)
+	action, score, err := s.ipReputation.Screen(ctx, endpoint, ip)
+	if err != nil {
+		s.logger.Warn("ip reputation check failed; allowing request", "error", err, "endpoint", endpoint)
+	}
+	if action == ipreputation.ActionOff {
+		return ipreputation.ActionOff
+	}
+	ipReputationVerdicts.Inc(endpoint, string(action))
+	s.logger.Warn("request from flagged ip", "endpoint", endpoint, "ip", ip, "score", score, "action", action)
+	if action == ipreputation.ActionMonitor {
+		return ipreputation.ActionOff
+	}
+	return action
+}
+
+// startLoginStepUp emails user a code that completes their sign-in via ConfirmLoginStepUp and
+// returns ErrLoginStepUpRequired. A code still within its resend cooldown is not resent.
+func (s *service) startLoginStepUp(ctx context.Context, user *User) error {
+	code, err := s.createOrRefreshVerificationCode(ctx, user, user.Email, VerificationPurposeLoginStepUp, VerificationChannelEmail)
+	if err != nil {
+		if errors.Is(err, ErrResendTooSoon) {
+			return ErrLoginStepUpRequired
+		}
+		s.logger.Error("login step-up: create code failed", "error", err, "user_id", user.ID)
+		return ErrInternal.WithCause(err)
+	}
+
+	ip := contextx.ClientIP(ctx)
+	go func() {
+		ctx := contextx.Detach(ctx) // the request may finish before the send
+		data := templates.LoginStepUpCodeData{
+			FirstName:        user.FirstName,
+			Code:             code,
+			IP:               ip,
+			ExpiresInMinutes: s.otpPolicy(VerificationPurposeLoginStepUp).TTLMinutes,
+			ExpiresAt:        s.otpExpiresAt(VerificationPurposeLoginStepUp),
+			Locale:           recipientLocale(user),
+			SupportEmail:     s.config.SMTP.From,
+		}
+		if err := notification.SendTemplate(ctx, s.notification, templates.LoginStepUpCode, user.Email, []notification.Channel{notification.ChannelEmail}, notification.PriorityHigh, data); err != nil {
+			s.logger.Error("failed to send login step-up code", "error", err, "user_id", user.ID)
+		}
+	}()
+	s.logger.Info("login requires step-up verification", "user_id", user.ID)
+	return ErrLoginStepUpRequired
+}
+
+// ConfirmLoginStepUp validates the code sent by a Login that returned ErrLoginStepUpRequired
+// and signs the user in. The account is re-checked, as it may have changed since.
+func (s *service) ConfirmLoginStepUp(ctx context.Context, email, code string, rememberMe bool) (string, error) {
+	user, err := s.repo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return "", ErrInvalidOTP
+		}
+		s.logger.Error("confirm login step-up: find user failed", "error", err)
+		return "", ErrInternal.WithCause(err)
+	}
+
+	if err := s.checkVerificationCode(ctx, user.ID, VerificationPurposeLoginStepUp, code); err != nil {
+		return "", err
+	}
+
+	meta, err := s.loginSessionMetadata(ctx, user, "password")
+	if err != nil {
+		return "", err
+	}
+	meta.RememberMe = rememberMe
+	sessionID, err := s.sessions.CreateAuthSession(ctx, user.ID, meta)
+	if err != nil {
+		s.logger.Error("confirm login step-up: create session failed", "error", err, "user_id", user.ID)
+		return "", sessionIssueError(err)
+	}
+
+	s.recordLogin(ctx, user.ID, "password")
+	s.logger.Info("user logged in after step-up verification", "user_id", user.ID)
+	return sessionID, nil
+}
@@ -7,9 +7,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"strings"
 	"time"
 
+	"github.com/delordemm1/go-api-simple-starter/internal/clock"
+	"github.com/delordemm1/go-api-simple-starter/internal/config"
+	"github.com/delordemm1/go-api-simple-starter/internal/securerand"
+	"github.com/delordemm1/go-api-simple-starter/internal/session"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"golang.org/x/oauth2"
@@ -22,7 +27,25 @@ import (
 type oAuthUserInfo struct {
 	ID    string
 	Email string
-	Name  string
+	// EmailVerified is true only when the provider asserts it owns/verified Email. Users
+	// provisioned with an unverified email confirm it with a code before getting a full session.
+	EmailVerified bool
+	Name          string
+	// FirstName and LastName are set when the provider reports the name in parts (Apple).
+	FirstName string
+	LastName  string
+}
+
+// truthy reads a boolean claim that providers send either as a JSON boolean or as the
+// string "true" (Apple does both).
+func truthy(v any) bool {
+	switch b := v.(type) {
+	case bool:
+		return b
+	case string:
+		return b == "true"
+	}
+	return false
 }
 
 // OAuthProvider defines the interface for an OAuth provider like Google or Apple.
@@ -38,19 +61,20 @@ func parseApplePrivateKey(key string) (*ecdsa.PrivateKey, error) {
 }
 
 // newOAuthProvider is a factory function that returns the correct provider implementation.
-func (s *service) newOAuthProvider(provider string) (OAuth, error) {
-	switch provider {
-	case "google":
+func (s *service) newOAuthProvider(ctx context.Context, provider string) (OAuth, error) {
+	switch OAuthProvider(provider) {
+	case OAuthProviderGOOGLE:
 		return &googleProvider{
 			config: &oauth2.Config{
 				ClientID:     s.config.Google.ClientID,
 				ClientSecret: s.config.Google.ClientSecret,
 				RedirectURL:  s.config.Google.RedirectURL,
 				Endpoint:     google.Endpoint,
-				Scopes:       []string{"https://www.googleapis.com/auth/userinfo.email", "https://www.googleapis.com/auth/userinfo.profile"},
+				Scopes: append([]string{"https://www.googleapis.com/auth/userinfo.email", "https://www.googleapis.com/auth/userinfo.profile"},
+					strings.Fields(s.config.Google.ExtraScopes)...),
 			},
 		}, nil
-	case "apple":
+	case OAuthProviderAPPLE:
 		privateKey, err := parseApplePrivateKey(s.config.Apple.PrivateKey)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse apple private key: %w", err)
@@ -70,7 +94,30 @@ func (s *service) newOAuthProvider(provider string) (OAuth, error) {
 			teamID: s.config.Apple.TeamID,
 			keyID:  s.config.Apple.KeyID,
 			prvKey: privateKey,
+			keys:   s.appleKeys,
+		}, nil
+	case OAuthProviderMICROSOFT:
+		tenant := microsoftTenant(s.config)
+		scopes := []string{"openid", "email", "profile"}
+		if s.tokenBox != nil {
+			// Microsoft issues refresh tokens only for this scope.
+			scopes = append(scopes, "offline_access")
+		}
+		return &microsoftProvider{
+			config: &oauth2.Config{
+				ClientID:     s.config.Microsoft.ClientID,
+				ClientSecret: s.config.Microsoft.ClientSecret,
+				RedirectURL:  s.config.Microsoft.RedirectURL,
+				Endpoint: oauth2.Endpoint{
+					AuthURL:  "https://login.microsoftonline.com/" + tenant + "/oauth2/v2.0/authorize",
+					TokenURL: "https://login.microsoftonline.com/" + tenant + "/oauth2/v2.0/token",
+				},
+				Scopes: scopes,
+			},
+			keys: s.microsoftKeys,
 		}, nil
+	case OAuthProviderOIDC:
+		return s.newOIDCProvider(ctx)
 	default:
 		return nil, ErrUnsupportedOAuthProvider.WithDetail(fmt.Sprintf("unsupported oauth provider: %s", provider))
 	}
@@ -86,8 +133,14 @@ type appleProvider struct {
 	teamID string
 	keyID  string
 	prvKey *ecdsa.PrivateKey
+	keys   *oidcClient
+	// nonce is the value sent with the authorization request; the id_token must echo it.
+	nonce string
 }
 
+// appleIssuer is the issuer of Apple id_tokens; its discovery document points to the JWKS.
+const appleIssuer = "https://appleid.apple.com"
+
 func (g *googleProvider) getOAuthConfig() *oauth2.Config {
 	return g.config
 }
@@ -104,34 +157,101 @@ func (a *appleProvider) getUserInfo(ctx context.Context, token *oauth2.Token) (*
 		return nil, errors.New("id_token not found in apple oauth token")
 	}
 
-	// 2. Parse the JWT without verification, as we trust the source (Apple's token endpoint).
-	// For higher security, you could verify the token's signature against Apple's public key.
-	var claims struct {
-		jwt.RegisteredClaims
-		Email string `json:"email"`
-	}
-
-	// The parser needs a key function, but we're skipping verification for this step.
-	_, _, err := jwt.NewParser().ParseUnverified(idToken, &claims)
+	// 2. Verify the signature against Apple's published keys, and the issuer, audience
+	// (our client ID), and expiry.
+	claims, err := a.keys.verifyIDToken(ctx, idToken, appleIssuer, a.config.ClientID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse apple id_token: %w", err)
+		return nil, fmt.Errorf("apple: %w", err)
+	}
+	// The nonce binds the id_token to the authorization request that started this flow.
+	if a.nonce != "" && claimString(claims, "nonce") != a.nonce {
+		return nil, errors.New("apple id_token nonce does not match the authorization request")
 	}
 
 	// 3. The unique user ID is in the 'Subject' claim.
-	if claims.Subject == "" {
+	subject := claimString(claims, "sub")
+	if subject == "" {
 		return nil, errors.New("subject (user id) claim missing from apple id_token")
 	}
 
-	// Note: Apple only sends the user's name on the VERY FIRST login.
-	// Your application MUST save it then, as it won't be sent again.
-	// The name is not part of the id_token; it's sent as a separate `user` form parameter
-	// to your redirect URI, which is not handled by the standard oauth2.Exchange.
-	// The best practice is to ask the user for their name on the next screen if it's missing.
+	// Note: Apple only sends the user's name on the VERY FIRST login, and not in the id_token:
+	// it arrives as the `user` form field of the callback (see parseAppleUser) and is saved then.
+
+	return &oAuthUserInfo{
+		ID:            subject, // This is the stable unique identifier for the user.
+		Email:         claimString(claims, "email"),
+		EmailVerified: claimBool(claims, "email_verified"),
+		Name:          "", // Name comes from the callback's `user` field (see note above).
+	}, nil
+}
+
+// --- Microsoft Provider Implementation ---
+
+// microsoftProvider signs in Microsoft accounts (Azure AD / Entra ID work and school accounts
+// and, with the "common" or "consumers" tenant, personal accounts).
+type microsoftProvider struct {
+	config *oauth2.Config
+	keys   *oidcClient // the tenant's signing keys, shared across requests
+}
+
+func (m *microsoftProvider) getOAuthConfig() *oauth2.Config {
+	return m.config
+}
+
+// microsoftTenant is the configured Microsoft tenant: a tenant ID or domain, or one of the
+// multi-tenant aliases "common", "organizations" and "consumers".
+func microsoftTenant(cfg *config.Config) string {
+	if cfg.Microsoft.TenantID == "" {
+		return "common"
+	}
+	return cfg.Microsoft.TenantID
+}
+
+// newMicrosoftKeys returns the OIDC client of the tenant's v2.0 endpoint.
+func newMicrosoftKeys(tenant string, clk clock.Clock) *oidcClient {
+	c := newOIDCClient("https://login.microsoftonline.com/"+tenant+"/v2.0", clk)
+	c.tenantIssuer = true
+	return c
+}
+
+// Like Apple, Microsoft's user info is read from the id_token returned by the token endpoint.
+func (m *microsoftProvider) getUserInfo(ctx context.Context, token *oauth2.Token) (*oAuthUserInfo, error) {
+	idToken, ok := token.Extra("id_token").(string)
+	if !ok || idToken == "" {
+		return nil, errors.New("id_token not found in microsoft oauth token")
+	}
+
+	// Verify the signature against the tenant's published keys, the audience (our client ID) and
+	// the expiry; the issuer is checked below, as it depends on the token's tenant.
+	doc, err := m.keys.discovery(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("microsoft: %w", err)
+	}
+	claims, err := m.keys.verifyIDToken(ctx, idToken, "", m.config.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("microsoft: %w", err)
+	}
+	// A single tenant names one issuer; "common" and "organizations" publish the template
+	// https://login.microsoftonline.com/{tenantid}/v2.0, filled in with the token's tid.
+	tid := claimString(claims, "tid")
+	if tid == "" || claimString(claims, "iss") != strings.ReplaceAll(doc.Issuer, "{tenantid}", tid) {
+		return nil, fmt.Errorf("microsoft id_token issuer %q does not match tenant %q", claimString(claims, "iss"), tid)
+	}
+
+	subject := claimString(claims, "sub")
+	if subject == "" {
+		return nil, errors.New("subject (user id) claim missing from microsoft id_token")
+	}
 
+	// Only the optional `email` claim is used. preferred_username looks like an email but
+	// is not verified and can be changed by tenant admins, so it is never used for sign-in.
+	// The email itself counts as verified only with xms_edov (optional claim, set when the
+	// tenant verified the email's domain); otherwise it is confirmed by code.
 	return &oAuthUserInfo{
-		ID:    claims.Subject, // This is the stable unique identifier for the user.
-		Email: claims.Email,
-		Name:  "", // Name must be handled separately (see note above).
+		ID:            subject, // Pairwise per application; stable for this client ID.
+		Email:         claimString(claims, "email"),
+		EmailVerified: claimBool(claims, "xms_edov"),
+		Name:          claimString(claims, "name"),
 	}, nil
 }
 
@@ -165,43 +285,75 @@ func (g *googleProvider) getUserInfo(ctx context.Context, token *oauth2.Token) (
 	}
 
 	var userInfo struct {
-		ID    string `json:"id"`
-		Email string `json:"email"`
-		Name  string `json:"name"`
+		ID            string `json:"id"`
+		Email         string `json:"email"`
+		VerifiedEmail bool   `json:"verified_email"`
+		Name          string `json:"name"`
 	}
 	if err := json.Unmarshal(body, &userInfo); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal user info: %w", err)
 	}
 
 	return &oAuthUserInfo{
-		ID:    userInfo.ID,
-		Email: userInfo.Email,
-		Name:  userInfo.Name,
+		ID:            userInfo.ID,
+		Email:         userInfo.Email,
+		EmailVerified: userInfo.VerifiedEmail,
+		Name:          userInfo.Name,
 	}, nil
 }
 
 // --- Main Service Methods ---
 
+// OAuthCallbackResult is the outcome of an OAuth callback. A login yields a SessionID; a flow
+// started with InitiateOAuthLink sets Linked instead and creates no session.
+type OAuthCallbackResult struct {
+	SessionID string
+	Linked    bool
+	// EmailVerificationRequired marks SessionID as a pending session: the provider did not
+	// verify the email, so a code was sent and must be confirmed to unlock the account.
+	EmailVerificationRequired bool
+}
+
 // InitiateOAuthLogin generates the redirect URL and a state for CSRF protection.
 // The handler is responsible for storing the state (e.g., in a secure, short-lived cookie).
 func (s *service) InitiateOAuthLogin(ctx context.Context, provider OAuthProvider) (redirectURL string, err error) {
-	oauthProvider, err := s.newOAuthProvider((string(provider)))
+	return s.initiateOAuth(ctx, provider, nil)
+}
+
+// InitiateOAuthLink starts a flow that links the provider identity to userID (who is already
+// signed in) instead of logging in. The user is remembered on the stored state.
+func (s *service) InitiateOAuthLink(ctx context.Context, userID string, provider OAuthProvider) (redirectURL string, err error) {
+	return s.initiateOAuth(ctx, provider, &userID)
+}
+
+func (s *service) initiateOAuth(ctx context.Context, provider OAuthProvider, userID *string) (redirectURL string, err error) {
+	oauthProvider, err := s.newOAuthProvider(ctx, string(provider))
 	if err != nil {
 		return "", err
 	}
 
 	// Generate a random state string for CSRF protection.
-	state, err := generateSecureToken(32)
+	state, err := securerand.Token(32)
 	if err != nil {
 		return "", ErrInternal.WithCause(fmt.Errorf("failed to generate oauth state: %w", err))
 	}
 	verifier := oauth2.GenerateVerifier()
+	var nonce *string
+	if provider == OAuthProviderAPPLE {
+		n, err := securerand.Token(32)
+		if err != nil {
+			return "", ErrInternal.WithCause(fmt.Errorf("failed to generate oauth nonce: %w", err))
+		}
+		nonce = &n
+	}
 	err = s.repo.InsertOAuthState(ctx, &OAuthState{
 		Verifier:  verifier,
+		Nonce:     nonce,
 		State:     state,
-		ExpiresAt: time.Now().Add(5 * time.Minute),
-		UpdatedAt: time.Now(),
+		ExpiresAt: s.clock.Now().Add(5 * time.Minute),
+		UpdatedAt: s.clock.Now(),
 		Provider:  provider,
+		UserID:    userID,
 	})
 	if err != nil {
 		return "", ErrInternal.WithCause(fmt.Errorf("failed to generate oauth state: %w", err))
@@ -212,11 +364,17 @@ func (s *service) InitiateOAuthLogin(ctx context.Context, provider OAuthProvider
 		oauth2.AccessTypeOffline,
 		oauth2.S256ChallengeOption(verifier),
 	}
+	// Google grants a refresh token only on consent; ask again when linking so the stored
+	// tokens can be refreshed.
+	if userID != nil && s.tokenBox != nil && provider == OAuthProviderGOOGLE {
+		opts = append(opts, oauth2.SetAuthURLParam("prompt", "consent"))
+	}
 	// Apple requires response_mode=form_post when requesting name/email scopes.
-	if provider == "apple" {
+	if provider == OAuthProviderAPPLE {
 		opts = append(opts,
 			oauth2.SetAuthURLParam("response_mode", "form_post"),
 			oauth2.SetAuthURLParam("response_type", "code"),
+			oauth2.SetAuthURLParam("nonce", *nonce),
 		)
 	}
 	url := oauthProvider.getOAuthConfig().AuthCodeURL(state, opts...)
@@ -225,41 +383,49 @@ func (s *service) InitiateOAuthLogin(ctx context.Context, provider OAuthProvider
 }
 
 // HandleOAuthCallback processes the callback from the OAuth provider. It verifies the state,
-// exchanges the code for a token, fetches user info, finds or creates a local user,
-// and returns a session ID.
-func (s *service) HandleOAuthCallback(ctx context.Context, provider OAuthProvider, state, code string) (sessionID string, err error) {
-	oauthProvider, err := s.newOAuthProvider(string(provider))
+// exchanges the code for a token, and fetches user info. A link flow then attaches the identity
+// to the user who started it; a login resolves the local user through the linked identity (or
+// links/provisions one by email) and returns a session ID.
+func (s *service) HandleOAuthCallback(ctx context.Context, provider OAuthProvider, state, code, appleUser string) (*OAuthCallbackResult, error) {
+	oauthProvider, err := s.newOAuthProvider(ctx, string(provider))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	token, err := s.repo.GetOAuthStateByState(ctx, state)
 	if err != nil {
 		if errors.Is(err, ErrNotFound) {
 			s.logger.Error("oauth state not found", "state", state, "error", err)
-			return "", ErrOAuthStateInvalid.WithCause(err)
+			return nil, ErrOAuthStateInvalid.WithCause(err)
 		}
 		s.logger.Error("error getting oauth state", "error", err)
-		return "", ErrInternal.WithCause(err)
+		return nil, ErrInternal.WithCause(err)
 	}
-	if time.Now().After(token.ExpiresAt) {
+	if s.clock.Now().After(token.ExpiresAt) {
 		s.logger.Error("oauth state expired", "state", state)
-		return "", ErrOAuthStateExpired
+		return nil, ErrOAuthStateExpired
+	}
+	// A state is bound to the provider it was issued for.
+	if token.Provider != provider {
+		return nil, ErrOAuthStateInvalid
 	}
 	defer s.repo.DeleteOAuthState(ctx, state)
 
 	var exchangeOptions []oauth2.AuthCodeOption
 	exchangeOptions = append(exchangeOptions, oauth2.VerifierOption(token.Verifier))
 
-	if provider == "apple" {
+	if provider == OAuthProviderAPPLE {
 		appleP, ok := oauthProvider.(*appleProvider)
 		if !ok {
-			return "", ErrInternal.WithDetail("provider is not a valid apple provider")
+			return nil, ErrInternal.WithDetail("provider is not a valid apple provider")
+		}
+		if token.Nonce != nil {
+			appleP.nonce = *token.Nonce
 		}
 
 		clientSecret, err := appleP.generateAppleClientSecret()
 		if err != nil {
-			return "", ErrInternal.WithCause(fmt.Errorf("failed to generate apple client secret: %w", err))
+			return nil, ErrInternal.WithCause(fmt.Errorf("failed to generate apple client secret: %w", err))
 		}
 		exchangeOptions = append(exchangeOptions, oauth2.SetAuthURLParam("client_secret", clientSecret))
 	}
@@ -267,66 +433,356 @@ func (s *service) HandleOAuthCallback(ctx context.Context, provider OAuthProvide
 	// Exchange the authorization code for an access token.
 	oauthToken, err := oauthProvider.getOAuthConfig().Exchange(ctx, code, exchangeOptions...)
 	if err != nil {
-		return "", ErrOAuthExchangeFailed.WithCause(fmt.Errorf("failed to exchange oauth code for token: %w", err))
+		return nil, ErrOAuthExchangeFailed.WithCause(fmt.Errorf("failed to exchange oauth code for token: %w", err))
 	}
 
 	// 3. Fetch the user's information from the provider.
 	userInfo, err := oauthProvider.getUserInfo(ctx, oauthToken)
 	if err != nil {
-		return "", ErrOAuthExchangeFailed.WithCause(err)
+		return nil, ErrOAuthExchangeFailed.WithCause(err)
+	}
+	if userInfo.ID == "" {
+		return nil, ErrOAuthExchangeFailed.WithDetail("the provider did not return a subject identifier")
+	}
+	if provider == OAuthProviderAPPLE && appleUser != "" {
+		parseAppleUser(appleUser, userInfo, s.logger)
 	}
-	if userInfo.Email == "" {
-		return "", ErrOAuthEmailMissing
+
+	// A flow started by InitiateOAuthLink links the identity and does not log in.
+	if token.UserID != nil {
+		if err := s.linkOAuthAccount(ctx, *token.UserID, provider, userInfo); err != nil {
+			return nil, err
+		}
+		if user, err := s.repo.FindByID(ctx, *token.UserID); err == nil {
+			s.saveOAuthName(ctx, user, userInfo)
+		}
+		s.saveOAuthTokens(ctx, provider, userInfo.ID, oauthToken)
+		return &OAuthCallbackResult{Linked: true}, nil
 	}
 
-	// 4. Find or create the user in the local database.
-	user, err := s.repo.FindByEmail(ctx, userInfo.Email)
-	firstName, lastName := "", ""
-	nameParts := strings.SplitN(userInfo.Name, " ", 2)
+	// 4. Find (or provision) the local user for this identity.
+	user, err := s.resolveOAuthUser(ctx, provider, userInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	// Soft-deleted accounts must go through the restore flow.
+	if user.DeletedAt != nil {
+		if s.isRestorable(user) {
+			return nil, ErrAccountPendingDeletion
+		}
+		return nil, ErrOAuthExchangeFailed
+	}
+	if user.SuspendedAt != nil {
+		return nil, ErrAccountSuspended
+	}
+	s.saveOAuthTokens(ctx, provider, userInfo.ID, oauthToken)
+	s.saveOAuthName(ctx, user, userInfo)
+
+	// 5. Create a session for the user. While the email is unverified it is a pending session,
+	// restricted like an unverified password login, that lasts as long as the emailed code.
+	// Confirming the code (POST /users/verify/email/confirm) upgrades it to a full session.
+	meta := sessionMetadata(ctx, "oauth:"+string(provider))
+	pending, err := s.requireOAuthEmailVerification(ctx, user, userInfo)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		meta.Scope = session.ScopeEmailUnverified
+		meta.ExpiresAt = s.otpExpiresAt(VerificationPurposeEmailVerify)
+	}
+	sessionID, err := s.sessions.CreateAuthSession(ctx, user.ID, meta)
+	if err != nil {
+		s.logger.Error("failed to create auth session after oauth login", "error", err)
+		return nil, sessionIssueError(err)
+	}
+
+	s.recordLogin(ctx, user.ID, "oauth:"+string(provider))
+
+	s.logger.Info("user logged in successfully via oauth", "provider", provider, "user_id", user.ID, "pending_email_verification", pending)
+
+	return &OAuthCallbackResult{SessionID: sessionID, EmailVerificationRequired: pending}, nil
+}
+
+// requireOAuthEmailVerification reports whether user's email still needs confirming. An
+// unverified email is marked verified once the provider asserts it; otherwise a verification
+// code is sent (subject to the resend cooldown) and the login gets a pending session.
+func (s *service) requireOAuthEmailVerification(ctx context.Context, user *User, info *oAuthUserInfo) (bool, error) {
+	if user.EmailVerified {
+		return false, nil
+	}
+	if info.EmailVerified && strings.EqualFold(info.Email, user.Email) {
+		user.EmailVerified = true
+		if err := s.repo.Update(ctx, user); err != nil {
+			s.logger.Error("failed to mark oauth email verified", "error", err, "user_id", user.ID)
+			return false, ErrInternal.WithCause(err)
+		}
+		if _, err := s.sessions.ClearScope(ctx, user.ID, session.ScopeEmailUnverified); err != nil {
+			s.logger.Warn("failed to upgrade pending oauth sessions", "error", err, "user_id", user.ID)
+		}
+		return false, nil
+	}
+
+	if err := s.ResendEmailVerification(ctx, user.Email); err != nil && !errors.Is(err, ErrResendTooSoon) {
+		s.logger.Error("failed to send verification code after oauth login", "error", err, "user_id", user.ID)
+	}
+	return true, nil
+}
+
+// resolveOAuthUser returns the user a login identity belongs to. Identities are matched by
+// (provider, subject); email only decides where an identity is linked the first time it is seen:
+//   - no user has the email: a user is provisioned with the identity linked;
+//   - a user with a verified email has it and the provider verified it too: the identity is
+//     linked to that user;
+//   - either side's email is unverified, or the user already linked a different identity of
+//     this provider: ErrOAuthAccountConflict. Whoever controls the provider account may not be the
+//     owner of the local one, so they must sign in and link it explicitly.
+func (s *service) resolveOAuthUser(ctx context.Context, provider OAuthProvider, info *oAuthUserInfo) (*User, error) {
+	account, err := s.repo.FindOAuthAccount(ctx, provider, info.ID)
+	if err == nil {
+		user, err := s.repo.FindByID(ctx, account.UserID)
+		if err != nil {
+			s.logger.Error("failed to load user of linked oauth account", "error", err, "user_id", account.UserID)
+			return nil, ErrInternal.WithCause(err)
+		}
+		return user, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		s.logger.Error("failed to find oauth account during oauth callback", "error", err)
+		return nil, ErrInternal.WithCause(err)
+	}
+
+	if info.Email == "" {
+		return nil, ErrOAuthEmailMissing
+	}
+	user, err := s.repo.FindByEmail(ctx, info.Email)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			s.logger.Error("failed to find user by email during oauth callback", "error", err)
+			return nil, ErrInternal.WithCause(err)
+		}
+		user, created, err := s.provisionOAuthUser(ctx, provider, info)
+		if err != nil || created {
+			return user, err
+		}
+		// Lost a race with a concurrent sign-up; link to the user that won, like any existing user.
+		return s.linkOAuthAccountOnLogin(ctx, user, provider, info)
+	}
+	return s.linkOAuthAccountOnLogin(ctx, user, provider, info)
+}
+
+// linkOAuthAccountOnLogin links a first-seen identity to the existing user with its email.
+func (s *service) linkOAuthAccountOnLogin(ctx context.Context, user *User, provider OAuthProvider, info *oAuthUserInfo) (*User, error) {
+	// Deleted and suspended users are rejected by the caller; do not link on their behalf.
+	if user.DeletedAt != nil || user.SuspendedAt != nil {
+		return user, nil
+	}
+	if !user.EmailVerified || !info.EmailVerified {
+		s.logger.Warn("oauth login refused: email match is not verified on both sides", "provider", provider, "user_id", user.ID, "provider_verified", info.EmailVerified)
+		return nil, ErrOAuthAccountConflict.WithDetail("an account with this email exists; sign in to it and link " + string(provider) + " from your account settings")
+	}
+	if err := s.createOAuthAccount(ctx, user.ID, provider, info); err != nil {
+		if errors.Is(err, ErrOAuthAccountConflict) {
+			s.logger.Warn("oauth login refused: user has another account of this provider linked", "provider", provider, "user_id", user.ID)
+		}
+		return nil, err
+	}
+	s.logger.Info("oauth account linked on first login", "provider", provider, "user_id", user.ID)
+	return user, nil
+}
+
+// provisionOAuthUser creates a user for an identity whose email is unknown and links the
+// identity. When a concurrent sign-up took the email first, that user is returned with
+// created=false and nothing is linked.
+func (s *service) provisionOAuthUser(ctx context.Context, provider OAuthProvider, info *oAuthUserInfo) (user *User, created bool, err error) {
+	firstName, lastName := oauthName(info)
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, false, ErrInternal.WithCause(err)
+	}
+	newUser := &User{
+		ID:            id.String(),
+		Email:         info.Email,
+		FirstName:     firstName,
+		LastName:      lastName,
+		EmailVerified: info.EmailVerified, // otherwise confirmed by code on login
+		CreatedAt:     s.clock.Now(),
+		UpdatedAt:     s.clock.Now(),
+	}
+
+	if err := s.repo.Create(ctx, newUser); err != nil {
+		if !errors.Is(err, ErrEmailExists) {
+			s.logger.Error("failed to create new user from oauth", "error", err)
+			return nil, false, ErrInternal.WithCause(err)
+		}
+		user, err := s.repo.FindByEmail(ctx, info.Email)
+		if err != nil {
+			s.logger.Error("failed to load concurrently created user during oauth callback", "error", err)
+			return nil, false, ErrInternal.WithCause(err)
+		}
+		return user, false, nil
+	}
+	s.logger.Info("new user created via oauth", "user_id", newUser.ID, "email", newUser.Email)
+	s.scheduleOnboarding(ctx, newUser)
+
+	if err := s.createOAuthAccount(ctx, newUser.ID, provider, info); err != nil {
+		return nil, false, err
+	}
+	return newUser, true, nil
+}
+
+// oauthName returns the first and last name reported by the provider, splitting a full name
+// at the first space when the provider does not report the parts.
+func oauthName(info *oAuthUserInfo) (firstName, lastName string) {
+	if info.FirstName != "" || info.LastName != "" {
+		return info.FirstName, info.LastName
+	}
+	nameParts := strings.SplitN(info.Name, " ", 2)
 	if len(nameParts) > 0 {
 		firstName = nameParts[0]
 	}
 	if len(nameParts) > 1 {
 		lastName = nameParts[1]
 	}
+	return firstName, lastName
+}
+
+// appleUser is the `user` form field Apple posts with the first authorization of an app.
+type appleUser struct {
+	Name struct {
+		FirstName string `json:"firstName"`
+		LastName  string `json:"lastName"`
+	} `json:"name"`
+}
+
+// parseAppleUser copies the name from Apple's `user` field into info. The field is not signed,
+// so only the name is taken from it; the email always comes from the verified id_token.
+func parseAppleUser(raw string, info *oAuthUserInfo, logger *slog.Logger) {
+	var u appleUser
+	if err := json.Unmarshal([]byte(raw), &u); err != nil {
+		logger.Warn("ignoring malformed apple user field", "error", err)
+		return
+	}
+	info.FirstName = strings.TrimSpace(u.Name.FirstName)
+	info.LastName = strings.TrimSpace(u.Name.LastName)
+}
+
+// saveOAuthName fills in user's name from the provider when the user has none yet, e.g. an
+// account created before the name was known. A name the user already has is never replaced.
+func (s *service) saveOAuthName(ctx context.Context, user *User, info *oAuthUserInfo) {
+	if user.FirstName != "" || user.LastName != "" {
+		return
+	}
+	firstName, lastName := oauthName(info)
+	if firstName == "" && lastName == "" {
+		return
+	}
+	user.FirstName, user.LastName = firstName, lastName
+	if err := s.repo.Update(ctx, user); err != nil {
+		s.logger.Error("failed to save name from oauth provider", "error", err, "user_id", user.ID)
+		return
+	}
+	s.profileReads.Forget(user.ID)
+}
+
+// linkOAuthAccount attaches an identity to userID for an explicit link flow. Linking an
+// identity the user already has is a no-op.
+func (s *service) linkOAuthAccount(ctx context.Context, userID string, provider OAuthProvider, info *oAuthUserInfo) error {
+	account, err := s.repo.FindOAuthAccount(ctx, provider, info.ID)
+	if err == nil {
+		if account.UserID == userID {
+			return nil
+		}
+		s.logger.Warn("oauth link refused: identity is linked to another user", "provider", provider, "user_id", userID)
+		return ErrOAuthAccountConflict.WithDetail("this " + string(provider) + " account is linked to another user")
+	}
+	if !errors.Is(err, ErrNotFound) {
+		s.logger.Error("failed to find oauth account during link", "error", err, "user_id", userID)
+		return ErrInternal.WithCause(err)
+	}
+
+	if err := s.createOAuthAccount(ctx, userID, provider, info); err != nil {
+		return err
+	}
+	s.recordActivity(ctx, userID, ActivityOAuthLinked, map[string]any{"provider": string(provider)})
+	s.logger.Info("oauth account linked", "provider", provider, "user_id", userID)
+	return nil
+}
+
+// createOAuthAccount inserts the link. A unique violation means the identity was linked
+// concurrently or the user already has another identity of this provider.
+func (s *service) createOAuthAccount(ctx context.Context, userID string, provider OAuthProvider, info *oAuthUserInfo) error {
+	id, err := uuid.NewV7()
 	if err != nil {
-		// If the user doesn't exist, create a new one (provisioning).
-		if errors.Is(err, ErrNotFound) {
-			id, err := uuid.NewV7()
-			if err != nil {
-				return "", ErrInternal.WithCause(err)
-			}
-			newUser := &User{
-				ID:            id.String(),
-				Email:         userInfo.Email,
-				FirstName:     firstName,
-				LastName:      lastName,
-				EmailVerified: true,
-				CreatedAt:     time.Now(),
-				UpdatedAt:     time.Now(),
-			}
-
-			if err := s.repo.Create(ctx, newUser); err != nil {
-				s.logger.Error("failed to create new user from oauth", "error", err)
-				return "", ErrInternal.WithCause(err)
-			}
-			s.logger.Info("new user created via oauth", "user_id", newUser.ID, "email", newUser.Email)
-			user = newUser
-		} else {
-			// Handle other database errors.
-			s.logger.Error("failed to find user by email during oauth callback", "error", err)
-			return "", ErrInternal.WithCause(err)
+		return ErrInternal.WithCause(err)
+	}
+	err = s.repo.CreateOAuthAccount(ctx, &OAuthAccount{
+		ID:             id.String(),
+		UserID:         userID,
+		Provider:       provider,
+		ProviderUserID: info.ID,
+		Email:          info.Email,
+	})
+	if err != nil {
+		if errors.Is(err, ErrConflict) {
+			return ErrOAuthAccountConflict.WithDetail("a different " + string(provider) + " account is already linked; unlink it first").WithCause(err)
 		}
+		s.logger.Error("failed to create oauth account", "error", err, "user_id", userID)
+		return ErrInternal.WithCause(err)
 	}
+	return nil
+}
 
-	// 5. Create a session for the user.
-	sessionID, err = s.sessions.CreateAuthSession(ctx, user.ID, "", "")
+// ListOAuthAccounts returns the provider identities linked to userID.
+func (s *service) ListOAuthAccounts(ctx context.Context, userID string) ([]*OAuthAccount, error) {
+	accounts, err := s.repo.ListOAuthAccounts(ctx, userID)
 	if err != nil {
-		s.logger.Error("failed to create auth session after oauth login", "error", err)
-		return "", ErrInternal.WithCause(err)
+		s.logger.Error("failed to list oauth accounts", "error", err, "user_id", userID)
+		return nil, ErrInternal.WithCause(err)
 	}
+	return accounts, nil
+}
 
-	s.logger.Info("user logged in successfully via oauth", "provider", provider, "user_id", user.ID)
+// UnlinkOAuthAccount removes userID's identity for provider. It fails with ErrLastSignInMethod
+// when the identity is the user's only way to sign in: no password, no verified phone, and no
+// other linked provider.
+func (s *service) UnlinkOAuthAccount(ctx context.Context, userID string, provider OAuthProvider) error {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrNotFound.WithCause(err)
+		}
+		s.logger.Error("unlink oauth: find user failed", "error", err, "user_id", userID)
+		return ErrInternal.WithCause(err)
+	}
+	accounts, err := s.repo.ListOAuthAccounts(ctx, userID)
+	if err != nil {
+		s.logger.Error("unlink oauth: list oauth accounts failed", "error", err, "user_id", userID)
+		return ErrInternal.WithCause(err)
+	}
+	linked, others := false, 0
+	for _, a := range accounts {
+		if a.Provider == provider {
+			linked = true
+		} else {
+			others++
+		}
+	}
+	if !linked {
+		return ErrNotFound.WithDetail("no " + string(provider) + " account is linked")
+	}
+	if user.PasswordHash == "" && !user.PhoneVerified && others == 0 {
+		return ErrLastSignInMethod
+	}
 
-	return sessionID, nil
+	if err := s.repo.DeleteOAuthAccount(ctx, userID, provider); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrNotFound.WithDetail("no " + string(provider) + " account is linked")
+		}
+		s.logger.Error("failed to unlink oauth account", "error", err, "user_id", userID)
+		return ErrInternal.WithCause(err)
+	}
+	s.recordActivity(ctx, userID, ActivityOAuthUnlinked, map[string]any{"provider": string(provider)})
+	s.logger.Info("oauth account unlinked", "provider", provider, "user_id", userID)
+	return nil
 }
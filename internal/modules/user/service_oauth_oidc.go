@@ -0,0 +1,348 @@
+package user
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/clock"
+	"github.com/delordemm1/go-api-simple-starter/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+// --- Generic OIDC Provider ---
+//
+// The "oidc" provider signs users in with any OpenID Connect issuer (Keycloak, Auth0, Okta, ...)
+// from configuration alone: endpoints come from the issuer's discovery document, the id_token
+// is verified against its JWKS, and the subject/email/name are read from configurable claims.
+
+const (
+	// oidcMetadataTTL bounds how long discovery documents and key sets are reused.
+	oidcMetadataTTL = time.Hour
+	// oidcKeyRefreshInterval rate-limits key set refetches triggered by an unknown kid.
+	oidcKeyRefreshInterval = time.Minute
+)
+
+// oidcDiscovery is the subset of the issuer's /.well-known/openid-configuration we use.
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcClient caches an issuer's discovery document and signing keys. It is shared by every
+// request, so it lives on the service rather than on the per-request provider.
+type oidcClient struct {
+	issuer string
+	http   *http.Client
+	clock  clock.Clock
+	// tenantIssuer is set for multi-tenant issuers (Microsoft), whose discovery document names
+	// a per-tenant issuer, possibly a template such as ".../{tenantid}/v2.0", rather than the
+	// URL it is served from. Callers then check each token's issuer themselves.
+	tenantIssuer bool
+
+	mu        sync.Mutex
+	doc       *oidcDiscovery
+	docAt     time.Time
+	keys      map[string]any // kid -> *rsa.PublicKey or *ecdsa.PublicKey
+	keysAt    time.Time
+	refreshAt time.Time // last refetch forced by an unknown kid
+}
+
+func newOIDCClient(issuer string, clk clock.Clock) *oidcClient {
+	return &oidcClient{
+		issuer: strings.TrimSuffix(issuer, "/"),
+		http:   &http.Client{Timeout: 10 * time.Second},
+		clock:  clk,
+	}
+}
+
+// discovery returns the issuer's metadata, fetching it when missing or older than oidcMetadataTTL.
+func (c *oidcClient) discovery(ctx context.Context) (*oidcDiscovery, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.doc != nil && c.clock.Now().Sub(c.docAt) < oidcMetadataTTL {
+		return c.doc, nil
+	}
+
+	var doc oidcDiscovery
+	if err := c.getJSON(ctx, c.issuer+"/.well-known/openid-configuration", "", &doc); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	// The issuer must identify itself exactly as configured (OIDC Discovery §4.3).
+	if !c.tenantIssuer && strings.TrimSuffix(doc.Issuer, "/") != c.issuer {
+		return nil, fmt.Errorf("oidc discovery: issuer %q does not match configured %q", doc.Issuer, c.issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, errors.New("oidc discovery: document lacks authorization, token, or jwks endpoint")
+	}
+	c.doc, c.docAt = &doc, c.clock.Now()
+	return c.doc, nil
+}
+
+// key returns the signing key kid, refetching the key set when it is stale or the kid is
+// unknown (the issuer rotated keys), at most once per oidcKeyRefreshInterval.
+func (c *oidcClient) key(ctx context.Context, kid string) (any, error) {
+	doc, err := c.discovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock.Now()
+	key, ok := c.keys[kid]
+	stale := c.keys == nil || now.Sub(c.keysAt) >= oidcMetadataTTL
+	if ok && !stale {
+		return key, nil
+	}
+	if !stale && now.Sub(c.refreshAt) < oidcKeyRefreshInterval {
+		return nil, fmt.Errorf("oidc: unknown signing key %q", kid)
+	}
+
+	keys, err := c.fetchKeys(ctx, doc.JWKSURI)
+	if err != nil {
+		return nil, err
+	}
+	c.keys, c.keysAt, c.refreshAt = keys, now, now
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("oidc: unknown signing key %q", kid)
+}
+
+// fetchKeys downloads a JWKS and keeps its RSA and EC signature keys by kid.
+func (c *oidcClient) fetchKeys(ctx context.Context, url string) (map[string]any, error) {
+	var set struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := c.getJSON(ctx, url, "", &set); err != nil {
+		return nil, fmt.Errorf("oidc jwks: %w", err)
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		switch k.Kty {
+		case "RSA":
+			n, errN := decodeBigInt(k.N)
+			e, errE := decodeBigInt(k.E)
+			if errN != nil || errE != nil || !e.IsInt64() {
+				continue
+			}
+			keys[k.Kid] = &rsa.PublicKey{N: n, E: int(e.Int64())}
+		case "EC":
+			var curve elliptic.Curve
+			switch k.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				continue
+			}
+			x, errX := decodeBigInt(k.X)
+			y, errY := decodeBigInt(k.Y)
+			if errX != nil || errY != nil {
+				continue
+			}
+			keys[k.Kid] = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("oidc jwks: no usable signing keys")
+	}
+	return keys, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// verifyIDToken checks the id_token's signature, issuer, audience, and expiry and returns its claims.
+// An empty issuer skips the issuer check, for callers that check it per tenant.
+func (c *oidcClient) verifyIDToken(ctx context.Context, raw, issuer, clientID string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return c.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+		jwt.WithTimeFunc(c.clock.Now),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: invalid id_token: %w", err)
+	}
+	return claims, nil
+}
+
+// getJSON GETs url (with a bearer token when set) and decodes the JSON response into out.
+func (c *oidcClient) getJSON(ctx context.Context, url, bearer string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// oidcProvider adapts an oidcClient to the OAuth interface for one request.
+type oidcProvider struct {
+	client *oidcClient
+	config *oauth2.Config
+	claims config.OIDCConfig
+	doc    *oidcDiscovery
+}
+
+// newOIDCProvider resolves the issuer's endpoints (cached) and builds the per-request provider.
+func (s *service) newOIDCProvider(ctx context.Context) (OAuth, error) {
+	if s.oidc == nil {
+		return nil, ErrUnsupportedOAuthProvider.WithDetail("the oidc provider is not configured")
+	}
+	doc, err := s.oidc.discovery(ctx)
+	if err != nil {
+		return nil, ErrOAuthExchangeFailed.WithCause(err)
+	}
+	cfg := s.config.OIDC
+	return &oidcProvider{
+		client: s.oidc,
+		config: &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  doc.AuthorizationEndpoint,
+				TokenURL: doc.TokenEndpoint,
+			},
+			Scopes: strings.Fields(cfg.Scopes),
+		},
+		claims: cfg,
+		doc:    doc,
+	}, nil
+}
+
+func (o *oidcProvider) getOAuthConfig() *oauth2.Config {
+	return o.config
+}
+
+// getUserInfo verifies the id_token and maps its claims. Issuers that leave the email or name
+// out of the id_token (common with minimal scopes) are asked at their userinfo endpoint.
+func (o *oidcProvider) getUserInfo(ctx context.Context, token *oauth2.Token) (*oAuthUserInfo, error) {
+	idToken, ok := token.Extra("id_token").(string)
+	if !ok || idToken == "" {
+		return nil, errors.New("id_token not found in oidc token response")
+	}
+	claims, err := o.client.verifyIDToken(ctx, idToken, o.doc.Issuer, o.config.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	info := o.mapClaims(claims)
+	if info.ID == "" {
+		return nil, fmt.Errorf("oidc: claim %q missing from id_token", o.claims.SubjectClaim)
+	}
+	if (info.Email == "" || info.Name == "") && o.doc.UserinfoEndpoint != "" {
+		userinfo := map[string]any{}
+		if err := o.client.getJSON(ctx, o.doc.UserinfoEndpoint, token.AccessToken, &userinfo); err != nil {
+			return nil, fmt.Errorf("oidc userinfo: %w", err)
+		}
+		// The userinfo response must describe the same subject as the id_token (OIDC Core §5.3.2).
+		if sub, _ := userinfo["sub"].(string); sub != "" && sub != claims["sub"] {
+			return nil, errors.New("oidc userinfo: subject does not match id_token")
+		}
+		extra := o.mapClaims(userinfo)
+		if info.Email == "" {
+			info.Email = extra.Email
+		}
+		if info.Name == "" {
+			info.Name = extra.Name
+		}
+		// The verified flag only counts for the email it came with.
+		if info.Email == extra.Email && !info.EmailVerified {
+			info.EmailVerified = extra.EmailVerified
+		}
+	}
+	return info, nil
+}
+
+// mapClaims reads the configured subject, email, and name claims. Claim names may be dotted
+// paths into nested objects (e.g. "profile.email").
+func (o *oidcProvider) mapClaims(claims map[string]any) *oAuthUserInfo {
+	return &oAuthUserInfo{
+		ID:            claimString(claims, o.claims.SubjectClaim),
+		Email:         claimString(claims, o.claims.EmailClaim),
+		EmailVerified: claimBool(claims, o.claims.EmailVerifiedClaim),
+		Name:          claimString(claims, o.claims.NameClaim),
+	}
+}
+
+func claimString(claims map[string]any, path string) string {
+	s, _ := claimValue(claims, path).(string)
+	return s
+}
+
+// claimBool reads a boolean claim; some issuers send booleans as the strings "true"/"false".
+func claimBool(claims map[string]any, path string) bool {
+	return truthy(claimValue(claims, path))
+}
+
+func claimValue(claims map[string]any, path string) any {
+	if path == "" {
+		return nil
+	}
+	var v any = claims
+	for _, part := range strings.Split(path, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[part]
+	}
+	return v
+}
@@ -0,0 +1,123 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/clock"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+const (
+	testMicrosoftClientID = "11111111-2222-3333-4444-555555555555"
+	testTenantID          = "9a0b1c2d-0000-4000-8000-000000000001"
+)
+
+// newTestMicrosoftProvider serves a "common" tenant's discovery document and key set and
+// returns a provider using them, with the key that signs its id_tokens.
+func newTestMicrosoftProvider(t *testing.T) (*microsoftProvider, *rsa.PrivateKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oidcDiscovery{
+			Issuer:                "https://login.microsoftonline.com/{tenantid}/v2.0",
+			AuthorizationEndpoint: srv.URL + "/authorize",
+			TokenEndpoint:         srv.URL + "/token",
+			JWKSURI:               srv.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kid": "k1",
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+
+	keys := newOIDCClient(srv.URL, clock.OrReal(nil))
+	keys.tenantIssuer = true
+	return &microsoftProvider{config: &oauth2.Config{ClientID: testMicrosoftClientID}, keys: keys}, key
+}
+
+func signIDToken(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) *oauth2.Token {
+	t.Helper()
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tok.Header["kid"] = "k1"
+	raw, err := tok.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return (&oauth2.Token{AccessToken: "access"}).WithExtra(map[string]any{"id_token": raw})
+}
+
+func TestMicrosoftIDTokenVerified(t *testing.T) {
+	m, key := newTestMicrosoftProvider(t)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":      "https://login.microsoftonline.com/" + testTenantID + "/v2.0",
+			"tid":      testTenantID,
+			"aud":      testMicrosoftClientID,
+			"sub":      "pairwise-subject",
+			"email":    "ada@contoso.com",
+			"name":     "Ada Lovelace",
+			"xms_edov": true,
+			"iat":      now.Unix(),
+			"exp":      now.Add(time.Hour).Unix(),
+		}
+	}
+
+	info, err := m.getUserInfo(context.Background(), signIDToken(t, key, valid()))
+	if err != nil {
+		t.Fatalf("valid id_token: %v", err)
+	}
+	if info.ID != "pairwise-subject" || info.Email != "ada@contoso.com" || !info.EmailVerified || info.Name != "Ada Lovelace" {
+		t.Fatalf("user info = %+v", info)
+	}
+
+	tests := []struct {
+		name   string
+		key    *rsa.PrivateKey
+		change func(jwt.MapClaims)
+	}{
+		{"other audience", key, func(c jwt.MapClaims) { c["aud"] = "another-client" }},
+		{"expired", key, func(c jwt.MapClaims) { c["exp"] = now.Add(-time.Hour).Unix() }},
+		{"no expiry", key, func(c jwt.MapClaims) { delete(c, "exp") }},
+		{"issuer of another tenant", key, func(c jwt.MapClaims) {
+			c["iss"] = "https://login.microsoftonline.com/9a0b1c2d-0000-4000-8000-000000000002/v2.0"
+		}},
+		{"foreign issuer", key, func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com/" + testTenantID + "/v2.0" }},
+		{"no tenant", key, func(c jwt.MapClaims) { delete(c, "tid") }},
+		{"not signed by the tenant", other, func(jwt.MapClaims) {}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := valid()
+			tt.change(claims)
+			if _, err := m.getUserInfo(context.Background(), signIDToken(t, tt.key, claims)); err == nil {
+				t.Fatal("id_token accepted")
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// oauthTokenExpiryMargin refreshes tokens this long before they expire, so a token handed
+// to a caller does not expire mid-request.
+const oauthTokenExpiryMargin = time.Minute
+
+// saveOAuthTokens stores the tokens of an exchange on the identity's linked account when
+// token storage is enabled. It is best-effort: failures are logged and never fail a login.
+func (s *service) saveOAuthTokens(ctx context.Context, provider OAuthProvider, subject string, token *oauth2.Token) {
+	if s.tokenBox == nil || token == nil {
+		return
+	}
+	if err := s.storeOAuthTokens(ctx, provider, subject, token); err != nil {
+		s.logger.Error("failed to store oauth provider tokens", "error", err, "provider", provider)
+	}
+}
+
+func (s *service) storeOAuthTokens(ctx context.Context, provider OAuthProvider, subject string, token *oauth2.Token) error {
+	access, err := s.tokenBox.Seal(token.AccessToken)
+	if err != nil {
+		return err
+	}
+	refresh, err := s.tokenBox.Seal(token.RefreshToken)
+	if err != nil {
+		return err
+	}
+	var expiresAt *time.Time
+	if !token.Expiry.IsZero() {
+		expiry := token.Expiry
+		expiresAt = &expiry
+	}
+	return s.repo.UpdateOAuthAccountTokens(ctx, provider, subject, access, refresh, expiresAt)
+}
+
+// OAuthToken returns a provider access token for userID's linked account, refreshing it
+// first when it expires within oauthTokenExpiryMargin. Concurrent calls for the same account
+// share one refresh; the returned token is shared too and must not be modified.
+func (s *service) OAuthToken(ctx context.Context, userID string, provider OAuthProvider) (*oauth2.Token, error) {
+	if s.tokenBox == nil {
+		return nil, ErrOAuthTokenUnavailable.WithDetail("provider token storage is not enabled")
+	}
+	return s.tokenRefreshes.Do(ctx, userID+":"+string(provider), func(ctx context.Context) (*oauth2.Token, error) {
+		return s.loadOAuthToken(ctx, userID, provider)
+	})
+}
+
+func (s *service) loadOAuthToken(ctx context.Context, userID string, provider OAuthProvider) (*oauth2.Token, error) {
+	account, err := s.repo.FindOAuthAccountByUser(ctx, userID, provider)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNotFound.WithDetail("no " + string(provider) + " account is linked")
+		}
+		s.logger.Error("oauth token: find account failed", "error", err, "user_id", userID)
+		return nil, ErrInternal.WithCause(err)
+	}
+
+	access, errA := s.tokenBox.Open(account.AccessToken)
+	refresh, errR := s.tokenBox.Open(account.RefreshToken)
+	if err := errors.Join(errA, errR); err != nil {
+		// Sealed under a key that is no longer configured.
+		s.logger.Error("oauth token: stored tokens cannot be decrypted", "error", err, "user_id", userID, "provider", provider)
+		return nil, ErrOAuthTokenUnavailable.WithDetail("the stored provider tokens cannot be decrypted; link the account again").WithCause(err)
+	}
+
+	token := &oauth2.Token{AccessToken: access, RefreshToken: refresh, TokenType: "Bearer"}
+	if account.TokenExpiresAt != nil {
+		token.Expiry = *account.TokenExpiresAt
+	}
+	if access != "" && (token.Expiry.IsZero() || s.clock.Now().Add(oauthTokenExpiryMargin).Before(token.Expiry)) {
+		return token, nil
+	}
+	if refresh == "" {
+		return nil, ErrOAuthTokenUnavailable.WithDetail("the provider granted no refresh token; link the account again")
+	}
+
+	cfg, err := s.oauthRefreshConfig(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+	fresh, err := cfg.TokenSource(ctx, &oauth2.Token{RefreshToken: refresh}).Token()
+	if err != nil {
+		var re *oauth2.RetrieveError
+		if errors.As(err, &re) {
+			// Revoked or expired grant (invalid_grant): only a new consent helps.
+			s.logger.Warn("oauth token: provider refused refresh", "error", err, "user_id", userID, "provider", provider)
+			return nil, ErrOAuthTokenUnavailable.WithDetail("the provider refused to refresh the token; link the account again").WithCause(err)
+		}
+		s.logger.Error("oauth token: refresh failed", "error", err, "user_id", userID, "provider", provider)
+		return nil, ErrInternal.WithCause(err)
+	}
+	if fresh.RefreshToken == "" {
+		fresh.RefreshToken = refresh
+	}
+	if err := s.storeOAuthTokens(ctx, provider, account.ProviderUserID, fresh); err != nil {
+		// The token is still valid for this caller; the next call refreshes again.
+		s.logger.Error("oauth token: storing refreshed token failed", "error", err, "user_id", userID, "provider", provider)
+	}
+	return fresh, nil
+}
+
+// oauthRefreshConfig returns the provider's OAuth2 config, with Apple's per-request client
+// secret filled in.
+func (s *service) oauthRefreshConfig(ctx context.Context, provider OAuthProvider) (*oauth2.Config, error) {
+	p, err := s.newOAuthProvider(ctx, string(provider))
+	if err != nil {
+		return nil, err
+	}
+	cfg := *p.getOAuthConfig()
+	if apple, ok := p.(*appleProvider); ok {
+		secret, err := apple.generateAppleClientSecret()
+		if err != nil {
+			return nil, ErrInternal.WithCause(err)
+		}
+		cfg.ClientSecret = secret
+		cfg.Endpoint.AuthStyle = oauth2.AuthStyleInParams
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,139 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/jobs"
+	"github.com/delordemm1/go-api-simple-starter/internal/notification"
+	"github.com/delordemm1/go-api-simple-starter/internal/notification/templates"
+)
+
+// jobOnboardingEmail sends one step of the onboarding sequence.
+const jobOnboardingEmail = "user.onboarding_email"
+
+// onboardingStep is one email of the onboarding sequence, sent Delay after signup.
+type onboardingStep struct {
+	TemplateID string
+	Delay      time.Duration
+}
+
+// onboardingJob is the payload of a jobOnboardingEmail job. TemplateID is kept so a step
+// whose template changed position after a config change is recognised and skipped.
+type onboardingJob struct {
+	UserID     string `json:"userId"`
+	Step       int    `json:"step"` // 0-based index into the sequence
+	TemplateID string `json:"templateId"`
+}
+
+// parseOnboardingSequence parses ONBOARDING_SEQUENCE (see config.OnboardingConfig).
+func parseOnboardingSequence(spec string) ([]onboardingStep, error) {
+	var steps []onboardingStep
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, delay, ok := strings.Cut(part, "@")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("onboarding step %q: want <template id>@<delay>", part)
+		}
+		if _, known := templates.Sample(id); !known {
+			return nil, fmt.Errorf("onboarding step %q: unknown template %q", part, id)
+		}
+		d, err := parseOnboardingDelay(delay)
+		if err != nil {
+			return nil, fmt.Errorf("onboarding step %q: %w", part, err)
+		}
+		steps = append(steps, onboardingStep{TemplateID: id, Delay: d})
+	}
+	return steps, nil
+}
+
+// parseOnboardingDelay is time.ParseDuration plus whole days ("7d").
+func parseOnboardingDelay(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid delay %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid delay %q", s)
+	}
+	return d, nil
+}
+
+// scheduleOnboarding enqueues every step of the sequence for a user who just signed up.
+// Steps are never dequeued; runOnboardingStep skips them once the user unsubscribes, is
+// deleted, or is suspended. Failures are logged: onboarding must not fail a signup.
+func (s *service) scheduleOnboarding(ctx context.Context, user *User) {
+	if s.jobs == nil || len(s.onboarding) == 0 || user.Email == "" {
+		return
+	}
+	now := s.clock.Now()
+	for i, step := range s.onboarding {
+		payload := onboardingJob{UserID: user.ID, Step: i, TemplateID: step.TemplateID}
+		if err := jobs.Enqueue(ctx, s.jobs, jobOnboardingEmail, payload, now.Add(step.Delay)); err != nil {
+			s.logger.Error("failed to schedule onboarding email", "error", err, "user_id", user.ID, "template", step.TemplateID)
+		}
+	}
+}
+
+// runOnboardingStep is the jobOnboardingEmail handler.
+func (s *service) runOnboardingStep(ctx context.Context, job onboardingJob) error {
+	if job.Step < 0 || job.Step >= len(s.onboarding) || s.onboarding[job.Step].TemplateID != job.TemplateID {
+		s.logger.Info("skipping onboarding email no longer in the sequence", "user_id", job.UserID, "template", job.TemplateID)
+		return nil
+	}
+
+	user, err := s.repo.FindByID(ctx, job.UserID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.Email == "" || user.DeletedAt != nil || user.AnonymizedAt != nil || user.SuspendedAt != nil || user.OnboardingUnsubscribedAt != nil {
+		return nil
+	}
+
+	data := templates.OnboardingData{
+		FirstName:    user.FirstName,
+		Step:         job.Step + 1,
+		SignedUpAt:   user.CreatedAt,
+		Locale:       recipientLocale(user),
+		SupportEmail: s.config.SMTP.From,
+	}
+	return notification.SendTemplate(ctx, s.notification, templates.Expect[templates.OnboardingData](job.TemplateID), user.Email, []notification.Channel{notification.ChannelEmail}, notification.PriorityLow, data)
+}
+
+// UnsubscribeOnboarding stops the remaining onboarding emails for userID. Repeated calls are no-ops.
+func (s *service) UnsubscribeOnboarding(ctx context.Context, userID string) error {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrNotFound.WithCause(err)
+		}
+		s.logger.Error("unsubscribe onboarding: find user failed", "error", err, "user_id", userID)
+		return ErrInternal.WithCause(err)
+	}
+	if user.OnboardingUnsubscribedAt != nil {
+		return nil
+	}
+
+	now := s.clock.Now()
+	user.OnboardingUnsubscribedAt = &now
+	if err := s.repo.Update(ctx, user); err != nil {
+		s.logger.Error("unsubscribe onboarding: update user failed", "error", err, "user_id", userID)
+		return ErrInternal.WithCause(err)
+	}
+	s.logger.Info("user unsubscribed from onboarding emails", "user_id", userID)
+	return nil
+}
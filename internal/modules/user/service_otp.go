@@ -0,0 +1,129 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/config"
+	"github.com/delordemm1/go-api-simple-starter/internal/securerand"
+)
+
+// OTP alphabets accepted in VerificationConfig.CodeAlphabet.
+const (
+	OTPAlphabetNumeric = "numeric"
+	OTPAlphabetBase32  = "base32"
+)
+
+const (
+	numericAlphabet = securerand.Digits
+	// crockfordAlphabet excludes I, L, O and U to avoid transcription mistakes.
+	crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+)
+
+// otpPolicy returns the effective OTP policy for purpose with built-in defaults applied.
+func (s *service) otpPolicy(purpose VerificationPurpose) config.VerificationPolicy {
+	p := s.config.Verification.For(string(purpose))
+	if p.TTLMinutes <= 0 {
+		p.TTLMinutes = 10
+	}
+	if p.ResendCooldownSeconds <= 0 {
+		p.ResendCooldownSeconds = 60
+	}
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 5
+	}
+	if p.CodeLength <= 0 {
+		p.CodeLength = 6
+	}
+	if p.CodeAlphabet != OTPAlphabetBase32 {
+		p.CodeAlphabet = OTPAlphabetNumeric
+	}
+	return p
+}
+
+// otpExpiresAt is when a code for purpose issued now stops working, for display in notifications.
+func (s *service) otpExpiresAt(purpose VerificationPurpose) time.Time {
+	return s.clock.Now().Add(time.Duration(s.otpPolicy(purpose).TTLMinutes) * time.Minute)
+}
+
+func otpCharset(alphabet string) string {
+	if alphabet == OTPAlphabetBase32 {
+		return crockfordAlphabet
+	}
+	return numericAlphabet
+}
+
+// generateCode returns a uniformly random code of n characters from the given alphabet.
+func generateCode(alphabet string, n int) (string, error) {
+	if n <= 0 {
+		n = 6
+	}
+	return securerand.String(otpCharset(alphabet), n)
+}
+
+// normalizeCode canonicalizes user input before hashing: separators are removed and,
+// for base32 codes, input is upper-cased with the Crockford aliases (I/L -> 1, O -> 0) resolved.
+// It returns "" when the result does not match the policy's length and alphabet.
+func normalizeCode(p config.VerificationPolicy, code string) string {
+	code = strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code))
+	if p.CodeAlphabet == OTPAlphabetBase32 {
+		code = strings.NewReplacer("I", "1", "L", "1", "O", "0").Replace(strings.ToUpper(code))
+	}
+	if len(code) != p.CodeLength {
+		return ""
+	}
+	charset := otpCharset(p.CodeAlphabet)
+	for i := 0; i < len(code); i++ {
+		if strings.IndexByte(charset, code[i]) < 0 {
+			return ""
+		}
+	}
+	return code
+}
+
+// checkVerificationCode validates code against the user's active code for purpose and consumes it
+// on success. Mismatches count against the code's attempt limit; once it is reached the code is
+// refused outright.
+func (s *service) checkVerificationCode(ctx context.Context, userID string, purpose VerificationPurpose, code string) (err error) {
+	defer func() { s.recordOTPAttempt(err) }()
+	code = normalizeCode(s.otpPolicy(purpose), code)
+	if code == "" {
+		return ErrInvalidOTP
+	}
+
+	vc, err := s.repo.GetActiveVerificationCodeByUser(ctx, userID, purpose, purpose.channel())
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrInvalidOTP
+		}
+		s.logger.Error("check code: get active code failed", "error", err, "purpose", purpose)
+		return ErrInternal.WithCause(err)
+	}
+	if s.clock.Now().After(vc.ExpiresAt) {
+		return ErrInvalidOTP
+	}
+	// A code whose attempts are used up stays dead, even for the right guess.
+	if vc.MaxAttempts > 0 && vc.Attempts >= vc.MaxAttempts {
+		return ErrTooManyAttempts
+	}
+
+	if !securerand.Equal(hashToken(code), vc.CodeHash) {
+		attempts, max, incErr := s.repo.IncrementVerificationAttempt(ctx, vc.ID)
+		if incErr != nil && !errors.Is(incErr, ErrNotFound) {
+			s.logger.Error("check code: increment attempts failed", "error", incErr, "purpose", purpose)
+			return ErrInternal.WithCause(incErr)
+		}
+		if attempts >= max {
+			return ErrTooManyAttempts
+		}
+		return ErrInvalidOTP
+	}
+
+	if err := s.repo.ConsumeVerificationCode(ctx, vc.ID); err != nil && !errors.Is(err, ErrNotFound) {
+		s.logger.Error("check code: consume code failed", "error", err, "purpose", purpose)
+		return ErrInternal.WithCause(err)
+	}
+	return nil
+}
@@ -2,15 +2,19 @@ package user
 
 import (
 	"context"
-	"crypto/subtle"
 	"errors"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
 	"github.com/delordemm1/go-api-simple-starter/internal/notification"
 	"github.com/delordemm1/go-api-simple-starter/internal/notification/templates"
+	"github.com/delordemm1/go-api-simple-starter/internal/securerand"
+	"github.com/delordemm1/go-api-simple-starter/internal/siem"
 )
 
-// InitiatePasswordReset sends a 6-digit reset code to the user's email if it exists.
+// InitiatePasswordReset sends a reset code to the user's email if it exists.
 // Always returns nil to avoid email enumeration.
 func (s *service) InitiatePasswordReset(ctx context.Context, email string) error {
 	// 1. Find user by email.
@@ -25,7 +29,7 @@ func (s *service) InitiatePasswordReset(ctx context.Context, email string) error
 		return ErrInternal.WithCause(err)
 	}
 
-	// 2. Create or refresh a 6-digit code with TTL & cooldown.
+	// 2. Create or refresh a reset code with TTL & cooldown.
 	code, err := s.createOrRefreshVerificationCode(ctx, user, user.Email, VerificationPurposePasswordReset, VerificationChannelEmail)
 	if err != nil {
 		if errors.Is(err, ErrResendTooSoon) {
@@ -35,24 +39,52 @@ func (s *service) InitiatePasswordReset(ctx context.Context, email string) error
 		return err
 	}
 
-	// 3. Send via templates.
+	// 3. With deep-link delivery configured, issue the action token now and link to it.
+	var link string
+	if tmpl := s.config.ResetToken.LinkTemplate; tmpl != "" {
+		rawToken, err := s.issuePasswordResetToken(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		link = passwordResetLink(tmpl, rawToken, user.Email)
+	}
+
+	// 4. Send via templates.
 	go func() {
+		ctx := contextx.Detach(ctx) // the request may finish before the send
 		data := templates.PasswordResetCodeData{
-			FirstName:    user.FirstName,
-			Code:         code,
-			SupportEmail: s.config.SMTP.From,
+			FirstName:                 user.FirstName,
+			Code:                      code,
+			ExpiresInMinutes:          s.otpPolicy(VerificationPurposePasswordReset).TTLMinutes,
+			ExpiresAt:                 s.otpExpiresAt(VerificationPurposePasswordReset),
+			Locale:                    recipientLocale(user),
+			ResetLink:                 link,
+			ResetLinkExpiresInMinutes: s.resetTokenTTLMinutes(),
+			SupportEmail:              s.config.SMTP.From,
 		}
-		if err := notification.SendTemplate(ctx, s.notification, templates.PasswordResetCode, user.Email, []notification.Channel{notification.ChannelEmail}, notification.PriorityHigh, data); err != nil {
+		receipt, err := notification.DispatchTemplate(ctx, s.notification, templates.PasswordResetCode, user.Email, []notification.Channel{notification.ChannelEmail}, notification.PriorityHigh, data)
+		if err != nil {
 			s.logger.Error("failed to send password reset code", "error", err, "user_id", user.ID)
+			return
+		}
+		// The request has returned by now; ctx is detached, so this waits for the provider's verdict.
+		results, _ := receipt.Wait(ctx)
+		for _, r := range results {
+			if r.Status == notification.OutboxStatusFailed {
+				s.logger.Error("password reset code rejected by provider", "error", r.Err, "user_id", user.ID,
+					"channel", r.Channel, "dispatch_id", r.ID, "attempts", r.Attempts)
+			}
 		}
 	}()
 
 	return nil
 }
 
-// VerifyPasswordResetCode validates the 6-digit code and issues a short-lived internal reset token.
+// VerifyPasswordResetCode validates the reset code and issues a short-lived internal reset token.
 // The raw token is returned to the client; only its hash is stored.
-func (s *service) VerifyPasswordResetCode(ctx context.Context, email, code string) (string, error) {
+func (s *service) VerifyPasswordResetCode(ctx context.Context, email, code string) (_ string, err error) {
+	defer func() { s.recordOTPAttempt(err) }()
+	code = normalizeCode(s.otpPolicy(VerificationPurposePasswordReset), code)
 	if code == "" {
 		return "", ErrInvalidOTP
 	}
@@ -78,13 +110,13 @@ func (s *service) VerifyPasswordResetCode(ctx context.Context, email, code strin
 	}
 
 	// 3) TTL check
-	if time.Now().After(vc.ExpiresAt) {
+	if s.clock.Now().After(vc.ExpiresAt) {
 		return "", ErrInvalidOTP
 	}
 
 	// 4) Constant-time compare
 	hashed := hashToken(code)
-	if subtle.ConstantTimeCompare([]byte(hashed), []byte(vc.CodeHash)) != 1 {
+	if !securerand.Equal(hashed, vc.CodeHash) {
 		attempts, max, incErr := s.repo.IncrementVerificationAttempt(ctx, vc.ID)
 		if incErr != nil && !errors.Is(incErr, ErrNotFound) {
 			s.logger.Error("verify reset code: increment attempts failed", "error", incErr)
@@ -103,42 +135,58 @@ func (s *service) VerifyPasswordResetCode(ctx context.Context, email, code strin
 	}
 
 	// 6) Issue internal action token (short-lived)
-	rawToken, err := generateSecureToken(32)
+	return s.issuePasswordResetToken(ctx, user.ID)
+}
+
+// issuePasswordResetToken creates the short-lived action token accepted by FinalizePasswordReset,
+// replacing any previous one. The raw token is returned; only its hash is stored.
+func (s *service) issuePasswordResetToken(ctx context.Context, userID string) (string, error) {
+	rawToken, err := securerand.Token(32)
 	if err != nil {
-		s.logger.Error("verify reset code: generate action token failed", "error", err)
+		s.logger.Error("issue reset token: generate action token failed", "error", err)
 		return "", ErrInternal.WithCause(err)
 	}
-	tokenHash := hashToken(rawToken)
-
-	ttlMin := s.config.ResetToken.TTLMinutes
-	if ttlMin <= 0 {
-		ttlMin = 15
-	}
-	expiresAt := time.Now().Add(time.Duration(ttlMin) * time.Minute)
 
 	// Ensure only one active token per user/purpose
-	if err := s.repo.DeleteUserActionTokensByPurpose(ctx, user.ID, "password_reset"); err != nil {
-		s.logger.Warn("verify reset code: cleanup old action tokens failed", "error", err)
+	if err := s.repo.DeleteUserActionTokensByPurpose(ctx, userID, "password_reset"); err != nil {
+		s.logger.Warn("issue reset token: cleanup old action tokens failed", "error", err)
 	}
 
+	now := s.clock.Now()
 	at := &ActionToken{
-		UserID:    user.ID,
+		UserID:    userID,
 		Purpose:   "password_reset",
-		TokenHash: tokenHash,
-		ExpiresAt: expiresAt,
-		ConsumedAt: nil,
-		CreatedAt: time.Now(),
+		TokenHash: hashToken(rawToken),
+		ExpiresAt: now.Add(time.Duration(s.resetTokenTTLMinutes()) * time.Minute),
+		CreatedAt: now,
 	}
 	if err := s.repo.CreateActionToken(ctx, at); err != nil {
-		s.logger.Error("verify reset code: create action token failed", "error", err)
+		s.logger.Error("issue reset token: create action token failed", "error", err)
 		return "", ErrInternal.WithCause(err)
 	}
-
 	return rawToken, nil
 }
 
+func (s *service) resetTokenTTLMinutes() int {
+	if ttl := s.config.ResetToken.TTLMinutes; ttl > 0 {
+		return ttl
+	}
+	return 15
+}
+
+// passwordResetLink fills the configured deep-link template. Values are URL-escaped so the
+// link stays valid whether the placeholder sits in a path segment, query, or fragment,
+// which keeps https links eligible for universal/app link handling.
+func passwordResetLink(tmpl, rawToken, email string) string {
+	return strings.NewReplacer(
+		"{token}", url.QueryEscape(rawToken),
+		"{email}", url.QueryEscape(email),
+	).Replace(tmpl)
+}
+
 // FinalizePasswordReset accepts an internal reset token and the new password.
-// It validates and consumes the token, then updates the user's password.
+// It validates and consumes the token, then updates the user's password and signs every
+// session out.
 func (s *service) FinalizePasswordReset(ctx context.Context, resetToken, newPassword string) error {
 	if resetToken == "" {
 		return ErrInvalidResetToken
@@ -158,12 +206,27 @@ func (s *service) FinalizePasswordReset(ctx context.Context, resetToken, newPass
 	}
 
 	// Expiry check
-	if time.Now().After(at.ExpiresAt) {
+	if s.clock.Now().After(at.ExpiresAt) {
 		return ErrInvalidResetToken
 	}
 
+	// Reject passwords known from data breaches
+	if err := s.checkBreachedPassword(ctx, newPassword); err != nil {
+		return err
+	}
+
+	// Reject recently used passwords
+	user, err := s.repo.FindByID(ctx, at.UserID)
+	if err != nil {
+		s.logger.Error("finalize reset: find user failed", "error", err)
+		return ErrInternal.WithCause(err)
+	}
+	if err := s.checkPasswordReuse(ctx, user.ID, user.PasswordHash, newPassword); err != nil {
+		return err
+	}
+
 	// Hash new password
-	newPasswordHash, err := hashPassword(newPassword)
+	newPasswordHash, err := s.hashPassword(newPassword)
 	if err != nil {
 		s.logger.Error("finalize reset: hash password failed", "error", err)
 		return ErrInternal.WithCause(err)
@@ -174,12 +237,101 @@ func (s *service) FinalizePasswordReset(ctx context.Context, resetToken, newPass
 		s.logger.Error("finalize reset: update password failed", "error", err)
 		return ErrInternal.WithCause(err)
 	}
+	s.rememberPassword(ctx, at.UserID, newPasswordHash)
 
 	// Consume the action token
 	if err := s.repo.ConsumeActionToken(ctx, at.ID); err != nil && !errors.Is(err, ErrNotFound) {
 		s.logger.Warn("finalize reset: consume action token failed", "error", err)
 	}
 
+	// Whoever knew the old password may still hold a session; the user signs in again.
+	if err := s.revokeAllSessions(ctx, at.UserID, "", "password_reset"); err != nil {
+		s.logger.Error("finalize reset: revoke sessions failed", "error", err, "user_id", at.UserID)
+	}
+
+	s.recordActivity(ctx, at.UserID, ActivityPasswordReset, nil)
+
 	s.logger.Info("user password has been reset successfully", "user_id", at.UserID)
 	return nil
-}
\ No newline at end of file
+}
+// ChangePassword replaces the password of an authenticated user after checking the current one.
+// Every other session is revoked and a notice is emailed. The caller's session is kept under a
+// new token, which is returned; if it cannot be rotated, every session is revoked instead.
+func (s *service) ChangePassword(ctx context.Context, userID, sessionID, currentPassword, newPassword string) (string, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return "", ErrNotFound.WithCause(err)
+		}
+		s.logger.Error("change password: find user failed", "error", err, "user_id", userID)
+		return "", ErrInternal.WithCause(err)
+	}
+	// Accounts without a password (OAuth or phone only) set one through password reset.
+	if user.PasswordHash == "" || !s.checkPasswordHash(currentPassword, user.PasswordHash) {
+		return "", ErrInvalidCredentials.WithDetail("current password is incorrect")
+	}
+	// A forced reset means the current password may be known to an attacker.
+	if user.PasswordResetRequired {
+		return "", ErrPasswordResetRequired
+	}
+
+	if err := s.checkBreachedPassword(ctx, newPassword); err != nil {
+		return "", err
+	}
+	if err := s.checkPasswordReuse(ctx, user.ID, user.PasswordHash, newPassword); err != nil {
+		return "", err
+	}
+
+	newPasswordHash, err := s.hashPassword(newPassword)
+	if err != nil {
+		s.logger.Error("change password: hash password failed", "error", err)
+		return "", ErrInternal.WithCause(err)
+	}
+	if err := s.repo.UpdatePassword(ctx, user.ID, newPasswordHash); err != nil {
+		s.logger.Error("change password: update password failed", "error", err, "user_id", user.ID)
+		return "", ErrInternal.WithCause(err)
+	}
+	s.rememberPassword(ctx, user.ID, newPasswordHash)
+	s.recordActivity(ctx, user.ID, ActivityPasswordChanged, nil)
+
+	if user.Email != "" {
+		go func() {
+			ctx := contextx.Detach(ctx) // the request may finish before the send
+			data := templates.PasswordChangedData{
+				FirstName:    user.FirstName,
+				ChangedAt:    s.clock.Now(),
+				Locale:       recipientLocale(user),
+				SupportEmail: s.config.SMTP.From,
+			}
+			if err := notification.SendTemplate(ctx, s.notification, templates.PasswordChanged, user.Email, []notification.Channel{notification.ChannelEmail}, notification.PriorityHigh, data); err != nil {
+				s.logger.Error("failed to send password changed notice", "error", err, "user_id", user.ID)
+			}
+		}()
+	}
+
+	s.logger.Info("user changed password", "user_id", user.ID)
+
+	// The password is changed by now. The caller's session goes on under a new token; when the
+	// rotation fails, every session of the user is signed out instead, so no token issued before
+	// the change outlives it.
+	newSessionID, err := s.rotateSession(ctx, user.ID, sessionID, "password_changed")
+	if err != nil {
+		if rerr := s.revokeAllSessions(ctx, user.ID, user.ID, "password_changed"); rerr != nil {
+			s.logger.Error("change password: revoke sessions failed", "error", rerr, "user_id", user.ID)
+		}
+		return "", err
+	}
+	n, err := s.sessions.DeleteOthersForUser(ctx, user.ID, newSessionID)
+	if err != nil {
+		// The password already changed; stale sessions still expire on their own.
+		s.logger.Error("change password: revoke other sessions failed", "error", err, "user_id", user.ID)
+	} else {
+		s.events.Publish(ctx, siem.Event{
+			Type:     securityEventSessionsRevoked,
+			UserID:   user.ID,
+			ActorID:  user.ID,
+			Metadata: map[string]any{"reason": "password_changed", "count": n},
+		})
+	}
+	return newSessionID, nil
+}
@@ -0,0 +1,59 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+)
+
+// PasswordHasher hashes new passwords and verifies stored hashes (implemented by passhash.Hasher).
+// Verify must accept hashes of every algorithm the service has ever stored; NeedsRehash reports
+// the ones not made with the current algorithm and parameters, which are replaced on sign-in.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password, hash string) bool
+	NeedsRehash(hash string) bool
+}
+
+// hashPassword hashes a plaintext password with the configured algorithm.
+func (s *service) hashPassword(password string) (string, error) {
+	return s.hasher.Hash(password)
+}
+
+// checkPasswordHash compares a plaintext password with a stored hash. An empty hash (unknown
+// user, or an account without a password) never matches, but is checked against a dummy hash so
+// it takes as long as a real comparison.
+func (s *service) checkPasswordHash(password, hash string) bool {
+	if hash == "" {
+		_ = s.hasher.Verify(password, s.dummyHash())
+		return false
+	}
+	return s.hasher.Verify(password, hash)
+}
+
+// newDummyHash returns a hash of a random password made the way hasher makes new hashes.
+func newDummyHash(hasher PasswordHasher) func() string {
+	return func() string {
+		h, _ := hasher.Hash(rand.Text())
+		return h
+	}
+}
+
+// upgradePasswordHash replaces user's stored hash, just verified against password, when it was
+// made with another algorithm or weaker parameters than new hashes are, so existing hashes
+// migrate as users sign in. Failures are logged; the old hash keeps working.
+func (s *service) upgradePasswordHash(ctx context.Context, user *User, password string) {
+	if !s.hasher.NeedsRehash(user.PasswordHash) {
+		return
+	}
+	newHash, err := s.hashPassword(password)
+	if err != nil {
+		s.logger.Warn("failed to rehash password", "error", err, "user_id", user.ID)
+		return
+	}
+	if err := s.repo.RehashPassword(ctx, user.ID, user.PasswordHash, newHash); err != nil {
+		s.logger.Warn("failed to store rehashed password", "error", err, "user_id", user.ID)
+		return
+	}
+	user.PasswordHash = newHash
+	s.logger.Info("password hash upgraded", "user_id", user.ID)
+}
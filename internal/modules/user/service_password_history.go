@@ -0,0 +1,46 @@
+package user
+
+import (
+	"context"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/validation"
+)
+
+// checkPasswordReuse rejects newPassword when it matches the user's current password or one of
+// their last PASSWORD_HISTORY_SIZE passwords, with a validation problem on the "password" field.
+// currentHash covers accounts whose password predates the history table.
+func (s *service) checkPasswordReuse(ctx context.Context, userID, currentHash, newPassword string) error {
+	size := s.config.PasswordHistory.Size
+	if size <= 0 {
+		return nil
+	}
+
+	hashes, err := s.repo.ListPasswordHistory(ctx, userID, size)
+	if err != nil {
+		s.logger.Error("failed to load password history", "error", err, "user_id", userID)
+		return ErrInternal.WithCause(err)
+	}
+	if currentHash != "" {
+		hashes = append(hashes, currentHash)
+	}
+	for _, h := range hashes {
+		if s.checkPasswordHash(newPassword, h) {
+			return validation.NewFieldError(validation.FieldErrors{
+				"password": {"matches a recently used password; choose a different password"},
+			})
+		}
+	}
+	return nil
+}
+
+// rememberPassword adds a newly set password hash to the user's history. Failures are logged
+// only: the password change itself already succeeded.
+func (s *service) rememberPassword(ctx context.Context, userID, passwordHash string) {
+	size := s.config.PasswordHistory.Size
+	if size <= 0 {
+		return
+	}
+	if err := s.repo.AddPasswordHistory(ctx, userID, passwordHash, size); err != nil {
+		s.logger.Warn("failed to record password history", "error", err, "user_id", userID)
+	}
+}
@@ -0,0 +1,187 @@
+package user
+
+import (
+	"context"
+	"errors"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/ipreputation"
+	"github.com/delordemm1/go-api-simple-starter/internal/notification"
+	"github.com/delordemm1/go-api-simple-starter/internal/notification/templates"
+	"github.com/google/uuid"
+)
+
+// RegisterWithPhone creates a phone-only account (no email or password) and texts it a sign-in
+// code. Re-registering an unverified phone updates the names and resends the code; the phone is
+// verified by the first successful ConfirmPhoneLogin.
+func (s *service) RegisterWithPhone(ctx context.Context, firstName, lastName, phone string) (*User, error) {
+	if s.screenIP(ctx, ipreputation.EndpointRegister) == ipreputation.ActionBlock {
+		return nil, ErrRequestBlocked
+	}
+
+	existing, err := s.repo.FindByPhone(ctx, phone)
+	if err == nil {
+		if existing.DeletedAt != nil {
+			if s.isRestorable(existing) {
+				return nil, ErrAccountPendingDeletion
+			}
+			return nil, ErrPhoneExists
+		}
+		if existing.PhoneVerified {
+			return nil, ErrPhoneExists
+		}
+		if existing.FirstName != firstName || existing.LastName != lastName {
+			existing.FirstName, existing.LastName = firstName, lastName
+			if uerr := s.repo.Update(ctx, existing); uerr != nil {
+				s.logger.Error("failed to update unverified phone user names", "error", uerr, "user_id", existing.ID)
+				return nil, ErrInternal.WithCause(uerr)
+			}
+		}
+		s.sendPhoneLoginCode(ctx, existing)
+		return existing, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		s.logger.Error("failed to check existing user by phone", "error", err)
+		return nil, ErrInternal.WithCause(err)
+	}
+
+	newUserID, err := uuid.NewV7()
+	if err != nil {
+		s.logger.Error("failed to generate user ID", "error", err)
+		return nil, ErrInternal.WithCause(err)
+	}
+	newUser := &User{
+		ID:        newUserID.String(),
+		FirstName: firstName,
+		LastName:  lastName,
+		Phone:     &phone,
+	}
+	s.acceptCurrentTerms(newUser)
+	// The unique index on phone is the source of truth for concurrent registrations.
+	if err := s.repo.Create(ctx, newUser); err != nil {
+		if errors.Is(err, ErrPhoneExists) {
+			return nil, ErrPhoneExists
+		}
+		s.logger.Error("failed to create phone user", "error", err)
+		return nil, ErrInternal.WithCause(err)
+	}
+
+	s.sendPhoneLoginCode(ctx, newUser)
+	s.logger.Info("user registered by phone", "user_id", newUser.ID)
+	return newUser, nil
+}
+
+// RequestPhoneLoginCode texts a sign-in code to the account registered with phone.
+// Unknown and deleted accounts are silently ignored to avoid enumeration.
+func (s *service) RequestPhoneLoginCode(ctx context.Context, phone string) error {
+	if s.screenIP(ctx, ipreputation.EndpointLogin) == ipreputation.ActionBlock {
+		return ErrRequestBlocked
+	}
+
+	user, err := s.repo.FindByPhone(ctx, phone)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		s.logger.Error("request phone code: find user failed", "error", err)
+		return ErrInternal.WithCause(err)
+	}
+	if user.DeletedAt != nil {
+		return nil
+	}
+	return s.issuePhoneLoginCode(ctx, user)
+}
+
+// ConfirmPhoneLogin validates the SMS code, marks the phone verified, and signs the user in.
+func (s *service) ConfirmPhoneLogin(ctx context.Context, phone, code string) (string, error) {
+	if s.screenIP(ctx, ipreputation.EndpointLogin) == ipreputation.ActionBlock {
+		s.recordFailedPhoneLogin(ctx, phone, "", "ip_blocked")
+		return "", ErrRequestBlocked
+	}
+
+	user, err := s.repo.FindByPhone(ctx, phone)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			s.recordFailedPhoneLogin(ctx, phone, "", "unknown_phone")
+			return "", ErrInvalidOTP
+		}
+		s.logger.Error("confirm phone login: find user failed", "error", err)
+		return "", ErrInternal.WithCause(err)
+	}
+
+	if err := s.checkVerificationCode(ctx, user.ID, VerificationPurposePhoneLogin, code); err != nil {
+		if errors.Is(err, ErrInvalidOTP) || errors.Is(err, ErrTooManyAttempts) {
+			s.recordFailedPhoneLogin(ctx, phone, user.ID, "invalid_code")
+		}
+		return "", err
+	}
+
+	if user.DeletedAt != nil {
+		s.recordFailedPhoneLogin(ctx, phone, user.ID, "account_deleted")
+		if s.isRestorable(user) {
+			return "", ErrAccountPendingDeletion
+		}
+		return "", ErrInvalidOTP
+	}
+	if user.SuspendedAt != nil {
+		s.recordFailedPhoneLogin(ctx, phone, user.ID, "account_suspended")
+		return "", ErrAccountSuspended
+	}
+
+	if !user.PhoneVerified {
+		user.PhoneVerified = true
+		if err := s.repo.Update(ctx, user); err != nil {
+			s.logger.Error("confirm phone login: update user failed", "error", err, "user_id", user.ID)
+			return "", ErrInternal.WithCause(err)
+		}
+	}
+
+	sessionID, err := s.sessions.CreateAuthSession(ctx, user.ID, sessionMetadata(ctx, "phone"))
+	if err != nil {
+		s.logger.Error("failed to create auth session", "error", err)
+		return "", sessionIssueError(err)
+	}
+	s.recordLogin(ctx, user.ID, "phone")
+
+	s.logger.Info("user logged in by phone", "user_id", user.ID)
+	return sessionID, nil
+}
+
+// sendPhoneLoginCode issues a code for a registration, logging (not returning) failures such
+// as an active resend cooldown so registration itself still succeeds.
+func (s *service) sendPhoneLoginCode(ctx context.Context, user *User) {
+	if err := s.issuePhoneLoginCode(ctx, user); err != nil {
+		if errors.Is(err, ErrResendTooSoon) {
+			s.logger.Info("phone login code resend cooldown active", "user_id", user.ID)
+			return
+		}
+		s.logger.Error("failed to issue phone login code", "error", err, "user_id", user.ID)
+	}
+}
+
+// issuePhoneLoginCode creates or refreshes the user's phone login code and texts it (fire-and-forget).
+func (s *service) issuePhoneLoginCode(ctx context.Context, user *User) error {
+	if user.Phone == nil {
+		return ErrInternal.WithDetail("user has no phone number")
+	}
+	phone := *user.Phone
+	code, err := s.createOrRefreshVerificationCode(ctx, user, phone, VerificationPurposePhoneLogin, VerificationChannelSMS)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		ctx := contextx.Detach(ctx) // the request may finish before the send
+		data := templates.PhoneLoginCodeData{
+			FirstName:        user.FirstName,
+			Code:             code,
+			ExpiresInMinutes: s.otpPolicy(VerificationPurposePhoneLogin).TTLMinutes,
+			ExpiresAt:        s.otpExpiresAt(VerificationPurposePhoneLogin),
+			Locale:           recipientLocale(user),
+		}
+		if err := notification.SendTemplate(ctx, s.notification, templates.PhoneLoginCode, phone, []notification.Channel{notification.ChannelSMS}, notification.PriorityHigh, data); err != nil {
+			s.logger.Error("failed to send phone login code", "error", err, "user_id", user.ID)
+		}
+	}()
+	return nil
+}
@@ -3,7 +3,8 @@ package user
 import (
 	"context"
 	"errors"
-	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/notification/templates"
 )
 
 // UpdateProfileInput defines the updatable fields for a user's profile.
@@ -12,11 +13,43 @@ import (
 type UpdateProfileInput struct {
 	FirstName *string
 	LastName  *string
+	Locale    *string // BCP 47 tag; "" resets to the default
+	TimeZone  *string // IANA zone; "" resets to UTC
+}
+
+// SecurityStatus summarizes how a user can sign in, for rendering security settings.
+type SecurityStatus struct {
+	// AuthProviders lists the sign-in methods: "password" when one is set, then the
+	// linked OAuth providers, oldest first.
+	AuthProviders []string
+	// MFAEnabled reports whether a second factor is enrolled. No second factor exists
+	// yet, so it is always false; it is reported so clients can rely on the field.
+	MFAEnabled bool
+}
+
+// SecurityStatus returns the sign-in methods and second-factor state of user.
+func (s *service) SecurityStatus(ctx context.Context, user *User) (*SecurityStatus, error) {
+	accounts, err := s.repo.ListOAuthAccounts(ctx, user.ID)
+	if err != nil {
+		s.logger.Error("security status: list oauth accounts failed", "error", err, "user_id", user.ID)
+		return nil, ErrInternal.WithCause(err)
+	}
+	status := &SecurityStatus{AuthProviders: make([]string, 0, len(accounts)+1)}
+	if user.PasswordHash != "" {
+		status.AuthProviders = append(status.AuthProviders, "password")
+	}
+	for _, a := range accounts {
+		status.AuthProviders = append(status.AuthProviders, string(a.Provider))
+	}
+	return status, nil
 }
 
 // GetProfile retrieves a single user's profile by their ID.
+// Concurrent reads of the same profile share a single query.
 func (s *service) GetProfile(ctx context.Context, userID string) (*User, error) {
-	user, err := s.repo.FindByID(ctx, userID)
+	shared, err := s.profileReads.Do(ctx, userID, func(ctx context.Context) (*User, error) {
+		return s.repo.FindByID(ctx, userID)
+	})
 	if err != nil {
 		if errors.Is(err, ErrNotFound) {
 			return nil, ErrNotFound.WithCause(err)
@@ -24,7 +57,9 @@ func (s *service) GetProfile(ctx context.Context, userID string) (*User, error)
 		s.logger.Error("failed to get user profile from repository", "error", err, "user_id", userID)
 		return nil, ErrInternal.WithCause(err)
 	}
-	return user, nil
+	// The result is shared between callers; hand each its own copy.
+	user := *shared
+	return &user, nil
 }
 
 // UpdateProfile updates a user's profile information.
@@ -39,16 +74,31 @@ func (s *service) UpdateProfile(ctx context.Context, userID string, input Update
 		return nil, ErrInternal.WithCause(err)
 	}
 
-	// 2. Apply updates from the input struct.
+	// 2. Apply updates from the input struct. An empty patch is a no-op.
+	if input.FirstName == nil && input.LastName == nil && input.Locale == nil && input.TimeZone == nil {
+		return user, nil
+	}
+	if input.Locale != nil && *input.Locale != "" && !templates.ValidLanguage(*input.Locale) {
+		return nil, ErrInvalidLocale
+	}
+	if input.TimeZone != nil && *input.TimeZone != "" && !templates.ValidTimeZone(*input.TimeZone) {
+		return nil, ErrInvalidLocale
+	}
 	if input.FirstName != nil {
 		user.FirstName = *input.FirstName
 	}
 	if input.LastName != nil {
 		user.LastName = *input.LastName
 	}
+	if input.Locale != nil {
+		user.Locale = *input.Locale
+	}
+	if input.TimeZone != nil {
+		user.TimeZone = *input.TimeZone
+	}
 
 	// 3. Set the updated timestamp.
-	user.UpdatedAt = time.Now()
+	user.UpdatedAt = s.clock.Now()
 
 	// 4. Persist the changes to the database.
 	// NOTE: This requires the repository to have a general `Update` method.
@@ -56,8 +106,15 @@ func (s *service) UpdateProfile(ctx context.Context, userID string, input Update
 		s.logger.Error("failed to update user profile in repository", "error", err, "user_id", userID)
 		return nil, ErrInternal.WithCause(err)
 	}
+	// A read started before the write would return the old profile; don't let new readers join it.
+	s.profileReads.Forget(userID)
 
 	s.logger.Info("user profile updated successfully", "user_id", user.ID)
 
 	return user, nil
 }
+
+// recipientLocale is how dates and numbers are formatted in notifications to user.
+func recipientLocale(user *User) templates.Locale {
+	return templates.Locale{Language: user.Locale, TimeZone: user.TimeZone}
+}
@@ -0,0 +1,106 @@
+package user
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/metrics"
+)
+
+// registrationReplays counts registrations answered with an existing pending user instead of
+// being run again, labelled by how the repeat was detected (retry, race).
+var registrationReplays = metrics.NewCounter("user_registration_replays")
+
+// registrationKey is the idempotency key of registrations for email. The address is hashed
+// so it never appears in the store.
+func registrationKey(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return "register:" + hex.EncodeToString(sum[:])
+}
+
+// registrationWindow is how long a registration is replayed to retries;
+// ACCOUNT_REGISTRATION_IDEMPOTENCY_MINUTES=0 (or no store) disables replays.
+func (s *service) registrationWindow() time.Duration {
+	if s.idempotency == nil {
+		return 0
+	}
+	return time.Duration(s.config.Accounts.RegistrationIdempotencyMinutes) * time.Minute
+}
+
+// rememberRegistration records that user was registered, so retries within the window are
+// replayed. Failures only cost the retry a second verification email.
+func (s *service) rememberRegistration(ctx context.Context, user *User) {
+	window := s.registrationWindow()
+	if window <= 0 {
+		return
+	}
+	if err := s.idempotency.Put(ctx, registrationKey(user.Email), user.ID, window); err != nil {
+		s.logger.Warn("failed to record registration for retries", "error", err, "user_id", user.ID)
+	}
+}
+
+// replayRegistration returns the user created by a registration for email within the window
+// if the account is still pending verification and password matches. It returns nil when the
+// registration must run normally.
+func (s *service) replayRegistration(ctx context.Context, email, password string) *User {
+	if s.registrationWindow() <= 0 {
+		return nil
+	}
+	userID, ok, err := s.idempotency.Get(ctx, registrationKey(email))
+	if err != nil {
+		s.logger.Warn("failed to look up registration for retries", "error", err)
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			s.logger.Warn("failed to load replayed registration", "error", err, "user_id", userID)
+		}
+		return nil
+	}
+	if !s.isPendingRegistration(user, password) {
+		return nil
+	}
+	registrationReplays.Inc("retry")
+	s.logger.Info("registration retry replayed", "user_id", user.ID)
+	return user
+}
+
+// registrationRaceWindow is how recently the conflicting account must have been created for a
+// registration to count as concurrent with it.
+const registrationRaceWindow = 30 * time.Second
+
+// registrationConflict answers a registration whose insert hit an existing account for the same
+// email. If that account was created moments ago with the same password, the same client is
+// retrying concurrently and gets the winner's user; the winner sends the verification email.
+// Otherwise it is handled as a re-registration.
+func (s *service) registrationConflict(ctx context.Context, firstName, lastName, email, password string) (*User, error) {
+	existing, err := s.repo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrEmailExists
+		}
+		s.logger.Error("failed to load existing user by email", "error", err)
+		return nil, ErrInternal.WithCause(err)
+	}
+	if s.clock.Now().Sub(existing.CreatedAt) < registrationRaceWindow && s.isPendingRegistration(existing, password) {
+		registrationReplays.Inc("race")
+		s.logger.Info("concurrent registration replayed", "user_id", existing.ID)
+		return existing, nil
+	}
+	return s.reRegister(ctx, existing, firstName, lastName)
+}
+
+// isPendingRegistration reports whether user still awaits email verification and was
+// registered with password.
+func (s *service) isPendingRegistration(user *User, password string) bool {
+	return user.DeletedAt == nil && !user.EmailVerified && user.PasswordHash != "" &&
+		s.checkPasswordHash(password, user.PasswordHash)
+}
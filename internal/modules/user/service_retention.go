@@ -0,0 +1,111 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/metrics"
+)
+
+const (
+	defaultRetentionBatchSize = 1000
+	// retentionMaxBatches bounds the batches per target in one pass; the next pass continues.
+	retentionMaxBatches = 100
+)
+
+var (
+	// retentionDeleted counts records deleted by retention passes, labelled by target.
+	retentionDeleted = metrics.NewCounter("user_retention_deleted")
+	// expiredPurged counts expired rows deleted by PurgeExpired, labelled by table.
+	expiredPurged = metrics.NewCounter("user_expired_purged")
+)
+
+// RetentionPolicies returns every retention policy with its configured age, including the
+// ones that keep records forever (Days == 0), so compliance can review the full lifecycle.
+func (s *service) RetentionPolicies() []RetentionPolicy {
+	cfg := s.config.Retention
+	return []RetentionPolicy{
+		{Target: RetentionAudit, Table: "account_lifecycle_audit", Days: cfg.AuditDays},
+		{Target: RetentionActivity, Table: "user_activity_events", Days: cfg.ActivityDays},
+		{Target: RetentionDeliveries, Table: "notification_outbox", Days: cfg.DeliveryDays},
+		{Target: RetentionVerificationCodes, Table: "verification_codes", Days: cfg.VerificationCodeDays},
+	}
+}
+
+// EnforceRetention runs one retention pass: for each policy with a positive age, records older
+// than that age are deleted in batches of RETENTION_BATCH_SIZE. A failing target does not stop
+// the others; all errors are returned together.
+func (s *service) EnforceRetention(ctx context.Context) (*RetentionReport, error) {
+	batch := s.config.Retention.BatchSize
+	if batch <= 0 {
+		batch = defaultRetentionBatchSize
+	}
+	now := s.clock.Now()
+	report := &RetentionReport{Deleted: map[RetentionTarget]int64{}}
+	var errs []error
+
+	for _, p := range s.RetentionPolicies() {
+		if p.Days <= 0 {
+			continue
+		}
+		cutoff := now.AddDate(0, 0, -p.Days)
+		for range retentionMaxBatches {
+			n, err := s.repo.PurgeRetained(ctx, p.Target, cutoff, s.config.Server.Namespace(), batch)
+			report.Deleted[p.Target] += n
+			retentionDeleted.Add(n, string(p.Target))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("purge %s: %w", p.Target, err))
+				break
+			}
+			if n < int64(batch) {
+				break
+			}
+		}
+	}
+
+	s.logger.Info("retention pass finished",
+		"audit", report.Deleted[RetentionAudit],
+		"activity", report.Deleted[RetentionActivity],
+		"deliveries", report.Deleted[RetentionDeliveries],
+		"verification_codes", report.Deleted[RetentionVerificationCodes],
+		"errors", len(errs))
+	return report, errors.Join(errs...)
+}
+
+// PurgeExpired deletes expired action tokens, OAuth states and one-time codes in batches of
+// RETENTION_BATCH_SIZE. Expired sessions are purged by the session provider. A failing table
+// does not stop the others.
+func (s *service) PurgeExpired(ctx context.Context) (map[string]int64, error) {
+	batch := s.config.Retention.BatchSize
+	if batch <= 0 {
+		batch = defaultRetentionBatchSize
+	}
+	now := s.clock.Now()
+	deleted := map[string]int64{}
+	var errs []error
+
+	for _, table := range []string{ExpiredActionTokens, ExpiredOAuthStates, ExpiredVerificationCodes} {
+		for range retentionMaxBatches {
+			n, err := s.repo.PurgeExpired(ctx, table, now, batch)
+			deleted[table] += n
+			expiredPurged.Add(n, table)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("purge expired %s: %w", table, err))
+				break
+			}
+			if n < int64(batch) {
+				break
+			}
+		}
+	}
+
+	if deleted[ExpiredActionTokens] > 0 || deleted[ExpiredOAuthStates] > 0 || deleted[ExpiredVerificationCodes] > 0 || len(errs) > 0 {
+		s.logger.Info("expired rows purged",
+			"action_tokens", deleted[ExpiredActionTokens],
+			"oauth_states", deleted[ExpiredOAuthStates],
+			"verification_codes", deleted[ExpiredVerificationCodes],
+			"errors", len(errs))
+	}
+	return deleted, errors.Join(errs...)
+}
@@ -0,0 +1,15 @@
+package user
+
+import (
+	"context"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/runbook"
+)
+
+// Runbook returns the status of every background subsystem, keyed by component name.
+func (s *service) Runbook(ctx context.Context) map[string]runbook.Status {
+	if s.runbook == nil {
+		return map[string]runbook.Status{}
+	}
+	return s.runbook(ctx)
+}
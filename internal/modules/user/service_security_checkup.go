@@ -0,0 +1,113 @@
+package user
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// suspiciousActivityWindow is how far back the checkup looks for suspicious events.
+	suspiciousActivityWindow = 30 * 24 * time.Hour
+	maxSuspiciousEvents      = 10
+	// stalePasswordAge is the password age past which the checkup suggests a new one.
+	stalePasswordAge = 365 * 24 * time.Hour
+	// manySessions is the active session count past which the checkup suggests a review.
+	manySessions = 5
+)
+
+// suspiciousActivity lists the events a user should double-check: sign-ins from new devices and
+// actions an administrator took on the account.
+var suspiciousActivity = []ActivityType{
+	ActivityNewDevice,
+	ActivityAdminForcedPasswordReset,
+	ActivityAdminForcedReverification,
+	ActivityAdminSuspended,
+	ActivityAdminImpersonated,
+}
+
+// Security checkup recommendations, in the order they are reported.
+const (
+	RecommendVerifyEmail    = "verify_email"
+	RecommendChangePassword = "change_password"
+	RecommendReviewActivity = "review_activity"
+	RecommendReviewSessions = "review_sessions"
+)
+
+// SecurityCheckup is a user's security posture, for a "security checkup" screen.
+type SecurityCheckup struct {
+	// CheckedAt is when the checkup was taken; ages are relative to it.
+	CheckedAt   time.Time
+	HasPassword bool
+	// PasswordSetAt is when the password was last set; nil without a password.
+	PasswordSetAt *time.Time
+	EmailVerified bool
+	// MFAEnabled and RecoveryCodesRemaining describe the second factor. No second factor exists
+	// yet, so they are always false and 0; they are reported so clients can rely on the fields.
+	MFAEnabled             bool
+	RecoveryCodesRemaining int
+	ActiveSessions         int
+	// SuspiciousEvents are the suspiciousActivity events of the last 30 days, newest first.
+	SuspiciousEvents []*ActivityEvent
+	OAuthAccounts    []*OAuthAccount
+	Recommendations  []string
+}
+
+// SecurityCheckup gathers userID's security posture in one call.
+func (s *service) SecurityCheckup(ctx context.Context, userID string) (*SecurityCheckup, error) {
+	user, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	c := &SecurityCheckup{
+		CheckedAt:     now,
+		HasPassword:   user.PasswordHash != "",
+		EmailVerified: user.EmailVerified,
+	}
+
+	if c.HasPassword {
+		c.PasswordSetAt, err = s.repo.PasswordSetAt(ctx, userID)
+		if err != nil {
+			s.logger.Error("security checkup: password age failed", "error", err, "user_id", userID)
+			return nil, ErrInternal.WithCause(err)
+		}
+		if c.PasswordSetAt == nil {
+			// Set at signup and never changed, or its records were pruned by retention.
+			c.PasswordSetAt = &user.CreatedAt
+		}
+	}
+
+	sessions, err := s.sessions.ListForUser(ctx, userID)
+	if err != nil {
+		s.logger.Error("security checkup: list sessions failed", "error", err, "user_id", userID)
+		return nil, ErrInternal.WithCause(err)
+	}
+	c.ActiveSessions = len(sessions)
+
+	c.SuspiciousEvents, err = s.repo.ListActivityEventsSince(ctx, userID, suspiciousActivity, now.Add(-suspiciousActivityWindow), maxSuspiciousEvents)
+	if err != nil {
+		s.logger.Error("security checkup: list activity failed", "error", err, "user_id", userID)
+		return nil, ErrInternal.WithCause(err)
+	}
+
+	c.OAuthAccounts, err = s.repo.ListOAuthAccounts(ctx, userID)
+	if err != nil {
+		s.logger.Error("security checkup: list oauth accounts failed", "error", err, "user_id", userID)
+		return nil, ErrInternal.WithCause(err)
+	}
+
+	c.Recommendations = []string{}
+	if user.Email != "" && !user.EmailVerified {
+		c.Recommendations = append(c.Recommendations, RecommendVerifyEmail)
+	}
+	if c.PasswordSetAt != nil && now.Sub(*c.PasswordSetAt) > stalePasswordAge {
+		c.Recommendations = append(c.Recommendations, RecommendChangePassword)
+	}
+	if len(c.SuspiciousEvents) > 0 {
+		c.Recommendations = append(c.Recommendations, RecommendReviewActivity)
+	}
+	if c.ActiveSessions > manySessions {
+		c.Recommendations = append(c.Recommendations, RecommendReviewSessions)
+	}
+	return c, nil
+}
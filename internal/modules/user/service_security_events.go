@@ -0,0 +1,101 @@
+package user
+
+import (
+	"context"
+	"errors"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/authstats"
+	"github.com/delordemm1/go-api-simple-starter/internal/siem"
+)
+
+// Security event types streamed to the SIEM in addition to the activity timeline types.
+const (
+	securityEventLoginFailed     = "login_failed"
+	securityEventSessionRevoked  = "session_revoked"
+	securityEventSessionsRevoked = "sessions_revoked"
+	securityEventSessionRotated  = "session_rotated"
+)
+
+// Logout revokes a single session.
+func (s *service) Logout(ctx context.Context, userID, sessionID string) error {
+	if err := s.sessions.Delete(ctx, sessionID); err != nil {
+		s.logger.Warn("failed to delete session on logout", "error", err, "user_id", userID)
+		return ErrInternal.WithCause(err)
+	}
+	s.events.Publish(ctx, siem.Event{
+		Type:     securityEventSessionRevoked,
+		UserID:   userID,
+		Metadata: map[string]any{"reason": "logout"},
+	})
+	return nil
+}
+
+// LogoutAll revokes every session of the user, including the caller's.
+func (s *service) LogoutAll(ctx context.Context, userID string) error {
+	if err := s.revokeAllSessions(ctx, userID, userID, "logout_all"); err != nil {
+		s.logger.Error("failed to delete sessions on logout-all", "error", err, "user_id", userID)
+		return ErrInternal.WithCause(err)
+	}
+	s.logger.Info("user logged out everywhere", "user_id", userID)
+	return nil
+}
+
+// revokeAllSessions deletes every session of the user and reports it to the SIEM.
+// reason is a short machine-readable cause (e.g., "account_deleted").
+func (s *service) revokeAllSessions(ctx context.Context, userID, actorID, reason string) error {
+	n, err := s.sessions.DeleteAllForUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	s.events.Publish(ctx, siem.Event{
+		Type:     securityEventSessionsRevoked,
+		UserID:   userID,
+		ActorID:  actorID,
+		Metadata: map[string]any{"reason": reason, "count": n},
+	})
+	return nil
+}
+
+// recordFailedLogin reports a rejected password login. userID is empty for unknown emails.
+func (s *service) recordFailedLogin(ctx context.Context, email, userID, reason string) {
+	s.recordAttempt(authstats.KindLogin, false)
+	s.events.Publish(ctx, siem.Event{
+		Type:     securityEventLoginFailed,
+		Outcome:  siem.OutcomeFailure,
+		UserID:   userID,
+		Metadata: map[string]any{"email": email, "reason": reason},
+	})
+}
+
+// recordFailedPhoneLogin is recordFailedLogin for SMS code sign-ins.
+func (s *service) recordFailedPhoneLogin(ctx context.Context, phone, userID, reason string) {
+	s.recordAttempt(authstats.KindLogin, false)
+	s.events.Publish(ctx, siem.Event{
+		Type:     securityEventLoginFailed,
+		Outcome:  siem.OutcomeFailure,
+		UserID:   userID,
+		Metadata: map[string]any{"phone": phone, "reason": reason},
+	})
+}
+
+// AttemptRecorder observes authentication outcomes (implemented by authstats.Tracker).
+type AttemptRecorder interface {
+	Record(kind authstats.Kind, success bool)
+}
+
+func (s *service) recordAttempt(kind authstats.Kind, success bool) {
+	if s.attempts != nil {
+		s.attempts.Record(kind, success)
+	}
+}
+
+// recordOTPAttempt counts the outcome of a one-time code check. Internal errors are not
+// attempts, so outages never look like an attack.
+func (s *service) recordOTPAttempt(err error) {
+	switch {
+	case err == nil:
+		s.recordAttempt(authstats.KindOTP, true)
+	case errors.Is(err, ErrInvalidOTP), errors.Is(err, ErrTooManyAttempts):
+		s.recordAttempt(authstats.KindOTP, false)
+	}
+}
@@ -0,0 +1,140 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/session"
+	"github.com/delordemm1/go-api-simple-starter/internal/siem"
+)
+
+// RebindSession binds the caller's session to the client it is now used from, once the user
+// re-entered their password. It is the way out of session.ScopeRebindRequired; accounts without
+// a password sign in again instead. The session's token is rotated; the returned one replaces it.
+// When the rebind fails after the rotation, the new token is returned along with the error (see
+// withRotatedToken) and the session stays restricted until a retry succeeds.
+func (s *service) RebindSession(ctx context.Context, userID, sessionID, password string) (string, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return "", ErrNotFound.WithCause(err)
+		}
+		s.logger.Error("rebind session: find user failed", "error", err, "user_id", userID)
+		return "", ErrInternal.WithCause(err)
+	}
+	if user.PasswordHash == "" || !s.checkPasswordHash(password, user.PasswordHash) {
+		s.recordFailedLogin(ctx, user.Email, user.ID, "rebind_invalid_password")
+		return "", ErrInvalidCredentials.WithDetail("password is incorrect")
+	}
+
+	newSessionID, err := s.rotateSession(ctx, user.ID, sessionID, "rebound")
+	if err != nil {
+		return "", err
+	}
+	if err := s.sessions.Rebind(ctx, newSessionID, sessionMetadata(ctx, "")); err != nil {
+		if errors.Is(err, session.ErrNotFound) {
+			return "", ErrUnauthorized.WithDetail("invalid or expired session")
+		}
+		s.logger.Error("rebind session failed", "error", err, "user_id", user.ID)
+		return newSessionID, withRotatedToken(ErrInternal.WithCause(err), newSessionID)
+	}
+	s.logger.Info("session rebound to a new client", "user_id", user.ID)
+	return newSessionID, nil
+}
+
+// Reauthenticate re-checks the password and puts the caller's session in sudo mode, letting it
+// call operations guarded by middleware.RequireRecentAuth until the returned time. Accounts
+// without a password sign in again instead, as new sessions start out recently authenticated.
+// The session's token is rotated, so a copy taken before the elevation does not gain sudo mode;
+// the returned token replaces it. When the elevation fails after the rotation, the new token is
+// returned along with the error (see withRotatedToken), without sudo mode.
+func (s *service) Reauthenticate(ctx context.Context, userID, sessionID, password string) (string, time.Time, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return "", time.Time{}, ErrNotFound.WithCause(err)
+		}
+		s.logger.Error("reauthenticate: find user failed", "error", err, "user_id", userID)
+		return "", time.Time{}, ErrInternal.WithCause(err)
+	}
+	if user.PasswordHash == "" || !s.checkPasswordHash(password, user.PasswordHash) {
+		s.recordFailedLogin(ctx, user.Email, user.ID, "reauth_invalid_password")
+		return "", time.Time{}, ErrInvalidCredentials.WithDetail("password is incorrect")
+	}
+
+	// Rotate before elevating, so only the new token ever carries sudo mode.
+	newSessionID, err := s.rotateSession(ctx, user.ID, sessionID, "reauthenticated")
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	until, err := s.sessions.Elevate(ctx, newSessionID)
+	if err != nil {
+		if errors.Is(err, session.ErrNotFound) {
+			return "", time.Time{}, ErrUnauthorized.WithDetail("invalid or expired session")
+		}
+		s.logger.Error("elevate session failed", "error", err, "user_id", user.ID)
+		return newSessionID, time.Time{}, withRotatedToken(ErrInternal.WithCause(err), newSessionID)
+	}
+	s.logger.Info("session re-authenticated", "user_id", user.ID, "until", until)
+	return newSessionID, until, nil
+}
+
+// withRotatedToken reports err to the client along with the session's new token, for failures
+// after the rotation: the old token is gone by then, so a client not told the new one would be
+// signed out. The token goes in the problem's context as "sessionToken".
+func withRotatedToken(err *DomainError, newSessionID string) *DomainError {
+	return err.WithContext(map[string]any{"sessionToken": newSessionID})
+}
+
+// rotateSession replaces the token of the caller's session when it gains privileges, so a
+// token captured earlier (from logs, a shared machine, a fixation attempt) is worthless from now
+// on. It returns the token the client must use. A failure is returned, never the old token:
+// callers must not grant the privilege to a session that kept its token.
+func (s *service) rotateSession(ctx context.Context, userID, sessionID, reason string) (string, error) {
+	newSessionID, err := s.sessions.Rotate(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, session.ErrNotFound) {
+			return "", ErrUnauthorized.WithDetail("invalid or expired session")
+		}
+		s.logger.Error("rotate session failed", "error", err, "user_id", userID, "reason", reason)
+		return "", ErrInternal.WithCause(err)
+	}
+	s.events.Publish(ctx, siem.Event{
+		Type:     securityEventSessionRotated,
+		UserID:   userID,
+		ActorID:  userID,
+		Metadata: map[string]any{"reason": reason},
+	})
+	return newSessionID, nil
+}
+
+// ListSessions returns the user's active sessions (devices), most recently active first.
+func (s *service) ListSessions(ctx context.Context, userID string) ([]*session.Info, error) {
+	sessions, err := s.sessions.ListForUser(ctx, userID)
+	if err != nil {
+		s.logger.Error("list sessions failed", "error", err, "user_id", userID)
+		return nil, ErrInternal.WithCause(err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs one of the user's devices out. id is the session's Info.ID; sessions of
+// other users are reported as ErrNotFound.
+func (s *service) RevokeSession(ctx context.Context, userID, id string) error {
+	if err := s.sessions.DeleteForUser(ctx, userID, id); err != nil {
+		if errors.Is(err, session.ErrNotFound) {
+			return ErrNotFound.WithDetail("session not found")
+		}
+		s.logger.Error("revoke session failed", "error", err, "user_id", userID)
+		return ErrInternal.WithCause(err)
+	}
+	s.events.Publish(ctx, siem.Event{
+		Type:     securityEventSessionRevoked,
+		UserID:   userID,
+		ActorID:  userID,
+		Metadata: map[string]any{"reason": "device_revoked", "sessionId": id},
+	})
+	s.logger.Info("session revoked by user", "user_id", userID, "session", id)
+	return nil
+}
@@ -0,0 +1,84 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/config"
+	"github.com/delordemm1/go-api-simple-starter/internal/passhash"
+	"github.com/delordemm1/go-api-simple-starter/internal/session"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// sessionRepo knows one user with the password "correct horse".
+type sessionRepo struct {
+	Repository
+	user *User
+}
+
+func (r *sessionRepo) FindByID(ctx context.Context, id string) (*User, error) {
+	if id != r.user.ID {
+		return nil, ErrNotFound
+	}
+	c := *r.user
+	return &c, nil
+}
+
+// failingElevate is a session provider whose Elevate always fails.
+type failingElevate struct {
+	session.Provider
+}
+
+func (p failingElevate) Elevate(ctx context.Context, sessionID string) (time.Time, error) {
+	return time.Time{}, errors.New("store unavailable")
+}
+
+// TestReauthenticateKeepsRotatedTokenOnFailure checks that a failure after the rotation hands
+// the client the new token, as the old one no longer works.
+func TestReauthenticateKeepsRotatedTokenOnFailure(t *testing.T) {
+	hasher, err := passhash.New(passhash.Config{Algorithm: passhash.Bcrypt, BcryptCost: bcrypt.MinCost})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := hasher.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &User{ID: "0199f0a0-0000-7000-8000-000000000001", Email: "alice@example.com", PasswordHash: hash}
+	sessions := failingElevate{session.NewMemoryProvider(session.Config{})}
+	svc := NewService(&Config{
+		Repo:           &sessionRepo{user: user},
+		Logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Config:         &config.Config{},
+		Sessions:       sessions,
+		PasswordHasher: hasher,
+	})
+	ctx := context.Background()
+	old, err := sessions.CreateAuthSession(ctx, user.ID, session.Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, _, err := svc.Reauthenticate(ctx, user.ID, old, "correct horse")
+	if !errors.Is(err, ErrInternal) {
+		t.Fatalf("Reauthenticate: %v, want ErrInternal", err)
+	}
+	var de *DomainError
+	if !errors.As(err, &de) {
+		t.Fatalf("Reauthenticate error %T is not a DomainError", err)
+	}
+	problemCtx, _ := de.ProblemContext().(map[string]any)
+	if token == "" || problemCtx["sessionToken"] != token {
+		t.Fatalf("problem context %v does not carry the returned token %q", de.ProblemContext(), token)
+	}
+	if _, err := sessions.Get(ctx, old); !errors.Is(err, session.ErrNotFound) {
+		t.Fatalf("old token: %v, want ErrNotFound", err)
+	}
+	if _, err := sessions.Get(ctx, token); err != nil {
+		t.Fatalf("new token: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/notification"
+	"github.com/delordemm1/go-api-simple-starter/internal/notification/templates"
+	"github.com/delordemm1/go-api-simple-starter/internal/securerand"
+)
+
+// actionPurposeRevokeSessions authorizes signing out every session from a sign-in alert link.
+const actionPurposeRevokeSessions = "revoke_sessions"
+
+// notifyNewSignIn emails the user about a login from an unrecognized device (SIGNIN_ALERT_*).
+// It runs in the background; failures are logged and never affect the login.
+func (s *service) notifyNewSignIn(ctx context.Context, userID, authMethod string) {
+	if !s.config.SignInAlert.Enabled {
+		return
+	}
+	signedInAt := s.clock.Now()
+	go func() {
+		ctx := contextx.Detach(ctx) // the request may finish before the send
+		user, err := s.repo.FindByID(ctx, userID)
+		if err != nil {
+			s.logger.Warn("sign-in alert: find user failed", "error", err, "user_id", userID)
+			return
+		}
+		if user.Email == "" {
+			return
+		}
+
+		var link string
+		if tmpl := s.config.SignInAlert.RevokeLinkTemplate; tmpl != "" {
+			rawToken, err := s.issueRevokeSessionsToken(ctx, user.ID)
+			if err != nil {
+				s.logger.Warn("sign-in alert: issue revoke token failed", "error", err, "user_id", user.ID)
+			} else {
+				link = strings.ReplaceAll(tmpl, "{token}", url.QueryEscape(rawToken))
+			}
+		}
+
+		data := templates.NewSignInData{
+			FirstName:                user.FirstName,
+			SignedInAt:               signedInAt,
+			Locale:                   recipientLocale(user),
+			UserAgent:                contextx.UserAgent(ctx),
+			IPAddress:                contextx.ClientIP(ctx),
+			AuthMethod:               authMethod,
+			RevokeLink:               link,
+			RevokeLinkExpiresInHours: s.revokeTokenTTLHours(),
+			SupportEmail:             s.config.SMTP.From,
+		}
+		if err := notification.SendTemplate(ctx, s.notification, templates.NewSignIn, user.Email, []notification.Channel{notification.ChannelEmail}, notification.PriorityHigh, data); err != nil {
+			s.logger.Error("failed to send sign-in alert", "error", err, "user_id", user.ID)
+		}
+	}()
+}
+
+// issueRevokeSessionsToken creates the single-use token behind a sign-in alert's revoke link.
+// Earlier tokens are kept, so the link in every alert still received keeps working.
+func (s *service) issueRevokeSessionsToken(ctx context.Context, userID string) (string, error) {
+	rawToken, err := securerand.Token(32)
+	if err != nil {
+		return "", err
+	}
+	now := s.clock.Now()
+	at := &ActionToken{
+		UserID:    userID,
+		Purpose:   actionPurposeRevokeSessions,
+		TokenHash: hashToken(rawToken),
+		ExpiresAt: now.Add(time.Duration(s.revokeTokenTTLHours()) * time.Hour),
+		CreatedAt: now,
+	}
+	if err := s.repo.CreateActionToken(ctx, at); err != nil {
+		return "", err
+	}
+	return rawToken, nil
+}
+
+func (s *service) revokeTokenTTLHours() int {
+	if ttl := s.config.SignInAlert.RevokeTokenTTLHours; ttl > 0 {
+		return ttl
+	}
+	return 72
+}
+
+// RevokeSessionsWithToken signs out every session of the user a sign-in alert was sent to.
+// It needs no session, since the alert's recipient may not be signed in (or trust the
+// sessions they are signed in with). Every outstanding revoke token of the user is spent.
+func (s *service) RevokeSessionsWithToken(ctx context.Context, token string) error {
+	if token == "" {
+		return ErrInvalidRevokeToken
+	}
+	at, err := s.repo.FindActionTokenByHash(ctx, hashToken(token), actionPurposeRevokeSessions)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrInvalidRevokeToken
+		}
+		s.logger.Error("revoke sessions: find token failed", "error", err)
+		return ErrInternal.WithCause(err)
+	}
+	if s.clock.Now().After(at.ExpiresAt) {
+		return ErrInvalidRevokeToken
+	}
+
+	if err := s.revokeAllSessions(ctx, at.UserID, at.UserID, "sign_in_alert"); err != nil {
+		s.logger.Error("revoke sessions: delete sessions failed", "error", err, "user_id", at.UserID)
+		return ErrInternal.WithCause(err)
+	}
+	if err := s.repo.DeleteUserActionTokensByPurpose(ctx, at.UserID, actionPurposeRevokeSessions); err != nil {
+		s.logger.Warn("revoke sessions: delete revoke tokens failed", "error", err, "user_id", at.UserID)
+	}
+
+	s.logger.Info("user signed out everywhere from a sign-in alert", "user_id", at.UserID)
+	return nil
+}
@@ -0,0 +1,73 @@
+package user
+
+import (
+	"context"
+	"errors"
+)
+
+// CheckTerms returns ErrTermsAcceptanceRequired when TERMS_CURRENT_VERSION is set and userID
+// has not accepted it; the problem context names the version to accept. It is a no-op when
+// no version is configured.
+func (s *service) CheckTerms(ctx context.Context, userID string) error {
+	current := s.config.Terms.CurrentVersion
+	if current == "" {
+		return nil
+	}
+	user, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !termsAccepted(user, current) {
+		return ErrTermsAcceptanceRequired.WithContext(map[string]any{
+			"currentVersion":  current,
+			"acceptedVersion": user.TermsVersion,
+		})
+	}
+	return nil
+}
+
+// AcceptTerms records that userID accepted version, which must be the current one (so a
+// client showing outdated terms cannot accept them). Accepting again is a no-op.
+func (s *service) AcceptTerms(ctx context.Context, userID, version string) (*User, error) {
+	current := s.config.Terms.CurrentVersion
+	if current == "" || version != current {
+		return nil, ErrTermsVersionMismatch.WithContext(map[string]any{"currentVersion": current})
+	}
+
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNotFound.WithCause(err)
+		}
+		s.logger.Error("accept terms: find user failed", "error", err, "user_id", userID)
+		return nil, ErrInternal.WithCause(err)
+	}
+	if termsAccepted(user, current) {
+		return user, nil
+	}
+
+	s.acceptCurrentTerms(user)
+	if err := s.repo.Update(ctx, user); err != nil {
+		s.logger.Error("accept terms: update user failed", "error", err, "user_id", userID)
+		return nil, ErrInternal.WithCause(err)
+	}
+	s.recordActivity(ctx, userID, ActivityTermsAccepted, map[string]any{"version": current})
+	s.logger.Info("user accepted terms of service", "user_id", userID, "version", current)
+	return user, nil
+}
+
+// acceptCurrentTerms marks user as having accepted the configured terms now, e.g. at
+// registration, where the terms checkbox is required. No-op when no version is configured.
+func (s *service) acceptCurrentTerms(user *User) {
+	current := s.config.Terms.CurrentVersion
+	if current == "" {
+		return
+	}
+	now := s.clock.Now()
+	user.TermsVersion, user.TermsAcceptedAt = &current, &now
+}
+
+// termsAccepted reports whether user's last accepted terms version is current.
+func termsAccepted(user *User, current string) bool {
+	return user.TermsVersion != nil && *user.TermsVersion == current
+}
@@ -2,16 +2,17 @@ package user
 
 import (
 	"context"
-	"crypto/subtle"
 	"errors"
-	"strings"
 	"time"
 
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
 	"github.com/delordemm1/go-api-simple-starter/internal/notification"
 	"github.com/delordemm1/go-api-simple-starter/internal/notification/templates"
+	"github.com/delordemm1/go-api-simple-starter/internal/securerand"
+	"github.com/delordemm1/go-api-simple-starter/internal/session"
 )
 
-// ResendEmailVerification generates or refreshes a 6-digit code for email verification and sends it.
+// ResendEmailVerification generates or refreshes a verification code for email verification and sends it.
 // It enforces resend cooldown and hides user enumeration by returning nil when the email is unknown or already verified.
 func (s *service) ResendEmailVerification(ctx context.Context, email string) error {
 	user, err := s.repo.FindByEmail(ctx, email)
@@ -35,10 +36,16 @@ func (s *service) ResendEmailVerification(ctx context.Context, email string) err
 
 	// Fire-and-forget notification
 	go func() {
+		ctx := contextx.Detach(ctx) // the request may finish before the send
 		data := templates.VerifyEmailData{
-			FirstName:    user.FirstName,
-			Code:         code,
-			SupportEmail: s.config.SMTP.From,
+			FirstName:                  user.FirstName,
+			Code:                       code,
+			ExpiresInMinutes:           s.otpPolicy(VerificationPurposeEmailVerify).TTLMinutes,
+			ExpiresAt:                  s.otpExpiresAt(VerificationPurposeEmailVerify),
+			Locale:                     recipientLocale(user),
+			VerifyLink:                 s.emailVerifyLink(ctx, user),
+			VerifyLinkExpiresInMinutes: s.verifyLinkTTLMinutes(),
+			SupportEmail:               s.config.SMTP.From,
 		}
 		if err := notification.SendTemplate(ctx, s.notification, templates.VerifyEmail, user.Email, []notification.Channel{notification.ChannelEmail}, notification.PriorityHigh, data); err != nil {
 			s.logger.Error("failed to send verify email", "error", err, "user_id", user.ID)
@@ -47,9 +54,11 @@ func (s *service) ResendEmailVerification(ctx context.Context, email string) err
 	return nil
 }
 
-// ConfirmEmailVerification validates a 6-digit code, marks the user's email as verified, and consumes the code.
-func (s *service) ConfirmEmailVerification(ctx context.Context, email, code string) error {
-	if strings.TrimSpace(code) == "" {
+// ConfirmEmailVerification validates a verification code, marks the user's email as verified, and consumes the code.
+func (s *service) ConfirmEmailVerification(ctx context.Context, email, code string) (err error) {
+	defer func() { s.recordOTPAttempt(err) }()
+	code = normalizeCode(s.otpPolicy(VerificationPurposeEmailVerify), code)
+	if code == "" {
 		return ErrInvalidOTP
 	}
 
@@ -78,13 +87,13 @@ func (s *service) ConfirmEmailVerification(ctx context.Context, email, code stri
 	}
 
 	// TTL check
-	if time.Now().After(vc.ExpiresAt) {
+	if s.clock.Now().After(vc.ExpiresAt) {
 		return ErrInvalidOTP
 	}
 
 	// Compare hash in constant time
 	hashed := hashToken(code)
-	if subtle.ConstantTimeCompare([]byte(hashed), []byte(vc.CodeHash)) != 1 {
+	if !securerand.Equal(hashed, vc.CodeHash) {
 		attempts, max, incErr := s.repo.IncrementVerificationAttempt(ctx, vc.ID)
 		if incErr != nil && !errors.Is(incErr, ErrNotFound) {
 			s.logger.Error("confirm verify: increment attempts failed", "error", incErr)
@@ -101,29 +110,30 @@ func (s *service) ConfirmEmailVerification(ctx context.Context, email, code stri
 		s.logger.Error("confirm verify: consume code failed", "error", err)
 		return ErrInternal.WithCause(err)
 	}
+	return s.markEmailVerified(ctx, user)
+}
+
+// markEmailVerified records that user proved ownership of their email address.
+func (s *service) markEmailVerified(ctx context.Context, user *User) error {
 	user.EmailVerified = true
 	if err := s.repo.Update(ctx, user); err != nil {
 		s.logger.Error("confirm verify: update user failed", "error", err)
 		return ErrInternal.WithCause(err)
 	}
-
+	// Sessions opened during the unverified grace period become full sessions.
+	if _, err := s.sessions.ClearScope(ctx, user.ID, session.ScopeEmailUnverified); err != nil {
+		s.logger.Warn("confirm verify: upgrade restricted sessions failed", "error", err, "user_id", user.ID)
+	}
+	s.recordActivity(ctx, user.ID, ActivityEmailVerified, nil)
 	return nil
 }
 
 // createOrRefreshVerificationCode enforces cooldown and returns the plaintext code (never stored).
 func (s *service) createOrRefreshVerificationCode(ctx context.Context, user *User, contact string, purpose VerificationPurpose, channel VerificationChannel) (string, error) {
-	ttlMinutes := s.config.Verification.TTLMinutes
-	if ttlMinutes <= 0 {
-		ttlMinutes = 10
-	}
-	resendCooldownSecs := s.config.Verification.ResendCooldownSeconds
-	if resendCooldownSecs <= 0 {
-		resendCooldownSecs = 60
-	}
-	maxAttempts := s.config.Verification.MaxAttempts
-	if maxAttempts <= 0 {
-		maxAttempts = 5
-	}
+	policy := s.otpPolicy(purpose)
+	ttlMinutes := policy.TTLMinutes
+	resendCooldownSecs := policy.ResendCooldownSeconds
+	maxAttempts := policy.MaxAttempts
 
 	// Prefer user scoped active code
 	var active *VerificationCode
@@ -143,14 +153,14 @@ func (s *service) createOrRefreshVerificationCode(ctx context.Context, user *Use
 		}
 	}
 
-	now := time.Now()
+	now := s.clock.Now()
 	// Cooldown check if there is an active code
 	if active != nil && now.Sub(active.LastSentAt) < time.Duration(resendCooldownSecs)*time.Second {
 		return "", ErrResendTooSoon
 	}
 
-	// Generate new 6-digit code
-	code, genErr := generateNumericCode(6)
+	// Generate a new code per the purpose's length and alphabet
+	code, genErr := generateCode(policy.CodeAlphabet, policy.CodeLength)
 	if genErr != nil {
 		s.logger.Error("createOrRefresh: generate code failed", "error", genErr)
 		return "", ErrInternal.WithCause(genErr)
@@ -190,6 +200,10 @@ func (s *service) createOrRefreshVerificationCode(ctx context.Context, user *Use
 		CreatedAt:   now,
 	}
 	if err := s.repo.CreateVerificationCode(ctx, vc); err != nil {
+		if errors.Is(err, ErrConflict) {
+			// A concurrent request just issued an active code for this contact.
+			return "", ErrResendTooSoon
+		}
 		s.logger.Error("createOrRefresh: create code failed", "error", err)
 		return "", ErrInternal.WithCause(err)
 	}
@@ -0,0 +1,135 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/securerand"
+)
+
+// actionTokenEmailVerify is the action token purpose of email verification links.
+const actionTokenEmailVerify = "email_verify"
+
+// Statuses reported to VERIFY_EMAIL_REDIRECT_URL by the verification link.
+const (
+	verifyLinkStatusVerified = "verified"
+	verifyLinkStatusInvalid  = "invalid"
+	verifyLinkStatusError    = "error"
+)
+
+// emailVerifyLink issues a verification token for user, replacing any previous one, and returns
+// the link that redeems it. It returns "" when link verification is not configured
+// or the token could not be stored; the code in the same email still works.
+func (s *service) emailVerifyLink(ctx context.Context, user *User) string {
+	base := strings.TrimSuffix(s.config.Server.PublicURL, "/")
+	if s.config.VerifyLink.RedirectURL == "" || base == "" {
+		return ""
+	}
+
+	rawToken, err := securerand.Token(32)
+	if err != nil {
+		s.logger.Error("verify link: generate token failed", "error", err)
+		return ""
+	}
+	if err := s.repo.DeleteUserActionTokensByPurpose(ctx, user.ID, actionTokenEmailVerify); err != nil {
+		s.logger.Warn("verify link: cleanup old tokens failed", "error", err, "user_id", user.ID)
+	}
+	now := s.clock.Now()
+	at := &ActionToken{
+		UserID:    user.ID,
+		Purpose:   actionTokenEmailVerify,
+		TokenHash: hashToken(rawToken),
+		ExpiresAt: now.Add(time.Duration(s.verifyLinkTTLMinutes()) * time.Minute),
+		CreatedAt: now,
+	}
+	if err := s.repo.CreateActionToken(ctx, at); err != nil {
+		s.logger.Error("verify link: create token failed", "error", err, "user_id", user.ID)
+		return ""
+	}
+	return base + "/users/verify-email?token=" + url.QueryEscape(rawToken)
+}
+
+func (s *service) verifyLinkTTLMinutes() int {
+	if ttl := s.config.VerifyLink.TTLMinutes; ttl > 0 {
+		return ttl
+	}
+	return 1440
+}
+
+// ConfirmEmailVerificationLink redeems a verification link token and returns where to send the
+// browser: VERIFY_EMAIL_REDIRECT_URL with a status parameter. Unknown, replaced and expired tokens
+// are reported there as "invalid"; the error is only set when link verification is disabled.
+func (s *service) ConfirmEmailVerificationLink(ctx context.Context, token string) (string, error) {
+	target := s.config.VerifyLink.RedirectURL
+	if target == "" {
+		return "", ErrNotFound.WithDetail("email verification links are not enabled")
+	}
+	return withQueryParam(target, "status", s.redeemEmailVerifyToken(ctx, token)), nil
+}
+
+// redeemEmailVerifyToken verifies the email of token's user, returning the status. The token is
+// not consumed: verifying is idempotent and signs nobody in, and mail scanners that open links
+// before the user does would otherwise leave them an "invalid" page.
+func (s *service) redeemEmailVerifyToken(ctx context.Context, token string) string {
+	if token == "" {
+		return verifyLinkStatusInvalid
+	}
+	at, err := s.repo.FindActionTokenByHash(ctx, hashToken(token), actionTokenEmailVerify)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return verifyLinkStatusInvalid
+		}
+		s.logger.Error("verify link: find token failed", "error", err)
+		return verifyLinkStatusError
+	}
+	if s.clock.Now().After(at.ExpiresAt) {
+		return verifyLinkStatusInvalid
+	}
+
+	user, err := s.repo.FindByID(ctx, at.UserID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return verifyLinkStatusInvalid
+		}
+		s.logger.Error("verify link: find user failed", "error", err, "user_id", at.UserID)
+		return verifyLinkStatusError
+	}
+	if user.DeletedAt != nil {
+		return verifyLinkStatusInvalid
+	}
+	if user.EmailVerified {
+		// Already verified (e.g., with the code) - idempotent success
+		return verifyLinkStatusVerified
+	}
+	if err := s.markEmailVerified(ctx, user); err != nil {
+		return verifyLinkStatusError
+	}
+	// The code sent with the link is no longer needed.
+	if vc, err := s.repo.GetActiveVerificationCodeByUser(ctx, user.ID, VerificationPurposeEmailVerify, VerificationChannelEmail); err == nil {
+		if err := s.repo.ConsumeVerificationCode(ctx, vc.ID); err != nil && !errors.Is(err, ErrNotFound) {
+			s.logger.Warn("verify link: consume code failed", "error", err, "user_id", user.ID)
+		}
+	}
+	s.logger.Info("email verified via link", "user_id", user.ID)
+	return verifyLinkStatusVerified
+}
+
+// withQueryParam returns rawURL with key=value added to its query. An unparsable rawURL is
+// returned with the parameter appended.
+func withQueryParam(rawURL, key, value string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		sep := "?"
+		if strings.Contains(rawURL, "?") {
+			sep = "&"
+		}
+		return rawURL + sep + url.QueryEscape(key) + "=" + url.QueryEscape(value)
+	}
+	q := u.Query()
+	q.Set(key, value)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
@@ -17,28 +17,95 @@ type User struct {
 	PasswordResetTokenExpiry *time.Time `db:"password_reset_token_expiry"`
 	CreatedAt                time.Time  `db:"created_at"`
 	UpdatedAt                time.Time  `db:"updated_at"`
+	DeletedAt                *time.Time `db:"deleted_at"` // Set when soft-deleted; restorable during the grace period
+	ReengagementSentAt       *time.Time `db:"reengagement_sent_at"`
+	AnonymizedAt             *time.Time `db:"anonymized_at"`
+	Role                     Role       `db:"role"`
+	PasswordResetRequired    bool       `db:"password_reset_required"` // Set by admins; blocks password login until reset
+	Phone                    *string    `db:"phone"`                   // E.164; nil when no phone is on file. Phone-only accounts have an empty Email
+	PhoneVerified            bool       `db:"phone_verified"`
+	SuspendedAt              *time.Time `db:"suspended_at"` // Set by admins; blocks every sign-in method until lifted
+	SuspendedReason          *string    `db:"suspended_reason"`
+	Locale                   string     `db:"locale"`   // BCP 47 language tag for notifications; empty = English
+	TimeZone                 string     `db:"timezone"` // IANA zone for times in notifications; empty = UTC
+	OnboardingUnsubscribedAt *time.Time `db:"onboarding_unsubscribed_at"` // Set when the user opts out of onboarding emails
+	TermsVersion             *string    `db:"terms_version"`              // Terms-of-service version last accepted; nil if none
+	TermsAcceptedAt          *time.Time `db:"terms_accepted_at"`
 }
 
+// UserFilter narrows the admin user list. Zero values match everything.
+type UserFilter struct {
+	// EmailVerified, when set, keeps only users whose email verification matches.
+	EmailVerified *bool
+	// Suspended, when set, keeps only suspended (true) or active (false) users.
+	Suspended *bool
+	// Query matches a case-insensitive substring of the email, first name, or last name.
+	Query string
+}
+
+// Role is a coarse authorization role.
+type Role string
+
+const (
+	RoleUser  Role = "user"
+	RoleAdmin Role = "admin"
+)
+
 type OAuthProvider string
 
 const (
-	OAuthProviderGOOGLE   OAuthProvider = "google"
-	OAuthProviderFACEBOOK OAuthProvider = "facebook"
-	OAuthProviderGITHUB   OAuthProvider = "github"
-	OAuthProviderX        OAuthProvider = "x"
-	OAuthProviderLINKEDIN OAuthProvider = "linkedin"
+	OAuthProviderGOOGLE    OAuthProvider = "google"
+	OAuthProviderAPPLE     OAuthProvider = "apple"
+	OAuthProviderFACEBOOK  OAuthProvider = "facebook"
+	OAuthProviderGITHUB    OAuthProvider = "github"
+	OAuthProviderX         OAuthProvider = "x"
+	OAuthProviderLINKEDIN  OAuthProvider = "linkedin"
+	OAuthProviderMICROSOFT OAuthProvider = "microsoft"
+	// OAuthProviderOIDC is the generic OpenID Connect provider configured by OIDC_* settings.
+	OAuthProviderOIDC OAuthProvider = "oidc"
 )
 
+// supportedOAuthProviders lists the providers with a working implementation.
+// Keep in sync with newOAuthProvider and the `enum` tags on the OAuth DTOs.
+var supportedOAuthProviders = []OAuthProvider{OAuthProviderGOOGLE, OAuthProviderAPPLE, OAuthProviderMICROSOFT, OAuthProviderOIDC}
+
+// IsSupported reports whether p has a working OAuth implementation.
+func (p OAuthProvider) IsSupported() bool {
+	for _, sp := range supportedOAuthProviders {
+		if p == sp {
+			return true
+		}
+	}
+	return false
+}
+
 type OAuthState struct {
 	State     string        `db:"state"`
 	Provider  OAuthProvider `db:"provider"`
 	UserID    *string       `db:"user_id"`
 	Verifier  string        `db:"verifier"`
+	Nonce     *string       `db:"nonce"` // Apple only: echoed in the id_token
 	ExpiresAt time.Time     `db:"expires_at"`
 	CreatedAt time.Time     `db:"created_at"`
 	UpdatedAt time.Time     `db:"updated_at"`
 }
 
+// OAuthAccount is a provider identity linked to a user. OAuth logins resolve the user through
+// (Provider, ProviderUserID); Email is what the provider reported when the link was made.
+type OAuthAccount struct {
+	ID             string        `db:"id"`
+	UserID         string        `db:"user_id"`
+	Provider       OAuthProvider `db:"provider"`
+	ProviderUserID string        `db:"provider_user_id"`
+	Email          string        `db:"email"`
+	// Provider tokens sealed with the service's secretbox; nil when not stored.
+	AccessToken    []byte     `db:"access_token"`
+	RefreshToken   []byte     `db:"refresh_token"`
+	TokenExpiresAt *time.Time `db:"token_expires_at"`
+	CreatedAt      time.Time  `db:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at"`
+}
+
 type UserActiveSession struct {
 	ID           string    `db:"id"`
 	UserID       string    `db:"user_id"`
@@ -52,12 +119,16 @@ type UserActiveSession struct {
 
 // --- Verification & Reset Types ---
 
-// VerificationPurpose defines the reason a 6-digit code is issued.
+// VerificationPurpose defines the reason a one-time code is issued.
 type VerificationPurpose string
 
 const (
 	VerificationPurposeEmailVerify  VerificationPurpose = "email_verify"
 	VerificationPurposePasswordReset VerificationPurpose = "password_reset"
+	VerificationPurposeAccountRestore VerificationPurpose = "account_restore"
+	VerificationPurposePhoneLogin VerificationPurpose = "phone_login"
+	VerificationPurposeEmailChange VerificationPurpose = "email_change"
+	VerificationPurposeLoginStepUp VerificationPurpose = "login_step_up"
 )
 
 // VerificationChannel defines the medium used to deliver a verification code.
@@ -65,9 +136,18 @@ type VerificationChannel string
 
 const (
 	VerificationChannelEmail VerificationChannel = "email"
+	VerificationChannelSMS   VerificationChannel = "sms"
 )
 
-// VerificationCode represents a one-time 6-digit verification code issued to a user/contact.
+// channel returns the medium codes for purpose are delivered through.
+func (p VerificationPurpose) channel() VerificationChannel {
+	if p == VerificationPurposePhoneLogin {
+		return VerificationChannelSMS
+	}
+	return VerificationChannelEmail
+}
+
+// VerificationCode represents a one-time verification code issued to a user/contact.
 type VerificationCode struct {
 	ID          string               `db:"id"`
 	UserID      *string              `db:"user_id"`
@@ -92,4 +172,162 @@ type ActionToken struct {
 	ExpiresAt time.Time  `db:"expires_at"`
 	ConsumedAt *time.Time `db:"consumed_at"`
 	CreatedAt time.Time  `db:"created_at"`
-}
\ No newline at end of file
+}
+
+// --- Account Activity ---
+
+// ActivityType identifies a security-relevant account event.
+type ActivityType string
+
+const (
+	ActivityLogin           ActivityType = "login"
+	ActivityNewDevice       ActivityType = "new_device"
+	ActivityPasswordReset   ActivityType = "password_reset"
+	ActivityPasswordChanged ActivityType = "password_changed"
+	ActivityEmailChanged    ActivityType = "email_changed"
+	ActivityEmailVerified   ActivityType = "email_verified"
+	ActivityAccountDeleted  ActivityType = "account_deleted"
+	ActivityAccountRestored ActivityType = "account_restored"
+	ActivityOAuthLinked     ActivityType = "oauth_linked"   // metadata: "provider"
+	ActivityOAuthUnlinked   ActivityType = "oauth_unlinked" // metadata: "provider"
+	ActivityTermsAccepted   ActivityType = "terms_accepted" // metadata: "version"
+	// Admin actions; metadata carries the acting admin ("actorId") and "reason".
+	ActivityAdminForcedPasswordReset ActivityType = "admin_forced_password_reset"
+	ActivityAdminForcedReverification ActivityType = "admin_forced_reverification"
+	ActivityAdminAnonymized           ActivityType = "admin_anonymized"
+	ActivityAdminSuspended            ActivityType = "admin_suspended"
+	ActivityAdminUnsuspended          ActivityType = "admin_unsuspended"
+	ActivityAdminImpersonated         ActivityType = "admin_impersonated"
+	// ActivityAdminPromoted records a bootstrap promotion; metadata carries "source" (config or cli).
+	ActivityAdminPromoted ActivityType = "admin_promoted"
+)
+
+// ActivityEvent is a single entry in a user's security activity timeline.
+type ActivityEvent struct {
+	ID        string         `db:"id"` // UUIDv7, time-ordered
+	UserID    string         `db:"user_id"`
+	Type      ActivityType   `db:"event_type"`
+	IPAddress string         `db:"ip_address"`
+	UserAgent string         `db:"user_agent"`
+	Metadata  map[string]any `db:"metadata"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+// --- Account Lifecycle ---
+
+// LifecycleAction identifies an automated account cleanup action.
+type LifecycleAction string
+
+const (
+	LifecycleDeletedUnverified LifecycleAction = "deleted_unverified"
+	LifecycleReengagementSent  LifecycleAction = "reengagement_sent"
+	LifecycleAnonymized        LifecycleAction = "anonymized"
+)
+
+// --- Data Retention ---
+
+// RetentionTarget identifies a set of records with a retention policy.
+type RetentionTarget string
+
+const (
+	RetentionAudit             RetentionTarget = "audit"              // account_lifecycle_audit
+	RetentionActivity          RetentionTarget = "activity"           // user_activity_events (login history)
+	RetentionDeliveries        RetentionTarget = "deliveries"         // notification_outbox
+	RetentionVerificationCodes RetentionTarget = "verification_codes" // verification_codes
+)
+
+// RetentionPolicy is how long records of Target are kept; Days == 0 keeps them forever.
+type RetentionPolicy struct {
+	Target RetentionTarget
+	Table  string
+	Days   int
+}
+
+// RetentionReport counts the records deleted per target in one retention pass.
+type RetentionReport struct {
+	Deleted map[RetentionTarget]int64
+}
+
+// Tables whose rows are deleted as soon as they expire (see Service.PurgeExpired).
+const (
+	ExpiredActionTokens      = "action_tokens"
+	ExpiredOAuthStates       = "oauth_states"
+	ExpiredVerificationCodes = "verification_codes"
+)
+
+// --- Bulk Session Revocation ---
+
+// SessionRevocationStatus is the state of a bulk session revocation job.
+type SessionRevocationStatus string
+
+const (
+	SessionRevocationRunning   SessionRevocationStatus = "running"
+	SessionRevocationCompleted SessionRevocationStatus = "completed"
+	SessionRevocationFailed    SessionRevocationStatus = "failed"
+)
+
+// SessionRevocationCriteria selects the sessions an admin revokes in bulk. Set criteria are
+// combined with AND.
+type SessionRevocationCriteria struct {
+	CreatedBefore   *time.Time `db:"created_before"`
+	IPRange         *string    `db:"ip_range"` // CIDR, e.g. "203.0.113.0/24"
+	UnverifiedUsers bool       `db:"unverified_users"`
+}
+
+// SessionRevocation records a bulk session revocation and its progress.
+type SessionRevocation struct {
+	ID      string `db:"id"`
+	ActorID string `db:"actor_id"`
+	SessionRevocationCriteria
+	Reason     string                  `db:"reason"`
+	Status     SessionRevocationStatus `db:"status"`
+	Revoked    int64                   `db:"revoked"`
+	Batches    int                     `db:"batches"`
+	Error      *string                 `db:"error"`
+	CreatedAt  time.Time               `db:"created_at"`
+	UpdatedAt  time.Time               `db:"updated_at"`
+	FinishedAt *time.Time              `db:"finished_at"`
+}
+
+// --- Admin Broadcasts ---
+
+// BroadcastAudience selects the users an announcement is sent to.
+type BroadcastAudience string
+
+const (
+	// BroadcastAudienceVerified is every live (not deleted, suspended or anonymized) user with a verified email.
+	BroadcastAudienceVerified BroadcastAudience = "verified"
+	// BroadcastAudienceInactive narrows BroadcastAudienceVerified to users with no login for InactiveDays.
+	BroadcastAudienceInactive BroadcastAudience = "inactive"
+)
+
+// BroadcastStatus is the state of an admin broadcast.
+type BroadcastStatus string
+
+const (
+	BroadcastRunning   BroadcastStatus = "running"
+	BroadcastCompleted BroadcastStatus = "completed"
+	BroadcastFailed    BroadcastStatus = "failed"
+	BroadcastCancelled BroadcastStatus = "cancelled"
+)
+
+// Broadcast records an announcement emailed to an audience and its progress. Recipients are
+// processed in user ID order; Cursor is the last user ID handled.
+type Broadcast struct {
+	ID           string            `db:"id"`
+	ActorID      string            `db:"actor_id"`
+	Audience     BroadcastAudience `db:"audience"`
+	InactiveDays *int              `db:"inactive_days"`
+	Subject      string            `db:"subject"`
+	Message      string            `db:"message"`
+	Reason       string            `db:"reason"`
+	Status       BroadcastStatus   `db:"status"`
+	Sent         int64             `db:"sent"`
+	Failed       int64             `db:"failed"`
+	Batches      int               `db:"batches"`
+	Cursor       *string           `db:"cursor"`
+	Error        *string           `db:"error"`
+	CreatedAt    time.Time         `db:"created_at"`
+	UpdatedAt    time.Time         `db:"updated_at"`
+	FinishedAt   *time.Time        `db:"finished_at"`
+}
@@ -0,0 +1,65 @@
+package notification
+
+import (
+	"context"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/runbook"
+)
+
+// dispatchStallAfter is how long a channel dispatch may stay in flight (sending or waiting
+// between retries) before notifications are reported stalled on the admin runbook.
+const dispatchStallAfter = 5 * time.Minute
+
+// Backlog is the notification service's unsent work as shown on the admin runbook.
+type Backlog struct {
+	DryRun bool `json:"dryRun"`
+	// InFlight counts this instance's channel dispatches not yet sent or dead-lettered.
+	InFlight         int        `json:"inFlight"`
+	OldestInFlightAt *time.Time `json:"oldestInFlightAt,omitempty"`
+	// PendingDeadLetters counts dispatches whose attempts were exhausted and that no admin
+	// has requeued yet (shared by every instance).
+	PendingDeadLetters   int64      `json:"pendingDeadLetters"`
+	OldestDeadLetterAt   *time.Time `json:"oldestDeadLetterAt,omitempty"`
+	DeadLetterStoreError string     `json:"deadLetterStoreError,omitempty"`
+}
+
+// Status implements runbook.Reporter. Notifications are stalled when a dispatch has been in
+// flight longer than dispatchStallAfter; pending dead letters need an admin but do not
+// block new sends, so they are reported without counting as stalled.
+func (s *service) Status(ctx context.Context) runbook.Status {
+	backlog := Backlog{DryRun: s.cfg.DryRun}
+
+	s.queuedMu.Lock()
+	backlog.InFlight = len(s.queued)
+	for _, at := range s.queued {
+		if backlog.OldestInFlightAt == nil || at.Before(*backlog.OldestInFlightAt) {
+			backlog.OldestInFlightAt = &at
+		}
+	}
+	s.queuedMu.Unlock()
+
+	if s.cfg.DeadLetters != nil {
+		count, oldest, err := s.cfg.DeadLetters.Pending(ctx)
+		if err != nil {
+			backlog.DeadLetterStoreError = err.Error()
+		}
+		backlog.PendingDeadLetters, backlog.OldestDeadLetterAt = count, oldest
+	}
+
+	stalled := backlog.OldestInFlightAt != nil && time.Since(*backlog.OldestInFlightAt) > dispatchStallAfter
+	return runbook.Status{Stalled: stalled, Details: backlog}
+}
+
+// track and untrack bracket a channel dispatch, so Status can report what is still unsent.
+func (s *service) track(id string) {
+	s.queuedMu.Lock()
+	s.queued[id] = time.Now()
+	s.queuedMu.Unlock()
+}
+
+func (s *service) untrack(id string) {
+	s.queuedMu.Lock()
+	delete(s.queued, id)
+	s.queuedMu.Unlock()
+}
@@ -0,0 +1,202 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// DeadLetterStatus is the state of a dead-lettered notification.
+type DeadLetterStatus string
+
+const (
+	DeadLetterPending  DeadLetterStatus = "pending"
+	DeadLetterRequeued DeadLetterStatus = "requeued"
+)
+
+var (
+	// ErrDeadLetterNotFound is returned when no dead letter has the given ID.
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+	// ErrDeadLetterNotPending is returned when editing or requeueing an already requeued dead letter.
+	ErrDeadLetterNotPending = errors.New("dead letter is not pending")
+)
+
+// DeadLetter is a single channel dispatch whose delivery attempts were exhausted.
+// Content holds the rendered message, so it can be requeued without re-rendering.
+type DeadLetter struct {
+	ID         string
+	Channel    Channel
+	Recipient  string
+	Priority   Priority
+	TemplateID string
+	Content    Content
+	Attempts   int
+	LastError  string
+	Status     DeadLetterStatus
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	RequeuedAt *time.Time
+}
+
+// DeadLetterStore persists dead-lettered notifications.
+type DeadLetterStore interface {
+	Add(ctx context.Context, d *DeadLetter) error
+	// List returns dead letters with the given status (all when empty), newest first,
+	// with IDs lower than beforeID when set.
+	List(ctx context.Context, status DeadLetterStatus, beforeID string, limit int) ([]*DeadLetter, error)
+	Get(ctx context.Context, id string) (*DeadLetter, error)
+	// UpdateRecipient changes the recipient of a pending dead letter.
+	UpdateRecipient(ctx context.Context, id, recipient string) (*DeadLetter, error)
+	// MarkRequeued flips a pending dead letter to requeued and returns it. Only one caller
+	// can win, so a message is never requeued twice.
+	MarkRequeued(ctx context.Context, id string) (*DeadLetter, error)
+	// Pending counts the dead letters awaiting an admin and returns the oldest one's
+	// creation time (nil when there are none).
+	Pending(ctx context.Context) (count int64, oldest *time.Time, err error)
+}
+
+type postgresDeadLetters struct {
+	db        database.DBTX
+	namespace string
+}
+
+// NewPostgresDeadLetters returns a DeadLetterStore backed by the notification_dead_letters table.
+// Dead letters are scoped to namespace: other environments' rows are neither listed nor requeued.
+func NewPostgresDeadLetters(db database.DBTX, namespace string) DeadLetterStore {
+	return &postgresDeadLetters{db: db, namespace: namespace}
+}
+
+const deadLetterColumns = `id, channel, recipient, priority, COALESCE(template_id, ''), content, attempts, last_error, status, created_at, updated_at, requeued_at`
+
+func (s *postgresDeadLetters) Add(ctx context.Context, d *DeadLetter) error {
+	if d.ID == "" {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return fmt.Errorf("failed to generate dead letter id: %w", err)
+		}
+		d.ID = id.String()
+	}
+	now := time.Now()
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = now
+	}
+	d.UpdatedAt = d.CreatedAt
+	if d.Status == "" {
+		d.Status = DeadLetterPending
+	}
+	content, err := json.Marshal(d.Content)
+	if err != nil {
+		return fmt.Errorf("failed to encode dead letter content: %w", err)
+	}
+
+	sql := `
+		INSERT INTO notification_dead_letters
+			(id, channel, recipient, priority, template_id, content, attempts, last_error, status, created_at, updated_at, namespace)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10, $11)
+	`
+	_, err = s.db.Exec(ctx, sql, d.ID, string(d.Channel), d.Recipient, string(d.Priority), nullable(d.TemplateID), content, d.Attempts, d.LastError, string(d.Status), d.CreatedAt, s.namespace)
+	if err != nil {
+		return fmt.Errorf("failed to insert dead letter: %w", err)
+	}
+	return nil
+}
+
+func (s *postgresDeadLetters) List(ctx context.Context, status DeadLetterStatus, beforeID string, limit int) ([]*DeadLetter, error) {
+	sql := `
+		SELECT ` + deadLetterColumns + ` FROM notification_dead_letters
+		WHERE namespace = $4 AND ($1 = '' OR status = $1) AND ($2 = '' OR id < $2::uuid)
+		ORDER BY id DESC
+		LIMIT $3
+	`
+	rows, err := s.db.Query(ctx, sql, string(status), beforeID, limit, s.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	var out []*DeadLetter
+	for rows.Next() {
+		d, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (s *postgresDeadLetters) Get(ctx context.Context, id string) (*DeadLetter, error) {
+	row := s.db.QueryRow(ctx, `SELECT `+deadLetterColumns+` FROM notification_dead_letters WHERE id = $1 AND namespace = $2`, id, s.namespace)
+	return scanDeadLetter(row)
+}
+
+func (s *postgresDeadLetters) UpdateRecipient(ctx context.Context, id, recipient string) (*DeadLetter, error) {
+	row := s.db.QueryRow(ctx, `
+		UPDATE notification_dead_letters SET recipient = $2, updated_at = NOW()
+		WHERE id = $1 AND namespace = $3 AND status = 'pending'
+		RETURNING `+deadLetterColumns, id, recipient, s.namespace)
+	return s.pendingOnly(ctx, id, row)
+}
+
+func (s *postgresDeadLetters) MarkRequeued(ctx context.Context, id string) (*DeadLetter, error) {
+	row := s.db.QueryRow(ctx, `
+		UPDATE notification_dead_letters SET status = 'requeued', requeued_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND namespace = $2 AND status = 'pending'
+		RETURNING `+deadLetterColumns, id, s.namespace)
+	return s.pendingOnly(ctx, id, row)
+}
+
+func (s *postgresDeadLetters) Pending(ctx context.Context) (int64, *time.Time, error) {
+	var (
+		count  int64
+		oldest *time.Time
+	)
+	err := s.db.QueryRow(ctx, `
+		SELECT COUNT(*), MIN(created_at) FROM notification_dead_letters
+		WHERE namespace = $1 AND status = 'pending'
+	`, s.namespace).Scan(&count, &oldest)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to count pending dead letters: %w", err)
+	}
+	return count, oldest, nil
+}
+
+// pendingOnly scans the result of a conditional update, telling a missing dead letter
+// apart from one that is no longer pending.
+func (s *postgresDeadLetters) pendingOnly(ctx context.Context, id string, row pgx.Row) (*DeadLetter, error) {
+	d, err := scanDeadLetter(row)
+	if !errors.Is(err, ErrDeadLetterNotFound) {
+		return d, err
+	}
+	if _, getErr := s.Get(ctx, id); getErr != nil {
+		return nil, getErr
+	}
+	return nil, ErrDeadLetterNotPending
+}
+
+func scanDeadLetter(row pgx.Row) (*DeadLetter, error) {
+	var (
+		d                         DeadLetter
+		channel, priority, status string
+		content                   []byte
+	)
+	err := row.Scan(&d.ID, &channel, &d.Recipient, &priority, &d.TemplateID, &content, &d.Attempts, &d.LastError, &status, &d.CreatedAt, &d.UpdatedAt, &d.RequeuedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDeadLetterNotFound
+		}
+		return nil, fmt.Errorf("failed to scan dead letter: %w", err)
+	}
+	if err := json.Unmarshal(content, &d.Content); err != nil {
+		return nil, fmt.Errorf("failed to decode dead letter content: %w", err)
+	}
+	d.Channel, d.Priority, d.Status = Channel(channel), Priority(priority), DeadLetterStatus(status)
+	return &d, nil
+}
@@ -4,51 +4,183 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	netmail "net/mail"
+	"strings"
 	"time"
 
 	mail "github.com/xhit/go-simple-mail/v2"
 )
 
+// SMTPEncryption is how the connection to the SMTP server is secured.
+type SMTPEncryption string
+
+const (
+	SMTPEncryptionSTARTTLS SMTPEncryption = "starttls" // plain connection upgraded with STARTTLS (port 587)
+	SMTPEncryptionTLS      SMTPEncryption = "tls"      // implicit TLS from the first byte (port 465)
+	SMTPEncryptionNone     SMTPEncryption = "none"     // unencrypted; local development servers only
+)
+
+// ParseSMTPEncryption validates an encryption mode name; "" means SMTPEncryptionSTARTTLS
+// and "ssl" is accepted as an alias of "tls".
+func ParseSMTPEncryption(s string) (SMTPEncryption, error) {
+	switch m := SMTPEncryption(strings.ToLower(strings.TrimSpace(s))); m {
+	case "":
+		return SMTPEncryptionSTARTTLS, nil
+	case "ssl", "ssl/tls":
+		return SMTPEncryptionTLS, nil
+	case SMTPEncryptionSTARTTLS, SMTPEncryptionTLS, SMTPEncryptionNone:
+		return m, nil
+	default:
+		return "", fmt.Errorf("notification: unknown SMTP encryption %q (want starttls, tls, or none)", s)
+	}
+}
+
+// SMTPAuth is the SMTP authentication mechanism.
+type SMTPAuth string
+
+const (
+	SMTPAuthAuto    SMTPAuth = "auto" // first mechanism advertised by the server
+	SMTPAuthPlain   SMTPAuth = "plain"
+	SMTPAuthLogin   SMTPAuth = "login"
+	SMTPAuthCRAMMD5 SMTPAuth = "cram-md5"
+	SMTPAuthNone    SMTPAuth = "none" // servers without authentication (e.g. Mailpit, MailHog)
+)
+
+// ParseSMTPAuth validates an authentication mechanism name; "" means SMTPAuthAuto.
+func ParseSMTPAuth(s string) (SMTPAuth, error) {
+	switch m := SMTPAuth(strings.ToLower(strings.TrimSpace(s))); m {
+	case "":
+		return SMTPAuthAuto, nil
+	case SMTPAuthAuto, SMTPAuthPlain, SMTPAuthLogin, SMTPAuthCRAMMD5, SMTPAuthNone:
+		return m, nil
+	default:
+		return "", fmt.Errorf("notification: unknown SMTP auth mechanism %q (want auto, plain, login, cram-md5, or none)", s)
+	}
+}
+
+// SMTPConfig configures the SMTP email sender.
+type SMTPConfig struct {
+	Host       string
+	Port       int
+	Username   string
+	Password   string
+	Encryption SMTPEncryption // "" means SMTPEncryptionSTARTTLS
+	Auth       SMTPAuth       // "" means SMTPAuthAuto
+	// HeloName is the hostname announced in EHLO/HELO. Default: "localhost"; some relays
+	// reject that and want the sending host's FQDN.
+	HeloName string
+	// From may include a display name ("Acme <no-reply@acme.com>", see FormatAddress).
+	From    string
+	ReplyTo string // optional
+}
+
+// smtpEncryptions and smtpAuths map the config values to the mail client's settings.
+var (
+	smtpEncryptions = map[SMTPEncryption]mail.Encryption{
+		"":                     mail.EncryptionSTARTTLS,
+		SMTPEncryptionSTARTTLS: mail.EncryptionSTARTTLS,
+		SMTPEncryptionTLS:      mail.EncryptionSSLTLS,
+		SMTPEncryptionNone:     mail.EncryptionNone,
+	}
+	smtpAuths = map[SMTPAuth]mail.AuthType{
+		"":              mail.AuthAuto,
+		SMTPAuthAuto:    mail.AuthAuto,
+		SMTPAuthPlain:   mail.AuthPlain,
+		SMTPAuthLogin:   mail.AuthLogin,
+		SMTPAuthCRAMMD5: mail.AuthCRAMMD5,
+		SMTPAuthNone:    mail.AuthNone,
+	}
+)
+
 // smtpEmailSender is the concrete implementation for sending emails via SMTP.
 type smtpEmailSender struct {
-	client *mail.SMTPServer
-	from   string
-	log    *slog.Logger
+	client  *mail.SMTPServer
+	from    string
+	replyTo string
+	log     *slog.Logger
 }
 
 // NewSMTPEmailSender creates a new sender that uses an SMTP server.
-func NewSMTPEmailSender(host string, port int, username, password, from string, log *slog.Logger) emailSender {
+func NewSMTPEmailSender(cfg SMTPConfig, log *slog.Logger) (emailSender, error) {
+	encryption, ok := smtpEncryptions[cfg.Encryption]
+	if !ok {
+		return nil, fmt.Errorf("notification: unknown SMTP encryption %q", cfg.Encryption)
+	}
+	auth, ok := smtpAuths[cfg.Auth]
+	if !ok {
+		return nil, fmt.Errorf("notification: unknown SMTP auth mechanism %q", cfg.Auth)
+	}
+
 	server := mail.NewSMTPClient()
-	server.Host = host
-	server.Port = port
-	server.Username = username
-	server.Password = password
-	server.Encryption = mail.EncryptionSTARTTLS
+	server.Host = cfg.Host
+	server.Port = cfg.Port
+	server.Username = cfg.Username
+	server.Password = cfg.Password
+	server.Encryption = encryption
+	server.Authentication = auth
+	if cfg.HeloName != "" {
+		server.Helo = cfg.HeloName
+	}
 	server.KeepAlive = false
 	server.ConnectTimeout = 10 * time.Second
 	server.SendTimeout = 10 * time.Second
 
 	return &smtpEmailSender{
-		client: server,
-		from:   from,
-		log:    log,
-	}
+		client:  server,
+		from:    cfg.From,
+		replyTo: cfg.ReplyTo,
+		log:     log,
+	}, nil
 }
 
-func (s *smtpEmailSender) Send(ctx context.Context, to, subject, htmlBody string) error {
+func (s *smtpEmailSender) Send(ctx context.Context, msg EmailMessage) error {
 	smtpClient, err := s.client.Connect()
 	if err != nil {
 		return fmt.Errorf("failed to connect to SMTP server: %w", err)
 	}
 
+	from := msg.From
+	if from == "" {
+		from = s.from
+	}
+	replyTo := msg.ReplyTo
+	if replyTo == "" {
+		replyTo = s.replyTo
+	}
+
 	email := mail.NewMSG()
-	email.SetFrom(s.from).AddTo(to).SetSubject(subject)
-	email.SetBody(mail.TextHTML, htmlBody)
+	email.SetFrom(from).AddTo(msg.To).SetSubject(msg.Subject)
+	if replyTo != "" {
+		email.SetReplyTo(replyTo)
+	}
+	// multipart/alternative: plain text first, HTML as the preferred alternative.
+	switch {
+	case msg.TextBody != "" && msg.HTMLBody != "":
+		email.SetBody(mail.TextPlain, msg.TextBody)
+		email.AddAlternative(mail.TextHTML, msg.HTMLBody)
+	case msg.TextBody != "":
+		email.SetBody(mail.TextPlain, msg.TextBody)
+	default:
+		email.SetBody(mail.TextHTML, msg.HTMLBody)
+	}
+	if email.Error != nil {
+		return fmt.Errorf("failed to build email: %w", email.Error)
+	}
 
 	if err = email.Send(smtpClient); err != nil {
 		return fmt.Errorf("failed to send email: %w", err)
 	}
 
-	s.log.Info("email sent via smtp", "to", to)
+	s.log.Info("email sent via smtp", "to", msg.To)
 	return nil
 }
+
+// FormatAddress combines a display name and an address into an RFC 5322 mailbox
+// ("Acme <no-reply@acme.com>"). addr is returned unchanged when name is empty or
+// addr already carries a display name.
+func FormatAddress(name, addr string) string {
+	if name == "" || addr == "" || strings.Contains(addr, "<") {
+		return addr
+	}
+	return (&netmail.Address{Name: name, Address: addr}).String()
+}
@@ -0,0 +1,49 @@
+package notification
+
+import "context"
+
+// Event describes a single channel dispatch, passed to Hook callbacks.
+type Event struct {
+	Channel    Channel
+	Recipient  string
+	Priority   Priority
+	TemplateID string
+	Test       bool // sent by an admin template test-send
+}
+
+// Hook observes the lifecycle of notification dispatches, e.g. to track delivery
+// rates in an analytics system. Callbacks run on the dispatch path (OnSent and
+// OnFailed in the per-channel goroutine), so they must be fast and non-blocking.
+type Hook interface {
+	// OnQueued is called when a channel dispatch is accepted by Send.
+	OnQueued(ctx context.Context, e Event)
+	// OnSent is called when the provider accepted the message.
+	OnSent(ctx context.Context, e Event)
+	// OnFailed is called when the provider returned an error.
+	OnFailed(ctx context.Context, e Event, err error)
+}
+
+// HookFuncs adapts plain functions to the Hook interface. Nil funcs are no-ops.
+type HookFuncs struct {
+	Queued func(ctx context.Context, e Event)
+	Sent   func(ctx context.Context, e Event)
+	Failed func(ctx context.Context, e Event, err error)
+}
+
+func (h HookFuncs) OnQueued(ctx context.Context, e Event) {
+	if h.Queued != nil {
+		h.Queued(ctx, e)
+	}
+}
+
+func (h HookFuncs) OnSent(ctx context.Context, e Event) {
+	if h.Sent != nil {
+		h.Sent(ctx, e)
+	}
+}
+
+func (h HookFuncs) OnFailed(ctx context.Context, e Event, err error) {
+	if h.Failed != nil {
+		h.Failed(ctx, e, err)
+	}
+}
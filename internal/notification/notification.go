@@ -3,9 +3,14 @@ package notification
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"sync"
+	"time"
 
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
 	"github.com/delordemm1/go-api-simple-starter/internal/notification/templates"
+	"github.com/google/uuid"
 )
 
 // --- Constants for Type Safety ---
@@ -31,6 +36,9 @@ const (
 type Content struct {
 	EmailSubject   string
 	EmailHTMLBody  string
+	EmailTextBody  string // Plain-text alternative sent alongside the HTML body
+	EmailFrom      string // Optional sender override (defaults to the configured From)
+	EmailReplyTo   string // Optional Reply-To override (defaults to the configured Reply-To)
 	SMSText        string
 	PushTitle      string
 	PushBody       string
@@ -39,16 +47,32 @@ type Content struct {
 
 // Notification is the universal object used to send any notification.
 type Notification struct {
-	Recipient string    // Can be an email address, phone number, or device token
-	Channels  []Channel // A list of channels to send to
-	Priority  Priority
-	Content   Content
+	Recipient  string    // Can be an email address, phone number, or device token
+	Channels   []Channel // A list of channels to send to
+	Priority   Priority
+	Content    Content
+	TemplateID string // Set when the content was rendered from a template (for the outbox)
+	// Test marks an operator test send: the email subject is prefixed with "[TEST]", the
+	// outbox entry and hook events are flagged, and failures are not dead-lettered.
+	Test bool
 }
 
 // --- Internal Sender Interfaces ---
 // These are not exposed outside the package.
 type emailSender interface {
-	Send(ctx context.Context, to, subject, htmlBody string) error
+	Send(ctx context.Context, msg EmailMessage) error
+}
+
+// EmailMessage is a single email to deliver. When both bodies are set, a
+// multipart/alternative message is sent; either body may be empty.
+// Empty From/ReplyTo fall back to the sender's configured identity.
+type EmailMessage struct {
+	To       string
+	From     string
+	ReplyTo  string
+	Subject  string
+	HTMLBody string
+	TextBody string
 }
 type smsSender interface {
 	Send(ctx context.Context, to, message string) error
@@ -59,9 +83,46 @@ type smsSender interface {
 // Service is the main interface for the notification system.
 type Service interface {
 	Send(ctx context.Context, n Notification) error
+	// Dispatch is Send with a Receipt, so callers can tell "queued" apart from
+	// "provider rejected" by waiting for per-channel outcomes.
+	Dispatch(ctx context.Context, n Notification) (*Receipt, error)
 	// SendTemplateAny renders a template by ID with the provided data and dispatches across channels.
 	// Prefer the typed helper SendTemplate[T](...) for compile-time safety.
 	SendTemplateAny(ctx context.Context, recipient string, channels []Channel, priority Priority, templateID string, data any) error
+	// DispatchTemplateAny is SendTemplateAny with a Receipt (see Dispatch).
+	// Prefer the typed helper DispatchTemplate[T](...).
+	DispatchTemplateAny(ctx context.Context, recipient string, channels []Channel, priority Priority, templateID string, data any) (*Receipt, error)
+	// SendTemplateTest renders a template and sends it through the normal pipeline flagged as
+	// a test (see Notification.Test). Rendering errors are returned; delivery is asynchronous.
+	SendTemplateTest(ctx context.Context, recipient string, channels []Channel, templateID string, data any) error
+
+	// Dead-letter administration (see Config.DeadLetters).
+	ListDeadLetters(ctx context.Context, status DeadLetterStatus, beforeID string, limit int) ([]*DeadLetter, error)
+	GetDeadLetter(ctx context.Context, id string) (*DeadLetter, error)
+	UpdateDeadLetterRecipient(ctx context.Context, id, recipient string) (*DeadLetter, error)
+	// RequeueDeadLetter sends a pending dead letter again; if delivery fails again,
+	// a new dead letter is recorded.
+	RequeueDeadLetter(ctx context.Context, id string) error
+}
+
+// Config holds optional behavior for the notification service.
+type Config struct {
+	// DryRun renders and records notifications but skips provider calls,
+	// logging the rendered output instead. Useful for staging environments.
+	DryRun bool
+	// Outbox, when set, records every channel dispatch (sent, failed, or dry-run).
+	Outbox Outbox
+	// Hooks are notified as each channel dispatch is queued, sent, or fails.
+	// Dry-run dispatches are reported as queued only.
+	Hooks []Hook
+	// MaxAttempts is how many times an email or SMS dispatch is tried before it is
+	// dead-lettered. Default: 3.
+	MaxAttempts int
+	// RetryBackoff is the delay before the second attempt; it doubles after each failure.
+	// Default: 2s.
+	RetryBackoff time.Duration
+	// DeadLetters, when set, keeps dispatches whose attempts were exhausted for admin retry.
+	DeadLetters DeadLetterStore
 }
 
 // service is the concrete implementation.
@@ -70,74 +131,317 @@ type service struct {
 	emailSender      emailSender
 	smsSender        smsSender
 	templateRenderer templates.Renderer
+	cfg              Config
+
+	// inflight tracks channel dispatches still running (including retries), so Stop can
+	// let them finish instead of dropping them during a deploy.
+	inflight sync.WaitGroup
+	// queued maps in-flight dispatch IDs to when they were queued, for the admin runbook.
+	queuedMu sync.Mutex
+	queued   map[string]time.Time
 }
 
 // NewService creates a new notification service.
-func NewService(log *slog.Logger, emailSender emailSender, smsSender smsSender, renderer templates.Renderer) Service {
+func NewService(log *slog.Logger, emailSender emailSender, smsSender smsSender, renderer templates.Renderer, cfg Config) Service {
+	if cfg.DryRun {
+		log.Warn("notifications are in dry-run mode; no messages will be delivered")
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 2 * time.Second
+	}
 	return &service{
 		log:              log,
 		emailSender:      emailSender,
 		smsSender:        smsSender,
 		templateRenderer: renderer,
+		cfg:              cfg,
+		queued:           make(map[string]time.Time),
 	}
 }
 
 // Send acts as a dispatcher, routing the notification to the correct channel sender.
+// It returns immediately; use Dispatch to observe per-channel outcomes.
 func (s *service) Send(ctx context.Context, n Notification) error {
+	_, err := s.Dispatch(ctx, n)
+	return err
+}
+
+// Dispatch sends n like Send and returns a Receipt reporting each channel's outcome.
+func (s *service) Dispatch(ctx context.Context, n Notification) (*Receipt, error) {
+	// Channel sends outlive the call (and the request behind it); keep ctx's values only.
+	ctx = contextx.Detach(ctx)
+	receipt := newReceipt(len(n.Channels))
 	for _, channel := range n.Channels {
+		id, err := uuid.NewV7()
+		if err != nil {
+			// Channels already launched still report; seal so Results closes after them.
+			receipt.seal()
+			return receipt, fmt.Errorf("failed to generate dispatch id: %w", err)
+		}
+		receipt.IDs[channel] = id.String()
+		ev := Event{Channel: channel, Recipient: n.Recipient, Priority: n.Priority, TemplateID: n.TemplateID, Test: n.Test}
+		if channel == ChannelEmail || channel == ChannelSMS {
+			for _, h := range s.cfg.Hooks {
+				h.OnQueued(ctx, ev)
+			}
+		}
 		// Launch each channel send in a separate goroutine for speed.
-		go func(ch Channel) {
-			var err error
+		s.inflight.Add(1)
+		s.track(id.String())
+		receipt.pending.Add(1)
+		go func(ch Channel, id string) {
+			defer s.inflight.Done()
+			defer s.untrack(id)
+			entry := &OutboxEntry{
+				ID:         id,
+				Channel:    ch,
+				Recipient:  n.Recipient,
+				TemplateID: n.TemplateID,
+				Test:       n.Test,
+			}
+			var deliver func() error
 			switch ch {
 			case ChannelEmail:
-				s.log.Info("dispatching email notification", "recipient", n.Recipient)
-				err = s.emailSender.Send(ctx, n.Recipient, n.Content.EmailSubject, n.Content.EmailHTMLBody)
+				entry.Subject, entry.Body = n.Content.EmailSubject, n.Content.EmailHTMLBody
+				deliver = func() error {
+					s.log.Info("dispatching email notification", "recipient", n.Recipient)
+					return s.emailSender.Send(ctx, EmailMessage{
+						To:       n.Recipient,
+						From:     n.Content.EmailFrom,
+						ReplyTo:  n.Content.EmailReplyTo,
+						Subject:  n.Content.EmailSubject,
+						HTMLBody: n.Content.EmailHTMLBody,
+						TextBody: n.Content.EmailTextBody,
+					})
+				}
 			case ChannelSMS:
-				s.log.Info("dispatching sms notification", "recipient", n.Recipient)
-				err = s.smsSender.Send(ctx, n.Recipient, n.Content.SMSText)
+				entry.Body = n.Content.SMSText
+				deliver = func() error {
+					s.log.Info("dispatching sms notification", "recipient", n.Recipient)
+					return s.smsSender.Send(ctx, n.Recipient, n.Content.SMSText)
+				}
 			case ChannelPush:
 				s.log.Warn("push notifications are not yet implemented")
 				// err = s.pushSender.Send(...)
+				receipt.report(ChannelResult{Channel: ch, ID: id, Status: OutboxStatusSkipped})
+				return
 			default:
 				s.log.Warn("unsupported notification channel", "channel", ch)
+				receipt.report(ChannelResult{Channel: ch, ID: id, Status: OutboxStatusSkipped})
+				return
 			}
 
-			if err != nil {
+			var err error
+			attempts := 0
+			if !s.cfg.DryRun {
+				attempts, err = s.withRetry(ch, n.Recipient, deliver)
+			}
+
+			switch {
+			case s.cfg.DryRun:
+				entry.Status = OutboxStatusDryRun
+				s.log.Info("DRY RUN: notification not sent", "channel", ch, "recipient", n.Recipient,
+					"template", n.TemplateID, "subject", entry.Subject, "body", entry.Body)
+			case err != nil:
+				entry.Status = OutboxStatusFailed
+				entry.Error = err.Error()
 				// We can't return an error here, so we must log it for monitoring.
 				s.log.Error("failed to send notification", "channel", ch, "recipient", n.Recipient, "error", err)
+				for _, h := range s.cfg.Hooks {
+					h.OnFailed(ctx, ev, err)
+				}
+				s.deadLetter(ctx, n, ch, attempts, err)
+			default:
+				entry.Status = OutboxStatusSent
+				for _, h := range s.cfg.Hooks {
+					h.OnSent(ctx, ev)
+				}
 			}
-		}(channel)
+			s.record(ctx, entry)
+			receipt.report(ChannelResult{Channel: ch, ID: id, Status: entry.Status, Attempts: attempts, Err: err})
+		}(channel, id.String())
+	}
+	receipt.seal()
+	return receipt, nil
+}
+
+// Stop waits for in-flight dispatches to finish, or for ctx to expire. It implements the
+// bootstrap lifecycle Stopper; sends made after Stop are still dispatched.
+func (s *service) Stop(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// withRetry calls deliver up to MaxAttempts times with exponential backoff and returns the
+// number of attempts made and the last error.
+func (s *service) withRetry(ch Channel, recipient string, deliver func() error) (int, error) {
+	backoff := s.cfg.RetryBackoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = deliver(); err == nil || attempt >= s.cfg.MaxAttempts {
+			return attempt, err
+		}
+		s.log.Warn("notification attempt failed, retrying", "channel", ch, "recipient", recipient, "attempt", attempt, "error", err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// deadLetter keeps a dispatch whose attempts were exhausted, if a store is configured.
+func (s *service) deadLetter(ctx context.Context, n Notification, ch Channel, attempts int, err error) {
+	if s.cfg.DeadLetters == nil || n.Test {
+		return
+	}
+	d := &DeadLetter{
+		Channel:    ch,
+		Recipient:  n.Recipient,
+		Priority:   n.Priority,
+		TemplateID: n.TemplateID,
+		Content:    n.Content,
+		Attempts:   attempts,
+		LastError:  err.Error(),
+	}
+	if err := s.cfg.DeadLetters.Add(contextx.Detach(ctx), d); err != nil {
+		s.log.Error("failed to record dead letter", "channel", ch, "recipient", n.Recipient, "error", err)
+		return
+	}
+	s.log.Warn("notification dead-lettered", "id", d.ID, "channel", ch, "recipient", n.Recipient, "attempts", attempts)
+}
+
+// record stores entry in the outbox, if configured. The request context may already be
+// cancelled by the time an async send finishes, so cancellation is detached.
+func (s *service) record(ctx context.Context, entry *OutboxEntry) {
+	if s.cfg.Outbox == nil {
+		return
+	}
+	if err := s.cfg.Outbox.Record(contextx.Detach(ctx), entry); err != nil {
+		s.log.Error("failed to record outbox entry", "channel", entry.Channel, "error", err)
 	}
-	return nil // Return immediately
 }
 
 // SendTemplateAny renders a template by ID with the provided data and dispatches across channels.
 func (s *service) SendTemplateAny(ctx context.Context, recipient string, channels []Channel, priority Priority, templateID string, data any) error {
+	n, err := s.renderNotification(ctx, recipient, channels, priority, templateID, data)
+	if err != nil {
+		return err
+	}
+	return s.Send(ctx, n)
+}
+
+// DispatchTemplateAny renders a template by ID and dispatches it, returning a Receipt.
+func (s *service) DispatchTemplateAny(ctx context.Context, recipient string, channels []Channel, priority Priority, templateID string, data any) (*Receipt, error) {
+	n, err := s.renderNotification(ctx, recipient, channels, priority, templateID, data)
+	if err != nil {
+		return nil, err
+	}
+	return s.Dispatch(ctx, n)
+}
+
+// SendTemplateTest renders a template and sends it flagged as a test.
+func (s *service) SendTemplateTest(ctx context.Context, recipient string, channels []Channel, templateID string, data any) error {
+	n, err := s.renderNotification(ctx, recipient, channels, PriorityHigh, templateID, data)
+	if err != nil {
+		return err
+	}
+	n.Test = true
+	if n.Content.EmailSubject != "" {
+		n.Content.EmailSubject = "[TEST] " + n.Content.EmailSubject
+	}
+	s.log.Info("sending template test", "template", templateID, "recipient", recipient, "channels", channels)
+	// Detach from the admin request: delivery (and retries) continue after it returns.
+	return s.Send(contextx.Detach(ctx), n)
+}
+
+// renderNotification renders templateID into a Notification ready for Send.
+func (s *service) renderNotification(ctx context.Context, recipient string, channels []Channel, priority Priority, templateID string, data any) (Notification, error) {
 	if s.templateRenderer == nil {
 		s.log.Error("template renderer is not configured")
-		return errors.New("template renderer not configured")
+		return Notification{}, errors.New("template renderer not configured")
 	}
 	rendered, err := s.templateRenderer.RenderAny(ctx, templateID, data)
 	if err != nil {
-		return err
+		return Notification{}, err
 	}
 
-	n := Notification{
-		Recipient: recipient,
-		Channels:  channels,
-		Priority:  priority,
+	return Notification{
+		Recipient:  recipient,
+		Channels:   channels,
+		Priority:   priority,
+		TemplateID: templateID,
 		Content: Content{
 			EmailSubject:  rendered.Subject,
 			EmailHTMLBody: rendered.EmailHTML,
+			EmailTextBody: rendered.EmailText,
+			EmailFrom:     rendered.From,
+			EmailReplyTo:  rendered.ReplyTo,
 			SMSText:       rendered.SMSText,
 			PushTitle:     rendered.PushTitle,
 			PushBody:      rendered.PushBody,
 		},
-	}
-	return s.Send(ctx, n)
+	}, nil
 }
 
 // SendTemplate is a typed helper that preserves compile-time type-safety via a Handle[T].
 func SendTemplate[T any](ctx context.Context, s Service, h templates.Handle[T], recipient string, channels []Channel, priority Priority, data T) error {
 	return s.SendTemplateAny(ctx, recipient, channels, priority, h.ID(), data)
 }
+
+// DispatchTemplate is the typed counterpart of DispatchTemplateAny.
+func DispatchTemplate[T any](ctx context.Context, s Service, h templates.Handle[T], recipient string, channels []Channel, priority Priority, data T) (*Receipt, error) {
+	return s.DispatchTemplateAny(ctx, recipient, channels, priority, h.ID(), data)
+}
+
+// errDeadLettersDisabled is returned by the dead-letter methods when no store is configured.
+var errDeadLettersDisabled = errors.New("dead-letter store not configured")
+
+func (s *service) ListDeadLetters(ctx context.Context, status DeadLetterStatus, beforeID string, limit int) ([]*DeadLetter, error) {
+	if s.cfg.DeadLetters == nil {
+		return nil, errDeadLettersDisabled
+	}
+	return s.cfg.DeadLetters.List(ctx, status, beforeID, limit)
+}
+
+func (s *service) GetDeadLetter(ctx context.Context, id string) (*DeadLetter, error) {
+	if s.cfg.DeadLetters == nil {
+		return nil, errDeadLettersDisabled
+	}
+	return s.cfg.DeadLetters.Get(ctx, id)
+}
+
+func (s *service) UpdateDeadLetterRecipient(ctx context.Context, id, recipient string) (*DeadLetter, error) {
+	if s.cfg.DeadLetters == nil {
+		return nil, errDeadLettersDisabled
+	}
+	return s.cfg.DeadLetters.UpdateRecipient(ctx, id, recipient)
+}
+
+func (s *service) RequeueDeadLetter(ctx context.Context, id string) error {
+	if s.cfg.DeadLetters == nil {
+		return errDeadLettersDisabled
+	}
+	d, err := s.cfg.DeadLetters.MarkRequeued(ctx, id)
+	if err != nil {
+		return err
+	}
+	s.log.Info("requeueing dead letter", "id", d.ID, "channel", d.Channel, "recipient", d.Recipient)
+	// Detach from the admin request: delivery (and retries) continue after it returns.
+	return s.Send(contextx.Detach(ctx), Notification{
+		Recipient:  d.Recipient,
+		Channels:   []Channel{d.Channel},
+		Priority:   d.Priority,
+		Content:    d.Content,
+		TemplateID: d.TemplateID,
+	})
+}
@@ -0,0 +1,82 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/database"
+	"github.com/google/uuid"
+)
+
+// OutboxStatus is the outcome of a single channel dispatch.
+type OutboxStatus string
+
+const (
+	OutboxStatusSent   OutboxStatus = "sent"
+	OutboxStatusFailed OutboxStatus = "failed"
+	OutboxStatusDryRun OutboxStatus = "dry_run"
+)
+
+// OutboxEntry records one channel dispatch of a notification.
+type OutboxEntry struct {
+	ID         string
+	Channel    Channel
+	Recipient  string
+	TemplateID string
+	Subject    string
+	Body       string
+	Status     OutboxStatus
+	Error      string
+	Test       bool // sent by an admin template test-send
+	CreatedAt  time.Time
+}
+
+// Outbox persists dispatch records for auditing and debugging.
+type Outbox interface {
+	Record(ctx context.Context, e *OutboxEntry) error
+}
+
+type postgresOutbox struct {
+	db        database.DBTX
+	namespace string
+}
+
+// NewPostgresOutbox returns an Outbox backed by the notification_outbox table. Entries are
+// tagged with namespace so environments sharing the table can tell their records apart.
+func NewPostgresOutbox(db database.DBTX, namespace string) Outbox {
+	return &postgresOutbox{db: db, namespace: namespace}
+}
+
+func (o *postgresOutbox) Record(ctx context.Context, e *OutboxEntry) error {
+	if e.ID == "" {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return fmt.Errorf("failed to generate outbox id: %w", err)
+		}
+		e.ID = id.String()
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+
+	sql := `
+		INSERT INTO notification_outbox
+			(id, channel, recipient, template_id, subject, body, status, error, created_at, namespace, is_test)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err := o.db.Exec(ctx, sql, e.ID, string(e.Channel), e.Recipient, nullable(e.TemplateID), nullable(e.Subject), e.Body, string(e.Status), nullable(e.Error), e.CreatedAt, o.namespace, e.Test)
+	if err != nil {
+		return fmt.Errorf("failed to insert outbox entry: %w", err)
+	}
+	return nil
+}
+
+func nullable(s string) any {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,76 @@
+package notification
+
+import (
+	"context"
+	"sync"
+)
+
+// OutboxStatusSkipped is reported in a ChannelResult for channels that were not dispatched
+// (e.g., push, which has no sender yet). It is never recorded in the outbox.
+const OutboxStatusSkipped OutboxStatus = "skipped"
+
+// ChannelResult is the final outcome of one channel dispatch.
+type ChannelResult struct {
+	Channel Channel
+	// ID identifies the dispatch; it is the outbox entry ID when an outbox is configured.
+	ID       string
+	Status   OutboxStatus
+	Attempts int
+	// Err is the provider's last error when Status is OutboxStatusFailed.
+	Err error
+}
+
+// Receipt tracks a notification accepted by Dispatch. IDs are assigned at enqueue time, so a
+// caller that only needs to know the message was queued can store them and return at once;
+// Wait or Results report what each provider did.
+type Receipt struct {
+	// IDs maps each dispatched channel to its dispatch ID (see ChannelResult.ID).
+	IDs map[Channel]string
+
+	results chan ChannelResult
+	pending sync.WaitGroup
+}
+
+func newReceipt(channels int) *Receipt {
+	return &Receipt{
+		IDs:     make(map[Channel]string, channels),
+		results: make(chan ChannelResult, channels),
+	}
+}
+
+// report delivers a channel's outcome; every dispatched channel reports exactly once.
+func (r *Receipt) report(res ChannelResult) {
+	r.results <- res
+	r.pending.Done()
+}
+
+// seal closes Results once every channel has reported.
+func (r *Receipt) seal() {
+	go func() {
+		r.pending.Wait()
+		close(r.results)
+	}()
+}
+
+// Results yields each channel's outcome as it completes and is closed after the last one.
+// Retries happen before a result is reported, so a failed outcome is final.
+func (r *Receipt) Results() <-chan ChannelResult {
+	return r.results
+}
+
+// Wait blocks until every channel has reported or ctx is done, returning the outcomes
+// collected so far and ctx's error in the latter case.
+func (r *Receipt) Wait(ctx context.Context) ([]ChannelResult, error) {
+	var out []ChannelResult
+	for {
+		select {
+		case res, ok := <-r.results:
+			if !ok {
+				return out, nil
+			}
+			out = append(out, res)
+		case <-ctx.Done():
+			return out, ctx.Err()
+		}
+	}
+}
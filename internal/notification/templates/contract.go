@@ -0,0 +1,247 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	texttmpl "text/template"
+	"text/template/parse"
+)
+
+// registered lists the handles declared with register, i.e. every scenario the code sends.
+var registered []IHandle
+
+// register declares the typed handle of a shipped scenario, so Check can verify it at startup.
+func register[T any](id string) Handle[T] {
+	h := Expect[T](id)
+	registered = append(registered, h)
+	return h
+}
+
+// Handles returns the handles of every shipped scenario, sorted by ID.
+func Handles() []IHandle {
+	out := append([]IHandle(nil), registered...)
+	sort.Slice(out, func(i, j int) bool { return out[i].ID() < out[j].ID() })
+	return out
+}
+
+// Check verifies, without rendering, that every variable referenced by the blocks of each
+// handle's template exists on the handle's data type: the static counterpart of the engines'
+// missingkey=error, run at startup so a renamed field fails the boot instead of the first
+// email that needs it. Fields behind interface values cannot be resolved and are skipped.
+func (e *Engine) Check(handles ...IHandle) error {
+	var errs []error
+	for _, h := range handles {
+		c, err := e.getCompiled(context.Background(), h.ID())
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		errs = append(errs, checkContract(c.text, h.DataType())...)
+	}
+	return errors.Join(errs...)
+}
+
+// checkContract walks every block of t with dot typed as data.
+func checkContract(t *texttmpl.Template, data reflect.Type) []error {
+	c := &contractChecker{tmpl: t, seen: make(map[string]bool)}
+	blocks := t.Templates()
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].Name() < blocks[j].Name() })
+	for _, block := range blocks {
+		if block.Tree != nil {
+			c.walk(block.Tree, block.Tree.Root, data, scope{"$": data})
+		}
+	}
+	return c.errs
+}
+
+// scope maps variable names ($, $x) to their types; nil means unknown.
+type scope map[string]reflect.Type
+
+func (s scope) with(name string, t reflect.Type) scope {
+	out := make(scope, len(s)+1)
+	for k, v := range s {
+		out[k] = v
+	}
+	out[name] = t
+	return out
+}
+
+type contractChecker struct {
+	tmpl *texttmpl.Template
+	seen map[string]bool // "<block>|<dot type>" already walked via {{template}}
+	errs []error
+}
+
+func (c *contractChecker) walk(tree *parse.Tree, node parse.Node, dot reflect.Type, vars scope) scope {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return vars
+		}
+		for _, child := range n.Nodes {
+			vars = c.walk(tree, child, dot, vars)
+		}
+	case *parse.ActionNode:
+		t := c.pipe(tree, n.Pipe, dot, vars)
+		for _, v := range n.Pipe.Decl {
+			vars = vars.with(v.Ident[0], t)
+		}
+	case *parse.IfNode:
+		c.pipe(tree, n.Pipe, dot, vars)
+		c.walk(tree, n.List, dot, vars)
+		c.walk(tree, n.ElseList, dot, vars)
+	case *parse.WithNode:
+		t := c.pipe(tree, n.Pipe, dot, vars)
+		inner := vars
+		for _, v := range n.Pipe.Decl {
+			inner = inner.with(v.Ident[0], t)
+		}
+		c.walk(tree, n.List, t, inner)
+		c.walk(tree, n.ElseList, dot, vars)
+	case *parse.RangeNode:
+		key, elem := rangeTypes(c.pipe(tree, n.Pipe, dot, vars))
+		inner := vars
+		switch len(n.Pipe.Decl) {
+		case 1:
+			inner = inner.with(n.Pipe.Decl[0].Ident[0], elem)
+		case 2:
+			inner = inner.with(n.Pipe.Decl[0].Ident[0], key).with(n.Pipe.Decl[1].Ident[0], elem)
+		}
+		c.walk(tree, n.List, elem, inner)
+		c.walk(tree, n.ElseList, dot, vars)
+	case *parse.TemplateNode:
+		var t reflect.Type
+		if n.Pipe != nil {
+			t = c.pipe(tree, n.Pipe, dot, vars)
+		}
+		key := n.Name + "|" + fmt.Sprint(t)
+		if called := c.tmpl.Lookup(n.Name); called != nil && called.Tree != nil && !c.seen[key] {
+			c.seen[key] = true
+			c.walk(called.Tree, called.Tree.Root, t, scope{"$": t})
+		}
+	}
+	return vars
+}
+
+// pipe checks every command of p and returns the type it produces (nil if unknown).
+func (c *contractChecker) pipe(tree *parse.Tree, p *parse.PipeNode, dot reflect.Type, vars scope) reflect.Type {
+	if p == nil {
+		return nil
+	}
+	var last reflect.Type
+	for _, cmd := range p.Cmds {
+		last = nil
+		for i, arg := range cmd.Args {
+			t := c.arg(tree, arg, dot, vars)
+			if i == 0 {
+				last = t
+			}
+		}
+		if len(cmd.Args) > 1 {
+			if _, isFunc := cmd.Args[0].(*parse.IdentifierNode); !isFunc {
+				last = nil
+			}
+		}
+	}
+	return last
+}
+
+// arg checks one operand and returns its type (nil if unknown). For a function identifier,
+// that is the function's result type.
+func (c *contractChecker) arg(tree *parse.Tree, node parse.Node, dot reflect.Type, vars scope) reflect.Type {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		return c.fields(tree, n, dot, n.Ident)
+	case *parse.VariableNode:
+		t, ok := vars[n.Ident[0]]
+		if !ok {
+			return nil
+		}
+		return c.fields(tree, n, t, n.Ident[1:])
+	case *parse.ChainNode:
+		return c.fields(tree, n, c.arg(tree, n.Node, dot, vars), n.Field)
+	case *parse.PipeNode:
+		return c.pipe(tree, n, dot, vars)
+	case *parse.IdentifierNode:
+		if fn, ok := funcs[n.Ident]; ok {
+			if ft := reflect.TypeOf(fn); ft.Kind() == reflect.Func && ft.NumOut() > 0 {
+				return ft.Out(0)
+			}
+		}
+	}
+	return nil
+}
+
+// fields resolves the chain path on t, recording an error for the first missing step.
+func (c *contractChecker) fields(tree *parse.Tree, node parse.Node, t reflect.Type, path []string) reflect.Type {
+	for i, name := range path {
+		if t == nil {
+			return nil
+		}
+		next, ok := member(t, name)
+		if !ok {
+			loc, _ := tree.ErrorContext(node)
+			c.errs = append(c.errs, fmt.Errorf("template %s (block %q): .%s is not a field or method of %s",
+				loc, tree.Name, strings.Join(path[:i+1], "."), t))
+			return nil
+		}
+		t = next
+	}
+	return t
+}
+
+// member returns the type of t.name as a template would evaluate it: a method (on the value
+// or its pointer), a struct field, or a map entry. ok is true with a nil type when t is an
+// interface, whose dynamic fields are only known at render time.
+func member(t reflect.Type, name string) (reflect.Type, bool) {
+	for _, mt := range []reflect.Type{t, reflect.PointerTo(t)} {
+		if mt.Kind() == reflect.Interface {
+			break
+		}
+		if m, ok := mt.MethodByName(name); ok {
+			if m.Type.NumOut() == 0 {
+				return nil, true
+			}
+			return m.Type.Out(0), true
+		}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		return nil, true
+	case reflect.Map:
+		return t.Elem(), true
+	case reflect.Struct:
+		if f, ok := t.FieldByName(name); ok && f.IsExported() {
+			return f.Type, true
+		}
+	}
+	return nil, false
+}
+
+// rangeTypes returns the key and element types of ranging over t (nil when unknown).
+func rangeTypes(t reflect.Type) (key, elem reflect.Type) {
+	if t == nil {
+		return nil, nil
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return reflect.TypeOf(0), t.Elem()
+	case reflect.Map:
+		return t.Key(), t.Elem()
+	case reflect.Int:
+		return t, t
+	}
+	return nil, nil
+}
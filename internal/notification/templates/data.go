@@ -1,21 +1,174 @@
 package templates
 
-// VerifyEmailData holds variables for the user.verify_email scenario using a 6-digit code.
+import "time"
+
+// VerifyEmailData holds variables for the user.verify_email scenario using a one-time code.
+// VerifyLink is set when link verification is configured (VERIFY_EMAIL_REDIRECT_URL); opening
+// it verifies the address without typing the code.
 type VerifyEmailData struct {
 	FirstName    string
-	Code         string
-	SupportEmail string
+	Code             string
+	ExpiresInMinutes int
+	ExpiresAt        time.Time // when the code stops working; render with localTime
+	Locale           Locale    // recipient's language and time zone
+	VerifyLink                 string
+	VerifyLinkExpiresInMinutes int
+	SupportEmail     string
 }
 
 // VerifyEmail is the typed handle for the user.verify_email template.
-var VerifyEmail = Expect[VerifyEmailData]("user.verify_email")
+var VerifyEmail = register[VerifyEmailData]("user.verify_email")
 
-// PasswordResetCodeData holds variables for sending a 6-digit password reset code.
+// PasswordResetCodeData holds variables for sending a one-time password reset code.
+// ResetLink is set when deep-link delivery is configured (PASSWORD_RESET_LINK_TEMPLATE);
+// it opens the app (universal/app link) or web page that finalizes the reset.
 type PasswordResetCodeData struct {
+	FirstName                 string
+	Code                      string
+	ExpiresInMinutes          int
+	ExpiresAt                 time.Time // when the code stops working; render with localTime
+	Locale                    Locale    // recipient's language and time zone
+	ResetLink                 string
+	ResetLinkExpiresInMinutes int
+	SupportEmail              string
+}
+
+// PasswordResetCode is the typed handle for the user.password_reset_code template.
+var PasswordResetCode = register[PasswordResetCodeData]("user.password_reset_code")
+
+// AccountRestoreCodeData holds variables for sending a code that restores a soft-deleted account.
+type AccountRestoreCodeData struct {
+	FirstName        string
+	Code             string
+	ExpiresInMinutes int
+	ExpiresAt        time.Time // when the code stops working; render with localTime
+	Locale           Locale    // recipient's language and time zone
+	SupportEmail     string
+}
+
+// AccountRestoreCode is the typed handle for the user.account_restore_code template.
+var AccountRestoreCode = register[AccountRestoreCodeData]("user.account_restore_code")
+
+// LoginStepUpCodeData holds variables for the code that completes a sign-in flagged for extra
+// verification (e.g., from a network with a poor IP reputation).
+type LoginStepUpCodeData struct {
+	FirstName        string
+	Code             string
+	IP               string // the address the sign-in came from
+	ExpiresInMinutes int
+	ExpiresAt        time.Time // when the code stops working; render with localTime
+	Locale           Locale    // recipient's language and time zone
+	SupportEmail     string
+}
+
+// LoginStepUpCode is the typed handle for the user.login_step_up_code template.
+var LoginStepUpCode = register[LoginStepUpCodeData]("user.login_step_up_code")
+
+// PhoneLoginCodeData holds variables for the SMS code that registers or signs in a user by phone.
+type PhoneLoginCodeData struct {
+	FirstName        string
+	Code             string
+	ExpiresInMinutes int
+	ExpiresAt        time.Time // when the code stops working; render with localTime
+	Locale           Locale    // recipient's language and time zone
+}
+
+// PhoneLoginCode is the typed handle for the user.phone_login_code template.
+var PhoneLoginCode = register[PhoneLoginCodeData]("user.phone_login_code")
+
+// ReengagementData holds variables for the email sent to long-inactive users before anonymization.
+type ReengagementData struct {
+	FirstName          string
+	InactiveMonths     int
+	AnonymizeAfterDays int
+	SupportEmail       string
+}
+
+// Reengagement is the typed handle for the user.reengagement template.
+var Reengagement = register[ReengagementData]("user.reengagement")
+
+// EmailChangeCodeData holds variables for the code sent to a user's requested new email address.
+type EmailChangeCodeData struct {
+	FirstName        string
+	Code             string
+	ExpiresInMinutes int
+	ExpiresAt        time.Time // when the code stops working; render with localTime
+	Locale           Locale    // recipient's language and time zone
+	SupportEmail     string
+}
+
+// EmailChangeCode is the typed handle for the user.email_change_code template.
+var EmailChangeCode = register[EmailChangeCodeData]("user.email_change_code")
+
+// EmailChangeNoticeData holds variables for the notice sent to the current address when an
+// email change is requested.
+type EmailChangeNoticeData struct {
 	FirstName    string
-	Code         string
+	NewEmail     string
 	SupportEmail string
 }
 
-// PasswordResetCode is the typed handle for the user.password_reset_code template.
-var PasswordResetCode = Expect[PasswordResetCodeData]("user.password_reset_code")
\ No newline at end of file
+// EmailChangeNotice is the typed handle for the user.email_change_notice template.
+var EmailChangeNotice = register[EmailChangeNoticeData]("user.email_change_notice")
+
+// PasswordChangedData holds variables for the notice sent after a user changes their password.
+// ChangedAt is rendered in the recipient's Locale (localDateTime).
+type PasswordChangedData struct {
+	FirstName    string
+	ChangedAt    time.Time
+	Locale       Locale
+	SupportEmail string
+}
+
+// PasswordChanged is the typed handle for the user.password_changed template.
+var PasswordChanged = register[PasswordChangedData]("user.password_changed")
+
+// NewSignInData holds variables for the alert sent after a login from an unrecognized device.
+// SignedInAt is rendered in the recipient's Locale (localDateTime). RevokeLink, when set,
+// signs out every session without signing in; it stays valid for RevokeLinkExpiresInHours.
+type NewSignInData struct {
+	FirstName                string
+	SignedInAt               time.Time
+	Locale                   Locale
+	UserAgent                string
+	IPAddress                string
+	AuthMethod               string
+	RevokeLink               string
+	RevokeLinkExpiresInHours int
+	SupportEmail             string
+}
+
+// NewSignIn is the typed handle for the user.new_sign_in template.
+var NewSignIn = register[NewSignInData]("user.new_sign_in")
+
+// AnnouncementData holds variables for an admin broadcast (POST /admin/broadcast). Subject and
+// Message are written by the admin; Message is plain text and rendered escaped.
+type AnnouncementData struct {
+	FirstName    string
+	Subject      string
+	Message      string
+	Locale       Locale
+	SupportEmail string
+}
+
+// Announcement is the typed handle for the user.announcement template.
+var Announcement = register[AnnouncementData]("user.announcement")
+
+// OnboardingData holds variables shared by every email of the onboarding sequence
+// (ONBOARDING_SEQUENCE), so any template can be placed at any step.
+// Step is the 1-based position in the sequence; SignedUpAt is rendered with localDate.
+type OnboardingData struct {
+	FirstName    string
+	Step         int
+	SignedUpAt   time.Time
+	Locale       Locale
+	SupportEmail string
+}
+
+// Welcome, OnboardingTips, and OnboardingReengage are the onboarding templates shipped with
+// the starter; the sequence may name any template that accepts OnboardingData.
+var (
+	Welcome            = register[OnboardingData]("user.welcome")
+	OnboardingTips     = register[OnboardingData]("user.onboarding_tips")
+	OnboardingReengage = register[OnboardingData]("user.onboarding_reengage")
+)
@@ -3,28 +3,45 @@ package templates
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	htmltmpl "html/template"
-	"io/fs"
 	"os"
-	"path/filepath"
 	"reflect"
+	"strings"
 	"sync"
 	texttmpl "text/template"
+	"time"
 
 	"log/slog"
 )
 
 // Config controls how the template engine loads templates.
-// Dir: when non-empty, loads templates from this directory (expects files named <id>.tmpl).
-// Reload: when true and Dir is set, templates are reparsed on every render.
+// Dir: when non-empty and Source is nil, loads templates from this directory (expects files named <id>.tmpl).
+// Reload: when true, the source is asked for a newer version on every render.
+// Source: where templates come from (embedded FS, disk, S3, database); nil means Dir or the embedded FS.
+// RefreshInterval: how long a compiled template is served before the source is asked for a newer
+// version; 0 keeps it until the process restarts (or an Upload replaces it).
 type Config struct {
-	Dir    string
-	Reload bool
+	Dir             string
+	Reload          bool
+	Source          Source
+	RefreshInterval time.Duration
 }
 
+// maxTemplateSize bounds the size of a template file.
+const maxTemplateSize = 256 << 10
+
+// ErrInvalidTemplate is returned by Engine.Upload for content that does not parse or references
+// fields the template's data type lacks.
+var ErrInvalidTemplate = errors.New("invalid template")
+
 // Rendered holds the per-channel materialized content from a scenario template.
 type Rendered struct {
+	// From and ReplyTo optionally override the sender identity for this scenario
+	// (blocks "from" and "reply_to"); empty means use the configured defaults.
+	From      string
+	ReplyTo   string
 	Subject   string
 	EmailHTML string
 	EmailText string
@@ -61,32 +78,44 @@ type Renderer interface {
 	RenderAny(ctx context.Context, id string, data any) (Rendered, error)
 }
 
-// Engine compiles and renders scenario templates with optional dev reload.
+// Engine compiles and renders scenario templates, caching them per source version.
 type Engine struct {
-	cfg   Config
-	log   *slog.Logger
-	fs    fs.FS
-	mu    sync.RWMutex
-	cache map[string]*compiled
+	cfg    Config
+	log    *slog.Logger
+	source Source
+	mu     sync.RWMutex
+	cache  map[string]*compiled
 }
 
 type compiled struct {
 	text *texttmpl.Template
 	html *htmltmpl.Template
+	// version is the source version the templates were parsed from; checked is when the
+	// source last confirmed it.
+	version string
+	checked time.Time
 }
 
 // NewEngine creates a template engine. It uses embedded templates by default.
-// If cfg.Dir is provided, templates are loaded from disk; if cfg.Reload is true,
-// disk templates are reparsed on every render call.
+// If cfg.Source is set templates are fetched from it, else if cfg.Dir is provided they are
+// loaded from disk; if cfg.Reload is true the source is revalidated on every render call.
 func NewEngine(cfg Config, log *slog.Logger) *Engine {
 	if log == nil {
 		log = slog.New(slog.NewTextHandler(os.Stdout, nil))
 	}
+	source := cfg.Source
+	switch {
+	case source != nil:
+	case cfg.Dir != "":
+		source = NewDirSource(cfg.Dir)
+	default:
+		source = NewEmbeddedSource()
+	}
 	return &Engine{
-		cfg:   cfg,
-		log:   log,
-		fs:    EmbeddedFS,
-		cache: make(map[string]*compiled),
+		cfg:    cfg,
+		log:    log,
+		source: source,
+		cache:  make(map[string]*compiled),
 	}
 }
 
@@ -95,9 +124,9 @@ func Render[T any](ctx context.Context, e *Engine, h Handle[T], data T) (Rendere
 	return e.RenderAny(ctx, h.ID(), data)
 }
 
-// RenderAny renders a scenario by ID using either embedded or disk templates.
+// RenderAny renders a scenario by ID using the engine's template source.
 func (e *Engine) RenderAny(ctx context.Context, id string, data any) (Rendered, error) {
-	c, err := e.getCompiled(id)
+	c, err := e.getCompiled(ctx, id)
 	if err != nil {
 		return Rendered{}, err
 	}
@@ -139,6 +168,20 @@ func (e *Engine) RenderAny(ctx context.Context, id string, data any) (Rendered,
 			out.PushBody = s
 		}
 	}
+	if c.text.Lookup("from") != nil {
+		if s, err := execText(c.text, "from", data); err != nil {
+			return Rendered{}, fmt.Errorf("render from: %w", err)
+		} else {
+			out.From = strings.TrimSpace(s)
+		}
+	}
+	if c.text.Lookup("reply_to") != nil {
+		if s, err := execText(c.text, "reply_to", data); err != nil {
+			return Rendered{}, fmt.Errorf("render reply_to: %w", err)
+		} else {
+			out.ReplyTo = strings.TrimSpace(s)
+		}
+	}
 	// html block
 	if c.html.Lookup("email_html") != nil {
 		if s, err := execHTML(c.html, "email_html", data); err != nil {
@@ -151,66 +194,112 @@ func (e *Engine) RenderAny(ctx context.Context, id string, data any) (Rendered,
 	return out, nil
 }
 
-func (e *Engine) getCompiled(id string) (*compiled, error) {
-	// Disk reload path: always reparse when Reload is true and Dir is set.
-	if e.cfg.Dir != "" && e.cfg.Reload {
-		return e.parseFromDisk(id)
-	}
-
-	// Cache path: try cache first.
+func (e *Engine) getCompiled(ctx context.Context, id string) (*compiled, error) {
 	e.mu.RLock()
-	cached, ok := e.cache[id]
+	cached := e.cache[id]
 	e.mu.RUnlock()
-	if ok {
+	if cached != nil && !e.stale(cached) {
 		return cached, nil
 	}
 
-	// Not cached: parse and cache.
-	var (
-		c   *compiled
-		err error
-	)
-	if e.cfg.Dir != "" {
-		c, err = e.parseFromDisk(id)
-	} else {
-		c, err = e.parseFromEmbed(id)
+	var known string
+	if cached != nil {
+		known = cached.version
 	}
+	b, version, err := e.source.Fetch(ctx, id, known)
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrNotModified) && cached != nil:
+		return e.store(id, cached.text, cached.html, cached.version), nil
+	case cached != nil:
+		// Keep serving the last good copy while the source is unavailable.
+		e.log.Warn("template source unavailable; serving cached template", "error", err, "template", id)
+		return e.store(id, cached.text, cached.html, cached.version), nil
+	default:
+		return nil, fmt.Errorf("load template %q: %w", id, err)
+	}
+
+	c, err := parseBoth(id, string(b))
 	if err != nil {
+		if cached != nil {
+			e.log.Error("template source returned an invalid template; serving cached template", "error", err, "template", id, "version", version)
+			return e.store(id, cached.text, cached.html, cached.version), nil
+		}
 		return nil, err
 	}
+	return e.store(id, c.text, c.html, version), nil
+}
+
+// stale reports whether c must be revalidated with the source before use.
+func (e *Engine) stale(c *compiled) bool {
+	if e.cfg.Reload {
+		return true
+	}
+	return e.cfg.RefreshInterval > 0 && time.Since(c.checked) >= e.cfg.RefreshInterval
+}
 
+// store caches the templates of id at version, checked now. Cached entries are never mutated,
+// so renders holding one are unaffected.
+func (e *Engine) store(id string, text *texttmpl.Template, html *htmltmpl.Template, version string) *compiled {
+	c := &compiled{text: text, html: html, version: version, checked: time.Now()}
 	e.mu.Lock()
 	e.cache[id] = c
 	e.mu.Unlock()
-	return c, nil
+	return c
 }
 
-func (e *Engine) parseFromDisk(id string) (*compiled, error) {
-	path := filepath.Join(e.cfg.Dir, id+".tmpl")
-	b, err := os.ReadFile(path)
+// Content returns the current source of template id and its version, e.g. for an operator to
+// download before editing.
+func (e *Engine) Content(ctx context.Context, id string) ([]byte, string, error) {
+	return e.source.Fetch(ctx, id, "")
+}
+
+// Upload validates content as template id and saves it to the engine's source, which must be a
+// Store. Only registered scenarios can be uploaded, and the content must parse and reference only
+// fields of the scenario's data type (ErrInvalidTemplate). The new version is served by this
+// engine at once and by other instances after their RefreshInterval.
+func (e *Engine) Upload(ctx context.Context, id string, content []byte) (string, error) {
+	store, ok := e.source.(Store)
+	if !ok {
+		return "", ErrReadOnly
+	}
+	var handle IHandle
+	for _, h := range registered {
+		if h.ID() == id {
+			handle = h
+		}
+	}
+	if handle == nil {
+		return "", ErrTemplateNotFound
+	}
+	if len(content) > maxTemplateSize {
+		return "", fmt.Errorf("%w: larger than %d bytes", ErrInvalidTemplate, maxTemplateSize)
+	}
+	c, err := parseBoth(id, string(content))
 	if err != nil {
-		return nil, fmt.Errorf("read template from disk %q: %w", path, err)
+		return "", fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+	if errs := checkContract(c.text, handle.DataType()); len(errs) > 0 {
+		return "", fmt.Errorf("%w: %w", ErrInvalidTemplate, errors.Join(errs...))
 	}
-	return parseBoth(id, string(b))
-}
 
-func (e *Engine) parseFromEmbed(id string) (*compiled, error) {
-	path := "files/" + id + ".tmpl"
-	b, err := fs.ReadFile(e.fs, path)
+	version, err := store.Put(ctx, id, content)
 	if err != nil {
-		return nil, fmt.Errorf("read embedded template %q: %w", path, err)
+		return "", err
 	}
-	return parseBoth(id, string(b))
+	e.store(id, c.text, c.html, version)
+	e.log.Info("template uploaded", "template", id, "version", version)
+	return version, nil
 }
 
 func parseBoth(id, content string) (*compiled, error) {
 	// text/template for subject, email_text, sms_text, push_title, push_body
-	tText, err := texttmpl.New(id).Option("missingkey=error").Parse(content)
+	tText, err := texttmpl.New(id).Funcs(funcs).Option("missingkey=error").Parse(content)
 	if err != nil {
 		return nil, fmt.Errorf("parse text blocks (%s): %w", id, err)
 	}
 	// html/template for email_html
-	tHTML, err := htmltmpl.New(id).Option("missingkey=error").Parse(content)
+	tHTML, err := htmltmpl.New(id).Funcs(funcs).Option("missingkey=error").Parse(content)
 	if err != nil {
 		return nil, fmt.Errorf("parse html block (%s): %w", id, err)
 	}
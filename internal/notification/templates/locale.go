@@ -0,0 +1,156 @@
+package templates
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale is the recipient's formatting preference, passed in template data so dates and
+// numbers render the way the recipient reads them rather than in server time:
+//
+//	This code expires at {{localTime .ExpiresAt .Locale}}.   →  "expires at 14:05 CET"
+//
+// Language is a BCP 47 tag ("en", "en-US", "de"); TimeZone is an IANA zone name
+// ("Europe/Berlin"). Either may be empty: English formats and UTC are used then.
+type Locale struct {
+	Language string
+	TimeZone string
+}
+
+// localeFormat is how one language writes times, dates, and numbers.
+type localeFormat struct {
+	time    string // time.Format layout; MST prints the zone abbreviation
+	date    string
+	group   string // thousands separator
+	decimal string
+}
+
+// localeFormats is keyed by lower-case tag: full tag first, then base language (see Locale.format).
+// Non-English dates are numeric so no month names need translating.
+var localeFormats = map[string]localeFormat{
+	"en":    {time: "15:04 MST", date: "2 Jan 2006", group: ",", decimal: "."},
+	"en-us": {time: "3:04 PM MST", date: "Jan 2, 2006", group: ",", decimal: "."},
+	"de":    {time: "15:04 MST", date: "02.01.2006", group: ".", decimal: ","},
+	"es":    {time: "15:04 MST", date: "02/01/2006", group: ".", decimal: ","},
+	"fr":    {time: "15:04 MST", date: "02/01/2006", group: " ", decimal: ","},
+	"it":    {time: "15:04 MST", date: "02/01/2006", group: ".", decimal: ","},
+	"nl":    {time: "15:04 MST", date: "02-01-2006", group: ".", decimal: ","},
+	"pt":    {time: "15:04 MST", date: "02/01/2006", group: ".", decimal: ","},
+}
+
+// languageTag accepts the common shapes of BCP 47 tags: "en", "pt-BR", "zh-Hant".
+var languageTag = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// ValidLanguage reports whether tag is a plausible BCP 47 language tag. Languages without a
+// dedicated format are accepted and rendered with English conventions.
+func ValidLanguage(tag string) bool {
+	return languageTag.MatchString(tag)
+}
+
+// ValidTimeZone reports whether name is an IANA time zone known to this host.
+func ValidTimeZone(name string) bool {
+	if name == "" || strings.EqualFold(name, "local") {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// format returns the conventions for l's language: exact tag, then base language, then English.
+func (l Locale) format() localeFormat {
+	tag := strings.ToLower(l.Language)
+	if f, ok := localeFormats[tag]; ok {
+		return f
+	}
+	base, _, _ := strings.Cut(tag, "-")
+	if f, ok := localeFormats[base]; ok {
+		return f
+	}
+	return localeFormats["en"]
+}
+
+// location returns l's time zone, or UTC when it is unset or unknown.
+func (l Locale) location() *time.Location {
+	if l.TimeZone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(l.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// funcs is the FuncMap shared by every scenario template.
+var funcs = map[string]any{
+	"localTime":     localTime,
+	"localDate":     localDate,
+	"localDateTime": localDateTime,
+	"localNumber":   localNumber,
+}
+
+// localTime renders the time of day of t in l, e.g. "14:05 CET" or "2:05 PM EST".
+func localTime(t time.Time, l Locale) string {
+	return t.In(l.location()).Format(l.format().time)
+}
+
+// localDate renders the calendar date of t in l, e.g. "05.03.2025".
+func localDate(t time.Time, l Locale) string {
+	return t.In(l.location()).Format(l.format().date)
+}
+
+// localDateTime renders the date and time of t in l, e.g. "2 Jan 2006 15:04 UTC".
+func localDateTime(t time.Time, l Locale) string {
+	f := l.format()
+	return t.In(l.location()).Format(f.date + " " + f.time)
+}
+
+// localNumber renders an integer with l's thousands separator, or a float with two decimals,
+// e.g. 1234567 → "1.234.567" in German.
+func localNumber(v any, l Locale) (string, error) {
+	f := l.format()
+	switch n := v.(type) {
+	case int:
+		return groupDigits(strconv.FormatInt(int64(n), 10), f.group), nil
+	case int64:
+		return groupDigits(strconv.FormatInt(n, 10), f.group), nil
+	case int32:
+		return groupDigits(strconv.FormatInt(int64(n), 10), f.group), nil
+	case float64:
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return "", fmt.Errorf("localNumber: %v is not a finite number", n)
+		}
+		whole, frac, _ := strings.Cut(strconv.FormatFloat(n, 'f', 2, 64), ".")
+		return groupDigits(whole, f.group) + f.decimal + frac, nil
+	default:
+		return "", fmt.Errorf("localNumber: unsupported type %T", v)
+	}
+}
+
+// groupDigits inserts sep every three digits from the right of a formatted integer.
+func groupDigits(s, sep string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	if len(s) <= 3 {
+		return sign + s
+	}
+	var b strings.Builder
+	b.WriteString(sign)
+	head := len(s) % 3
+	if head > 0 {
+		b.WriteString(s[:head])
+	}
+	for i := head; i < len(s); i += 3 {
+		if b.Len() > len(sign) {
+			b.WriteString(sep)
+		}
+		b.WriteString(s[i : i+3])
+	}
+	return b.String()
+}
@@ -0,0 +1,159 @@
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// sampleLocale and sampleTime make sample renders show locale-aware formatting.
+var (
+	sampleLocale = Locale{Language: "en", TimeZone: "Europe/Berlin"}
+	sampleTime   = time.Date(2006, time.January, 2, 15, 4, 0, 0, time.UTC)
+)
+
+// samples holds representative data for every scenario, used by admin test sends so
+// operators can exercise a template without constructing its data by hand.
+var samples = map[string]any{
+	VerifyEmail.ID(): VerifyEmailData{
+		FirstName:                  "Ada",
+		Code:                       "123456",
+		ExpiresInMinutes:           15,
+		ExpiresAt:                  sampleTime,
+		Locale:                     sampleLocale,
+		VerifyLink:                 "https://api.example.com/users/verify-email?token=sample",
+		VerifyLinkExpiresInMinutes: 1440,
+		SupportEmail:               "support@example.com",
+	},
+	PasswordResetCode.ID(): PasswordResetCodeData{
+		FirstName:                 "Ada",
+		Code:                      "123456",
+		ExpiresInMinutes:          15,
+		ExpiresAt:                 sampleTime,
+		Locale:                    sampleLocale,
+		ResetLink:                 "https://example.com/reset-password?token=sample",
+		ResetLinkExpiresInMinutes: 15,
+		SupportEmail:              "support@example.com",
+	},
+	AccountRestoreCode.ID(): AccountRestoreCodeData{
+		FirstName:        "Ada",
+		Code:             "123456",
+		ExpiresInMinutes: 15,
+		ExpiresAt:        sampleTime,
+		Locale:           sampleLocale,
+		SupportEmail:     "support@example.com",
+	},
+	LoginStepUpCode.ID(): LoginStepUpCodeData{
+		FirstName:        "Ada",
+		Code:             "123456",
+		IP:               "203.0.113.7",
+		ExpiresInMinutes: 10,
+		ExpiresAt:        sampleTime,
+		Locale:           sampleLocale,
+		SupportEmail:     "support@example.com",
+	},
+	PhoneLoginCode.ID(): PhoneLoginCodeData{
+		FirstName:        "Ada",
+		Code:             "123456",
+		ExpiresInMinutes: 10,
+		ExpiresAt:        sampleTime,
+		Locale:           sampleLocale,
+	},
+	EmailChangeCode.ID(): EmailChangeCodeData{
+		FirstName:        "Ada",
+		Code:             "123456",
+		ExpiresInMinutes: 10,
+		ExpiresAt:        sampleTime,
+		Locale:           sampleLocale,
+		SupportEmail:     "support@example.com",
+	},
+	EmailChangeNotice.ID(): EmailChangeNoticeData{
+		FirstName:    "Ada",
+		NewEmail:     "ada.new@example.com",
+		SupportEmail: "support@example.com",
+	},
+	PasswordChanged.ID(): PasswordChangedData{
+		FirstName:    "Ada",
+		ChangedAt:    sampleTime,
+		Locale:       sampleLocale,
+		SupportEmail: "support@example.com",
+	},
+	Announcement.ID(): AnnouncementData{
+		FirstName:    "Ada",
+		Subject:      "Scheduled maintenance on Saturday",
+		Message:      "The app will be unavailable on Saturday from 02:00 to 04:00 UTC while we upgrade our database.",
+		Locale:       sampleLocale,
+		SupportEmail: "support@example.com",
+	},
+	NewSignIn.ID(): NewSignInData{
+		FirstName:                "Ada",
+		SignedInAt:               sampleTime,
+		Locale:                   sampleLocale,
+		UserAgent:                "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) AppleWebKit/605.1.15 Safari/605.1.15",
+		IPAddress:                "203.0.113.7",
+		AuthMethod:               "password",
+		RevokeLink:               "https://app.example.com/sessions/revoke?token=sample-token",
+		RevokeLinkExpiresInHours: 72,
+		SupportEmail:             "support@example.com",
+	},
+	Reengagement.ID(): ReengagementData{
+		FirstName:          "Ada",
+		InactiveMonths:     12,
+		AnonymizeAfterDays: 30,
+		SupportEmail:       "support@example.com",
+	},
+	Welcome.ID():            onboardingSample(1),
+	OnboardingTips.ID():     onboardingSample(2),
+	OnboardingReengage.ID(): onboardingSample(3),
+}
+
+func onboardingSample(step int) OnboardingData {
+	return OnboardingData{
+		FirstName:    "Ada",
+		Step:         step,
+		SignedUpAt:   sampleTime,
+		Locale:       sampleLocale,
+		SupportEmail: "support@example.com",
+	}
+}
+
+// IDs returns the IDs of all known scenarios, sorted.
+func IDs() []string {
+	ids := make([]string, 0, len(samples))
+	for id := range samples {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Sample returns the sample data for the scenario id.
+func Sample(id string) (any, bool) {
+	data, ok := samples[id]
+	return data, ok
+}
+
+// DecodeData decodes raw JSON (keys are the data struct's field names) into the data type of
+// scenario id. Omitted fields keep their sample values and unknown fields are rejected, so a
+// typo surfaces instead of rendering an empty variable. Empty raw returns the sample unchanged.
+func DecodeData(id string, raw json.RawMessage) (any, error) {
+	sample, ok := samples[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown template %q", id)
+	}
+	if len(bytes.TrimSpace(raw)) == 0 || bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		return sample, nil
+	}
+
+	v := reflect.New(reflect.TypeOf(sample))
+	v.Elem().Set(reflect.ValueOf(sample))
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v.Interface()); err != nil {
+		return nil, fmt.Errorf("decode data for %s: %w", id, err)
+	}
+	return v.Elem().Interface(), nil
+}
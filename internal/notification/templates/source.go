@@ -0,0 +1,193 @@
+package templates
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrTemplateNotFound is returned by a Source that has no template with the requested ID.
+	ErrTemplateNotFound = errors.New("template not found")
+	// ErrNotModified is returned by Source.Fetch when the template is still at the known version.
+	ErrNotModified = errors.New("template not modified")
+	// ErrReadOnly is returned by Engine.Upload when the engine's source cannot store templates.
+	ErrReadOnly = errors.New("template source is read-only")
+)
+
+// Source supplies template content by ID. Versions are opaque strings that change whenever the
+// content does (a content hash, an ETag, a row version); the engine passes the version it has
+// cached as known so sources can answer ErrNotModified without transferring the content.
+// Implementations must be safe for concurrent use.
+type Source interface {
+	Fetch(ctx context.Context, id, known string) (content []byte, version string, err error)
+}
+
+// Store is a Source templates can be written to, e.g. by the admin upload endpoint.
+type Store interface {
+	Source
+	// Put saves content as the new version of id and returns that version.
+	Put(ctx context.Context, id string, content []byte) (version string, err error)
+}
+
+// Source kinds, as configured with TEMPLATES_SOURCE.
+const (
+	SourceEmbedded = "embedded"
+	SourceDisk     = "disk"
+	SourceS3       = "s3"
+	SourceDatabase = "database"
+)
+
+// ParseSourceKind validates a source kind; "" means SourceEmbedded.
+func ParseSourceKind(s string) (string, error) {
+	switch k := strings.ToLower(strings.TrimSpace(s)); k {
+	case "":
+		return SourceEmbedded, nil
+	case SourceEmbedded, SourceDisk, SourceS3, SourceDatabase:
+		return k, nil
+	default:
+		return "", fmt.Errorf("templates: unknown source %q (want embedded, disk, s3, or database)", s)
+	}
+}
+
+// fsSource reads <id>.tmpl files from a file system; versions are content hashes.
+type fsSource struct {
+	fsys fs.FS
+	dir  string
+	name string
+}
+
+// NewEmbeddedSource returns the templates compiled into the binary.
+func NewEmbeddedSource() Source {
+	return &fsSource{fsys: EmbeddedFS, dir: "files", name: "embedded"}
+}
+
+// NewDirSource reads <id>.tmpl files from dir.
+func NewDirSource(dir string) Store {
+	return &dirSource{fsSource{fsys: os.DirFS(dir), dir: ".", name: dir}, dir}
+}
+
+func (s *fsSource) Fetch(_ context.Context, id, known string) ([]byte, string, error) {
+	if !validID(id) {
+		return nil, "", ErrTemplateNotFound
+	}
+	b, err := fs.ReadFile(s.fsys, s.dir+"/"+id+".tmpl")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, "", ErrTemplateNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("read template %q from %s: %w", id, s.name, err)
+	}
+	version := contentVersion(b)
+	if version == known {
+		return nil, "", ErrNotModified
+	}
+	return b, version, nil
+}
+
+// dirSource is a writable fsSource over a directory.
+type dirSource struct {
+	fsSource
+	path string
+}
+
+// Put writes the file atomically, so a concurrent Fetch sees either the old or the new content.
+func (s *dirSource) Put(_ context.Context, id string, content []byte) (string, error) {
+	if !validID(id) {
+		return "", ErrTemplateNotFound
+	}
+	tmp, err := os.CreateTemp(s.path, "."+id+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("write template %q: %w", id, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("write template %q: %w", id, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("write template %q: %w", id, err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.path, id+".tmpl")); err != nil {
+		return "", fmt.Errorf("write template %q: %w", id, err)
+	}
+	return contentVersion(content), nil
+}
+
+// WithFallback returns a Store that serves templates from store and, for IDs store does not
+// have, from fallback; uploads go to store. It lets an S3 bucket or database table hold only
+// the templates that were edited, with the embedded copies serving the rest.
+func WithFallback(store Store, fallback Source) Store {
+	return &fallbackStore{Store: store, fallback: fallback}
+}
+
+type fallbackStore struct {
+	Store
+	fallback Source
+}
+
+// Versions are tagged with the layer they came from, so a template that moves between layers
+// (first upload, or an override deleted by hand) is never mistaken for unchanged.
+const (
+	overrideVersionPrefix = "o:"
+	fallbackVersionPrefix = "f:"
+)
+
+func (s *fallbackStore) Fetch(ctx context.Context, id, known string) ([]byte, string, error) {
+	b, v, err := s.Store.Fetch(ctx, id, strings.TrimPrefix(known, overrideVersionPrefix))
+	if err == nil {
+		return b, overrideVersionPrefix + v, nil
+	}
+	if errors.Is(err, ErrNotModified) && strings.HasPrefix(known, overrideVersionPrefix) {
+		return nil, "", ErrNotModified
+	}
+	if !errors.Is(err, ErrTemplateNotFound) {
+		return nil, "", err
+	}
+
+	fallbackKnown, ok := strings.CutPrefix(known, fallbackVersionPrefix)
+	if !ok {
+		fallbackKnown = "" // known belongs to the other layer
+	}
+	b, v, err = s.fallback.Fetch(ctx, id, fallbackKnown)
+	if err != nil {
+		return nil, "", err
+	}
+	return b, fallbackVersionPrefix + v, nil
+}
+
+func (s *fallbackStore) Put(ctx context.Context, id string, content []byte) (string, error) {
+	v, err := s.Store.Put(ctx, id, content)
+	if err != nil {
+		return "", err
+	}
+	return overrideVersionPrefix + v, nil
+}
+
+// contentVersion is the version of sources without their own: a short content hash.
+func contentVersion(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:8])
+}
+
+// validID reports whether id can be used as a file or object name: letters, digits, '.', '_'
+// and '-', not starting with '.'.
+func validID(id string) bool {
+	if id == "" || len(id) > 200 || id[0] == '.' {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,199 @@
+package templates
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config locates the bucket of an S3 (or S3-compatible: MinIO, R2, ...) template store.
+type S3Config struct {
+	Bucket string
+	// Prefix is prepended to object keys, e.g. "templates/" stores <prefix><id>.tmpl.
+	Prefix string
+	Region string
+	// Endpoint is the service URL for S3-compatible stores; buckets are then addressed
+	// path-style. Default: https://<bucket>.s3.<region>.amazonaws.com.
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is set for temporary credentials.
+	SessionToken string
+	// Timeout bounds each request. Default: 10s.
+	Timeout time.Duration
+}
+
+// s3Store reads and writes <prefix><id>.tmpl objects with SigV4-signed requests; versions are ETags.
+type s3Store struct {
+	cfg  S3Config
+	base *url.URL
+	http *http.Client
+}
+
+// NewS3Store returns a Store backed by an S3 bucket.
+func NewS3Store(cfg S3Config) (Store, error) {
+	if cfg.Bucket == "" || cfg.Region == "" {
+		return nil, fmt.Errorf("templates: s3 source requires a bucket and a region")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("templates: s3 source requires an access key")
+	}
+	endpoint := "https://" + cfg.Bucket + ".s3." + cfg.Region + ".amazonaws.com"
+	if cfg.Endpoint != "" {
+		endpoint = strings.TrimSuffix(cfg.Endpoint, "/") + "/" + cfg.Bucket
+	}
+	base, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("templates: s3 endpoint: %w", err)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &s3Store{cfg: cfg, base: base, http: &http.Client{Timeout: cfg.Timeout}}, nil
+}
+
+func (s *s3Store) Fetch(ctx context.Context, id, known string) ([]byte, string, error) {
+	if !validID(id) {
+		return nil, "", ErrTemplateNotFound
+	}
+	req, err := s.newRequest(ctx, http.MethodGet, id, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if known != "" {
+		req.Header.Set("If-None-Match", known)
+	}
+	resp, err := s.do(req, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("fetch template %q from s3: %w", id, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, "", ErrNotModified
+	case http.StatusNotFound:
+		return nil, "", ErrTemplateNotFound
+	default:
+		return nil, "", fmt.Errorf("fetch template %q from s3: %s", id, s3Error(resp))
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxTemplateSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("fetch template %q from s3: %w", id, err)
+	}
+	if len(b) > maxTemplateSize {
+		return nil, "", fmt.Errorf("fetch template %q from s3: larger than %d bytes", id, maxTemplateSize)
+	}
+	return b, resp.Header.Get("ETag"), nil
+}
+
+func (s *s3Store) Put(ctx context.Context, id string, content []byte) (string, error) {
+	if !validID(id) {
+		return "", ErrTemplateNotFound
+	}
+	req, err := s.newRequest(ctx, http.MethodPut, id, content)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	resp, err := s.do(req, content)
+	if err != nil {
+		return "", fmt.Errorf("store template %q in s3: %w", id, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("store template %q in s3: %s", id, s3Error(resp))
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+func (s *s3Store) newRequest(ctx context.Context, method, id string, body []byte) (*http.Request, error) {
+	u := *s.base
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.cfg.Prefix + id + ".tmpl"
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("templates: s3 request: %w", err)
+	}
+	req.ContentLength = int64(len(body))
+	return req, nil
+}
+
+// do signs req with AWS Signature Version 4 and sends it.
+func (s *s3Store) do(req *http.Request, body []byte) (*http.Response, error) {
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.cfg.SessionToken)
+	}
+
+	// Sign the host and every x-amz-* header.
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := day + "/" + s.cfg.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretAccessKey), day)
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.cfg.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+	return s.http.Do(req)
+}
+
+// s3Error summarizes an error response: the status and the start of the XML error body.
+func s3Error(resp *http.Response) string {
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Sprintf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(data))
+	return m.Sum(nil)
+}
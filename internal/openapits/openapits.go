@@ -0,0 +1,409 @@
+// Package openapits emits TypeScript definitions from an OpenAPI 3.1 document, in the
+// shape produced by openapi-typescript (paths, operations, and components interfaces),
+// so frontends can type their API calls against the Go DTOs.
+package openapits
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// schema is the subset of JSON Schema the emitter understands.
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 any                `json:"type"` // string or []string (3.1 nullable types)
+	Description          string             `json:"description"`
+	Nullable             bool               `json:"nullable"`
+	ReadOnly             bool               `json:"readOnly"`
+	Deprecated           bool               `json:"deprecated"`
+	Enum                 []any              `json:"enum"`
+	Items                *schema            `json:"items"`
+	Properties           map[string]*schema `json:"properties"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"` // bool or schema
+	Required             []string           `json:"required"`
+	OneOf                []*schema          `json:"oneOf"`
+	AnyOf                []*schema          `json:"anyOf"`
+	AllOf                []*schema          `json:"allOf"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Schema      *schema `json:"schema"`
+}
+
+type header struct {
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Schema      *schema `json:"schema"`
+}
+
+type response struct {
+	Description string               `json:"description"`
+	Headers     map[string]header    `json:"headers"`
+	Content     map[string]mediaType `json:"content"`
+}
+
+type operation struct {
+	OperationID string      `json:"operationId"`
+	Summary     string      `json:"summary"`
+	Description string      `json:"description"`
+	Deprecated  bool        `json:"deprecated"`
+	Parameters  []parameter `json:"parameters"`
+	RequestBody *struct {
+		Required bool                 `json:"required"`
+		Content  map[string]mediaType `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]response `json:"responses"`
+}
+
+type document struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+// httpMethods lists the path item keys that hold operations, in emission order.
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// Generate writes TypeScript definitions for the OpenAPI document spec (anything that
+// marshals to OpenAPI JSON, e.g. *huma.OpenAPI).
+func Generate(w io.Writer, spec any) error {
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("marshal spec: %w", err)
+	}
+	var doc document
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return fmt.Errorf("parse spec: %w", err)
+	}
+
+	e := &emitter{}
+	e.line(0, "// Code generated by cmd/openapi-ts from the OpenAPI spec. DO NOT EDIT.")
+	e.line(0, "")
+
+	ops := map[string]*operation{}
+	var opIDs []string
+
+	e.line(0, "export interface paths {")
+	for _, path := range sortedKeys(doc.Paths) {
+		item := doc.Paths[path]
+		e.line(1, "%s: {", quote(path))
+		for _, method := range httpMethods {
+			rawOp, ok := item[method]
+			if !ok {
+				continue
+			}
+			var op operation
+			if err := json.Unmarshal(rawOp, &op); err != nil {
+				return fmt.Errorf("parse %s %s: %w", method, path, err)
+			}
+			id := op.OperationID
+			if id == "" {
+				id = method + strings.NewReplacer("/", "-", "{", "", "}", "").Replace(path)
+			}
+			ops[id] = &op
+			opIDs = append(opIDs, id)
+			e.doc(2, op.Summary, op.Description, op.Deprecated)
+			e.line(2, "%s: operations[%s];", method, quote(id))
+		}
+		e.line(1, "};")
+	}
+	e.line(0, "}")
+	e.line(0, "")
+
+	e.line(0, "export interface components {")
+	e.line(1, "schemas: {")
+	for _, name := range sortedKeys(doc.Components.Schemas) {
+		s := doc.Components.Schemas[name]
+		e.doc(2, "", s.Description, s.Deprecated)
+		e.line(2, "%s: %s;", quote(name), e.typeOf(s, 2))
+	}
+	e.line(1, "};")
+	e.line(0, "}")
+	e.line(0, "")
+
+	sort.Strings(opIDs)
+	e.line(0, "export interface operations {")
+	for _, id := range opIDs {
+		e.operation(1, id, ops[id])
+	}
+	e.line(0, "}")
+	e.line(0, "")
+
+	_, err = w.Write(e.buf.Bytes())
+	return err
+}
+
+type emitter struct {
+	buf bytes.Buffer
+}
+
+func (e *emitter) line(indent int, format string, args ...any) {
+	e.buf.WriteString(strings.Repeat("  ", indent))
+	fmt.Fprintf(&e.buf, format, args...)
+	e.buf.WriteByte('\n')
+}
+
+// doc writes a JSDoc comment when there is anything to say.
+func (e *emitter) doc(indent int, summary, description string, deprecated bool) {
+	var tags []string
+	if summary != "" {
+		tags = append(tags, "@summary "+summary)
+	}
+	if description != "" {
+		tags = append(tags, "@description "+description)
+	}
+	if deprecated {
+		tags = append(tags, "@deprecated")
+	}
+	if len(tags) == 0 {
+		return
+	}
+	e.line(indent, "/**")
+	for _, t := range tags {
+		for _, l := range strings.Split(strings.ReplaceAll(t, "*/", "*\\/"), "\n") {
+			e.line(indent, " * %s", l)
+		}
+	}
+	e.line(indent, " */")
+}
+
+func (e *emitter) operation(indent int, id string, op *operation) {
+	e.line(indent, "%s: {", quote(id))
+
+	e.line(indent+1, "parameters: {")
+	for _, in := range []string{"query", "header", "path", "cookie"} {
+		var params []parameter
+		required := false
+		for _, p := range op.Parameters {
+			if p.In == in {
+				params = append(params, p)
+				required = required || p.Required
+			}
+		}
+		if len(params) == 0 {
+			e.line(indent+2, "%s?: never;", in)
+			continue
+		}
+		e.line(indent+2, "%s%s: {", in, optional(required))
+		for _, p := range params {
+			e.doc(indent+3, "", p.Description, false)
+			e.line(indent+3, "%s%s: %s;", quote(p.Name), optional(p.Required), e.typeOf(p.Schema, indent+3))
+		}
+		e.line(indent+2, "};")
+	}
+	e.line(indent+1, "};")
+
+	if op.RequestBody == nil {
+		e.line(indent+1, "requestBody?: never;")
+	} else {
+		e.line(indent+1, "requestBody%s: {", optional(op.RequestBody.Required))
+		e.content(indent+2, op.RequestBody.Content)
+		e.line(indent+1, "};")
+	}
+
+	e.line(indent+1, "responses: {")
+	for _, status := range sortedKeys(op.Responses) {
+		r := op.Responses[status]
+		e.doc(indent+2, "", r.Description, false)
+		if _, err := strconv.Atoi(status); err != nil {
+			status = quote(status)
+		}
+		e.line(indent+2, "%s: {", status)
+		e.line(indent+3, "headers: {")
+		for _, name := range sortedKeys(r.Headers) {
+			h := r.Headers[name]
+			e.doc(indent+4, "", h.Description, false)
+			e.line(indent+4, "%s%s: %s;", quote(name), optional(h.Required), e.typeOf(h.Schema, indent+4))
+		}
+		e.line(indent+4, "[name: string]: unknown;")
+		e.line(indent+3, "};")
+		if len(r.Content) == 0 {
+			e.line(indent+3, "content?: never;")
+		} else {
+			e.content(indent+3, r.Content)
+		}
+		e.line(indent+2, "};")
+	}
+	e.line(indent+1, "};")
+
+	e.line(indent, "};")
+}
+
+func (e *emitter) content(indent int, content map[string]mediaType) {
+	e.line(indent, "content: {")
+	for _, ct := range sortedKeys(content) {
+		e.line(indent+1, "%s: %s;", quote(ct), e.typeOf(content[ct].Schema, indent+1))
+	}
+	e.line(indent, "};")
+}
+
+// typeOf renders s as a TypeScript type expression; nested object literals are indented
+// relative to indent.
+func (e *emitter) typeOf(s *schema, indent int) string {
+	if s == nil {
+		return "unknown"
+	}
+	t := e.baseType(s, indent)
+	if s.Nullable && !strings.Contains(t, "null") {
+		t += " | null"
+	}
+	return t
+}
+
+func (e *emitter) baseType(s *schema, indent int) string {
+	switch {
+	case s.Ref != "":
+		return fmt.Sprintf("components[\"schemas\"][%s]", quote(strings.TrimPrefix(s.Ref, "#/components/schemas/")))
+	case len(s.Enum) > 0:
+		parts := make([]string, len(s.Enum))
+		for i, v := range s.Enum {
+			b, _ := json.Marshal(v)
+			parts[i] = string(b)
+		}
+		return strings.Join(parts, " | ")
+	case len(s.OneOf) > 0:
+		return e.join(s.OneOf, " | ", indent)
+	case len(s.AnyOf) > 0:
+		return e.join(s.AnyOf, " | ", indent)
+	case len(s.AllOf) > 0:
+		return e.join(s.AllOf, " & ", indent)
+	}
+
+	var types []string
+	switch t := s.Type.(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, v := range t {
+			if str, ok := v.(string); ok {
+				types = append(types, str)
+			}
+		}
+	}
+	if len(types) == 0 {
+		if len(s.Properties) > 0 {
+			types = []string{"object"}
+		} else {
+			return "unknown"
+		}
+	}
+
+	parts := make([]string, 0, len(types))
+	for _, t := range types {
+		switch t {
+		case "string":
+			parts = append(parts, "string")
+		case "integer", "number":
+			parts = append(parts, "number")
+		case "boolean":
+			parts = append(parts, "boolean")
+		case "null":
+			parts = append(parts, "null")
+		case "array":
+			item := e.typeOf(s.Items, indent)
+			if strings.ContainsAny(item, "|&") {
+				item = "(" + item + ")"
+			}
+			parts = append(parts, item+"[]")
+		case "object":
+			parts = append(parts, e.object(s, indent))
+		default:
+			parts = append(parts, "unknown")
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+func (e *emitter) join(schemas []*schema, sep string, indent int) string {
+	parts := make([]string, len(schemas))
+	for i, s := range schemas {
+		parts[i] = e.typeOf(s, indent)
+	}
+	return strings.Join(parts, sep)
+}
+
+func (e *emitter) object(s *schema, indent int) string {
+	var additional string
+	if len(s.AdditionalProperties) > 0 {
+		var allowed bool
+		if err := json.Unmarshal(s.AdditionalProperties, &allowed); err == nil {
+			if allowed {
+				additional = "unknown"
+			}
+		} else {
+			var as schema
+			if err := json.Unmarshal(s.AdditionalProperties, &as); err == nil {
+				additional = e.typeOf(&as, indent+1)
+			}
+		}
+	}
+	if len(s.Properties) == 0 {
+		if additional == "" {
+			return "Record<string, never>"
+		}
+		return "{ [key: string]: " + additional + " }"
+	}
+
+	required := make(map[string]bool, len(s.Required))
+	for _, r := range s.Required {
+		required[r] = true
+	}
+
+	sub := &emitter{}
+	sub.buf.WriteString("{\n")
+	for _, name := range sortedKeys(s.Properties) {
+		p := s.Properties[name]
+		sub.doc(indent+1, "", p.Description, p.Deprecated)
+		readonly := ""
+		if p.ReadOnly {
+			readonly = "readonly "
+		}
+		sub.line(indent+1, "%s%s%s: %s;", readonly, quote(name), optional(required[name]), e.typeOf(p, indent+1))
+	}
+	if additional != "" {
+		sub.line(indent+1, "[key: string]: %s;", additional)
+	}
+	sub.buf.WriteString(strings.Repeat("  ", indent) + "}")
+	return sub.buf.String()
+}
+
+func optional(required bool) string {
+	if required {
+		return ""
+	}
+	return "?"
+}
+
+// quote returns name as a TypeScript property key: bare when it is a valid identifier.
+func quote(name string) string {
+	if identifier.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
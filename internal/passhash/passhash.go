@@ -0,0 +1,184 @@
+// Package passhash hashes passwords with Argon2id or bcrypt. Hashes are self-describing
+// (the bcrypt "$2b$..." and PHC "$argon2id$v=19$m=...,t=...,p=...$salt$key" formats), so a
+// Hasher verifies hashes of either algorithm and reports the ones made with other settings,
+// letting stored hashes migrate to the configured algorithm as users sign in.
+package passhash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/metrics"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Algorithm names a hashing algorithm.
+type Algorithm string
+
+const (
+	Argon2id Algorithm = "argon2id"
+	Bcrypt   Algorithm = "bcrypt"
+)
+
+// ParseAlgorithm validates an algorithm name; "" means Argon2id.
+func ParseAlgorithm(s string) (Algorithm, error) {
+	switch a := Algorithm(strings.ToLower(strings.TrimSpace(s))); a {
+	case "":
+		return Argon2id, nil
+	case Argon2id, Bcrypt:
+		return a, nil
+	default:
+		return "", fmt.Errorf("passhash: unknown algorithm %q (want argon2id or bcrypt)", s)
+	}
+}
+
+// verifications counts password verifications, labelled by the algorithm of the stored hash
+// (argon2id / bcrypt / unknown), to follow the migration.
+var verifications = metrics.NewCounter("password_hash_verifications")
+
+// Config selects the algorithm new hashes are made with and its cost. Zero fields take the
+// defaults, which follow the OWASP password storage recommendations.
+type Config struct {
+	Algorithm Algorithm
+	// BcryptCost is the bcrypt work factor. Default: bcrypt.DefaultCost (10).
+	BcryptCost int
+	// Memory is the Argon2id memory in KiB. Default: 19456 (19 MiB).
+	Memory uint32
+	// Iterations is the Argon2id number of passes. Default: 2.
+	Iterations uint32
+	// Parallelism is the Argon2id number of lanes. Default: 1.
+	Parallelism uint8
+}
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// Hasher hashes new passwords with the configured algorithm and verifies hashes of any
+// supported algorithm. It is safe for concurrent use.
+type Hasher struct {
+	cfg Config
+}
+
+// New returns a Hasher for cfg.
+func New(cfg Config) (*Hasher, error) {
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = Argon2id
+	}
+	if cfg.BcryptCost == 0 {
+		cfg.BcryptCost = bcrypt.DefaultCost
+	}
+	if cfg.Memory == 0 {
+		cfg.Memory = 19456
+	}
+	if cfg.Iterations == 0 {
+		cfg.Iterations = 2
+	}
+	if cfg.Parallelism == 0 {
+		cfg.Parallelism = 1
+	}
+	switch cfg.Algorithm {
+	case Argon2id:
+		if cfg.Memory < 8*uint32(cfg.Parallelism) {
+			return nil, fmt.Errorf("passhash: argon2id memory must be at least 8 KiB per lane")
+		}
+	case Bcrypt:
+		if cfg.BcryptCost < bcrypt.MinCost || cfg.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("passhash: bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	default:
+		return nil, fmt.Errorf("passhash: unknown algorithm %q", cfg.Algorithm)
+	}
+	return &Hasher{cfg: cfg}, nil
+}
+
+// Hash returns the encoded hash of password.
+func (h *Hasher) Hash(password string) (string, error) {
+	if h.cfg.Algorithm == Bcrypt {
+		b, err := bcrypt.GenerateFromPassword([]byte(password), h.cfg.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	p := argon2Params{memory: h.cfg.Memory, iterations: h.cfg.Iterations, parallelism: h.cfg.Parallelism}
+	key := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, argon2KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, p.memory, p.iterations, p.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify reports whether password matches hash. Malformed hashes never match.
+func (h *Hasher) Verify(password, hash string) bool {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		verifications.Inc(string(Argon2id))
+		p, salt, key, ok := decodeArgon2id(hash)
+		if !ok {
+			return false
+		}
+		got := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, uint32(len(key)))
+		return subtle.ConstantTimeCompare(got, key) == 1
+	case strings.HasPrefix(hash, "$2"):
+		verifications.Inc(string(Bcrypt))
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	default:
+		verifications.Inc("unknown")
+		return false
+	}
+}
+
+// NeedsRehash reports whether hash was made with another algorithm or other parameters than
+// new hashes are, so it should be replaced once the password is known (at sign-in).
+func (h *Hasher) NeedsRehash(hash string) bool {
+	if h.cfg.Algorithm == Bcrypt {
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != h.cfg.BcryptCost
+	}
+	p, _, key, ok := decodeArgon2id(hash)
+	return !ok || len(key) != argon2KeyLength ||
+		p != argon2Params{memory: h.cfg.Memory, iterations: h.cfg.Iterations, parallelism: h.cfg.Parallelism}
+}
+
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+// decodeArgon2id parses "$argon2id$v=19$m=<KiB>,t=<passes>,p=<lanes>$<salt>$<key>".
+func decodeArgon2id(hash string) (argon2Params, []byte, []byte, bool) {
+	var p argon2Params
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, false
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, false
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.iterations, &p.parallelism); err != nil {
+		return p, nil, nil, false
+	}
+	if p.memory == 0 || p.iterations == 0 || p.parallelism == 0 {
+		return p, nil, nil, false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, false
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, false
+	}
+	return p, salt, key, true
+}
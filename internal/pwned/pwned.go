@@ -0,0 +1,101 @@
+// Package pwned checks passwords against the HaveIBeenPwned "Pwned Passwords" corpus using
+// its k-anonymity range API: only the first 5 hex characters of the password's SHA-1 hash
+// leave the process, and the match is done locally against the returned suffixes.
+package pwned
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/metrics"
+)
+
+// DefaultEndpoint is the public range API; the hash prefix is appended.
+const DefaultEndpoint = "https://api.pwnedpasswords.com/range/"
+
+// checks counts range lookups, labelled by outcome (breached / clean / error).
+var checks = metrics.NewCounter("pwned_password_checks")
+
+// Config controls the range client.
+type Config struct {
+	// Endpoint is the range API base URL. Default: DefaultEndpoint.
+	Endpoint string
+	// MinCount is how many appearances in breaches make a password breached. Default: 1.
+	MinCount int
+	// Timeout bounds each lookup. Default: 3s.
+	Timeout time.Duration
+}
+
+// Client looks passwords up in the range API.
+type Client struct {
+	cfg  Config
+	http *http.Client
+}
+
+// New returns a range API client.
+func New(cfg Config) *Client {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = DefaultEndpoint
+	}
+	if cfg.MinCount <= 0 {
+		cfg.MinCount = 1
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 3 * time.Second
+	}
+	return &Client{cfg: cfg, http: &http.Client{Timeout: cfg.Timeout}}
+}
+
+// Breached reports whether password appears in known breaches at least MinCount times.
+func (c *Client) Breached(ctx context.Context, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.cfg.Endpoint+prefix, nil)
+	if err != nil {
+		checks.Inc("error")
+		return false, fmt.Errorf("pwned: build request: %w", err)
+	}
+	// Padding hides the real number of matching suffixes from observers of the response size.
+	req.Header.Set("Add-Padding", "true")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		checks.Inc("error")
+		return false, fmt.Errorf("pwned: range lookup: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		checks.Inc("error")
+		return false, fmt.Errorf("pwned: range lookup: unexpected status %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		// Lines are "SUFFIX:COUNT"; padding entries have a count of 0.
+		s, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok || !strings.EqualFold(s, suffix) {
+			continue
+		}
+		n, _ := strconv.Atoi(count)
+		if n >= c.cfg.MinCount {
+			checks.Inc("breached")
+			return true, nil
+		}
+		break
+	}
+	if err := scanner.Err(); err != nil {
+		checks.Inc("error")
+		return false, fmt.Errorf("pwned: read range: %w", err)
+	}
+	checks.Inc("clean")
+	return false, nil
+}
@@ -0,0 +1,79 @@
+// Package quota tracks per-caller request consumption in fixed windows backed by Redis
+// counters. Quotas are soft: consumption is reported (headers, usage endpoint) so clients
+// can back off, but requests are never rejected here.
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// Usage is a caller's consumption in the current window.
+type Usage struct {
+	Limit     int64
+	Used      int64
+	Remaining int64
+	Window    time.Duration
+	ResetAt   time.Time
+}
+
+// Tracker counts requests per subject (e.g., user ID) in fixed windows.
+type Tracker struct {
+	rdb    *redis.Client
+	ns     cache.Namespace
+	limit  int64
+	window time.Duration
+}
+
+// NewTracker creates a tracker allowing limit requests per window. Counter keys are
+// prefixed with ns so environments sharing a Redis keep separate counts.
+func NewTracker(rdb *redis.Client, ns cache.Namespace, limit int64, window time.Duration) *Tracker {
+	if window <= 0 {
+		window = time.Hour
+	}
+	return &Tracker{rdb: rdb, ns: ns, limit: limit, window: window}
+}
+
+// Consume counts one request for subject and returns the resulting usage.
+func (t *Tracker) Consume(ctx context.Context, subject string) (Usage, error) {
+	key, reset := t.key(subject, time.Now())
+	pipe := t.rdb.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.ExpireAt(ctx, key, reset)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return Usage{}, fmt.Errorf("quota consume: %w", err)
+	}
+	return t.usage(incr.Val(), reset), nil
+}
+
+// Peek returns subject's usage without counting a request.
+func (t *Tracker) Peek(ctx context.Context, subject string) (Usage, error) {
+	key, reset := t.key(subject, time.Now())
+	used, err := t.rdb.Get(ctx, key).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return Usage{}, fmt.Errorf("quota peek: %w", err)
+	}
+	return t.usage(used, reset), nil
+}
+
+// key returns the counter key for the window containing now, and when that window ends.
+func (t *Tracker) key(subject string, now time.Time) (string, time.Time) {
+	start := now.Truncate(t.window)
+	return t.ns.Key("quota", subject, strconv.FormatInt(start.Unix(), 10)), start.Add(t.window)
+}
+
+func (t *Tracker) usage(used int64, reset time.Time) Usage {
+	return Usage{
+		Limit:     t.limit,
+		Used:      used,
+		Remaining: max(t.limit-used, 0),
+		Window:    t.window,
+		ResetAt:   reset,
+	}
+}
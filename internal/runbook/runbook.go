@@ -0,0 +1,22 @@
+// Package runbook describes the operational state of background subsystems (notification
+// dispatch, the job queue, scheduled jobs) for the admin runbook endpoint, so operators can
+// tell whether async processing has stalled without querying the database or Redis directly.
+package runbook
+
+import "context"
+
+// Status is one component's snapshot. Details is JSON-encoded as is; Stalled is the
+// component's own judgement that work is piling up or has stopped being picked up.
+type Status struct {
+	Stalled bool `json:"stalled"`
+	Details any  `json:"details"`
+}
+
+// Reporter is implemented by components with background state worth showing operators.
+// Status must be cheap enough to call on every request to the endpoint.
+type Reporter interface {
+	Status(ctx context.Context) Status
+}
+
+// Func returns the status of every reporting component, keyed by component name.
+type Func func(ctx context.Context) map[string]Status
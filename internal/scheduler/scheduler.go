@@ -0,0 +1,180 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/metrics"
+	"github.com/delordemm1/go-api-simple-starter/internal/runbook"
+)
+
+// runsTotal counts job runs, labelled by job name and outcome (ok / error).
+var runsTotal = metrics.NewCounter("scheduler_job_runs")
+
+// Job runs a function periodically in the background. It implements the
+// bootstrap lifecycle Starter/Stopper interfaces, so it can be registered
+// with the lifecycle container directly.
+type Job struct {
+	name     string
+	interval time.Duration
+	fn       func(ctx context.Context) error
+	log      *slog.Logger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// Run bookkeeping for the admin runbook, guarded by mu.
+	startedAt     time.Time
+	lastRunAt     time.Time
+	lastSuccessAt time.Time
+	lastDuration  time.Duration
+	lastError     string
+	running       bool
+}
+
+// Status is a job's run history as shown on the admin runbook.
+type Status struct {
+	Interval      string     `json:"interval"`
+	Running       bool       `json:"running"`
+	LastRunAt     *time.Time `json:"lastRunAt,omitempty"`
+	LastSuccessAt *time.Time `json:"lastSuccessAt,omitempty"`
+	LastDuration  string     `json:"lastDuration,omitempty"`
+	LastError     string     `json:"lastError,omitempty"`
+}
+
+// Every returns a job that calls fn every interval, starting one interval after Start.
+// Runs never overlap; a run that outlasts the interval delays the next one.
+func Every(name string, interval time.Duration, fn func(ctx context.Context) error, log *slog.Logger) *Job {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &Job{name: name, interval: interval, fn: fn, log: log}
+}
+
+// Start launches the job loop. It does not block.
+func (j *Job) Start(ctx context.Context) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.cancel != nil {
+		return nil
+	}
+
+	// The loop outlives the startup context; it stops only via Stop.
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	j.cancel = cancel
+	j.done = make(chan struct{})
+	j.startedAt = time.Now()
+	go j.loop(runCtx, j.done)
+
+	j.log.Info("scheduled job started", "job", j.name, "interval", j.interval.String())
+	return nil
+}
+
+// Stop cancels the loop and waits for an in-flight run to finish, or for ctx to expire.
+func (j *Job) Stop(ctx context.Context) error {
+	j.mu.Lock()
+	cancel, done := j.cancel, j.done
+	j.cancel, j.done = nil, nil
+	j.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RunNow executes the job once, synchronously (e.g., from an admin endpoint or CLI).
+func (j *Job) RunNow(ctx context.Context) error {
+	return j.run(ctx)
+}
+
+// Status implements runbook.Reporter. A started job is reported stalled when it has not
+// completed a run successfully within two intervals (hung or failing every time).
+func (j *Job) Status(context.Context) runbook.Status {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	st := Status{
+		Interval:  j.interval.String(),
+		Running:   j.running,
+		LastError: j.lastError,
+	}
+	if lastRun := j.lastRunAt; !lastRun.IsZero() {
+		st.LastRunAt = &lastRun
+		st.LastDuration = j.lastDuration.String()
+	}
+	if lastSuccess := j.lastSuccessAt; !lastSuccess.IsZero() {
+		st.LastSuccessAt = &lastSuccess
+	}
+	stalled := j.cancel != nil && time.Since(later(j.startedAt, j.lastSuccessAt)) > 2*j.interval
+	return runbook.Status{Stalled: stalled, Details: st}
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func (j *Job) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = j.run(ctx)
+		}
+	}
+}
+
+func (j *Job) run(ctx context.Context) (err error) {
+	start := time.Now()
+	j.mu.Lock()
+	j.running, j.lastRunAt = true, start
+	j.mu.Unlock()
+	defer func() { j.finish(start, err) }()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+			j.log.Error("scheduled job panicked", "job", j.name, "panic", r)
+			runsTotal.Inc(j.name, "error")
+			return
+		}
+		if err != nil {
+			j.log.Error("scheduled job failed", "job", j.name, "error", err, "duration", time.Since(start).String())
+			runsTotal.Inc(j.name, "error")
+			return
+		}
+		runsTotal.Inc(j.name, "ok")
+	}()
+	return j.fn(ctx)
+}
+
+// finish records the outcome of a run started at start.
+func (j *Job) finish(start time.Time, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.running = false
+	j.lastDuration = time.Since(start)
+	if err != nil {
+		j.lastError = err.Error()
+		return
+	}
+	j.lastError = ""
+	j.lastSuccessAt = time.Now()
+}
@@ -0,0 +1,108 @@
+// Package secretbox encrypts small secrets (e.g., OAuth provider tokens) for storage with
+// AES-256-GCM. Keys are derived from configured passphrases with SHA-256, so any long random
+// string works as a key. Ciphertexts carry a key ID, which lets a Box keep decrypting values
+// sealed under previous keys while a new key is rolled out.
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+)
+
+// ErrDecrypt is returned when a ciphertext is malformed, was sealed with an unknown key,
+// or fails authentication.
+var ErrDecrypt = errors.New("secretbox: cannot decrypt")
+
+// keyIDSize is the length of the key ID prefix: the first bytes of SHA-256(key).
+const keyIDSize = 4
+
+type key struct {
+	id   [keyIDSize]byte
+	aead cipher.AEAD
+}
+
+// Box seals with its primary key and opens with the primary or any previous key.
+type Box struct {
+	keys []key // keys[0] is the primary
+}
+
+// New returns a Box sealing with secret. Values sealed with any of previous still open.
+func New(secret string, previous ...string) (*Box, error) {
+	if secret == "" {
+		return nil, errors.New("secretbox: secret is required")
+	}
+	b := &Box{}
+	for _, s := range append([]string{secret}, previous...) {
+		if s == "" {
+			continue
+		}
+		k, err := newKey(s)
+		if err != nil {
+			return nil, err
+		}
+		b.keys = append(b.keys, k)
+	}
+	return b, nil
+}
+
+func newKey(secret string) (key, error) {
+	sum := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return key{}, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return key{}, err
+	}
+	k := key{aead: aead}
+	id := sha256.Sum256(sum[:])
+	copy(k.id[:], id[:keyIDSize])
+	return k, nil
+}
+
+// Seal encrypts plaintext as key ID || nonce || ciphertext. Empty input seals to nil, so
+// absent secrets stay NULL in storage.
+func (b *Box) Seal(plaintext string) ([]byte, error) {
+	if plaintext == "" {
+		return nil, nil
+	}
+	k := b.keys[0]
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, keyIDSize+len(nonce)+len(plaintext)+k.aead.Overhead())
+	out = append(out, k.id[:]...)
+	out = append(out, nonce...)
+	return k.aead.Seal(out, nonce, []byte(plaintext), k.id[:]), nil
+}
+
+// Open decrypts a value produced by Seal. nil opens to "".
+func (b *Box) Open(sealed []byte) (string, error) {
+	if len(sealed) == 0 {
+		return "", nil
+	}
+	if len(sealed) < keyIDSize {
+		return "", ErrDecrypt
+	}
+	id, rest := sealed[:keyIDSize], sealed[keyIDSize:]
+	for _, k := range b.keys {
+		if string(k.id[:]) != string(id) {
+			continue
+		}
+		n := k.aead.NonceSize()
+		if len(rest) < n {
+			return "", ErrDecrypt
+		}
+		plaintext, err := k.aead.Open(nil, rest[:n], rest[n:], id)
+		if err != nil {
+			return "", ErrDecrypt
+		}
+		return string(plaintext), nil
+	}
+	return "", ErrDecrypt
+}
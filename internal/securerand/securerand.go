@@ -0,0 +1,76 @@
+// Package securerand generates secrets from crypto/rand: opaque tokens and one-time codes.
+// Codes are drawn by rejection sampling, so every character of the alphabet is equally
+// likely regardless of the alphabet's size (no modulo bias).
+package securerand
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// Digits is the alphabet of numeric codes.
+const Digits = "0123456789"
+
+// errAlphabet is returned for alphabets that cannot be sampled uniformly from a byte.
+var errAlphabet = errors.New("securerand: alphabet must have 2 to 256 characters")
+
+// Bytes returns n random bytes.
+func Bytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("securerand: read: %w", err)
+	}
+	return b, nil
+}
+
+// Token returns n random bytes encoded as unpadded base64url, safe in URLs and headers.
+// 32 bytes (256 bits) is the default strength for session and action tokens.
+func Token(n int) (string, error) {
+	b, err := Bytes(n)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// String returns n characters drawn uniformly from alphabet (single-byte characters).
+func String(alphabet string, n int) (string, error) {
+	size := len(alphabet)
+	if size < 2 || size > 256 {
+		return "", errAlphabet
+	}
+	// Bytes at or above limit would favour the first 256%size characters; they are rejected.
+	limit := 256 - 256%size
+
+	out := make([]byte, 0, n)
+	buf := make([]byte, n+n/2+8)
+	for len(out) < n {
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("securerand: read: %w", err)
+		}
+		for _, b := range buf {
+			if int(b) >= limit {
+				continue
+			}
+			out = append(out, alphabet[int(b)%size])
+			if len(out) == n {
+				break
+			}
+		}
+	}
+	return string(out), nil
+}
+
+// Numeric returns an n-digit numeric code; leading zeros are kept.
+func Numeric(n int) (string, error) {
+	return String(Digits, n)
+}
+
+// Equal reports whether a and b are equal in time that depends only on their lengths.
+// Use it for comparing secrets or their hashes, never ==.
+func Equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
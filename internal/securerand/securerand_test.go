@@ -0,0 +1,158 @@
+package securerand
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestBytes(t *testing.T) {
+	for _, n := range []int{0, 1, 16, 32} {
+		b, err := Bytes(n)
+		if err != nil {
+			t.Fatalf("Bytes(%d): %v", n, err)
+		}
+		if len(b) != n {
+			t.Errorf("Bytes(%d) returned %d bytes", n, len(b))
+		}
+	}
+}
+
+func TestToken(t *testing.T) {
+	for _, n := range []int{1, 16, 32, 33} {
+		tok, err := Token(n)
+		if err != nil {
+			t.Fatalf("Token(%d): %v", n, err)
+		}
+		if want := base64.RawURLEncoding.EncodedLen(n); len(tok) != want {
+			t.Errorf("Token(%d) has length %d, want %d", n, len(tok), want)
+		}
+		raw, err := base64.RawURLEncoding.Strict().DecodeString(tok)
+		if err != nil {
+			t.Errorf("Token(%d) = %q is not unpadded base64url: %v", n, tok, err)
+		}
+		if len(raw) != n {
+			t.Errorf("Token(%d) decodes to %d bytes", n, len(raw))
+		}
+	}
+
+	a, _ := Token(32)
+	b, _ := Token(32)
+	if a == b {
+		t.Error("two 32-byte tokens are equal")
+	}
+}
+
+func TestString(t *testing.T) {
+	alphabets := map[string]string{
+		"binary":     "01",
+		"digits":     Digits,
+		"base32":     "ABCDEFGHJKLMNPQRSTUVWXYZ23456789",
+		"odd size":   "abcdefg", // 256 % 7 != 0, so rejection sampling kicks in
+		"full bytes": fullByteAlphabet(),
+	}
+	for name, alphabet := range alphabets {
+		t.Run(name, func(t *testing.T) {
+			s, err := String(alphabet, 4096)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(s) != 4096 {
+				t.Fatalf("length %d, want 4096", len(s))
+			}
+			seen := make(map[byte]bool)
+			for i := 0; i < len(s); i++ {
+				if strings.IndexByte(alphabet, s[i]) < 0 {
+					t.Fatalf("character %q outside the alphabet", s[i])
+				}
+				seen[s[i]] = true
+			}
+			// 4096 draws miss a given character of a 256-character alphabet with probability
+			// (255/256)^4096 ≈ 1e-7, so every character shows up.
+			if len(seen) != len(alphabet) {
+				t.Errorf("%d of %d characters drawn", len(seen), len(alphabet))
+			}
+		})
+	}
+}
+
+func TestStringLength(t *testing.T) {
+	for _, n := range []int{0, 1, 6, 100} {
+		s, err := String(Digits, n)
+		if err != nil {
+			t.Fatalf("String(Digits, %d): %v", n, err)
+		}
+		if len(s) != n {
+			t.Errorf("String(Digits, %d) has length %d", n, len(s))
+		}
+	}
+}
+
+func TestStringAlphabetBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		alphabet string
+		wantErr  bool
+	}{
+		{"empty", "", true},
+		{"one character", "a", true},
+		{"two characters", "ab", false},
+		{"256 characters", fullByteAlphabet(), false},
+		{"257 characters", fullByteAlphabet() + "a", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := String(tt.alphabet, 8)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("String(%d characters) error = %v, want error %v", len(tt.alphabet), err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNumeric(t *testing.T) {
+	leadingZero := false
+	for range 200 {
+		code, err := Numeric(6)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(code) != 6 {
+			t.Fatalf("Numeric(6) = %q", code)
+		}
+		if strings.Trim(code, Digits) != "" {
+			t.Fatalf("Numeric(6) = %q has non-digits", code)
+		}
+		leadingZero = leadingZero || code[0] == '0'
+	}
+	// Each code starts with 0 one time in ten; 200 codes without one happen with p ≈ 7e-10.
+	if !leadingZero {
+		t.Error("no code with a leading zero: zeros are dropped or underdrawn")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"", "", true},
+		{"secret", "secret", true},
+		{"secret", "secreT", false},
+		{"secret", "secret2", false},
+		{"", "x", false},
+	}
+	for _, tt := range tests {
+		if got := Equal(tt.a, tt.b); got != tt.want {
+			t.Errorf("Equal(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func fullByteAlphabet() string {
+	b := make([]byte, 256)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return string(b)
+}
@@ -2,15 +2,25 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/delordemm1/go-api-simple-starter/internal/buildinfo"
 	"github.com/delordemm1/go-api-simple-starter/internal/config"
+	"github.com/delordemm1/go-api-simple-starter/internal/jwtkeys"
+	"github.com/delordemm1/go-api-simple-starter/internal/metrics"
+	appmw "github.com/delordemm1/go-api-simple-starter/internal/middleware"
+	"github.com/delordemm1/go-api-simple-starter/internal/modules/audit"
 	"github.com/delordemm1/go-api-simple-starter/internal/modules/user"
+	"github.com/delordemm1/go-api-simple-starter/internal/quota"
+	"github.com/delordemm1/go-api-simple-starter/internal/ratelimit"
 	"github.com/delordemm1/go-api-simple-starter/internal/session"
+	"github.com/delordemm1/go-api-simple-starter/internal/siem"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 )
@@ -23,15 +33,78 @@ import (
 // 	config *config.Config
 // }
 
-// New creates and configures a new server instance.
-func New(cfg *config.Config, log *slog.Logger, userService user.Service, sessions session.Provider) chi.Router {
+// HealthFunc reports the health of the application's components (nil value = healthy).
+type HealthFunc func(ctx context.Context) map[string]error
+
+// HealthResponse is the body of GET /health.
+type HealthResponse struct {
+	Status int
+	Body   struct {
+		Status string            `json:"status"`
+		Checks map[string]string `json:"checks,omitempty"`
+	}
+}
+
+// JWKSResponse is the body of GET /.well-known/jwks.json.
+type JWKSResponse struct {
+	CacheControl string `header:"Cache-Control"`
+	Body         jwtkeys.JWKS
+}
+
+// VersionResponse is the body of GET /version.
+type VersionResponse struct {
+	Body buildinfo.Info
+}
+
+// New creates and configures a new server instance. It fails when TRUSTED_PROXIES is invalid.
+func New(cfg *config.Config, log *slog.Logger, userService user.Service, auditService audit.Service, sessions session.Provider, usage *quota.Tracker, limiter *ratelimit.Limiter, events siem.Publisher, keys *jwtkeys.Set, health HealthFunc) (chi.Router, error) {
+	trusted, err := appmw.ParseTrustedProxies(cfg.Server.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("TRUSTED_PROXIES: %w", err)
+	}
+
 	// Create a new Chi router and Huma API.
 	router := chi.NewMux()
 	router.Use(middleware.RequestID)
-	router.Use(middleware.RealIP)
+	router.Use(appmw.ClientInfo(trusted))
 	router.Use(middleware.Logger) // Chi's built-in logger, can be replaced with a custom slog one.
+	if dbg := cfg.Debug; dbg.PayloadCapture {
+		payloads := appmw.NewPayloadLogger(appmw.PayloadLoggerConfig{
+			SampleRate:   dbg.PayloadSampleRate,
+			Secret:       dbg.PayloadSecret,
+			MaxBodyBytes: dbg.PayloadMaxBodyBytes,
+			BufferSize:   dbg.PayloadBufferSize,
+		}, log)
+		router.Use(payloads.Middleware)
+		router.Handle("/debug/payloads", payloads.Handler())
+	}
 	router.Use(middleware.Recoverer)
 	router.Use(middleware.Timeout(60 * time.Second))
+	if len(cfg.Server.CORSAllowedOrigins) > 0 {
+		router.Use(appmw.CORS(cfg.Server.CORSAllowedOrigins))
+	}
+	NewAPI(router, log, cfg.Modules, userService, auditService, sessions, usage, limiter, events, keys, health)
+
+	// Expose in-process counters (expvar JSON) for scraping, only to holders of the metrics
+	// secret: expvar includes memstats and the command line.
+	if secret := cfg.Debug.MetricsSecret; secret != "" {
+		router.Handle("/debug/vars", appmw.RequireSecret(secret, metrics.Handler()))
+	}
+
+	return router, nil
+}
+
+// module is a named set of routes that can be switched off via config.ModulesConfig.
+type module struct {
+	name     string
+	register func(api huma.API)
+}
+
+// NewAPI creates the Huma API on router and registers the enabled module routes and /health.
+// Operations declaring an audit category (middleware.Audit) are reported to events, and those
+// declaring a rate limit bucket (middleware.RateLimit) are limited by limiter (nil disables it).
+// With keys (JWT session tokens), their public keys are served at /.well-known/jwks.json.
+func NewAPI(router chi.Router, log *slog.Logger, modules config.ModulesConfig, userService user.Service, auditService audit.Service, sessions session.Provider, usage *quota.Tracker, limiter *ratelimit.Limiter, events siem.Publisher, keys *jwtkeys.Set, health HealthFunc) huma.API {
 	apiConfig := huma.DefaultConfig("Go API Starter", "1.0.0")
 	apiConfig.Components.SecuritySchemes = map[string]*huma.SecurityScheme{
 		"bearer": {
@@ -43,29 +116,94 @@ func New(cfg *config.Config, log *slog.Logger, userService user.Service, session
 	api := humachi.New(router, apiConfig)
 
 	// Add standard middleware.
-	userHandler := user.NewHandler(userService, log, sessions)
-	userHandler.RegisterRoutes(api)
+	if events == nil {
+		events = siem.Nop{}
+	}
+	api.UseMiddleware(appmw.AuditHuma(events))
+	api.UseMiddleware(appmw.RateLimitHuma(limiter, log))
+	userHandler := user.NewHandler(userService, log, sessions, usage)
+	auditHandler := audit.NewHandler(auditService, log, sessions, usage)
+	mount(api, log, modules, []module{
+		{name: "users", register: userHandler.RegisterRoutes},
+		{name: "admin", register: userHandler.RegisterAdminRoutes},
+		{name: "audit", register: auditHandler.RegisterRoutes},
+	})
 
-	// Register a simple health check endpoint.
+	// Register a health check endpoint reporting component health.
 	huma.Register(api, huma.Operation{
 		OperationID: "get-health",
 		Method:      http.MethodGet,
 		Path:        "/health",
 		Summary:     "Health Check",
-		Description: "Responds with the server's health status.",
-	}, func(ctx context.Context, input *struct{}) (*struct {
-		Body struct {
-			Status string `json:"status"`
+		Description: "Responds with the server's health status and the status of each component.",
+	}, func(ctx context.Context, input *struct{}) (*HealthResponse, error) {
+		resp := &HealthResponse{Status: http.StatusOK}
+		resp.Body.Status = "ok"
+		if health == nil {
+			return resp, nil
 		}
-	}, error) {
-		resp := &struct {
-			Body struct {
-				Status string `json:"status"`
+		resp.Body.Checks = make(map[string]string)
+		for name, err := range health(ctx) {
+			if err != nil {
+				resp.Status = http.StatusServiceUnavailable
+				resp.Body.Status = "degraded"
+				resp.Body.Checks[name] = "unhealthy"
+				log.Warn("health check failed", "component", name, "error", err)
+				continue
 			}
-		}{}
-		resp.Body.Status = "ok"
+			resp.Body.Checks[name] = "ok"
+		}
 		return resp, nil
 	})
 
-	return router
+	// Build info, so operators can match error reports to the exact deployed build.
+	huma.Register(api, huma.Operation{
+		OperationID: "get-version",
+		Method:      http.MethodGet,
+		Path:        "/version",
+		Summary:     "Build information",
+		Description: "Responds with the version, commit, and build time of the running binary.",
+	}, func(ctx context.Context, input *struct{}) (*VersionResponse, error) {
+		return &VersionResponse{Body: buildinfo.Get()}, nil
+	})
+
+	if keys != nil {
+		// Public keys verifying session JWTs, for services that check tokens offline.
+		huma.Register(api, huma.Operation{
+			OperationID: "get-jwks",
+			Method:      http.MethodGet,
+			Path:        "/.well-known/jwks.json",
+			Summary:     "JSON Web Key Set",
+			Description: "Responds with the public keys that verify session JWTs (SESSION_TOKEN_FORMAT=jwt), current key first.",
+		}, func(ctx context.Context, input *struct{}) (*JWKSResponse, error) {
+			return &JWKSResponse{CacheControl: "public, max-age=300", Body: keys.JWKS()}, nil
+		})
+	}
+
+	return api
+}
+
+// mount registers each enabled module. Names in cfg.Disabled that match no module are
+// reported, since they usually mean a typo in MODULES_DISABLED.
+func mount(api huma.API, log *slog.Logger, cfg config.ModulesConfig, modules []module) {
+	known := make(map[string]bool, len(modules))
+	for _, m := range modules {
+		known[m.name] = true
+		if !cfg.Enabled(m.name) {
+			log.Info("module disabled", "module", m.name)
+			continue
+		}
+		m.register(api)
+	}
+	for _, name := range cfg.Disabled {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" && !known[name] {
+			log.Warn("unknown module in MODULES_DISABLED", "module", name)
+		}
+	}
+}
+
+// Spec returns the OpenAPI document of the full API (every module enabled) without wiring
+// any dependencies (handlers are registered but never invoked). Used by cmd/openapi-ts.
+func Spec(log *slog.Logger) *huma.OpenAPI {
+	return NewAPI(chi.NewMux(), log, config.ModulesConfig{}, nil, nil, nil, nil, nil, nil, nil, nil).OpenAPI()
 }
@@ -0,0 +1,140 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/metrics"
+)
+
+// BindingMode is what GetAndExtend does when a session is used from a client whose fingerprint
+// differs from the one recorded when the session was created.
+type BindingMode string
+
+const (
+	BindingOff    BindingMode = "off"     // do not compare fingerprints
+	BindingLog    BindingMode = "log"     // allow, flagging Info.BindingMismatch for logging
+	BindingStepUp BindingMode = "step_up" // restrict the request to ScopeRebindRequired
+	BindingReject BindingMode = "reject"  // delete the session and return ErrBindingMismatch
+)
+
+// ParseBindingMode validates a binding mode name; "" means BindingOff.
+func ParseBindingMode(s string) (BindingMode, error) {
+	switch m := BindingMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case "":
+		return BindingOff, nil
+	case BindingOff, BindingLog, BindingStepUp, BindingReject:
+		return m, nil
+	default:
+		return "", fmt.Errorf("session: unknown binding mode %q (want off, log, step_up, or reject)", s)
+	}
+}
+
+// ScopeRebindRequired is reported by GetAndExtend in BindingStepUp mode for requests from a
+// client that does not match the session's fingerprint. Only operations that opt in (e.g.,
+// rebinding after re-entering the password, logout) accept it; Rebind lifts it.
+const ScopeRebindRequired = "rebind_required"
+
+// ErrBindingMismatch is returned by GetAndExtend in BindingReject mode; the session is deleted.
+var ErrBindingMismatch = errors.New("session used from a different client")
+
+// bindingMismatches counts uses of a session from a client with a different fingerprint,
+// labelled by binding mode.
+var bindingMismatches = metrics.NewCounter("session_binding_mismatches")
+
+// Fingerprint derives a coarse client fingerprint from a User-Agent and IP: the browser or client
+// family and the IP's network (/16 for IPv4, /48 for IPv6). It survives browser updates and
+// address changes within a provider's network, but not a token replayed from elsewhere.
+// It returns "" when neither part is known.
+func Fingerprint(userAgent, ip string) string {
+	family, network := uaFamily(userAgent), ipNetwork(ip)
+	if family == "" && network == "" {
+		return ""
+	}
+	return family + "|" + network
+}
+
+// uaFamily returns a lowercase client family such as "chrome", "firefox", or the product name of
+// a non-browser client ("okhttp", "myapp").
+func uaFamily(ua string) string {
+	ua = strings.TrimSpace(ua)
+	if ua == "" {
+		return ""
+	}
+	// Order matters: Edge and Opera also claim Chrome, and Chrome claims Safari.
+	for _, b := range []struct{ token, family string }{
+		{"Edg/", "edge"},
+		{"OPR/", "opera"},
+		{"Firefox/", "firefox"},
+		{"FxiOS/", "firefox"},
+		{"CriOS/", "chrome"},
+		{"Chrome/", "chrome"},
+		{"Safari/", "safari"},
+	} {
+		if strings.Contains(ua, b.token) {
+			return b.family
+		}
+	}
+	product, _, _ := strings.Cut(ua, "/")
+	product, _, _ = strings.Cut(product, " ")
+	return strings.ToLower(product)
+}
+
+// ipNetwork returns the /16 (IPv4) or /48 (IPv6) network of ip, or "" if ip is not an address.
+func ipNetwork(ip string) string {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+	bits := 48
+	if addr.Is4() {
+		bits = 16
+	}
+	p, err := addr.Prefix(bits)
+	if err != nil {
+		return ""
+	}
+	return p.String()
+}
+
+// checkBinding compares the client in ctx with info's fingerprint according to mode. It returns
+// ErrBindingMismatch when the session must be deleted, and extend=false when the request may
+// proceed (restricted to ScopeRebindRequired) but must not keep the session alive.
+func checkBinding(ctx context.Context, mode BindingMode, info *Info) (extend bool, err error) {
+	if bindingHolds(ctx, mode, info) {
+		return true, nil
+	}
+	bindingMismatches.Inc(string(mode))
+	switch mode {
+	case BindingReject:
+		return false, ErrBindingMismatch
+	case BindingStepUp:
+		// Not extended: a replayed token must not keep the session alive.
+		info.Scope = ScopeRebindRequired
+		info.BindingMismatch = true
+		return false, nil
+	default:
+		info.BindingMismatch = true
+		return true, nil
+	}
+}
+
+// bindingHolds reports whether the client in ctx may use info's session without any action:
+// binding is off, the session has no fingerprint, or the fingerprints match.
+func bindingHolds(ctx context.Context, mode BindingMode, info *Info) bool {
+	if mode == BindingOff || info.Fingerprint == "" {
+		return true
+	}
+	current := requestFingerprint(ctx)
+	return current == "" || current == info.Fingerprint
+}
+
+// requestFingerprint is the fingerprint of the client in ctx (see middleware.ClientInfo).
+func requestFingerprint(ctx context.Context) string {
+	return Fingerprint(contextx.UserAgent(ctx), contextx.ClientIP(ctx))
+}
@@ -0,0 +1,121 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MaxDataBytes bounds a session's data bag: the summed length of its keys and JSON values.
+// The bag is meant for a few small values (chosen organization, locale, a timestamp), not a
+// cache; a SetData that would exceed it fails with ErrDataTooLarge.
+const MaxDataBytes = 4096
+
+var (
+	// ErrDataTooLarge is returned by SetData when the bag would exceed MaxDataBytes.
+	ErrDataTooLarge = errors.New("session data too large")
+	// ErrInvalidDataKey is returned for keys that are not 1-64 letters, digits, '.', '_' or '-'.
+	ErrInvalidDataKey = errors.New("invalid session data key")
+	// ErrInvalidDataValue is returned by SetData for values that are not valid JSON.
+	ErrInvalidDataValue = errors.New("session data value is not valid JSON")
+)
+
+var dataKeyPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// checkData validates a key and, unless it is nil (a delete), the JSON value to store under it.
+func checkData(key string, value json.RawMessage) error {
+	if !dataKeyPattern.MatchString(key) {
+		return ErrInvalidDataKey
+	}
+	if value != nil && !json.Valid(value) {
+		return ErrInvalidDataValue
+	}
+	return nil
+}
+
+// GetValue decodes the value stored under key in the session's data bag into a T. ok is false
+// when the key is not set.
+func GetValue[T any](ctx context.Context, p Provider, sessionID, key string) (value T, ok bool, err error) {
+	raw, err := p.GetData(ctx, sessionID, key)
+	if err != nil || raw == nil {
+		return value, false, err
+	}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return value, false, err
+	}
+	return value, true, nil
+}
+
+// SetValue stores value, JSON-encoded, under key in the session's data bag.
+func SetValue[T any](ctx context.Context, p Provider, sessionID, key string, value T) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return p.SetData(ctx, sessionID, key, raw)
+}
+
+// DeleteValue removes key from the session's data bag. Removing an unset key is not an error.
+func DeleteValue(ctx context.Context, p Provider, sessionID, key string) error {
+	return p.SetData(ctx, sessionID, key, nil)
+}
+
+func (p *postgresProvider) GetData(ctx context.Context, sessionID, key string) (json.RawMessage, error) {
+	if err := p.tokens.Validate(sessionID, TokenAuth); err != nil {
+		return nil, err
+	}
+	if err := checkData(key, nil); err != nil {
+		return nil, err
+	}
+	var value []byte
+	err := p.db.QueryRow(ctx, `SELECT data -> $1::text FROM user_active_sessions WHERE session_token = $2`, key, HashToken(sessionID)).Scan(&value)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get session data: %w", err)
+	}
+	return value, nil
+}
+
+func (p *postgresProvider) SetData(ctx context.Context, sessionID, key string, value json.RawMessage) error {
+	if !p.owns(sessionID) {
+		return ErrNotFound
+	}
+	if err := checkData(key, value); err != nil {
+		return err
+	}
+	var jsonValue *string
+	if value != nil {
+		v := string(value)
+		jsonValue = &v
+	}
+	// The new bag is computed from the row being updated, so concurrent writes to other keys are
+	// neither lost nor able to overflow the bag together.
+	newData := `CASE WHEN $2::jsonb IS NULL THEN data - $1::text ELSE data || jsonb_build_object($1::text, $2::jsonb) END`
+	sql := `
+		UPDATE user_active_sessions SET data = ` + newData + `
+		WHERE session_token = $3
+		  AND (SELECT COALESCE(SUM(length(k) + length(v::text)), 0) FROM jsonb_each(` + newData + `) AS e(k, v)) <= $4
+	`
+	hash := HashToken(sessionID)
+	ct, err := p.db.Exec(ctx, sql, key, jsonValue, hash, MaxDataBytes)
+	if err != nil {
+		return fmt.Errorf("failed to set session data: %w", err)
+	}
+	if ct.RowsAffected() > 0 {
+		return nil
+	}
+	var exists bool
+	if err := p.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM user_active_sessions WHERE session_token = $1)`, hash).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to set session data: %w", err)
+	}
+	if exists {
+		return ErrDataTooLarge
+	}
+	return ErrNotFound
+}
@@ -0,0 +1,55 @@
+package session
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ListForUser returns the user's sessions in this provider's namespace that have not expired,
+// most recently active first.
+func (p *postgresProvider) ListForUser(ctx context.Context, userID string) ([]*Info, error) {
+	sliding, absolute := p.ttlSeconds()
+	query := `
+		SELECT ` + infoColumns + `
+		FROM user_active_sessions
+		WHERE user_id = $1 AND starts_with(session_token, $2)
+		  AND NOT ` + expiredCondition(3, 4, 5) + `
+		ORDER BY last_active_at DESC
+	`
+	rows, err := p.db.Query(ctx, query, userID, p.prefix, p.cfg.Clock.Now(), sliding, absolute)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*Info
+	for rows.Next() {
+		info, err := p.scanInfo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user session: %w", err)
+		}
+		sessions = append(sessions, info)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list user sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// DeleteForUser deletes the session with row ID id, provided it belongs to userID and this
+// provider's namespace. Other users' session IDs are reported as ErrNotFound.
+func (p *postgresProvider) DeleteForUser(ctx context.Context, userID, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrNotFound
+	}
+	ct, err := p.db.Exec(ctx, `DELETE FROM user_active_sessions WHERE id = $1 AND user_id = $2 AND starts_with(session_token, $3)`, id, userID, p.prefix)
+	if err != nil {
+		return fmt.Errorf("failed to delete user session: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
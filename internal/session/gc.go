@@ -0,0 +1,49 @@
+package session
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/metrics"
+)
+
+// gcDeleted counts sessions removed by PurgeExpired.
+var gcDeleted = metrics.NewCounter("session_gc_deleted")
+
+// defaultGCBatchSize is used when PurgeExpired is called with a non-positive batch size.
+const defaultGCBatchSize = 1000
+
+// PurgeExpired deletes sessions past their absolute, sliding, or hard expiry in batches of
+// batchSize rows, so a large backlog never holds long locks. Only sessions in this
+// provider's namespace are considered. It returns the total deleted.
+func (p *postgresProvider) PurgeExpired(ctx context.Context, batchSize int) (int64, error) {
+	if batchSize <= 0 {
+		batchSize = defaultGCBatchSize
+	}
+
+	sql := `
+		DELETE FROM user_active_sessions
+		WHERE id IN (
+			SELECT id FROM user_active_sessions
+			WHERE ` + expiredCondition(1, 2, 5) + ` AND starts_with(session_token, $4)
+			LIMIT $3
+		)
+	`
+	sliding, absolute := p.ttlSeconds()
+	var total int64
+	for {
+		ct, err := p.db.Exec(ctx, sql, p.cfg.Clock.Now(), sliding, batchSize, p.prefix, absolute)
+		if err != nil {
+			return total, fmt.Errorf("failed to purge expired sessions: %w", err)
+		}
+		n := ct.RowsAffected()
+		total += n
+		gcDeleted.Add(n)
+		if n < int64(batchSize) {
+			return total, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+	}
+}
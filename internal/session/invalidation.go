@@ -0,0 +1,163 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/cache"
+	"github.com/delordemm1/go-api-simple-starter/internal/metrics"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// invalidations counts invalidation messages, labelled by outcome (published, publish_failed,
+// received, resubscribed).
+var invalidations = metrics.NewCounter("session_invalidations")
+
+// Invalidation names the sessions whose cached state every instance must drop.
+type Invalidation struct {
+	// Hashes are HashToken values of individual sessions.
+	Hashes []string `json:"hashes,omitempty"`
+	// UserID covers every session of the user.
+	UserID string `json:"userId,omitempty"`
+	// All covers every session, e.g. after a bulk revocation by filter.
+	All bool `json:"all,omitempty"`
+	// Origin is the publishing instance, which has applied the invalidation already.
+	Origin string `json:"origin,omitempty"`
+}
+
+// Invalidator broadcasts session invalidations between API instances over a Redis pub/sub
+// channel (<ns>:session:invalidate), so per-process caches drop revoked or changed sessions
+// immediately instead of when their entries expire. Delivery is best effort: pub/sub does not
+// keep messages for a disconnected subscriber, so after a reconnect everything is invalidated.
+//
+// It implements the bootstrap lifecycle Starter/Stopper interfaces; Start subscribes.
+type Invalidator struct {
+	rdb     *redis.Client
+	channel string
+	origin  string
+	log     *slog.Logger
+
+	mu       sync.RWMutex
+	handlers []func(Invalidation)
+
+	pubsub *redis.PubSub
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewInvalidator returns an invalidator on rdb. Register handlers with OnInvalidate before Start.
+func NewInvalidator(rdb *redis.Client, ns cache.Namespace, logger *slog.Logger) *Invalidator {
+	return &Invalidator{
+		rdb:     rdb,
+		channel: ns.Key("session", "invalidate"),
+		origin:  uuid.NewString(),
+		log:     logger.With("component", "session-invalidator"),
+	}
+}
+
+// OnInvalidate registers fn to run for every invalidation, published by this instance or
+// received from another one. fn is called from the subscriber goroutine and must not block.
+func (i *Invalidator) OnInvalidate(fn func(Invalidation)) {
+	i.mu.Lock()
+	i.handlers = append(i.handlers, fn)
+	i.mu.Unlock()
+}
+
+// Publish applies inv to this instance's handlers and announces it to the other instances.
+// A failure to publish is logged; the other instances' caches then expire on their own.
+func (i *Invalidator) Publish(ctx context.Context, inv Invalidation) {
+	inv.Origin = i.origin
+	i.apply(inv)
+	raw, err := json.Marshal(inv)
+	if err == nil {
+		err = i.rdb.Publish(ctx, i.channel, raw).Err()
+	}
+	if err != nil {
+		invalidations.Inc("publish_failed")
+		i.log.Warn("failed to publish session invalidation", "error", err)
+		return
+	}
+	invalidations.Inc("published")
+}
+
+// Start subscribes to the channel and starts delivering messages from other instances. It
+// fails when the subscription cannot be confirmed. It does not block.
+func (i *Invalidator) Start(ctx context.Context) error {
+	ps := i.rdb.Subscribe(ctx, i.channel)
+	if _, err := ps.Receive(ctx); err != nil {
+		_ = ps.Close()
+		return fmt.Errorf("session: subscribe to invalidations: %w", err)
+	}
+	// The listener outlives the startup context; it stops only via Stop.
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	i.pubsub, i.cancel, i.done = ps, cancel, make(chan struct{})
+	go i.listen(runCtx, ps, i.done)
+	return nil
+}
+
+// Stop closes the subscription and waits for the listener to exit, or for ctx to expire.
+func (i *Invalidator) Stop(ctx context.Context) error {
+	if i.cancel == nil {
+		return nil
+	}
+	i.cancel()
+	// Closing the subscription unblocks a pending Receive.
+	err := i.pubsub.Close()
+	select {
+	case <-i.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (i *Invalidator) listen(ctx context.Context, ps *redis.PubSub, done chan struct{}) {
+	defer close(done)
+	for {
+		msg, err := ps.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			i.log.Warn("session invalidation subscription failed; reconnecting", "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			// Resubscribed after a lost connection: messages sent meanwhile were dropped.
+			if m.Kind == "subscribe" {
+				invalidations.Inc("resubscribed")
+				i.apply(Invalidation{All: true})
+			}
+		case *redis.Message:
+			var inv Invalidation
+			if err := json.Unmarshal([]byte(m.Payload), &inv); err != nil {
+				i.log.Warn("malformed session invalidation", "error", err)
+				continue
+			}
+			if inv.Origin == i.origin {
+				continue
+			}
+			invalidations.Inc("received")
+			i.apply(inv)
+		}
+	}
+}
+
+func (i *Invalidator) apply(inv Invalidation) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	for _, fn := range i.handlers {
+		fn(inv)
+	}
+}
@@ -0,0 +1,137 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/cache"
+	"github.com/delordemm1/go-api-simple-starter/internal/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrIssuanceBlocked is returned by CreateAuthSession while the user or client IP is blocked for
+// creating sessions faster than the IssuanceConfig thresholds allow.
+var ErrIssuanceBlocked = errors.New("session issuance temporarily blocked")
+
+var (
+	// issuance counts CreateAuthSession calls through the guard, labelled by outcome
+	// (issued / blocked) and, for blocked ones, the subject kind (user / ip).
+	issuance = metrics.NewCounter("session_issuance")
+	// issuancePerMinute is the number of sessions created in the current minute across all instances.
+	issuancePerMinute = metrics.NewGauge("session_issuance_global_per_minute")
+)
+
+// IssuanceConfig configures the issuance guard. A per-minute limit of 0 disables that check.
+type IssuanceConfig struct {
+	Redis     *redis.Client
+	Namespace cache.Namespace
+	// PerUserPerMinute and PerIPPerMinute bound the sessions one user or client IP may create
+	// in a calendar minute.
+	PerUserPerMinute int64
+	PerIPPerMinute   int64
+	// BlockFor is how long a subject that exceeded its limit is refused new sessions (default 15m).
+	BlockFor time.Duration
+	// GlobalAlertPerMinute raises an alert (via Notify) when this many sessions are created in
+	// one minute across all instances; 0 disables it. Global issuance is never blocked.
+	GlobalAlertPerMinute int64
+	// Notify is called once per minute in which GlobalAlertPerMinute is reached.
+	Notify func(perMinute int64)
+}
+
+// issuanceGuard counts session creation per user, per client IP and globally in per-minute
+// Redis counters, and refuses new sessions to a subject that floods the session table.
+type issuanceGuard struct {
+	Provider
+	cfg IssuanceConfig
+	log *slog.Logger
+}
+
+// NewIssuanceGuard wraps next so CreateAuthSession is tracked and throttled per cfg. Impersonation
+// sessions are counted but never blocked. When Redis is unavailable sessions are created as usual.
+func NewIssuanceGuard(next Provider, cfg IssuanceConfig, log *slog.Logger) Provider {
+	if cfg.BlockFor <= 0 {
+		cfg.BlockFor = 15 * time.Minute
+	}
+	return &issuanceGuard{Provider: next, cfg: cfg, log: log}
+}
+
+func (g *issuanceGuard) CreateAuthSession(ctx context.Context, userID string, meta Metadata) (string, error) {
+	blocked, err := g.admit(ctx, userID, meta)
+	if err != nil {
+		g.log.Warn("session issuance tracking failed; allowing session", "error", err)
+	}
+	if blocked != "" && meta.ImpersonatorID == "" {
+		issuance.Inc("blocked", blocked)
+		return "", ErrIssuanceBlocked
+	}
+
+	sessionID, err := g.Provider.CreateAuthSession(ctx, userID, meta)
+	if err == nil {
+		issuance.Inc("issued")
+	}
+	return sessionID, err
+}
+
+// admit counts one session for userID and meta.IP and returns the kind of subject ("user" or
+// "ip") that is blocked, or "" when the session may be created.
+func (g *issuanceGuard) admit(ctx context.Context, userID string, meta Metadata) (string, error) {
+	subjects := []struct {
+		kind, id string
+		limit    int64
+	}{
+		{"user", userID, g.cfg.PerUserPerMinute},
+		{"ip", meta.IP, g.cfg.PerIPPerMinute},
+	}
+	minute := strconv.FormatInt(time.Now().Truncate(time.Minute).Unix(), 10)
+
+	// The block check and the counters share one round trip.
+	pipe := g.cfg.Redis.Pipeline()
+	blocks := make([]*redis.IntCmd, len(subjects))
+	for i, s := range subjects {
+		if s.id != "" && s.limit > 0 {
+			blocks[i] = pipe.Exists(ctx, g.cfg.Namespace.Key("session_issuance", "block", s.kind, s.id))
+		}
+	}
+	counts := make([]*redis.IntCmd, len(subjects))
+	for i, s := range subjects {
+		if s.id == "" {
+			continue
+		}
+		key := g.cfg.Namespace.Key("session_issuance", s.kind, s.id, minute)
+		counts[i] = pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, 2*time.Minute)
+	}
+	globalKey := g.cfg.Namespace.Key("session_issuance", "global", minute)
+	global := pipe.Incr(ctx, globalKey)
+	pipe.Expire(ctx, globalKey, 2*time.Minute)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", err
+	}
+
+	perMinute := global.Val()
+	issuancePerMinute.Set(float64(perMinute))
+	if g.cfg.GlobalAlertPerMinute > 0 && perMinute == g.cfg.GlobalAlertPerMinute && g.cfg.Notify != nil {
+		g.cfg.Notify(perMinute)
+	}
+
+	for i, s := range subjects {
+		if blocks[i] != nil && blocks[i].Val() > 0 {
+			return s.kind, nil
+		}
+	}
+	for i, s := range subjects {
+		if counts[i] == nil || s.limit <= 0 || counts[i].Val() <= s.limit {
+			continue
+		}
+		if err := g.cfg.Redis.Set(ctx, g.cfg.Namespace.Key("session_issuance", "block", s.kind, s.id), 1, g.cfg.BlockFor).Err(); err != nil {
+			return s.kind, err
+		}
+		g.log.Warn("session issuance blocked", "subject", s.kind, "user_id", userID, "ip", meta.IP,
+			"per_minute", counts[i].Val(), "limit", s.limit, "block_for", g.cfg.BlockFor.String())
+		return s.kind, nil
+	}
+	return "", nil
+}
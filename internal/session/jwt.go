@@ -0,0 +1,182 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/jwtkeys"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Token formats selectable with SESSION_TOKEN_FORMAT.
+const (
+	TokenFormatOpaque = "opaque"
+	// TokenFormatJWT hands clients signed JWTs wrapping the opaque tokens (see NewJWTProvider).
+	TokenFormatJWT = "jwt"
+)
+
+// accessClaims are the claims of a JWT access token. The registered "jti" is the stored form
+// of the session's token (HashToken), which is not a credential: sessions are looked up by it,
+// so revocation, rotation and sliding expiry work as with opaque tokens, yet the JWT never
+// carries a token the opaque path would accept.
+type accessClaims struct {
+	jwt.RegisteredClaims
+	// SessionID is the session's public ID (Info.ID), as listed by GET /users/sessions.
+	SessionID string `json:"sid"`
+	// Actor is the impersonating admin (RFC 8693), for impersonation sessions.
+	Actor *actorClaim `json:"act,omitempty"`
+}
+
+type actorClaim struct {
+	Subject string `json:"sub"`
+}
+
+// jwtProvider issues signed JWTs in place of next's opaque tokens and unwraps them on the way in.
+type jwtProvider struct {
+	Provider
+	keys   *jwtkeys.Set
+	issuer string
+}
+
+// NewJWTProvider wraps next so the session tokens it hands out are JWTs signed by keys (sub is
+// the user, sid the session ID, exp the end of the session's absolute lifetime, iss issuer when
+// set). Services holding the key set's JWKS can verify them offline. Every method taking a
+// session token accepts only such JWTs; the session itself still lives in next, so it can be
+// revoked and expires idle as before. The opaque token stays server-side: a JWT names the
+// session by its stored hash (jti), which only this provider turns back into a lookup.
+func NewJWTProvider(next Provider, keys *jwtkeys.Set, issuer string) Provider {
+	return &jwtProvider{Provider: next, keys: keys, issuer: issuer}
+}
+
+func (p *jwtProvider) CreateAuthSession(ctx context.Context, userID string, meta Metadata) (string, error) {
+	sessionID, err := p.Provider.CreateAuthSession(ctx, userID, meta)
+	if err != nil {
+		return "", err
+	}
+	return p.sign(ctx, sessionID)
+}
+
+func (p *jwtProvider) GetAndExtend(ctx context.Context, sessionID string) (*Info, error) {
+	token, err := p.unwrap(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return p.Provider.GetAndExtend(ctx, token)
+}
+
+func (p *jwtProvider) Get(ctx context.Context, sessionID string) (*Info, error) {
+	token, err := p.unwrap(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return p.Provider.Get(ctx, token)
+}
+
+func (p *jwtProvider) Rebind(ctx context.Context, sessionID string, meta Metadata) error {
+	token, err := p.unwrap(sessionID)
+	if err != nil {
+		return ErrNotFound
+	}
+	return p.Provider.Rebind(ctx, token, meta)
+}
+
+func (p *jwtProvider) Elevate(ctx context.Context, sessionID string) (time.Time, error) {
+	token, err := p.unwrap(sessionID)
+	if err != nil {
+		return time.Time{}, ErrNotFound
+	}
+	return p.Provider.Elevate(ctx, token)
+}
+
+func (p *jwtProvider) GetData(ctx context.Context, sessionID, key string) (json.RawMessage, error) {
+	token, err := p.unwrap(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return p.Provider.GetData(ctx, token, key)
+}
+
+func (p *jwtProvider) SetData(ctx context.Context, sessionID, key string, value json.RawMessage) error {
+	token, err := p.unwrap(sessionID)
+	if err != nil {
+		return ErrNotFound
+	}
+	return p.Provider.SetData(ctx, token, key, value)
+}
+
+func (p *jwtProvider) Rotate(ctx context.Context, oldSessionID string) (string, error) {
+	token, err := p.unwrap(oldSessionID)
+	if err != nil {
+		return "", ErrNotFound
+	}
+	sessionID, err := p.Provider.Rotate(ctx, token)
+	if err != nil {
+		return "", err
+	}
+	return p.sign(ctx, sessionID)
+}
+
+// Delete ignores tokens that do not verify, like the stores ignore malformed ones.
+func (p *jwtProvider) Delete(ctx context.Context, sessionID string) error {
+	token, err := p.unwrap(sessionID)
+	if err != nil {
+		return nil
+	}
+	return p.Provider.Delete(ctx, token)
+}
+
+// DeleteOthersForUser deletes every session of the user when keepSessionID does not verify.
+func (p *jwtProvider) DeleteOthersForUser(ctx context.Context, userID, keepSessionID string) (int64, error) {
+	token, err := p.unwrap(keepSessionID)
+	if err != nil {
+		return p.Provider.DeleteAllForUser(ctx, userID)
+	}
+	return p.Provider.DeleteOthersForUser(ctx, userID, token)
+}
+
+// sign returns the JWT standing for the opaque token sessionID.
+func (p *jwtProvider) sign(ctx context.Context, sessionID string) (string, error) {
+	info, err := p.Provider.Get(ctx, sessionID)
+	if err != nil {
+		return "", err
+	}
+	claims := accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:   p.issuer,
+			Subject:  info.UserID,
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+			// The longest the session can live: a shorter hard expiry (Metadata.ExpiresAt) may be
+			// lifted by ClearScope, and the store enforces it meanwhile.
+			ExpiresAt: jwt.NewNumericDate(info.CreatedAt.Add(info.AbsoluteTTL)),
+			// Changes with every rotation, so JWTs signed before one stop resolving.
+			ID: HashToken(sessionID),
+		},
+		SessionID: info.ID,
+	}
+	if info.ImpersonatorID != "" {
+		claims.Actor = &actorClaim{Subject: info.ImpersonatorID}
+	}
+	return p.keys.Sign(claims)
+}
+
+// unwrap verifies sessionID as a JWT and returns a reference (tokenRef) to the session it names,
+// which next accepts in place of the opaque token.
+func (p *jwtProvider) unwrap(sessionID string) (string, error) {
+	var claims accessClaims
+	opts := []jwt.ParserOption{jwt.WithExpirationRequired()}
+	if p.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(p.issuer))
+	}
+	if err := p.keys.Parse(sessionID, &claims, opts...); err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return "", ErrExpired
+		}
+		return "", ErrMalformedToken
+	}
+	if claims.ID == "" {
+		return "", ErrMalformedToken
+	}
+	return tokenRef(claims.ID), nil
+}
@@ -0,0 +1,131 @@
+package session
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/jwtkeys"
+)
+
+func newTestJWTProvider(t *testing.T) (Provider, Provider) {
+	t.Helper()
+	keys, err := jwtkeys.New("jwt-test-secret-0123456789abcdef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// JWTs verify exp against the wall clock, so the sessions run on it too.
+	inner := NewMemoryProvider(Config{})
+	return NewJWTProvider(inner, keys, "https://api.example.com"), inner
+}
+
+func jwtClaims(t *testing.T, token string) map[string]any {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("%q is not a compact JWT", token)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		t.Fatal(err)
+	}
+	return claims
+}
+
+// TestJWTCarriesNoOpaqueToken checks that nothing in a JWT is accepted by the opaque-token path.
+func TestJWTCarriesNoOpaqueToken(t *testing.T) {
+	p, inner := newTestJWTProvider(t)
+	ctx := context.Background()
+	token, err := p.CreateAuthSession(ctx, "user-1", Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := jwtClaims(t, token)
+	if _, ok := claims["tok"]; ok {
+		t.Fatalf("JWT carries a tok claim: %v", claims)
+	}
+	info, err := p.Get(ctx, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims["sub"] != "user-1" || claims["sid"] != info.ID {
+		t.Fatalf("claims %v do not name session %s of user-1", claims, info.ID)
+	}
+	for name, value := range claims {
+		s, ok := value.(string)
+		if !ok {
+			continue
+		}
+		if _, err := inner.Get(ctx, s); err == nil {
+			t.Errorf("claim %s is accepted as an opaque session token", name)
+		}
+		// A reference must be minted by this process; one built by hand from a claim is refused.
+		i := strings.LastIndexByte(s, ':') + 1
+		if _, err := inner.Get(ctx, s[:i]+refMarker+s[i:]+".forged"); !errors.Is(err, ErrMalformedToken) {
+			t.Errorf("forged reference from claim %s: %v, want ErrMalformedToken", name, err)
+		}
+	}
+}
+
+// TestJWTRotation checks that rotating a JWT session retires the JWTs signed before it.
+func TestJWTRotation(t *testing.T) {
+	p, _ := newTestJWTProvider(t)
+	ctx := context.Background()
+	old, err := p.CreateAuthSession(ctx, "user-1", Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetData(ctx, old, "step", []byte(`2`)); err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := p.Rotate(ctx, old)
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if _, err := p.GetAndExtend(ctx, old); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("JWT signed before the rotation: %v, want ErrNotFound", err)
+	}
+	if jwtClaims(t, old)["sid"] != jwtClaims(t, rotated)["sid"] {
+		t.Fatal("rotation changed the session ID")
+	}
+	if v, err := p.GetData(ctx, rotated, "step"); err != nil || string(v) != "2" {
+		t.Fatalf("data after rotation = %s, %v", v, err)
+	}
+	if _, err := p.Elevate(ctx, rotated); err != nil {
+		t.Fatalf("Elevate after rotation: %v", err)
+	}
+	if err := p.Delete(ctx, rotated); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Get(ctx, rotated); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("JWT after Delete: %v, want ErrNotFound", err)
+	}
+}
+
+func TestJWTRejectsOtherTokens(t *testing.T) {
+	p, inner := newTestJWTProvider(t)
+	ctx := context.Background()
+	opaque, err := inner.CreateAuthSession(ctx, "user-1", Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKeys, _ := jwtkeys.New("another-secret-0123456789abcdefgh")
+	foreign, err := NewJWTProvider(inner, otherKeys, "https://api.example.com").CreateAuthSession(ctx, "user-1", Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, token := range map[string]string{"opaque token": opaque, "JWT from another key": foreign, "garbage": "a.b.c"} {
+		if _, err := p.GetAndExtend(ctx, token); !errors.Is(err, ErrMalformedToken) {
+			t.Errorf("%s: %v, want ErrMalformedToken", name, err)
+		}
+	}
+}
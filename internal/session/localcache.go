@@ -0,0 +1,196 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/clock"
+	"github.com/delordemm1/go-api-simple-starter/internal/metrics"
+)
+
+// localCacheLookups counts GetAndExtend calls through the local cache, labelled hit / miss.
+var localCacheLookups = metrics.NewCounter("session_local_cache")
+
+// maxCachedSessions bounds the local cache. When it is full, stale entries are swept and, if
+// that is not enough, the whole cache is dropped.
+const maxCachedSessions = 100000
+
+// cachedProvider keeps GetAndExtend results in process for a few seconds, sparing the store a
+// read and a write per authenticated request. Changes made through it are published with an
+// Invalidator, so every instance drops the affected entries at once.
+type cachedProvider struct {
+	Provider
+	ttl     time.Duration
+	binding BindingMode
+	clock   clock.Clock
+	inv     *Invalidator
+
+	mu sync.Mutex
+	// entries is keyed by HashToken of the session token.
+	entries map[string]cachedSession
+}
+
+type cachedSession struct {
+	info  Info
+	until time.Time
+}
+
+// NewCachedProvider wraps next with a per-process cache of GetAndExtend results kept for ttl.
+// cfg supplies the binding mode, checked on every hit, and the clock. Revocations and other
+// changes made through the returned provider are published on inv; changes made around it
+// (another process writing the store directly) are only seen when entries expire.
+// A session's sliding TTL is extended at most once per ttl.
+func NewCachedProvider(next Provider, ttl time.Duration, cfg Config, inv *Invalidator) Provider {
+	cfg = cfg.withDefaults()
+	p := &cachedProvider{
+		Provider: next,
+		ttl:      ttl,
+		binding:  cfg.Binding,
+		clock:    cfg.Clock,
+		inv:      inv,
+		entries:  make(map[string]cachedSession),
+	}
+	inv.OnInvalidate(p.drop)
+	return p
+}
+
+func (p *cachedProvider) GetAndExtend(ctx context.Context, sessionID string) (*Info, error) {
+	hash := HashToken(sessionID)
+	now := p.clock.Now()
+	p.mu.Lock()
+	e, ok := p.entries[hash]
+	p.mu.Unlock()
+	// A client mismatch goes to the provider, which acts on it per the binding mode.
+	if ok && now.Before(e.until) && now.Before(e.info.AbsoluteExpiresAt) && now.Before(e.info.IdleExpiresAt) &&
+		bindingHolds(ctx, p.binding, &e.info) {
+		localCacheLookups.Inc("hit")
+		info := e.info
+		info.Elevated = now.Before(info.ElevatedUntil)
+		return &info, nil
+	}
+	localCacheLookups.Inc("miss")
+
+	info, err := p.Provider.GetAndExtend(ctx, sessionID)
+	if err != nil {
+		if ok {
+			p.drop(Invalidation{Hashes: []string{hash}})
+		}
+		return nil, err
+	}
+	if !info.BindingMismatch {
+		p.store(hash, *info, now)
+	}
+	return info, nil
+}
+
+func (p *cachedProvider) Rebind(ctx context.Context, sessionID string, meta Metadata) error {
+	err := p.Provider.Rebind(ctx, sessionID, meta)
+	if err == nil {
+		p.inv.Publish(ctx, Invalidation{Hashes: []string{HashToken(sessionID)}})
+	}
+	return err
+}
+
+func (p *cachedProvider) Elevate(ctx context.Context, sessionID string) (time.Time, error) {
+	until, err := p.Provider.Elevate(ctx, sessionID)
+	if err == nil {
+		p.inv.Publish(ctx, Invalidation{Hashes: []string{HashToken(sessionID)}})
+	}
+	return until, err
+}
+
+func (p *cachedProvider) Rotate(ctx context.Context, oldSessionID string) (string, error) {
+	sessionID, err := p.Provider.Rotate(ctx, oldSessionID)
+	if err == nil {
+		p.inv.Publish(ctx, Invalidation{Hashes: []string{HashToken(oldSessionID)}})
+	}
+	return sessionID, err
+}
+
+func (p *cachedProvider) Delete(ctx context.Context, sessionID string) error {
+	err := p.Provider.Delete(ctx, sessionID)
+	if err == nil {
+		p.inv.Publish(ctx, Invalidation{Hashes: []string{HashToken(sessionID)}})
+	}
+	return err
+}
+
+func (p *cachedProvider) DeleteAllForUser(ctx context.Context, userID string) (int64, error) {
+	n, err := p.Provider.DeleteAllForUser(ctx, userID)
+	if err == nil {
+		p.inv.Publish(ctx, Invalidation{UserID: userID})
+	}
+	return n, err
+}
+
+func (p *cachedProvider) DeleteOthersForUser(ctx context.Context, userID, keepSessionID string) (int64, error) {
+	n, err := p.Provider.DeleteOthersForUser(ctx, userID, keepSessionID)
+	if err == nil {
+		p.inv.Publish(ctx, Invalidation{UserID: userID})
+	}
+	return n, err
+}
+
+func (p *cachedProvider) DeleteForUser(ctx context.Context, userID, id string) error {
+	err := p.Provider.DeleteForUser(ctx, userID, id)
+	if err == nil {
+		p.inv.Publish(ctx, Invalidation{UserID: userID})
+	}
+	return err
+}
+
+func (p *cachedProvider) ClearScope(ctx context.Context, userID, scope string) (int64, error) {
+	n, err := p.Provider.ClearScope(ctx, userID, scope)
+	if err == nil {
+		p.inv.Publish(ctx, Invalidation{UserID: userID})
+	}
+	return n, err
+}
+
+// DeleteMatching invalidates every cached session once anything was deleted, even when a later
+// batch failed.
+func (p *cachedProvider) DeleteMatching(ctx context.Context, f Filter, batchSize int, progress func(deleted int64)) (int64, error) {
+	n, err := p.Provider.DeleteMatching(ctx, f, batchSize, progress)
+	if n > 0 {
+		p.inv.Publish(ctx, Invalidation{All: true})
+	}
+	return n, err
+}
+
+func (p *cachedProvider) store(hash string, info Info, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.entries) >= maxCachedSessions {
+		for h, e := range p.entries {
+			if !now.Before(e.until) {
+				delete(p.entries, h)
+			}
+		}
+		if len(p.entries) >= maxCachedSessions {
+			clear(p.entries)
+		}
+	}
+	p.entries[hash] = cachedSession{info: info, until: now.Add(p.ttl)}
+}
+
+// drop removes the entries inv names.
+func (p *cachedProvider) drop(inv Invalidation) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if inv.All {
+		clear(p.entries)
+		return
+	}
+	for _, h := range inv.Hashes {
+		delete(p.entries, h)
+	}
+	if inv.UserID == "" {
+		return
+	}
+	for h, e := range p.entries {
+		if e.info.UserID == inv.UserID {
+			delete(p.entries, h)
+		}
+	}
+}
@@ -0,0 +1,350 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// memoryProvider keeps sessions in a map guarded by a mutex, for tests and local demos that run
+// without Postgres or Redis. Sessions are lost on restart and not shared between instances.
+type memoryProvider struct {
+	cfg    Config
+	tokens *Tokens
+
+	mu sync.Mutex
+	// sessions is keyed by HashToken of the session token, like the other stores.
+	sessions map[string]*memorySession
+}
+
+// memorySession is a stored session; Info's derived fields are computed on read.
+type memorySession struct {
+	info          Info
+	expiresAt     *time.Time
+	elevatedUntil *time.Time
+	sliding       int64
+	absolute      int64
+	data          map[string]json.RawMessage
+}
+
+// NewMemoryProvider returns an in-memory Provider. It is safe for concurrent use.
+// Filter.UnverifiedUsers is not supported by DeleteMatching, which knows nothing of users.
+func NewMemoryProvider(cfg Config) Provider {
+	cfg = cfg.withDefaults()
+	return &memoryProvider{cfg: cfg, tokens: NewTokens(cfg.Namespace, cfg.TokenBytes), sessions: make(map[string]*memorySession)}
+}
+
+func (p *memoryProvider) CreateAuthSession(ctx context.Context, userID string, meta Metadata) (string, error) {
+	sessionID, err := p.tokens.New(TokenAuth)
+	if err != nil {
+		return "", err
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate session id: %w", err)
+	}
+
+	sliding, absolute := p.cfg.ttlsFor(meta)
+	now := p.cfg.Clock.Now()
+	s := &memorySession{
+		info: Info{
+			ID:             id.String(),
+			UserID:         userID,
+			UserAgent:      meta.UserAgent,
+			IP:             meta.IP,
+			AuthMethod:     meta.AuthMethod,
+			Scope:          meta.Scope,
+			CreatedAt:      now,
+			LastActiveAt:   now,
+			Fingerprint:    Fingerprint(meta.UserAgent, meta.IP),
+			ImpersonatorID: meta.ImpersonatorID,
+			RememberMe:     meta.RememberMe,
+		},
+		sliding:  int64(sliding / time.Second),
+		absolute: int64(absolute / time.Second),
+	}
+	if !meta.ExpiresAt.IsZero() {
+		expiresAt := meta.ExpiresAt
+		s.expiresAt = &expiresAt
+	}
+
+	p.mu.Lock()
+	p.sessions[HashToken(sessionID)] = s
+	p.mu.Unlock()
+	return sessionID, nil
+}
+
+func (p *memoryProvider) GetAndExtend(ctx context.Context, sessionID string) (*Info, error) {
+	if err := p.tokens.Validate(sessionID, TokenAuth); err != nil {
+		return nil, err
+	}
+	hash := HashToken(sessionID)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.sessions[hash]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	info := p.view(s)
+	now := p.cfg.Clock.Now()
+	if p.expired(info, now) {
+		delete(p.sessions, hash)
+		return nil, ErrExpired
+	}
+
+	extend, err := checkBinding(ctx, p.cfg.Binding, info)
+	if err != nil {
+		delete(p.sessions, hash)
+		return nil, err
+	}
+	if !extend {
+		return info, nil
+	}
+
+	// Extend sliding TTL
+	s.info.LastActiveAt = now
+	info.LastActiveAt = now
+	info.IdleExpiresAt = now.Add(info.SlidingTTL)
+	info.Elevated = now.Before(info.ElevatedUntil)
+	return info, nil
+}
+
+func (p *memoryProvider) Get(ctx context.Context, sessionID string) (*Info, error) {
+	if err := p.tokens.Validate(sessionID, TokenAuth); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.sessions[HashToken(sessionID)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return p.view(s), nil
+}
+
+func (p *memoryProvider) Rebind(ctx context.Context, sessionID string, meta Metadata) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.lookup(sessionID)
+	if !ok {
+		return ErrNotFound
+	}
+	s.info.UserAgent, s.info.IP = meta.UserAgent, meta.IP
+	s.info.Fingerprint = Fingerprint(meta.UserAgent, meta.IP)
+	s.info.LastActiveAt = p.cfg.Clock.Now()
+	return nil
+}
+
+func (p *memoryProvider) Elevate(ctx context.Context, sessionID string) (time.Time, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.lookup(sessionID)
+	if !ok || s.info.ImpersonatorID != "" {
+		return time.Time{}, ErrNotFound
+	}
+	until := p.cfg.Clock.Now().Add(p.cfg.ElevationTTL)
+	s.elevatedUntil = &until
+	return until, nil
+}
+
+func (p *memoryProvider) GetData(ctx context.Context, sessionID, key string) (json.RawMessage, error) {
+	if err := p.tokens.Validate(sessionID, TokenAuth); err != nil {
+		return nil, err
+	}
+	if err := checkData(key, nil); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.sessions[HashToken(sessionID)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append(json.RawMessage(nil), s.data[key]...), nil
+}
+
+func (p *memoryProvider) SetData(ctx context.Context, sessionID, key string, value json.RawMessage) error {
+	if err := checkData(key, value); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.lookup(sessionID)
+	if !ok {
+		return ErrNotFound
+	}
+	if value == nil {
+		delete(s.data, key)
+		return nil
+	}
+	size := len(key) + len(value)
+	for k, v := range s.data {
+		if k != key {
+			size += len(k) + len(v)
+		}
+	}
+	if size > MaxDataBytes {
+		return ErrDataTooLarge
+	}
+	if s.data == nil {
+		s.data = make(map[string]json.RawMessage)
+	}
+	s.data[key] = append(json.RawMessage(nil), value...)
+	return nil
+}
+
+func (p *memoryProvider) Rotate(ctx context.Context, oldSessionID string) (string, error) {
+	sessionID, err := p.tokens.New(TokenAuth)
+	if err != nil {
+		return "", err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.lookup(oldSessionID)
+	if !ok {
+		return "", ErrNotFound
+	}
+	delete(p.sessions, HashToken(oldSessionID))
+	p.sessions[HashToken(sessionID)] = s
+	return sessionID, nil
+}
+
+func (p *memoryProvider) Delete(ctx context.Context, sessionID string) error {
+	if p.tokens.Validate(sessionID, TokenAuth) != nil {
+		return nil
+	}
+	p.mu.Lock()
+	delete(p.sessions, HashToken(sessionID))
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *memoryProvider) DeleteAllForUser(ctx context.Context, userID string) (int64, error) {
+	return p.deleteWhere(func(hash string, s *memorySession) bool { return s.info.UserID == userID }), nil
+}
+
+func (p *memoryProvider) DeleteOthersForUser(ctx context.Context, userID, keepSessionID string) (int64, error) {
+	keep := HashToken(keepSessionID)
+	return p.deleteWhere(func(hash string, s *memorySession) bool {
+		return s.info.UserID == userID && hash != keep
+	}), nil
+}
+
+func (p *memoryProvider) ListForUser(ctx context.Context, userID string) ([]*Info, error) {
+	now := p.cfg.Clock.Now()
+	p.mu.Lock()
+	var sessions []*Info
+	for _, s := range p.sessions {
+		if s.info.UserID != userID {
+			continue
+		}
+		if info := p.view(s); !p.expired(info, now) {
+			sessions = append(sessions, info)
+		}
+	}
+	p.mu.Unlock()
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastActiveAt.After(sessions[j].LastActiveAt) })
+	return sessions, nil
+}
+
+func (p *memoryProvider) DeleteForUser(ctx context.Context, userID, id string) error {
+	n := p.deleteWhere(func(hash string, s *memorySession) bool {
+		return s.info.UserID == userID && s.info.ID == id
+	})
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (p *memoryProvider) ClearScope(ctx context.Context, userID, scope string) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var n int64
+	for _, s := range p.sessions {
+		if s.info.UserID == userID && s.info.Scope == scope {
+			s.info.Scope = ""
+			s.expiresAt = nil
+			n++
+		}
+	}
+	return n, nil
+}
+
+// PurgeExpired deletes every expired session; batchSize does not apply to a map.
+func (p *memoryProvider) PurgeExpired(ctx context.Context, batchSize int) (int64, error) {
+	now := p.cfg.Clock.Now()
+	n := p.deleteWhere(func(hash string, s *memorySession) bool { return p.expired(p.view(s), now) })
+	gcDeleted.Add(n)
+	return n, nil
+}
+
+// DeleteMatching deletes the sessions matching f in one pass and reports the total to progress.
+func (p *memoryProvider) DeleteMatching(ctx context.Context, f Filter, batchSize int, progress func(deleted int64)) (int64, error) {
+	if f.IsZero() {
+		return 0, nil
+	}
+	if f.UnverifiedUsers {
+		return 0, errors.New("session: the memory provider cannot filter by unverified users")
+	}
+	ipRange := f.IPRange.Masked()
+	n := p.deleteWhere(func(hash string, s *memorySession) bool {
+		if !f.CreatedBefore.IsZero() && !s.info.CreatedAt.Before(f.CreatedBefore) {
+			return false
+		}
+		if f.IPRange.IsValid() {
+			addr, err := netip.ParseAddr(s.info.IP)
+			if err != nil || !ipRange.Contains(addr.Unmap()) {
+				return false
+			}
+		}
+		return true
+	})
+	bulkRevoked.Add(n)
+	if progress != nil {
+		progress(n)
+	}
+	return n, nil
+}
+
+// lookup returns the session of sessionID. The caller holds p.mu.
+func (p *memoryProvider) lookup(sessionID string) (*memorySession, bool) {
+	if p.tokens.Validate(sessionID, TokenAuth) != nil {
+		return nil, false
+	}
+	s, ok := p.sessions[HashToken(sessionID)]
+	return s, ok
+}
+
+// deleteWhere deletes the sessions for which match returns true and returns how many.
+func (p *memoryProvider) deleteWhere(match func(hash string, s *memorySession) bool) int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var n int64
+	for hash, s := range p.sessions {
+		if match(hash, s) {
+			delete(p.sessions, hash)
+			n++
+		}
+	}
+	return n
+}
+
+// view returns a copy of s's Info with its lifetime derived. The caller holds p.mu.
+func (p *memoryProvider) view(s *memorySession) *Info {
+	info := s.info
+	p.cfg.deriveLifetime(&info, s.expiresAt, s.elevatedUntil, &s.sliding, &s.absolute)
+	return &info
+}
+
+func (p *memoryProvider) expired(info *Info, now time.Time) bool {
+	return now.After(info.AbsoluteExpiresAt) || now.After(info.IdleExpiresAt)
+}
@@ -0,0 +1,220 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/clock"
+)
+
+var epoch = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestProvider returns a memory provider on a fake clock with short TTLs: 1h idle, 24h
+// absolute, 10m elevation.
+func newTestProvider(t *testing.T) (Provider, *clock.Fake) {
+	t.Helper()
+	clk := clock.NewFake(epoch)
+	return NewMemoryProvider(Config{
+		SlidingTTL:            time.Hour,
+		AbsoluteTTL:           24 * time.Hour,
+		RememberMeSlidingTTL:  48 * time.Hour,
+		RememberMeAbsoluteTTL: 7 * 24 * time.Hour,
+		ElevationTTL:          10 * time.Minute,
+		Clock:                 clk,
+	}), clk
+}
+
+func createSession(t *testing.T, p Provider, meta Metadata) string {
+	t.Helper()
+	token, err := p.CreateAuthSession(context.Background(), "user-1", meta)
+	if err != nil {
+		t.Fatalf("CreateAuthSession: %v", err)
+	}
+	return token
+}
+
+func mustGetAndExtend(t *testing.T, p Provider, token string) *Info {
+	t.Helper()
+	info, err := p.GetAndExtend(context.Background(), token)
+	if err != nil {
+		t.Fatalf("GetAndExtend: %v", err)
+	}
+	return info
+}
+
+func TestMemoryProviderIdleExpiry(t *testing.T) {
+	p, clk := newTestProvider(t)
+	ctx := context.Background()
+	token := createSession(t, p, Metadata{})
+
+	// Each use within the idle timeout extends it.
+	for range 3 {
+		clk.Advance(59 * time.Minute)
+		info := mustGetAndExtend(t, p, token)
+		if want := clk.Now().Add(time.Hour); !info.IdleExpiresAt.Equal(want) {
+			t.Fatalf("IdleExpiresAt = %v, want %v", info.IdleExpiresAt, want)
+		}
+	}
+
+	clk.Advance(time.Hour + time.Second)
+	if _, err := p.GetAndExtend(ctx, token); !errors.Is(err, ErrExpired) {
+		t.Fatalf("GetAndExtend after the idle timeout: %v, want ErrExpired", err)
+	}
+	// The expired session was deleted when presented.
+	if _, err := p.GetAndExtend(ctx, token); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetAndExtend after expiry: %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryProviderAbsoluteExpiry(t *testing.T) {
+	p, clk := newTestProvider(t)
+	token := createSession(t, p, Metadata{})
+
+	// Constant activity does not carry a session past its absolute lifetime.
+	for clk.Now().Before(epoch.Add(24*time.Hour - 30*time.Minute)) {
+		clk.Advance(30 * time.Minute)
+		mustGetAndExtend(t, p, token)
+	}
+	clk.Set(epoch.Add(24*time.Hour + time.Second))
+	if _, err := p.GetAndExtend(context.Background(), token); !errors.Is(err, ErrExpired) {
+		t.Fatalf("GetAndExtend past the absolute lifetime: %v, want ErrExpired", err)
+	}
+}
+
+func TestMemoryProviderRememberMe(t *testing.T) {
+	p, clk := newTestProvider(t)
+	token := createSession(t, p, Metadata{RememberMe: true})
+
+	clk.Advance(47 * time.Hour)
+	info := mustGetAndExtend(t, p, token)
+	if !info.RememberMe || info.SlidingTTL != 48*time.Hour || info.AbsoluteTTL != 7*24*time.Hour {
+		t.Fatalf("remember-me session has TTLs %v / %v", info.SlidingTTL, info.AbsoluteTTL)
+	}
+	clk.Set(epoch.Add(7*24*time.Hour + time.Second))
+	if _, err := p.GetAndExtend(context.Background(), token); !errors.Is(err, ErrExpired) {
+		t.Fatalf("GetAndExtend past the remember-me lifetime: %v, want ErrExpired", err)
+	}
+}
+
+func TestMemoryProviderHardExpiryLiftedByClearScope(t *testing.T) {
+	p, clk := newTestProvider(t)
+	ctx := context.Background()
+	limited := createSession(t, p, Metadata{Scope: ScopeEmailUnverified, ExpiresAt: epoch.Add(30 * time.Minute)})
+	other := createSession(t, p, Metadata{Scope: ScopeEmailUnverified, ExpiresAt: epoch.Add(30 * time.Minute)})
+
+	clk.Advance(20 * time.Minute)
+	if n, err := p.ClearScope(ctx, "user-1", ScopeEmailUnverified); err != nil || n != 2 {
+		t.Fatalf("ClearScope = %d, %v; want 2 sessions", n, err)
+	}
+	if err := p.Delete(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+	clk.Advance(20 * time.Minute)
+	info := mustGetAndExtend(t, p, limited)
+	if info.Scope != "" || !info.AbsoluteExpiresAt.Equal(epoch.Add(24*time.Hour)) {
+		t.Fatalf("after ClearScope: scope %q, absolute expiry %v", info.Scope, info.AbsoluteExpiresAt)
+	}
+}
+
+func TestMemoryProviderElevation(t *testing.T) {
+	p, clk := newTestProvider(t)
+	ctx := context.Background()
+	token := createSession(t, p, Metadata{})
+
+	// New sessions start out recently authenticated.
+	if info := mustGetAndExtend(t, p, token); !info.Elevated {
+		t.Fatal("new session is not elevated")
+	}
+	clk.Advance(10*time.Minute + time.Second)
+	if info := mustGetAndExtend(t, p, token); info.Elevated {
+		t.Fatal("session still elevated after ElevationTTL")
+	}
+
+	until, err := p.Elevate(ctx, token)
+	if err != nil {
+		t.Fatalf("Elevate: %v", err)
+	}
+	if want := clk.Now().Add(10 * time.Minute); !until.Equal(want) {
+		t.Fatalf("Elevate until %v, want %v", until, want)
+	}
+	clk.Advance(9 * time.Minute)
+	if info := mustGetAndExtend(t, p, token); !info.Elevated || !info.ElevatedUntil.Equal(until) {
+		t.Fatalf("after Elevate: elevated %v until %v", info.Elevated, info.ElevatedUntil)
+	}
+	clk.Advance(2 * time.Minute)
+	if info := mustGetAndExtend(t, p, token); info.Elevated {
+		t.Fatal("session still elevated after the elevation ended")
+	}
+}
+
+func TestMemoryProviderImpersonationNeverElevated(t *testing.T) {
+	p, _ := newTestProvider(t)
+	token := createSession(t, p, Metadata{ImpersonatorID: "admin-1"})
+
+	if info := mustGetAndExtend(t, p, token); info.Elevated {
+		t.Fatal("impersonation session is elevated")
+	}
+	if _, err := p.Elevate(context.Background(), token); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Elevate of an impersonation session: %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryProviderRotate(t *testing.T) {
+	p, clk := newTestProvider(t)
+	ctx := context.Background()
+	old := createSession(t, p, Metadata{})
+	if err := p.SetData(ctx, old, "cart", []byte(`{"items":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	before := mustGetAndExtend(t, p, old)
+
+	clk.Advance(30 * time.Minute)
+	rotated, err := p.Rotate(ctx, old)
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if rotated == old {
+		t.Fatal("Rotate returned the old token")
+	}
+	if _, err := p.Get(ctx, old); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("old token after Rotate: %v, want ErrNotFound", err)
+	}
+	if _, err := p.Rotate(ctx, old); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("rotating the old token again: %v, want ErrNotFound", err)
+	}
+
+	after := mustGetAndExtend(t, p, rotated)
+	if after.ID != before.ID || !after.CreatedAt.Equal(before.CreatedAt) {
+		t.Fatalf("rotation changed the session: %s/%v -> %s/%v", before.ID, before.CreatedAt, after.ID, after.CreatedAt)
+	}
+	if v, err := p.GetData(ctx, rotated, "cart"); err != nil || string(v) != `{"items":1}` {
+		t.Fatalf("data after Rotate = %s, %v", v, err)
+	}
+
+	// The lifetime still counts from creation, not from the rotation.
+	clk.Set(epoch.Add(24*time.Hour + time.Second))
+	if _, err := p.GetAndExtend(ctx, rotated); !errors.Is(err, ErrExpired) {
+		t.Fatalf("rotated session past the original lifetime: %v, want ErrExpired", err)
+	}
+}
+
+func TestMemoryProviderPurgeExpired(t *testing.T) {
+	p, clk := newTestProvider(t)
+	ctx := context.Background()
+	idle := createSession(t, p, Metadata{})
+	active := createSession(t, p, Metadata{})
+
+	clk.Advance(50 * time.Minute)
+	mustGetAndExtend(t, p, active)
+	clk.Advance(20 * time.Minute)
+
+	if n, err := p.PurgeExpired(ctx, 100); err != nil || n != 1 {
+		t.Fatalf("PurgeExpired = %d, %v; want 1", n, err)
+	}
+	if _, err := p.Get(ctx, idle); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("idle session after purge: %v, want ErrNotFound", err)
+	}
+	mustGetAndExtend(t, p, active)
+}
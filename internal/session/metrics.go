@@ -0,0 +1,146 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/metrics"
+)
+
+// providerTimer publishes per-method call counts, errors, and latency of the session provider.
+var providerTimer = metrics.NewTimer("session_provider")
+
+// instrumentedProvider decorates a Provider with per-method metrics (see metrics.Timer).
+type instrumentedProvider struct {
+	next Provider
+}
+
+// NewInstrumentedProvider wraps next so every call is timed and counted under
+// session_provider_* with the method name as label.
+func NewInstrumentedProvider(next Provider) Provider {
+	return &instrumentedProvider{next: next}
+}
+
+// observe records a call. Unknown, expired, malformed, or mismatched tokens are rejected sessions rather
+// than provider failures, so they do not count towards the error rate; neither do rejected data.
+func (p *instrumentedProvider) observe(start time.Time, err error, method string) {
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrExpired) || errors.Is(err, ErrMalformedToken) || errors.Is(err, ErrBindingMismatch) ||
+		errors.Is(err, ErrDataTooLarge) || errors.Is(err, ErrInvalidDataKey) || errors.Is(err, ErrInvalidDataValue) {
+		err = nil
+	}
+	providerTimer.Observe(start, err, method)
+}
+
+func (p *instrumentedProvider) CreateAuthSession(ctx context.Context, userID string, meta Metadata) (string, error) {
+	start := time.Now()
+	sessionID, err := p.next.CreateAuthSession(ctx, userID, meta)
+	p.observe(start, err, "CreateAuthSession")
+	return sessionID, err
+}
+
+func (p *instrumentedProvider) GetAndExtend(ctx context.Context, sessionID string) (*Info, error) {
+	start := time.Now()
+	info, err := p.next.GetAndExtend(ctx, sessionID)
+	p.observe(start, err, "GetAndExtend")
+	return info, err
+}
+
+func (p *instrumentedProvider) Get(ctx context.Context, sessionID string) (*Info, error) {
+	start := time.Now()
+	info, err := p.next.Get(ctx, sessionID)
+	p.observe(start, err, "Get")
+	return info, err
+}
+
+func (p *instrumentedProvider) GetData(ctx context.Context, sessionID, key string) (json.RawMessage, error) {
+	start := time.Now()
+	value, err := p.next.GetData(ctx, sessionID, key)
+	p.observe(start, err, "GetData")
+	return value, err
+}
+
+func (p *instrumentedProvider) SetData(ctx context.Context, sessionID, key string, value json.RawMessage) error {
+	start := time.Now()
+	err := p.next.SetData(ctx, sessionID, key, value)
+	p.observe(start, err, "SetData")
+	return err
+}
+
+func (p *instrumentedProvider) Rotate(ctx context.Context, oldSessionID string) (string, error) {
+	start := time.Now()
+	sessionID, err := p.next.Rotate(ctx, oldSessionID)
+	p.observe(start, err, "Rotate")
+	return sessionID, err
+}
+
+func (p *instrumentedProvider) Delete(ctx context.Context, sessionID string) error {
+	start := time.Now()
+	err := p.next.Delete(ctx, sessionID)
+	p.observe(start, err, "Delete")
+	return err
+}
+
+func (p *instrumentedProvider) DeleteAllForUser(ctx context.Context, userID string) (int64, error) {
+	start := time.Now()
+	n, err := p.next.DeleteAllForUser(ctx, userID)
+	p.observe(start, err, "DeleteAllForUser")
+	return n, err
+}
+
+func (p *instrumentedProvider) DeleteOthersForUser(ctx context.Context, userID, keepSessionID string) (int64, error) {
+	start := time.Now()
+	n, err := p.next.DeleteOthersForUser(ctx, userID, keepSessionID)
+	p.observe(start, err, "DeleteOthersForUser")
+	return n, err
+}
+
+func (p *instrumentedProvider) Rebind(ctx context.Context, sessionID string, meta Metadata) error {
+	start := time.Now()
+	err := p.next.Rebind(ctx, sessionID, meta)
+	p.observe(start, err, "Rebind")
+	return err
+}
+
+func (p *instrumentedProvider) Elevate(ctx context.Context, sessionID string) (time.Time, error) {
+	start := time.Now()
+	until, err := p.next.Elevate(ctx, sessionID)
+	p.observe(start, err, "Elevate")
+	return until, err
+}
+
+func (p *instrumentedProvider) ListForUser(ctx context.Context, userID string) ([]*Info, error) {
+	start := time.Now()
+	sessions, err := p.next.ListForUser(ctx, userID)
+	p.observe(start, err, "ListForUser")
+	return sessions, err
+}
+
+func (p *instrumentedProvider) DeleteForUser(ctx context.Context, userID, id string) error {
+	start := time.Now()
+	err := p.next.DeleteForUser(ctx, userID, id)
+	p.observe(start, err, "DeleteForUser")
+	return err
+}
+
+func (p *instrumentedProvider) ClearScope(ctx context.Context, userID, scope string) (int64, error) {
+	start := time.Now()
+	n, err := p.next.ClearScope(ctx, userID, scope)
+	p.observe(start, err, "ClearScope")
+	return n, err
+}
+
+func (p *instrumentedProvider) PurgeExpired(ctx context.Context, batchSize int) (int64, error) {
+	start := time.Now()
+	n, err := p.next.PurgeExpired(ctx, batchSize)
+	p.observe(start, err, "PurgeExpired")
+	return n, err
+}
+
+func (p *instrumentedProvider) DeleteMatching(ctx context.Context, f Filter, batchSize int, progress func(deleted int64)) (int64, error) {
+	start := time.Now()
+	n, err := p.next.DeleteMatching(ctx, f, batchSize, progress)
+	p.observe(start, err, "DeleteMatching")
+	return n, err
+}
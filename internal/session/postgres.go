@@ -2,8 +2,6 @@ package session
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/base64"
 	"errors"
 	"fmt"
 	"strings"
@@ -19,41 +17,48 @@ var (
 )
 
 type postgresProvider struct {
-	db  database.DBTX
-	cfg Config
+	db     database.DBTX
+	cfg    Config
+	tokens *Tokens
+	// prefix is the namespaced auth token prefix, e.g. "auth:" or "prod-eu:auth:".
+	prefix string
 }
 
 func newPostgresProvider(db database.DBTX, cfg Config) *postgresProvider {
-	// Defaults
-	if cfg.SlidingTTL == 0 {
-		cfg.SlidingTTL = 7 * 24 * time.Hour // 7 days
-	}
-	if cfg.AbsoluteTTL == 0 {
-		cfg.AbsoluteTTL = 30 * 24 * time.Hour // 30 days
-	}
-	return &postgresProvider{db: db, cfg: cfg}
+	cfg = cfg.withDefaults()
+	tokens := NewTokens(cfg.Namespace, cfg.TokenBytes)
+	return &postgresProvider{db: db, cfg: cfg, tokens: tokens, prefix: tokens.Prefix(TokenAuth)}
 }
 
-func (p *postgresProvider) CreateAuthSession(ctx context.Context, userID string, userAgent string, ip string) (string, error) {
-	raw, err := randomOpaque(32)
+func (p *postgresProvider) CreateAuthSession(ctx context.Context, userID string, meta Metadata) (string, error) {
+	sessionID, err := p.tokens.New(TokenAuth)
 	if err != nil {
 		return "", err
 	}
-	sessionID := "auth:" + raw
 
 	id, err := uuid.NewV7()
 	if err != nil {
 		return "", fmt.Errorf("failed to generate session row id: %w", err)
 	}
 
-	now := time.Now()
+	var expiresAt *time.Time
+	if !meta.ExpiresAt.IsZero() {
+		expiresAt = &meta.ExpiresAt
+	}
+
+	sliding, absolute := p.cfg.ttlsFor(meta)
+
+	now := p.cfg.Clock.Now()
 	sql := `
 		INSERT INTO user_active_sessions
-			(id, user_id, session_token, user_agent, ip_address, last_active_at, created_at)
+			(id, user_id, session_token, user_agent, ip_address, auth_method, scope, expires_at, fingerprint,
+			 remember_me, sliding_ttl_seconds, absolute_ttl_seconds, impersonator_id, last_active_at, created_at)
 		VALUES
-			($1, $2, $3, $4, $5, $6, $7)
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 	`
-	_, execErr := p.db.Exec(ctx, sql, id.String(), userID, sessionID, nullable(userAgent), nullable(ip), now, now)
+	fingerprint := Fingerprint(meta.UserAgent, meta.IP)
+	_, execErr := p.db.Exec(ctx, sql, id.String(), userID, HashToken(sessionID), nullable(meta.UserAgent), nullable(meta.IP), nullable(meta.AuthMethod), meta.Scope, expiresAt, nullable(fingerprint),
+		meta.RememberMe, int64(sliding/time.Second), int64(absolute/time.Second), nullable(meta.ImpersonatorID), now, now)
 	if execErr != nil {
 		return "", fmt.Errorf("failed to insert session: %w", execErr)
 	}
@@ -61,63 +66,179 @@ func (p *postgresProvider) CreateAuthSession(ctx context.Context, userID string,
 	return sessionID, nil
 }
 
-func (p *postgresProvider) GetAndExtend(ctx context.Context, sessionID string) (string, error) {
-	if sessionID == "" || !strings.Contains(sessionID, ":") {
-		return "", ErrNotFound
+func (p *postgresProvider) GetAndExtend(ctx context.Context, sessionID string) (*Info, error) {
+	info, err := p.Get(ctx, sessionID)
+	if err != nil {
+		return nil, err
 	}
 
-	var (
-		userID       string
-		createdAt    time.Time
-		lastActiveAt time.Time
-	)
+	now := p.cfg.Clock.Now()
+	// Absolute TTL (or the session's own hard expiry, whichever is sooner)
+	if now.After(info.AbsoluteExpiresAt) {
+		// Best effort cleanup
+		_, _ = p.db.Exec(ctx, `DELETE FROM user_active_sessions WHERE session_token = $1`, HashToken(sessionID))
+		return nil, ErrExpired
+	}
+	// Sliding TTL
+	if now.After(info.IdleExpiresAt) {
+		// Best effort cleanup
+		_, _ = p.db.Exec(ctx, `DELETE FROM user_active_sessions WHERE session_token = $1`, HashToken(sessionID))
+		return nil, ErrExpired
+	}
+
+	// Client binding
+	extend, err := checkBinding(ctx, p.cfg.Binding, info)
+	if err != nil {
+		_, _ = p.db.Exec(ctx, `DELETE FROM user_active_sessions WHERE session_token = $1`, HashToken(sessionID))
+		return nil, err
+	}
+	if !extend {
+		return info, nil
+	}
+
+	// Extend sliding TTL
+	_, _ = p.db.Exec(ctx, `UPDATE user_active_sessions SET last_active_at = $1 WHERE session_token = $2`, now, HashToken(sessionID))
+	info.LastActiveAt = now
+	info.IdleExpiresAt = now.Add(info.SlidingTTL)
+	info.Elevated = now.Before(info.ElevatedUntil)
+
+	return info, nil
+}
+
+func (p *postgresProvider) Get(ctx context.Context, sessionID string) (*Info, error) {
+	// Reject malformed or foreign-namespace tokens before touching the database.
+	if err := p.tokens.Validate(sessionID, TokenAuth); err != nil {
+		return nil, err
+	}
 
 	query := `
-		SELECT user_id, created_at, last_active_at
+		SELECT ` + infoColumns + `
 		FROM user_active_sessions
 		WHERE session_token = $1
 		LIMIT 1
 	`
-	row := p.db.QueryRow(ctx, query, sessionID)
-	if err := row.Scan(&userID, &createdAt, &lastActiveAt); err != nil {
-		return "", ErrNotFound
+	info, err := p.scanInfo(p.db.QueryRow(ctx, query, HashToken(sessionID)))
+	if err != nil {
+		return nil, ErrNotFound
 	}
+	return info, nil
+}
 
-	now := time.Now()
-	// Absolute TTL
-	if now.Sub(createdAt) > p.cfg.AbsoluteTTL {
-		// Best effort cleanup
-		_, _ = p.db.Exec(ctx, `DELETE FROM user_active_sessions WHERE session_token = $1`, sessionID)
-		return "", ErrExpired
-	}
-	// Sliding TTL
-	if now.Sub(lastActiveAt) > p.cfg.SlidingTTL {
-		// Best effort cleanup
-		_, _ = p.db.Exec(ctx, `DELETE FROM user_active_sessions WHERE session_token = $1`, sessionID)
-		return "", ErrExpired
-	}
+// infoColumns are the user_active_sessions columns read by scanInfo.
+const infoColumns = `id, user_id, COALESCE(user_agent, ''), COALESCE(ip_address, ''), COALESCE(auth_method, ''),
+			scope, expires_at, COALESCE(fingerprint, ''), remember_me, sliding_ttl_seconds, absolute_ttl_seconds,
+			elevated_until, COALESCE(impersonator_id, ''), created_at, last_active_at`
+
+// ttlExpired is the SQL condition for a session past its absolute, sliding, or hard expiry at $now.
+// Sessions created before TTLs were recorded per row use the configured $sliding and $absolute
+// seconds.
+const ttlExpired = `(created_at + make_interval(secs => COALESCE(absolute_ttl_seconds, $absolute)) < $now
+			OR last_active_at + make_interval(secs => COALESCE(sliding_ttl_seconds, $sliding)) < $now
+			OR COALESCE(expires_at < $now, FALSE))`
+
+// expiredCondition returns ttlExpired with its placeholders numbered from the given positions.
+func expiredCondition(now, sliding, absolute int) string {
+	return strings.NewReplacer(
+		"$now", fmt.Sprintf("$%d::timestamptz", now),
+		"$sliding", fmt.Sprintf("$%d::bigint", sliding),
+		"$absolute", fmt.Sprintf("$%d::bigint", absolute),
+	).Replace(ttlExpired)
+}
 
-	// Extend sliding TTL
-	_, _ = p.db.Exec(ctx, `UPDATE user_active_sessions SET last_active_at = $1 WHERE session_token = $2`, now, sessionID)
+// rowScanner is the Scan method shared by a pgx Row and Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanInfo reads the infoColumns of one row into an Info and derives its expiry.
+func (p *postgresProvider) scanInfo(row rowScanner) (*Info, error) {
+	var (
+		info              Info
+		expiresAt         *time.Time
+		sliding, absolute *int64
+		elevatedUntil     *time.Time
+	)
+	if err := row.Scan(&info.ID, &info.UserID, &info.UserAgent, &info.IP, &info.AuthMethod, &info.Scope, &expiresAt, &info.Fingerprint,
+		&info.RememberMe, &sliding, &absolute, &elevatedUntil, &info.ImpersonatorID, &info.CreatedAt, &info.LastActiveAt); err != nil {
+		return nil, err
+	}
+	p.cfg.deriveLifetime(&info, expiresAt, elevatedUntil, sliding, absolute)
+	return &info, nil
+}
 
-	return userID, nil
+// ttlSeconds returns the configured default TTLs, for rows without their own.
+func (p *postgresProvider) ttlSeconds() (sliding, absolute int64) {
+	return int64(p.cfg.SlidingTTL / time.Second), int64(p.cfg.AbsoluteTTL / time.Second)
 }
 
 func (p *postgresProvider) Delete(ctx context.Context, sessionID string) error {
-	_, err := p.db.Exec(ctx, `DELETE FROM user_active_sessions WHERE session_token = $1`, sessionID)
+	if !p.owns(sessionID) {
+		return nil
+	}
+	_, err := p.db.Exec(ctx, `DELETE FROM user_active_sessions WHERE session_token = $1`, HashToken(sessionID))
 	if err != nil {
 		return fmt.Errorf("failed to delete session: %w", err)
 	}
 	return nil
 }
 
-func randomOpaque(n int) (string, error) {
-	b := make([]byte, n)
-	if _, err := rand.Read(b); err != nil {
-		return "", fmt.Errorf("rand read: %w", err)
+func (p *postgresProvider) DeleteAllForUser(ctx context.Context, userID string) (int64, error) {
+	ct, err := p.db.Exec(ctx, `DELETE FROM user_active_sessions WHERE user_id = $1`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete user sessions: %w", err)
+	}
+	return ct.RowsAffected(), nil
+}
+
+func (p *postgresProvider) DeleteOthersForUser(ctx context.Context, userID, keepSessionID string) (int64, error) {
+	ct, err := p.db.Exec(ctx, `DELETE FROM user_active_sessions WHERE user_id = $1 AND session_token <> $2`, userID, HashToken(keepSessionID))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete other user sessions: %w", err)
+	}
+	return ct.RowsAffected(), nil
+}
+
+func (p *postgresProvider) Rebind(ctx context.Context, sessionID string, meta Metadata) error {
+	if !p.owns(sessionID) {
+		return ErrNotFound
+	}
+	ct, err := p.db.Exec(ctx, `UPDATE user_active_sessions SET user_agent = $1, ip_address = $2, fingerprint = $3, last_active_at = $4 WHERE session_token = $5`,
+		nullable(meta.UserAgent), nullable(meta.IP), nullable(Fingerprint(meta.UserAgent, meta.IP)), p.cfg.Clock.Now(), HashToken(sessionID))
+	if err != nil {
+		return fmt.Errorf("failed to rebind session: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (p *postgresProvider) Elevate(ctx context.Context, sessionID string) (time.Time, error) {
+	if !p.owns(sessionID) {
+		return time.Time{}, ErrNotFound
+	}
+	until := p.cfg.Clock.Now().Add(p.cfg.ElevationTTL)
+	ct, err := p.db.Exec(ctx, `UPDATE user_active_sessions SET elevated_until = $1 WHERE session_token = $2 AND impersonator_id IS NULL`, until, HashToken(sessionID))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to elevate session: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return time.Time{}, ErrNotFound
+	}
+	return until, nil
+}
+
+func (p *postgresProvider) ClearScope(ctx context.Context, userID, scope string) (int64, error) {
+	ct, err := p.db.Exec(ctx, `UPDATE user_active_sessions SET scope = '', expires_at = NULL WHERE user_id = $1 AND scope = $2`, userID, scope)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear session scope: %w", err)
 	}
-	// base64url without padding
-	return base64.RawURLEncoding.EncodeToString(b), nil
+	return ct.RowsAffected(), nil
+}
+
+// owns reports whether sessionID is a well-formed auth token of this provider's namespace.
+func (p *postgresProvider) owns(sessionID string) bool {
+	return p.tokens.Validate(sessionID, TokenAuth) == nil
 }
 
 func nullable(s string) any {
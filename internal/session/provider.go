@@ -2,12 +2,15 @@ package session
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
+	"github.com/delordemm1/go-api-simple-starter/internal/cache"
+	"github.com/delordemm1/go-api-simple-starter/internal/clock"
 	"github.com/delordemm1/go-api-simple-starter/internal/database"
 )
 
-// Config controls session TTLs.
+// Config controls session TTLs and token namespacing.
 type Config struct {
 	// SlidingTTL is the idle timeout. Each valid access extends last_active_at by this duration.
 	// Default: 7 days.
@@ -16,25 +19,224 @@ type Config struct {
 	// AbsoluteTTL is the maximum lifetime from creation. After this duration the session is invalid
 	// regardless of activity. Default: 30 days.
 	AbsoluteTTL time.Duration
+
+	// RememberMeSlidingTTL and RememberMeAbsoluteTTL replace SlidingTTL and AbsoluteTTL for
+	// sessions created with Metadata.RememberMe. Defaults: 30 and 90 days.
+	RememberMeSlidingTTL  time.Duration
+	RememberMeAbsoluteTTL time.Duration
+
+	// Namespace prefixes every session token (e.g. "prod-eu:auth:..."). Tokens from another
+	// namespace are rejected and left alone by PurgeExpired, so environments sharing a
+	// database cannot use or expire each other's sessions. Default: none ("auth:...").
+	Namespace cache.Namespace
+
+	// TokenBytes is the entropy of the random token part (see Tokens). Changing it invalidates
+	// existing sessions. Default: 32 bytes; values below 16 are raised to 16.
+	TokenBytes int
+
+	// Clock drives TTL checks, sliding extension, and expiry purges. Default: wall clock.
+	Clock clock.Clock
+
+	// ElevationTTL is how long a session counts as recently authenticated after it is created
+	// or Elevate is called (sudo mode for sensitive operations). Default: 10 minutes.
+	ElevationTTL time.Duration
+
+	// Binding is what GetAndExtend does when a session is used from a client whose Fingerprint
+	// differs from the one recorded at creation. Sessions without a recorded fingerprint are
+	// never checked. Default: BindingOff.
+	Binding BindingMode
+}
+
+// withDefaults returns cfg with its zero fields set to their defaults.
+func (cfg Config) withDefaults() Config {
+	if cfg.SlidingTTL == 0 {
+		cfg.SlidingTTL = 7 * 24 * time.Hour // 7 days
+	}
+	if cfg.AbsoluteTTL == 0 {
+		cfg.AbsoluteTTL = 30 * 24 * time.Hour // 30 days
+	}
+	if cfg.RememberMeSlidingTTL == 0 {
+		cfg.RememberMeSlidingTTL = 30 * 24 * time.Hour // 30 days
+	}
+	if cfg.RememberMeAbsoluteTTL == 0 {
+		cfg.RememberMeAbsoluteTTL = 90 * 24 * time.Hour // 90 days
+	}
+	if cfg.ElevationTTL == 0 {
+		cfg.ElevationTTL = 10 * time.Minute
+	}
+	cfg.Clock = clock.OrReal(cfg.Clock)
+	if cfg.Binding == "" {
+		cfg.Binding = BindingOff
+	}
+	return cfg
+}
+
+// ttlsFor returns the sliding and absolute TTLs of a new session created with meta.
+func (cfg Config) ttlsFor(meta Metadata) (sliding, absolute time.Duration) {
+	if meta.RememberMe {
+		return cfg.RememberMeSlidingTTL, cfg.RememberMeAbsoluteTTL
+	}
+	return cfg.SlidingTTL, cfg.AbsoluteTTL
+}
+
+// deriveLifetime fills info's TTLs, expiries and elevation from the stored values. Nil TTLs
+// (sessions created before TTLs were recorded per session) take the configured defaults.
+func (cfg Config) deriveLifetime(info *Info, expiresAt, elevatedUntil *time.Time, sliding, absolute *int64) {
+	if info.ImpersonatorID == "" {
+		info.ElevatedUntil = info.CreatedAt.Add(cfg.ElevationTTL)
+		if elevatedUntil != nil && elevatedUntil.After(info.ElevatedUntil) {
+			info.ElevatedUntil = *elevatedUntil
+		}
+	}
+	info.SlidingTTL, info.AbsoluteTTL = cfg.SlidingTTL, cfg.AbsoluteTTL
+	if sliding != nil {
+		info.SlidingTTL = time.Duration(*sliding) * time.Second
+	}
+	if absolute != nil {
+		info.AbsoluteTTL = time.Duration(*absolute) * time.Second
+	}
+	info.AbsoluteExpiresAt = info.CreatedAt.Add(info.AbsoluteTTL)
+	if expiresAt != nil && expiresAt.Before(info.AbsoluteExpiresAt) {
+		info.AbsoluteExpiresAt = *expiresAt
+	}
+	info.IdleExpiresAt = info.LastActiveAt.Add(info.SlidingTTL)
+}
+
+// ScopeEmailUnverified limits a session to the few operations that opt in to it (profile read,
+// session info, logout) while the user's email is unverified. The empty scope is unrestricted.
+const ScopeEmailUnverified = "email_unverified"
+
+// Metadata describes the client and method that established a session.
+type Metadata struct {
+	UserAgent  string
+	IP         string
+	AuthMethod string // e.g. "password", "oauth:google"
+	// Scope restricts the session (see ScopeEmailUnverified); empty means unrestricted.
+	Scope string
+	// ExpiresAt, when set, ends the session at this instant even if AbsoluteTTL is longer.
+	ExpiresAt time.Time
+	// ImpersonatorID, when set, is the admin acting as the user through this session. Such
+	// sessions never count as recently authenticated (see Config.ElevationTTL).
+	ImpersonatorID string
+	// RememberMe gives the session the long remember-me TTLs instead of the default ones.
+	// The TTLs are recorded on the session, so later Config changes only affect new sessions.
+	RememberMe bool
+}
+
+// Info is a read-only view of a session's metadata and lifetime.
+type Info struct {
+	// ID identifies the session without revealing its token, e.g. to list and revoke devices.
+	ID           string
+	UserID       string
+	UserAgent    string
+	IP           string
+	AuthMethod   string
+	Scope        string
+	CreatedAt    time.Time
+	LastActiveAt time.Time
+	// AbsoluteExpiresAt is when the session ends regardless of activity.
+	AbsoluteExpiresAt time.Time
+	// IdleExpiresAt is when the session ends if no further activity occurs.
+	IdleExpiresAt time.Time
+	// Fingerprint is the client Fingerprint recorded at creation (or the last Rebind).
+	Fingerprint string
+	// ImpersonatorID is the admin acting as the user, for impersonation sessions.
+	ImpersonatorID string
+	// RememberMe reports whether the session was created with the remember-me TTLs.
+	RememberMe bool
+	// SlidingTTL and AbsoluteTTL are the TTLs the session was created with.
+	SlidingTTL  time.Duration
+	AbsoluteTTL time.Duration
+	// ElevatedUntil is when the session stops counting as recently authenticated: ElevationTTL
+	// after its creation or the last Elevate, whichever is later.
+	ElevatedUntil time.Time
+	// Elevated is set by GetAndExtend when the session is used before ElevatedUntil.
+	Elevated bool
+	// BindingMismatch is set by GetAndExtend when the request's client does not match
+	// Fingerprint and the binding mode let the request through (BindingLog, BindingStepUp).
+	BindingMismatch bool
 }
 
 // Provider defines operations for managing opaque sessions.
 //
-// Session IDs MUST be opaque, random, and prefixed with a type, e.g. "auth:".
+// Session IDs MUST be opaque, random, and prefixed with a type, e.g. "auth:", itself
+// preceded by the configured namespace when one is set. Tokens that do not match the
+// registered format (see Tokens) are rejected without a storage lookup. Implementations store
+// only HashToken of a session ID, never the ID itself.
 type Provider interface {
 	// CreateAuthSession creates a new auth session for the given user and returns the session ID,
 	// e.g. "auth:..." with a base64url-encoded random token part.
-	// Optional userAgent and ip can be recorded for auditing.
-	CreateAuthSession(ctx context.Context, userID string, userAgent string, ip string) (sessionID string, err error)
+	// Metadata fields are optional and recorded for auditing.
+	CreateAuthSession(ctx context.Context, userID string, meta Metadata) (sessionID string, err error)
 
 	// GetAndExtend validates the given session ID (including TTL checks) and extends the sliding TTL.
-	// It returns the associated user ID on success.
-	GetAndExtend(ctx context.Context, sessionID string) (userID string, err error)
+	// It returns the session (user ID, scope, lifetime) on success. The client in ctx is compared
+	// with the session's fingerprint according to Config.Binding.
+	GetAndExtend(ctx context.Context, sessionID string) (*Info, error)
+
+	// Rebind records the client in meta (user agent, IP, and their fingerprint) on the session,
+	// e.g. after the user re-entered their password from a new network.
+	Rebind(ctx context.Context, sessionID string, meta Metadata) error
+
+	// Elevate marks the session as recently authenticated for Config.ElevationTTL, e.g. after
+	// the user re-entered their password, and returns when that ends.
+	Elevate(ctx context.Context, sessionID string) (until time.Time, err error)
+
+	// Get returns the metadata of a session without extending it.
+	Get(ctx context.Context, sessionID string) (*Info, error)
+
+	// GetData returns the JSON value stored under key in the session's data bag, or nil when
+	// the key is not set. See GetValue for typed access.
+	GetData(ctx context.Context, sessionID, key string) (json.RawMessage, error)
+
+	// SetData stores a JSON value under key in the session's data bag, which lives and expires
+	// with the session; a nil value removes the key. The bag is bounded by MaxDataBytes.
+	// See SetValue for typed access.
+	SetData(ctx context.Context, sessionID, key string, value json.RawMessage) error
+
+	// Rotate replaces the token of a session with a new one and returns it; the old token stops
+	// working in the same step. The session keeps its ID, metadata, data and lifetime. Call it
+	// when the session gains privileges (e.g. password change, re-authentication) so a token
+	// captured earlier cannot ride on them.
+	Rotate(ctx context.Context, oldSessionID string) (newSessionID string, err error)
 
 	// Delete deletes a session by its session ID. It should be idempotent.
 	Delete(ctx context.Context, sessionID string) error
+
+	// DeleteAllForUser deletes every session of the given user and returns how many were removed.
+	DeleteAllForUser(ctx context.Context, userID string) (int64, error)
+
+	// DeleteOthersForUser deletes every session of the given user except keepSessionID
+	// (e.g., the one used to change the password) and returns how many were removed.
+	DeleteOthersForUser(ctx context.Context, userID, keepSessionID string) (int64, error)
+
+	// ListForUser returns the unexpired sessions of the user, most recently active first.
+	ListForUser(ctx context.Context, userID string) ([]*Info, error)
+
+	// DeleteForUser deletes the session with Info.ID id if it belongs to userID, else ErrNotFound.
+	DeleteForUser(ctx context.Context, userID, id string) error
+
+	// ClearScope lifts scope from every session of the user (and its hard expiry), e.g. once the
+	// email is verified, and returns how many sessions were upgraded.
+	ClearScope(ctx context.Context, userID, scope string) (int64, error)
+
+	// PurgeExpired deletes expired sessions in batches of batchSize and returns how many were removed.
+	// Expired sessions are otherwise only removed lazily when presented.
+	PurgeExpired(ctx context.Context, batchSize int) (int64, error)
+
+	// DeleteMatching revokes every session matching f in batches of batchSize, reporting the
+	// running total to progress after each batch, and returns how many were removed.
+	DeleteMatching(ctx context.Context, f Filter, batchSize int, progress func(deleted int64)) (int64, error)
 }
 
+// Backends selectable with SESSION_BACKEND.
+const (
+	BackendPostgres = "postgres"
+	BackendRedis    = "redis"
+	// BackendMemory keeps sessions in process memory, for tests and local demos.
+	BackendMemory = "memory"
+)
+
 // NewPostgresProvider returns a Postgres-backed Provider implementation.
 // Implemented in postgres.go.
 func NewPostgresProvider(db database.DBTX, cfg Config) Provider {
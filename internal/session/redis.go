@@ -0,0 +1,769 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/database"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisProvider keeps sessions in Redis, for deployments that do not want a database write on
+// every authenticated request. Each session is a hash that expires with the session (the sooner
+// of its idle and absolute expiry), so Redis drops expired sessions by itself. A per-user hash
+// maps session IDs (Info.ID) to token hashes for listing and bulk revocation; PurgeExpired
+// removes the entries of sessions Redis has expired.
+//
+// Keys: <ns>:session:token:<HashToken(token)> and <ns>:session:user:<userID>. The session's data
+// bag is kept in "data:<key>" fields of its hash.
+type redisProvider struct {
+	rdb    *redis.Client
+	db     database.DBTX
+	cfg    Config
+	tokens *Tokens
+	// prefix is the namespaced auth token prefix, e.g. "auth:" or "prod-eu:auth:".
+	prefix string
+}
+
+// NewRedisProvider returns a Redis-backed Provider. db is only used by DeleteMatching to find
+// unverified users (Filter.UnverifiedUsers); with a nil db that filter fails.
+func NewRedisProvider(rdb *redis.Client, db database.DBTX, cfg Config) Provider {
+	cfg = cfg.withDefaults()
+	tokens := NewTokens(cfg.Namespace, cfg.TokenBytes)
+	return &redisProvider{rdb: rdb, db: db, cfg: cfg, tokens: tokens, prefix: tokens.Prefix(TokenAuth)}
+}
+
+// Session hash fields. Times are Unix microseconds; optional values are stored empty.
+const (
+	fieldID             = "id"
+	fieldUserID         = "user_id"
+	fieldUserAgent      = "user_agent"
+	fieldIP             = "ip"
+	fieldAuthMethod     = "auth_method"
+	fieldScope          = "scope"
+	fieldExpiresAt      = "expires_at"
+	fieldFingerprint    = "fingerprint"
+	fieldRememberMe     = "remember_me"
+	fieldSlidingTTL     = "sliding_ttl_seconds"
+	fieldAbsoluteTTL    = "absolute_ttl_seconds"
+	fieldElevatedUntil  = "elevated_until"
+	fieldImpersonatorID = "impersonator_id"
+	fieldCreatedAt      = "created_at"
+	fieldLastActiveAt   = "last_active_at"
+)
+
+// updateScript sets fields on a session that still exists and, unless ARGV[2] is "0", moves its
+// expiry to ARGV[2] (Unix ms). With ARGV[1] = "1", impersonation sessions are left alone. It
+// returns 1 when the session was updated. HSET alone would recreate a session that just expired.
+//
+// KEYS[1] session key; ARGV[1] skip impersonation; ARGV[2] expiry; ARGV[3..] field/value pairs.
+var updateScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then return 0 end
+if ARGV[1] == '1' then
+	local imp = redis.call('HGET', KEYS[1], 'impersonator_id')
+	if imp and imp ~= '' then return 0 end
+end
+redis.call('HSET', KEYS[1], unpack(ARGV, 3))
+if ARGV[2] ~= '0' then redis.call('PEXPIREAT', KEYS[1], ARGV[2]) end
+return 1
+`)
+
+// setDataScript stores ARGV[2] in field ARGV[1] of an existing session (deleting the field when
+// ARGV[2] is empty) unless the data fields would exceed ARGV[3] bytes. It returns 1 when stored,
+// 0 when the session is gone, and -1 when the data is too large.
+//
+// KEYS[1] session key; ARGV[1] field ("data:<key>"); ARGV[2] JSON value; ARGV[3] MaxDataBytes.
+var setDataScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then return 0 end
+if ARGV[2] == '' then
+	redis.call('HDEL', KEYS[1], ARGV[1])
+	return 1
+end
+local size = string.len(ARGV[1]) - 5 + string.len(ARGV[2])
+local fields = redis.call('HGETALL', KEYS[1])
+for i = 1, #fields, 2 do
+	if string.sub(fields[i], 1, 5) == 'data:' and fields[i] ~= ARGV[1] then
+		size = size + string.len(fields[i]) - 5 + string.len(fields[i + 1])
+	end
+end
+if size > tonumber(ARGV[3]) then return -1 end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+return 1
+`)
+
+// rotateScript moves a session to a new key and points its user index entry at the new token
+// hash, returning 0 when the session is gone. RENAME keeps the key's expiry.
+//
+// KEYS[1] old session key; KEYS[2] new session key; KEYS[3] user index; ARGV[1] new token hash.
+var rotateScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then return 0 end
+local id = redis.call('HGET', KEYS[1], 'id')
+redis.call('RENAME', KEYS[1], KEYS[2])
+if id then redis.call('HSET', KEYS[3], id, ARGV[1]) end
+return 1
+`)
+
+// dataFieldPrefix prefixes the session hash fields of the data bag.
+const dataFieldPrefix = "data:"
+
+func (p *redisProvider) tokenKey(hash string) string {
+	return p.cfg.Namespace.Key("session", "token", hash)
+}
+
+func (p *redisProvider) userKey(userID string) string {
+	return p.cfg.Namespace.Key("session", "user", userID)
+}
+
+func (p *redisProvider) CreateAuthSession(ctx context.Context, userID string, meta Metadata) (string, error) {
+	sessionID, err := p.tokens.New(TokenAuth)
+	if err != nil {
+		return "", err
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate session id: %w", err)
+	}
+
+	sliding, absolute := p.cfg.ttlsFor(meta)
+	now := p.cfg.Clock.Now()
+	info := &Info{
+		ID:             id.String(),
+		UserID:         userID,
+		Scope:          meta.Scope,
+		CreatedAt:      now,
+		LastActiveAt:   now,
+		ImpersonatorID: meta.ImpersonatorID,
+	}
+	var expiresAt *time.Time
+	if !meta.ExpiresAt.IsZero() {
+		expiresAt = &meta.ExpiresAt
+	}
+	slidingSeconds, absoluteSeconds := int64(sliding/time.Second), int64(absolute/time.Second)
+	p.cfg.deriveLifetime(info, expiresAt, nil, &slidingSeconds, &absoluteSeconds)
+
+	hash := HashToken(sessionID)
+	key := p.tokenKey(hash)
+	pipe := p.rdb.TxPipeline()
+	pipe.HSet(ctx, key, map[string]any{
+		fieldID:             info.ID,
+		fieldUserID:         userID,
+		fieldUserAgent:      meta.UserAgent,
+		fieldIP:             meta.IP,
+		fieldAuthMethod:     meta.AuthMethod,
+		fieldScope:          meta.Scope,
+		fieldExpiresAt:      formatTime(meta.ExpiresAt),
+		fieldFingerprint:    Fingerprint(meta.UserAgent, meta.IP),
+		fieldRememberMe:     strconv.FormatBool(meta.RememberMe),
+		fieldSlidingTTL:     slidingSeconds,
+		fieldAbsoluteTTL:    absoluteSeconds,
+		fieldElevatedUntil:  "",
+		fieldImpersonatorID: meta.ImpersonatorID,
+		fieldCreatedAt:      formatTime(now),
+		fieldLastActiveAt:   formatTime(now),
+	})
+	pipe.PExpireAt(ctx, key, keyExpiry(info))
+	pipe.HSet(ctx, p.userKey(userID), info.ID, hash)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", fmt.Errorf("failed to store session: %w", err)
+	}
+	return sessionID, nil
+}
+
+func (p *redisProvider) GetAndExtend(ctx context.Context, sessionID string) (*Info, error) {
+	info, err := p.Get(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	hash := HashToken(sessionID)
+
+	now := p.cfg.Clock.Now()
+	if now.After(info.AbsoluteExpiresAt) || now.After(info.IdleExpiresAt) {
+		// Best effort cleanup
+		_, _ = p.remove(ctx, info.UserID, info.ID, hash)
+		return nil, ErrExpired
+	}
+
+	extend, err := checkBinding(ctx, p.cfg.Binding, info)
+	if err != nil {
+		_, _ = p.remove(ctx, info.UserID, info.ID, hash)
+		return nil, err
+	}
+	if !extend {
+		return info, nil
+	}
+
+	// Extend sliding TTL
+	info.LastActiveAt = now
+	info.IdleExpiresAt = now.Add(info.SlidingTTL)
+	_, _ = p.update(ctx, hash, false, keyExpiry(info), fieldLastActiveAt, formatTime(now))
+	info.Elevated = now.Before(info.ElevatedUntil)
+	return info, nil
+}
+
+func (p *redisProvider) Get(ctx context.Context, sessionID string) (*Info, error) {
+	// Reject malformed or foreign-namespace tokens before touching Redis.
+	if err := p.tokens.Validate(sessionID, TokenAuth); err != nil {
+		return nil, err
+	}
+	fields, err := p.rdb.HGetAll(ctx, p.tokenKey(HashToken(sessionID))).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return p.parseInfo(fields)
+}
+
+func (p *redisProvider) Rebind(ctx context.Context, sessionID string, meta Metadata) error {
+	info, err := p.Get(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, ErrMalformedToken) {
+			return ErrNotFound
+		}
+		return err
+	}
+	now := p.cfg.Clock.Now()
+	info.LastActiveAt = now
+	info.IdleExpiresAt = now.Add(info.SlidingTTL)
+	ok, err := p.update(ctx, HashToken(sessionID), false, keyExpiry(info),
+		fieldUserAgent, meta.UserAgent, fieldIP, meta.IP, fieldFingerprint, Fingerprint(meta.UserAgent, meta.IP),
+		fieldLastActiveAt, formatTime(now))
+	if err != nil {
+		return fmt.Errorf("failed to rebind session: %w", err)
+	}
+	if !ok {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (p *redisProvider) Elevate(ctx context.Context, sessionID string) (time.Time, error) {
+	if !p.owns(sessionID) {
+		return time.Time{}, ErrNotFound
+	}
+	until := p.cfg.Clock.Now().Add(p.cfg.ElevationTTL)
+	ok, err := p.update(ctx, HashToken(sessionID), true, time.Time{}, fieldElevatedUntil, formatTime(until))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to elevate session: %w", err)
+	}
+	if !ok {
+		return time.Time{}, ErrNotFound
+	}
+	return until, nil
+}
+
+func (p *redisProvider) GetData(ctx context.Context, sessionID, key string) (json.RawMessage, error) {
+	if err := p.tokens.Validate(sessionID, TokenAuth); err != nil {
+		return nil, err
+	}
+	if err := checkData(key, nil); err != nil {
+		return nil, err
+	}
+	vals, err := p.rdb.HMGet(ctx, p.tokenKey(HashToken(sessionID)), fieldUserID, dataFieldPrefix+key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session data: %w", err)
+	}
+	if vals[0] == nil {
+		return nil, ErrNotFound
+	}
+	value, ok := vals[1].(string)
+	if !ok {
+		return nil, nil
+	}
+	return json.RawMessage(value), nil
+}
+
+func (p *redisProvider) SetData(ctx context.Context, sessionID, key string, value json.RawMessage) error {
+	if !p.owns(sessionID) {
+		return ErrNotFound
+	}
+	if err := checkData(key, value); err != nil {
+		return err
+	}
+	n, err := setDataScript.Run(ctx, p.rdb, []string{p.tokenKey(HashToken(sessionID))}, dataFieldPrefix+key, string(value), MaxDataBytes).Int()
+	if err != nil {
+		return fmt.Errorf("failed to set session data: %w", err)
+	}
+	switch n {
+	case 0:
+		return ErrNotFound
+	case -1:
+		return ErrDataTooLarge
+	}
+	return nil
+}
+
+func (p *redisProvider) Rotate(ctx context.Context, oldSessionID string) (string, error) {
+	if !p.owns(oldSessionID) {
+		return "", ErrNotFound
+	}
+	oldKey := p.tokenKey(HashToken(oldSessionID))
+	userID, err := p.rdb.HGet(ctx, oldKey, fieldUserID).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("failed to rotate session: %w", err)
+	}
+	sessionID, err := p.tokens.New(TokenAuth)
+	if err != nil {
+		return "", err
+	}
+	hash := HashToken(sessionID)
+	n, err := rotateScript.Run(ctx, p.rdb, []string{oldKey, p.tokenKey(hash), p.userKey(userID)}, hash).Int()
+	if err != nil {
+		return "", fmt.Errorf("failed to rotate session: %w", err)
+	}
+	if n == 0 {
+		return "", ErrNotFound
+	}
+	return sessionID, nil
+}
+
+func (p *redisProvider) Delete(ctx context.Context, sessionID string) error {
+	if !p.owns(sessionID) {
+		return nil
+	}
+	hash := HashToken(sessionID)
+	ids, err := p.rdb.HMGet(ctx, p.tokenKey(hash), fieldUserID, fieldID).Result()
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	userID, _ := ids[0].(string)
+	id, _ := ids[1].(string)
+	if userID == "" {
+		return nil
+	}
+	if _, err := p.remove(ctx, userID, id, hash); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+func (p *redisProvider) DeleteAllForUser(ctx context.Context, userID string) (int64, error) {
+	return p.deleteForUserExcept(ctx, userID, "")
+}
+
+func (p *redisProvider) DeleteOthersForUser(ctx context.Context, userID, keepSessionID string) (int64, error) {
+	return p.deleteForUserExcept(ctx, userID, HashToken(keepSessionID))
+}
+
+// deleteForUserExcept deletes every session of userID except the one with token hash keep.
+func (p *redisProvider) deleteForUserExcept(ctx context.Context, userID, keep string) (int64, error) {
+	index, err := p.rdb.HGetAll(ctx, p.userKey(userID)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete user sessions: %w", err)
+	}
+	pipe := p.rdb.Pipeline()
+	var dels []*redis.IntCmd
+	var ids []string
+	for id, hash := range index {
+		if hash == keep {
+			continue
+		}
+		dels = append(dels, pipe.Del(ctx, p.tokenKey(hash)))
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	pipe.HDel(ctx, p.userKey(userID), ids...)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to delete user sessions: %w", err)
+	}
+	var n int64
+	for _, del := range dels {
+		n += del.Val()
+	}
+	return n, nil
+}
+
+// ListForUser returns the user's sessions that have not expired, most recently active first.
+// Index entries of sessions Redis has expired are dropped on the way.
+func (p *redisProvider) ListForUser(ctx context.Context, userID string) ([]*Info, error) {
+	sessions, err := p.userSessions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user sessions: %w", err)
+	}
+	now := p.cfg.Clock.Now()
+	list := make([]*Info, 0, len(sessions))
+	for _, s := range sessions {
+		if now.After(s.info.AbsoluteExpiresAt) || now.After(s.info.IdleExpiresAt) {
+			continue
+		}
+		list = append(list, s.info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].LastActiveAt.After(list[j].LastActiveAt) })
+	return list, nil
+}
+
+// DeleteForUser deletes the session with ID id, provided it belongs to userID. Other users'
+// session IDs are reported as ErrNotFound.
+func (p *redisProvider) DeleteForUser(ctx context.Context, userID, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrNotFound
+	}
+	hash, err := p.rdb.HGet(ctx, p.userKey(userID), id).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to delete user session: %w", err)
+	}
+	n, err := p.remove(ctx, userID, id, hash)
+	if err != nil {
+		return fmt.Errorf("failed to delete user session: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (p *redisProvider) ClearScope(ctx context.Context, userID, scope string) (int64, error) {
+	sessions, err := p.userSessions(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear session scope: %w", err)
+	}
+	var n int64
+	for _, s := range sessions {
+		if s.info.Scope != scope {
+			continue
+		}
+		// The hard expiry goes with the scope.
+		s.info.AbsoluteExpiresAt = s.info.CreatedAt.Add(s.info.AbsoluteTTL)
+		ok, err := p.update(ctx, s.hash, false, keyExpiry(s.info), fieldScope, "", fieldExpiresAt, "")
+		if err != nil {
+			return n, fmt.Errorf("failed to clear session scope: %w", err)
+		}
+		if ok {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// PurgeExpired removes the user index entries of sessions Redis has already expired, scanning
+// batchSize index keys at a time, and returns how many were removed.
+func (p *redisProvider) PurgeExpired(ctx context.Context, batchSize int) (int64, error) {
+	if batchSize <= 0 {
+		batchSize = defaultGCBatchSize
+	}
+	var total int64
+	iter := p.rdb.Scan(ctx, 0, p.userKey("*"), int64(batchSize)).Iterator()
+	for iter.Next(ctx) {
+		userKey := iter.Val()
+		index, err := p.rdb.HGetAll(ctx, userKey).Result()
+		if err != nil {
+			return total, fmt.Errorf("failed to purge expired sessions: %w", err)
+		}
+		pipe := p.rdb.Pipeline()
+		exists := make(map[string]*redis.IntCmd, len(index))
+		for id, hash := range index {
+			exists[id] = pipe.Exists(ctx, p.tokenKey(hash))
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return total, fmt.Errorf("failed to purge expired sessions: %w", err)
+		}
+		var stale []string
+		for id, cmd := range exists {
+			if cmd.Val() == 0 {
+				stale = append(stale, id)
+			}
+		}
+		if len(stale) > 0 {
+			if err := p.rdb.HDel(ctx, userKey, stale...).Err(); err != nil {
+				return total, fmt.Errorf("failed to purge expired sessions: %w", err)
+			}
+			total += int64(len(stale))
+			gcDeleted.Add(int64(len(stale)))
+		}
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return total, fmt.Errorf("failed to purge expired sessions: %w", err)
+	}
+	return total, nil
+}
+
+// DeleteMatching scans this namespace's sessions batchSize keys at a time and deletes those
+// matching f, calling progress (when non-nil) with the running total after each batch.
+func (p *redisProvider) DeleteMatching(ctx context.Context, f Filter, batchSize int, progress func(deleted int64)) (int64, error) {
+	if f.IsZero() {
+		return 0, nil
+	}
+	if f.UnverifiedUsers && p.db == nil {
+		return 0, errors.New("session: filtering by unverified users requires a database")
+	}
+	if batchSize <= 0 {
+		batchSize = defaultGCBatchSize
+	}
+	var ipRange netip.Prefix
+	if f.IPRange.IsValid() {
+		ipRange = f.IPRange.Masked()
+	}
+
+	var total int64
+	var cursor uint64
+	for {
+		keys, next, err := p.rdb.Scan(ctx, cursor, p.tokenKey(p.prefix+"*"), int64(batchSize)).Result()
+		if err != nil {
+			return total, fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+		n, err := p.deleteMatchingKeys(ctx, keys, f, ipRange)
+		total += n
+		bulkRevoked.Add(n)
+		if err != nil {
+			return total, fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+		if n > 0 && progress != nil {
+			progress(total)
+		}
+		cursor = next
+		if cursor == 0 {
+			return total, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+	}
+}
+
+// deleteMatchingKeys deletes the sessions stored at keys that match f.
+func (p *redisProvider) deleteMatchingKeys(ctx context.Context, keys []string, f Filter, ipRange netip.Prefix) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	pipe := p.rdb.Pipeline()
+	cmds := make([]*redis.SliceCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.HMGet(ctx, key, fieldID, fieldUserID, fieldIP, fieldCreatedAt)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	type candidate struct{ key, id, userID string }
+	var matched []candidate
+	for i, cmd := range cmds {
+		vals := cmd.Val()
+		id, _ := vals[0].(string)
+		userID, _ := vals[1].(string)
+		ip, _ := vals[2].(string)
+		created, _ := vals[3].(string)
+		if userID == "" {
+			continue // expired since the scan
+		}
+		if !f.CreatedBefore.IsZero() {
+			if t := parseTime(created); t == nil || !t.Before(f.CreatedBefore) {
+				continue
+			}
+		}
+		if ipRange.IsValid() {
+			addr, err := netip.ParseAddr(ip)
+			if err != nil || !ipRange.Contains(addr.Unmap()) {
+				continue
+			}
+		}
+		matched = append(matched, candidate{key: keys[i], id: id, userID: userID})
+	}
+
+	if f.UnverifiedUsers && len(matched) > 0 {
+		userIDs := make([]string, len(matched))
+		for i, c := range matched {
+			userIDs[i] = c.userID
+		}
+		unverified, err := p.unverifiedUsers(ctx, userIDs)
+		if err != nil {
+			return 0, err
+		}
+		kept := matched[:0]
+		for _, c := range matched {
+			if unverified[c.userID] {
+				kept = append(kept, c)
+			}
+		}
+		matched = kept
+	}
+	if len(matched) == 0 {
+		return 0, nil
+	}
+
+	pipe = p.rdb.Pipeline()
+	dels := make([]*redis.IntCmd, len(matched))
+	for i, c := range matched {
+		dels[i] = pipe.Del(ctx, c.key)
+		pipe.HDel(ctx, p.userKey(c.userID), c.id)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	var n int64
+	for _, del := range dels {
+		n += del.Val()
+	}
+	return n, nil
+}
+
+// unverifiedUsers returns which of userIDs verified neither their email nor their phone.
+func (p *redisProvider) unverifiedUsers(ctx context.Context, userIDs []string) (map[string]bool, error) {
+	rows, err := p.db.Query(ctx, `
+		SELECT id::text FROM users
+		WHERE id = ANY($1::uuid[]) AND email_verified = FALSE AND phone_verified = FALSE
+	`, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	unverified := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		unverified[id] = true
+	}
+	return unverified, rows.Err()
+}
+
+// redisSession is a session read through the user index.
+type redisSession struct {
+	hash string
+	info *Info
+}
+
+// userSessions reads every session in userID's index, dropping entries of expired sessions.
+func (p *redisProvider) userSessions(ctx context.Context, userID string) ([]redisSession, error) {
+	index, err := p.rdb.HGetAll(ctx, p.userKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(index) == 0 {
+		return nil, nil
+	}
+	pipe := p.rdb.Pipeline()
+	cmds := make(map[string]*redis.MapStringStringCmd, len(index))
+	for id, hash := range index {
+		cmds[id] = pipe.HGetAll(ctx, p.tokenKey(hash))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	var sessions []redisSession
+	var stale []string
+	for id, cmd := range cmds {
+		info, err := p.parseInfo(cmd.Val())
+		if err != nil {
+			stale = append(stale, id)
+			continue
+		}
+		sessions = append(sessions, redisSession{hash: index[id], info: info})
+	}
+	if len(stale) > 0 {
+		_ = p.rdb.HDel(ctx, p.userKey(userID), stale...).Err()
+	}
+	return sessions, nil
+}
+
+// update runs updateScript on the session with token hash and reports whether it still existed.
+func (p *redisProvider) update(ctx context.Context, hash string, skipImpersonation bool, expireAt time.Time, fieldValues ...string) (bool, error) {
+	expiry := "0"
+	if !expireAt.IsZero() {
+		expiry = strconv.FormatInt(expireAt.UnixMilli(), 10)
+	}
+	skip := "0"
+	if skipImpersonation {
+		skip = "1"
+	}
+	args := make([]any, 0, 2+len(fieldValues))
+	args = append(args, skip, expiry)
+	for _, v := range fieldValues {
+		args = append(args, v)
+	}
+	n, err := updateScript.Run(ctx, p.rdb, []string{p.tokenKey(hash)}, args...).Int()
+	return n == 1, err
+}
+
+// remove deletes the session with token hash and its entry in userID's index, returning how
+// many sessions were deleted (0 or 1).
+func (p *redisProvider) remove(ctx context.Context, userID, id, hash string) (int64, error) {
+	pipe := p.rdb.TxPipeline()
+	del := pipe.Del(ctx, p.tokenKey(hash))
+	if id != "" {
+		pipe.HDel(ctx, p.userKey(userID), id)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return del.Val(), nil
+}
+
+// parseInfo decodes a session hash. An empty hash (unknown or expired session) is ErrNotFound.
+func (p *redisProvider) parseInfo(fields map[string]string) (*Info, error) {
+	if len(fields) == 0 || fields[fieldUserID] == "" {
+		return nil, ErrNotFound
+	}
+	info := &Info{
+		ID:             fields[fieldID],
+		UserID:         fields[fieldUserID],
+		UserAgent:      fields[fieldUserAgent],
+		IP:             fields[fieldIP],
+		AuthMethod:     fields[fieldAuthMethod],
+		Scope:          fields[fieldScope],
+		Fingerprint:    fields[fieldFingerprint],
+		ImpersonatorID: fields[fieldImpersonatorID],
+	}
+	info.RememberMe, _ = strconv.ParseBool(fields[fieldRememberMe])
+	created, lastActive := parseTime(fields[fieldCreatedAt]), parseTime(fields[fieldLastActiveAt])
+	if created == nil || lastActive == nil {
+		return nil, ErrNotFound
+	}
+	info.CreatedAt, info.LastActiveAt = *created, *lastActive
+	p.cfg.deriveLifetime(info, parseTime(fields[fieldExpiresAt]), parseTime(fields[fieldElevatedUntil]),
+		parseSeconds(fields[fieldSlidingTTL]), parseSeconds(fields[fieldAbsoluteTTL]))
+	return info, nil
+}
+
+// owns reports whether sessionID is a well-formed auth token of this provider's namespace.
+func (p *redisProvider) owns(sessionID string) bool {
+	return p.tokens.Validate(sessionID, TokenAuth) == nil
+}
+
+// keyExpiry is when Redis should drop the session: the sooner of its idle and absolute expiry.
+func keyExpiry(info *Info) time.Time {
+	if info.IdleExpiresAt.Before(info.AbsoluteExpiresAt) {
+		return info.IdleExpiresAt
+	}
+	return info.AbsoluteExpiresAt
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return strconv.FormatInt(t.UnixMicro(), 10)
+}
+
+func parseTime(s string) *time.Time {
+	us, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return nil
+	}
+	t := time.UnixMicro(us)
+	return &t
+}
+
+func parseSeconds(s string) *int64 {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &n
+}
@@ -0,0 +1,27 @@
+package session
+
+import (
+	"context"
+	"fmt"
+)
+
+// Rotate swaps the stored token hash in place, so the row (and its ID, as listed to the user)
+// stays the same and the old token stops matching in the same statement.
+func (p *postgresProvider) Rotate(ctx context.Context, oldSessionID string) (string, error) {
+	if !p.owns(oldSessionID) {
+		return "", ErrNotFound
+	}
+	sessionID, err := p.tokens.New(TokenAuth)
+	if err != nil {
+		return "", err
+	}
+	ct, err := p.db.Exec(ctx, `UPDATE user_active_sessions SET session_token = $1 WHERE session_token = $2`,
+		HashToken(sessionID), HashToken(oldSessionID))
+	if err != nil {
+		return "", fmt.Errorf("failed to rotate session: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return "", ErrNotFound
+	}
+	return sessionID, nil
+}
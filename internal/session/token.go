@@ -0,0 +1,196 @@
+package session
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/cache"
+	"github.com/delordemm1/go-api-simple-starter/internal/securerand"
+)
+
+// TokenType identifies what a session token grants; it is the prefix before the random part.
+type TokenType string
+
+const (
+	// TokenAuth is a regular signed-in session.
+	TokenAuth TokenType = "auth"
+	// TokenGuest is an anonymous session (e.g. a cart before signup).
+	TokenGuest TokenType = "guest"
+	// TokenPending is a half-finished login waiting on a second step (e.g. 2FA).
+	TokenPending TokenType = "pending"
+	// TokenSudo is a short-lived elevation for sensitive operations.
+	TokenSudo TokenType = "sudo"
+)
+
+const (
+	// DefaultTokenBytes is the random part of a token when none is configured (256 bits).
+	DefaultTokenBytes = 32
+	// MinTokenBytes is the smallest accepted random part (128 bits); shorter settings are raised.
+	MinTokenBytes = 16
+)
+
+// ErrMalformedToken is returned for tokens that do not match any registered format. Such
+// tokens are rejected before any storage lookup.
+var ErrMalformedToken = errors.New("malformed session token")
+
+// TokenFormat describes how tokens of one type are built: "<namespace>:<prefix>:<random>",
+// where random is Bytes random bytes encoded as unpadded base64url.
+type TokenFormat struct {
+	Prefix string
+	Bytes  int
+}
+
+// Tokens is the registry of token formats for one namespace. It is safe for concurrent use
+// once configured; Register is meant for startup only.
+type Tokens struct {
+	ns      cache.Namespace
+	formats map[TokenType]TokenFormat
+}
+
+// NewTokens returns a registry with the built-in types (auth, guest, pending, sudo), each
+// prefixed by its own name and carrying bytes of entropy (DefaultTokenBytes when 0).
+func NewTokens(ns cache.Namespace, bytes int) *Tokens {
+	t := &Tokens{ns: ns, formats: make(map[TokenType]TokenFormat)}
+	for _, tt := range []TokenType{TokenAuth, TokenGuest, TokenPending, TokenSudo} {
+		t.Register(tt, TokenFormat{Prefix: string(tt), Bytes: bytes})
+	}
+	return t
+}
+
+// Register adds or replaces the format of tt. Bytes below MinTokenBytes are raised to it.
+func (t *Tokens) Register(tt TokenType, f TokenFormat) {
+	if f.Prefix == "" {
+		f.Prefix = string(tt)
+	}
+	if f.Bytes == 0 {
+		f.Bytes = DefaultTokenBytes
+	}
+	if f.Bytes < MinTokenBytes {
+		f.Bytes = MinTokenBytes
+	}
+	t.formats[tt] = f
+}
+
+// Prefix returns the namespaced prefix of tt, e.g. "auth:" or "prod-eu:auth:".
+func (t *Tokens) Prefix(tt TokenType) string {
+	f, ok := t.formats[tt]
+	if !ok {
+		return ""
+	}
+	return t.ns.Key(f.Prefix, "")
+}
+
+// New returns a fresh random token of type tt.
+func (t *Tokens) New(tt TokenType) (string, error) {
+	f, ok := t.formats[tt]
+	if !ok {
+		return "", fmt.Errorf("session: unknown token type %q", tt)
+	}
+	raw, err := securerand.Token(f.Bytes)
+	if err != nil {
+		return "", err
+	}
+	return t.ns.Key(f.Prefix, raw), nil
+}
+
+// Parse returns the type of token after checking its namespace, prefix, length, and alphabet.
+func (t *Tokens) Parse(token string) (TokenType, error) {
+	for tt, f := range t.formats {
+		raw, ok := strings.CutPrefix(token, t.ns.Key(f.Prefix, ""))
+		if !ok {
+			continue
+		}
+		if strings.HasPrefix(raw, refMarker) {
+			if _, ok := parseRef(token); !ok {
+				return "", ErrMalformedToken
+			}
+			return tt, nil
+		}
+		if len(raw) != base64.RawURLEncoding.EncodedLen(f.Bytes) {
+			return "", ErrMalformedToken
+		}
+		if _, err := base64.RawURLEncoding.Strict().DecodeString(raw); err != nil {
+			return "", ErrMalformedToken
+		}
+		return tt, nil
+	}
+	return "", ErrMalformedToken
+}
+
+// Validate returns ErrMalformedToken unless token is a well-formed token of type tt.
+func (t *Tokens) Validate(token string, tt TokenType) error {
+	got, err := t.Parse(token)
+	if err != nil {
+		return err
+	}
+	if got != tt {
+		return ErrMalformedToken
+	}
+	return nil
+}
+
+// HashToken returns the form in which token is stored: its prefix (namespace and type, e.g.
+// "auth:") followed by the hex SHA-256 of the whole token. Stores keep only this, so a copy of
+// the session table cannot be replayed as bearer tokens, while the prefix still lets queries
+// select a namespace's sessions.
+func HashToken(token string) string {
+	if hash, ok := parseRef(token); ok {
+		return hash
+	}
+	prefix := token[:strings.LastIndexByte(token, ':')+1]
+	sum := sha256.Sum256([]byte(token))
+	return prefix + hex.EncodeToString(sum[:])
+}
+
+// refMarker starts the random part of a token reference. It is outside the base64url
+// alphabet, so no issued token contains it.
+const refMarker = "~"
+
+// refKey authenticates token references. It is per process, as references never leave it.
+var refKey = func() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("session: generate reference key: %v", err))
+	}
+	return key
+}()
+
+// tokenRef returns a stand-in for the token stored as hash (a HashToken value), for callers
+// that hold the hash but not the token, such as the JWT provider. HashToken maps it back to
+// hash and Tokens.Parse accepts it as the type in hash's prefix, so it passes through every
+// provider and wrapper like the token itself. A reference carries a MAC under a per-process
+// key: clients cannot make one from a hash, and the session table still cannot be replayed.
+func tokenRef(hash string) string {
+	i := strings.LastIndexByte(hash, ':') + 1
+	return hash[:i] + refMarker + hash[i:] + "." + refMAC(hash)
+}
+
+// parseRef returns the hash that token stands for when it is a reference made by tokenRef.
+func parseRef(token string) (string, bool) {
+	i := strings.LastIndexByte(token, ':') + 1
+	body, ok := strings.CutPrefix(token[i:], refMarker)
+	if !ok {
+		return "", false
+	}
+	digest, mac, ok := strings.Cut(body, ".")
+	if !ok {
+		return "", false
+	}
+	hash := token[:i] + digest
+	if !hmac.Equal([]byte(mac), []byte(refMAC(hash))) {
+		return "", false
+	}
+	return hash, true
+}
+
+func refMAC(hash string) string {
+	m := hmac.New(sha256.New, refKey)
+	m.Write([]byte(hash))
+	return base64.RawURLEncoding.EncodeToString(m.Sum(nil))
+}
@@ -0,0 +1,231 @@
+// Package siem streams security events (logins, failed logins, session revocations,
+// admin actions) to an external SIEM over HTTPS or syslog, in signed batches.
+package siem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/delordemm1/go-api-simple-starter/internal/contextx"
+	"github.com/delordemm1/go-api-simple-starter/internal/metrics"
+	chimw "github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+)
+
+// eventsTotal counts streamed events, labelled by outcome (sent, failed, dropped).
+var eventsTotal = metrics.NewCounter("siem_events")
+
+// Outcome of the action an event describes.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Event is a single security event. Publish fills ID, Time and the request/client
+// fields from the context when they are empty.
+type Event struct {
+	ID        string         `json:"id"`
+	Type      string         `json:"type"`
+	Outcome   string         `json:"outcome"`
+	Time      time.Time      `json:"time"`
+	UserID    string         `json:"userId,omitempty"`
+	ActorID   string         `json:"actorId,omitempty"`
+	RequestID string         `json:"requestId,omitempty"`
+	IPAddress string         `json:"ipAddress,omitempty"`
+	UserAgent string         `json:"userAgent,omitempty"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+}
+
+// Publisher accepts security events. Publish must not block the calling request.
+type Publisher interface {
+	Publish(ctx context.Context, e Event)
+}
+
+// Nop discards all events; used when no SIEM endpoint is configured.
+type Nop struct{}
+
+func (Nop) Publish(context.Context, Event) {}
+
+// Multi publishes each event to every publisher in order (e.g., the SIEM stream and the
+// per-user audit log).
+type Multi []Publisher
+
+func (m Multi) Publish(ctx context.Context, e Event) {
+	for _, p := range m {
+		p.Publish(ctx, e)
+	}
+}
+
+// Config configures a Stream.
+type Config struct {
+	// Endpoint is an https:// URL (batches are POSTed as JSON) or a syslog+tcp://,
+	// syslog+tls:// or syslog+udp:// address (one RFC 5424 message per event).
+	Endpoint string
+	// Secret signs batches (HMAC-SHA256). Empty disables signing.
+	Secret string
+	// BatchSize is the maximum number of events per delivery. Default: 100.
+	BatchSize int
+	// FlushInterval bounds how long an event waits for its batch to fill. Default: 5s.
+	FlushInterval time.Duration
+	// QueueSize bounds buffered events; when full, new events are dropped. Default: 10000.
+	QueueSize int
+}
+
+// transport delivers one batch of events.
+type transport interface {
+	send(ctx context.Context, batch []Event) error
+	close() error
+}
+
+// Stream buffers published events and delivers them in batches from a background goroutine.
+// It implements the bootstrap lifecycle interfaces (Start/Stop); Stop flushes pending events.
+type Stream struct {
+	cfg       Config
+	log       *slog.Logger
+	transport transport
+
+	queue chan Event
+	stop  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+}
+
+// New creates a stream for cfg.Endpoint. It does not connect until events are delivered.
+func New(cfg Config, log *slog.Logger) (*Stream, error) {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 5 * time.Second
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 10000
+	}
+	t, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Stream{
+		cfg:       cfg,
+		log:       log,
+		transport: t,
+		queue:     make(chan Event, cfg.QueueSize),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}, nil
+}
+
+// Publish enqueues e without blocking; events are dropped (and counted) when the queue is full.
+func (s *Stream) Publish(ctx context.Context, e Event) {
+	if e.ID == "" {
+		if id, err := uuid.NewV7(); err == nil {
+			e.ID = id.String()
+		}
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	if e.Outcome == "" {
+		e.Outcome = OutcomeSuccess
+	}
+	if e.RequestID == "" {
+		e.RequestID = chimw.GetReqID(ctx)
+	}
+	if e.IPAddress == "" {
+		e.IPAddress = contextx.ClientIP(ctx)
+	}
+	if e.UserAgent == "" {
+		e.UserAgent = contextx.UserAgent(ctx)
+	}
+
+	select {
+	case s.queue <- e:
+	default:
+		eventsTotal.Inc("dropped")
+		s.log.Warn("siem queue full, dropping event", "type", e.Type)
+	}
+}
+
+// Start launches the delivery loop.
+func (s *Stream) Start(context.Context) error {
+	go s.run()
+	return nil
+}
+
+// Stop flushes queued events and closes the transport, giving up when ctx expires.
+func (s *Stream) Stop(ctx context.Context) error {
+	s.once.Do(func() { close(s.stop) })
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		return fmt.Errorf("siem flush: %w", ctx.Err())
+	}
+	return s.transport.close()
+}
+
+func (s *Stream) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, s.cfg.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		s.deliver(batch)
+		batch = make([]Event, 0, s.cfg.BatchSize)
+	}
+
+	for {
+		select {
+		case e := <-s.queue:
+			batch = append(batch, e)
+			if len(batch) >= s.cfg.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-s.stop:
+			// Drain what is already queued, then exit.
+			for {
+				select {
+				case e := <-s.queue:
+					batch = append(batch, e)
+					if len(batch) >= s.cfg.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver sends a batch with a few retries; a batch that still fails is dropped and counted.
+func (s *Stream) deliver(batch []Event) {
+	const attempts = 3
+	var err error
+	for i := 0; i < attempts; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err = s.transport.send(ctx, batch)
+		cancel()
+		if err == nil {
+			eventsTotal.Add(int64(len(batch)), "sent")
+			return
+		}
+		if i < attempts-1 {
+			time.Sleep(time.Duration(i+1) * time.Second)
+		}
+	}
+	eventsTotal.Add(int64(len(batch)), "failed")
+	s.log.Error("siem delivery failed", "error", err, "events", len(batch))
+}
+
+var errUnsupportedEndpoint = errors.New("siem: endpoint must be https://, syslog+tcp://, syslog+tls:// or syslog+udp://")
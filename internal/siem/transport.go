@@ -0,0 +1,187 @@
+package siem
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signature headers sent with HTTPS batches. The signature is
+// hex(HMAC-SHA256(secret, timestamp + "." + body)), prefixed with "sha256=".
+const (
+	HeaderSignature = "X-SIEM-Signature"
+	HeaderTimestamp = "X-SIEM-Timestamp"
+)
+
+func newTransport(cfg Config) (transport, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("siem: parse endpoint: %w", err)
+	}
+	switch u.Scheme {
+	case "https":
+		return &httpTransport{url: u.String(), secret: cfg.Secret, client: &http.Client{Timeout: 10 * time.Second}}, nil
+	case "http":
+		// Plain HTTP is only accepted for a collector on the same host.
+		if h := u.Hostname(); h != "localhost" && h != "127.0.0.1" && h != "::1" {
+			return nil, errUnsupportedEndpoint
+		}
+		return &httpTransport{url: u.String(), secret: cfg.Secret, client: &http.Client{Timeout: 10 * time.Second}}, nil
+	case "syslog+tcp", "syslog+tls", "syslog+udp":
+		hostname, _ := os.Hostname()
+		return &syslogTransport{
+			network:  strings.TrimPrefix(u.Scheme, "syslog+"),
+			addr:     u.Host,
+			secret:   cfg.Secret,
+			hostname: hostname,
+		}, nil
+	default:
+		return nil, errUnsupportedEndpoint
+	}
+}
+
+func sign(secret string, parts ...[]byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	for _, p := range parts {
+		mac.Write(p)
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// httpTransport POSTs each batch as {"events": [...]}.
+type httpTransport struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func (t *httpTransport) send(ctx context.Context, batch []Event) error {
+	body, err := json.Marshal(map[string]any{"events": batch})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.secret != "" {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(HeaderTimestamp, ts)
+		req.Header.Set(HeaderSignature, "sha256="+sign(t.secret, []byte(ts), []byte("."), body))
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("siem endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (t *httpTransport) close() error {
+	t.client.CloseIdleConnections()
+	return nil
+}
+
+// syslogTransport writes one RFC 5424 message per event (facility authpriv), with the
+// event JSON as the message and, when a secret is set, its HMAC as structured data.
+// TCP and TLS use octet-counting framing (RFC 6587).
+type syslogTransport struct {
+	network  string // tcp, tls or udp
+	addr     string
+	secret   string
+	hostname string
+	conn     net.Conn // only used from the Stream's delivery goroutine
+}
+
+// syslogAuthPriv is the authpriv facility code (10) shifted into the PRI field.
+const syslogAuthPriv = 10 * 8
+
+func (t *syslogTransport) send(ctx context.Context, batch []Event) error {
+	if t.conn == nil {
+		if err := t.dial(ctx); err != nil {
+			return err
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = t.conn.SetWriteDeadline(deadline)
+	}
+	for _, e := range batch {
+		if err := t.write(e); err != nil {
+			// Reconnect on the next attempt; the whole batch is retried.
+			_ = t.conn.Close()
+			t.conn = nil
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *syslogTransport) dial(ctx context.Context) error {
+	var d net.Dialer
+	var err error
+	switch t.network {
+	case "tls":
+		td := &tls.Dialer{NetDialer: &d}
+		t.conn, err = td.DialContext(ctx, "tcp", t.addr)
+	default:
+		t.conn, err = d.DialContext(ctx, t.network, t.addr)
+	}
+	return err
+}
+
+func (t *syslogTransport) write(e Event) error {
+	msg, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	severity := 5 // notice
+	if e.Outcome == OutcomeFailure {
+		severity = 4 // warning
+	}
+	sd := "-"
+	if t.secret != "" {
+		sd = `[sig@32473 hmac="` + sign(t.secret, msg) + `"]`
+	}
+	line := fmt.Sprintf("<%d>1 %s %s go-api - %s %s %s",
+		syslogAuthPriv+severity, e.Time.UTC().Format(time.RFC3339Nano), nilValue(t.hostname), nilValue(e.Type), sd, msg)
+	if t.network == "udp" {
+		_, err = io.WriteString(t.conn, line)
+		return err
+	}
+	_, err = fmt.Fprintf(t.conn, "%d %s", len(line), line)
+	return err
+}
+
+func (t *syslogTransport) close() error {
+	if t.conn == nil {
+		return nil
+	}
+	return t.conn.Close()
+}
+
+// nilValue returns the RFC 5424 NILVALUE for empty header fields.
+func nilValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
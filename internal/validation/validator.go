@@ -3,19 +3,46 @@ package validation
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 
+	"github.com/danielgtaylor/huma/v2"
 	"github.com/go-playground/validator/v10"
 )
 
 // FieldErrors maps JSON field names to a list of validation error messages.
 type FieldErrors map[string][]string
 
+// ErrorDetails flattens f into one huma.ErrorDetail per message, ordered by field name.
+// locations overrides a field's location (e.g., "path.provider"); other fields use their name.
+func (f FieldErrors) ErrorDetails(locations map[string]string) []*huma.ErrorDetail {
+	keys := make([]string, 0, len(f))
+	for k := range f {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var details []*huma.ErrorDetail
+	for _, field := range keys {
+		loc := field
+		if l, ok := locations[field]; ok {
+			loc = l
+		}
+		for _, msg := range f[field] {
+			details = append(details, &huma.ErrorDetail{Message: msg, Location: loc})
+		}
+	}
+	return details
+}
+
 // ValidationError implements a DomainProblem (from internal/httpx) without importing it directly,
 // by providing the required method set. This avoids cycles and lets httpx.ToProblem format it.
 type ValidationError struct {
 	summary string
 	fields  FieldErrors
+	// locations maps request-parameter field names to their location (e.g., "path.provider").
+	// Body fields are not listed and use the field name as their location.
+	locations map[string]string
 }
 
 func (e *ValidationError) Error() string { return e.summary }
@@ -29,15 +56,29 @@ func (e *ValidationError) ProblemDetail() string  { return e.summary }
 func (e *ValidationError) ProblemTypeURI() string { return "urn:problem:validation-error" }
 func (e *ValidationError) ProblemContext() any    { return map[string]any{"fields": e.fields} }
 
+// ProblemErrors exposes one huma.ErrorDetail per field message so clients relying on
+// the standard errors[] shape see the same information as context.fields.
+func (e *ValidationError) ProblemErrors() []*huma.ErrorDetail {
+	return e.fields.ErrorDetails(e.locations)
+}
+
+// paramTags are the Huma request-parameter tags, in lookup order.
+var paramTags = []string{"path", "query", "header", "cookie", "form"}
+
 // ValidateStruct validates a struct instance according to `validate` tags.
+// It accepts either a Body struct or a whole Huma input struct, in which case
+// path/query/header/cookie/form parameters are validated alongside the body.
 // On success it returns nil. On failure it returns a *ValidationError with:
 // - summary: "invalid <field>, and N other errors" or "validation failed"
-// - fields:  map of JSON field name to list of messages
+// - fields:  map of JSON (or parameter) field name to list of messages
 func ValidateStruct(v any) error {
 	validate := validator.New()
 
-	// Use JSON tag names instead of struct field names.
+	// Use JSON tag names (or parameter names) instead of struct field names.
 	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		if name, _ := paramName(fld); name != "" {
+			return name
+		}
 		jsonTag := fld.Tag.Get("json")
 		name := strings.Split(jsonTag, ",")[0]
 		if name == "" || name == "-" {
@@ -59,8 +100,9 @@ func ValidateStruct(v any) error {
 			// Build summarized detail per spec, e.g. "invalid email, and 2 other errors"
 			summary := summarize(fields)
 			return &ValidationError{
-				summary: summary,
-				fields:  fields,
+				summary:   summary,
+				fields:    fields,
+				locations: paramLocations(v),
 			}
 		}
 		// Non-standard error from validator, return a generic summary.
@@ -72,6 +114,35 @@ func ValidateStruct(v any) error {
 	return nil
 }
 
+// paramName returns the parameter name and tag (e.g., "provider", "path") for a
+// Huma request-parameter field, or empty strings for other fields.
+func paramName(fld reflect.StructField) (string, string) {
+	for _, tag := range paramTags {
+		if name := strings.Split(fld.Tag.Get(tag), ",")[0]; name != "" && name != "-" {
+			return name, tag
+		}
+	}
+	return "", ""
+}
+
+// paramLocations maps the top-level parameter fields of v to "<tag>.<name>" locations.
+func paramLocations(v any) map[string]string {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	locs := make(map[string]string)
+	for i := 0; i < t.NumField(); i++ {
+		if name, tag := paramName(t.Field(i)); name != "" {
+			locs[name] = tag + "." + name
+		}
+	}
+	return locs
+}
+
 func messageForTag(fe validator.FieldError) string {
 	switch fe.Tag() {
 	case "required":
@@ -89,6 +160,19 @@ func messageForTag(fe validator.FieldError) string {
 			return fmt.Sprintf("must be at most %s characters", fe.Param())
 		}
 		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "len":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be exactly %s characters", fe.Param())
+		}
+		return fmt.Sprintf("must have length %s", fe.Param())
+	case "gte":
+		return fmt.Sprintf("must be greater than or equal to %s", fe.Param())
+	case "lte":
+		return fmt.Sprintf("must be less than or equal to %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.Join(strings.Fields(fe.Param()), ", "))
+	case "uuid", "uuid4", "uuid7":
+		return "must be a valid UUID"
 	case "eqfield":
 		// Match other field; convert to JSON lower-camel if needed
 		return fmt.Sprintf("must match %s", toJSONFieldName(fe.Param()))
@@ -179,4 +263,10 @@ func plural(n int) string {
 		return ""
 	}
 	return "s"
-}
\ No newline at end of file
+}
+
+// NewFieldError builds a *ValidationError for checks made outside struct tags (e.g., by a
+// service), formatted exactly like ValidateStruct failures.
+func NewFieldError(fields FieldErrors) *ValidationError {
+	return &ValidationError{summary: summarize(fields), fields: fields}
+}
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- How the session was established (e.g., 'password', 'oauth:google')
+ALTER TABLE user_active_sessions ADD COLUMN IF NOT EXISTS auth_method TEXT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_active_sessions DROP COLUMN IF EXISTS auth_method;
+-- +goose StatementEnd
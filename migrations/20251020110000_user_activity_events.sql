@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Security-relevant account activity shown to the user (logins, password/email changes, new devices)
+CREATE TABLE IF NOT EXISTS user_activity_events (
+  id UUID PRIMARY KEY, -- UUIDv7: time-ordered, used as the pagination cursor
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  event_type TEXT NOT NULL,
+  ip_address TEXT NULL,
+  user_agent TEXT NULL,
+  metadata JSONB NOT NULL DEFAULT '{}'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_activity_events_user_id ON user_activity_events (user_id, id DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_user_activity_events_user_id;
+DROP TABLE IF EXISTS user_activity_events;
+-- +goose StatementEnd
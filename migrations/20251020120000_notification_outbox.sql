@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- One row per channel dispatch attempt (sent, failed, or skipped in dry-run mode)
+CREATE TABLE IF NOT EXISTS notification_outbox (
+  id UUID PRIMARY KEY,
+  channel TEXT NOT NULL,
+  recipient TEXT NOT NULL,
+  template_id TEXT NULL,
+  subject TEXT NULL,
+  body TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL, -- 'sent' | 'failed' | 'dry_run'
+  error TEXT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_outbox_created_at ON notification_outbox (created_at);
+CREATE INDEX IF NOT EXISTS idx_notification_outbox_status ON notification_outbox (status, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_notification_outbox_status;
+DROP INDEX IF EXISTS idx_notification_outbox_created_at;
+DROP TABLE IF EXISTS notification_outbox;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Re-engagement and anonymization markers used by the inactive account cleanup job
+ALTER TABLE users ADD COLUMN IF NOT EXISTS reengagement_sent_at TIMESTAMPTZ NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMPTZ NULL;
+
+-- Audit trail of automated lifecycle actions. user_id intentionally has no foreign key
+-- so records survive hard deletion of the user.
+CREATE TABLE IF NOT EXISTS account_lifecycle_audit (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL,
+  action TEXT NOT NULL,
+  reason TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_lifecycle_audit_user_id ON account_lifecycle_audit (user_id);
+CREATE INDEX IF NOT EXISTS idx_users_created_at_unverified ON users (created_at) WHERE email_verified = FALSE;
+CREATE INDEX IF NOT EXISTS idx_user_activity_events_login ON user_activity_events (user_id, created_at) WHERE event_type = 'login';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_user_activity_events_login;
+DROP INDEX IF EXISTS idx_users_created_at_unverified;
+DROP INDEX IF EXISTS idx_account_lifecycle_audit_user_id;
+DROP TABLE IF EXISTS account_lifecycle_audit;
+ALTER TABLE users DROP COLUMN IF EXISTS anonymized_at;
+ALTER TABLE users DROP COLUMN IF EXISTS reengagement_sent_at;
+-- +goose StatementEnd
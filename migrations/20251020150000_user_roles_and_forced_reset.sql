@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Coarse role for admin-only endpoints ('user' or 'admin')
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'user';
+-- Set by admins after a suspected compromise; password login is refused until the password is reset
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_reset_required BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS password_reset_required;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Notifications whose delivery attempts were exhausted; admins can fix the recipient and requeue them
+CREATE TABLE IF NOT EXISTS notification_dead_letters (
+  id UUID PRIMARY KEY,
+  channel TEXT NOT NULL,
+  recipient TEXT NOT NULL,
+  priority TEXT NOT NULL,
+  template_id TEXT NULL,
+  content JSONB NOT NULL,
+  attempts INT NOT NULL,
+  last_error TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'pending', -- 'pending' | 'requeued'
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  requeued_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_dead_letters_status ON notification_dead_letters (status, id);
+CREATE INDEX IF NOT EXISTS idx_notification_dead_letters_recipient ON notification_dead_letters (recipient);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_notification_dead_letters_recipient;
+DROP INDEX IF EXISTS idx_notification_dead_letters_status;
+DROP TABLE IF EXISTS notification_dead_letters;
+-- +goose StatementEnd
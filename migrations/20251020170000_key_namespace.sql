@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Namespace (env/region) of the deployment that wrote each notification record, so
+-- environments sharing a database only list and requeue their own
+ALTER TABLE notification_outbox ADD COLUMN IF NOT EXISTS namespace TEXT NOT NULL DEFAULT '';
+ALTER TABLE notification_dead_letters ADD COLUMN IF NOT EXISTS namespace TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_notification_dead_letters_namespace ON notification_dead_letters (namespace, status, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_notification_dead_letters_namespace;
+ALTER TABLE notification_dead_letters DROP COLUMN IF EXISTS namespace;
+ALTER TABLE notification_outbox DROP COLUMN IF EXISTS namespace;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Phone number (E.164) for SMS one-time-code login
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone TEXT NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_verified BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE UNIQUE INDEX IF NOT EXISTS users_phone_key ON users (phone) WHERE phone IS NOT NULL;
+
+-- Phone-only accounts have an empty email, so email is unique only when present
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_key ON users (email) WHERE email <> '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Phone-only accounts cannot be represented without the phone column
+DELETE FROM users WHERE email = '';
+DROP INDEX IF EXISTS users_email_key;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+DROP INDEX IF EXISTS users_phone_key;
+ALTER TABLE users DROP COLUMN IF EXISTS phone_verified;
+ALTER TABLE users DROP COLUMN IF EXISTS phone;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Admin-initiated bulk session revocations (incident response) and their progress
+CREATE TABLE IF NOT EXISTS session_revocation_jobs (
+  id UUID PRIMARY KEY,
+  actor_id UUID NOT NULL REFERENCES users(id),
+  created_before TIMESTAMPTZ NULL,
+  ip_range TEXT NULL,
+  unverified_users BOOLEAN NOT NULL DEFAULT FALSE,
+  reason TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL DEFAULT 'running', -- 'running' | 'completed' | 'failed'
+  revoked BIGINT NOT NULL DEFAULT 0,
+  batches INT NOT NULL DEFAULT 0,
+  error TEXT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  finished_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_session_revocation_jobs_created_at ON session_revocation_jobs (created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_session_revocation_jobs_created_at;
+DROP TABLE IF EXISTS session_revocation_jobs;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Flags dispatches made by the admin template test-send endpoint
+ALTER TABLE notification_outbox ADD COLUMN IF NOT EXISTS is_test BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE notification_outbox DROP COLUMN IF EXISTS is_test;
+-- +goose StatementEnd
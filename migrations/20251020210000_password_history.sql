@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Recent password hashes per user, so resets and changes cannot reuse them (PASSWORD_HISTORY_SIZE)
+CREATE TABLE IF NOT EXISTS user_password_history (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  password_hash TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_password_history_user ON user_password_history (user_id, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_user_password_history_user;
+DROP TABLE IF EXISTS user_password_history;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Restricted sessions (e.g., unverified email during the grace period) and their hard expiry
+ALTER TABLE user_active_sessions ADD COLUMN IF NOT EXISTS scope TEXT NOT NULL DEFAULT '';
+ALTER TABLE user_active_sessions ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_active_sessions DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE user_active_sessions DROP COLUMN IF EXISTS scope;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Admin suspension: suspended accounts cannot sign in until an admin lifts the suspension
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspended_at TIMESTAMPTZ NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspended_reason TEXT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS suspended_reason;
+ALTER TABLE users DROP COLUMN IF EXISTS suspended_at;
+-- +goose StatementEnd
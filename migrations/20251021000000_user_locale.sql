@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Recipient formatting preferences: BCP 47 language tag and IANA time zone ('' = defaults)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
+ALTER TABLE users DROP COLUMN IF EXISTS locale;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Set when the user opts out of the onboarding email sequence; pending steps are skipped
+ALTER TABLE users ADD COLUMN IF NOT EXISTS onboarding_unsubscribed_at TIMESTAMPTZ NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS onboarding_unsubscribed_at;
+-- +goose StatementEnd
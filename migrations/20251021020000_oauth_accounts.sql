@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Provider identities linked to a user. OAuth logins are matched by (provider, provider_user_id),
+-- never by email alone; a user has at most one identity per provider.
+CREATE TABLE IF NOT EXISTS oauth_accounts (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  provider TEXT NOT NULL,
+  provider_user_id TEXT NOT NULL,
+  email TEXT NOT NULL DEFAULT '', -- email reported by the provider when linked
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT oauth_accounts_provider_subject_key UNIQUE (provider, provider_user_id),
+  CONSTRAINT oauth_accounts_user_provider_key UNIQUE (user_id, provider)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS oauth_accounts;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Provider tokens from the last exchange or refresh, sealed with OAUTH_TOKEN_ENCRYPTION_KEY.
+-- NULL when token storage is disabled or the provider returned none.
+ALTER TABLE oauth_accounts ADD COLUMN IF NOT EXISTS access_token BYTEA NULL;
+ALTER TABLE oauth_accounts ADD COLUMN IF NOT EXISTS refresh_token BYTEA NULL;
+ALTER TABLE oauth_accounts ADD COLUMN IF NOT EXISTS token_expires_at TIMESTAMPTZ NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE oauth_accounts DROP COLUMN IF EXISTS token_expires_at;
+ALTER TABLE oauth_accounts DROP COLUMN IF EXISTS refresh_token;
+ALTER TABLE oauth_accounts DROP COLUMN IF EXISTS access_token;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Nonce sent with Apple authorization requests; the returned id_token must carry it.
+ALTER TABLE oauth_states ADD COLUMN IF NOT EXISTS nonce TEXT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE oauth_states DROP COLUMN IF EXISTS nonce;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Retention passes select old records by age.
+CREATE INDEX IF NOT EXISTS idx_account_lifecycle_audit_created_at ON account_lifecycle_audit (created_at);
+CREATE INDEX IF NOT EXISTS idx_user_activity_events_created_at ON user_activity_events (created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_user_activity_events_created_at;
+DROP INDEX IF EXISTS idx_account_lifecycle_audit_created_at;
+-- +goose StatementEnd
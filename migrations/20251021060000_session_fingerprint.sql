@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Coarse client fingerprint (client family + IP network) for session binding
+ALTER TABLE user_active_sessions ADD COLUMN IF NOT EXISTS fingerprint TEXT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_active_sessions DROP COLUMN IF EXISTS fingerprint;
+-- +goose StatementEnd
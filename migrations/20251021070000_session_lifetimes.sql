@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Per-session TTLs (remember-me); NULL falls back to the configured defaults
+ALTER TABLE user_active_sessions ADD COLUMN IF NOT EXISTS remember_me BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE user_active_sessions ADD COLUMN IF NOT EXISTS sliding_ttl_seconds BIGINT NULL;
+ALTER TABLE user_active_sessions ADD COLUMN IF NOT EXISTS absolute_ttl_seconds BIGINT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_active_sessions DROP COLUMN IF EXISTS absolute_ttl_seconds;
+ALTER TABLE user_active_sessions DROP COLUMN IF EXISTS sliding_ttl_seconds;
+ALTER TABLE user_active_sessions DROP COLUMN IF EXISTS remember_me;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Sudo mode: until when a session counts as recently authenticated after re-entering the password
+ALTER TABLE user_active_sessions ADD COLUMN IF NOT EXISTS elevated_until TIMESTAMPTZ NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_active_sessions DROP COLUMN IF EXISTS elevated_until;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Admin impersonation: the admin acting as the session's user
+ALTER TABLE user_active_sessions ADD COLUMN IF NOT EXISTS impersonator_id TEXT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_active_sessions DROP COLUMN IF EXISTS impersonator_id;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Terms-of-service version the user last accepted, and when; NULL until they accept one
+ALTER TABLE users ADD COLUMN IF NOT EXISTS terms_version TEXT NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS terms_accepted_at TIMESTAMPTZ NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS terms_accepted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS terms_version;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Per-user security event log (logins, logouts, password changes, OAuth links, verifications)
+CREATE TABLE IF NOT EXISTS security_events (
+  id UUID PRIMARY KEY, -- UUIDv7: time-ordered, used as the pagination cursor
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  event_type TEXT NOT NULL,
+  outcome TEXT NOT NULL DEFAULT 'success',
+  ip_address TEXT NULL,
+  user_agent TEXT NULL,
+  metadata JSONB NOT NULL DEFAULT '{}'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_security_events_user_id ON security_events (user_id, id DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_security_events_user_id;
+DROP TABLE IF EXISTS security_events;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Admin announcements emailed to an audience in batches through the job queue, and their progress
+CREATE TABLE IF NOT EXISTS broadcasts (
+  id UUID PRIMARY KEY,
+  actor_id UUID NOT NULL REFERENCES users(id),
+  audience TEXT NOT NULL, -- 'verified' | 'inactive'
+  inactive_days INT NULL,
+  subject TEXT NOT NULL,
+  message TEXT NOT NULL,
+  reason TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL DEFAULT 'running', -- 'running' | 'completed' | 'failed' | 'cancelled'
+  sent BIGINT NOT NULL DEFAULT 0,
+  failed BIGINT NOT NULL DEFAULT 0,
+  batches INT NOT NULL DEFAULT 0,
+  cursor UUID NULL, -- last recipient user ID processed
+  error TEXT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  finished_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_broadcasts_created_at ON broadcasts (created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_broadcasts_created_at;
+DROP TABLE IF EXISTS broadcasts;
+-- +goose StatementEnd
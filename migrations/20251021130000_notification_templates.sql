@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Notification templates uploaded by admins (TEMPLATES_SOURCE=database). Every upload is a new
+-- version; the highest one is served. Templates without a row fall back to the embedded copy.
+CREATE TABLE IF NOT EXISTS notification_templates (
+  namespace TEXT NOT NULL DEFAULT '',
+  id TEXT NOT NULL, -- template ID, e.g. 'user.verify_email'
+  version BIGINT NOT NULL,
+  content TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (namespace, id, version)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notification_templates;
+-- +goose StatementEnd
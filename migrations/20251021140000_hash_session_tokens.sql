@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Session tokens are stored as their prefix (e.g. 'auth:') followed by the hex SHA-256 of the
+-- whole token (session.HashToken), so existing sessions keep working after the upgrade.
+UPDATE user_active_sessions
+SET session_token = COALESCE(substring(session_token from '^(.*:)'), '')
+    || encode(sha256(convert_to(session_token, 'UTF8')), 'hex');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Hashes cannot be turned back into tokens; everyone signs in again.
+DELETE FROM user_active_sessions;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Small per-session key/value data (session.Provider GetData/SetData), e.g. the chosen
+-- organization or locale. Bounded in code by session.MaxDataBytes.
+ALTER TABLE user_active_sessions ADD COLUMN IF NOT EXISTS data JSONB NOT NULL DEFAULT '{}'::jsonb;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_active_sessions DROP COLUMN IF EXISTS data;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Lets the expired-gc job find expired action tokens without scanning the table.
+CREATE INDEX IF NOT EXISTS idx_action_tokens_expires_at ON action_tokens (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_action_tokens_expires_at;
+-- +goose StatementEnd